@@ -0,0 +1,200 @@
+// Copyright 2026 Franklin "Snaipe" Mathieu.
+//
+// Use of this source code is governed by the MIT license that can be
+// found in the LICENSE file.
+
+package htutil
+
+import (
+	"net/http"
+	"net/textproto"
+	"sort"
+	"strings"
+)
+
+// CanonicalizeAccept returns the canonical form of the values of the
+// Accept, Accept-Charset, Accept-Encoding, or Accept-Language header field
+// name, so that requests expressing the same preferences send the same
+// field value.
+//
+// In the canonical form, members are combined into a single line, sorted
+// by decreasing quality, with the original order of members of equal
+// quality preserved. Duplicate members are dropped, values and parameter
+// names are lowercased (language tags get their conventional case), and
+// parameters are sorted. Quality values are written with the fewest digits,
+// and omitted when equal to 1, unless accept-ext parameters follow them.
+// Unparseable members are dropped.
+func CanonicalizeAccept(name string, values ...string) string {
+	language := textproto.CanonicalMIMEHeaderKey(name) == "Accept-Language"
+
+	var (
+		accepts []Acceptable
+		seen    = map[string]bool{}
+	)
+	for _, v := range values {
+		for _, member := range SplitList(v) {
+			acc, err := ParseAcceptable(member)
+			if err != nil {
+				continue
+			}
+			if language {
+				acc.Value = canonicalLanguageTag(acc.Value)
+			}
+			key := formatAcceptable(Acceptable{Value: acc.Value, Quality: 1, Params: acc.Params})
+			if seen[key] {
+				continue
+			}
+			seen[key] = true
+			accepts = append(accepts, acc)
+		}
+	}
+	sort.SliceStable(accepts, func(i, j int) bool {
+		return accepts[i].Quality > accepts[j].Quality && !qualityEq(accepts[i].Quality, accepts[j].Quality)
+	})
+
+	members := make([]string, len(accepts))
+	for i, acc := range accepts {
+		members[i] = formatAcceptable(acc)
+	}
+	return strings.Join(members, ", ")
+}
+
+// formatAcceptable formats acc like Acceptable.String, but only writes its
+// quality when it is not 1, or when accept-ext parameters follow it.
+func formatAcceptable(acc Acceptable) string {
+	acc.QualitySet = false
+	return acc.String()
+}
+
+// writeAcceptParams writes the parameters params to out, sorted by name.
+func writeAcceptParams(out *strings.Builder, params map[string]string) {
+	keys := make([]string, 0, len(params))
+	for k := range params {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	for _, k := range keys {
+		out.WriteString(";" + k + "=" + tokenOrQuoted(params[k]))
+	}
+}
+
+// FormatAccept returns the value of an Accept, Accept-Charset,
+// Accept-Encoding, or Accept-Language header field conveying accs, in
+// order.
+func FormatAccept(accs ...Acceptable) string {
+	members := make([]string, len(accs))
+	for i, acc := range accs {
+		members[i] = acc.String()
+	}
+	return strings.Join(members, ", ")
+}
+
+// SetAccept merges accs into the Accept, Accept-Charset, Accept-Encoding,
+// or Accept-Language header field key of h, combining it into a single
+// line. Members of the field with the same value and parameters as one of
+// accs are replaced by it, at the position of the first of them; the other
+// members of accs are appended, in order. Unparseable members of the field
+// are kept as is.
+//
+// To replace the field altogether, set it to the result of FormatAccept.
+func SetAccept(h http.Header, key string, accs ...Acceptable) {
+	identity := func(acc Acceptable) string {
+		return formatAcceptable(Acceptable{Value: strings.ToLower(acc.Value), Quality: 1, Params: acc.Params})
+	}
+	index := make(map[string]int, len(accs))
+	for i := len(accs) - 1; i >= 0; i-- {
+		index[identity(accs[i])] = i
+	}
+
+	var members []string
+	merged := make([]bool, len(accs))
+	for _, v := range h.Values(key) {
+		for _, member := range SplitList(v) {
+			if acc, err := ParseAcceptable(member); err == nil {
+				if i, ok := index[identity(acc)]; ok {
+					if !merged[i] {
+						members = append(members, accs[i].String())
+						merged[i] = true
+					}
+					continue
+				}
+			}
+			members = append(members, member)
+		}
+	}
+	for i, acc := range accs {
+		if !merged[i] {
+			members = append(members, acc.String())
+			merged[i] = true
+		}
+	}
+	h.Set(key, strings.Join(members, ", "))
+}
+
+// canonicalLanguageTag returns tag with the conventional case of BCP 47
+// subtags: lowercase languages, titlecase scripts, and uppercase regions.
+func canonicalLanguageTag(tag string) string {
+	subtags := strings.Split(strings.ToLower(tag), "-")
+	for i, sub := range subtags {
+		if i == 0 {
+			continue
+		}
+		if len(subtags[i-1]) == 1 {
+			// Subtags following a singleton belong to an extension or to
+			// private use, and are left lowercase.
+			break
+		}
+		switch {
+		case len(sub) == 2:
+			subtags[i] = strings.ToUpper(sub)
+		case len(sub) == 4 && !isDigit(sub[0]):
+			subtags[i] = strings.ToUpper(sub[:1]) + sub[1:]
+		}
+	}
+	return strings.Join(subtags, "-")
+}
+
+// AcceptTransport is a http.RoundTripper that canonicalizes the Accept,
+// Accept-Charset, Accept-Encoding, and Accept-Language header fields of
+// outgoing requests with CanonicalizeAccept.
+//
+// Caches storing responses that vary on these fields key them on the
+// exact field values; sending the same preferences in the same form
+// across requests improves their hit rates.
+type AcceptTransport struct {
+	// Base is the underlying transport. Defaults to http.DefaultTransport.
+	Base http.RoundTripper
+}
+
+func (t *AcceptTransport) base() http.RoundTripper {
+	if t.Base != nil {
+		return t.Base
+	}
+	return http.DefaultTransport
+}
+
+func (t *AcceptTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	var clone *http.Request
+	for _, name := range []string{"Accept", "Accept-Charset", "Accept-Encoding", "Accept-Language"} {
+		values := req.Header.Values(name)
+		if len(values) == 0 {
+			continue
+		}
+		canonical := CanonicalizeAccept(name, values...)
+		if len(values) == 1 && values[0] == canonical {
+			continue
+		}
+		if clone == nil {
+			clone = req.Clone(req.Context())
+		}
+		if canonical == "" {
+			clone.Header.Del(name)
+		} else {
+			clone.Header.Set(name, canonical)
+		}
+	}
+	if clone != nil {
+		req = clone
+	}
+	return t.base().RoundTrip(req)
+}
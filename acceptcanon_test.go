@@ -0,0 +1,158 @@
+// Copyright 2026 Franklin "Snaipe" Mathieu.
+//
+// Use of this source code is governed by the MIT license that can be
+// found in the LICENSE file.
+
+package htutil
+
+import (
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestCanonicalizeAccept(t *testing.T) {
+	t.Parallel()
+
+	tcases := []struct {
+		Name      string
+		Values    []string
+		Canonical string
+	}{
+		{
+			Name:      "Accept",
+			Values:    []string{"text/html;q=0.80, Application/JSON , text/plain; charset=UTF-8 ;format=flowed"},
+			Canonical: "application/json, text/plain;charset=UTF-8;format=flowed, text/html;q=0.8",
+		},
+		{
+			Name:      "Accept",
+			Values:    []string{"*/*;q=0.1", "text/html", "text/html;q=1.000", "image/*"},
+			Canonical: "text/html, image/*, */*;q=0.1",
+		},
+		{
+			Name:      "accept-encoding",
+			Values:    []string{"GZIP, br;q=1.0, identity;q=0, zstd;q=0.500"},
+			Canonical: "gzip, br, zstd;q=0.5, identity;q=0",
+		},
+		{
+			Name:      "Accept-Language",
+			Values:    []string{"fr-ca;q=0.9, EN-us, zh-hant-tw;q=0.5, en-x-twain;q=0.1"},
+			Canonical: "en-US, fr-CA;q=0.9, zh-Hant-TW;q=0.5, en-x-twain;q=0.1",
+		},
+		{
+			Name:      "Accept",
+			Values:    []string{"text/html;level=1;q=1;ext=A, text/plain;q=0.5;b=2;a=1"},
+			Canonical: "text/html;level=1;q=1;ext=A, text/plain;q=0.5;a=1;b=2",
+		},
+		{
+			Name:      "Accept",
+			Values:    []string{"invalid/, ;;"},
+			Canonical: "",
+		},
+	}
+
+	for i, tcase := range tcases {
+		t.Run(fmt.Sprintf("%d", i), func(t *testing.T) {
+			if v := CanonicalizeAccept(tcase.Name, tcase.Values...); v != tcase.Canonical {
+				t.Fatalf("expected %q, got %q", tcase.Canonical, v)
+			}
+		})
+	}
+}
+
+func TestFormatAccept(t *testing.T) {
+	t.Parallel()
+
+	accs := []Acceptable{
+		{Value: "text/html", Quality: 1},
+		{Value: "text/plain", Quality: 0.5, Params: map[string]string{"format": "flowed", "charset": "utf-8"}},
+		{Value: "*/*", Quality: 0.1, Extensions: map[string]string{"b": "2", "a": "1"}},
+	}
+	const expected = "text/html, text/plain;charset=utf-8;format=flowed;q=0.5, */*;q=0.1;a=1;b=2"
+	if actual := FormatAccept(accs...); actual != expected {
+		t.Fatalf("expected %q, got %q", expected, actual)
+	}
+	if actual := FormatAccept(ParseAccept(expected)...); actual != expected {
+		t.Fatalf("expected %q to round-trip, got %q", expected, actual)
+	}
+}
+
+func TestSetAccept(t *testing.T) {
+	t.Parallel()
+
+	tcases := []struct {
+		Values   []string
+		Accs     []Acceptable
+		Expected string
+	}{
+		{
+			Accs:     []Acceptable{{Value: "gzip", Quality: 1}, {Value: "br", Quality: 0.5}},
+			Expected: "gzip, br;q=0.5",
+		},
+		{
+			Values:   []string{"gzip, deflate;q=0.5", "br"},
+			Accs:     []Acceptable{{Value: "zstd", Quality: 1}, {Value: "DEFLATE", Quality: 0.8}},
+			Expected: "gzip, DEFLATE;q=0.8, br, zstd",
+		},
+		{
+			Values:   []string{"text/html;level=1, text/html;q=0.5"},
+			Accs:     []Acceptable{{Value: "text/html", Quality: 0.9}},
+			Expected: "text/html;level=1, text/html;q=0.9",
+		},
+		{
+			// Unparseable members are kept as is, and duplicates are merged.
+			Values:   []string{"text/html;q=x, text/html;q=0.2, text/html"},
+			Accs:     []Acceptable{{Value: "text/html", Quality: 1}},
+			Expected: "text/html;q=x, text/html",
+		},
+	}
+
+	for i, tcase := range tcases {
+		t.Run(fmt.Sprintf("%d", i), func(t *testing.T) {
+			h := http.Header{}
+			for _, v := range tcase.Values {
+				h.Add("Accept", v)
+			}
+			SetAccept(h, "Accept", tcase.Accs...)
+			if actual := h.Values("Accept"); len(actual) != 1 || actual[0] != tcase.Expected {
+				t.Fatalf("expected %q, got %q", tcase.Expected, actual)
+			}
+		})
+	}
+}
+
+func TestAcceptTransport(t *testing.T) {
+	t.Parallel()
+
+	var got http.Header
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+		got = req.Header
+	}))
+	defer srv.Close()
+
+	req, err := http.NewRequest("GET", srv.URL, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	req.Header.Add("Accept", "text/html;q=0.5")
+	req.Header.Add("Accept", "application/json")
+	req.Header.Set("Accept-Language", "en-us")
+
+	client := &http.Client{Transport: &AcceptTransport{}}
+	resp, err := client.Do(req)
+	if err != nil {
+		t.Fatal(err)
+	}
+	resp.Body.Close()
+
+	if v := got.Values("Accept"); len(v) != 1 || v[0] != "application/json, text/html;q=0.5" {
+		t.Fatalf("expected canonical Accept, got %q", v)
+	}
+	if v := got.Get("Accept-Language"); v != "en-US" {
+		t.Fatalf("expected canonical Accept-Language, got %q", v)
+	}
+	if v := req.Header.Values("Accept"); len(v) != 2 {
+		t.Fatalf("expected original request to be left untouched, got %q", v)
+	}
+}
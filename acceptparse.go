@@ -0,0 +1,409 @@
+// Copyright 2026 Franklin "Snaipe" Mathieu.
+//
+// Use of this source code is governed by the MIT license that can be
+// found in the LICENSE file.
+
+package htutil
+
+import (
+	"errors"
+	"fmt"
+	"mime"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"unicode/utf8"
+)
+
+// This file implements the parsing of the members of Accept-family fields
+// (media ranges, codings, languages, and charsets). It follows the grammar
+// accepted by mime.ParseMediaType, which it used to be built on, but avoids
+// its allocations and lowercasing passes, as proxies parse these fields on
+// every request.
+
+// errSlowPath is returned by parseAcceptable for the inputs it leaves to
+// mime.ParseMediaType: non-ASCII text, which mime lowercases and trims
+// according to Unicode, and RFC 2231 extended parameters.
+var errSlowPath = errors.New("slow path")
+
+var (
+	errNoMediaType      = errors.New("parsing acceptable value: no value")
+	errBadMediaType     = errors.New("parsing acceptable value: invalid value")
+	errDuplicateParam   = errors.New("parsing acceptable value: duplicate parameter name")
+	errInvalidParameter = fmt.Errorf("parsing acceptable value: %w", mime.ErrInvalidMediaParameter)
+)
+
+// parseAcceptable parses a single acceptable value, or returns errSlowPath.
+func parseAcceptable(v string) (Acceptable, error) {
+	for i := 0; i < len(v); i++ {
+		if v[i] >= utf8.RuneSelf {
+			return Acceptable{}, errSlowPath
+		}
+	}
+
+	end := strings.IndexByte(v, ';')
+	if end < 0 {
+		end = len(v)
+	}
+	value := trimASCIISpace(v[:end])
+	typ, rest := consumeMIMEToken(value)
+	if typ == "" {
+		return Acceptable{}, errNoMediaType
+	}
+	if rest != "" {
+		if rest[0] != '/' {
+			return Acceptable{}, errBadMediaType
+		}
+		subtype, rest := consumeMIMEToken(rest[1:])
+		if subtype == "" || rest != "" {
+			return Acceptable{}, errBadMediaType
+		}
+	}
+
+	acc := Acceptable{Value: lowerASCII(value), Quality: 1}
+	var qstr string
+	var hasQ bool
+	for rest := v[end:]; ; {
+		rest = trimLeftASCIISpace(rest)
+		if rest == "" {
+			break
+		}
+		key, val, next, ok := consumeMIMEParam(rest)
+		if !ok {
+			if trimASCIISpace(rest) == ";" {
+				// Trailing semicolons are ignored.
+				break
+			}
+			return Acceptable{}, errInvalidParameter
+		}
+		if strings.IndexByte(key, '*') >= 0 {
+			return Acceptable{}, errSlowPath
+		}
+		switch old, ok := acc.Params[key]; {
+		case key == "q":
+			if hasQ && qstr != val {
+				return Acceptable{}, errDuplicateParam
+			}
+			qstr, hasQ = val, true
+		case ok:
+			if old != val {
+				return Acceptable{}, errDuplicateParam
+			}
+		case hasQ:
+			if old, ok := acc.Extensions[key]; ok && old != val {
+				return Acceptable{}, errDuplicateParam
+			}
+			if acc.Extensions == nil {
+				acc.Extensions = make(map[string]string, 1)
+			}
+			acc.Extensions[key] = val
+		default:
+			if acc.Params == nil {
+				acc.Params = make(map[string]string, 1)
+			}
+			acc.Params[key] = val
+		}
+		rest = next
+	}
+
+	if hasQ {
+		quality, err := parseQuality(qstr)
+		if err != nil {
+			return Acceptable{}, err
+		}
+		acc.Quality, acc.QualitySet = quality, true
+	}
+	return acc, nil
+}
+
+// parseAcceptableValue parses a single acceptable value like
+// ParseAcceptable, but leaves Params nil if the value has none, so that
+// ForEachAcceptable and AcceptBuffer do not allocate for them.
+func parseAcceptableValue(v string) (Acceptable, error) {
+	acc, err := parseAcceptable(v)
+	if err == errSlowPath {
+		return parseAcceptableMIME(v)
+	}
+	return acc, err
+}
+
+// parseAcceptableMIME parses a single acceptable value with
+// mime.ParseMediaType.
+func parseAcceptableMIME(v string) (Acceptable, error) {
+	value, params, err := mime.ParseMediaType(v)
+	if err != nil {
+		return Acceptable{}, err
+	}
+
+	quality := float32(1)
+	qstr, hasQ := params["q"]
+	if hasQ {
+		if quality, err = parseQuality(qstr); err != nil {
+			return Acceptable{}, err
+		}
+		delete(params, "q")
+	}
+
+	// The parameters are unordered once parsed, so the ones following the
+	// quality are found from the names in the original value.
+	var exts map[string]string
+	if hasQ {
+		var afterQ bool
+		before := make(map[string]bool)
+		for _, param := range splitUnquoted(v, ';')[1:] {
+			name, _, _ := strings.Cut(param, "=")
+			name, _, _ = strings.Cut(strings.ToLower(strings.TrimSpace(name)), "*")
+			switch {
+			case name == "q":
+				afterQ = true
+			case !afterQ:
+				before[name] = true
+			case !before[name]:
+				if val, ok := params[name]; ok {
+					if exts == nil {
+						exts = make(map[string]string, 1)
+					}
+					exts[name] = val
+					delete(params, name)
+				}
+			}
+		}
+	}
+	return Acceptable{
+		Value:      value,
+		Quality:    quality,
+		QualitySet: hasQ,
+		Params:     params,
+		Extensions: exts,
+	}, nil
+}
+
+// ForEachAcceptable calls fn with each acceptable value of the Accept-family
+// field values, in field order, until fn returns false. Unparseable values
+// are silently dropped, like in ParseAccept.
+//
+// Unlike ParseAccept, ForEachAcceptable does not sort or collect the values,
+// and only allocates for values with parameters other than the quality
+// factor, or that must be lowercased. The Params of values without
+// parameters are nil.
+func ForEachAcceptable(values []string, fn func(Acceptable) bool) {
+	for _, value := range values {
+		for rest := value; rest != ""; {
+			var member string
+			member, rest = cutListMember(rest)
+			if member == "" {
+				continue
+			}
+			acc, err := parseAcceptableValue(member)
+			if err != nil {
+				continue
+			}
+			if !fn(acc) {
+				return
+			}
+		}
+	}
+}
+
+// AppendAccept parses the Accept-family field values like ParseAccept, and
+// appends the acceptable values to dst, sorted by precedence. Values that
+// were already in dst are left in place. Reusing dst across calls avoids
+// allocating a new slice each time; see also AcceptBuffer. Like in
+// ForEachAcceptable, the Params of values without parameters are nil.
+func AppendAccept(dst []Acceptable, values ...string) []Acceptable {
+	start := len(dst)
+	ForEachAcceptable(values, func(acc Acceptable) bool {
+		dst = append(dst, acc)
+		return true
+	})
+	sortAcceptables(dst[start:])
+	return dst
+}
+
+// sortAcceptables sorts accepts by precedence. Values of equal precedence
+// keep their field order.
+func sortAcceptables(accepts []Acceptable) {
+	// Accept fields rarely have more than a dozen members, which are
+	// sorted in place, without the allocations of sort.SliceStable.
+	if len(accepts) > 12 {
+		sort.SliceStable(accepts, func(i, j int) bool { return accepts[i].Less(accepts[j]) })
+		return
+	}
+	for i := 1; i < len(accepts); i++ {
+		for j := i; j > 0 && accepts[j].Less(accepts[j-1]); j-- {
+			accepts[j], accepts[j-1] = accepts[j-1], accepts[j]
+		}
+	}
+}
+
+// AcceptBuffer is a pooled buffer of acceptable values, to parse
+// Accept-family fields on hot paths without allocating:
+//
+//	buf := htutil.AcquireAcceptBuffer()
+//	defer buf.Release()
+//	for _, acc := range buf.Parse(req.Header.Values("Accept")...) {
+//		...
+//	}
+type AcceptBuffer struct {
+	accepts []Acceptable
+}
+
+var acceptBufferPool = sync.Pool{
+	New: func() any { return &AcceptBuffer{accepts: make([]Acceptable, 0, 16)} },
+}
+
+// AcquireAcceptBuffer returns an AcceptBuffer from the pool.
+func AcquireAcceptBuffer() *AcceptBuffer {
+	return acceptBufferPool.Get().(*AcceptBuffer)
+}
+
+// Parse parses the Accept-family field values like AppendAccept, into the
+// buffer. The returned slice is only valid until the next call to Parse or
+// Release.
+func (b *AcceptBuffer) Parse(values ...string) []Acceptable {
+	b.accepts = AppendAccept(b.reset(), values...)
+	return b.accepts
+}
+
+// Release returns b to the pool. b and the values it parsed must not be
+// used afterwards.
+func (b *AcceptBuffer) Release() {
+	// Oversized buffers are dropped rather than pinned by the pool.
+	if cap(b.accepts) > 256 {
+		return
+	}
+	b.accepts = b.reset()
+	acceptBufferPool.Put(b)
+}
+
+// reset clears the values of b, so that their parameter maps can be
+// collected, and returns its empty slice.
+func (b *AcceptBuffer) reset() []Acceptable {
+	for i := range b.accepts {
+		b.accepts[i] = Acceptable{}
+	}
+	return b.accepts[:0]
+}
+
+func parseQuality(qstr string) (float32, error) {
+	quality, err := strconv.ParseFloat(qstr, 32)
+	if err != nil {
+		return 0, fmt.Errorf("parsing quality factor: %w", err)
+	}
+	if quality > 1 || quality < 0 {
+		return 0, fmt.Errorf("parsing quality factor: %s is not between 0 and 1", qstr)
+	}
+	return float32(quality), nil
+}
+
+// tspecials are the special characters of RFC 1521 and RFC 2045.
+const tspecials = `()<>@,;:\"/[]?=`
+
+// isMIMETokenChar reports whether c is a token character, as defined by
+// RFC 1521 and RFC 2045, like in package mime.
+func isMIMETokenChar(c byte) bool {
+	return c > 0x20 && c < 0x7f && strings.IndexByte(tspecials, c) < 0
+}
+
+func consumeMIMEToken(v string) (token, rest string) {
+	i := 0
+	for i < len(v) && isMIMETokenChar(v[i]) {
+		i++
+	}
+	return v[:i], v[i:]
+}
+
+// consumeMIMEValue consumes a token or a quoted string. It returns ok=false
+// if there is neither.
+func consumeMIMEValue(v string) (value, rest string, ok bool) {
+	if v == "" {
+		return "", v, false
+	}
+	if v[0] != '"' {
+		value, rest = consumeMIMEToken(v)
+		return value, rest, value != ""
+	}
+	for i := 1; i < len(v); i++ {
+		switch v[i] {
+		case '"':
+			return v[1:i], v[i+1:], true
+		case '\\', '\r', '\n':
+			return consumeQuotedEscapes(v)
+		}
+	}
+	return "", v, false
+}
+
+// consumeQuotedEscapes consumes a quoted string with escapes. Like package
+// mime, backslashes only escape tspecials, and are literal otherwise, as
+// some clients do not escape backslashes in file paths.
+func consumeQuotedEscapes(v string) (value, rest string, ok bool) {
+	var buf strings.Builder
+	for i := 1; i < len(v); i++ {
+		c := v[i]
+		switch {
+		case c == '"':
+			return buf.String(), v[i+1:], true
+		case c == '\\' && i+1 < len(v) && strings.IndexByte(tspecials, v[i+1]) >= 0:
+			buf.WriteByte(v[i+1])
+			i++
+		case c == '\r' || c == '\n':
+			return "", v, false
+		default:
+			buf.WriteByte(c)
+		}
+	}
+	return "", v, false
+}
+
+// consumeMIMEParam consumes a `;name=value` parameter. Parameter names are
+// lowercased.
+func consumeMIMEParam(v string) (key, value, rest string, ok bool) {
+	rest = trimLeftASCIISpace(v)
+	if rest == "" || rest[0] != ';' {
+		return "", "", v, false
+	}
+	key, rest = consumeMIMEToken(trimLeftASCIISpace(rest[1:]))
+	if key == "" {
+		return "", "", v, false
+	}
+	rest = trimLeftASCIISpace(rest)
+	if rest == "" || rest[0] != '=' {
+		return "", "", v, false
+	}
+	value, rest, ok = consumeMIMEValue(trimLeftASCIISpace(rest[1:]))
+	if !ok {
+		return "", "", v, false
+	}
+	return lowerASCII(key), value, rest, true
+}
+
+func isASCIISpace(c byte) bool {
+	return c == ' ' || c == '\t' || c == '\n' || c == '\v' || c == '\f' || c == '\r'
+}
+
+func trimLeftASCIISpace(s string) string {
+	for len(s) > 0 && isASCIISpace(s[0]) {
+		s = s[1:]
+	}
+	return s
+}
+
+func trimASCIISpace(s string) string {
+	s = trimLeftASCIISpace(s)
+	for len(s) > 0 && isASCIISpace(s[len(s)-1]) {
+		s = s[:len(s)-1]
+	}
+	return s
+}
+
+// lowerASCII returns s in lowercase, without allocating if it already is.
+func lowerASCII(s string) string {
+	for i := 0; i < len(s); i++ {
+		if c := s[i]; 'A' <= c && c <= 'Z' {
+			return strings.ToLower(s)
+		}
+	}
+	return s
+}
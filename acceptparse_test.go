@@ -0,0 +1,263 @@
+// Copyright 2026 Franklin "Snaipe" Mathieu.
+//
+// Use of this source code is governed by the MIT license that can be
+// found in the LICENSE file.
+
+package htutil
+
+import (
+	"fmt"
+	"reflect"
+	"sort"
+	"strings"
+	"testing"
+)
+
+// acceptableSamples are typical members of Accept-family fields, plus edge
+// cases of the grammar.
+var acceptableSamples = []string{
+	"text/html",
+	"TEXT/HTML; Level=1",
+	"application/json;q=0.9",
+	"*/*; q=0.1",
+	"gzip",
+	"fr-CH",
+	"iso-8859-5;q=0.8",
+	`text/plain; title="a, b"; q=0.1`,
+	`text/plain; title="C:\dev\go"`,
+	`text/plain; title="a\"b"`,
+	"text/html; charset=utf-8;",
+	"text/html; charset=utf-8; charset=utf-8",
+	"text/html; charset=utf-8; charset=latin1",
+	"text/html; q=0.5; q=0.5",
+	"text/html; q=2",
+	"text/html; q=abc",
+	"text/html;",
+	"text/html; ;",
+	"text/html; level",
+	"text/html; level=",
+	`text/html; level="1`,
+	"text / html",
+	"text/",
+	"/html",
+	"",
+	"   ",
+	"text/html/x",
+	"text/html; title*=utf-8''%E2%82%AC",
+	"text/html; level=1; q=0.5; ext=a",
+	"text/html; q=0.5; level=1; level=1",
+	"text/html; level=1; q=0.5; level=1",
+	"text/html; level=1; q=0.5; level=2",
+	"text/htmlé",
+	"\u212Aext/html",
+}
+
+func TestParseAcceptable(t *testing.T) {
+	t.Parallel()
+
+	tcases := []struct {
+		In       string
+		Expected Acceptable
+		Err      bool
+	}{
+		{In: "text/html", Expected: Acceptable{Value: "text/html", Quality: 1, Params: map[string]string{}}},
+		{In: " TEXT/HTML ; Level=1 ; q=0.5 ", Expected: Acceptable{Value: "text/html", Quality: 0.5, QualitySet: true, Params: map[string]string{"level": "1"}}},
+		{In: "text/html;q=1", Expected: Acceptable{Value: "text/html", Quality: 1, QualitySet: true, Params: map[string]string{}}},
+		{In: "text/html; level=1; q=0.5; ext=a; Token", Err: true},
+		{In: `text/html; level=1; q=0.5; Ext="a b"`, Expected: Acceptable{Value: "text/html", Quality: 0.5, QualitySet: true, Params: map[string]string{"level": "1"}, Extensions: map[string]string{"ext": "a b"}}},
+		{In: "text/html; level=1; q=0.5; level=1", Expected: Acceptable{Value: "text/html", Quality: 0.5, QualitySet: true, Params: map[string]string{"level": "1"}}},
+		{In: "text/html; q=0.5; ext*=utf-8''%E2%82%AC", Expected: Acceptable{Value: "text/html", Quality: 0.5, QualitySet: true, Params: map[string]string{}, Extensions: map[string]string{"ext": "€"}}},
+		{In: `text/plain; title="a, b"`, Expected: Acceptable{Value: "text/plain", Quality: 1, Params: map[string]string{"title": "a, b"}}},
+		{In: `text/plain; title="a\"b\c"`, Expected: Acceptable{Value: "text/plain", Quality: 1, Params: map[string]string{"title": `a"b\c`}}},
+		{In: "gzip;q=0", Expected: Acceptable{Value: "gzip", Quality: 0, QualitySet: true, Params: map[string]string{}}},
+		{In: "text/html;", Expected: Acceptable{Value: "text/html", Quality: 1, Params: map[string]string{}}},
+		{In: "text/html; title*=utf-8''%E2%82%AC", Expected: Acceptable{Value: "text/html", Quality: 1, Params: map[string]string{"title": "€"}}},
+		{In: "text/html; q=1.5", Err: true},
+		{In: "text/html; level", Err: true},
+		{In: "text/html; a=1; a=2", Err: true},
+		{In: "text / html", Err: true},
+		{In: "", Err: true},
+	}
+
+	for i, tcase := range tcases {
+		t.Run(fmt.Sprintf("%d", i), func(t *testing.T) {
+			acc, err := ParseAcceptable(tcase.In)
+			if tcase.Err {
+				if err == nil {
+					t.Fatalf("expected error, got %v", acc)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("expected no error, got %v", err)
+			}
+			if !reflect.DeepEqual(acc, tcase.Expected) {
+				t.Fatalf("expected %#v, got %#v", tcase.Expected, acc)
+			}
+		})
+	}
+}
+
+// FuzzParseAcceptable checks that the parser is equivalent to parsing with
+// mime.ParseMediaType.
+func FuzzParseAcceptable(f *testing.F) {
+	for _, s := range acceptableSamples {
+		f.Add(s)
+	}
+	f.Fuzz(func(t *testing.T, s string) {
+		if _, err := parseAcceptable(s); err == errSlowPath {
+			return
+		}
+		got, err := ParseAcceptable(s)
+		expected, expectedErr := parseAcceptableMIME(s)
+		if (err == nil) != (expectedErr == nil) {
+			t.Fatalf("%q: expected error %v, got %v", s, expectedErr, err)
+		}
+		if !reflect.DeepEqual(got, expected) {
+			t.Fatalf("%q: expected %#v, got %#v", s, expected, got)
+		}
+	})
+}
+
+func BenchmarkParseAcceptable(b *testing.B) {
+	values := []string{"text/html", "application/xhtml+xml", "application/xml;q=0.9", "image/avif", "*/*;q=0.8"}
+
+	for _, bench := range []struct {
+		Name  string
+		Parse func(string) (Acceptable, error)
+	}{
+		{Name: "htutil", Parse: ParseAcceptable},
+		{Name: "mime", Parse: parseAcceptableMIME},
+	} {
+		b.Run(bench.Name, func(b *testing.B) {
+			b.ReportAllocs()
+			for i := 0; i < b.N; i++ {
+				for _, v := range values {
+					bench.Parse(v)
+				}
+			}
+		})
+	}
+}
+
+// parseAcceptSplit is the former implementation of ParseAccept, splitting
+// the fields with SplitList and sorting with a closure, kept to compare
+// against in benchmarks. It sorts stably, as ParseAccept now does.
+func parseAcceptSplit(accepts ...string) []Acceptable {
+	var types []Acceptable
+	for _, accept := range accepts {
+		for _, value := range SplitList(accept) {
+			acc, err := ParseAcceptable(value)
+			if err != nil {
+				continue
+			}
+			types = append(types, acc)
+		}
+	}
+	sort.SliceStable(types, func(i, j int) bool { return Acceptable.Less(types[i], types[j]) })
+	return types
+}
+
+func TestParseAcceptEquivalence(t *testing.T) {
+	t.Parallel()
+
+	tcases := [][]string{
+		acceptableSamples,
+		{strings.Join(acceptableSamples, ", ")},
+		{"text/html,application/xhtml+xml,application/xml;q=0.9,image/avif,image/webp,*/*;q=0.8"},
+		{`text/plain; title="a, b", text/html`, "", " , ,", "*/*;q=0"},
+		{"a;q=0.1, b;q=0.1, c;q=0.1, d, e, f, g;q=0.5, h, i, j, k, l, m, n, o;q=0.1"},
+	}
+
+	for i, tcase := range tcases {
+		t.Run(fmt.Sprintf("%d", i), func(t *testing.T) {
+			expected := parseAcceptSplit(tcase...)
+			if got := ParseAccept(tcase...); !reflect.DeepEqual(got, expected) {
+				t.Fatalf("expected %v, got %v", expected, got)
+			}
+
+			buf := AcquireAcceptBuffer()
+			defer buf.Release()
+			got := buf.Parse(tcase...)
+			for i := range got {
+				if got[i].Params == nil {
+					got[i].Params = map[string]string{}
+				}
+			}
+			if len(expected) > 0 && !reflect.DeepEqual(got, expected) {
+				t.Fatalf("expected %v, got %v", expected, got)
+			}
+
+			prefix := []Acceptable{{Value: "x", Quality: 0}}
+			got = AppendAccept(prefix, tcase...)
+			if !reflect.DeepEqual(got[:1], prefix) || len(got[1:]) != len(expected) {
+				t.Fatalf("expected %v to be appended to %v, got %v", expected, prefix, got)
+			}
+		})
+	}
+}
+
+func TestForEachAcceptable(t *testing.T) {
+	t.Parallel()
+
+	var got []string
+	ForEachAcceptable([]string{"text/html;q=0.1, invalid/", "*/*, application/json"}, func(acc Acceptable) bool {
+		got = append(got, acc.Value)
+		return acc.Value != "*/*"
+	})
+	if expected := []string{"text/html", "*/*"}; !reflect.DeepEqual(got, expected) {
+		t.Fatalf("expected %v, got %v", expected, got)
+	}
+}
+
+// TestParseAcceptAllocs is not parallel, as allocations are counted
+// process-wide.
+func TestParseAcceptAllocs(t *testing.T) {
+	accept := []string{"text/html,application/xhtml+xml,application/xml;q=0.9,image/avif,image/webp,*/*;q=0.8"}
+
+	allocs := testing.AllocsPerRun(100, func() {
+		buf := AcquireAcceptBuffer()
+		buf.Parse(accept...)
+		buf.Release()
+	})
+	if allocs != 0 {
+		t.Fatalf("expected no allocations, got %v", allocs)
+	}
+	allocs = testing.AllocsPerRun(100, func() {
+		ForEachAcceptable(accept, func(acc Acceptable) bool { return true })
+	})
+	if allocs != 0 {
+		t.Fatalf("expected no allocations, got %v", allocs)
+	}
+}
+
+func BenchmarkParseAccept(b *testing.B) {
+	accept := []string{"text/html,application/xhtml+xml,application/xml;q=0.9,image/avif,image/webp,*/*;q=0.8"}
+
+	b.Run("split", func(b *testing.B) {
+		b.ReportAllocs()
+		for i := 0; i < b.N; i++ {
+			parseAcceptSplit(accept...)
+		}
+	})
+	b.Run("ParseAccept", func(b *testing.B) {
+		b.ReportAllocs()
+		for i := 0; i < b.N; i++ {
+			ParseAccept(accept...)
+		}
+	})
+	b.Run("AcceptBuffer", func(b *testing.B) {
+		b.ReportAllocs()
+		for i := 0; i < b.N; i++ {
+			buf := AcquireAcceptBuffer()
+			buf.Parse(accept...)
+			buf.Release()
+		}
+	})
+	b.Run("ForEachAcceptable", func(b *testing.B) {
+		b.ReportAllocs()
+		for i := 0; i < b.N; i++ {
+			ForEachAcceptable(accept, func(acc Acceptable) bool { return true })
+		}
+	})
+}
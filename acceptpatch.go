@@ -0,0 +1,140 @@
+// Copyright 2026 Franklin "Snaipe" Mathieu.
+//
+// Use of this source code is governed by the MIT license that can be
+// found in the LICENSE file.
+
+package htutil
+
+import (
+	"fmt"
+	"net/http"
+	"strings"
+)
+
+// SetAcceptPatch sets the Accept-Patch header field of h, advertising the
+// media types of the patch documents accepted by PATCH requests, as per
+// RFC 5789 §3.1.
+func SetAcceptPatch(h http.Header, mediaTypes ...string) {
+	h.Set("Accept-Patch", strings.Join(mediaTypes, ", "))
+}
+
+// SetAcceptPost sets the Accept-Post header field of h, advertising the
+// media types of the content accepted by POST requests, as per the W3C
+// Linked Data Platform.
+func SetAcceptPost(h http.Header, mediaTypes ...string) {
+	h.Set("Accept-Post", strings.Join(mediaTypes, ", "))
+}
+
+// UnsupportedMediaTypeError is the error of requests whose content is not
+// of an accepted media type. It unwraps to a 415 Unsupported Media Type
+// problem, and RespondError advertises the accepted media types in the
+// response with the header field Field.
+type UnsupportedMediaTypeError struct {
+	// Field is the header field advertising the accepted media types, like
+	// Accept-Patch or Accept-Post, or "" if they are not advertised.
+	Field string
+
+	// MediaType is the media type of the request content, or "" if it has
+	// no valid media type.
+	MediaType string
+
+	// Accepted are the accepted media types.
+	Accepted []string
+}
+
+func (e *UnsupportedMediaTypeError) Error() string {
+	mediaType := e.MediaType
+	if mediaType == "" {
+		mediaType = "no media type"
+	}
+	return fmt.Sprintf("unsupported content of %s, accepted: %s", mediaType, strings.Join(e.Accepted, ", "))
+}
+
+// Unwrap returns the 415 Unsupported Media Type problem describing e.
+func (e *UnsupportedMediaTypeError) Unwrap() error {
+	if e.MediaType == "" {
+		return NewProblem(http.StatusUnsupportedMediaType, "The request content has no valid media type.")
+	}
+	return NewProblem(http.StatusUnsupportedMediaType,
+		fmt.Sprintf("Content of type %s is not supported.", e.MediaType))
+}
+
+func (e *UnsupportedMediaTypeError) setResponseHeader(h http.Header) {
+	if e.Field != "" {
+		h.Set(e.Field, strings.Join(e.Accepted, ", "))
+	}
+}
+
+// CheckPatchContentType returns an *UnsupportedMediaTypeError if the
+// content of the PATCH request req is not of one of the media types
+// offers, as advertised with SetAcceptPatch. Offers may be media ranges,
+// like application/*, and their parameters must be present in the
+// Content-Type of req. Requests without content are accepted.
+func CheckPatchContentType(req *http.Request, offers ...string) error {
+	return checkContentType(req, "Accept-Patch", offers)
+}
+
+// CheckPostContentType is like CheckPatchContentType, for POST requests
+// and the media types advertised with SetAcceptPost.
+func CheckPostContentType(req *http.Request, offers ...string) error {
+	return checkContentType(req, "Accept-Post", offers)
+}
+
+func checkContentType(req *http.Request, field string, offers []string) error {
+	if !hasBody(req) {
+		return nil
+	}
+	err := &UnsupportedMediaTypeError{Field: field, Accepted: offers}
+	mt, perr := ParseMediaType(req.Header.Get("Content-Type"))
+	if perr != nil || mt.Type == "*" || mt.Subtype == "*" {
+		return err
+	}
+	for _, offer := range offers {
+		if pattern, perr := ParseMediaType(offer); perr == nil && mt.Match(pattern) {
+			return nil
+		}
+	}
+	err.MediaType = mt.Essence()
+	return err
+}
+
+// AcceptedContent is a middleware enforcing, and advertising, the media
+// types of the content of PATCH and POST requests.
+type AcceptedContent struct {
+	// Patch are the media types accepted by PATCH requests. If empty, PATCH
+	// requests are not checked.
+	Patch []string
+
+	// Post are the media types accepted by POST requests. If empty, POST
+	// requests are not checked.
+	Post []string
+}
+
+// Middleware returns a middleware checking the content of the PATCH and
+// POST requests served by next, with CheckPatchContentType and
+// CheckPostContentType, and answering the ones of other media types with
+// a 415 Unsupported Media Type problem. The accepted media types are
+// advertised in responses to OPTIONS requests, and in 415 responses.
+func (a *AcceptedContent) Middleware(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+		var err error
+		switch {
+		case req.Method == http.MethodOptions:
+			if len(a.Patch) > 0 {
+				SetAcceptPatch(w.Header(), a.Patch...)
+			}
+			if len(a.Post) > 0 {
+				SetAcceptPost(w.Header(), a.Post...)
+			}
+		case req.Method == http.MethodPatch && len(a.Patch) > 0:
+			err = CheckPatchContentType(req, a.Patch...)
+		case req.Method == http.MethodPost && len(a.Post) > 0:
+			err = CheckPostContentType(req, a.Post...)
+		}
+		if err != nil {
+			RespondError(w, req, err)
+			return
+		}
+		next.ServeHTTP(w, req)
+	})
+}
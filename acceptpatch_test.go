@@ -0,0 +1,108 @@
+// Copyright 2026 Franklin "Snaipe" Mathieu.
+//
+// Use of this source code is governed by the MIT license that can be
+// found in the LICENSE file.
+
+package htutil
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+func TestCheckPatchContentType(t *testing.T) {
+	t.Parallel()
+
+	offers := []string{"application/merge-patch+json", "application/json-patch+json", "text/*;charset=utf-8"}
+
+	tcases := []struct {
+		ContentType string
+		Body        string
+		MediaType   string
+		Err         bool
+	}{
+		{ContentType: "application/merge-patch+json", Body: "{}"},
+		{ContentType: "Application/JSON-Patch+JSON; charset=utf-8", Body: "[]"},
+		{ContentType: "text/plain; charset=UTF-8", Body: "x"},
+		{ContentType: "text/plain", Body: "x", MediaType: "text/plain", Err: true},
+		{ContentType: "application/json", Body: "{}", MediaType: "application/json", Err: true},
+		{ContentType: "", Body: "{}", Err: true},
+		{ContentType: "*/*", Body: "{}", Err: true},
+		{ContentType: "", Body: ""},
+	}
+
+	for i, tcase := range tcases {
+		t.Run(fmt.Sprintf("%d", i), func(t *testing.T) {
+			req := httptest.NewRequest("PATCH", "/", strings.NewReader(tcase.Body))
+			if tcase.ContentType != "" {
+				req.Header.Set("Content-Type", tcase.ContentType)
+			}
+			err := CheckPatchContentType(req, offers...)
+			if (err != nil) != tcase.Err {
+				t.Fatalf("expected error %v, got %v", tcase.Err, err)
+			}
+			if err == nil {
+				return
+			}
+			var uerr *UnsupportedMediaTypeError
+			if !errors.As(err, &uerr) || uerr.Field != "Accept-Patch" || uerr.MediaType != tcase.MediaType {
+				t.Fatalf("expected an Accept-Patch error for %q, got %#v", tcase.MediaType, err)
+			}
+			var p *Problem
+			if !errors.As(err, &p) || p.Status != http.StatusUnsupportedMediaType {
+				t.Fatalf("expected a 415 problem, got %v", err)
+			}
+		})
+	}
+}
+
+func TestAcceptedContent(t *testing.T) {
+	t.Parallel()
+
+	handler := (&AcceptedContent{
+		Patch: []string{"application/merge-patch+json"},
+		Post:  []string{"application/json", "multipart/form-data"},
+	}).Middleware(http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+		w.WriteHeader(http.StatusNoContent)
+	}))
+
+	tcases := []struct {
+		Method      string
+		ContentType string
+		Status      int
+		AcceptPatch string
+		AcceptPost  string
+	}{
+		{Method: "OPTIONS", Status: 204, AcceptPatch: "application/merge-patch+json", AcceptPost: "application/json, multipart/form-data"},
+		{Method: "PATCH", ContentType: "application/merge-patch+json", Status: 204},
+		{Method: "PATCH", ContentType: "application/json", Status: 415, AcceptPatch: "application/merge-patch+json"},
+		{Method: "POST", ContentType: "application/json", Status: 204},
+		{Method: "POST", ContentType: "text/plain", Status: 415, AcceptPost: "application/json, multipart/form-data"},
+		{Method: "PUT", ContentType: "text/plain", Status: 204},
+	}
+
+	for i, tcase := range tcases {
+		t.Run(fmt.Sprintf("%d", i), func(t *testing.T) {
+			req := httptest.NewRequest(tcase.Method, "/", strings.NewReader("content"))
+			if tcase.ContentType != "" {
+				req.Header.Set("Content-Type", tcase.ContentType)
+			}
+			rw := httptest.NewRecorder()
+			handler.ServeHTTP(rw, req)
+
+			if rw.Code != tcase.Status {
+				t.Fatalf("expected status %d, got %d", tcase.Status, rw.Code)
+			}
+			if v := rw.Header().Get("Accept-Patch"); v != tcase.AcceptPatch {
+				t.Fatalf("expected Accept-Patch %q, got %q", tcase.AcceptPatch, v)
+			}
+			if v := rw.Header().Get("Accept-Post"); v != tcase.AcceptPost {
+				t.Fatalf("expected Accept-Post %q, got %q", tcase.AcceptPost, v)
+			}
+		})
+	}
+}
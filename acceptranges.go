@@ -0,0 +1,116 @@
+// Copyright 2026 Franklin "Snaipe" Mathieu.
+//
+// Use of this source code is governed by the MIT license that can be
+// found in the LICENSE file.
+
+package htutil
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+)
+
+// SetAcceptRanges advertises the range units supported for the target
+// resource via the Accept-Ranges header field, as per RFC 9110 §14.3.
+// Calling SetAcceptRanges with no units advertises "none".
+func SetAcceptRanges(h http.Header, units ...string) {
+	if len(units) == 0 {
+		h.Set("Accept-Ranges", "none")
+		return
+	}
+	h.Set("Accept-Ranges", strings.Join(units, ", "))
+}
+
+// AcceptsRanges returns whether the Accept-Ranges header field of h
+// advertises support for the specified range unit. Range units are
+// compared case-insensitively.
+func AcceptsRanges(h http.Header, unit string) bool {
+	for _, v := range h.Values("Accept-Ranges") {
+		for _, u := range SplitList(v) {
+			if strings.EqualFold(u, unit) {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// RangeSupport is the result of probing an origin for byte range support.
+type RangeSupport struct {
+	// Supported is true if the origin serves byte ranges for the resource.
+	Supported bool
+
+	// Length is the length of the representation, or -1 if unknown.
+	Length int64
+
+	// ETag and LastModified are the validators of the representation, if
+	// any, to be sent in If-Range when requesting ranges so as to detect
+	// changes of the representation across requests.
+	ETag         string
+	LastModified string
+}
+
+// ProbeRanges determines whether the origin serving url supports byte
+// ranges, for instance before attempting a parallel download.
+//
+// It first sends a HEAD request and inspects its Accept-Ranges header.
+// Since origins are allowed to serve ranges without advertising it, if
+// the HEAD response does not mention Accept-Ranges at all, ProbeRanges
+// falls back to requesting the first byte of the representation. If
+// client is nil, http.DefaultClient is used.
+func ProbeRanges(ctx context.Context, client *http.Client, url string) (RangeSupport, error) {
+	if client == nil {
+		client = http.DefaultClient
+	}
+
+	do := func(method string, hdr http.Header) (*http.Response, error) {
+		req, err := http.NewRequestWithContext(ctx, method, url, nil)
+		if err != nil {
+			return nil, err
+		}
+		for k, v := range hdr {
+			req.Header[k] = v
+		}
+		resp, err := client.Do(req)
+		if err != nil {
+			return nil, err
+		}
+		io.Copy(io.Discard, io.LimitReader(resp.Body, 4096))
+		resp.Body.Close()
+		return resp, nil
+	}
+
+	resp, err := do(http.MethodHead, nil)
+	if err != nil {
+		return RangeSupport{}, fmt.Errorf("probing ranges: %w", err)
+	}
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		return RangeSupport{}, fmt.Errorf("probing ranges: HEAD %s: %s", url, resp.Status)
+	}
+
+	support := RangeSupport{
+		Length:       resp.ContentLength,
+		ETag:         resp.Header.Get("ETag"),
+		LastModified: resp.Header.Get("Last-Modified"),
+	}
+	if len(resp.Header.Values("Accept-Ranges")) > 0 {
+		support.Supported = AcceptsRanges(resp.Header, "bytes")
+		return support, nil
+	}
+
+	resp, err = do(http.MethodGet, http.Header{"Range": {"bytes=0-0"}})
+	if err != nil {
+		return RangeSupport{}, fmt.Errorf("probing ranges: %w", err)
+	}
+	if resp.StatusCode == http.StatusPartialContent {
+		support.Supported = true
+		// Content-Range: bytes 0-0/<length>
+		if cr, err := ParseContentRange(resp.Header.Get("Content-Range")); err == nil && cr.Length >= 0 {
+			support.Length = cr.Length
+		}
+	}
+	return support, nil
+}
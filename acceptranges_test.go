@@ -0,0 +1,87 @@
+// Copyright 2026 Franklin "Snaipe" Mathieu.
+//
+// Use of this source code is governed by the MIT license that can be
+// found in the LICENSE file.
+
+package htutil
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestProbeRanges(t *testing.T) {
+	t.Parallel()
+
+	tcases := []struct {
+		Handler   http.HandlerFunc
+		Supported bool
+		Length    int64
+	}{
+		{
+			// http.ServeContent advertises Accept-Ranges: bytes
+			Handler: func(w http.ResponseWriter, req *http.Request) {
+				http.ServeContent(w, req, "", time.Time{}, strings.NewReader("hello, world"))
+			},
+			Supported: true,
+			Length:    12,
+		},
+		{
+			Handler: func(w http.ResponseWriter, req *http.Request) {
+				SetAcceptRanges(w.Header())
+				fmt.Fprint(w, "hello, world")
+			},
+			Supported: false,
+			Length:    12,
+		},
+		{
+			// Does not advertise, but supports ranges nonetheless
+			Handler: func(w http.ResponseWriter, req *http.Request) {
+				if req.Method == http.MethodHead {
+					w.Header().Set("Content-Length", "12")
+					return
+				}
+				http.ServeContent(w, req, "", time.Time{}, strings.NewReader("hello, world"))
+			},
+			Supported: true,
+			Length:    12,
+		},
+	}
+
+	for i, tcase := range tcases {
+		t.Run(fmt.Sprintf("%d", i), func(t *testing.T) {
+			srv := httptest.NewServer(tcase.Handler)
+			defer srv.Close()
+
+			support, err := ProbeRanges(context.Background(), srv.Client(), srv.URL)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if support.Supported != tcase.Supported {
+				t.Fatalf("expected supported %v, got %v", tcase.Supported, support.Supported)
+			}
+			if support.Length != tcase.Length {
+				t.Fatalf("expected length %d, got %d", tcase.Length, support.Length)
+			}
+		})
+	}
+}
+
+func TestAcceptsRanges(t *testing.T) {
+	t.Parallel()
+
+	h := http.Header{}
+	SetAcceptRanges(h, "bytes", "items")
+	if !AcceptsRanges(h, "Bytes") || !AcceptsRanges(h, "items") || AcceptsRanges(h, "none") {
+		t.Fatalf("unexpected Accept-Ranges handling for %v", h)
+	}
+	SetAcceptRanges(h)
+	if AcceptsRanges(h, "bytes") {
+		t.Fatalf("expected none to not accept bytes")
+	}
+}
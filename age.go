@@ -0,0 +1,90 @@
+// Copyright 2026 Franklin "Snaipe" Mathieu.
+//
+// Use of this source code is governed by the MIT license that can be
+// found in the LICENSE file.
+
+package htutil
+
+import (
+	"net/http"
+	"time"
+)
+
+// maxAge is the largest Age value, as per RFC 9111 §1.2.2.
+const maxAge = 2147483648 * time.Second
+
+// ResponseAge holds the values from which caches calculate the age of a
+// stored response, as per RFC 9111 §4.2.3.
+type ResponseAge struct {
+	// RequestTime is the time at which the request that resulted in the
+	// stored response was sent.
+	RequestTime time.Time
+
+	// ResponseTime is the time at which the response was received.
+	ResponseTime time.Time
+
+	// Date is the value of the Date header field of the response, or
+	// zero if absent.
+	Date time.Time
+
+	// Age is the value of the Age header field of the response.
+	Age time.Duration
+}
+
+// NewResponseAge returns the age values of a response with the header
+// fields h, for a request sent at requestTime and answered at responseTime.
+func NewResponseAge(h http.Header, requestTime, responseTime time.Time) ResponseAge {
+	date, _ := GetHeader[time.Time](h, "Date")
+	age, _ := GetHeader[time.Duration](h, "Age")
+	return ResponseAge{
+		RequestTime:  requestTime,
+		ResponseTime: responseTime,
+		Date:         date,
+		Age:          age,
+	}
+}
+
+// ApparentAge returns the age of the response as apparent from its Date,
+// which is 0 if the response has no Date, or comes from a clock ahead of
+// the one of the cache.
+func (a ResponseAge) ApparentAge() time.Duration {
+	if a.Date.IsZero() {
+		return 0
+	}
+	return maxDuration(0, a.ResponseTime.Sub(a.Date))
+}
+
+// CorrectedInitialAge returns the age of the response when it was received,
+// as the largest of its apparent age, and of its Age corrected for the
+// delay of the response.
+func (a ResponseAge) CorrectedInitialAge() time.Duration {
+	responseDelay := a.ResponseTime.Sub(a.RequestTime)
+	return maxDuration(a.ApparentAge(), a.Age+responseDelay)
+}
+
+// CurrentAge returns the age of the response at now, which is its corrected
+// initial age, plus the time it has been resident in the cache.
+func (a ResponseAge) CurrentAge(now time.Time) time.Duration {
+	residentTime := now.Sub(a.ResponseTime)
+	return a.CorrectedInitialAge() + residentTime
+}
+
+func maxDuration(lhs, rhs time.Duration) time.Duration {
+	if lhs > rhs {
+		return lhs
+	}
+	return rhs
+}
+
+// SetAge sets the Age header field of h to age, in seconds, as caches do
+// when serving stored responses. The age is truncated to the second, and
+// clamped to the range allowed by RFC 9111 §5.1.
+func SetAge(h http.Header, age time.Duration) {
+	if age < 0 {
+		age = 0
+	}
+	if age > maxAge {
+		age = maxAge
+	}
+	SetHeader(h, "Age", age)
+}
@@ -0,0 +1,94 @@
+// Copyright 2026 Franklin "Snaipe" Mathieu.
+//
+// Use of this source code is governed by the MIT license that can be
+// found in the LICENSE file.
+
+package htutil
+
+import (
+	"fmt"
+	"net/http"
+	"testing"
+	"time"
+)
+
+func TestResponseAge(t *testing.T) {
+	t.Parallel()
+
+	requestTime := time.Date(2026, 1, 2, 3, 4, 0, 0, time.UTC)
+	responseTime := requestTime.Add(2 * time.Second)
+	now := responseTime.Add(time.Minute)
+
+	tcases := []struct {
+		Header   http.Header
+		Apparent time.Duration
+		Initial  time.Duration
+		Current  time.Duration
+	}{
+		{
+			// Origin response, dated when it was generated.
+			Header:   http.Header{"Date": {"Fri, 02 Jan 2026 03:04:01 GMT"}},
+			Apparent: time.Second,
+			Initial:  2 * time.Second,
+			Current:  62 * time.Second,
+		},
+		{
+			// Response from an upstream cache, with an Age.
+			Header:   http.Header{"Date": {"Fri, 02 Jan 2026 03:03:00 GMT"}, "Age": {"30"}},
+			Apparent: 62 * time.Second,
+			Initial:  62 * time.Second,
+			Current:  122 * time.Second,
+		},
+		{
+			// Clock of the origin ahead of ours.
+			Header:   http.Header{"Date": {"Fri, 02 Jan 2026 03:10:00 GMT"}, "Age": {"10"}},
+			Apparent: 0,
+			Initial:  12 * time.Second,
+			Current:  72 * time.Second,
+		},
+		{
+			Header:   http.Header{},
+			Apparent: 0,
+			Initial:  2 * time.Second,
+			Current:  62 * time.Second,
+		},
+	}
+
+	for i, tcase := range tcases {
+		t.Run(fmt.Sprintf("%d", i), func(t *testing.T) {
+			age := NewResponseAge(tcase.Header, requestTime, responseTime)
+			if v := age.ApparentAge(); v != tcase.Apparent {
+				t.Fatalf("expected apparent age %v, got %v", tcase.Apparent, v)
+			}
+			if v := age.CorrectedInitialAge(); v != tcase.Initial {
+				t.Fatalf("expected corrected initial age %v, got %v", tcase.Initial, v)
+			}
+			if v := age.CurrentAge(now); v != tcase.Current {
+				t.Fatalf("expected current age %v, got %v", tcase.Current, v)
+			}
+		})
+	}
+}
+
+func TestSetAge(t *testing.T) {
+	t.Parallel()
+
+	tcases := []struct {
+		Age      time.Duration
+		Expected string
+	}{
+		{Age: 1500 * time.Millisecond, Expected: "1"},
+		{Age: -time.Second, Expected: "0"},
+		{Age: 100 * 365 * 24 * time.Hour, Expected: "2147483648"},
+	}
+
+	for i, tcase := range tcases {
+		t.Run(fmt.Sprintf("%d", i), func(t *testing.T) {
+			h := http.Header{}
+			SetAge(h, tcase.Age)
+			if v := h.Get("Age"); v != tcase.Expected {
+				t.Fatalf("expected %v, got %v", tcase.Expected, v)
+			}
+		})
+	}
+}
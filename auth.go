@@ -0,0 +1,311 @@
+// Copyright 2026 Franklin "Snaipe" Mathieu.
+//
+// Use of this source code is governed by the MIT license that can be
+// found in the LICENSE file.
+
+package htutil
+
+import (
+	"encoding/base64"
+	"fmt"
+	"sort"
+	"strings"
+)
+
+// Challenge is an authentication challenge of a WWW-Authenticate or
+// Proxy-Authenticate header field, as per RFC 9110 §11.3.
+type Challenge struct {
+	// Scheme is the authentication scheme, like "Basic" or "Bearer".
+	// Schemes are case-insensitive.
+	Scheme string
+
+	// Token68 is the token68 of the challenge, if any. Challenges have
+	// either a token68 or parameters.
+	Token68 string
+
+	// Params contains the auth-params of the challenge, with lowercased
+	// names and unquoted values.
+	Params map[string]string
+}
+
+// Credentials are the credentials of an Authorization or
+// Proxy-Authorization header field, as per RFC 9110 §11.4.
+type Credentials struct {
+	// Scheme is the authentication scheme, like "Basic" or "Bearer".
+	// Schemes are case-insensitive.
+	Scheme string
+
+	// Token68 is the token68 of the credentials, if any, like the token of
+	// Bearer credentials. Credentials have either a token68 or
+	// parameters.
+	Token68 string
+
+	// Params contains the auth-params of the credentials, with lowercased
+	// names and unquoted values.
+	Params map[string]string
+}
+
+// String returns the challenge as formatted in a WWW-Authenticate header
+// field.
+func (c Challenge) String() string {
+	return formatAuth(c.Scheme, c.Token68, c.Params, challengeTokenParams)
+}
+
+// String returns the credentials as formatted in an Authorization header
+// field.
+func (c Credentials) String() string {
+	return formatAuth(c.Scheme, c.Token68, c.Params, credentialsTokenParams)
+}
+
+// Parameters whose values are sent as tokens rather than quoted strings,
+// as per RFC 7616 §3.3 and §3.4. Other parameters are always quoted, as
+// many implementations expect, like for realm.
+var (
+	challengeTokenParams   = []string{"algorithm", "stale", "userhash"}
+	credentialsTokenParams = []string{"algorithm", "nc", "qop", "userhash"}
+)
+
+func formatAuth(scheme, token68 string, params map[string]string, tokenParams []string) string {
+	var out strings.Builder
+	out.WriteString(scheme)
+	if token68 != "" {
+		out.WriteString(" " + token68)
+		return out.String()
+	}
+	names := make([]string, 0, len(params))
+	for name := range params {
+		if name != "realm" {
+			names = append(names, name)
+		}
+	}
+	sort.Strings(names)
+	if _, ok := params["realm"]; ok {
+		names = append([]string{"realm"}, names...)
+	}
+	for i, name := range names {
+		if i == 0 {
+			out.WriteByte(' ')
+		} else {
+			out.WriteString(", ")
+		}
+		value := params[name]
+		if containsString(tokenParams, name) && IsToken(value) {
+			out.WriteString(name + "=" + value)
+		} else {
+			out.WriteString(name + "=" + QuoteString(value))
+		}
+	}
+	return out.String()
+}
+
+// FormatChallenges returns the WWW-Authenticate header value for the
+// specified challenges.
+func FormatChallenges(challenges ...Challenge) string {
+	out := make([]string, len(challenges))
+	for i, c := range challenges {
+		out[i] = c.String()
+	}
+	return strings.Join(out, ", ")
+}
+
+// ParseChallenges parses the values of WWW-Authenticate or
+// Proxy-Authenticate header fields, and returns their challenges in order.
+func ParseChallenges(values ...string) ([]Challenge, error) {
+	var challenges []Challenge
+	for _, value := range values {
+		for _, elem := range splitUnquoted(value, ',') {
+			elem = trimOWS(elem)
+			if elem == "" {
+				continue
+			}
+			scheme, rest, isParam := cutAuthScheme(elem)
+			if !isParam {
+				if !IsToken(scheme) {
+					return nil, fmt.Errorf("parsing challenge: invalid scheme %q", scheme)
+				}
+				challenges = append(challenges, Challenge{Scheme: scheme})
+				if rest == "" {
+					continue
+				}
+			}
+			if len(challenges) == 0 {
+				return nil, fmt.Errorf("parsing challenge: parameter %q without scheme", elem)
+			}
+			c := &challenges[len(challenges)-1]
+			if err := parseAuthParam(rest, &c.Token68, &c.Params, !isParam); err != nil {
+				return nil, fmt.Errorf("parsing challenge: %s: %w", c.Scheme, err)
+			}
+		}
+	}
+	return challenges, nil
+}
+
+// ParseCredentials parses the value of an Authorization or
+// Proxy-Authorization header field.
+func ParseCredentials(value string) (Credentials, error) {
+	var c Credentials
+	for i, elem := range splitUnquoted(value, ',') {
+		elem = trimOWS(elem)
+		if i == 0 {
+			scheme, rest, isParam := cutAuthScheme(elem)
+			if isParam || !IsToken(scheme) {
+				return Credentials{}, fmt.Errorf("parsing credentials: invalid scheme %q", scheme)
+			}
+			c.Scheme, elem = scheme, rest
+			if elem == "" {
+				continue
+			}
+		} else if elem == "" {
+			continue
+		}
+		if err := parseAuthParam(elem, &c.Token68, &c.Params, i == 0); err != nil {
+			return Credentials{}, fmt.Errorf("parsing credentials: %w", err)
+		}
+	}
+	return c, nil
+}
+
+// cutAuthScheme splits the list element elem of an authentication field
+// into the scheme that it starts with, and the rest. It returns
+// isParam=true, and elem as rest, if elem is an auth-param continuing the
+// previous challenge.
+func cutAuthScheme(elem string) (scheme, rest string, isParam bool) {
+	i := strings.IndexAny(elem, " \t")
+	if i == -1 {
+		if strings.IndexByte(elem, '=') != -1 {
+			return "", elem, true
+		}
+		return elem, "", false
+	}
+	rest = trimOWS(elem[i:])
+	if strings.IndexByte(elem[:i], '=') != -1 || strings.HasPrefix(rest, "=") {
+		return "", elem, true
+	}
+	return elem[:i], rest, false
+}
+
+// parseAuthParam parses s, an auth-param, or a token68 if first is true,
+// into token68 or params.
+func parseAuthParam(s string, token68 *string, params *map[string]string, first bool) error {
+	if *token68 != "" {
+		return fmt.Errorf("unexpected %q after token68", s)
+	}
+	name, value, ok := strings.Cut(s, "=")
+	name, value = strings.ToLower(trimOWS(name)), trimOWS(value)
+	if !ok || !IsToken(name) || value == "" {
+		if first && *params == nil && isToken68(s) {
+			*token68 = s
+			return nil
+		}
+		return fmt.Errorf("invalid parameter %q", s)
+	}
+	if strings.HasPrefix(value, `"`) {
+		unquoted, err := UnquoteString(value)
+		if err != nil {
+			return err
+		}
+		value = unquoted
+	} else if !IsToken(value) {
+		if first && *params == nil && isToken68(s) {
+			*token68 = s
+			return nil
+		}
+		return fmt.Errorf("invalid parameter %q", s)
+	}
+	if *params == nil {
+		*params = make(map[string]string)
+	}
+	if _, dup := (*params)[name]; dup {
+		return fmt.Errorf("duplicate parameter %q", name)
+	}
+	(*params)[name] = value
+	return nil
+}
+
+// isToken68 returns whether s is a token68, as per RFC 9110 §11.2.
+func isToken68(s string) bool {
+	end := len(strings.TrimRight(s, "="))
+	if end == 0 {
+		return false
+	}
+	for i := 0; i < end; i++ {
+		c := s[i]
+		if !isAlpha(c) && !isDigit(c) && strings.IndexByte("-._~+/", c) == -1 {
+			return false
+		}
+	}
+	return true
+}
+
+// BasicChallenge returns a Basic challenge for the specified realm, as per
+// RFC 7617, announcing that credentials are encoded in UTF-8.
+func BasicChallenge(realm string) Challenge {
+	return Challenge{Scheme: "Basic", Params: map[string]string{"realm": realm, "charset": "UTF-8"}}
+}
+
+// BasicCredentials returns Basic credentials for the specified user and
+// password, as per RFC 7617.
+func BasicCredentials(user, password string) Credentials {
+	return Credentials{Scheme: "Basic", Token68: base64.StdEncoding.EncodeToString([]byte(user + ":" + password))}
+}
+
+// Basic returns the user and password of Basic credentials, or ok=false if
+// c are not valid Basic credentials.
+func (c Credentials) Basic() (user, password string, ok bool) {
+	if !strings.EqualFold(c.Scheme, "Basic") {
+		return "", "", false
+	}
+	decoded, err := base64.StdEncoding.DecodeString(c.Token68)
+	if err != nil {
+		return "", "", false
+	}
+	return strings.Cut(string(decoded), ":")
+}
+
+// BearerChallenge returns a Bearer challenge, as per RFC 6750 §3, for the
+// specified realm and scopes. errorCode, like "invalid_token", and
+// description report why the request was rejected, and are omitted if
+// empty, as are realm and scope.
+func BearerChallenge(realm string, scope []string, errorCode, description string) Challenge {
+	params := make(map[string]string)
+	set := func(name, value string) {
+		if value != "" {
+			params[name] = value
+		}
+	}
+	set("realm", realm)
+	set("scope", strings.Join(scope, " "))
+	set("error", errorCode)
+	set("error_description", description)
+	if len(params) == 0 {
+		params = nil
+	}
+	return Challenge{Scheme: "Bearer", Params: params}
+}
+
+// BearerCredentials returns Bearer credentials for the specified access
+// token, as per RFC 6750 §2.1.
+func BearerCredentials(token string) Credentials {
+	return Credentials{Scheme: "Bearer", Token68: token}
+}
+
+// DigestChallenge returns a Digest challenge, as per RFC 7616 §3.3, for the
+// specified realm, server nonce, and opaque value, with the SHA-256
+// algorithm and the auth quality of protection. stale reports that the
+// request was rejected only because its nonce was stale.
+func DigestChallenge(realm, nonce, opaque string, stale bool) Challenge {
+	params := map[string]string{
+		"realm":     realm,
+		"nonce":     nonce,
+		"qop":       "auth",
+		"algorithm": "SHA-256",
+		"charset":   "UTF-8",
+	}
+	if opaque != "" {
+		params["opaque"] = opaque
+	}
+	if stale {
+		params["stale"] = "true"
+	}
+	return Challenge{Scheme: "Digest", Params: params}
+}
@@ -0,0 +1,154 @@
+// Copyright 2026 Franklin "Snaipe" Mathieu.
+//
+// Use of this source code is governed by the MIT license that can be
+// found in the LICENSE file.
+
+package htutil
+
+import (
+	"fmt"
+	"reflect"
+	"testing"
+)
+
+func TestParseChallenges(t *testing.T) {
+	t.Parallel()
+
+	tcases := []struct {
+		Values     []string
+		Challenges []Challenge
+		Err        bool
+	}{
+		{Values: nil, Challenges: nil},
+		{Values: []string{"Basic"}, Challenges: []Challenge{{Scheme: "Basic"}}},
+		{Values: []string{`Basic realm="simple"`}, Challenges: []Challenge{{Scheme: "Basic", Params: map[string]string{"realm": "simple"}}}},
+		// The example of RFC 9110 §11.6.1.
+		{Values: []string{`Basic realm="simple", Newauth realm="apps", type=1, title="Login to \"apps\""`}, Challenges: []Challenge{
+			{Scheme: "Basic", Params: map[string]string{"realm": "simple"}},
+			{Scheme: "Newauth", Params: map[string]string{"realm": "apps", "type": "1", "title": `Login to "apps"`}},
+		}},
+		{Values: []string{`Bearer realm="a, b" , error = "invalid_token",, error_description="The token expired"`, "Negotiate"}, Challenges: []Challenge{
+			{Scheme: "Bearer", Params: map[string]string{"realm": "a, b", "error": "invalid_token", "error_description": "The token expired"}},
+			{Scheme: "Negotiate"},
+		}},
+		{Values: []string{"Negotiate YIIGhgYJKoZIhvcSAQICAQBuggZ1MIIGcaADAgEFoQMCAQ6iBwMFACAAAACjggS==, Basic realm=x"}, Challenges: []Challenge{
+			{Scheme: "Negotiate", Token68: "YIIGhgYJKoZIhvcSAQICAQBuggZ1MIIGcaADAgEFoQMCAQ6iBwMFACAAAACjggS=="},
+			{Scheme: "Basic", Params: map[string]string{"realm": "x"}},
+		}},
+		{Values: []string{"realm=x"}, Err: true},
+		{Values: []string{`Basic realm="x`}, Err: true},
+		{Values: []string{"Basic realm=x, realm=y"}, Err: true},
+		{Values: []string{"Negotiate abc, realm=x"}, Err: true},
+		{Values: []string{"Ba(sic realm=x"}, Err: true},
+		{Values: []string{"Basic realm=a b"}, Err: true},
+	}
+
+	for i, tcase := range tcases {
+		t.Run(fmt.Sprintf("%d", i), func(t *testing.T) {
+			challenges, err := ParseChallenges(tcase.Values...)
+			if (err != nil) != tcase.Err {
+				t.Fatalf("expected error %v, got %v", tcase.Err, err)
+			}
+			if !reflect.DeepEqual(challenges, tcase.Challenges) {
+				t.Fatalf("expected %#v, got %#v", tcase.Challenges, challenges)
+			}
+		})
+	}
+}
+
+func TestFormatChallenges(t *testing.T) {
+	t.Parallel()
+
+	tcases := []struct {
+		Challenges []Challenge
+		Expect     string
+	}{
+		{Challenges: []Challenge{BasicChallenge("simple")}, Expect: `Basic realm="simple", charset="UTF-8"`},
+		{Challenges: []Challenge{
+			BearerChallenge("api", []string{"read", "write"}, "insufficient_scope", `Needs "write"`),
+			{Scheme: "Negotiate", Token68: "abc=="},
+		}, Expect: `Bearer realm="api", error="insufficient_scope", error_description="Needs \"write\"", scope="read write", Negotiate abc==`},
+		{Challenges: []Challenge{BearerChallenge("", nil, "", "")}, Expect: "Bearer"},
+		{Challenges: []Challenge{DigestChallenge("http-auth@example.org", "7ypf/xlj9XXwfDPEoM4URrv/xwf94BcCAzFZH4GiTo0v", "FQhe/qaU925kfnzjCev0ciny7QMkPqMAFRtzCUYo5tdS", true)},
+			Expect: `Digest realm="http-auth@example.org", algorithm=SHA-256, charset="UTF-8", nonce="7ypf/xlj9XXwfDPEoM4URrv/xwf94BcCAzFZH4GiTo0v", opaque="FQhe/qaU925kfnzjCev0ciny7QMkPqMAFRtzCUYo5tdS", qop="auth", stale=true`},
+	}
+
+	for i, tcase := range tcases {
+		t.Run(fmt.Sprintf("%d", i), func(t *testing.T) {
+			out := FormatChallenges(tcase.Challenges...)
+			if out != tcase.Expect {
+				t.Fatalf("expected %q, got %q", tcase.Expect, out)
+			}
+			challenges, err := ParseChallenges(out)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if !reflect.DeepEqual(challenges, tcase.Challenges) {
+				t.Fatalf("expected %#v, got %#v", tcase.Challenges, challenges)
+			}
+		})
+	}
+}
+
+func TestParseCredentials(t *testing.T) {
+	t.Parallel()
+
+	tcases := []struct {
+		Value       string
+		Credentials Credentials
+		String      string
+		Err         bool
+	}{
+		{Value: "Bearer mF_9.B5f-4.1JqM", Credentials: Credentials{Scheme: "Bearer", Token68: "mF_9.B5f-4.1JqM"}, String: "Bearer mF_9.B5f-4.1JqM"},
+		{Value: "Basic dXNlcjpwYXNz", Credentials: Credentials{Scheme: "Basic", Token68: "dXNlcjpwYXNz"}, String: "Basic dXNlcjpwYXNz"},
+		{Value: `Digest username="Mufasa", realm="http-auth@example.org", uri="/dir/index.html", algorithm=SHA-256, nc=00000001, qop=auth, response="753927fa"`,
+			Credentials: Credentials{Scheme: "Digest", Params: map[string]string{
+				"username": "Mufasa", "realm": "http-auth@example.org", "uri": "/dir/index.html",
+				"algorithm": "SHA-256", "nc": "00000001", "qop": "auth", "response": "753927fa",
+			}},
+			String: `Digest realm="http-auth@example.org", algorithm=SHA-256, nc=00000001, qop=auth, response="753927fa", uri="/dir/index.html", username="Mufasa"`},
+		{Value: "Negotiate", Credentials: Credentials{Scheme: "Negotiate"}, String: "Negotiate"},
+		{Value: "", Err: true},
+		{Value: "token=abc", Err: true},
+		{Value: "Bearer abc, def", Err: true},
+		{Value: "Bearer a b", Err: true},
+	}
+
+	for i, tcase := range tcases {
+		t.Run(fmt.Sprintf("%d", i), func(t *testing.T) {
+			c, err := ParseCredentials(tcase.Value)
+			if (err != nil) != tcase.Err {
+				t.Fatalf("expected error %v, got %v", tcase.Err, err)
+			}
+			if err != nil {
+				return
+			}
+			if !reflect.DeepEqual(c, tcase.Credentials) {
+				t.Fatalf("expected %#v, got %#v", tcase.Credentials, c)
+			}
+			if s := c.String(); s != tcase.String {
+				t.Fatalf("expected %q, got %q", tcase.String, s)
+			}
+		})
+	}
+}
+
+func TestBasicCredentials(t *testing.T) {
+	t.Parallel()
+
+	c := BasicCredentials("Aladdin", "open: sesame")
+	if s := c.String(); s != "Basic QWxhZGRpbjpvcGVuOiBzZXNhbWU=" {
+		t.Fatalf("unexpected credentials %q", s)
+	}
+	parsed, err := ParseCredentials(c.String())
+	if err != nil {
+		t.Fatal(err)
+	}
+	user, password, ok := parsed.Basic()
+	if !ok || user != "Aladdin" || password != "open: sesame" {
+		t.Fatalf("expected Aladdin:open: sesame, got %q:%q (%v)", user, password, ok)
+	}
+	if _, _, ok := BearerCredentials("abc").Basic(); ok {
+		t.Fatalf("expected Bearer credentials not to be Basic")
+	}
+}
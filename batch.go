@@ -0,0 +1,281 @@
+// Copyright 2026 Franklin "Snaipe" Mathieu.
+//
+// Use of this source code is governed by the MIT license that can be
+// found in the LICENSE file.
+
+package htutil
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"mime"
+	"mime/multipart"
+	"net/http"
+	"net/textproto"
+	"strings"
+	"sync"
+)
+
+// BatchHandler serves batch requests, where a multipart/mixed request body
+// carries several serialized HTTP requests (one application/http part per
+// subrequest), in the fashion of OData and Google APIs batching.
+//
+// Each subrequest is dispatched to Handler, and the responses are sent back
+// as a multipart/mixed response, in the same order as the subrequests. If a
+// part has a Content-ID, the corresponding response part has the same
+// Content-ID, prefixed with "response-".
+//
+// Subrequests inherit the header fields of the batch request that they do
+// not set themselves, except for the representation metadata of the batch
+// request (Content-*), and hop-by-hop fields.
+type BatchHandler struct {
+	// Handler serves the subrequests.
+	Handler http.Handler
+
+	// MaxConcurrency is the maximum number of subrequests served at the
+	// same time. Defaults to 1, serving subrequests sequentially.
+	MaxConcurrency int
+
+	// MaxParts is the maximum number of subrequests in a batch. Defaults
+	// to 100.
+	MaxParts int
+
+	// MaxBodySize is the maximum size of the batch request body.
+	// Defaults to 10 MiB.
+	MaxBodySize int64
+}
+
+type batchPart struct {
+	contentID string
+	req       *http.Request
+	err       error
+	resp      *responseBuffer
+}
+
+func (b *BatchHandler) ServeHTTP(w http.ResponseWriter, req *http.Request) {
+	if req.Method != http.MethodPost {
+		w.Header().Set("Allow", http.MethodPost)
+		http.Error(w, http.StatusText(http.StatusMethodNotAllowed), http.StatusMethodNotAllowed)
+		return
+	}
+
+	ctype, params, err := mime.ParseMediaType(req.Header.Get("Content-Type"))
+	if err != nil || ctype != "multipart/mixed" || params["boundary"] == "" {
+		SetAcceptPost(w.Header(), "multipart/mixed")
+		http.Error(w, "batch requests must be multipart/mixed", http.StatusUnsupportedMediaType)
+		return
+	}
+
+	maxParts := b.MaxParts
+	if maxParts <= 0 {
+		maxParts = 100
+	}
+	maxSize := b.MaxBodySize
+	if maxSize <= 0 {
+		maxSize = 10 << 20
+	}
+
+	body := &io.LimitedReader{R: req.Body, N: maxSize + 1}
+	parts, err := readBatchParts(req, multipart.NewReader(body, params["boundary"]), maxParts)
+	if err != nil {
+		status := http.StatusBadRequest
+		if body.N <= 0 || errors.Is(err, errTooManyParts) {
+			status = http.StatusRequestEntityTooLarge
+		}
+		http.Error(w, err.Error(), status)
+		return
+	}
+
+	b.dispatch(parts)
+
+	mw := multipart.NewWriter(w)
+	w.Header().Set("Content-Type", mime.FormatMediaType("multipart/mixed", map[string]string{
+		"boundary": mw.Boundary(),
+	}))
+	w.WriteHeader(http.StatusOK)
+
+	for _, part := range parts {
+		hdr := textproto.MIMEHeader{}
+		hdr.Set("Content-Type", "application/http")
+		if part.contentID != "" {
+			hdr.Set("Content-ID", "<response-"+part.contentID+">")
+		}
+		pw, err := mw.CreatePart(hdr)
+		if err != nil {
+			return
+		}
+		resp := part.resp.response(part.req)
+		resp.Request = nil
+		if err := resp.Write(pw); err != nil {
+			return
+		}
+	}
+	mw.Close()
+}
+
+var errTooManyParts = errors.New("too many parts in batch request")
+
+func readBatchParts(outer *http.Request, mr *multipart.Reader, maxParts int) ([]*batchPart, error) {
+	var parts []*batchPart
+	for {
+		p, err := mr.NextPart()
+		if err == io.EOF {
+			return parts, nil
+		}
+		if err != nil {
+			return nil, fmt.Errorf("reading batch part: %w", err)
+		}
+		if len(parts) == maxParts {
+			return nil, errTooManyParts
+		}
+
+		part := &batchPart{
+			contentID: strings.Trim(p.Header.Get("Content-ID"), "<>"),
+			resp:      newResponseBuffer(),
+		}
+		parts = append(parts, part)
+
+		data, err := io.ReadAll(p)
+		if err != nil {
+			return nil, fmt.Errorf("reading batch part: %w", err)
+		}
+		if ct, _, _ := mime.ParseMediaType(p.Header.Get("Content-Type")); ct != "application/http" {
+			part.err = fmt.Errorf("batch part has content type %q, expected application/http", ct)
+			continue
+		}
+		sub, err := http.ReadRequest(bufio.NewReader(bytes.NewReader(data)))
+		if err != nil {
+			part.err = fmt.Errorf("parsing batch part: %w", err)
+			continue
+		}
+		part.req = subrequest(outer, sub)
+	}
+}
+
+// subrequest prepares sub to be served as part of the outer batch request.
+func subrequest(outer, sub *http.Request) *http.Request {
+	sub = sub.WithContext(withoutBypass(outer.Context()))
+	sub.RemoteAddr = outer.RemoteAddr
+	sub.TLS = outer.TLS
+	if sub.Host == "" {
+		sub.Host = outer.Host
+	}
+	connection := outer.Header.Values("Connection")
+	for k, v := range outer.Header {
+		if strings.HasPrefix(k, "Content-") || IsHopByHop(k, connection) {
+			continue
+		}
+		if _, ok := sub.Header[k]; !ok {
+			sub.Header[k] = v
+		}
+	}
+	return sub
+}
+
+func (b *BatchHandler) dispatch(parts []*batchPart) {
+	concurrency := b.MaxConcurrency
+	if concurrency <= 0 {
+		concurrency = 1
+	}
+	sem := make(chan struct{}, concurrency)
+
+	var wg sync.WaitGroup
+	for _, part := range parts {
+		if part.err != nil {
+			http.Error(part.resp, part.err.Error(), http.StatusBadRequest)
+			continue
+		}
+		wg.Add(1)
+		sem <- struct{}{}
+		go func(part *batchPart) {
+			defer func() {
+				if r := recover(); r != nil {
+					part.resp = newResponseBuffer()
+					http.Error(part.resp, http.StatusText(http.StatusInternalServerError), http.StatusInternalServerError)
+				}
+				<-sem
+				wg.Done()
+			}()
+			b.Handler.ServeHTTP(part.resp, part.req)
+		}(part)
+	}
+	wg.Wait()
+}
+
+// NewBatchRequest returns a POST request to url whose multipart/mixed body
+// carries the specified subrequests, for use with batch endpoints like the
+// ones served by BatchHandler. Subrequest bodies are read to completion.
+func NewBatchRequest(ctx context.Context, url string, reqs ...*http.Request) (*http.Request, error) {
+	var body bytes.Buffer
+	mw := multipart.NewWriter(&body)
+	for i, sub := range reqs {
+		hdr := textproto.MIMEHeader{}
+		hdr.Set("Content-Type", "application/http")
+		hdr.Set("Content-ID", fmt.Sprintf("<%d>", i))
+		pw, err := mw.CreatePart(hdr)
+		if err != nil {
+			return nil, err
+		}
+		if err := sub.Write(pw); err != nil {
+			return nil, fmt.Errorf("serializing subrequest %d: %w", i, err)
+		}
+	}
+	if err := mw.Close(); err != nil {
+		return nil, err
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, url, &body)
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("Content-Type", mime.FormatMediaType("multipart/mixed", map[string]string{
+		"boundary": mw.Boundary(),
+	}))
+	return req, nil
+}
+
+// ReadBatchResponse parses the multipart/mixed response to a batch request
+// made with NewBatchRequest, and returns the responses to the subrequests,
+// in order. The bodies of the returned responses are fully buffered, and
+// resp.Body is consumed but not closed.
+func ReadBatchResponse(resp *http.Response, reqs ...*http.Request) ([]*http.Response, error) {
+	ctype, params, err := mime.ParseMediaType(resp.Header.Get("Content-Type"))
+	if err != nil || ctype != "multipart/mixed" {
+		return nil, fmt.Errorf("batch response has content type %q, expected multipart/mixed", resp.Header.Get("Content-Type"))
+	}
+
+	var out []*http.Response
+	mr := multipart.NewReader(resp.Body, params["boundary"])
+	for i := 0; ; i++ {
+		p, err := mr.NextPart()
+		if err == io.EOF {
+			return out, nil
+		}
+		if err != nil {
+			return nil, fmt.Errorf("reading batch response part: %w", err)
+		}
+		data, err := io.ReadAll(p)
+		if err != nil {
+			return nil, fmt.Errorf("reading batch response part: %w", err)
+		}
+		var sub *http.Request
+		if i < len(reqs) {
+			sub = reqs[i]
+		}
+		r, err := http.ReadResponse(bufio.NewReader(bytes.NewReader(data)), sub)
+		if err != nil {
+			return nil, fmt.Errorf("parsing batch response part: %w", err)
+		}
+		rbody, err := io.ReadAll(r.Body)
+		r.Body.Close()
+		if err != nil {
+			return nil, fmt.Errorf("parsing batch response part: %w", err)
+		}
+		r.Body = io.NopCloser(bytes.NewReader(rbody))
+		out = append(out, r)
+	}
+}
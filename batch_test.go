@@ -0,0 +1,134 @@
+// Copyright 2026 Franklin "Snaipe" Mathieu.
+//
+// Use of this source code is governed by the MIT license that can be
+// found in the LICENSE file.
+
+package htutil
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+func TestBatchHandler(t *testing.T) {
+	t.Parallel()
+
+	mux := http.NewServeMux()
+	mux.HandleFunc("/echo", func(w http.ResponseWriter, req *http.Request) {
+		body, _ := io.ReadAll(req.Body)
+		w.Header().Set("X-Auth", req.Header.Get("Authorization"))
+		fmt.Fprintf(w, "%s %s", req.Method, body)
+	})
+	mux.HandleFunc("/panic", func(w http.ResponseWriter, req *http.Request) {
+		panic("oops")
+	})
+
+	srv := httptest.NewServer(&BatchHandler{Handler: mux, MaxConcurrency: 2})
+	defer srv.Close()
+
+	get, _ := http.NewRequest("GET", "http://batch.test/echo", nil)
+	post, _ := http.NewRequest("POST", "http://batch.test/echo", strings.NewReader("hello"))
+	override, _ := http.NewRequest("GET", "http://batch.test/echo", nil)
+	override.Header.Set("Authorization", "Bearer other")
+	broken, _ := http.NewRequest("GET", "http://batch.test/panic", nil)
+	missing, _ := http.NewRequest("GET", "http://batch.test/missing", nil)
+	reqs := []*http.Request{get, post, override, broken, missing}
+
+	req, err := NewBatchRequest(context.Background(), srv.URL, reqs...)
+	if err != nil {
+		t.Fatal(err)
+	}
+	req.Header.Set("Authorization", "Bearer outer")
+
+	resp, err := srv.Client().Do(req)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		t.Fatalf("expected status 200, got %d", resp.StatusCode)
+	}
+
+	resps, err := ReadBatchResponse(resp, reqs...)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	expected := []struct {
+		Status int
+		Body   string
+		Auth   string
+	}{
+		{Status: 200, Body: "GET ", Auth: "Bearer outer"},
+		{Status: 200, Body: "POST hello", Auth: "Bearer outer"},
+		{Status: 200, Body: "GET ", Auth: "Bearer other"},
+		{Status: 500},
+		{Status: 404},
+	}
+	if len(resps) != len(expected) {
+		t.Fatalf("expected %d responses, got %d", len(expected), len(resps))
+	}
+	for i, e := range expected {
+		r := resps[i]
+		if r.StatusCode != e.Status {
+			t.Fatalf("response %d: expected status %d, got %d", i, e.Status, r.StatusCode)
+		}
+		if e.Status != 200 {
+			continue
+		}
+		body, _ := io.ReadAll(r.Body)
+		if string(body) != e.Body {
+			t.Fatalf("response %d: expected body %q, got %q", i, e.Body, body)
+		}
+		if auth := r.Header.Get("X-Auth"); auth != e.Auth {
+			t.Fatalf("response %d: expected auth %q, got %q", i, e.Auth, auth)
+		}
+	}
+}
+
+func TestBatchHandlerLimits(t *testing.T) {
+	t.Parallel()
+
+	handler := &BatchHandler{
+		Handler:  http.NotFoundHandler(),
+		MaxParts: 1,
+	}
+
+	tcases := []struct {
+		Count  int
+		Status int
+	}{
+		{Count: 1, Status: http.StatusOK},
+		{Count: 2, Status: http.StatusRequestEntityTooLarge},
+	}
+
+	for i, tcase := range tcases {
+		t.Run(fmt.Sprintf("%d", i), func(t *testing.T) {
+			var reqs []*http.Request
+			for i := 0; i < tcase.Count; i++ {
+				sub, _ := http.NewRequest("GET", "http://batch.test/", nil)
+				reqs = append(reqs, sub)
+			}
+			req, err := NewBatchRequest(context.Background(), "http://batch.test/batch", reqs...)
+			if err != nil {
+				t.Fatal(err)
+			}
+			w := httptest.NewRecorder()
+			handler.ServeHTTP(w, req)
+			if w.Code != tcase.Status {
+				t.Fatalf("expected status %d, got %d", tcase.Status, w.Code)
+			}
+		})
+	}
+
+	w := httptest.NewRecorder()
+	handler.ServeHTTP(w, httptest.NewRequest("POST", "/batch", strings.NewReader("{}")))
+	if w.Code != http.StatusUnsupportedMediaType {
+		t.Fatalf("expected status 415, got %d", w.Code)
+	}
+}
@@ -0,0 +1,169 @@
+// Copyright 2026 Franklin "Snaipe" Mathieu.
+//
+// Use of this source code is governed by the MIT license that can be
+// found in the LICENSE file.
+
+package htutil
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"log/slog"
+	"net/http"
+	"sync"
+	"time"
+)
+
+// AccessToken is an OAuth 2.0 access token.
+type AccessToken struct {
+	// Value is the access token itself.
+	Value string
+
+	// Type is the token type, used as the authentication scheme.
+	// Defaults to "Bearer".
+	Type string
+
+	// Expiry is the time at which the token expires, or zero if unknown.
+	Expiry time.Time
+}
+
+// Valid returns whether t is set and not about to expire.
+func (t *AccessToken) Valid() bool {
+	return t != nil && t.Value != "" && (t.Expiry.IsZero() || time.Until(t.Expiry) > 10*time.Second)
+}
+
+// TokenSource provides access tokens, typically by running an OAuth 2.0
+// grant against an authorization server.
+type TokenSource interface {
+	// Token returns an access token. If stale is not nil, it is a token
+	// that the resource server rejected, and a different one must be
+	// returned.
+	Token(ctx context.Context, stale *AccessToken) (*AccessToken, error)
+}
+
+// CachedTokenSource wraps a TokenSource, reusing its tokens until they
+// expire or get rejected. It is safe for concurrent use; concurrent
+// refreshes are collapsed into a single call to Source.
+type CachedTokenSource struct {
+	Source TokenSource
+
+	mu    sync.Mutex
+	token *AccessToken
+}
+
+func (s *CachedTokenSource) Token(ctx context.Context, stale *AccessToken) (*AccessToken, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.token.Valid() && (stale == nil || stale.Value != s.token.Value) {
+		return s.token, nil
+	}
+	token, err := s.Source.Token(ctx, stale)
+	if err != nil {
+		return nil, err
+	}
+	s.token = token
+	return token, nil
+}
+
+// BearerTransport is a http.RoundTripper that authenticates requests with
+// access tokens from Source.
+//
+// When a response is a 401 Unauthorized with an invalid_token error, as
+// per RFC 6750 §3.1, a new token is requested from Source, and the request
+// is retried once, provided that its body can be replayed; see RequestBody.
+type BearerTransport struct {
+	// Base is the underlying transport. Defaults to http.DefaultTransport.
+	Base http.RoundTripper
+
+	// Source provides the access tokens. It is called for every request,
+	// and should cache tokens; see CachedTokenSource.
+	Source TokenSource
+}
+
+func (t *BearerTransport) base() http.RoundTripper {
+	if t.Base != nil {
+		return t.Base
+	}
+	return http.DefaultTransport
+}
+
+func (t *BearerTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	token, err := t.Source.Token(req.Context(), nil)
+	if err != nil {
+		closeBody(req)
+		return nil, fmt.Errorf("getting access token: %w", err)
+	}
+
+	resp, err := t.base().RoundTrip(authorize(req, token))
+	if err != nil || resp.StatusCode != http.StatusUnauthorized || !invalidToken(resp.Header) {
+		return resp, err
+	}
+
+	logger := ContextLogger(req.Context())
+	retry, err := rewind(req)
+	if err != nil {
+		// The request cannot be replayed; let the caller deal with the 401.
+		logger.Debug("not retrying request with renewed token",
+			slog.Any("url", URL{req.URL}), slog.String("error", err.Error()))
+		return resp, nil
+	}
+	token, err = t.Source.Token(req.Context(), token)
+	if err != nil {
+		logger.Warn("renewing access token", slog.String("error", err.Error()))
+		return resp, nil
+	}
+	logger.Debug("retrying request with renewed token", slog.Any("url", URL{req.URL}))
+	io.Copy(io.Discard, io.LimitReader(resp.Body, 4<<10))
+	resp.Body.Close()
+	return t.base().RoundTrip(authorize(retry, token))
+}
+
+// authorize returns a copy of req authenticated with token.
+func authorize(req *http.Request, token *AccessToken) *http.Request {
+	scheme := token.Type
+	if scheme == "" {
+		scheme = "Bearer"
+	}
+	req = req.Clone(req.Context())
+	req.Header.Set("Authorization", scheme+" "+token.Value)
+	return req
+}
+
+// rewind returns a copy of req whose body is reset, for retries.
+func rewind(req *http.Request) (*http.Request, error) {
+	if req.Body == nil || req.Body == http.NoBody {
+		return req, nil
+	}
+	if req.GetBody == nil {
+		return nil, errors.New("request body cannot be replayed")
+	}
+	body, err := req.GetBody()
+	if err != nil {
+		return nil, err
+	}
+	req = req.Clone(req.Context())
+	req.Body = body
+	return req, nil
+}
+
+func closeBody(req *http.Request) {
+	if req.Body != nil {
+		req.Body.Close()
+	}
+}
+
+// invalidToken returns whether the WWW-Authenticate fields of h report an
+// invalid_token error.
+func invalidToken(h http.Header) bool {
+	for _, v := range h.Values("WWW-Authenticate") {
+		challenges, _ := ParseChallenges(v)
+		for _, c := range challenges {
+			if c.Params["error"] == "invalid_token" {
+				return true
+			}
+		}
+	}
+	return false
+}
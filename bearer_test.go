@@ -0,0 +1,113 @@
+// Copyright 2026 Franklin "Snaipe" Mathieu.
+//
+// Use of this source code is governed by the MIT license that can be
+// found in the LICENSE file.
+
+package htutil
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync/atomic"
+	"testing"
+)
+
+type countingTokenSource struct {
+	issued atomic.Int32
+}
+
+func (s *countingTokenSource) Token(ctx context.Context, stale *AccessToken) (*AccessToken, error) {
+	n := s.issued.Add(1)
+	return &AccessToken{Value: fmt.Sprintf("token-%d", n)}, nil
+}
+
+func TestBearerTransport(t *testing.T) {
+	t.Parallel()
+
+	tcases := []struct {
+		Valid  string
+		Body   io.Reader
+		Status int
+		Issued int32
+		Echo   string
+	}{
+		{Valid: "token-1", Status: 200, Issued: 1, Echo: "token-1"},
+		{Valid: "token-2", Status: 200, Issued: 2, Echo: "token-2"},
+		{Valid: "token-2", Body: strings.NewReader("payload"), Status: 200, Issued: 2, Echo: "token-2 payload"},
+		{Valid: "token-3", Status: 401, Issued: 2},
+	}
+
+	for i, tcase := range tcases {
+		t.Run(fmt.Sprintf("%d", i), func(t *testing.T) {
+			srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+				if req.Header.Get("Authorization") != "Bearer "+tcase.Valid {
+					w.Header().Set("WWW-Authenticate", `Bearer realm="test", error="invalid_token", error_description="expired"`)
+					w.WriteHeader(http.StatusUnauthorized)
+					return
+				}
+				body, _ := io.ReadAll(req.Body)
+				fmt.Fprint(w, tcase.Valid)
+				if len(body) > 0 {
+					fmt.Fprintf(w, " %s", body)
+				}
+			}))
+			defer srv.Close()
+
+			source := &countingTokenSource{}
+			client := &http.Client{Transport: &BearerTransport{
+				Base:   srv.Client().Transport,
+				Source: &CachedTokenSource{Source: source},
+			}}
+
+			method := "GET"
+			if tcase.Body != nil {
+				method = "POST"
+			}
+			req, _ := http.NewRequest(method, srv.URL, tcase.Body)
+			resp, err := client.Do(req)
+			if err != nil {
+				t.Fatal(err)
+			}
+			defer resp.Body.Close()
+			body, _ := io.ReadAll(resp.Body)
+
+			if resp.StatusCode != tcase.Status {
+				t.Fatalf("expected status %d, got %d", tcase.Status, resp.StatusCode)
+			}
+			if n := source.issued.Load(); n != tcase.Issued {
+				t.Fatalf("expected %d tokens issued, got %d", tcase.Issued, n)
+			}
+			if tcase.Echo != "" && string(body) != tcase.Echo {
+				t.Fatalf("expected body %q, got %q", tcase.Echo, body)
+			}
+		})
+	}
+}
+
+func TestInvalidToken(t *testing.T) {
+	t.Parallel()
+
+	tcases := []struct {
+		Challenges []string
+		Expected   bool
+	}{
+		{Challenges: []string{`Bearer error="invalid_token"`}, Expected: true},
+		{Challenges: []string{`Basic realm="x", Bearer realm="y", error=invalid_token`}, Expected: true},
+		{Challenges: []string{`Bearer realm="y", error="insufficient_scope"`}, Expected: false},
+		{Challenges: []string{`Bearer realm="invalid_token"`}, Expected: false},
+		{Challenges: nil, Expected: false},
+	}
+
+	for i, tcase := range tcases {
+		t.Run(fmt.Sprintf("%d", i), func(t *testing.T) {
+			h := http.Header{"Www-Authenticate": tcase.Challenges}
+			if v := invalidToken(h); v != tcase.Expected {
+				t.Fatalf("expected %v, got %v", tcase.Expected, v)
+			}
+		})
+	}
+}
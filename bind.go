@@ -0,0 +1,360 @@
+// Copyright 2026 Franklin "Snaipe" Mathieu.
+//
+// Use of this source code is governed by the MIT license that can be
+// found in the LICENSE file.
+
+package htutil
+
+import (
+	"encoding"
+	"encoding/json"
+	"encoding/xml"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"reflect"
+	"strconv"
+	"strings"
+	"sync"
+)
+
+// BodyDecoder decodes a request body into v, a pointer.
+type BodyDecoder func(r io.Reader, v any) error
+
+var bodyDecoders = struct {
+	sync.RWMutex
+	m map[string]BodyDecoder
+}{
+	m: map[string]BodyDecoder{
+		"application/json": func(r io.Reader, v any) error {
+			return json.NewDecoder(r).Decode(v)
+		},
+		"application/xml": func(r io.Reader, v any) error {
+			return xml.NewDecoder(r).Decode(v)
+		},
+	},
+}
+
+// RegisterBodyDecoder registers the decoder used by Bind for request bodies
+// of the specified media type, replacing any previously registered decoder.
+// JSON and XML decoders are registered by default, and form bodies are
+// always bound to the fields tagged with `form`.
+func RegisterBodyDecoder(mediaType string, dec BodyDecoder) {
+	bodyDecoders.Lock()
+	defer bodyDecoders.Unlock()
+	bodyDecoders.m[strings.ToLower(mediaType)] = dec
+}
+
+// MediaDecoder is a BodyDecoder for request bodies of a media type.
+type MediaDecoder struct {
+	MediaType string
+	Decode    BodyDecoder
+}
+
+// bodyDecoderOf returns the decoder of mediaType among decoders, or among
+// the registered decoders if decoders is nil. A nil decoder selects the
+// built-in decoding of forms.
+func bodyDecoderOf(mediaType string, decoders []MediaDecoder) (BodyDecoder, bool) {
+	lookup := func(mediaType string) (BodyDecoder, bool) {
+		if decoders == nil {
+			if isFormMediaType(mediaType) {
+				return nil, true
+			}
+			bodyDecoders.RLock()
+			defer bodyDecoders.RUnlock()
+			dec, ok := bodyDecoders.m[mediaType]
+			return dec, ok
+		}
+		for _, d := range decoders {
+			if strings.EqualFold(d.MediaType, mediaType) {
+				return d.Decode, d.Decode != nil || isFormMediaType(mediaType)
+			}
+		}
+		return nil, false
+	}
+	if dec, ok := lookup(mediaType); ok {
+		return dec, true
+	}
+	// Structured syntax suffixes, like application/problem+json.
+	if i := strings.LastIndexByte(mediaType, '+'); i >= 0 {
+		return lookup("application/" + mediaType[i+1:])
+	}
+	return nil, false
+}
+
+func isFormMediaType(mediaType string) bool {
+	return mediaType == "application/x-www-form-urlencoded" || mediaType == "multipart/form-data"
+}
+
+// DefaultMaxBodySize is the size limit of the request bodies decoded by
+// Bind.
+const DefaultMaxBodySize = 1 << 20
+
+// Bind populates v, which must be a pointer, from req.
+//
+// The request body, if any, is decoded into v with DecodeBody, with the
+// registered decoders. Then, if v points to a struct, its fields are
+// populated from the request according to their tags:
+//
+//   - `query:"name"` binds the query parameter name;
+//   - `header:"Name"` binds the header field Name, with the codecs
+//     registered by RegisterHeaderCodec;
+//   - `form:"name"` binds the form field name, of application/x-www-form-urlencoded
+//     and multipart/form-data bodies.
+//
+// Fields with no value in the request are left untouched. Other than
+// header fields, tagged fields may be strings, booleans, numbers, slices
+// thereof for repeated values, or implement encoding.TextUnmarshaler.
+//
+// Errors are problems rendered by RespondError: 415 Unsupported Media Type
+// for bodies that cannot be decoded, 413 Content Too Large for bodies
+// larger than DefaultMaxBodySize, and 400 Bad Request for invalid values.
+func Bind(req *http.Request, v any) error {
+	rv := reflect.ValueOf(v)
+	if rv.Kind() != reflect.Pointer || rv.IsNil() {
+		return fmt.Errorf("binding request: %T is not a non-nil pointer", v)
+	}
+	if err := DecodeBody(req, v); err != nil {
+		return err
+	}
+	if rv.Elem().Kind() != reflect.Struct {
+		return nil
+	}
+	return bindFields(req, rv.Elem())
+}
+
+func hasBody(req *http.Request) bool {
+	return req.Body != nil && req.Body != http.NoBody && req.ContentLength != 0
+}
+
+// DecodeBody decodes the body of req into v, a pointer, with the decoder
+// of its Content-Type among decoders, or among the registered decoders if
+// none are specified (see RegisterBodyDecoder). This is the inbound
+// counterpart of NegotiateContent. Requests without a body leave v
+// untouched.
+//
+// Decoders of structured syntax suffixes, like application/json, also
+// decode the media types with that suffix, like application/problem+json.
+// Form bodies, of type application/x-www-form-urlencoded and
+// multipart/form-data, are parsed into req.PostForm and req.MultipartForm,
+// and bound to the fields of v tagged with `form`, as per Bind, or to v
+// itself if it is a *url.Values. Among decoders, form types are accepted
+// only if listed, with a nil Decode function for the built-in decoding.
+//
+// Errors are problems rendered by RespondError: 415 Unsupported Media Type
+// for media types with no decoder, 413 Content Too Large for bodies larger
+// than DefaultMaxBodySize, or than the limit of a http.MaxBytesReader
+// wrapping req.Body, and 400 Bad Request for invalid content. Use a
+// BodyDecoding for other size limits.
+func DecodeBody(req *http.Request, v any, decoders ...MediaDecoder) error {
+	d := BodyDecoding{Decoders: decoders}
+	return d.Decode(req, v)
+}
+
+// BodyDecoding decodes request bodies like DecodeBody, with a configurable
+// size limit.
+type BodyDecoding struct {
+	// Decoders are the decoders of the accepted media types, as per
+	// DecodeBody. If nil, the registered decoders are used.
+	Decoders []MediaDecoder
+
+	// MaxSize is the size limit of the request bodies, form bodies
+	// included. Defaults to DefaultMaxBodySize.
+	MaxSize int64
+}
+
+func (d *BodyDecoding) maxSize() int64 {
+	if d.MaxSize > 0 {
+		return d.MaxSize
+	}
+	return DefaultMaxBodySize
+}
+
+// Decode decodes the body of req into v, a pointer, as per DecodeBody.
+// Bodies larger than MaxSize are answered with a 413 Content Too Large
+// problem.
+func (d *BodyDecoding) Decode(req *http.Request, v any) error {
+	if !hasBody(req) {
+		return nil
+	}
+	acc, err := ParseAcceptable(req.Header.Get("Content-Type"))
+	if err != nil || !strings.Contains(acc.Value, "/") || strings.Contains(acc.Value, "*") {
+		return NewProblem(http.StatusUnsupportedMediaType, "The request content has no valid media type.")
+	}
+	dec, ok := bodyDecoderOf(acc.Value, d.Decoders)
+	if !ok {
+		return NewProblem(http.StatusUnsupportedMediaType,
+			fmt.Sprintf("Content of type %s is not supported.", acc.Value))
+	}
+	if dec == nil {
+		return decodeForm(req, acc.Value, v, d.maxSize())
+	}
+
+	lr := &io.LimitedReader{R: req.Body, N: d.maxSize() + 1}
+	err = dec(lr, v)
+	var tooLarge *http.MaxBytesError
+	switch {
+	case lr.N <= 0:
+		return NewProblem(http.StatusRequestEntityTooLarge,
+			fmt.Sprintf("The request content exceeds %d bytes.", d.maxSize()))
+	case errors.As(err, &tooLarge):
+		return NewProblem(http.StatusRequestEntityTooLarge,
+			fmt.Sprintf("The request content exceeds %d bytes.", tooLarge.Limit))
+	case err != nil:
+		return NewProblem(http.StatusBadRequest, fmt.Sprintf("Invalid request content: %v.", err))
+	}
+	return nil
+}
+
+func decodeForm(req *http.Request, mediaType string, v any, maxSize int64) error {
+	req.Body = http.MaxBytesReader(nil, req.Body, maxSize)
+	var err error
+	if mediaType == "multipart/form-data" {
+		// Files beyond 32 MiB in total are stored on disk, as with
+		// http.Request.FormFile.
+		err = req.ParseMultipartForm(min(maxSize, 32<<20))
+	} else {
+		err = req.ParseForm()
+	}
+	if err != nil {
+		// The multipart reader does not wrap read errors, but the
+		// http.MaxBytesReader keeps returning its own.
+		if _, rerr := req.Body.Read(nil); rerr != nil {
+			err = rerr
+		}
+	}
+	var tooLarge *http.MaxBytesError
+	if errors.As(err, &tooLarge) {
+		return NewProblem(http.StatusRequestEntityTooLarge,
+			fmt.Sprintf("The request content exceeds %d bytes.", tooLarge.Limit))
+	}
+	if err != nil {
+		return NewProblem(http.StatusBadRequest, fmt.Sprintf("Invalid form: %v.", err))
+	}
+
+	if values, ok := v.(*url.Values); ok {
+		*values = req.PostForm
+		return nil
+	}
+	rv := reflect.ValueOf(v)
+	if rv.Kind() != reflect.Pointer || rv.IsNil() || rv.Elem().Kind() != reflect.Struct {
+		return nil
+	}
+	rv = rv.Elem()
+	typ := rv.Type()
+	for i := 0; i < typ.NumField(); i++ {
+		field := typ.Field(i)
+		name := field.Tag.Get("form")
+		if !field.IsExported() || name == "" {
+			continue
+		}
+		if err := bindValues(rv.Field(i), req.PostForm[name]); err != nil {
+			return NewProblem(http.StatusBadRequest, fmt.Sprintf("Invalid %s: %v.", field.Name, err))
+		}
+	}
+	return nil
+}
+
+func bindFields(req *http.Request, v reflect.Value) error {
+	query := req.URL.Query()
+
+	typ := v.Type()
+	for i := 0; i < typ.NumField(); i++ {
+		field := typ.Field(i)
+		if !field.IsExported() {
+			continue
+		}
+		fv := v.Field(i)
+		var err error
+		switch {
+		case field.Tag.Get("query") != "":
+			err = bindValues(fv, query[field.Tag.Get("query")])
+		case field.Tag.Get("header") != "":
+			err = bindHeader(fv, req.Header, field.Tag.Get("header"))
+		}
+		if err != nil {
+			return NewProblem(http.StatusBadRequest, fmt.Sprintf("Invalid %s: %v.", field.Name, err))
+		}
+	}
+	return nil
+}
+
+var textUnmarshalerType = reflect.TypeOf((*encoding.TextUnmarshaler)(nil)).Elem()
+
+// bindValues sets v from the parameter values.
+func bindValues(v reflect.Value, values []string) error {
+	if len(values) == 0 {
+		return nil
+	}
+	if v.Kind() == reflect.Slice && !v.Addr().Type().Implements(textUnmarshalerType) {
+		out := reflect.MakeSlice(v.Type(), len(values), len(values))
+		for i, s := range values {
+			if err := bindValue(out.Index(i), s); err != nil {
+				return err
+			}
+		}
+		v.Set(out)
+		return nil
+	}
+	return bindValue(v, values[0])
+}
+
+// bindValue sets v from the parameter value s.
+func bindValue(v reflect.Value, s string) error {
+	if u, ok := v.Addr().Interface().(encoding.TextUnmarshaler); ok {
+		return u.UnmarshalText([]byte(s))
+	}
+	switch v.Kind() {
+	case reflect.String:
+		v.SetString(s)
+	case reflect.Bool:
+		b, err := strconv.ParseBool(s)
+		if err != nil {
+			return err
+		}
+		v.SetBool(b)
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		n, err := strconv.ParseInt(s, 10, v.Type().Bits())
+		if err != nil {
+			return err
+		}
+		v.SetInt(n)
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		n, err := strconv.ParseUint(s, 10, v.Type().Bits())
+		if err != nil {
+			return err
+		}
+		v.SetUint(n)
+	case reflect.Float32, reflect.Float64:
+		f, err := strconv.ParseFloat(s, v.Type().Bits())
+		if err != nil {
+			return err
+		}
+		v.SetFloat(f)
+	default:
+		return fmt.Errorf("cannot bind values of type %v", v.Type())
+	}
+	return nil
+}
+
+// bindHeader sets v from the header field name of h, with the header codec
+// registered for the type of v, or as a parameter value if there is none.
+func bindHeader(v reflect.Value, h http.Header, name string) error {
+	values := h.Values(name)
+	if len(values) == 0 {
+		return nil
+	}
+	codec, ok := headerCodecs.Load(v.Type())
+	if !ok {
+		return bindValue(v, strings.Join(values, ", "))
+	}
+	parse := reflect.ValueOf(codec).FieldByName("Parse")
+	out := parse.Call([]reflect.Value{reflect.ValueOf(strings.Join(values, ", "))})
+	if err, _ := out[1].Interface().(error); err != nil {
+		return err
+	}
+	v.Set(out[0])
+	return nil
+}
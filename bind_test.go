@@ -0,0 +1,291 @@
+// Copyright 2026 Franklin "Snaipe" Mathieu.
+//
+// Use of this source code is governed by the MIT license that can be
+// found in the LICENSE file.
+
+package htutil
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+)
+
+type bindTarget struct {
+	Name    string    `json:"name" xml:"name" form:"name"`
+	Count   int       `json:"count" xml:"count" form:"count"`
+	Page    uint      `query:"page"`
+	Tags    []string  `query:"tag"`
+	Verbose bool      `query:"verbose"`
+	Since   time.Time `header:"If-Modified-Since"`
+	Etag    ETag      `header:"If-Match"`
+	Token   string    `header:"X-Token"`
+}
+
+func TestBind(t *testing.T) {
+	t.Parallel()
+
+	since := time.Date(2026, 10, 17, 12, 0, 0, 0, time.UTC)
+
+	tcases := []struct {
+		URL         string
+		ContentType string
+		Body        string
+		Header      map[string]string
+		Expected    bindTarget
+		Status      int
+	}{
+		{
+			URL:      "/?page=2&tag=a&tag=b&verbose=true",
+			Expected: bindTarget{Page: 2, Tags: []string{"a", "b"}, Verbose: true},
+		},
+		{
+			URL:         "/",
+			ContentType: "application/json",
+			Body:        `{"name": "widget", "count": 3}`,
+			Expected:    bindTarget{Name: "widget", Count: 3},
+		},
+		{
+			URL:         "/",
+			ContentType: "application/vnd.example+json; charset=utf-8",
+			Body:        `{"name": "widget"}`,
+			Expected:    bindTarget{Name: "widget"},
+		},
+		{
+			URL:         "/",
+			ContentType: "application/xml",
+			Body:        `<bindTarget><name>widget</name><count>3</count></bindTarget>`,
+			Expected:    bindTarget{Name: "widget", Count: 3},
+		},
+		{
+			URL:         "/?page=1",
+			ContentType: "application/x-www-form-urlencoded",
+			Body:        "name=widget&count=3",
+			Expected:    bindTarget{Name: "widget", Count: 3, Page: 1},
+		},
+		{
+			URL: "/",
+			Header: map[string]string{
+				"If-Modified-Since": since.Format("Mon, 02 Jan 2006 15:04:05 GMT"),
+				"If-Match":          `"xyzzy"`,
+				"X-Token":           "secret",
+			},
+			Expected: bindTarget{Since: since, Etag: ETag{Tag: "xyzzy"}, Token: "secret"},
+		},
+		{
+			URL:    "/?page=-1",
+			Status: 400,
+		},
+		{
+			URL:    "/",
+			Header: map[string]string{"If-Match": "xyzzy"},
+			Status: 400,
+		},
+		{
+			URL:         "/",
+			ContentType: "application/json",
+			Body:        `{"name": `,
+			Status:      400,
+		},
+		{
+			URL:         "/",
+			ContentType: "text/csv",
+			Body:        "widget,3",
+			Status:      415,
+		},
+		{
+			URL:         "/",
+			ContentType: "application/json",
+			Body:        `{"name": "` + strings.Repeat("a", DefaultMaxBodySize) + `"}`,
+			Status:      413,
+		},
+	}
+
+	for i, tcase := range tcases {
+		t.Run(fmt.Sprintf("%d", i), func(t *testing.T) {
+			var body *strings.Reader
+			if tcase.Body != "" {
+				body = strings.NewReader(tcase.Body)
+			}
+			req := httptest.NewRequest("POST", tcase.URL, nil)
+			if body != nil {
+				req = httptest.NewRequest("POST", tcase.URL, body)
+			}
+			if tcase.ContentType != "" {
+				req.Header.Set("Content-Type", tcase.ContentType)
+			}
+			for k, v := range tcase.Header {
+				req.Header.Set(k, v)
+			}
+
+			var v bindTarget
+			err := Bind(req, &v)
+			if tcase.Status != 0 {
+				var p *Problem
+				if !errors.As(err, &p) || p.Status != tcase.Status {
+					t.Fatalf("expected %d problem, got %v", tcase.Status, err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("expected no error, got %v", err)
+			}
+			if !reflect.DeepEqual(v, tcase.Expected) {
+				t.Fatalf("expected %+v, got %+v", tcase.Expected, v)
+			}
+		})
+	}
+}
+
+func TestBindNonStruct(t *testing.T) {
+	t.Parallel()
+
+	req := httptest.NewRequest("POST", "/?page=2", strings.NewReader(`["a", "b"]`))
+	req.Header.Set("Content-Type", "application/json")
+
+	var v []string
+	if err := Bind(req, &v); err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+	if !reflect.DeepEqual(v, []string{"a", "b"}) {
+		t.Fatalf("expected [a b], got %v", v)
+	}
+	if err := Bind(req, v); err == nil {
+		t.Fatalf("expected error binding to a non-pointer")
+	}
+}
+
+func TestDecodeBody(t *testing.T) {
+	t.Parallel()
+
+	csv := MediaDecoder{MediaType: "text/csv", Decode: func(r io.Reader, v any) error {
+		data, err := io.ReadAll(r)
+		if err != nil {
+			return err
+		}
+		name, _, _ := strings.Cut(string(data), ",")
+		v.(*bindTarget).Name = name
+		return nil
+	}}
+	json := MediaDecoder{MediaType: "application/json", Decode: bodyDecoders.m["application/json"]}
+	form := MediaDecoder{MediaType: "application/x-www-form-urlencoded"}
+
+	tcases := []struct {
+		ContentType string
+		Body        string
+		Decoders    []MediaDecoder
+		Limit       int64
+		Expected    bindTarget
+		Status      int
+	}{
+		{ContentType: "", Body: "", Expected: bindTarget{}},
+		{ContentType: "application/json", Body: `{"name": "widget"}`, Expected: bindTarget{Name: "widget"}},
+		{ContentType: "text/csv", Body: "widget,3", Decoders: []MediaDecoder{csv}, Expected: bindTarget{Name: "widget"}},
+		{ContentType: "text/csv", Body: "widget,3", Status: 415},
+		{ContentType: "application/problem+json", Body: `{"name": "widget"}`, Decoders: []MediaDecoder{csv, json}, Expected: bindTarget{Name: "widget"}},
+		{ContentType: "application/xml", Body: `<bindTarget/>`, Decoders: []MediaDecoder{csv, json}, Status: 415},
+		{ContentType: "application/x-www-form-urlencoded", Body: "name=widget", Decoders: []MediaDecoder{json}, Status: 415},
+		{ContentType: "application/x-www-form-urlencoded", Body: "name=widget&count=2", Decoders: []MediaDecoder{json, form}, Expected: bindTarget{Name: "widget", Count: 2}},
+		{ContentType: "application/x-www-form-urlencoded", Body: "count=two", Status: 400},
+		{ContentType: "*/*", Body: "{}", Status: 415},
+		{ContentType: "json", Body: "{}", Status: 415},
+		{ContentType: "application/json", Body: `{"name": "widget"}`, Limit: 4, Status: 413},
+		{ContentType: "application/x-www-form-urlencoded", Body: "name=widget", Limit: 4, Status: 413},
+	}
+
+	for i, tcase := range tcases {
+		t.Run(fmt.Sprintf("%d", i), func(t *testing.T) {
+			req := httptest.NewRequest("POST", "/", strings.NewReader(tcase.Body))
+			if tcase.ContentType != "" {
+				req.Header.Set("Content-Type", tcase.ContentType)
+			}
+			if tcase.Limit != 0 {
+				req.Body = http.MaxBytesReader(nil, req.Body, tcase.Limit)
+			}
+
+			var v bindTarget
+			err := DecodeBody(req, &v, tcase.Decoders...)
+			if tcase.Status != 0 {
+				var p *Problem
+				if !errors.As(err, &p) || p.Status != tcase.Status {
+					t.Fatalf("expected %d problem, got %v", tcase.Status, err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("expected no error, got %v", err)
+			}
+			if !reflect.DeepEqual(v, tcase.Expected) {
+				t.Fatalf("expected %+v, got %+v", tcase.Expected, v)
+			}
+		})
+	}
+}
+
+func TestDecodeBodyValues(t *testing.T) {
+	t.Parallel()
+
+	req := httptest.NewRequest("POST", "/", strings.NewReader("a=1&a=2&b=3"))
+	req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+
+	var values url.Values
+	if err := DecodeBody(req, &values); err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+	expect := url.Values{"a": {"1", "2"}, "b": {"3"}}
+	if !reflect.DeepEqual(values, expect) {
+		t.Fatalf("expected %v, got %v", expect, values)
+	}
+}
+
+func TestBodyDecoding(t *testing.T) {
+	t.Parallel()
+
+	large := `{"name": "` + strings.Repeat("a", DefaultMaxBodySize) + `"}`
+	multipart := "--b\r\nContent-Disposition: form-data; name=\"name\"\r\n\r\nwidget\r\n--b--\r\n"
+
+	tcases := []struct {
+		MaxSize     int64
+		ContentType string
+		Body        string
+		Status      int
+	}{
+		{MaxSize: 0, ContentType: "application/json", Body: large, Status: 413},
+		{MaxSize: 2 * DefaultMaxBodySize, ContentType: "application/json", Body: large},
+		{MaxSize: 8, ContentType: "application/json", Body: `{"name": "widget"}`, Status: 413},
+		{MaxSize: 8, ContentType: "application/x-www-form-urlencoded", Body: "name=widget", Status: 413},
+		{MaxSize: 16, ContentType: "multipart/form-data; boundary=b", Body: multipart, Status: 413},
+		{MaxSize: 1024, ContentType: "multipart/form-data; boundary=b", Body: multipart},
+	}
+
+	for i, tcase := range tcases {
+		t.Run(fmt.Sprintf("%d", i), func(t *testing.T) {
+			req := httptest.NewRequest("POST", "/", strings.NewReader(tcase.Body))
+			req.Header.Set("Content-Type", tcase.ContentType)
+
+			d := BodyDecoding{MaxSize: tcase.MaxSize}
+			var v bindTarget
+			err := d.Decode(req, &v)
+			if tcase.Status != 0 {
+				var p *Problem
+				if !errors.As(err, &p) || p.Status != tcase.Status {
+					t.Fatalf("expected %d problem, got %v", tcase.Status, err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("expected no error, got %v", err)
+			}
+			if v.Name == "" {
+				t.Fatalf("expected a decoded name, got %+v", v)
+			}
+		})
+	}
+}
@@ -0,0 +1,184 @@
+// Copyright 2026 Franklin "Snaipe" Mathieu.
+//
+// Use of this source code is governed by the MIT license that can be
+// found in the LICENSE file.
+
+package htutil
+
+import (
+	"bytes"
+	"context"
+	"io"
+	"net/http"
+)
+
+// responseBuffer is a http.ResponseWriter that buffers the response in
+// memory, for handlers whose output needs to be replayed or serialized.
+// Informational responses are not buffered.
+type responseBuffer struct {
+	status int
+	header http.Header
+	body   bytes.Buffer
+
+	// w is the writer that the buffer gets bypassed to, if bypassable.
+	w        http.ResponseWriter
+	parent   *responseBuffer
+	bypassed bool
+}
+
+func newResponseBuffer() *responseBuffer {
+	return &responseBuffer{header: http.Header{}}
+}
+
+type bufferKey struct{}
+
+// newBypassableBuffer returns a buffer for the response to req, which
+// would otherwise be written to w, and a copy of req whose context allows
+// bypassing the buffer with bypassBuffers.
+func newBypassableBuffer(w http.ResponseWriter, req *http.Request) (*responseBuffer, *http.Request) {
+	b := newResponseBuffer()
+	b.w = w
+	b.parent, _ = req.Context().Value(bufferKey{}).(*responseBuffer)
+	return b, req.WithContext(context.WithValue(req.Context(), bufferKey{}, b))
+}
+
+// withoutBypass returns a copy of ctx in which the bypassable buffers of
+// ctx cannot be bypassed, for requests whose responses must be buffered
+// regardless, like the parts of a batch.
+func withoutBypass(ctx context.Context) context.Context {
+	return context.WithValue(ctx, bufferKey{}, (*responseBuffer)(nil))
+}
+
+// bypassBuffers makes the bypassable buffers of the response to req write
+// through to their underlying writer, for responses that are too large or
+// too long-lived to be buffered. The middlewares that installed them must
+// then leave the response alone.
+func bypassBuffers(req *http.Request) {
+	// Bypass the outermost buffers first, so that the header of the inner
+	// ones gets merged into the actual response header.
+	var chain []*responseBuffer
+	b, _ := req.Context().Value(bufferKey{}).(*responseBuffer)
+	for ; b != nil && !b.bypassed; b = b.parent {
+		chain = append(chain, b)
+	}
+	for i := len(chain) - 1; i >= 0; i-- {
+		b := chain[i]
+		b.bypassed = true
+		hdr := b.w.Header()
+		for k, v := range b.header {
+			hdr[k] = v
+		}
+		b.header = hdr
+		if b.status != 0 {
+			b.w.WriteHeader(b.status)
+		}
+		if b.body.Len() > 0 {
+			b.w.Write(b.body.Bytes())
+			b.body.Reset()
+		}
+	}
+}
+
+func (b *responseBuffer) Header() http.Header { return b.header }
+
+func (b *responseBuffer) Write(p []byte) (int, error) {
+	if b.status == 0 {
+		b.WriteHeader(http.StatusOK)
+	}
+	if b.bypassed {
+		return b.w.Write(p)
+	}
+	return b.body.Write(p)
+}
+
+func (b *responseBuffer) WriteHeader(status int) {
+	switch {
+	case b.status != 0:
+	case Status(status).IsInformational() && status != http.StatusSwitchingProtocols:
+		b.writeInformational(status)
+	default:
+		b.status = status
+		if b.bypassed {
+			b.w.WriteHeader(status)
+		}
+	}
+}
+
+// writeInformational sends an informational response, like 103 Early
+// Hints, right away, with the header fields currently in the buffer, and
+// keeps waiting for the final response. Informational responses are
+// dropped if there is no underlying writer.
+func (b *responseBuffer) writeInformational(status int) {
+	switch {
+	case b.bypassed:
+		b.w.WriteHeader(status)
+	case b.w != nil:
+		hdr := b.w.Header()
+		saved := hdr.Clone()
+		for name := range hdr {
+			delete(hdr, name)
+		}
+		for name, values := range b.header {
+			hdr[name] = values
+		}
+		b.w.WriteHeader(status)
+
+		for name := range hdr {
+			delete(hdr, name)
+		}
+		for name, values := range saved {
+			hdr[name] = values
+		}
+	}
+}
+
+// FlushError flushes the underlying writer if the buffer is bypassed, and
+// does nothing otherwise.
+func (b *responseBuffer) FlushError() error {
+	if !b.bypassed {
+		return nil
+	}
+	return http.NewResponseController(b.w).Flush()
+}
+
+// Unwrap returns the underlying writer, if any, so that deadlines can be
+// set through http.ResponseController.
+func (b *responseBuffer) Unwrap() http.ResponseWriter {
+	return b.w
+}
+
+// replay writes a copy of the buffered response to w. The body is omitted
+// for HEAD requests.
+func (b *responseBuffer) replay(w http.ResponseWriter, req *http.Request) {
+	hdr := w.Header()
+	for k, v := range b.header {
+		hdr[k] = append([]string(nil), v...)
+	}
+	status := b.status
+	if status == 0 {
+		status = http.StatusOK
+	}
+	w.WriteHeader(status)
+	if req.Method != http.MethodHead {
+		w.Write(b.body.Bytes())
+	}
+}
+
+// response converts the buffered response into a http.Response answering req.
+func (b *responseBuffer) response(req *http.Request) *http.Response {
+	status := b.status
+	if status == 0 {
+		status = http.StatusOK
+	}
+	return &http.Response{
+		Status:        http.StatusText(status),
+		StatusCode:    status,
+		Proto:         "HTTP/1.1",
+		ProtoMajor:    1,
+		ProtoMinor:    1,
+		Header:        b.header,
+		Body:          io.NopCloser(bytes.NewReader(b.body.Bytes())),
+		ContentLength: int64(b.body.Len()),
+		Request:       req,
+	}
+}
@@ -0,0 +1,220 @@
+// Copyright 2026 Franklin "Snaipe" Mathieu.
+//
+// Use of this source code is governed by the MIT license that can be
+// found in the LICENSE file.
+
+package htutil
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"mime"
+	"mime/multipart"
+	"net/http"
+	"strconv"
+	"strings"
+)
+
+// ContentRange is the value of a Content-Range header field, as per
+// RFC 9110 §14.4.
+type ContentRange struct {
+	// Unit is the range unit, like "bytes".
+	Unit string
+
+	// First and Last are the positions of the first and last units of the
+	// range, inclusive. Both are -1 for unsatisfied ranges, as sent in 416
+	// responses.
+	First, Last int64
+
+	// Length is the complete length of the representation, or -1 if
+	// unknown.
+	Length int64
+}
+
+// ParseContentRange parses a Content-Range header value.
+func ParseContentRange(value string) (ContentRange, error) {
+	unit, rest, ok := strings.Cut(trimOWS(value), " ")
+	if !ok || !IsToken(unit) {
+		return ContentRange{}, fmt.Errorf("parsing content-range: missing range unit")
+	}
+	rng, length, ok := strings.Cut(rest, "/")
+	if !ok {
+		return ContentRange{}, fmt.Errorf("parsing content-range: missing complete length")
+	}
+
+	cr := ContentRange{Unit: unit, First: -1, Last: -1, Length: -1}
+	if length != "*" {
+		n, err := parseRangePos(length)
+		if err != nil {
+			return ContentRange{}, fmt.Errorf("parsing content-range: %w", err)
+		}
+		cr.Length = n
+	}
+	if rng == "*" {
+		if cr.Length < 0 {
+			return ContentRange{}, fmt.Errorf("parsing content-range: unsatisfied range without complete length")
+		}
+		return cr, nil
+	}
+
+	first, last, ok := strings.Cut(rng, "-")
+	if !ok {
+		return ContentRange{}, fmt.Errorf("parsing content-range: invalid range %q", rng)
+	}
+	var err error
+	if cr.First, err = parseRangePos(first); err != nil {
+		return ContentRange{}, fmt.Errorf("parsing content-range: %w", err)
+	}
+	if cr.Last, err = parseRangePos(last); err != nil {
+		return ContentRange{}, fmt.Errorf("parsing content-range: %w", err)
+	}
+	if cr.Last < cr.First || (cr.Length >= 0 && cr.Last >= cr.Length) {
+		return ContentRange{}, fmt.Errorf("parsing content-range: invalid range %q", rng)
+	}
+	return cr, nil
+}
+
+func parseRangePos(s string) (int64, error) {
+	for i := 0; i < len(s); i++ {
+		if !isDigit(s[i]) {
+			return 0, fmt.Errorf("%q is not a valid position", s)
+		}
+	}
+	return strconv.ParseInt(s, 10, 64)
+}
+
+// String returns the Content-Range header value for cr.
+func (cr ContentRange) String() string {
+	rng, length := "*", "*"
+	if cr.First >= 0 {
+		rng = strconv.FormatInt(cr.First, 10) + "-" + strconv.FormatInt(cr.Last, 10)
+	}
+	if cr.Length >= 0 {
+		length = strconv.FormatInt(cr.Length, 10)
+	}
+	return cr.Unit + " " + rng + "/" + length
+}
+
+// Satisfied returns whether cr describes a range, rather than the
+// unsatisfied range of a 416 response.
+func (cr ContentRange) Satisfied() bool {
+	return cr.First >= 0
+}
+
+// Size returns the number of units in the range.
+func (cr ContentRange) Size() int64 {
+	if !cr.Satisfied() {
+		return 0
+	}
+	return cr.Last - cr.First + 1
+}
+
+// ByteRangesReader reads the parts of a 206 Partial Content response, which
+// is either a multipart/byteranges response, or a single part response
+// described by its Content-Range.
+type ByteRangesReader struct {
+	single *ContentRange
+	body   io.Reader
+	mr     *multipart.Reader
+	length int64
+}
+
+// NewByteRangesReader returns a reader for the parts of resp, which must be
+// a 206 Partial Content response of byte ranges.
+func NewByteRangesReader(resp *http.Response) (*ByteRangesReader, error) {
+	if resp.StatusCode != http.StatusPartialContent {
+		return nil, fmt.Errorf("response status is %d, expected %d", resp.StatusCode, http.StatusPartialContent)
+	}
+	r := &ByteRangesReader{body: resp.Body, length: -1}
+
+	ctype, params, err := mime.ParseMediaType(resp.Header.Get("Content-Type"))
+	if err == nil && ctype == "multipart/byteranges" {
+		if params["boundary"] == "" {
+			return nil, errors.New("multipart/byteranges response has no boundary")
+		}
+		r.mr = multipart.NewReader(resp.Body, params["boundary"])
+		return r, nil
+	}
+
+	cr, err := byteRange(resp.Header.Get("Content-Range"))
+	if err != nil {
+		return nil, err
+	}
+	r.single = &cr
+	return r, nil
+}
+
+func byteRange(value string) (ContentRange, error) {
+	cr, err := ParseContentRange(value)
+	if err != nil {
+		return ContentRange{}, err
+	}
+	if cr.Unit != "bytes" {
+		return ContentRange{}, fmt.Errorf("range unit is %q, expected bytes", cr.Unit)
+	}
+	if !cr.Satisfied() {
+		return ContentRange{}, errors.New("partial content has an unsatisfied range")
+	}
+	return cr, nil
+}
+
+// Next returns the range and the content of the next part, in the order
+// of the response. The content of a part must be read before calling Next
+// again; reads fail if its size does not match its range. Next returns
+// io.EOF when there are no more parts.
+func (r *ByteRangesReader) Next() (ContentRange, io.Reader, error) {
+	if r.single != nil {
+		cr, body := *r.single, r.body
+		r.single, r.body = nil, nil
+		return cr, &rangeReader{r: body, n: cr.Size()}, nil
+	}
+	if r.mr == nil {
+		return ContentRange{}, nil, io.EOF
+	}
+
+	p, err := r.mr.NextPart()
+	if err != nil {
+		if err != io.EOF {
+			err = fmt.Errorf("reading byte range: %w", err)
+		}
+		return ContentRange{}, nil, err
+	}
+	cr, err := byteRange(p.Header.Get("Content-Range"))
+	if err != nil {
+		return ContentRange{}, nil, fmt.Errorf("reading byte range: %w", err)
+	}
+	if r.length >= 0 && cr.Length >= 0 && cr.Length != r.length {
+		return ContentRange{}, nil, fmt.Errorf("reading byte range: complete length changed from %d to %d", r.length, cr.Length)
+	}
+	if cr.Length >= 0 {
+		r.length = cr.Length
+	}
+	return cr, &rangeReader{r: p, n: cr.Size()}, nil
+}
+
+// rangeReader reads the content of a range, and fails if its size does not
+// match the size of the range.
+type rangeReader struct {
+	r io.Reader
+	n int64
+}
+
+func (r *rangeReader) Read(p []byte) (int, error) {
+	if r.n <= 0 {
+		var extra [1]byte
+		if n, _ := io.ReadFull(r.r, extra[:]); n > 0 {
+			return 0, errors.New("byte range content is longer than its range")
+		}
+		return 0, io.EOF
+	}
+	if int64(len(p)) > r.n {
+		p = p[:r.n]
+	}
+	n, err := r.r.Read(p)
+	r.n -= int64(n)
+	if err == io.EOF && r.n > 0 {
+		err = io.ErrUnexpectedEOF
+	}
+	return n, err
+}
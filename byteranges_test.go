@@ -0,0 +1,162 @@
+// Copyright 2026 Franklin "Snaipe" Mathieu.
+//
+// Use of this source code is governed by the MIT license that can be
+// found in the LICENSE file.
+
+package htutil
+
+import (
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestParseContentRange(t *testing.T) {
+	t.Parallel()
+
+	tcases := []struct {
+		Value string
+		Range ContentRange
+		Err   bool
+	}{
+		{Value: "bytes 42-1233/1234", Range: ContentRange{Unit: "bytes", First: 42, Last: 1233, Length: 1234}},
+		{Value: "bytes 42-1233/*", Range: ContentRange{Unit: "bytes", First: 42, Last: 1233, Length: -1}},
+		{Value: "bytes */1234", Range: ContentRange{Unit: "bytes", First: -1, Last: -1, Length: 1234}},
+		{Value: "bytes */*", Err: true},
+		{Value: "bytes 42-1234/1234", Err: true},
+		{Value: "bytes 42-41/1234", Err: true},
+		{Value: "bytes -1-41/1234", Err: true},
+		{Value: "bytes 0-+1/1234", Err: true},
+		{Value: "0-1/1234", Err: true},
+	}
+
+	for i, tcase := range tcases {
+		t.Run(fmt.Sprintf("%d", i), func(t *testing.T) {
+			cr, err := ParseContentRange(tcase.Value)
+			if (err != nil) != tcase.Err {
+				t.Fatalf("expected error %v, got %v", tcase.Err, err)
+			}
+			if err != nil {
+				return
+			}
+			if cr != tcase.Range {
+				t.Fatalf("expected %+v, got %+v", tcase.Range, cr)
+			}
+			if cr.String() != tcase.Value {
+				t.Fatalf("expected %q, got %q", tcase.Value, cr.String())
+			}
+		})
+	}
+}
+
+func TestByteRangesReader(t *testing.T) {
+	t.Parallel()
+
+	const content = "hello, world! this is some ranged content."
+
+	serve := func(rng string) *http.Response {
+		req := httptest.NewRequest("GET", "/", nil)
+		req.Header.Set("Range", rng)
+		w := httptest.NewRecorder()
+		http.ServeContent(w, req, "content.txt", time.Time{}, strings.NewReader(content))
+		return w.Result()
+	}
+	craft := func(parts ...string) *http.Response {
+		var b strings.Builder
+		for _, p := range parts {
+			b.WriteString("--sep\r\nContent-Type: text/plain\r\n" + p)
+		}
+		b.WriteString("--sep--\r\n")
+		return &http.Response{
+			StatusCode: http.StatusPartialContent,
+			Header:     http.Header{"Content-Type": {"multipart/byteranges; boundary=sep"}},
+			Body:       io.NopCloser(strings.NewReader(b.String())),
+		}
+	}
+
+	tcases := []struct {
+		Response *http.Response
+		Ranges   []string
+		Parts    []string
+		Err      bool
+	}{
+		{
+			Response: serve("bytes=0-4,7-11,-8"),
+			Ranges:   []string{"bytes 0-4/42", "bytes 7-11/42", "bytes 34-41/42"},
+			Parts:    []string{"hello", "world", "content."},
+		},
+		{
+			Response: serve("bytes=7-"),
+			Ranges:   []string{"bytes 7-41/42"},
+			Parts:    []string{content[7:]},
+		},
+		{
+			Response: craft("Content-Range: bytes 0-4/42\r\n\r\nhell\r\n"),
+			Ranges:   []string{"bytes 0-4/42"},
+			Err:      true,
+		},
+		{
+			Response: craft("Content-Range: bytes 0-4/42\r\n\r\nhello!\r\n"),
+			Ranges:   []string{"bytes 0-4/42"},
+			Err:      true,
+		},
+		{
+			Response: craft("Content-Range: bytes 0-4/42\r\n\r\nhello\r\n", "Content-Range: bytes 0-4/43\r\n\r\nhello\r\n"),
+			Ranges:   []string{"bytes 0-4/42"},
+			Parts:    []string{"hello"},
+			Err:      true,
+		},
+		{
+			Response: craft("Content-Range: lines 0-4/42\r\n\r\nhello\r\n"),
+			Err:      true,
+		},
+	}
+
+	for i, tcase := range tcases {
+		t.Run(fmt.Sprintf("%d", i), func(t *testing.T) {
+			r, err := NewByteRangesReader(tcase.Response)
+			if err != nil {
+				t.Fatal(err)
+			}
+			var (
+				ranges []string
+				parts  []string
+			)
+			for {
+				cr, part, err := r.Next()
+				if err == io.EOF {
+					break
+				}
+				if err != nil {
+					if !tcase.Err {
+						t.Fatal(err)
+					}
+					break
+				}
+				ranges = append(ranges, cr.String())
+				data, err := io.ReadAll(part)
+				if err != nil {
+					if !tcase.Err {
+						t.Fatal(err)
+					}
+					break
+				}
+				parts = append(parts, string(data))
+			}
+			if fmt.Sprint(ranges) != fmt.Sprint(tcase.Ranges) {
+				t.Fatalf("expected ranges %q, got %q", tcase.Ranges, ranges)
+			}
+			if fmt.Sprint(parts) != fmt.Sprint(tcase.Parts) {
+				t.Fatalf("expected parts %q, got %q", tcase.Parts, parts)
+			}
+		})
+	}
+
+	if _, err := NewByteRangesReader(&http.Response{StatusCode: http.StatusOK}); err == nil {
+		t.Fatalf("expected error for non-206 response")
+	}
+}
@@ -0,0 +1,424 @@
+// Copyright 2026 Franklin "Snaipe" Mathieu.
+//
+// Use of this source code is governed by the MIT license that can be
+// found in the LICENSE file.
+
+package htutil
+
+import (
+	"container/list"
+	"net/http"
+	"strings"
+	"sync"
+	"time"
+)
+
+// CachedResponse is a response stored by a Cache. Stored responses are
+// shared between requests, and must not be modified.
+type CachedResponse struct {
+	// Status is the status code of the response.
+	Status int
+
+	// Header contains the header fields of the response.
+	Header http.Header
+
+	// Body is the content of the response.
+	Body []byte
+
+	// RequestHeader contains the fields of the request that the response
+	// was selected by, as listed in the Vary header field of the response.
+	RequestHeader http.Header
+
+	// RequestTime and ResponseTime are the times at which the request was
+	// forwarded, and the response received, from which the age of the
+	// response is calculated.
+	RequestTime  time.Time
+	ResponseTime time.Time
+}
+
+// age returns the age values of the response.
+func (r *CachedResponse) age() ResponseAge {
+	return NewResponseAge(r.Header, r.RequestTime, r.ResponseTime)
+}
+
+// CacheStore stores the responses of a Cache, by cache key. All the
+// responses stored under a key are variants of the same resource,
+// selected by the request fields listed in their Vary header field.
+//
+// Implementations must be safe for concurrent use.
+type CacheStore interface {
+	// Load returns the responses stored under key.
+	Load(key string) ([]*CachedResponse, bool)
+
+	// Store replaces the responses stored under key.
+	Store(key string, variants []*CachedResponse)
+
+	// Delete removes the responses stored under key.
+	Delete(key string)
+}
+
+// MemoryCacheStore is a CacheStore keeping responses in memory, evicting
+// the least recently used keys once full. The zero value is an unbounded
+// store.
+type MemoryCacheStore struct {
+	// MaxEntries is the maximum number of keys to keep, or 0 for no limit.
+	MaxEntries int
+
+	mu      sync.Mutex
+	entries map[string]*list.Element
+	lru     list.List
+}
+
+type memoryCacheEntry struct {
+	key      string
+	variants []*CachedResponse
+}
+
+func (s *MemoryCacheStore) Load(key string) ([]*CachedResponse, bool) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	elem, ok := s.entries[key]
+	if !ok {
+		return nil, false
+	}
+	s.lru.MoveToFront(elem)
+	return elem.Value.(*memoryCacheEntry).variants, true
+}
+
+func (s *MemoryCacheStore) Store(key string, variants []*CachedResponse) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.entries == nil {
+		s.entries = make(map[string]*list.Element)
+	}
+	if elem, ok := s.entries[key]; ok {
+		elem.Value.(*memoryCacheEntry).variants = variants
+		s.lru.MoveToFront(elem)
+		return
+	}
+	s.entries[key] = s.lru.PushFront(&memoryCacheEntry{key: key, variants: variants})
+	for s.MaxEntries > 0 && s.lru.Len() > s.MaxEntries {
+		oldest := s.lru.Back()
+		s.lru.Remove(oldest)
+		delete(s.entries, oldest.Value.(*memoryCacheEntry).key)
+	}
+}
+
+func (s *MemoryCacheStore) Delete(key string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if elem, ok := s.entries[key]; ok {
+		s.lru.Remove(elem)
+		delete(s.entries, key)
+	}
+}
+
+// Cache is a shared HTTP cache, as per RFC 9111, storing the responses of
+// a handler to serve subsequent requests without calling it.
+//
+// Responses are stored if their status is cacheable by default, and if
+// they have an explicit freshness lifetime, or validators with which they
+// can be revalidated. Responses with a no-store or private Cache-Control,
+// a Set-Cookie field, or a Vary of "*" are never stored, and neither are
+// responses to requests with an Authorization field, unless allowed by
+// RFC 9111 §3.5. Fresh responses are served from the store; stale ones
+// are revalidated with the validators of the stored response, so that
+// the handler can answer with 304 Not Modified.
+//
+// The Vary header field of responses is honored, so handlers negotiating
+// their responses must list the request fields they negotiate on; see
+// AddVary and Negotiate. Requests with preconditions get 304 Not Modified
+// or 412 Precondition Failed responses from the stored validators.
+//
+// Successful requests with unsafe methods invalidate the responses stored
+// for their target URI. Range requests are forwarded as is.
+type Cache struct {
+	// Store stores the responses. Defaults to an unbounded
+	// MemoryCacheStore.
+	Store CacheStore
+
+	// Name identifies the cache in the Cache-Status header field of
+	// responses. Defaults to "htutil".
+	Name string
+
+	// MaxBodySize is the size of the largest response content to store.
+	// Defaults to 1MiB.
+	MaxBodySize int64
+}
+
+func (c *Cache) name() string {
+	if c.Name == "" {
+		return "htutil"
+	}
+	return c.Name
+}
+
+func (c *Cache) maxBodySize() int64 {
+	if c.MaxBodySize <= 0 {
+		return 1 << 20
+	}
+	return c.MaxBodySize
+}
+
+// cacheableStatus are the status codes that are heuristically cacheable,
+// as per RFC 9110 §15.1. Partial content is not handled by Cache.
+var cacheableStatus = map[int]bool{
+	http.StatusOK:                   true,
+	http.StatusNonAuthoritativeInfo: true,
+	http.StatusNoContent:            true,
+	http.StatusMultipleChoices:      true,
+	http.StatusMovedPermanently:     true,
+	http.StatusPermanentRedirect:    true,
+	http.StatusNotFound:             true,
+	http.StatusMethodNotAllowed:     true,
+	http.StatusGone:                 true,
+	http.StatusRequestURITooLong:    true,
+	http.StatusNotImplemented:       true,
+}
+
+// conditionalFields are the request fields that Cache evaluates itself
+// rather than forwarding them.
+var conditionalFields = []string{"If-Match", "If-None-Match", "If-Modified-Since", "If-Unmodified-Since"}
+
+// cacheKeyOf returns the key of the responses to GET requests for the
+// target URI of req, which also serve HEAD requests.
+func cacheKeyOf(req *http.Request) string {
+	return http.MethodGet + strings.TrimPrefix(CacheKey(req), req.Method)
+}
+
+// Middleware returns a middleware that caches the responses of next.
+func (c *Cache) Middleware(next http.Handler) http.Handler {
+	store := c.Store
+	if store == nil {
+		store = &MemoryCacheStore{}
+	}
+	return http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+		key := cacheKeyOf(req)
+		reqCC, _ := ParseCacheControl(req.Header.Values("Cache-Control")...)
+
+		switch {
+		case req.Method != http.MethodGet && req.Method != http.MethodHead:
+			status := c.forwardUncached(w, req, next, FwdMethod)
+			if !isSafe(req.Method) && status < 400 {
+				store.Delete(key)
+			}
+			return
+		case reqCC.NoStore || req.Header.Get("Range") != "":
+			c.forwardUncached(w, req, next, FwdBypass)
+			return
+		}
+
+		now := time.Now()
+		variants, _ := store.Load(key)
+		fwd := FwdURIMiss
+		var stored *CachedResponse
+		for _, variant := range variants {
+			if varyMatches(&http.Request{Header: variant.RequestHeader}, req, variant.Header) {
+				stored = variant
+				break
+			}
+		}
+		if stored == nil && len(variants) > 0 {
+			fwd = FwdVaryMiss
+		}
+		if stored != nil {
+			if usableResponse(stored, reqCC, stored.age().CurrentAge(now)) {
+				c.serve(w, req, stored, CacheStatus{Hit: true}, now)
+				return
+			}
+			fwd = FwdStale
+			if reqCC.NoCache {
+				fwd = FwdRequest
+			}
+		}
+		if reqCC.OnlyIfCached {
+			w.WriteHeader(http.StatusGatewayTimeout)
+			return
+		}
+		if req.Method == http.MethodHead {
+			c.forwardUncached(w, req, next, fwd)
+			return
+		}
+
+		// Forward the request without the preconditions of the client,
+		// which are evaluated against the response, but with the
+		// validators of the stored response, to revalidate it.
+		fwdReq := req.Clone(req.Context())
+		for _, name := range conditionalFields {
+			fwdReq.Header.Del(name)
+		}
+		if stored != nil {
+			if etag := stored.Header.Get("ETag"); etag != "" {
+				fwdReq.Header.Set("If-None-Match", etag)
+			}
+			if lastModified := stored.Header.Get("Last-Modified"); lastModified != "" {
+				fwdReq.Header.Set("If-Modified-Since", lastModified)
+			}
+		}
+
+		buf, fwdReq := newBypassableBuffer(w, fwdReq)
+		requestTime := time.Now()
+		next.ServeHTTP(buf, fwdReq)
+		if buf.bypassed {
+			return
+		}
+		responseTime := time.Now()
+		if buf.status == 0 {
+			buf.status = http.StatusOK
+		}
+		StampDate(buf.header)
+		cs := CacheStatus{Fwd: fwd, FwdStatus: buf.status}
+
+		var fresh *CachedResponse
+		switch {
+		case stored != nil && buf.status == http.StatusNotModified:
+			// Freshen the stored response with the fields of the 304, as
+			// per RFC 9111 §4.3.4.
+			fresh = &CachedResponse{
+				Status:        stored.Status,
+				Header:        stored.Header.Clone(),
+				Body:          stored.Body,
+				RequestHeader: stored.RequestHeader,
+				RequestTime:   requestTime,
+				ResponseTime:  responseTime,
+			}
+			for k, v := range buf.header {
+				if !excludedFromNotModified(k) {
+					fresh.Header[k] = v
+				}
+			}
+		case c.storable(req, buf):
+			fresh = &CachedResponse{
+				Status:        buf.status,
+				Header:        buf.header.Clone(),
+				Body:          append([]byte(nil), buf.body.Bytes()...),
+				RequestHeader: http.Header{},
+				RequestTime:   requestTime,
+				ResponseTime:  responseTime,
+			}
+			for _, field := range ParseVary(fresh.Header) {
+				if values := req.Header.Values(field); len(values) > 0 {
+					fresh.RequestHeader[field] = values
+				}
+			}
+		default:
+			if stored != nil {
+				store.Delete(key)
+			}
+			AddCacheStatus(buf.header, c.status(cs))
+			buf.replay(w, req)
+			return
+		}
+
+		updated := make([]*CachedResponse, 0, len(variants)+1)
+		updated = append(updated, fresh)
+		for _, variant := range variants {
+			if variant != stored {
+				updated = append(updated, variant)
+			}
+		}
+		store.Store(key, updated)
+		cs.Stored = true
+		c.serve(w, req, fresh, cs, responseTime)
+	})
+}
+
+// forwardUncached forwards req to next without caching its response, and
+// returns the status of the response.
+func (c *Cache) forwardUncached(w http.ResponseWriter, req *http.Request, next http.Handler, fwd string) int {
+	rec := NewRecorder(w)
+	rec.OnWriteHeader(func(status int) int {
+		AddCacheStatus(w.Header(), c.status(CacheStatus{Fwd: fwd, FwdStatus: status}))
+		return status
+	})
+	next.ServeHTTP(rec.Writer, req)
+	if !rec.Written() && !rec.Hijacked() {
+		rec.Writer.WriteHeader(http.StatusOK)
+	}
+	return rec.Status()
+}
+
+func (c *Cache) status(cs CacheStatus) CacheStatus {
+	cs.Cache = c.name()
+	return cs
+}
+
+// storable returns whether the response buffered in buf, answering req,
+// may be stored, as per RFC 9111 §3.
+func (c *Cache) storable(req *http.Request, buf *responseBuffer) bool {
+	h := buf.header
+	if !cacheableStatus[buf.status] || int64(buf.body.Len()) > c.maxBodySize() || len(h.Values("Set-Cookie")) > 0 {
+		return false
+	}
+	cc, _ := ParseCacheControl(h.Values("Cache-Control")...)
+	if cc.NoStore || cc.Private {
+		return false
+	}
+	if req.Header.Get("Authorization") != "" && !cc.Public && !cc.MustRevalidate && cc.SMaxAge == nil {
+		return false
+	}
+	if vary := ParseVary(h); len(vary) == 1 && vary[0] == "*" {
+		return false
+	}
+	_, explicit := FreshnessLifetime(h, true)
+	return explicit || h.Get("ETag") != "" || h.Get("Last-Modified") != ""
+}
+
+// usableResponse returns whether the stored response, of the specified
+// current age, may be served without revalidation to a request with the
+// Cache-Control directives reqCC, as per RFC 9111 §4.2 and §5.2.1.
+func usableResponse(stored *CachedResponse, reqCC CacheControl, age time.Duration) bool {
+	cc, _ := ParseCacheControl(stored.Header.Values("Cache-Control")...)
+	if reqCC.NoCache || cc.NoCache {
+		return false
+	}
+	lifetime, _ := FreshnessLifetime(stored.Header, true)
+	if reqCC.MaxAge != nil && age > *reqCC.MaxAge {
+		return false
+	}
+	if reqCC.MinFresh != nil && lifetime-age < *reqCC.MinFresh {
+		return false
+	}
+	if lifetime > age {
+		return true
+	}
+	if cc.MustRevalidate || cc.ProxyRevalidate || cc.SMaxAge != nil {
+		return false
+	}
+	return reqCC.MaxStale != nil && age-lifetime <= *reqCC.MaxStale
+}
+
+// serve replies to req with the stored response, evaluating the
+// preconditions of req against its validators.
+func (c *Cache) serve(w http.ResponseWriter, req *http.Request, stored *CachedResponse, cs CacheStatus, now time.Time) {
+	h := w.Header()
+	for k, v := range stored.Header {
+		h[k] = append([]string(nil), v...)
+	}
+	age := stored.age().CurrentAge(now)
+	SetAge(h, age)
+	lifetime, _ := FreshnessLifetime(stored.Header, true)
+	cs.TTL, cs.HasTTL = lifetime-age, true
+	AddCacheStatus(h, c.status(cs))
+
+	if stored.Status == http.StatusOK {
+		etag, _ := ParseETag(stored.Header.Get("ETag"))
+		modtime, _ := http.ParseTime(stored.Header.Get("Last-Modified"))
+		switch EvaluateConditionals(req, etag, modtime) {
+		case http.StatusNotModified:
+			NotModified(w, stored.Header)
+			return
+		case http.StatusPreconditionFailed:
+			for k := range stored.Header {
+				h.Del(k)
+			}
+			RespondError(w, req, NewProblem(http.StatusPreconditionFailed,
+				"The resource was modified since it was last retrieved."))
+			return
+		}
+	}
+	w.WriteHeader(stored.Status)
+	if req.Method != http.MethodHead {
+		w.Write(stored.Body)
+	}
+}
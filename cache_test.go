@@ -0,0 +1,207 @@
+// Copyright 2026 Franklin "Snaipe" Mathieu.
+//
+// Use of this source code is governed by the MIT license that can be
+// found in the LICENSE file.
+
+package htutil
+
+import (
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+type cacheStep struct {
+	Method string
+	Header http.Header
+	Status int
+	Body   string
+	Calls  int
+	Hit    bool
+}
+
+func TestCache(t *testing.T) {
+	t.Parallel()
+
+	tcases := []struct {
+		Header http.Header
+		Steps  []cacheStep
+	}{
+		{
+			Header: http.Header{"Cache-Control": {"max-age=60"}},
+			Steps: []cacheStep{
+				{Status: 200, Body: "1", Calls: 1},
+				{Status: 200, Body: "1", Calls: 1, Hit: true},
+				{Method: "HEAD", Status: 200, Calls: 1, Hit: true},
+			},
+		},
+		{
+			Header: http.Header{"Cache-Control": {"no-store"}},
+			Steps: []cacheStep{
+				{Status: 200, Body: "1", Calls: 1},
+				{Status: 200, Body: "2", Calls: 2},
+			},
+		},
+		{
+			Header: http.Header{"Cache-Control": {"private, max-age=60"}},
+			Steps: []cacheStep{
+				{Status: 200, Body: "1", Calls: 1},
+				{Status: 200, Body: "2", Calls: 2},
+			},
+		},
+		{
+			Header: http.Header{"Cache-Control": {"max-age=60"}, "Set-Cookie": {"id=1"}},
+			Steps: []cacheStep{
+				{Status: 200, Body: "1", Calls: 1},
+				{Status: 200, Body: "2", Calls: 2},
+			},
+		},
+		{
+			// Stale responses are revalidated.
+			Header: http.Header{"Cache-Control": {"max-age=0"}, "ETag": {`"v"`}},
+			Steps: []cacheStep{
+				{Status: 200, Body: "1", Calls: 1},
+				{Status: 200, Body: "1", Calls: 2},
+				{Header: http.Header{"If-None-Match": {`"v"`}}, Status: 304, Calls: 3},
+			},
+		},
+		{
+			Header: http.Header{"Cache-Control": {"max-age=60"}, "ETag": {`"v"`}},
+			Steps: []cacheStep{
+				{Header: http.Header{"If-None-Match": {`"v"`}}, Status: 304, Calls: 1},
+				{Header: http.Header{"If-None-Match": {`"v"`}}, Status: 304, Calls: 1, Hit: true},
+				{Header: http.Header{"If-None-Match": {`"w"`}}, Status: 200, Body: "1", Calls: 1, Hit: true},
+				{Header: http.Header{"If-Match": {`"w"`}}, Status: 412, Calls: 1, Hit: true},
+			},
+		},
+		{
+			Header: http.Header{"Cache-Control": {"max-age=60"}, "Vary": {"Accept-Language"}},
+			Steps: []cacheStep{
+				{Header: http.Header{"Accept-Language": {"en"}}, Status: 200, Body: "1", Calls: 1},
+				{Header: http.Header{"Accept-Language": {"fr"}}, Status: 200, Body: "2", Calls: 2},
+				{Header: http.Header{"Accept-Language": {"en"}}, Status: 200, Body: "1", Calls: 2, Hit: true},
+				{Header: http.Header{"Accept-Language": {"fr"}}, Status: 200, Body: "2", Calls: 2, Hit: true},
+			},
+		},
+		{
+			Header: http.Header{"Cache-Control": {"max-age=60"}, "Vary": {"*"}},
+			Steps: []cacheStep{
+				{Status: 200, Body: "1", Calls: 1},
+				{Status: 200, Body: "2", Calls: 2},
+			},
+		},
+		{
+			Header: http.Header{"Cache-Control": {"max-age=60"}},
+			Steps: []cacheStep{
+				{Status: 200, Body: "1", Calls: 1},
+				{Header: http.Header{"Cache-Control": {"no-cache"}}, Status: 200, Body: "2", Calls: 2},
+				{Header: http.Header{"Cache-Control": {"no-store"}}, Status: 200, Body: "3", Calls: 3},
+				{Status: 200, Body: "2", Calls: 3, Hit: true},
+				{Method: "POST", Status: 200, Body: "4", Calls: 4},
+				{Status: 200, Body: "5", Calls: 5},
+			},
+		},
+		{
+			Header: http.Header{"Cache-Control": {"max-age=60"}},
+			Steps: []cacheStep{
+				{Header: http.Header{"Cache-Control": {"only-if-cached"}}, Status: 504, Calls: 0},
+				{Header: http.Header{"Authorization": {"Bearer x"}}, Status: 200, Body: "1", Calls: 1},
+				{Status: 200, Body: "2", Calls: 2},
+				{Header: http.Header{"Cache-Control": {"only-if-cached"}}, Status: 200, Body: "2", Calls: 2, Hit: true},
+			},
+		},
+		{
+			Header: http.Header{"Cache-Control": {"public, max-age=60"}},
+			Steps: []cacheStep{
+				{Header: http.Header{"Authorization": {"Bearer x"}}, Status: 200, Body: "1", Calls: 1},
+				{Status: 200, Body: "1", Calls: 1, Hit: true},
+			},
+		},
+		{
+			// Responses without a lifetime nor validators are not stored.
+			Header: http.Header{},
+			Steps: []cacheStep{
+				{Status: 200, Body: "1", Calls: 1},
+				{Status: 200, Body: "2", Calls: 2},
+			},
+		},
+	}
+
+	for i, tcase := range tcases {
+		t.Run(fmt.Sprintf("%d", i), func(t *testing.T) {
+			var calls int
+			handler := http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+				calls++
+				for k, vs := range tcase.Header {
+					for _, v := range vs {
+						w.Header().Add(k, v)
+					}
+				}
+				if etag := w.Header().Get("ETag"); etag != "" && req.Header.Get("If-None-Match") == etag {
+					w.WriteHeader(http.StatusNotModified)
+					return
+				}
+				fmt.Fprint(w, calls)
+			})
+			cache := (&Cache{}).Middleware(handler)
+
+			for j, step := range tcase.Steps {
+				method := step.Method
+				if method == "" {
+					method = "GET"
+				}
+				req := httptest.NewRequest(method, "/resource", nil)
+				for k, vs := range step.Header {
+					for _, v := range vs {
+						req.Header.Add(k, v)
+					}
+				}
+				rw := httptest.NewRecorder()
+				cache.ServeHTTP(rw, req)
+
+				if rw.Code != step.Status {
+					t.Fatalf("step %d: expected status %d, got %d", j, step.Status, rw.Code)
+				}
+				if body := rw.Body.String(); step.Status != 412 && body != step.Body {
+					t.Fatalf("step %d: expected body %q, got %q", j, step.Body, body)
+				}
+				if calls != step.Calls {
+					t.Fatalf("step %d: expected %d handler calls, got %d", j, step.Calls, calls)
+				}
+				status := rw.Header().Get("Cache-Status")
+				if hit := strings.Contains(status, ";hit"); hit != step.Hit && step.Status != 504 {
+					t.Fatalf("step %d: expected hit %v, got Cache-Status %q", j, step.Hit, status)
+				}
+				if step.Hit && rw.Header().Get("Age") == "" {
+					t.Fatalf("step %d: expected an Age on cached responses", j)
+				}
+			}
+		})
+	}
+}
+
+func TestMemoryCacheStore(t *testing.T) {
+	t.Parallel()
+
+	var store MemoryCacheStore
+	store.MaxEntries = 2
+	store.Store("a", []*CachedResponse{{Status: 200}})
+	store.Store("b", []*CachedResponse{{Status: 200}})
+	store.Load("a")
+	store.Store("c", []*CachedResponse{{Status: 200}})
+
+	if _, ok := store.Load("b"); ok {
+		t.Fatalf("expected least recently used key to be evicted")
+	}
+	for _, key := range []string{"a", "c"} {
+		if _, ok := store.Load(key); !ok {
+			t.Fatalf("expected key %q to be stored", key)
+		}
+	}
+	store.Delete("a")
+	if _, ok := store.Load("a"); ok {
+		t.Fatalf("expected deleted key to be gone")
+	}
+}
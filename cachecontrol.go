@@ -0,0 +1,295 @@
+// Copyright 2026 Franklin "Snaipe" Mathieu.
+//
+// Use of this source code is governed by the MIT license that can be
+// found in the LICENSE file.
+
+package htutil
+
+import (
+	"fmt"
+	"math"
+	"net/http"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// CacheControl holds the directives of a Cache-Control header field, as per
+// RFC 9111 §5.2 and the extensions of RFC 5861 and RFC 8246.
+//
+// Directives with a delta-seconds argument are nil when absent; see Seconds
+// to set them.
+type CacheControl struct {
+	// MaxAge is the max-age directive. In responses, it is the freshness
+	// lifetime of the response; in requests, the maximum age of the
+	// responses that the client accepts.
+	MaxAge *time.Duration
+
+	// NoCache is the no-cache directive. In responses, NoCacheFields are
+	// the header fields that must not be sent without revalidation, if
+	// the whole response may be.
+	NoCache       bool
+	NoCacheFields []string
+
+	// NoStore is the no-store directive.
+	NoStore bool
+
+	// NoTransform is the no-transform directive.
+	NoTransform bool
+
+	// MaxStale is the max-stale request directive. A max-stale directive
+	// with no argument, accepting stale responses regardless of their
+	// staleness, is represented as math.MaxInt64.
+	MaxStale *time.Duration
+
+	// MinFresh is the min-fresh request directive.
+	MinFresh *time.Duration
+
+	// OnlyIfCached is the only-if-cached request directive.
+	OnlyIfCached bool
+
+	// SMaxAge is the s-maxage response directive, overriding MaxAge for
+	// shared caches.
+	SMaxAge *time.Duration
+
+	// MustRevalidate, ProxyRevalidate, and MustUnderstand are the
+	// homonymous response directives.
+	MustRevalidate  bool
+	ProxyRevalidate bool
+	MustUnderstand  bool
+
+	// Public is the public response directive.
+	Public bool
+
+	// Private is the private response directive. PrivateFields are the
+	// header fields intended for a single user, if the rest of the
+	// response may be stored by shared caches.
+	Private       bool
+	PrivateFields []string
+
+	// Immutable is the immutable response directive of RFC 8246.
+	Immutable bool
+
+	// StaleWhileRevalidate and StaleIfError are the response directives of
+	// RFC 5861.
+	StaleWhileRevalidate *time.Duration
+	StaleIfError         *time.Duration
+
+	// Extensions contains the unknown directives, with lowercased names,
+	// and unquoted arguments. Directives with no argument are present with
+	// an empty value.
+	Extensions map[string]string
+}
+
+// Seconds returns a pointer to a duration of n seconds, to set the
+// delta-seconds directives of a CacheControl.
+func Seconds(n int64) *time.Duration {
+	d := time.Duration(n) * time.Second
+	return &d
+}
+
+// ParseCacheControl parses the values of Cache-Control header fields.
+//
+// As per RFC 9111 §4.2.1, only the first occurrence of a directive is
+// considered. Delta-seconds greater than 2^31 are clamped to it, and
+// invalid ones are taken as 0, which makes responses stale, and reported
+// as an error along with the parsed directives.
+func ParseCacheControl(values ...string) (CacheControl, error) {
+	var (
+		cc       CacheControl
+		firstErr error
+		seen     = make(map[string]bool)
+	)
+	for _, directive := range listMembers(values) {
+		name, arg, _ := strings.Cut(directive, "=")
+		name, arg = strings.ToLower(trimOWS(name)), trimOWS(arg)
+		if strings.HasPrefix(arg, `"`) {
+			if unquoted, err := UnquoteString(arg); err == nil {
+				arg = unquoted
+			}
+		}
+		if seen[name] {
+			continue
+		}
+		seen[name] = true
+
+		var (
+			dst **time.Duration
+			err error
+		)
+		switch name {
+		case "max-age":
+			dst = &cc.MaxAge
+		case "s-maxage":
+			dst = &cc.SMaxAge
+		case "min-fresh":
+			dst = &cc.MinFresh
+		case "stale-while-revalidate":
+			dst = &cc.StaleWhileRevalidate
+		case "stale-if-error":
+			dst = &cc.StaleIfError
+		case "max-stale":
+			if arg == "" {
+				d := time.Duration(math.MaxInt64)
+				cc.MaxStale = &d
+			} else {
+				dst = &cc.MaxStale
+			}
+		case "no-cache":
+			cc.NoCache = true
+			cc.NoCacheFields = fieldNames(arg)
+		case "private":
+			cc.Private = true
+			cc.PrivateFields = fieldNames(arg)
+		case "no-store":
+			cc.NoStore = true
+		case "no-transform":
+			cc.NoTransform = true
+		case "only-if-cached":
+			cc.OnlyIfCached = true
+		case "must-revalidate":
+			cc.MustRevalidate = true
+		case "proxy-revalidate":
+			cc.ProxyRevalidate = true
+		case "must-understand":
+			cc.MustUnderstand = true
+		case "public":
+			cc.Public = true
+		case "immutable":
+			cc.Immutable = true
+		default:
+			if cc.Extensions == nil {
+				cc.Extensions = make(map[string]string)
+			}
+			cc.Extensions[name] = arg
+		}
+		if dst != nil {
+			*dst, err = parseDeltaSeconds(arg)
+			if err != nil && firstErr == nil {
+				firstErr = fmt.Errorf("parsing cache-control: %s: %w", name, err)
+			}
+		}
+	}
+	return cc, firstErr
+}
+
+// parseDeltaSeconds parses delta-seconds, as per RFC 9111 §1.2.2.
+func parseDeltaSeconds(s string) (*time.Duration, error) {
+	if s == "" {
+		return Seconds(0), fmt.Errorf("missing delta-seconds")
+	}
+	for i := 0; i < len(s); i++ {
+		if !isDigit(s[i]) {
+			return Seconds(0), fmt.Errorf("%q is not a valid delta-seconds", s)
+		}
+	}
+	n, err := strconv.ParseInt(s, 10, 64)
+	if err != nil || n > int64(maxAge/time.Second) {
+		n = int64(maxAge / time.Second)
+	}
+	return Seconds(n), nil
+}
+
+func fieldNames(arg string) []string {
+	if arg == "" {
+		return nil
+	}
+	return SplitList(arg)
+}
+
+// String returns the Cache-Control header value for cc.
+func (cc CacheControl) String() string {
+	var out []string
+	flag := func(set bool, name string) {
+		if set {
+			out = append(out, name)
+		}
+	}
+	fields := func(set bool, name string, fields []string) {
+		switch {
+		case !set:
+		case len(fields) == 0:
+			out = append(out, name)
+		default:
+			out = append(out, name+"="+QuoteString(strings.Join(fields, ", ")))
+		}
+	}
+	delta := func(d *time.Duration, name string) {
+		if d != nil {
+			out = append(out, name+"="+strconv.FormatInt(int64(*d/time.Second), 10))
+		}
+	}
+
+	flag(cc.Public, "public")
+	fields(cc.Private, "private", cc.PrivateFields)
+	fields(cc.NoCache, "no-cache", cc.NoCacheFields)
+	flag(cc.NoStore, "no-store")
+	flag(cc.NoTransform, "no-transform")
+	flag(cc.MustRevalidate, "must-revalidate")
+	flag(cc.ProxyRevalidate, "proxy-revalidate")
+	flag(cc.MustUnderstand, "must-understand")
+	delta(cc.MaxAge, "max-age")
+	delta(cc.SMaxAge, "s-maxage")
+	delta(cc.StaleWhileRevalidate, "stale-while-revalidate")
+	delta(cc.StaleIfError, "stale-if-error")
+	flag(cc.Immutable, "immutable")
+	if cc.MaxStale != nil && *cc.MaxStale == math.MaxInt64 {
+		out = append(out, "max-stale")
+	} else {
+		delta(cc.MaxStale, "max-stale")
+	}
+	delta(cc.MinFresh, "min-fresh")
+	flag(cc.OnlyIfCached, "only-if-cached")
+
+	names := make([]string, 0, len(cc.Extensions))
+	for name := range cc.Extensions {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	for _, name := range names {
+		if arg := cc.Extensions[name]; arg != "" {
+			out = append(out, name+"="+tokenOrQuoted(arg))
+		} else {
+			out = append(out, name)
+		}
+	}
+	return strings.Join(out, ", ")
+}
+
+// FreshnessLifetime returns the freshness lifetime of a response with the
+// header fields h, as per RFC 9111 §4.2.1: s-maxage for shared caches, then
+// max-age, then the difference between Expires and Date. Invalid Expires
+// values make the response stale, with a lifetime of 0.
+//
+// It returns ok=false if the response has no explicit lifetime, in which
+// case caches may estimate one heuristically.
+func FreshnessLifetime(h http.Header, shared bool) (lifetime time.Duration, ok bool) {
+	cc, _ := ParseCacheControl(h.Values("Cache-Control")...)
+	if shared && cc.SMaxAge != nil {
+		return *cc.SMaxAge, true
+	}
+	if cc.MaxAge != nil {
+		return *cc.MaxAge, true
+	}
+	if h.Get("Expires") == "" {
+		return 0, false
+	}
+	expires, err := GetHeader[time.Time](h, "Expires")
+	if err != nil {
+		return 0, true
+	}
+	date, err := GetHeader[time.Time](h, "Date")
+	if err != nil {
+		return 0, true
+	}
+	return maxDuration(0, expires.Sub(date)), true
+}
+
+// IsFresh returns whether a stored response with the header fields h, and
+// the age values age, is fresh at now, as per RFC 9111 §4.2. Responses
+// without an explicit freshness lifetime are considered stale.
+func IsFresh(h http.Header, age ResponseAge, now time.Time, shared bool) bool {
+	lifetime, ok := FreshnessLifetime(h, shared)
+	return ok && lifetime > age.CurrentAge(now)
+}
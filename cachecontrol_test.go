@@ -0,0 +1,96 @@
+// Copyright 2026 Franklin "Snaipe" Mathieu.
+//
+// Use of this source code is governed by the MIT license that can be
+// found in the LICENSE file.
+
+package htutil
+
+import (
+	"fmt"
+	"net/http"
+	"testing"
+	"time"
+)
+
+func TestParseCacheControl(t *testing.T) {
+	t.Parallel()
+
+	tcases := []struct {
+		Values []string
+		Expect string
+		Err    bool
+	}{
+		{Values: nil, Expect: ""},
+		{Values: []string{"max-age=60, PUBLIC"}, Expect: "public, max-age=60"},
+		{Values: []string{"no-cache", `private="Set-Cookie, X-Token"`}, Expect: `private="Set-Cookie, X-Token", no-cache`},
+		{Values: []string{`no-cache="Set-Cookie"`}, Expect: `no-cache="Set-Cookie"`},
+		{Values: []string{"max-age=60, max-age=0"}, Expect: "max-age=60"},
+		{Values: []string{`max-age="30"`}, Expect: "max-age=30"},
+		{Values: []string{"s-maxage=99999999999999999999"}, Expect: "s-maxage=2147483648"},
+		{Values: []string{"stale-while-revalidate=30, stale-if-error=600, immutable"}, Expect: "stale-while-revalidate=30, stale-if-error=600, immutable"},
+		{Values: []string{"max-stale, min-fresh=10, only-if-cached"}, Expect: "max-stale, min-fresh=10, only-if-cached"},
+		{Values: []string{"max-stale=5"}, Expect: "max-stale=5"},
+		{Values: []string{"no-store, no-transform, must-revalidate, proxy-revalidate, must-understand"}, Expect: "no-store, no-transform, must-revalidate, proxy-revalidate, must-understand"},
+		{Values: []string{`x-b="a b", X-A`}, Expect: `x-a, x-b="a b"`},
+		{Values: []string{"max-age=-1, public"}, Expect: "public, max-age=0", Err: true},
+		{Values: []string{"max-age"}, Expect: "max-age=0", Err: true},
+	}
+
+	for i, tcase := range tcases {
+		t.Run(fmt.Sprintf("%d", i), func(t *testing.T) {
+			cc, err := ParseCacheControl(tcase.Values...)
+			if (err != nil) != tcase.Err {
+				t.Fatalf("expected error %v, got %v", tcase.Err, err)
+			}
+			if cc.String() != tcase.Expect {
+				t.Fatalf("expected %q, got %q", tcase.Expect, cc.String())
+			}
+		})
+	}
+
+	cc := CacheControl{Public: true, MaxAge: Seconds(3600), Immutable: true}
+	if cc.String() != "public, max-age=3600, immutable" {
+		t.Fatalf("expected %q, got %q", "public, max-age=3600, immutable", cc.String())
+	}
+}
+
+func TestFreshness(t *testing.T) {
+	t.Parallel()
+
+	date := time.Date(2026, 1, 2, 3, 4, 5, 0, time.UTC)
+
+	tcases := []struct {
+		Header   http.Header
+		Shared   bool
+		Elapsed  time.Duration
+		Lifetime time.Duration
+		Explicit bool
+		Fresh    bool
+	}{
+		{Header: http.Header{}, Explicit: false, Fresh: false},
+		{Header: http.Header{"Cache-Control": {"max-age=60"}}, Lifetime: time.Minute, Explicit: true, Fresh: true},
+		{Header: http.Header{"Cache-Control": {"max-age=60"}}, Elapsed: time.Minute, Lifetime: time.Minute, Explicit: true, Fresh: false},
+		{Header: http.Header{"Cache-Control": {"max-age=60"}, "Age": {"50"}}, Elapsed: 20 * time.Second, Lifetime: time.Minute, Explicit: true, Fresh: false},
+		{Header: http.Header{"Cache-Control": {"max-age=60, s-maxage=10"}}, Shared: true, Elapsed: 20 * time.Second, Lifetime: 10 * time.Second, Explicit: true, Fresh: false},
+		{Header: http.Header{"Cache-Control": {"max-age=60, s-maxage=10"}}, Elapsed: 20 * time.Second, Lifetime: time.Minute, Explicit: true, Fresh: true},
+		{Header: http.Header{"Expires": {"Fri, 02 Jan 2026 04:04:05 GMT"}}, Lifetime: time.Hour, Explicit: true, Fresh: true},
+		{Header: http.Header{"Expires": {"0"}}, Lifetime: 0, Explicit: true, Fresh: false},
+		{Header: http.Header{"Expires": {"Fri, 02 Jan 2026 04:04:05 GMT"}, "Cache-Control": {"max-age=5"}}, Elapsed: time.Minute, Lifetime: 5 * time.Second, Explicit: true, Fresh: false},
+	}
+
+	for i, tcase := range tcases {
+		t.Run(fmt.Sprintf("%d", i), func(t *testing.T) {
+			h := tcase.Header.Clone()
+			h.Set("Date", date.Format(http.TimeFormat))
+
+			lifetime, ok := FreshnessLifetime(h, tcase.Shared)
+			if ok != tcase.Explicit || lifetime != tcase.Lifetime {
+				t.Fatalf("expected lifetime %v (%v), got %v (%v)", tcase.Lifetime, tcase.Explicit, lifetime, ok)
+			}
+			age := NewResponseAge(h, date, date)
+			if fresh := IsFresh(h, age, date.Add(tcase.Elapsed), tcase.Shared); fresh != tcase.Fresh {
+				t.Fatalf("expected fresh %v, got %v", tcase.Fresh, fresh)
+			}
+		})
+	}
+}
@@ -0,0 +1,47 @@
+// Copyright 2026 Franklin "Snaipe" Mathieu.
+//
+// Use of this source code is governed by the MIT license that can be
+// found in the LICENSE file.
+
+package htutil
+
+import (
+	"net/http"
+	"net/textproto"
+	"sort"
+	"strings"
+)
+
+// CacheKey returns a key identifying the response to req, given the list of
+// request header fields that select the representation (typically, the
+// field names listed in the Vary header of the response).
+//
+// Two requests with the same method, host, request URI, and values for the
+// vary fields have the same cache key. Field names are case-insensitive,
+// and their order does not matter.
+func CacheKey(req *http.Request, vary ...string) string {
+	names := make([]string, 0, len(vary))
+	for _, name := range vary {
+		name = textproto.CanonicalMIMEHeaderKey(strings.TrimSpace(name))
+		if name != "" {
+			names = append(names, name)
+		}
+	}
+	sort.Strings(names)
+
+	var key strings.Builder
+	key.WriteString(req.Method)
+	key.WriteByte(' ')
+	key.WriteString(req.Host)
+	key.WriteString(req.URL.RequestURI())
+	for i, name := range names {
+		if i > 0 && names[i-1] == name {
+			continue
+		}
+		key.WriteByte('\n')
+		key.WriteString(name)
+		key.WriteByte(':')
+		key.WriteString(strings.Join(req.Header.Values(name), ","))
+	}
+	return key.String()
+}
@@ -0,0 +1,174 @@
+// Copyright 2026 Franklin "Snaipe" Mathieu.
+//
+// Use of this source code is governed by the MIT license that can be
+// found in the LICENSE file.
+
+package htutil
+
+import (
+	"fmt"
+	"net/http"
+	"time"
+
+	"snai.pe/go-htutil/sfv"
+)
+
+// Reasons for forwarding a request, as reported by the fwd parameter of
+// Cache-Status.
+const (
+	FwdBypass   = "bypass"
+	FwdMethod   = "method"
+	FwdURIMiss  = "uri-miss"
+	FwdVaryMiss = "vary-miss"
+	FwdMiss     = "miss"
+	FwdRequest  = "request"
+	FwdStale    = "stale"
+	FwdPartial  = "partial"
+)
+
+// CacheStatus is a single entry of the Cache-Status header field, as per
+// RFC 9211, describing how a particular cache handled a request.
+type CacheStatus struct {
+	// Cache identifies the cache that added the entry.
+	Cache string
+
+	// Hit is true if the request was satisfied by the cache.
+	Hit bool
+
+	// Fwd is the reason the request was forwarded, if it was.
+	Fwd string
+
+	// FwdStatus is the status code of the forwarded response, or 0.
+	FwdStatus int
+
+	// TTL is the remaining freshness lifetime of the response; negative
+	// values mean that the response is stale. It is only reported if
+	// HasTTL is true.
+	TTL    time.Duration
+	HasTTL bool
+
+	// Stored is true if the cache stored the response.
+	Stored bool
+
+	// Collapsed is true if the request was collapsed with another one.
+	Collapsed bool
+
+	// Key is the cache key used for the response, if reported.
+	Key string
+
+	// Detail is additional implementation-specific information.
+	Detail string
+}
+
+func (cs CacheStatus) item() sfv.Item {
+	item := sfv.Item{Value: cs.Cache}
+	if sfv.IsToken(cs.Cache) {
+		item.Value = sfv.Token(cs.Cache)
+	}
+	add := func(key string, value interface{}) {
+		item.Params = append(item.Params, sfv.Param{Key: key, Value: value})
+	}
+	if cs.Hit {
+		add("hit", true)
+	}
+	if cs.Fwd != "" {
+		add("fwd", sfv.Token(cs.Fwd))
+	}
+	if cs.FwdStatus != 0 {
+		add("fwd-status", int64(cs.FwdStatus))
+	}
+	if cs.HasTTL {
+		add("ttl", int64(cs.TTL/time.Second))
+	}
+	if cs.Stored {
+		add("stored", true)
+	}
+	if cs.Collapsed {
+		add("collapsed", true)
+	}
+	if cs.Key != "" {
+		add("key", cs.Key)
+	}
+	if cs.Detail != "" {
+		add("detail", cs.Detail)
+	}
+	return item
+}
+
+// String formats cs as a single Cache-Status list member.
+func (cs CacheStatus) String() string {
+	s, err := sfv.FormatItem(cs.item())
+	if err != nil {
+		return ""
+	}
+	return s
+}
+
+// ParseCacheStatus parses a Cache-Status header value into its entries,
+// ordered from the cache closest to the origin to the one closest to the
+// client. Unknown parameters are ignored.
+func ParseCacheStatus(value string) ([]CacheStatus, error) {
+	list, err := sfv.ParseList(value)
+	if err != nil {
+		return nil, fmt.Errorf("parsing cache-status: %w", err)
+	}
+	out := make([]CacheStatus, 0, len(list))
+	for _, member := range list {
+		item, ok := member.(sfv.Item)
+		if !ok {
+			return nil, fmt.Errorf("parsing cache-status: unexpected inner list")
+		}
+		var cs CacheStatus
+		switch v := item.Value.(type) {
+		case sfv.Token:
+			cs.Cache = string(v)
+		case string:
+			cs.Cache = v
+		default:
+			return nil, fmt.Errorf("parsing cache-status: %v is not a token or a string", v)
+		}
+		for _, param := range item.Params {
+			switch v := param.Value.(type) {
+			case bool:
+				switch param.Key {
+				case "hit":
+					cs.Hit = v
+				case "stored":
+					cs.Stored = v
+				case "collapsed":
+					cs.Collapsed = v
+				}
+			case sfv.Token:
+				switch param.Key {
+				case "fwd":
+					cs.Fwd = string(v)
+				case "detail":
+					cs.Detail = string(v)
+				}
+			case int64:
+				switch param.Key {
+				case "fwd-status":
+					cs.FwdStatus = int(v)
+				case "ttl":
+					cs.TTL = time.Duration(v) * time.Second
+					cs.HasTTL = true
+				}
+			case string:
+				switch param.Key {
+				case "key":
+					cs.Key = v
+				case "detail":
+					cs.Detail = v
+				}
+			}
+		}
+		out = append(out, cs)
+	}
+	return out, nil
+}
+
+// AddCacheStatus appends cs to the Cache-Status header field of h. Caches
+// must append their entry after the ones of the caches before them.
+func AddCacheStatus(h http.Header, cs CacheStatus) {
+	h.Add("Cache-Status", cs.String())
+}
@@ -0,0 +1,48 @@
+// Copyright 2026 Franklin "Snaipe" Mathieu.
+//
+// Use of this source code is governed by the MIT license that can be
+// found in the LICENSE file.
+
+package htutil
+
+import (
+	"net/http"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestCacheStatus(t *testing.T) {
+	t.Parallel()
+
+	const value = `OriginCache; hit; ttl=1100, "CDN Company Here"; fwd=uri-miss; fwd-status=200; stored; key="/foo"; detail=lru, ReverseProxyCache; hit; ttl=-412; collapsed`
+
+	expected := []CacheStatus{
+		{Cache: "OriginCache", Hit: true, TTL: 1100 * time.Second, HasTTL: true},
+		{Cache: "CDN Company Here", Fwd: FwdURIMiss, FwdStatus: 200, Stored: true, Key: "/foo", Detail: "lru"},
+		{Cache: "ReverseProxyCache", Hit: true, TTL: -412 * time.Second, HasTTL: true, Collapsed: true},
+	}
+
+	actual, err := ParseCacheStatus(value)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !reflect.DeepEqual(actual, expected) {
+		t.Fatalf("expected %+v, got %+v", expected, actual)
+	}
+
+	h := http.Header{}
+	for _, cs := range expected {
+		AddCacheStatus(h, cs)
+	}
+	const canonical = `OriginCache;hit;ttl=1100, "CDN Company Here";fwd=uri-miss;fwd-status=200;stored;key="/foo";detail="lru", ReverseProxyCache;hit;ttl=-412;collapsed`
+	joined := strings.Join(h.Values("Cache-Status"), ", ")
+	if joined != canonical {
+		t.Fatalf("expected %s, got %s", canonical, joined)
+	}
+
+	if _, err := ParseCacheStatus("cache; hit="); err == nil {
+		t.Fatalf("expected parse error")
+	}
+}
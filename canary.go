@@ -0,0 +1,121 @@
+// Copyright 2026 Franklin "Snaipe" Mathieu.
+//
+// Use of this source code is governed by the MIT license that can be
+// found in the LICENSE file.
+
+package htutil
+
+import (
+	"hash/fnv"
+	"log/slog"
+	"math/rand"
+	"net/http"
+	"time"
+)
+
+// Canary routes a percentage of requests to an alternate handler, such as
+// a new version of a service, or a httputil.ReverseProxy to one.
+//
+// Assignments are sticky when Header or Cookie is set: requests carrying
+// the same Header value are always routed the same way, and so are
+// requests from clients that kept the Cookie. Responses carry the variant
+// they were served by in VariantHeader, and Vary on whatever the
+// assignment depends on, so that caches keep variants separate.
+type Canary struct {
+	// Handler serves the requests routed to the canary.
+	Handler http.Handler
+
+	// Percent is the percentage of requests routed to the canary, between
+	// 0 and 100.
+	Percent float64
+
+	// Header, if set, is the request header field whose value is hashed to
+	// assign requests, e.g. a user or tenant identifier. Requests without
+	// it fall back to Cookie.
+	Header string
+
+	// Cookie, if set, is the name of the cookie persisting the assignment
+	// of clients.
+	Cookie string
+
+	// CookieMaxAge is the lifetime of the cookie. Defaults to 30 days.
+	CookieMaxAge time.Duration
+
+	// VariantHeader is the response header field set to "canary" or
+	// "stable" depending on the variant serving the request. Defaults to
+	// X-Variant.
+	VariantHeader string
+}
+
+const (
+	canaryVariant = "canary"
+	stableVariant = "stable"
+)
+
+// assign returns the variant of req, and whether it needs to be persisted
+// in a cookie.
+func (c *Canary) assign(req *http.Request, h http.Header) (variant string, persist bool) {
+	if c.Header != "" {
+		AddVary(h, c.Header)
+		if v := req.Header.Get(c.Header); v != "" {
+			hash := fnv.New32a()
+			hash.Write([]byte(v))
+			return c.pick(float64(hash.Sum32()%10000) / 100), false
+		}
+	}
+	if c.Cookie != "" {
+		AddVary(h, "Cookie")
+		if cookie, err := req.Cookie(c.Cookie); err == nil {
+			switch cookie.Value {
+			case canaryVariant, stableVariant:
+				return cookie.Value, false
+			}
+		}
+		return c.pick(rand.Float64() * 100), true
+	}
+	return c.pick(rand.Float64() * 100), false
+}
+
+func (c *Canary) pick(roll float64) string {
+	if roll < c.Percent {
+		return canaryVariant
+	}
+	return stableVariant
+}
+
+// Middleware returns a middleware that routes requests either to c.Handler
+// or to next.
+func (c *Canary) Middleware(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+		variant, persist := c.assign(req, w.Header())
+
+		name := c.VariantHeader
+		if name == "" {
+			name = "X-Variant"
+		}
+		w.Header().Set(name, variant)
+		ContextLogger(req.Context()).Debug("assigned variant",
+			slog.String("variant", variant), slog.Bool("new", persist))
+
+		if persist {
+			maxAge := c.CookieMaxAge
+			if maxAge <= 0 {
+				maxAge = 30 * 24 * time.Hour
+			}
+			http.SetCookie(w, &http.Cookie{
+				Name:     c.Cookie,
+				Value:    variant,
+				Path:     "/",
+				MaxAge:   int(maxAge / time.Second),
+				HttpOnly: true,
+				SameSite: http.SameSiteLaxMode,
+			})
+		}
+
+		if variant == canaryVariant {
+			c.Handler.ServeHTTP(w, req)
+		} else {
+			next.ServeHTTP(w, req)
+		}
+	})
+}
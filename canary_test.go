@@ -0,0 +1,100 @@
+// Copyright 2026 Franklin "Snaipe" Mathieu.
+//
+// Use of this source code is governed by the MIT license that can be
+// found in the LICENSE file.
+
+package htutil
+
+import (
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+func TestCanary(t *testing.T) {
+	t.Parallel()
+
+	stable := http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) { fmt.Fprint(w, "stable") })
+	canary := http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) { fmt.Fprint(w, "canary") })
+
+	tcases := []struct {
+		Canary    Canary
+		Header    http.Header
+		Body      string
+		Vary      []string
+		SetCookie bool
+	}{
+		{Canary: Canary{Percent: 0}, Body: "stable"},
+		{Canary: Canary{Percent: 100}, Body: "canary"},
+		{
+			Canary:    Canary{Percent: 100, Cookie: "rollout"},
+			Body:      "canary",
+			Vary:      []string{"Cookie"},
+			SetCookie: true,
+		},
+		{
+			Canary: Canary{Percent: 100, Cookie: "rollout"},
+			Header: http.Header{"Cookie": {"rollout=stable"}},
+			Body:   "stable",
+			Vary:   []string{"Cookie"},
+		},
+		{
+			Canary: Canary{Percent: 0, Header: "X-User", Cookie: "rollout"},
+			Header: http.Header{"X-User": {"alice"}, "Cookie": {"rollout=canary"}},
+			Body:   "stable",
+			Vary:   []string{"X-User"},
+		},
+	}
+
+	for i, tcase := range tcases {
+		t.Run(fmt.Sprintf("%d", i), func(t *testing.T) {
+			tcase.Canary.Handler = canary
+			req := httptest.NewRequest("GET", "/", nil)
+			for k, v := range tcase.Header {
+				req.Header[k] = v
+			}
+			w := httptest.NewRecorder()
+			tcase.Canary.Middleware(stable).ServeHTTP(w, req)
+
+			if body := w.Body.String(); body != tcase.Body {
+				t.Fatalf("expected body %q, got %q", tcase.Body, body)
+			}
+			if v := w.Header().Get("X-Variant"); v != tcase.Body {
+				t.Fatalf("expected X-Variant %q, got %q", tcase.Body, v)
+			}
+			if vary := w.Header().Values("Vary"); fmt.Sprint(vary) != fmt.Sprint(tcase.Vary) {
+				t.Fatalf("expected Vary %v, got %v", tcase.Vary, vary)
+			}
+			if set := w.Header().Get("Set-Cookie") != ""; set != tcase.SetCookie {
+				t.Fatalf("expected Set-Cookie %v, got %q", tcase.SetCookie, w.Header().Get("Set-Cookie"))
+			}
+			if set := w.Header().Get("Set-Cookie"); set != "" && !strings.Contains(set, "Max-Age=2592000") {
+				t.Fatalf("expected a 30 days Max-Age, got %q", set)
+			}
+		})
+	}
+}
+
+func TestCanaryStickyHeader(t *testing.T) {
+	t.Parallel()
+
+	c := &Canary{Percent: 50, Header: "X-User"}
+	canaries := 0
+	for i := 0; i < 1000; i++ {
+		req := httptest.NewRequest("GET", "/", nil)
+		req.Header.Set("X-User", fmt.Sprintf("user-%d", i))
+		first, _ := c.assign(req, http.Header{})
+		again, _ := c.assign(req, http.Header{})
+		if first != again {
+			t.Fatalf("expected sticky assignment for user-%d, got %s then %s", i, first, again)
+		}
+		if first == canaryVariant {
+			canaries++
+		}
+	}
+	if canaries < 400 || canaries > 600 {
+		t.Fatalf("expected about 500 canary assignments, got %d", canaries)
+	}
+}
@@ -0,0 +1,401 @@
+// Copyright 2026 Franklin "Snaipe" Mathieu.
+//
+// Use of this source code is governed by the MIT license that can be
+// found in the LICENSE file.
+
+package htutil
+
+import (
+	"bytes"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"sort"
+	"strings"
+	"time"
+)
+
+// This file implements the canonicalization machinery shared by signature
+// schemes in the fashion of AWS Signature Version 4, where the signer and
+// the verifier each serialize the request into a canonical form, and sign
+// a digest of it.
+
+// UnsignedPayload is the payload hash of requests whose body is not
+// covered by the signature.
+const UnsignedPayload = "UNSIGNED-PAYLOAD"
+
+// CanonicalRequest is the canonical form of a request.
+type CanonicalRequest struct {
+	Method string
+
+	// URI is the canonical path, as returned by CanonicalURI.
+	URI string
+
+	// Query is the canonical query string, as returned by CanonicalQuery.
+	Query string
+
+	// Headers are the canonical header fields, as returned by
+	// CanonicalHeaders.
+	Headers string
+
+	// SignedHeaders are the lowercase names of the fields of Headers, in
+	// order.
+	SignedHeaders []string
+
+	// PayloadHash is the hex-encoded digest of the request body, or
+	// UnsignedPayload.
+	PayloadHash string
+}
+
+// NewCanonicalRequest returns the canonical form of req, covering the
+// header fields named in headers, and the specified payload hash. The
+// "host" field refers to the host of req, which is not part of req.Header.
+//
+// Each segment of the path is percent-encoded once if doubleEncode is
+// false, and twice otherwise, as required by most SigV4 services.
+func NewCanonicalRequest(req *http.Request, headers []string, payloadHash string, doubleEncode bool) *CanonicalRequest {
+	host := req.Host
+	if host == "" {
+		host = req.URL.Host
+	}
+	h := req.Header.Clone()
+	if h == nil {
+		h = http.Header{}
+	}
+	h.Set("Host", host)
+
+	fields, signed := CanonicalHeaders(h, headers)
+	return &CanonicalRequest{
+		Method:        req.Method,
+		URI:           CanonicalURI(req.URL, doubleEncode),
+		Query:         CanonicalQuery(req.URL.Query()),
+		Headers:       fields,
+		SignedHeaders: signed,
+		PayloadHash:   payloadHash,
+	}
+}
+
+// String returns the serialization of c, which is the method, URI, query,
+// header fields, signed header names, and payload hash of c, each on its own
+// line.
+func (c *CanonicalRequest) String() string {
+	return strings.Join([]string{
+		c.Method,
+		c.URI,
+		c.Query,
+		c.Headers,
+		strings.Join(c.SignedHeaders, ";"),
+		c.PayloadHash,
+	}, "\n")
+}
+
+// Hash returns the hex-encoded SHA-256 digest of the serialization of c.
+func (c *CanonicalRequest) Hash() string {
+	sum := sha256.Sum256([]byte(c.String()))
+	return hex.EncodeToString(sum[:])
+}
+
+// uriEncode percent-encodes every byte of s but the unreserved characters
+// of RFC 3986.
+func uriEncode(s string) string {
+	var out strings.Builder
+	for i := 0; i < len(s); i++ {
+		c := s[i]
+		switch {
+		case 'A' <= c && c <= 'Z', 'a' <= c && c <= 'z', '0' <= c && c <= '9',
+			c == '-', c == '.', c == '_', c == '~':
+			out.WriteByte(c)
+		default:
+			fmt.Fprintf(&out, "%%%02X", c)
+		}
+	}
+	return out.String()
+}
+
+// CanonicalURI returns the canonical path of u, where each segment is
+// percent-encoded, once or twice, with only the unreserved characters of
+// RFC 3986 left as is. The canonical path of an empty path is "/".
+func CanonicalURI(u *url.URL, doubleEncode bool) string {
+	path := u.EscapedPath()
+	if path == "" {
+		return "/"
+	}
+	segments := strings.Split(path, "/")
+	for i, seg := range segments {
+		if unescaped, err := url.PathUnescape(seg); err == nil {
+			seg = unescaped
+		}
+		seg = uriEncode(seg)
+		if doubleEncode {
+			seg = uriEncode(seg)
+		}
+		segments[i] = seg
+	}
+	return strings.Join(segments, "/")
+}
+
+// CanonicalQuery returns the canonical query string of query, where the
+// parameters are percent-encoded, and sorted by name, then by value.
+// Parameters without a value have an empty value.
+func CanonicalQuery(query url.Values) string {
+	type param struct{ name, value string }
+	params := make([]param, 0, len(query))
+	for name, values := range query {
+		for _, v := range values {
+			params = append(params, param{uriEncode(name), uriEncode(v)})
+		}
+	}
+	// Sorting the joined "name=value" strings would order "a-b=1" before
+	// "a=1", since '-' sorts before '='.
+	sort.Slice(params, func(i, j int) bool {
+		if params[i].name != params[j].name {
+			return params[i].name < params[j].name
+		}
+		return params[i].value < params[j].value
+	})
+	var out strings.Builder
+	for i, p := range params {
+		if i > 0 {
+			out.WriteByte('&')
+		}
+		out.WriteString(p.name + "=" + p.value)
+	}
+	return out.String()
+}
+
+// CanonicalHeaders returns the canonical form of the header fields of h
+// named in names, and their lowercase names, sorted. Absent fields are left
+// out.
+//
+// Each field is serialized on its own line as its lowercase name, a colon,
+// and its values, trimmed, with sequences of spaces collapsed, and joined
+// by commas.
+func CanonicalHeaders(h http.Header, names []string) (string, []string) {
+	var signed []string
+	for _, name := range names {
+		name = strings.ToLower(name)
+		if len(h.Values(name)) > 0 && !containsString(signed, name) {
+			signed = append(signed, name)
+		}
+	}
+	sort.Strings(signed)
+
+	var out strings.Builder
+	for _, name := range signed {
+		var values []string
+		for _, v := range h.Values(name) {
+			values = append(values, strings.Join(strings.Fields(v), " "))
+		}
+		fmt.Fprintf(&out, "%s:%s\n", name, strings.Join(values, ","))
+	}
+	return out.String(), signed
+}
+
+// PayloadHash returns the hex-encoded SHA-256 digest of the body of req.
+//
+// The body is read using req.GetBody if set; otherwise, it is read in full,
+// and replaced by a buffered copy, and req.GetBody is set accordingly.
+func PayloadHash(req *http.Request) (string, error) {
+	h := sha256.New()
+	if req.Body == nil || req.Body == http.NoBody {
+		return hex.EncodeToString(h.Sum(nil)), nil
+	}
+
+	if req.GetBody != nil {
+		body, err := req.GetBody()
+		if err != nil {
+			return "", fmt.Errorf("hashing payload: %w", err)
+		}
+		defer body.Close()
+		if _, err := io.Copy(h, body); err != nil {
+			return "", fmt.Errorf("hashing payload: %w", err)
+		}
+		return hex.EncodeToString(h.Sum(nil)), nil
+	}
+
+	data, err := io.ReadAll(req.Body)
+	req.Body.Close()
+	if err != nil {
+		return "", fmt.Errorf("hashing payload: %w", err)
+	}
+	req.Body = io.NopCloser(bytes.NewReader(data))
+	req.GetBody = func() (io.ReadCloser, error) {
+		return io.NopCloser(bytes.NewReader(data)), nil
+	}
+	h.Write(data)
+	return hex.EncodeToString(h.Sum(nil)), nil
+}
+
+// CanonicalTimeFormat is the ISO 8601 basic format of signing times.
+const CanonicalTimeFormat = "20060102T150405Z"
+
+// CanonicalSignature describes a signature computed by a CanonicalSigner.
+type CanonicalSignature struct {
+	Algorithm     string
+	Time          time.Time
+	KeyID         string
+	Scope         string
+	SignedHeaders []string
+
+	// Signature is the hex-encoded signature.
+	Signature string
+}
+
+// CanonicalSigner signs requests with a scheme based on canonical requests,
+// in the fashion of AWS Signature Version 4.
+//
+// The string to sign is made of the algorithm name, the signing time, the
+// credential scope, and the hash of the canonical request, each on its own
+// line. It is signed with HMAC-SHA256, using a key derived by DeriveKey.
+type CanonicalSigner struct {
+	// Algorithm is the name of the signature algorithm, such as
+	// "AWS4-HMAC-SHA256".
+	Algorithm string
+
+	// KeyID identifies the credentials of the signer.
+	KeyID string
+
+	// Scope returns the credential scope of signatures made at t, such as
+	// "20150830/us-east-1/iam/aws4_request". The scope is empty if nil.
+	Scope func(t time.Time) string
+
+	// DeriveKey returns the signing key of signatures made at t, with the
+	// specified credential scope.
+	DeriveKey func(t time.Time, scope string) ([]byte, error)
+
+	// Headers are the names of the header fields covered by signatures,
+	// in addition to "host", DateHeader, and PayloadHashHeader. Absent
+	// fields are not covered.
+	Headers []string
+
+	// DateHeader is the field carrying the signing time, in the
+	// CanonicalTimeFormat. If a request already has this field, its
+	// value is used as the signing time. Defaults to "X-Date".
+	DateHeader string
+
+	// PayloadHashHeader is the field carrying the payload hash, such as
+	// "X-Amz-Content-Sha256". The payload hash is not sent if empty.
+	PayloadHashHeader string
+
+	// UnsignedPayload, if set, leaves the request body out of signatures.
+	UnsignedPayload bool
+
+	// DoubleEncode, if set, percent-encodes path segments twice in
+	// canonical requests.
+	DoubleEncode bool
+
+	// Authorization formats the Authorization field value for sig. If nil,
+	// the value is the algorithm name, followed by the Credential (KeyID
+	// and scope, separated by a slash), SignedHeaders, and Signature
+	// parameters.
+	Authorization func(sig *CanonicalSignature) string
+}
+
+func (s *CanonicalSigner) dateHeader() string {
+	if s.DateHeader == "" {
+		return "X-Date"
+	}
+	return s.DateHeader
+}
+
+// Sign stamps req with the signing time and the payload hash, and sets its
+// Authorization header field to a signature of the request.
+func (s *CanonicalSigner) Sign(req *http.Request) error {
+	t := time.Now().UTC()
+	if v := req.Header.Get(s.dateHeader()); v != "" {
+		var err error
+		if t, err = time.Parse(CanonicalTimeFormat, v); err != nil {
+			return fmt.Errorf("parsing %s: %w", strings.ToLower(s.dateHeader()), err)
+		}
+	}
+	req.Header.Set(s.dateHeader(), t.Format(CanonicalTimeFormat))
+
+	payloadHash := UnsignedPayload
+	if !s.UnsignedPayload {
+		var err error
+		if payloadHash, err = PayloadHash(req); err != nil {
+			return err
+		}
+	}
+	if s.PayloadHashHeader != "" {
+		req.Header.Set(s.PayloadHashHeader, payloadHash)
+	}
+
+	headers := append([]string{"host", s.dateHeader()}, s.Headers...)
+	if s.PayloadHashHeader != "" {
+		headers = append(headers, s.PayloadHashHeader)
+	}
+	sig, err := s.sign(req, t, headers, payloadHash)
+	if err != nil {
+		return err
+	}
+
+	authorization := s.Authorization
+	if authorization == nil {
+		authorization = defaultCanonicalAuthorization
+	}
+	req.Header.Set("Authorization", authorization(sig))
+	return nil
+}
+
+// Signature computes the signature of req made at t, covering the specified
+// header fields. Verifiers call it with the parameters of a received
+// signature, and compare the result to it with hmac.Equal.
+func (s *CanonicalSigner) Signature(req *http.Request, t time.Time, signedHeaders []string) (*CanonicalSignature, error) {
+	payloadHash := UnsignedPayload
+	if !s.UnsignedPayload {
+		var err error
+		if payloadHash, err = PayloadHash(req); err != nil {
+			return nil, err
+		}
+	}
+	return s.sign(req, t, signedHeaders, payloadHash)
+}
+
+// StringToSign returns the string to sign for the canonical request creq,
+// signed at t with the specified credential scope.
+func (s *CanonicalSigner) StringToSign(creq *CanonicalRequest, t time.Time, scope string) string {
+	return strings.Join([]string{
+		s.Algorithm,
+		t.UTC().Format(CanonicalTimeFormat),
+		scope,
+		creq.Hash(),
+	}, "\n")
+}
+
+func (s *CanonicalSigner) sign(req *http.Request, t time.Time, headers []string, payloadHash string) (*CanonicalSignature, error) {
+	var scope string
+	if s.Scope != nil {
+		scope = s.Scope(t)
+	}
+	key, err := s.DeriveKey(t, scope)
+	if err != nil {
+		return nil, fmt.Errorf("deriving signing key: %w", err)
+	}
+
+	creq := NewCanonicalRequest(req, headers, payloadHash, s.DoubleEncode)
+	mac := hmac.New(sha256.New, key)
+	mac.Write([]byte(s.StringToSign(creq, t, scope)))
+
+	return &CanonicalSignature{
+		Algorithm:     s.Algorithm,
+		Time:          t,
+		KeyID:         s.KeyID,
+		Scope:         scope,
+		SignedHeaders: creq.SignedHeaders,
+		Signature:     hex.EncodeToString(mac.Sum(nil)),
+	}, nil
+}
+
+func defaultCanonicalAuthorization(sig *CanonicalSignature) string {
+	credential := sig.KeyID
+	if sig.Scope != "" {
+		credential += "/" + sig.Scope
+	}
+	return fmt.Sprintf("%s Credential=%s, SignedHeaders=%s, Signature=%s",
+		sig.Algorithm, credential, strings.Join(sig.SignedHeaders, ";"), sig.Signature)
+}
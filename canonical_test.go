@@ -0,0 +1,189 @@
+// Copyright 2026 Franklin "Snaipe" Mathieu.
+//
+// Use of this source code is governed by the MIT license that can be
+// found in the LICENSE file.
+
+package htutil
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"testing"
+	"time"
+)
+
+// sigv4 returns a CanonicalSigner implementing AWS Signature Version 4,
+// with the credentials of the AWS test suite.
+func sigv4() *CanonicalSigner {
+	const (
+		region  = "us-east-1"
+		service = "service"
+		secret  = "wJalrXUtnFEMI/K7MDENG+bPxRfiCYEXAMPLEKEY"
+	)
+	hmacSHA256 := func(key []byte, data string) []byte {
+		mac := hmac.New(sha256.New, key)
+		mac.Write([]byte(data))
+		return mac.Sum(nil)
+	}
+	return &CanonicalSigner{
+		Algorithm:  "AWS4-HMAC-SHA256",
+		KeyID:      "AKIDEXAMPLE",
+		DateHeader: "X-Amz-Date",
+		Scope: func(t time.Time) string {
+			return t.Format("20060102") + "/" + region + "/" + service + "/aws4_request"
+		},
+		DeriveKey: func(t time.Time, scope string) ([]byte, error) {
+			key := hmacSHA256([]byte("AWS4"+secret), t.Format("20060102"))
+			key = hmacSHA256(key, region)
+			key = hmacSHA256(key, service)
+			return hmacSHA256(key, "aws4_request"), nil
+		},
+	}
+}
+
+func TestCanonicalSigner(t *testing.T) {
+	t.Parallel()
+
+	tcases := []struct {
+		Method        string
+		Target        string
+		Body          string
+		Header        http.Header
+		Authorization string
+	}{
+		{
+			Method:        "GET",
+			Target:        "/",
+			Authorization: "AWS4-HMAC-SHA256 Credential=AKIDEXAMPLE/20150830/us-east-1/service/aws4_request, SignedHeaders=host;x-amz-date, Signature=5fa00fa31553b73ebf1942676e86291e8372ff2a2260956d9b8aae1d763fbf31",
+		},
+		{
+			Method:        "GET",
+			Target:        "/?Param2=value2&Param1=value1",
+			Authorization: "AWS4-HMAC-SHA256 Credential=AKIDEXAMPLE/20150830/us-east-1/service/aws4_request, SignedHeaders=host;x-amz-date, Signature=b97d918cfa904a5beff61c982a1b6f458b799221646efd99d3219ec94cdf2500",
+		},
+		{
+			Method:        "POST",
+			Target:        "/",
+			Body:          "Param1=value1",
+			Header:        http.Header{"Content-Type": {"application/x-www-form-urlencoded"}},
+			Authorization: "AWS4-HMAC-SHA256 Credential=AKIDEXAMPLE/20150830/us-east-1/service/aws4_request, SignedHeaders=content-type;host;x-amz-date, Signature=ff11897932ad3f4e8b18135d722051e5ac45fc38421b1da7b9d196a0fe09473a",
+		},
+	}
+
+	for i, tcase := range tcases {
+		t.Run(fmt.Sprintf("%d", i), func(t *testing.T) {
+			req := httptest.NewRequest(tcase.Method, "http://example.amazonaws.com"+tcase.Target, strings.NewReader(tcase.Body))
+			for k, v := range tcase.Header {
+				req.Header[k] = v
+			}
+			req.Header.Set("X-Amz-Date", "20150830T123600Z")
+
+			signer := sigv4()
+			signer.Headers = []string{"Content-Type"}
+			if err := signer.Sign(req); err != nil {
+				t.Fatal(err)
+			}
+			if v := req.Header.Get("Authorization"); v != tcase.Authorization {
+				t.Fatalf("expected Authorization %q, got %q", tcase.Authorization, v)
+			}
+
+			// The body must still be readable after signing, and a verifier
+			// must compute the same signature.
+			sig, err := signer.Signature(req, time.Date(2015, 8, 30, 12, 36, 0, 0, time.UTC), []string{"host", "x-amz-date", "content-type"})
+			if err != nil {
+				t.Fatal(err)
+			}
+			if !strings.HasSuffix(tcase.Authorization, "Signature="+sig.Signature) {
+				t.Fatalf("expected verifier signature to match %q, got %q", tcase.Authorization, sig.Signature)
+			}
+		})
+	}
+}
+
+func TestCanonicalRequest(t *testing.T) {
+	t.Parallel()
+
+	tcases := []struct {
+		Target       string
+		DoubleEncode bool
+		Header       http.Header
+		Headers      []string
+		Canonical    string
+	}{
+		{
+			Target:    "/",
+			Canonical: "GET\n/\n\n\n\nUNSIGNED-PAYLOAD",
+		},
+		{
+			Target:    "/a%20b/c~d/?b=2&a=1&a=0&c",
+			Header:    http.Header{"X-Foo": {"  a   b ", "c"}},
+			Headers:   []string{"X-Foo", "host", "X-Missing"},
+			Canonical: "GET\n/a%20b/c~d/\na=0&a=1&b=2&c=\nhost:example.com\nx-foo:a b,c\n\nhost;x-foo\nUNSIGNED-PAYLOAD",
+		},
+		{
+			Target:       "/a%20b/$",
+			DoubleEncode: true,
+			Canonical:    "GET\n/a%2520b/%2524\n\n\n\nUNSIGNED-PAYLOAD",
+		},
+	}
+
+	for i, tcase := range tcases {
+		t.Run(fmt.Sprintf("%d", i), func(t *testing.T) {
+			req := httptest.NewRequest("GET", "http://example.com"+tcase.Target, nil)
+			for k, v := range tcase.Header {
+				req.Header[k] = v
+			}
+			creq := NewCanonicalRequest(req, tcase.Headers, UnsignedPayload, tcase.DoubleEncode)
+			if s := creq.String(); s != tcase.Canonical {
+				t.Fatalf("expected %q, got %q", tcase.Canonical, s)
+			}
+		})
+	}
+}
+
+func TestCanonicalQuery(t *testing.T) {
+	t.Parallel()
+
+	tcases := []struct {
+		Query    url.Values
+		Expected string
+	}{
+		{Query: url.Values{"b": {"2", "1"}, "a": {""}}, Expected: "a=&b=1&b=2"},
+		{Query: url.Values{"a": {"1"}, "a-b": {"1"}}, Expected: "a=1&a-b=1"},
+		{Query: url.Values{"a": {"x y", "x"}}, Expected: "a=x&a=x%20y"},
+	}
+
+	for i, tcase := range tcases {
+		t.Run(fmt.Sprintf("%d", i), func(t *testing.T) {
+			if q := CanonicalQuery(tcase.Query); q != tcase.Expected {
+				t.Fatalf("expected %q, got %q", tcase.Expected, q)
+			}
+		})
+	}
+}
+
+func TestPayloadHash(t *testing.T) {
+	t.Parallel()
+
+	req := httptest.NewRequest("PUT", "/", strings.NewReader("hello"))
+	want := "2cf24dba5fb0a30e26e83b2ac5b9e29e1b161e5c1fa7425e73043362938b9824"
+	for i := 0; i < 2; i++ {
+		hash, err := PayloadHash(req)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if hash != want {
+			t.Fatalf("expected %v, got %v", want, hash)
+		}
+	}
+
+	if body, _ := io.ReadAll(req.Body); string(body) != "hello" {
+		t.Fatalf("expected body to be preserved, got %q", body)
+	}
+}
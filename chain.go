@@ -0,0 +1,169 @@
+// Copyright 2026 Franklin "Snaipe" Mathieu.
+//
+// Use of this source code is governed by the MIT license that can be
+// found in the LICENSE file.
+
+package htutil
+
+import (
+	"mime"
+	"net/http"
+	"strings"
+)
+
+// Middleware wraps a handler with additional behavior. The Middleware
+// methods of the middleware types of this package, like
+// Compression.Middleware, are Middlewares, and so is TraceContext. The
+// middleware functions that take arguments besides next, like Deprecate,
+// Conditional, or RequestID, are not; they are adapted with a closure:
+//
+//	chain = chain.Use(func(next http.Handler) http.Handler {
+//		return htutil.Conditional(next, htutil.DigestETagger{})
+//	})
+type Middleware func(next http.Handler) http.Handler
+
+type chainEntry struct {
+	name string
+	mw   Middleware
+}
+
+// Chain is an ordered list of middlewares. The first middleware of a chain
+// is the outermost one: it sees requests first, and responses last.
+//
+// Chains are immutable; all of their methods return a new chain, so that
+// routes can extend or override a common chain without affecting it or each
+// other:
+//
+//	base := htutil.NewChain(logging, compression.Middleware).UseNamed("auth", auth)
+//	mux.Handle("/", base.Then(site))
+//	mux.Handle("/health", base.Without("auth").Then(health))
+type Chain struct {
+	entries []chainEntry
+}
+
+// NewChain returns a chain of the specified middlewares.
+func NewChain(mws ...Middleware) Chain {
+	return Chain{}.Use(mws...)
+}
+
+func (c Chain) with(entries ...chainEntry) Chain {
+	out := make([]chainEntry, 0, len(c.entries)+len(entries))
+	return Chain{entries: append(append(out, c.entries...), entries...)}
+}
+
+// Use returns a copy of c with mws appended, as inner middlewares.
+func (c Chain) Use(mws ...Middleware) Chain {
+	entries := make([]chainEntry, len(mws))
+	for i, mw := range mws {
+		entries[i] = chainEntry{mw: mw}
+	}
+	return c.with(entries...)
+}
+
+// UseNamed returns a copy of c with mw appended under name, so that it can
+// be removed or replaced by Without and Replace.
+func (c Chain) UseNamed(name string, mw Middleware) Chain {
+	return c.with(chainEntry{name: name, mw: mw})
+}
+
+// Without returns a copy of c without the middlewares of the specified
+// names.
+func (c Chain) Without(names ...string) Chain {
+	out := Chain{entries: make([]chainEntry, 0, len(c.entries))}
+	for _, e := range c.entries {
+		if e.name == "" || !containsString(names, e.name) {
+			out.entries = append(out.entries, e)
+		}
+	}
+	return out
+}
+
+// Replace returns a copy of c where the middleware of the specified name
+// is replaced by mw, at the same position. If c has no such middleware,
+// mw is appended.
+func (c Chain) Replace(name string, mw Middleware) Chain {
+	out := c.with()
+	for i, e := range out.entries {
+		if e.name == name {
+			out.entries[i].mw = mw
+			return out
+		}
+	}
+	return out.UseNamed(name, mw)
+}
+
+// Then returns h wrapped with the middlewares of c.
+func (c Chain) Then(h http.Handler) http.Handler {
+	for i := len(c.entries) - 1; i >= 0; i-- {
+		h = c.entries[i].mw(h)
+	}
+	return h
+}
+
+// ThenFunc returns fn wrapped with the middlewares of c.
+func (c Chain) ThenFunc(fn http.HandlerFunc) http.Handler {
+	return c.Then(fn)
+}
+
+// RequestMatcher reports whether a request satisfies some condition.
+type RequestMatcher func(req *http.Request) bool
+
+// When returns a middleware that applies mw to the requests matching m,
+// and passes other requests to next directly.
+func When(m RequestMatcher, mw Middleware) Middleware {
+	return func(next http.Handler) http.Handler {
+		wrapped := mw(next)
+		return http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+			if m(req) {
+				wrapped.ServeHTTP(w, req)
+			} else {
+				next.ServeHTTP(w, req)
+			}
+		})
+	}
+}
+
+// Not returns a matcher matching the requests that m does not match.
+func Not(m RequestMatcher) RequestMatcher {
+	return func(req *http.Request) bool { return !m(req) }
+}
+
+// MatchMethods returns a matcher matching the requests with any of the
+// specified methods.
+func MatchMethods(methods ...string) RequestMatcher {
+	return func(req *http.Request) bool {
+		return containsString(methods, req.Method)
+	}
+}
+
+// MatchPathPrefix returns a matcher matching the requests whose path starts
+// with any of the specified prefixes.
+func MatchPathPrefix(prefixes ...string) RequestMatcher {
+	return func(req *http.Request) bool {
+		for _, prefix := range prefixes {
+			if strings.HasPrefix(req.URL.Path, prefix) {
+				return true
+			}
+		}
+		return false
+	}
+}
+
+// MatchContentType returns a matcher matching the requests whose content
+// has a media type matching any of the specified patterns, like "text/*"
+// or "application/json".
+func MatchContentType(patterns ...string) RequestMatcher {
+	return func(req *http.Request) bool {
+		mediaType, _, err := mime.ParseMediaType(req.Header.Get("Content-Type"))
+		return err == nil && matchesMediaType(patterns, mediaType)
+	}
+}
+
+// MatchAccept returns a matcher matching the requests that accept any of
+// the specified media types, as per their Accept header field.
+func MatchAccept(mediaTypes ...string) RequestMatcher {
+	return func(req *http.Request) bool {
+		offer, _ := NegotiateContent(req.Header, "Accept", mediaTypes...)
+		return offer != ""
+	}
+}
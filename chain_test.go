@@ -0,0 +1,109 @@
+// Copyright 2026 Franklin "Snaipe" Mathieu.
+//
+// Use of this source code is governed by the MIT license that can be
+// found in the LICENSE file.
+
+package htutil
+
+import (
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+// traceMiddleware returns a middleware recording its name in the X-Trace
+// response header field, before and after calling the next handler.
+func traceMiddleware(name string) Middleware {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+			w.Header().Add("X-Trace", name)
+			next.ServeHTTP(w, req)
+			w.Header().Add("X-Trace", "/"+name)
+		})
+	}
+}
+
+func TestChain(t *testing.T) {
+	t.Parallel()
+
+	base := NewChain(traceMiddleware("a")).UseNamed("auth", traceMiddleware("auth")).Use(traceMiddleware("b"))
+
+	tcases := []struct {
+		Chain    Chain
+		Expected string
+	}{
+		{Chain: Chain{}, Expected: "handler"},
+		{Chain: base, Expected: "a auth b handler /b /auth /a"},
+		{Chain: base.Without("auth"), Expected: "a b handler /b /a"},
+		{Chain: base.Replace("auth", traceMiddleware("token")), Expected: "a token b handler /b /token /a"},
+		{Chain: base.Replace("cors", traceMiddleware("cors")), Expected: "a auth b cors handler /cors /b /auth /a"},
+		{Chain: base.Use(traceMiddleware("c")), Expected: "a auth b c handler /c /b /auth /a"},
+		{Chain: base.Without("nope"), Expected: "a auth b handler /b /auth /a"},
+	}
+
+	for i, tcase := range tcases {
+		t.Run(fmt.Sprintf("%d", i), func(t *testing.T) {
+			handler := tcase.Chain.ThenFunc(func(w http.ResponseWriter, req *http.Request) {
+				w.Header().Add("X-Trace", "handler")
+			})
+			rw := httptest.NewRecorder()
+			handler.ServeHTTP(rw, httptest.NewRequest("GET", "/", nil))
+			if trace := strings.Join(rw.Header().Values("X-Trace"), " "); trace != tcase.Expected {
+				t.Fatalf("expected %q, got %q", tcase.Expected, trace)
+			}
+		})
+	}
+}
+
+func TestWhen(t *testing.T) {
+	t.Parallel()
+
+	tcases := []struct {
+		Matcher     RequestMatcher
+		Method      string
+		Path        string
+		ContentType string
+		Accept      string
+		Applied     bool
+	}{
+		{Matcher: MatchMethods("POST", "PUT"), Method: "POST", Applied: true},
+		{Matcher: MatchMethods("POST", "PUT"), Method: "GET", Applied: false},
+		{Matcher: Not(MatchMethods("POST")), Method: "GET", Applied: true},
+		{Matcher: MatchPathPrefix("/api/", "/v2/"), Path: "/v2/widgets", Applied: true},
+		{Matcher: MatchPathPrefix("/api/"), Path: "/static/app.js", Applied: false},
+		{Matcher: MatchContentType("application/json"), ContentType: "application/json; charset=utf-8", Applied: true},
+		{Matcher: MatchContentType("text/*"), ContentType: "application/json", Applied: false},
+		{Matcher: MatchContentType("text/*"), Applied: false},
+		{Matcher: MatchAccept("text/html"), Accept: "text/html, */*;q=0.1", Applied: true},
+		{Matcher: MatchAccept("text/html"), Accept: "application/json", Applied: false},
+	}
+
+	for i, tcase := range tcases {
+		t.Run(fmt.Sprintf("%d", i), func(t *testing.T) {
+			handler := NewChain(When(tcase.Matcher, traceMiddleware("mw"))).ThenFunc(func(w http.ResponseWriter, req *http.Request) {})
+
+			method, path := tcase.Method, tcase.Path
+			if method == "" {
+				method = "GET"
+			}
+			if path == "" {
+				path = "/"
+			}
+			req := httptest.NewRequest(method, path, nil)
+			if tcase.ContentType != "" {
+				req.Header.Set("Content-Type", tcase.ContentType)
+			}
+			if tcase.Accept != "" {
+				req.Header.Set("Accept", tcase.Accept)
+			}
+			rw := httptest.NewRecorder()
+			handler.ServeHTTP(rw, req)
+
+			if applied := rw.Header().Get("X-Trace") != ""; applied != tcase.Applied {
+				t.Fatalf("expected applied=%v, got %v", tcase.Applied, applied)
+			}
+		})
+	}
+}
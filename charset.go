@@ -0,0 +1,242 @@
+// Copyright 2026 Franklin "Snaipe" Mathieu.
+//
+// Use of this source code is governed by the MIT license that can be
+// found in the LICENSE file.
+
+package htutil
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"mime"
+	"net/http"
+	"sort"
+	"strings"
+	"sync"
+	"unicode/utf8"
+)
+
+// CharsetEncoder returns a writer encoding the UTF-8 text written to it in
+// a character set, to w. If the writer implements io.Closer, it is closed
+// once all the text is written, to flush any buffered output.
+//
+// The encoders of golang.org/x/text/encoding can be registered with:
+//
+//	htutil.RegisterCharset("shift_jis", func(w io.Writer) io.Writer {
+//		return encoding.ReplaceUnsupported(japanese.ShiftJIS.NewEncoder()).Writer(w)
+//	})
+type CharsetEncoder func(w io.Writer) io.Writer
+
+// ErrUnknownCharset is returned when using a character set that was not
+// registered.
+var ErrUnknownCharset = errors.New("unknown charset")
+
+var charsets = struct {
+	sync.RWMutex
+	m map[string]CharsetEncoder
+}{
+	m: map[string]CharsetEncoder{
+		"utf-8": func(w io.Writer) io.Writer {
+			return writerOnly{w}
+		},
+		"iso-8859-1": func(w io.Writer) io.Writer {
+			return &runeEncoder{w: w, max: 0xff}
+		},
+		"us-ascii": func(w io.Writer) io.Writer {
+			return &runeEncoder{w: w, max: 0x7f}
+		},
+	},
+}
+
+// RegisterCharset registers the encoder of the character set name,
+// replacing any previously registered encoder. The UTF-8, ISO-8859-1, and
+// US-ASCII character sets are registered by default.
+func RegisterCharset(name string, enc CharsetEncoder) {
+	charsets.Lock()
+	defer charsets.Unlock()
+	charsets.m[strings.ToLower(name)] = enc
+}
+
+// Charsets returns the names of the registered character sets, sorted.
+func Charsets() []string {
+	charsets.RLock()
+	defer charsets.RUnlock()
+	names := make([]string, 0, len(charsets.m))
+	for name := range charsets.m {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// NewCharsetEncoder returns a writer encoding the UTF-8 text written to it
+// in the character set name, to w. Closing the writer flushes the encoder,
+// but does not close w.
+func NewCharsetEncoder(w io.Writer, name string) (io.WriteCloser, error) {
+	charsets.RLock()
+	enc, ok := charsets.m[strings.ToLower(name)]
+	charsets.RUnlock()
+	if !ok {
+		return nil, fmt.Errorf("%w %q", ErrUnknownCharset, name)
+	}
+	ew := enc(w)
+	if c, ok := ew.(io.WriteCloser); ok {
+		return c, nil
+	}
+	return nopWriteCloser{ew}, nil
+}
+
+// runeEncoder encodes UTF-8 text in a single-byte character set whose code
+// points are the first Unicode code points, up to max. Characters that
+// cannot be represented are replaced by '?'.
+type runeEncoder struct {
+	w       io.Writer
+	max     rune
+	pending []byte
+	buf     []byte
+}
+
+func (e *runeEncoder) Write(p []byte) (int, error) {
+	e.pending = append(e.pending, p...)
+	e.buf = e.buf[:0]
+	text := e.pending
+	for len(text) > 0 && utf8.FullRune(text) {
+		r, size := utf8.DecodeRune(text)
+		if r > e.max || r == utf8.RuneError {
+			e.buf = append(e.buf, '?')
+		} else {
+			e.buf = append(e.buf, byte(r))
+		}
+		text = text[size:]
+	}
+	e.pending = append(e.pending[:0], text...)
+	if _, err := e.w.Write(e.buf); err != nil {
+		return 0, err
+	}
+	return len(p), nil
+}
+
+// Close encodes the trailing incomplete character, if any.
+func (e *runeEncoder) Close() error {
+	if len(e.pending) == 0 {
+		return nil
+	}
+	e.pending = e.pending[:0]
+	_, err := e.w.Write([]byte{'?'})
+	return err
+}
+
+// NegotiateCharset returns the character set among offers best matching
+// the Accept-Charset header field of hdr, as well as the entry that it
+// matched against, as per NegotiateOffers. Character set names are
+// case-insensitive.
+//
+// As per RFC 9110 §12.5.2, all character sets are acceptable if hdr has no
+// Accept-Charset, in which case the first offer is returned. If no offer
+// is acceptable, ("", nil) is returned.
+func NegotiateCharset(hdr http.Header, offers ...string) (string, *Acceptable) {
+	lowered := make([]Offer, len(offers))
+	for i, offer := range offers {
+		lowered[i] = Offer{Value: strings.ToLower(offer)}
+	}
+	selected, match := NegotiateOffers(hdr, "Accept-Charset", lowered...)
+	for i := range lowered {
+		if match != nil && lowered[i].Value == selected.Value {
+			return offers[i], match
+		}
+	}
+	return "", nil
+}
+
+// TranscodeCharset returns a middleware that transcodes the UTF-8 text
+// responses of next to the character set negotiated among offers, which
+// must be registered character sets (see RegisterCharset), and sets the
+// charset parameter of their Content-Type accordingly. If no offer is
+// acceptable, the first one is used, as RFC 9110 §12.5.2 allows
+// disregarding the Accept-Charset header field.
+//
+// Text responses are the responses of type text/*, and the responses whose
+// Content-Type has a charset=utf-8 parameter. Responses with a
+// Content-Encoding, or partial content, are left untouched. The ETag of
+// transcoded responses is weakened, like by Compression.
+func TranscodeCharset(next http.Handler, offers ...string) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+		AddVary(w.Header(), "Accept-Charset")
+		charset, _ := NegotiateCharset(req.Header, offers...)
+		if charset == "" && len(offers) > 0 {
+			charset = offers[0]
+		}
+		cw := &charsetWriter{w: w, charset: charset}
+		next.ServeHTTP(Wrap(w, WriterHooks{
+			WriteHeader: func(WriteHeaderFunc) WriteHeaderFunc { return cw.writeHeader },
+			Write:       func(WriteFunc) WriteFunc { return cw.write },
+		}), req)
+		cw.finish()
+	})
+}
+
+// charsetWriter transcodes the body of a response.
+type charsetWriter struct {
+	w           http.ResponseWriter
+	charset     string
+	wroteHeader bool
+	enc         io.WriteCloser
+}
+
+func (cw *charsetWriter) writeHeader(status int) {
+	if !cw.wroteHeader && (status < 100 || status >= 200 || status == http.StatusSwitchingProtocols) {
+		cw.wroteHeader = true
+		cw.prepare()
+	}
+	cw.w.WriteHeader(status)
+}
+
+// prepare sets up the encoder of the response, if it is a text response.
+func (cw *charsetWriter) prepare() {
+	h := cw.w.Header()
+	if cw.charset == "" || h.Get("Content-Encoding") != "" || h.Get("Content-Range") != "" {
+		return
+	}
+	mediaType, params, err := mime.ParseMediaType(h.Get("Content-Type"))
+	if err != nil {
+		return
+	}
+	if charset, ok := params["charset"]; ok {
+		if !strings.EqualFold(charset, "utf-8") {
+			return
+		}
+	} else if !strings.HasPrefix(mediaType, "text/") {
+		return
+	}
+	enc, err := NewCharsetEncoder(cw.w, cw.charset)
+	if err != nil {
+		return
+	}
+	params["charset"] = cw.charset
+	h.Set("Content-Type", mime.FormatMediaType(mediaType, params))
+	if !strings.EqualFold(cw.charset, "utf-8") {
+		h.Del("Content-Length")
+		// The representation changed, but remains equivalent.
+		if etag := h.Get("ETag"); etag != "" && !strings.HasPrefix(etag, "W/") {
+			h.Set("ETag", "W/"+etag)
+		}
+		cw.enc = enc
+	}
+}
+
+func (cw *charsetWriter) write(p []byte) (int, error) {
+	if !cw.wroteHeader {
+		cw.writeHeader(http.StatusOK)
+	}
+	if cw.enc == nil {
+		return cw.w.Write(p)
+	}
+	return cw.enc.Write(p)
+}
+
+func (cw *charsetWriter) finish() {
+	if cw.enc != nil {
+		cw.enc.Close()
+	}
+}
@@ -0,0 +1,178 @@
+// Copyright 2026 Franklin "Snaipe" Mathieu.
+//
+// Use of this source code is governed by the MIT license that can be
+// found in the LICENSE file.
+
+package htutil
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+func TestNegotiateCharset(t *testing.T) {
+	t.Parallel()
+
+	tcases := []struct {
+		Accept string
+		Offers []string
+		Expect string
+	}{
+		{Accept: "", Offers: []string{"UTF-8", "ISO-8859-1"}, Expect: "UTF-8"},
+		{Accept: "iso-8859-1", Offers: []string{"UTF-8", "ISO-8859-1"}, Expect: "ISO-8859-1"},
+		{Accept: "utf-8;q=0.5, ISO-8859-1", Offers: []string{"utf-8", "iso-8859-1"}, Expect: "iso-8859-1"},
+		{Accept: "*, utf-8;q=0", Offers: []string{"utf-8", "us-ascii"}, Expect: "us-ascii"},
+		{Accept: "shift_jis", Offers: []string{"utf-8"}, Expect: ""},
+	}
+
+	for i, tcase := range tcases {
+		t.Run(fmt.Sprintf("%d", i), func(t *testing.T) {
+			hdr := http.Header{}
+			if tcase.Accept != "" {
+				hdr.Set("Accept-Charset", tcase.Accept)
+			}
+			if out, _ := NegotiateCharset(hdr, tcase.Offers...); out != tcase.Expect {
+				t.Fatalf("expected %q, got %q", tcase.Expect, out)
+			}
+		})
+	}
+}
+
+func TestNewCharsetEncoder(t *testing.T) {
+	t.Parallel()
+
+	tcases := []struct {
+		Charset string
+		Writes  []string
+		Expect  string
+		Err     error
+	}{
+		{Charset: "utf-8", Writes: []string{"héllo €"}, Expect: "héllo €"},
+		{Charset: "ISO-8859-1", Writes: []string{"héllo €"}, Expect: "h\xe9llo ?"},
+		{Charset: "iso-8859-1", Writes: []string{"h\xc3", "\xa9", "llo"}, Expect: "h\xe9llo"},
+		{Charset: "iso-8859-1", Writes: []string{"truncated \xc3"}, Expect: "truncated ?"},
+		{Charset: "iso-8859-1", Writes: []string{"invalid \xff!"}, Expect: "invalid ?!"},
+		{Charset: "us-ascii", Writes: []string{"héllo"}, Expect: "h?llo"},
+		{Charset: "koi8-r", Err: ErrUnknownCharset},
+	}
+
+	for i, tcase := range tcases {
+		t.Run(fmt.Sprintf("%d", i), func(t *testing.T) {
+			var out bytes.Buffer
+			enc, err := NewCharsetEncoder(&out, tcase.Charset)
+			if !errors.Is(err, tcase.Err) {
+				t.Fatalf("expected error %v, got %v", tcase.Err, err)
+			}
+			if err != nil {
+				return
+			}
+			for _, s := range tcase.Writes {
+				if n, err := io.WriteString(enc, s); err != nil || n != len(s) {
+					t.Fatalf("writing %q: wrote %d bytes (%v)", s, n, err)
+				}
+			}
+			if err := enc.Close(); err != nil {
+				t.Fatal(err)
+			}
+			if out.String() != tcase.Expect {
+				t.Fatalf("expected %q, got %q", tcase.Expect, out.String())
+			}
+		})
+	}
+}
+
+func TestRegisterCharset(t *testing.T) {
+	t.Parallel()
+
+	RegisterCharset("X-Test-Upper", func(w io.Writer) io.Writer {
+		return writerFunc(func(p []byte) (int, error) {
+			return w.Write(bytes.ToUpper(p))
+		})
+	})
+	var out bytes.Buffer
+	enc, err := NewCharsetEncoder(&out, "x-test-upper")
+	if err != nil {
+		t.Fatal(err)
+	}
+	io.WriteString(enc, "hello")
+	enc.Close()
+	if out.String() != "HELLO" {
+		t.Fatalf("expected %q, got %q", "HELLO", out.String())
+	}
+	if names := Charsets(); !containsString(names, "x-test-upper") || !containsString(names, "iso-8859-1") {
+		t.Fatalf("expected registered charsets, got %v", names)
+	}
+}
+
+type writerFunc func(p []byte) (int, error)
+
+func (fn writerFunc) Write(p []byte) (int, error) { return fn(p) }
+
+func TestTranscodeCharset(t *testing.T) {
+	t.Parallel()
+
+	tcases := []struct {
+		Accept      string
+		ContentType string
+		Header      http.Header
+		Expect      string
+		ExpectType  string
+	}{
+		{Accept: "", ContentType: "text/plain", Expect: "café", ExpectType: "text/plain; charset=utf-8"},
+		{Accept: "iso-8859-1", ContentType: "text/plain; charset=utf-8", Expect: "caf\xe9", ExpectType: "text/plain; charset=iso-8859-1"},
+		{Accept: "iso-8859-1", ContentType: "text/html", Expect: "caf\xe9", ExpectType: "text/html; charset=iso-8859-1"},
+		{Accept: "iso-8859-1", ContentType: "application/xml; charset=UTF-8", Expect: "caf\xe9", ExpectType: "application/xml; charset=iso-8859-1"},
+		{Accept: "koi8-r", ContentType: "text/plain", Expect: "café", ExpectType: "text/plain; charset=utf-8"},
+		{Accept: "iso-8859-1", ContentType: "application/json", Expect: "café", ExpectType: "application/json"},
+		{Accept: "iso-8859-1", ContentType: "text/plain; charset=windows-1252", Expect: "café", ExpectType: "text/plain; charset=windows-1252"},
+		{Accept: "iso-8859-1", ContentType: "text/plain", Header: http.Header{"Content-Encoding": {"gzip"}}, Expect: "café", ExpectType: "text/plain"},
+	}
+
+	for i, tcase := range tcases {
+		t.Run(fmt.Sprintf("%d", i), func(t *testing.T) {
+			handler := TranscodeCharset(http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+				for k, v := range tcase.Header {
+					w.Header()[k] = v
+				}
+				w.Header().Set("Content-Type", tcase.ContentType)
+				w.Header().Set("Content-Length", "5")
+				w.Header().Set("ETag", `"abc"`)
+				io.Copy(w, strings.NewReader("caf"))
+				io.WriteString(w, "é")
+			}), "utf-8", "iso-8859-1")
+
+			req := httptest.NewRequest("GET", "/", nil)
+			if tcase.Accept != "" {
+				req.Header.Set("Accept-Charset", tcase.Accept)
+			}
+			rw := httptest.NewRecorder()
+			handler.ServeHTTP(rw, req)
+
+			if body := rw.Body.String(); body != tcase.Expect {
+				t.Fatalf("expected body %q, got %q", tcase.Expect, body)
+			}
+			if ctype := rw.Header().Get("Content-Type"); ctype != tcase.ExpectType {
+				t.Fatalf("expected Content-Type %q, got %q", tcase.ExpectType, ctype)
+			}
+			if cl := rw.Header().Get("Content-Length"); cl != "" && cl != fmt.Sprint(len(tcase.Expect)) {
+				t.Fatalf("expected Content-Length to match body, got %q", cl)
+			}
+			etag := `"abc"`
+			if tcase.Expect != "café" {
+				etag = "W/" + etag
+			}
+			if got := rw.Header().Get("ETag"); got != etag {
+				t.Fatalf("expected ETag %q, got %q", etag, got)
+			}
+			if vary := rw.Header().Get("Vary"); vary != "Accept-Charset" {
+				t.Fatalf("expected Vary: Accept-Charset, got %q", vary)
+			}
+		})
+	}
+}
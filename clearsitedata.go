@@ -0,0 +1,101 @@
+// Copyright 2026 Franklin "Snaipe" Mathieu.
+//
+// Use of this source code is governed by the MIT license that can be
+// found in the LICENSE file.
+
+package htutil
+
+import (
+	"fmt"
+	"net/http"
+	"strings"
+)
+
+// ClearSiteData is a set of Clear-Site-Data directives, as per the W3C
+// Clear Site Data specification. Directives may be or-ed together.
+type ClearSiteData int
+
+const (
+	ClearCache ClearSiteData = 1 << iota
+	ClearCookies
+	ClearStorage
+	ClearExecutionContexts
+	ClearClientHints
+
+	// ClearAll is the "*" wildcard, clearing all the data types known
+	// to the user agent, including ones added in future versions of the
+	// specification.
+	ClearAll
+)
+
+var clearSiteDataDirectives = []struct {
+	flag ClearSiteData
+	name string
+}{
+	{ClearCache, "cache"},
+	{ClearCookies, "cookies"},
+	{ClearStorage, "storage"},
+	{ClearExecutionContexts, "executionContexts"},
+	{ClearClientHints, "clientHints"},
+	{ClearAll, "*"},
+}
+
+// String formats c as a Clear-Site-Data header value, as a list of quoted
+// directives.
+func (c ClearSiteData) String() string {
+	var out []string
+	for _, d := range clearSiteDataDirectives {
+		if c&d.flag != 0 {
+			out = append(out, QuoteString(d.name))
+		}
+	}
+	return strings.Join(out, ", ")
+}
+
+// ParseClearSiteData parses a Clear-Site-Data header value. Unknown
+// directives are ignored, as mandated by the specification.
+func ParseClearSiteData(value string) (ClearSiteData, error) {
+	var c ClearSiteData
+	for _, member := range SplitList(value) {
+		name, err := UnquoteString(member)
+		if err != nil {
+			return 0, fmt.Errorf("parsing clear-site-data: %w", err)
+		}
+		for _, d := range clearSiteDataDirectives {
+			if d.name == name {
+				c |= d.flag
+			}
+		}
+	}
+	return c, nil
+}
+
+// SetClearSiteData sets the Clear-Site-Data header field of h to c.
+func SetClearSiteData(h http.Header, c ClearSiteData) {
+	if c == 0 {
+		h.Del("Clear-Site-Data")
+		return
+	}
+	h.Set("Clear-Site-Data", c.String())
+}
+
+// Logout instructs the user agent to clear the cache, cookies, and storage
+// of the origin via Clear-Site-Data, and expires the specified cookies.
+//
+// Since user agents only honor Clear-Site-Data on secure connections, and
+// since some do not implement it at all, the cookies that matter should
+// always be passed explicitly. Only their Name, Path, and Domain are used.
+func Logout(w http.ResponseWriter, cookies ...*http.Cookie) {
+	SetClearSiteData(w.Header(), ClearCache|ClearCookies|ClearStorage)
+	for _, cookie := range cookies {
+		http.SetCookie(w, &http.Cookie{
+			Name:     cookie.Name,
+			Path:     cookie.Path,
+			Domain:   cookie.Domain,
+			Secure:   cookie.Secure,
+			HttpOnly: cookie.HttpOnly,
+			SameSite: cookie.SameSite,
+			MaxAge:   -1,
+		})
+	}
+}
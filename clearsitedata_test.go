@@ -0,0 +1,68 @@
+// Copyright 2026 Franklin "Snaipe" Mathieu.
+//
+// Use of this source code is governed by the MIT license that can be
+// found in the LICENSE file.
+
+package htutil
+
+import (
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestClearSiteData(t *testing.T) {
+	t.Parallel()
+
+	tcases := []struct {
+		Value ClearSiteData
+		Out   string
+	}{
+		{Value: ClearCache, Out: `"cache"`},
+		{Value: ClearCookies | ClearStorage, Out: `"cookies", "storage"`},
+		{Value: ClearAll, Out: `"*"`},
+		{Value: ClearExecutionContexts | ClearCache, Out: `"cache", "executionContexts"`},
+	}
+
+	for i, tcase := range tcases {
+		t.Run(fmt.Sprintf("%d", i), func(t *testing.T) {
+			if actual := tcase.Value.String(); actual != tcase.Out {
+				t.Fatalf("expected %s, got %s", tcase.Out, actual)
+			}
+			parsed, err := ParseClearSiteData(tcase.Out)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if parsed != tcase.Value {
+				t.Fatalf("expected %v, got %v", tcase.Value, parsed)
+			}
+		})
+	}
+
+	if _, err := ParseClearSiteData(`cache`); err == nil {
+		t.Fatalf("expected unquoted directive to be rejected")
+	}
+	if c, err := ParseClearSiteData(`"cache", "unknown"`); err != nil || c != ClearCache {
+		t.Fatalf("expected unknown directive to be ignored, got %v (%v)", c, err)
+	}
+}
+
+func TestLogout(t *testing.T) {
+	t.Parallel()
+
+	w := httptest.NewRecorder()
+	Logout(w, &http.Cookie{Name: "session", Path: "/", Value: "secret"})
+
+	resp := w.Result()
+	if csd := resp.Header.Get("Clear-Site-Data"); csd != `"cache", "cookies", "storage"` {
+		t.Fatalf("unexpected Clear-Site-Data %s", csd)
+	}
+	cookies := resp.Cookies()
+	if len(cookies) != 1 {
+		t.Fatalf("expected 1 cookie, got %v", cookies)
+	}
+	if c := cookies[0]; c.Name != "session" || c.Value != "" || c.MaxAge >= 0 {
+		t.Fatalf("expected expired session cookie, got %v", c)
+	}
+}
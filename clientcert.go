@@ -0,0 +1,194 @@
+// Copyright 2026 Franklin "Snaipe" Mathieu.
+//
+// Use of this source code is governed by the MIT license that can be
+// found in the LICENSE file.
+
+package htutil
+
+import (
+	"context"
+	"crypto/tls"
+	"crypto/x509"
+	"errors"
+	"fmt"
+	"log/slog"
+	"net/http"
+	"net/netip"
+
+	"snai.pe/go-htutil/sfv"
+)
+
+// This file implements the Client-Cert and Client-Cert-Chain header fields
+// of RFC 9440, which TLS-terminating proxies use to forward the client
+// certificate to backends.
+
+// SetClientCert replaces the Client-Cert and Client-Cert-Chain fields of h
+// with the client certificate chain of the TLS connection state, if any.
+//
+// Proxies must call it on every forwarded request, so that clients cannot
+// inject their own certificates.
+func SetClientCert(h http.Header, state *tls.ConnectionState) {
+	h.Del("Client-Cert")
+	h.Del("Client-Cert-Chain")
+	if state == nil || len(state.PeerCertificates) == 0 {
+		return
+	}
+
+	leaf, _ := sfv.FormatItem(sfv.Item{Value: state.PeerCertificates[0].Raw})
+	h.Set("Client-Cert", leaf)
+
+	if len(state.PeerCertificates) > 1 {
+		chain := make(sfv.List, 0, len(state.PeerCertificates)-1)
+		for _, cert := range state.PeerCertificates[1:] {
+			chain = append(chain, sfv.Item{Value: cert.Raw})
+		}
+		s, _ := sfv.FormatList(chain)
+		h.Set("Client-Cert-Chain", s)
+	}
+}
+
+// ParseClientCert parses the Client-Cert and Client-Cert-Chain fields of
+// h, and returns the client certificate along with the intermediate
+// certificates sent by the client. It returns a nil certificate if h has
+// no Client-Cert field.
+func ParseClientCert(h http.Header) (*x509.Certificate, []*x509.Certificate, error) {
+	values := h.Values("Client-Cert")
+	if len(values) == 0 {
+		return nil, nil, nil
+	}
+	if len(values) > 1 {
+		return nil, nil, errors.New("parsing client-cert: multiple field lines")
+	}
+	item, err := sfv.ParseItem(values[0])
+	if err != nil {
+		return nil, nil, fmt.Errorf("parsing client-cert: %w", err)
+	}
+	der, ok := item.Value.([]byte)
+	if !ok {
+		return nil, nil, errors.New("parsing client-cert: not a byte sequence")
+	}
+	leaf, err := x509.ParseCertificate(der)
+	if err != nil {
+		return nil, nil, fmt.Errorf("parsing client-cert: %w", err)
+	}
+
+	var chain []*x509.Certificate
+	for _, v := range h.Values("Client-Cert-Chain") {
+		list, err := sfv.ParseList(v)
+		if err != nil {
+			return nil, nil, fmt.Errorf("parsing client-cert-chain: %w", err)
+		}
+		for _, member := range list {
+			item, ok := member.(sfv.Item)
+			if !ok {
+				return nil, nil, errors.New("parsing client-cert-chain: member is not an item")
+			}
+			der, ok := item.Value.([]byte)
+			if !ok {
+				return nil, nil, errors.New("parsing client-cert-chain: member is not a byte sequence")
+			}
+			cert, err := x509.ParseCertificate(der)
+			if err != nil {
+				return nil, nil, fmt.Errorf("parsing client-cert-chain: %w", err)
+			}
+			chain = append(chain, cert)
+		}
+	}
+	return leaf, chain, nil
+}
+
+type clientCertKey struct{}
+
+// ClientCertificate returns the verified client certificate of the request
+// that ctx belongs to, as set by ClientCertAuth.
+func ClientCertificate(ctx context.Context) (*x509.Certificate, bool) {
+	cert, ok := ctx.Value(clientCertKey{}).(*x509.Certificate)
+	return cert, ok
+}
+
+// ClientCertAuth authenticates clients with their TLS certificate, either
+// from the TLS connection itself, or from the Client-Cert fields set by a
+// trusted TLS-terminating proxy.
+type ClientCertAuth struct {
+	// Roots are the certificate authorities trusted to issue client
+	// certificates.
+	Roots *x509.CertPool
+
+	// TrustedProxies lists the addresses of the proxies whose Client-Cert
+	// fields are honored. Client-Cert fields of requests from any other
+	// address are ignored.
+	TrustedProxies []netip.Prefix
+
+	// Required makes requests without a valid client certificate get
+	// rejected with 401 Unauthorized. Otherwise, they are served without
+	// a client certificate in their context.
+	Required bool
+}
+
+func (a *ClientCertAuth) trusted(req *http.Request) bool {
+	return peerIn(req, a.TrustedProxies)
+}
+
+// certificate returns the client certificate of req, and its intermediates.
+func (a *ClientCertAuth) certificate(req *http.Request) (*x509.Certificate, []*x509.Certificate, error) {
+	if req.TLS != nil && len(req.TLS.PeerCertificates) > 0 {
+		return req.TLS.PeerCertificates[0], req.TLS.PeerCertificates[1:], nil
+	}
+	if !a.trusted(req) {
+		return nil, nil, nil
+	}
+	return ParseClientCert(req.Header)
+}
+
+// Verify returns the verified client certificate of req, or nil if there
+// is none.
+func (a *ClientCertAuth) Verify(req *http.Request) (*x509.Certificate, error) {
+	leaf, chain, err := a.certificate(req)
+	if err != nil || leaf == nil {
+		return nil, err
+	}
+	intermediates := x509.NewCertPool()
+	for _, cert := range chain {
+		intermediates.AddCert(cert)
+	}
+	_, err = leaf.Verify(x509.VerifyOptions{
+		Roots:         a.Roots,
+		Intermediates: intermediates,
+		KeyUsages:     []x509.ExtKeyUsage{x509.ExtKeyUsageClientAuth},
+	})
+	if err != nil {
+		return nil, fmt.Errorf("verifying client certificate: %w", err)
+	}
+	return leaf, nil
+}
+
+// Middleware returns a middleware that verifies the client certificate of
+// requests, and makes it available to next through ClientCertificate.
+// The Client-Cert fields of requests that do not come from a trusted proxy
+// are removed, so that next cannot mistake them for verified ones.
+func (a *ClientCertAuth) Middleware(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+		if !a.trusted(req) && (req.Header.Get("Client-Cert") != "" || req.Header.Get("Client-Cert-Chain") != "") {
+			stripped := *req
+			stripped.Header = req.Header.Clone()
+			stripped.Header.Del("Client-Cert")
+			stripped.Header.Del("Client-Cert-Chain")
+			req = &stripped
+		}
+		cert, err := a.Verify(req)
+		if err != nil {
+			ContextLogger(req.Context()).Warn("rejecting client certificate",
+				slog.String("remote_addr", req.RemoteAddr), slog.String("error", err.Error()))
+		}
+		if err != nil || cert == nil {
+			if a.Required {
+				RespondError(w, req, NewProblem(http.StatusUnauthorized, "A valid client certificate is required."))
+				return
+			}
+			next.ServeHTTP(w, req)
+			return
+		}
+		ctx := context.WithValue(req.Context(), clientCertKey{}, cert)
+		next.ServeHTTP(w, req.WithContext(ctx))
+	})
+}
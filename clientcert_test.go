@@ -0,0 +1,146 @@
+// Copyright 2026 Franklin "Snaipe" Mathieu.
+//
+// Use of this source code is governed by the MIT license that can be
+// found in the LICENSE file.
+
+package htutil
+
+import (
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/tls"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"fmt"
+	"math/big"
+	"net/http"
+	"net/http/httptest"
+	"net/netip"
+	"testing"
+	"time"
+)
+
+func newTestCert(t *testing.T, name string, parent *x509.Certificate, parentKey *ecdsa.PrivateKey, ca bool) (*x509.Certificate, *ecdsa.PrivateKey) {
+	t.Helper()
+
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+	tmpl := &x509.Certificate{
+		SerialNumber:          big.NewInt(time.Now().UnixNano()),
+		Subject:               pkix.Name{CommonName: name},
+		NotBefore:             time.Now().Add(-time.Hour),
+		NotAfter:              time.Now().Add(time.Hour),
+		IsCA:                  ca,
+		BasicConstraintsValid: true,
+		KeyUsage:              x509.KeyUsageDigitalSignature | x509.KeyUsageCertSign,
+		ExtKeyUsage:           []x509.ExtKeyUsage{x509.ExtKeyUsageClientAuth},
+	}
+	if parent == nil {
+		parent, parentKey = tmpl, key
+	}
+	der, err := x509.CreateCertificate(rand.Reader, tmpl, parent, &key.PublicKey, parentKey)
+	if err != nil {
+		t.Fatal(err)
+	}
+	cert, err := x509.ParseCertificate(der)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return cert, key
+}
+
+func TestClientCertAuth(t *testing.T) {
+	t.Parallel()
+
+	root, rootKey := newTestCert(t, "root", nil, nil, true)
+	inter, interKey := newTestCert(t, "intermediate", root, rootKey, true)
+	client, _ := newTestCert(t, "client", inter, interKey, false)
+	rogue, _ := newTestCert(t, "client", nil, nil, false)
+
+	roots := x509.NewCertPool()
+	roots.AddCert(root)
+	auth := &ClientCertAuth{
+		Roots:          roots,
+		TrustedProxies: []netip.Prefix{netip.MustParsePrefix("127.0.0.1/32")},
+		Required:       true,
+	}
+	handler := auth.Middleware(http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+		cert, _ := ClientCertificate(req.Context())
+		fmt.Fprint(w, cert.Subject.CommonName)
+	}))
+
+	tcases := []struct {
+		RemoteAddr string
+		Chain      []*x509.Certificate
+		Status     int
+	}{
+		{RemoteAddr: "127.0.0.1:1234", Chain: []*x509.Certificate{client, inter}, Status: 200},
+		{RemoteAddr: "192.0.2.1:1234", Chain: []*x509.Certificate{client, inter}, Status: 401},
+		{RemoteAddr: "127.0.0.1:1234", Chain: []*x509.Certificate{client}, Status: 401},
+		{RemoteAddr: "127.0.0.1:1234", Chain: []*x509.Certificate{rogue}, Status: 401},
+		{RemoteAddr: "127.0.0.1:1234", Status: 401},
+	}
+
+	for i, tcase := range tcases {
+		t.Run(fmt.Sprintf("%d", i), func(t *testing.T) {
+			req := httptest.NewRequest("GET", "/", nil)
+			req.RemoteAddr = tcase.RemoteAddr
+			req.Header.Set("Client-Cert", ":Zm9yZ2Vk:")
+			SetClientCert(req.Header, &tls.ConnectionState{PeerCertificates: tcase.Chain})
+
+			w := httptest.NewRecorder()
+			handler.ServeHTTP(w, req)
+			if w.Code != tcase.Status {
+				t.Fatalf("expected status %d, got %d", tcase.Status, w.Code)
+			}
+			if tcase.Status == 200 && w.Body.String() != "client" {
+				t.Fatalf("expected client certificate in context, got %q", w.Body.String())
+			}
+		})
+	}
+}
+
+func TestClientCertAuthStrip(t *testing.T) {
+	t.Parallel()
+
+	auth := &ClientCertAuth{TrustedProxies: []netip.Prefix{netip.MustParsePrefix("127.0.0.1/32")}}
+	handler := auth.Middleware(http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+		fmt.Fprint(w, req.Header.Get("Client-Cert")+req.Header.Get("Client-Cert-Chain"))
+	}))
+
+	req := httptest.NewRequest("GET", "/", nil)
+	req.RemoteAddr = "192.0.2.1:1234"
+	req.Header.Set("Client-Cert", ":Zm9yZ2Vk:")
+	req.Header.Set("Client-Cert-Chain", ":Zm9yZ2Vk:")
+	w := httptest.NewRecorder()
+	handler.ServeHTTP(w, req)
+	if w.Body.Len() != 0 {
+		t.Fatalf("expected Client-Cert fields to be stripped, got %q", w.Body.String())
+	}
+}
+
+func TestParseClientCert(t *testing.T) {
+	t.Parallel()
+
+	root, rootKey := newTestCert(t, "root", nil, nil, true)
+	client, _ := newTestCert(t, "client", root, rootKey, false)
+
+	h := http.Header{}
+	SetClientCert(h, &tls.ConnectionState{PeerCertificates: []*x509.Certificate{client, root}})
+	leaf, chain, err := ParseClientCert(h)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !leaf.Equal(client) || len(chain) != 1 || !chain[0].Equal(root) {
+		t.Fatalf("expected client certificate and root chain, got %v and %v", leaf.Subject, chain)
+	}
+
+	for _, v := range []string{"not-binary", ":Zm9v:"} {
+		if _, _, err := ParseClientCert(http.Header{"Client-Cert": {v}}); err == nil {
+			t.Fatalf("expected error for Client-Cert %q", v)
+		}
+	}
+}
@@ -0,0 +1,223 @@
+// Copyright 2026 Franklin "Snaipe" Mathieu.
+//
+// Use of this source code is governed by the MIT license that can be
+// found in the LICENSE file.
+
+package htutil
+
+import (
+	"net/http"
+	"strings"
+
+	"snai.pe/go-htutil/sfv"
+)
+
+// UABrand is a brand of a user agent, as listed in the Sec-CH-UA header
+// field of the User-Agent Client Hints specification.
+type UABrand struct {
+	// Brand is the name of the brand, like "Chromium".
+	Brand string
+
+	// Version is the significant version of the brand, like "124".
+	Version string
+}
+
+// ClientHints are the client hints of a request, as per RFC 8942 and the
+// specifications defining each hint. Absent hints are zero.
+type ClientHints struct {
+	// Brands are the brands of the user agent, from Sec-CH-UA.
+	Brands []UABrand
+
+	// Mobile reports whether the user agent prefers a mobile experience,
+	// from Sec-CH-UA-Mobile.
+	Mobile bool
+
+	// Platform is the platform of the user agent, like "Linux", from
+	// Sec-CH-UA-Platform.
+	Platform string
+
+	// DPR is the device pixel ratio, the number of physical pixels per CSS
+	// pixel, from Sec-CH-DPR, or the legacy DPR.
+	DPR float64
+
+	// Width is the width of the requested image, in physical pixels, from
+	// Sec-CH-Width, or the legacy Width.
+	Width int64
+
+	// ViewportWidth is the width of the layout viewport, in CSS pixels,
+	// from Sec-CH-Viewport-Width, or the legacy Viewport-Width.
+	ViewportWidth int64
+
+	// SaveData reports whether the user requested reduced data usage, from
+	// Save-Data.
+	SaveData bool
+}
+
+// ParseClientHints returns the client hints of the header fields h of a
+// request. As for other structured fields, hints that cannot be parsed
+// are ignored.
+func ParseClientHints(h http.Header) ClientHints {
+	var ch ClientHints
+
+	if list, err := sfv.ParseList(strings.Join(h.Values("Sec-CH-UA"), ", ")); err == nil {
+		for _, m := range list {
+			item, ok := m.(sfv.Item)
+			if !ok {
+				continue
+			}
+			brand, ok := item.Value.(string)
+			if !ok {
+				continue
+			}
+			version, _ := item.Params.Get("v")
+			v, _ := version.(string)
+			ch.Brands = append(ch.Brands, UABrand{Brand: brand, Version: v})
+		}
+	}
+	if item, err := hintItem(h, "Sec-CH-UA-Mobile"); err == nil {
+		ch.Mobile, _ = item.Value.(bool)
+	}
+	if item, err := hintItem(h, "Sec-CH-UA-Platform"); err == nil {
+		ch.Platform, _ = item.Value.(string)
+	}
+	if item, err := hintItem(h, "Sec-CH-DPR", "DPR"); err == nil {
+		switch v := item.Value.(type) {
+		case float64:
+			ch.DPR = v
+		case int64:
+			ch.DPR = float64(v)
+		}
+		if ch.DPR <= 0 {
+			ch.DPR = 0
+		}
+	}
+	ch.Width = hintWidth(h, "Sec-CH-Width", "Width")
+	ch.ViewportWidth = hintWidth(h, "Sec-CH-Viewport-Width", "Viewport-Width")
+
+	if value := h.Get("Save-Data"); value != "" {
+		token, _, _ := strings.Cut(value, ";")
+		ch.SaveData = strings.EqualFold(trimOWS(token), "on")
+	}
+	return ch
+}
+
+// hintItem parses the first of the header fields names present in h as an
+// item.
+func hintItem(h http.Header, names ...string) (sfv.Item, error) {
+	for _, name := range names {
+		if values := h.Values(name); len(values) > 0 {
+			return sfv.ParseItem(strings.Join(values, ", "))
+		}
+	}
+	return sfv.Item{}, ErrNoHeader
+}
+
+// hintWidth returns the non-negative integer width of the first of the
+// header fields names present in h, or 0.
+func hintWidth(h http.Header, names ...string) int64 {
+	item, err := hintItem(h, names...)
+	if err != nil {
+		return 0
+	}
+	if w, ok := item.Value.(int64); ok && w > 0 {
+		return w
+	}
+	return 0
+}
+
+// AdvertiseClientHints sets the Accept-CH header field of h, requesting
+// user agents to send the client hints hints in subsequent requests, like
+// "Sec-CH-DPR" or "Sec-CH-Width", and the Critical-CH header field, with
+// the hints critical to the response, which user agents retry the request
+// with if they did not send them. Critical hints are also requested.
+//
+// The requested hints are added to the Vary header field, as responses
+// depend on them.
+func AdvertiseClientHints(h http.Header, hints []string, critical ...string) {
+	all := append([]string(nil), hints...)
+	for _, name := range critical {
+		if !containsFold(all, name) {
+			all = append(all, name)
+		}
+	}
+	if len(all) == 0 {
+		return
+	}
+	h.Set("Accept-CH", formatTokenList(all))
+	if len(critical) > 0 {
+		h.Set("Critical-CH", formatTokenList(critical))
+	}
+	AddVary(h, all...)
+}
+
+func formatTokenList(tokens []string) string {
+	list := make(sfv.List, len(tokens))
+	for i, t := range tokens {
+		list[i] = sfv.Item{Value: sfv.Token(t)}
+	}
+	s, _ := sfv.FormatList(list)
+	return s
+}
+
+func containsFold(list []string, s string) bool {
+	for _, v := range list {
+		if strings.EqualFold(v, s) {
+			return true
+		}
+	}
+	return false
+}
+
+// SelectImageWidth returns the width, in physical pixels, of the variant
+// of an image to serve among widths, as per the client hints ch: the
+// narrowest variant at least as wide as the image is displayed, or the
+// widest variant if none is.
+//
+// The displayed width is Width, or else the viewport width scaled by DPR.
+// If the user requested to save data, the device pixel ratio is ignored,
+// so that the image is not served in high density. If ch does not hint at
+// the displayed width, fallback is returned, or the narrowest variant if
+// the user requested to save data.
+func (ch ClientHints) SelectImageWidth(widths []int, fallback int) int {
+	if len(widths) == 0 {
+		return fallback
+	}
+	dpr := ch.DPR
+	if dpr == 0 {
+		dpr = 1
+	}
+	var target float64
+	switch {
+	case ch.Width > 0:
+		target = float64(ch.Width)
+	case ch.ViewportWidth > 0:
+		target = float64(ch.ViewportWidth) * dpr
+	}
+	if ch.SaveData {
+		target /= dpr
+	}
+
+	narrowest, widest := widths[0], widths[0]
+	for _, w := range widths[1:] {
+		if w < narrowest {
+			narrowest = w
+		}
+		if w > widest {
+			widest = w
+		}
+	}
+	if target == 0 {
+		if ch.SaveData {
+			return narrowest
+		}
+		return fallback
+	}
+
+	best := widest
+	for _, w := range widths {
+		if float64(w) >= target && w < best {
+			best = w
+		}
+	}
+	return best
+}
@@ -0,0 +1,152 @@
+// Copyright 2026 Franklin "Snaipe" Mathieu.
+//
+// Use of this source code is governed by the MIT license that can be
+// found in the LICENSE file.
+
+package htutil
+
+import (
+	"fmt"
+	"net/http"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestParseClientHints(t *testing.T) {
+	t.Parallel()
+
+	tcases := []struct {
+		Header http.Header
+		Expect ClientHints
+	}{
+		{
+			Header: http.Header{
+				"Sec-Ch-Ua":             {`"Chromium";v="124", "Google Chrome";v="124", "Not-A.Brand";v="99"`},
+				"Sec-Ch-Ua-Mobile":      {"?1"},
+				"Sec-Ch-Ua-Platform":    {`"Android"`},
+				"Sec-Ch-Dpr":            {"2.5"},
+				"Sec-Ch-Width":          {"800"},
+				"Sec-Ch-Viewport-Width": {"412"},
+				"Save-Data":             {"on"},
+			},
+			Expect: ClientHints{
+				Brands: []UABrand{
+					{Brand: "Chromium", Version: "124"},
+					{Brand: "Google Chrome", Version: "124"},
+					{Brand: "Not-A.Brand", Version: "99"},
+				},
+				Mobile:        true,
+				Platform:      "Android",
+				DPR:           2.5,
+				Width:         800,
+				ViewportWidth: 412,
+				SaveData:      true,
+			},
+		},
+		{
+			Header: http.Header{"Dpr": {"2"}, "Width": {"640"}, "Viewport-Width": {"320"}, "Save-Data": {"On; foo=bar"}},
+			Expect: ClientHints{DPR: 2, Width: 640, ViewportWidth: 320, SaveData: true},
+		},
+		{
+			Header: http.Header{"Sec-Ch-Dpr": {"3"}, "Dpr": {"2"}},
+			Expect: ClientHints{DPR: 3},
+		},
+		{
+			Header: http.Header{
+				"Sec-Ch-Ua":             {"Chromium"},
+				"Sec-Ch-Ua-Mobile":      {"1"},
+				"Sec-Ch-Ua-Platform":    {"Linux"},
+				"Sec-Ch-Dpr":            {"-1"},
+				"Sec-Ch-Width":          {"1.5"},
+				"Sec-Ch-Viewport-Width": {"wide"},
+				"Save-Data":             {"off"},
+			},
+			Expect: ClientHints{},
+		},
+		{Header: http.Header{}, Expect: ClientHints{}},
+	}
+
+	for i, tcase := range tcases {
+		t.Run(fmt.Sprintf("%d", i), func(t *testing.T) {
+			if got := ParseClientHints(tcase.Header); !reflect.DeepEqual(got, tcase.Expect) {
+				t.Fatalf("expected %+v, got %+v", tcase.Expect, got)
+			}
+		})
+	}
+}
+
+func TestAdvertiseClientHints(t *testing.T) {
+	t.Parallel()
+
+	tcases := []struct {
+		Hints      []string
+		Critical   []string
+		Vary       string
+		AcceptCH   string
+		CriticalCH string
+	}{
+		{
+			Hints:    []string{"Sec-CH-DPR", "Sec-CH-Width"},
+			Vary:     "Accept-Encoding, Sec-CH-DPR, Sec-CH-Width",
+			AcceptCH: "Sec-CH-DPR, Sec-CH-Width",
+		},
+		{
+			Hints:      []string{"Sec-CH-Width"},
+			Critical:   []string{"Sec-CH-DPR", "sec-ch-width"},
+			Vary:       "Accept-Encoding, Sec-CH-Width, Sec-CH-DPR",
+			AcceptCH:   "Sec-CH-Width, Sec-CH-DPR",
+			CriticalCH: "Sec-CH-DPR, sec-ch-width",
+		},
+		{Vary: "Accept-Encoding"},
+	}
+
+	for i, tcase := range tcases {
+		t.Run(fmt.Sprintf("%d", i), func(t *testing.T) {
+			h := http.Header{"Vary": {"Accept-Encoding"}}
+			AdvertiseClientHints(h, tcase.Hints, tcase.Critical...)
+			if got := h.Get("Accept-CH"); got != tcase.AcceptCH {
+				t.Fatalf("expected Accept-CH %q, got %q", tcase.AcceptCH, got)
+			}
+			if got := h.Get("Critical-CH"); got != tcase.CriticalCH {
+				t.Fatalf("expected Critical-CH %q, got %q", tcase.CriticalCH, got)
+			}
+			if got := strings.Join(h.Values("Vary"), ", "); got != tcase.Vary {
+				t.Fatalf("expected Vary %q, got %q", tcase.Vary, got)
+			}
+		})
+	}
+}
+
+func TestSelectImageWidth(t *testing.T) {
+	t.Parallel()
+
+	widths := []int{1600, 400, 800, 1200}
+
+	tcases := []struct {
+		Hints  ClientHints
+		Expect int
+	}{
+		{Hints: ClientHints{}, Expect: 800},
+		{Hints: ClientHints{Width: 640}, Expect: 800},
+		{Hints: ClientHints{Width: 800}, Expect: 800},
+		{Hints: ClientHints{Width: 3000}, Expect: 1600},
+		{Hints: ClientHints{ViewportWidth: 375, DPR: 3}, Expect: 1200},
+		{Hints: ClientHints{ViewportWidth: 375}, Expect: 400},
+		{Hints: ClientHints{ViewportWidth: 375, DPR: 3, SaveData: true}, Expect: 400},
+		{Hints: ClientHints{Width: 1500, DPR: 2, SaveData: true}, Expect: 800},
+		{Hints: ClientHints{SaveData: true}, Expect: 400},
+	}
+
+	for i, tcase := range tcases {
+		t.Run(fmt.Sprintf("%d", i), func(t *testing.T) {
+			if got := tcase.Hints.SelectImageWidth(widths, 800); got != tcase.Expect {
+				t.Fatalf("expected %d, got %d", tcase.Expect, got)
+			}
+		})
+	}
+
+	if got := (ClientHints{Width: 100}).SelectImageWidth(nil, 42); got != 42 {
+		t.Fatalf("expected the fallback without variants, got %d", got)
+	}
+}
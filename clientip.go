@@ -0,0 +1,136 @@
+// Copyright 2026 Franklin "Snaipe" Mathieu.
+//
+// Use of this source code is governed by the MIT license that can be
+// found in the LICENSE file.
+
+package htutil
+
+import (
+	"context"
+	"net"
+	"net/http"
+	"net/netip"
+	"strings"
+)
+
+// ClientIPResolver determines the address of the clients of a service
+// behind reverse proxies, from the addresses the proxies record in the
+// Forwarded (RFC 7239) or X-Forwarded-For header fields.
+//
+// The recorded addresses are only trusted as far as they were appended by
+// trusted proxies: the client address is the rightmost address that is not
+// one of a trusted proxy, since any address to its left may have been
+// forged by the client itself.
+type ClientIPResolver struct {
+	// TrustedProxies lists the addresses of the proxies whose records are
+	// honored.
+	TrustedProxies []netip.Prefix
+
+	// Header is the request header field that proxies record addresses
+	// in, either Forwarded or X-Forwarded-For. Defaults to Forwarded.
+	Header string
+}
+
+func (r *ClientIPResolver) trusted(addr netip.Addr) bool {
+	return prefixesContain(r.TrustedProxies, addr)
+}
+
+// prefixesContain returns whether addr belongs to any of prefixes.
+func prefixesContain(prefixes []netip.Prefix, addr netip.Addr) bool {
+	for _, prefix := range prefixes {
+		if prefix.Contains(addr) {
+			return true
+		}
+	}
+	return false
+}
+
+// peerIn returns whether the peer that sent req, as given by
+// http.Request.RemoteAddr, has an address belonging to any of prefixes.
+func peerIn(req *http.Request, prefixes []netip.Prefix) bool {
+	addr, ok := parseNodeAddr(req.RemoteAddr)
+	return ok && prefixesContain(prefixes, addr)
+}
+
+// Resolve returns the address of the client that made req. It returns
+// ok=false if the address cannot be determined, like when the closest
+// untrusted hop was recorded as "unknown" or as an obfuscated identifier.
+func (r *ClientIPResolver) Resolve(req *http.Request) (addr netip.Addr, ok bool) {
+	addr, ok = parseNodeAddr(req.RemoteAddr)
+	if !ok || !r.trusted(addr) {
+		return addr, ok
+	}
+
+	var hops []string
+	switch name := r.Header; {
+	case name == "" || strings.EqualFold(name, "Forwarded"):
+		hops = forwardedFor(req.Header.Values("Forwarded"))
+	default:
+		hops = SplitList(strings.Join(req.Header.Values(name), ","))
+	}
+	for i := len(hops) - 1; i >= 0; i-- {
+		if addr, ok = parseNodeAddr(hops[i]); !ok || !r.trusted(addr) {
+			return addr, ok
+		}
+	}
+	// All the hops are trusted proxies; the leftmost one is the closest to
+	// the client.
+	return addr, true
+}
+
+// forwardedFor returns the "for" parameters of the elements of the
+// Forwarded field values, in order. Invalid elements have an empty one.
+func forwardedFor(values []string) []string {
+	var out []string
+	for _, member := range SplitList(strings.Join(values, ",")) {
+		e, _ := parseForwardedElement(member)
+		out = append(out, e.For)
+	}
+	return out
+}
+
+// parseNodeAddr parses the address of a node, with an optional port, as
+// found in http.Request.RemoteAddr or in the Forwarded and X-Forwarded-For
+// fields.
+func parseNodeAddr(node string) (netip.Addr, bool) {
+	if host, _, err := net.SplitHostPort(node); err == nil {
+		node = host
+	}
+	node = strings.TrimSuffix(strings.TrimPrefix(node, "["), "]")
+	addr, err := netip.ParseAddr(node)
+	if err != nil {
+		return netip.Addr{}, false
+	}
+	return addr.Unmap(), true
+}
+
+// ClientIP returns the address of the client that made req, as recorded
+// in the header field by the proxies with addresses in trusted; see
+// ClientIPResolver.
+//
+// header must be the field that the trusted proxies set, either Forwarded
+// or X-Forwarded-For, since clients can forge the other one.
+func ClientIP(req *http.Request, header string, trusted []netip.Prefix) (netip.Addr, bool) {
+	r := ClientIPResolver{TrustedProxies: trusted, Header: header}
+	return r.Resolve(req)
+}
+
+type clientIPKey struct{}
+
+// Middleware returns a middleware that resolves the address of the clients
+// of next, and makes it available through ClientIPFromContext.
+func (r *ClientIPResolver) Middleware(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+		if addr, ok := r.Resolve(req); ok {
+			req = req.WithContext(context.WithValue(req.Context(), clientIPKey{}, addr))
+		}
+		next.ServeHTTP(w, req)
+	})
+}
+
+// ClientIPFromContext returns the client address resolved by a
+// ClientIPResolver middleware, for the request that ctx belongs to.
+func ClientIPFromContext(ctx context.Context) (netip.Addr, bool) {
+	addr, ok := ctx.Value(clientIPKey{}).(netip.Addr)
+	return addr, ok
+}
@@ -0,0 +1,113 @@
+// Copyright 2026 Franklin "Snaipe" Mathieu.
+//
+// Use of this source code is governed by the MIT license that can be
+// found in the LICENSE file.
+
+package htutil
+
+import (
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"net/netip"
+	"testing"
+)
+
+func TestClientIPResolver(t *testing.T) {
+	t.Parallel()
+
+	proxies := []netip.Prefix{netip.MustParsePrefix("10.0.0.0/8"), netip.MustParsePrefix("2001:db8:cafe::/48")}
+
+	tcases := []struct {
+		RemoteAddr string
+		Header     string
+		Values     []string
+		Expected   string
+	}{
+		{
+			RemoteAddr: "192.0.2.1:1234",
+			Values:     []string{"for=198.51.100.7"},
+			Expected:   "192.0.2.1",
+		},
+		{
+			RemoteAddr: "10.0.0.1:1234",
+			Expected:   "10.0.0.1",
+		},
+		{
+			RemoteAddr: "10.0.0.1:1234",
+			Values:     []string{`for=203.0.113.9, for=198.51.100.7;proto=https`, `for="[2001:db8:cafe::17]:4711"`},
+			Expected:   "198.51.100.7",
+		},
+		{
+			RemoteAddr: "[::ffff:10.0.0.1]:1234",
+			Values:     []string{"for=10.1.2.3;by=10.0.0.1"},
+			Expected:   "10.1.2.3",
+		},
+		{
+			RemoteAddr: "10.0.0.1:1234",
+			Values:     []string{"for=unknown"},
+			Expected:   "invalid IP",
+		},
+		{
+			RemoteAddr: "10.0.0.1:1234",
+			Header:     "X-Forwarded-For",
+			Values:     []string{"203.0.113.9, 198.51.100.7", "10.2.0.1"},
+			Expected:   "198.51.100.7",
+		},
+	}
+
+	for i, tcase := range tcases {
+		t.Run(fmt.Sprintf("%d", i), func(t *testing.T) {
+			r := &ClientIPResolver{TrustedProxies: proxies, Header: tcase.Header}
+
+			var addr netip.Addr
+			handler := r.Middleware(http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+				addr, _ = ClientIPFromContext(req.Context())
+			}))
+
+			req := httptest.NewRequest("GET", "/", nil)
+			req.RemoteAddr = tcase.RemoteAddr
+			name := tcase.Header
+			if name == "" {
+				name = "Forwarded"
+			}
+			for _, v := range tcase.Values {
+				req.Header.Add(name, v)
+			}
+			handler.ServeHTTP(httptest.NewRecorder(), req)
+
+			if addr.String() != tcase.Expected {
+				t.Fatalf("expected %v, got %v", tcase.Expected, addr)
+			}
+		})
+	}
+}
+
+func TestClientIP(t *testing.T) {
+	t.Parallel()
+
+	proxies := []netip.Prefix{netip.MustParsePrefix("10.0.0.0/8")}
+
+	tcases := []struct {
+		Field    string
+		Header   http.Header
+		Expected string
+	}{
+		{Field: "Forwarded", Header: http.Header{}, Expected: "10.0.0.1"},
+		{Field: "Forwarded", Header: http.Header{"Forwarded": {"for=192.0.2.1"}, "X-Forwarded-For": {"198.51.100.7"}}, Expected: "192.0.2.1"},
+		{Field: "X-Forwarded-For", Header: http.Header{"Forwarded": {"for=192.0.2.1"}, "X-Forwarded-For": {"198.51.100.7"}}, Expected: "198.51.100.7"},
+		{Field: "X-Forwarded-For", Header: http.Header{"X-Forwarded-For": {"192.0.2.1, 198.51.100.7, 10.0.0.2"}}, Expected: "198.51.100.7"},
+	}
+
+	for i, tcase := range tcases {
+		t.Run(fmt.Sprintf("%d", i), func(t *testing.T) {
+			req := httptest.NewRequest("GET", "/", nil)
+			req.RemoteAddr = "10.0.0.1:1234"
+			req.Header = tcase.Header
+			addr, ok := ClientIP(req, tcase.Field, proxies)
+			if !ok || addr.String() != tcase.Expected {
+				t.Fatalf("expected %v, got %v (%v)", tcase.Expected, addr, ok)
+			}
+		})
+	}
+}
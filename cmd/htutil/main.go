@@ -0,0 +1,197 @@
+// Copyright 2026 Franklin "Snaipe" Mathieu.
+//
+// Use of this source code is governed by the MIT license that can be
+// found in the LICENSE file.
+
+// Command htutil exposes the header parsing and content negotiation logic
+// of snai.pe/go-htutil, to debug it against real traffic samples.
+//
+// Usage:
+//
+//	htutil negotiate --accept 'text/html;q=0.9, */*;q=0.1' --offers text/html,application/json
+//	htutil parse <header-field> <value>
+//	htutil probe --ranges <url>
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"sort"
+	"strings"
+	"time"
+
+	"snai.pe/go-htutil"
+)
+
+const usage = `usage:
+	htutil negotiate [--header Accept] --accept VALUE --offers TYPE,... [TYPE...]
+	htutil parse HEADER-FIELD VALUE
+	htutil probe --ranges URL
+`
+
+var errUsage = errors.New("invalid usage")
+
+func main() {
+	err := run(os.Args[1:], os.Stdout)
+	if errors.Is(err, errUsage) {
+		fmt.Fprint(os.Stderr, usage)
+		os.Exit(2)
+	}
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "htutil: %v\n", err)
+		os.Exit(1)
+	}
+}
+
+func run(args []string, out io.Writer) error {
+	if len(args) == 0 {
+		return errUsage
+	}
+	switch args[0] {
+	case "negotiate":
+		return negotiate(args[1:], out)
+	case "parse":
+		return parse(args[1:], out)
+	case "probe":
+		return probe(args[1:], out)
+	}
+	return errUsage
+}
+
+func newFlagSet(name string) *flag.FlagSet {
+	fs := flag.NewFlagSet(name, flag.ContinueOnError)
+	fs.SetOutput(io.Discard)
+	return fs
+}
+
+func printJSON(out io.Writer, v interface{}) error {
+	enc := json.NewEncoder(out)
+	enc.SetIndent("", "  ")
+	return enc.Encode(v)
+}
+
+func negotiate(args []string, out io.Writer) error {
+	fs := newFlagSet("negotiate")
+	field := fs.String("header", "Accept", "request header field to negotiate on")
+	accept := fs.String("accept", "", "value of the request header field")
+	offers := fs.String("offers", "", "comma-separated list of offers")
+	if err := fs.Parse(args); err != nil {
+		return errUsage
+	}
+
+	var available []string
+	if *offers != "" {
+		available = strings.Split(*offers, ",")
+	}
+	available = append(available, fs.Args()...)
+	if len(available) == 0 {
+		return errUsage
+	}
+
+	fmt.Fprintln(out, "parsed:")
+	for _, acc := range htutil.ParseAccept(*accept) {
+		fmt.Fprintf(out, "\t%v\n", acc)
+	}
+
+	hdr := http.Header{}
+	hdr.Set(*field, *accept)
+	offer, acc := htutil.NegotiateContent(hdr, *field, available...)
+	switch {
+	case offer == "":
+		fmt.Fprintln(out, "selected: none")
+	case acc == nil:
+		// identity is implicitly acceptable, without a matching value.
+		fmt.Fprintf(out, "selected: %s (implicitly acceptable)\n", offer)
+	default:
+		fmt.Fprintf(out, "selected: %s (matching %v)\n", offer, acc)
+	}
+	return nil
+}
+
+type headerParser func(value string) (interface{}, error)
+
+var parsers = map[string]headerParser{
+	"accept": func(v string) (interface{}, error) {
+		return htutil.ParseAccept(v), nil
+	},
+	"cache-control": func(v string) (interface{}, error) {
+		cc, err := htutil.ParseCacheControl(v)
+		return cc.String(), err
+	},
+	"cache-status": func(v string) (interface{}, error) {
+		return htutil.ParseCacheStatus(v)
+	},
+	"clear-site-data": func(v string) (interface{}, error) {
+		c, err := htutil.ParseClearSiteData(v)
+		return c.String(), err
+	},
+	"deprecation": func(v string) (interface{}, error) {
+		return htutil.ParseDeprecation(v)
+	},
+	"origin": func(v string) (interface{}, error) {
+		return htutil.ParseOrigin(v)
+	},
+	"proxy-status": func(v string) (interface{}, error) {
+		return htutil.ParseProxyStatus(v)
+	},
+	"server": func(v string) (interface{}, error) {
+		return htutil.ParseUserAgent(v)
+	},
+	"sunset": func(v string) (interface{}, error) {
+		return htutil.ParseSunset(v)
+	},
+	"user-agent": func(v string) (interface{}, error) {
+		return htutil.ParseUserAgent(v)
+	},
+	"variant-key": func(v string) (interface{}, error) {
+		return htutil.ParseVariantKey(v)
+	},
+	"variants": func(v string) (interface{}, error) {
+		return htutil.ParseVariants(v)
+	},
+}
+
+func parse(args []string, out io.Writer) error {
+	if len(args) != 2 {
+		return errUsage
+	}
+	name := strings.ToLower(args[0])
+	parser, ok := parsers[name]
+	if !ok {
+		names := make([]string, 0, len(parsers))
+		for k := range parsers {
+			names = append(names, k)
+		}
+		sort.Strings(names)
+		return fmt.Errorf("unsupported header field %q (supported: %s)", args[0], strings.Join(names, ", "))
+	}
+	v, err := parser(args[1])
+	if err != nil {
+		return err
+	}
+	return printJSON(out, v)
+}
+
+func probe(args []string, out io.Writer) error {
+	fs := newFlagSet("probe")
+	ranges := fs.Bool("ranges", false, "probe for byte range support")
+	timeout := fs.Duration("timeout", 10*time.Second, "probe timeout")
+	if err := fs.Parse(args); err != nil || fs.NArg() != 1 || !*ranges {
+		return errUsage
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), *timeout)
+	defer cancel()
+
+	support, err := htutil.ProbeRanges(ctx, http.DefaultClient, fs.Arg(0))
+	if err != nil {
+		return err
+	}
+	return printJSON(out, support)
+}
@@ -0,0 +1,75 @@
+// Copyright 2026 Franklin "Snaipe" Mathieu.
+//
+// Use of this source code is governed by the MIT license that can be
+// found in the LICENSE file.
+
+package main
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+	"testing"
+)
+
+func TestRun(t *testing.T) {
+	t.Parallel()
+
+	tcases := []struct {
+		Args   []string
+		Output string
+		Err    bool
+	}{
+		{
+			Args:   []string{"negotiate", "--accept", "text/html;q=0.9, */*;q=0.1", "--offers", "application/json,text/html"},
+			Output: "selected: text/html (matching text/html;q=0.9)",
+		},
+		{
+			Args:   []string{"negotiate", "--accept", "text/html", "application/json"},
+			Output: "selected: none",
+		},
+		{
+			Args:   []string{"negotiate", "--header", "Accept-Encoding", "--accept", "br", "gzip", "identity"},
+			Output: "selected: identity (implicitly acceptable)",
+		},
+		{
+			Args:   []string{"parse", "User-Agent", "curl/8.0 (x86_64)"},
+			Output: `"Version": "8.0"`,
+		},
+		{
+			Args:   []string{"parse", "clear-site-data", `"cache", "cookies"`},
+			Output: `"\"cache\", \"cookies\""`,
+		},
+		{
+			Args:   []string{"parse", "cache-control", "MAX-AGE=60, public"},
+			Output: `"public, max-age=60"`,
+		},
+		{Args: []string{"parse", "x-unknown", "value"}, Err: true},
+		{Args: []string{"parse", "origin", "not an origin"}, Err: true},
+		{Args: []string{"probe", "http://example.com"}, Err: true},
+		{Args: []string{}, Err: true},
+	}
+
+	for i, tcase := range tcases {
+		t.Run(fmt.Sprintf("%d", i), func(t *testing.T) {
+			var out strings.Builder
+			err := run(tcase.Args, &out)
+			if tcase.Err {
+				if err == nil {
+					t.Fatalf("expected error, got output %q", out.String())
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("expected no error, got %v", err)
+			}
+			if !strings.Contains(out.String(), tcase.Output) {
+				t.Fatalf("expected output containing %q, got %q", tcase.Output, out.String())
+			}
+		})
+	}
+
+	if err := run([]string{"bogus"}, &strings.Builder{}); !errors.Is(err, errUsage) {
+		t.Fatalf("expected usage error, got %v", err)
+	}
+}
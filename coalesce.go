@@ -0,0 +1,131 @@
+// Copyright 2026 Franklin "Snaipe" Mathieu.
+//
+// Use of this source code is governed by the MIT license that can be
+// found in the LICENSE file.
+
+package htutil
+
+import (
+	"net/http"
+	"strings"
+	"sync"
+)
+
+// coalescedCall is a handler execution whose response is shared by all
+// the concurrent requests with the same cache key.
+type coalescedCall struct {
+	*responseBuffer
+	done   chan struct{}
+	req    *http.Request
+	shared bool
+}
+
+// shareable returns whether the response may be sent to other clients.
+func (c *coalescedCall) shareable() bool {
+	if c.status == 0 || c.bypassed || len(c.header.Values("Set-Cookie")) > 0 {
+		return false
+	}
+	cc, _ := ParseCacheControl(c.header.Values("Cache-Control")...)
+	return !cc.Private && !cc.NoStore
+}
+
+// varyMatches returns whether the response to a request can be used to
+// satisfy other, given the Vary header of that response.
+func varyMatches(req, other *http.Request, hdr http.Header) bool {
+	for _, field := range ParseVary(hdr) {
+		if field == "*" {
+			return false
+		}
+		lhs := strings.Join(req.Header.Values(field), ",")
+		rhs := strings.Join(other.Header.Values(field), ",")
+		if lhs != rhs {
+			return false
+		}
+	}
+	return true
+}
+
+// Coalesce returns a middleware that deduplicates concurrent identical GET
+// and HEAD requests: the first request executes next, and its buffered
+// response is replayed to all the requests that arrived while it was being
+// served.
+//
+// Requests are considered identical if they have the same CacheKey for the
+// vary fields and any Authorization or Cookie fields, and if they match on
+// the fields listed in the Vary header of the shared response. Responses
+// with Vary: *, Set-Cookie, or a private or no-store Cache-Control are never
+// shared; the waiting requests execute next themselves instead.
+//
+// Since the response of the first request is shared with the others, its
+// cancellation affects all the waiting requests.
+func Coalesce(next http.Handler, vary ...string) http.Handler {
+	var (
+		mu    sync.Mutex
+		calls = map[string]*coalescedCall{}
+	)
+
+	fields := append([]string{"Authorization", "Cookie"}, vary...)
+
+	return http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+		if req.Method != http.MethodGet && req.Method != http.MethodHead {
+			next.ServeHTTP(w, req)
+			return
+		}
+
+		key := CacheKey(req, fields...)
+
+		mu.Lock()
+		call, waiting := calls[key]
+		if !waiting {
+			var buf *responseBuffer
+			buf, req = newBypassableBuffer(w, req)
+			call = &coalescedCall{
+				responseBuffer: buf,
+				done:           make(chan struct{}),
+				req:            req,
+			}
+			calls[key] = call
+		}
+		mu.Unlock()
+
+		if waiting {
+			select {
+			case <-call.done:
+			case <-req.Context().Done():
+				return
+			}
+			if !call.shared || !varyMatches(call.req, req, call.header) {
+				next.ServeHTTP(w, req)
+				return
+			}
+		} else {
+			func() {
+				defer func() {
+					call.shared = call.shareable()
+
+					mu.Lock()
+					delete(calls, key)
+					mu.Unlock()
+
+					close(call.done)
+				}()
+				next.ServeHTTP(call, req)
+				if call.bypassed {
+					// The response was streamed to the first client
+					// only; the others execute next themselves.
+					return
+				}
+				if call.status == 0 {
+					// The handler wrote nothing, which stands for an
+					// empty 200 OK response.
+					call.WriteHeader(http.StatusOK)
+				}
+			}()
+			if call.bypassed {
+				return
+			}
+		}
+
+		call.replay(w, req)
+	})
+}
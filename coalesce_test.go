@@ -0,0 +1,128 @@
+// Copyright 2026 Franklin "Snaipe" Mathieu.
+//
+// Use of this source code is governed by the MIT license that can be
+// found in the LICENSE file.
+
+package htutil
+
+import (
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func TestCoalesce(t *testing.T) {
+	t.Parallel()
+
+	tcases := []struct {
+		Vary     string
+		Langs    []string
+		Expected int32
+	}{
+		{Vary: "", Langs: []string{"en", "fr", "de"}, Expected: 1},
+		{Vary: "Accept-Language", Langs: []string{"en", "en", "en"}, Expected: 1},
+		{Vary: "Accept-Language", Langs: []string{"en", "fr", "fr"}, Expected: 3},
+		{Vary: "*", Langs: []string{"en", "en"}, Expected: 2},
+	}
+
+	for i, tcase := range tcases {
+		t.Run(fmt.Sprintf("%d", i), func(t *testing.T) {
+			var calls int32
+			started := make(chan struct{}, len(tcase.Langs))
+			release := make(chan struct{})
+
+			handler := Coalesce(http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+				if atomic.AddInt32(&calls, 1) == 1 {
+					started <- struct{}{}
+					<-release
+				}
+				if tcase.Vary != "" {
+					w.Header().Set("Vary", tcase.Vary)
+				}
+				fmt.Fprint(w, "hello")
+			}))
+
+			serve := func(lang string) *httptest.ResponseRecorder {
+				req := httptest.NewRequest("GET", "/", nil)
+				req.Header.Set("Accept-Language", lang)
+				w := httptest.NewRecorder()
+				handler.ServeHTTP(w, req)
+				return w
+			}
+
+			var wg sync.WaitGroup
+			results := make([]*httptest.ResponseRecorder, len(tcase.Langs))
+			wg.Add(1)
+			go func() {
+				defer wg.Done()
+				results[0] = serve(tcase.Langs[0])
+			}()
+			<-started
+
+			for i, lang := range tcase.Langs[1:] {
+				wg.Add(1)
+				go func(i int, lang string) {
+					defer wg.Done()
+					results[i] = serve(lang)
+				}(i+1, lang)
+			}
+			time.Sleep(10 * time.Millisecond)
+			close(release)
+			wg.Wait()
+
+			if calls != tcase.Expected {
+				t.Fatalf("expected %d handler calls, got %d", tcase.Expected, calls)
+			}
+			for _, w := range results {
+				if w.Body.String() != "hello" {
+					t.Fatalf("expected body %q, got %q", "hello", w.Body.String())
+				}
+			}
+		})
+	}
+}
+
+func TestCoalesceEmptyResponse(t *testing.T) {
+	t.Parallel()
+
+	var calls int32
+	started := make(chan struct{})
+	release := make(chan struct{})
+
+	handler := Coalesce(http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+		if atomic.AddInt32(&calls, 1) == 1 {
+			close(started)
+			<-release
+		}
+	}))
+
+	results := make([]*httptest.ResponseRecorder, 2)
+	var wg sync.WaitGroup
+	for i := range results {
+		results[i] = httptest.NewRecorder()
+		wg.Add(1)
+		go func(w *httptest.ResponseRecorder) {
+			defer wg.Done()
+			handler.ServeHTTP(w, httptest.NewRequest("GET", "/", nil))
+		}(results[i])
+		if i == 0 {
+			<-started
+		}
+	}
+	time.Sleep(10 * time.Millisecond)
+	close(release)
+	wg.Wait()
+
+	if calls != 1 {
+		t.Fatalf("expected the empty response to be shared, got %d handler calls", calls)
+	}
+	for _, w := range results {
+		if w.Code != http.StatusOK || w.Body.Len() != 0 {
+			t.Fatalf("expected an empty 200 response, got %d %q", w.Code, w.Body.String())
+		}
+	}
+}
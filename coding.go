@@ -0,0 +1,225 @@
+// Copyright 2026 Franklin "Snaipe" Mathieu.
+//
+// Use of this source code is governed by the MIT license that can be
+// found in the LICENSE file.
+
+package htutil
+
+import (
+	"bytes"
+	"compress/gzip"
+	"compress/zlib"
+	"crypto/sha256"
+	"errors"
+	"fmt"
+	"io"
+	"sort"
+	"strings"
+	"sync"
+)
+
+// Coding implements a content coding, such as gzip.
+type Coding struct {
+	// NewWriter returns a writer encoding to w. Level is the compression
+	// level, whose meaning depends on the coding; 0 selects the default
+	// level of the coding.
+	NewWriter func(w io.Writer, level int) (io.WriteCloser, error)
+
+	// NewReader returns a reader decoding r.
+	NewReader func(r io.Reader) (io.ReadCloser, error)
+}
+
+// DictionaryCoding implements a dictionary-compressed content coding, such
+// as dcb (Brotli) or dcz (Zstandard), as per Compression Dictionary
+// Transport (RFC 9842).
+//
+// NewWriter and NewReader implement the raw compression format with an
+// external dictionary; the header of the dcb and dcz codings, which
+// identifies the dictionary, is written and checked by NewDictionaryEncoder
+// and NewDictionaryDecoder.
+type DictionaryCoding struct {
+	NewWriter func(w io.Writer, dict []byte, level int) (io.WriteCloser, error)
+	NewReader func(r io.Reader, dict []byte) (io.ReadCloser, error)
+}
+
+var codings = struct {
+	sync.RWMutex
+	plain map[string]Coding
+	dict  map[string]DictionaryCoding
+}{
+	plain: map[string]Coding{
+		"gzip": {
+			NewWriter: func(w io.Writer, level int) (io.WriteCloser, error) {
+				if level == 0 {
+					level = gzip.DefaultCompression
+				}
+				return gzip.NewWriterLevel(w, level)
+			},
+			NewReader: func(r io.Reader) (io.ReadCloser, error) {
+				return gzip.NewReader(r)
+			},
+		},
+		// The deflate coding is the zlib format, not raw deflate.
+		"deflate": {
+			NewWriter: func(w io.Writer, level int) (io.WriteCloser, error) {
+				if level == 0 {
+					level = zlib.DefaultCompression
+				}
+				return zlib.NewWriterLevel(w, level)
+			},
+			NewReader: zlib.NewReader,
+		},
+	},
+	dict: map[string]DictionaryCoding{},
+}
+
+// RegisterCoding registers the implementation of the content coding name,
+// replacing any previously registered implementation. The gzip and deflate
+// codings are registered by default.
+//
+// Codings whose implementation lives outside of the standard library, like
+// br or zstd, are made available to this package by registering them.
+func RegisterCoding(name string, c Coding) {
+	codings.Lock()
+	defer codings.Unlock()
+	codings.plain[strings.ToLower(name)] = c
+}
+
+// RegisterDictionaryCoding registers the implementation of the
+// dictionary-compressed content coding name, replacing any previously
+// registered implementation. No dictionary coding is registered by default.
+func RegisterDictionaryCoding(name string, c DictionaryCoding) {
+	codings.Lock()
+	defer codings.Unlock()
+	codings.dict[strings.ToLower(name)] = c
+}
+
+// Codings returns the names of the registered content codings, sorted.
+func Codings() []string {
+	codings.RLock()
+	defer codings.RUnlock()
+	names := make([]string, 0, len(codings.plain))
+	for name := range codings.plain {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// DictionaryCodings returns the names of the registered dictionary
+// content codings, sorted.
+func DictionaryCodings() []string {
+	codings.RLock()
+	defer codings.RUnlock()
+	names := make([]string, 0, len(codings.dict))
+	for name := range codings.dict {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// ErrUnknownCoding is returned when a content coding is not registered.
+var ErrUnknownCoding = errors.New("unknown content coding")
+
+type nopWriteCloser struct {
+	io.Writer
+}
+
+func (nopWriteCloser) Close() error { return nil }
+
+// NewEncoder returns a writer encoding to w with the content coding name.
+// The identity coding writes to w as is.
+func NewEncoder(w io.Writer, name string, level int) (io.WriteCloser, error) {
+	name = strings.ToLower(name)
+	if name == "identity" {
+		return nopWriteCloser{w}, nil
+	}
+	codings.RLock()
+	c, ok := codings.plain[name]
+	codings.RUnlock()
+	if !ok {
+		return nil, fmt.Errorf("%w %q", ErrUnknownCoding, name)
+	}
+	return c.NewWriter(w, level)
+}
+
+// NewDecoder returns a reader decoding r with the content coding name. The
+// identity coding reads r as is.
+func NewDecoder(r io.Reader, name string) (io.ReadCloser, error) {
+	name = strings.ToLower(name)
+	if name == "identity" {
+		return io.NopCloser(r), nil
+	}
+	codings.RLock()
+	c, ok := codings.plain[name]
+	codings.RUnlock()
+	if !ok {
+		return nil, fmt.Errorf("%w %q", ErrUnknownCoding, name)
+	}
+	return c.NewReader(r)
+}
+
+// dictionaryMagic are the magic numbers preceding the SHA-256 hash of the
+// dictionary in the header of dictionary-compressed streams.
+var dictionaryMagic = map[string][]byte{
+	"dcb": {0xff, 0x44, 0x43, 0x42},
+	"dcz": {0x5e, 0x2a, 0x4d, 0x18, 0x20, 0x00, 0x00, 0x00},
+}
+
+func dictionaryCoding(name string) (DictionaryCoding, error) {
+	codings.RLock()
+	c, ok := codings.dict[name]
+	codings.RUnlock()
+	if !ok {
+		return DictionaryCoding{}, fmt.Errorf("%w %q", ErrUnknownCoding, name)
+	}
+	return c, nil
+}
+
+// NewDictionaryEncoder returns a writer encoding to w with the dictionary
+// content coding name, using dict as the dictionary. For the dcb and dcz
+// codings, the stream header identifying dict is written first.
+func NewDictionaryEncoder(w io.Writer, name string, dict []byte, level int) (io.WriteCloser, error) {
+	name = strings.ToLower(name)
+	c, err := dictionaryCoding(name)
+	if err != nil {
+		return nil, err
+	}
+	if magic, ok := dictionaryMagic[name]; ok {
+		hash := sha256.Sum256(dict)
+		if _, err := w.Write(append(append([]byte(nil), magic...), hash[:]...)); err != nil {
+			return nil, err
+		}
+	}
+	return c.NewWriter(w, dict, level)
+}
+
+// ErrDictionaryMismatch is returned when decoding a dictionary-compressed
+// stream that was not encoded with the specified dictionary.
+var ErrDictionaryMismatch = errors.New("content was compressed with another dictionary")
+
+// NewDictionaryDecoder returns a reader decoding r with the dictionary
+// content coding name, using dict as the dictionary. For the dcb and dcz
+// codings, the stream header is checked to identify dict.
+func NewDictionaryDecoder(r io.Reader, name string, dict []byte) (io.ReadCloser, error) {
+	name = strings.ToLower(name)
+	c, err := dictionaryCoding(name)
+	if err != nil {
+		return nil, err
+	}
+	if magic, ok := dictionaryMagic[name]; ok {
+		hdr := make([]byte, len(magic)+sha256.Size)
+		if _, err := io.ReadFull(r, hdr); err != nil {
+			return nil, fmt.Errorf("reading %s header: %w", name, err)
+		}
+		hash := sha256.Sum256(dict)
+		if !bytes.Equal(hdr[:len(magic)], magic) {
+			return nil, fmt.Errorf("reading %s header: bad magic number", name)
+		}
+		if !bytes.Equal(hdr[len(magic):], hash[:]) {
+			return nil, ErrDictionaryMismatch
+		}
+	}
+	return c.NewReader(r, dict)
+}
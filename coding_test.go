@@ -0,0 +1,119 @@
+// Copyright 2026 Franklin "Snaipe" Mathieu.
+//
+// Use of this source code is governed by the MIT license that can be
+// found in the LICENSE file.
+
+package htutil
+
+import (
+	"bytes"
+	"compress/flate"
+	"errors"
+	"fmt"
+	"io"
+	"testing"
+)
+
+func init() {
+	// Stand-in for Zstandard, which is not in the standard library; only
+	// the framing of dcz matters to these tests.
+	RegisterDictionaryCoding("dcz", DictionaryCoding{
+		NewWriter: func(w io.Writer, dict []byte, level int) (io.WriteCloser, error) {
+			if level == 0 {
+				level = flate.DefaultCompression
+			}
+			return flate.NewWriterDict(w, level, dict)
+		},
+		NewReader: func(r io.Reader, dict []byte) (io.ReadCloser, error) {
+			return flate.NewReaderDict(r, dict), nil
+		},
+	})
+}
+
+func TestCodings(t *testing.T) {
+	t.Parallel()
+
+	content := bytes.Repeat([]byte("hello, world! "), 64)
+
+	tcases := []struct {
+		Coding string
+		Err    error
+	}{
+		{Coding: "gzip"},
+		{Coding: "deflate"},
+		{Coding: "GZIP"},
+		{Coding: "identity"},
+		{Coding: "compress", Err: ErrUnknownCoding},
+	}
+
+	for i, tcase := range tcases {
+		t.Run(fmt.Sprintf("%d", i), func(t *testing.T) {
+			var buf bytes.Buffer
+			enc, err := NewEncoder(&buf, tcase.Coding, 0)
+			if !errors.Is(err, tcase.Err) {
+				t.Fatalf("expected error %v, got %v", tcase.Err, err)
+			}
+			if err != nil {
+				return
+			}
+			enc.Write(content)
+			if err := enc.Close(); err != nil {
+				t.Fatal(err)
+			}
+
+			dec, err := NewDecoder(&buf, tcase.Coding)
+			if err != nil {
+				t.Fatal(err)
+			}
+			out, err := io.ReadAll(dec)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if !bytes.Equal(out, content) {
+				t.Fatalf("expected %q, got %q", content, out)
+			}
+		})
+	}
+}
+
+func TestDictionaryCodings(t *testing.T) {
+	t.Parallel()
+
+	dict := []byte("function hello() { return 'hello, world!'; }")
+	content := []byte("function hello() { return 'hello, dictionary!'; }")
+
+	var buf bytes.Buffer
+	enc, err := NewDictionaryEncoder(&buf, "dcz", dict, 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	enc.Write(content)
+	enc.Close()
+
+	encoded := buf.Bytes()
+	if magic := dictionaryMagic["dcz"]; !bytes.HasPrefix(encoded, magic) {
+		t.Fatalf("expected dcz magic number, got %x", encoded[:len(magic)])
+	}
+	if hash := encoded[len(dictionaryMagic["dcz"]):][:32]; !bytes.Equal(hash, DictionaryHash(dict)) {
+		t.Fatalf("expected dictionary hash %x, got %x", DictionaryHash(dict), hash)
+	}
+
+	dec, err := NewDictionaryDecoder(bytes.NewReader(encoded), "dcz", dict)
+	if err != nil {
+		t.Fatal(err)
+	}
+	out, err := io.ReadAll(dec)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !bytes.Equal(out, content) {
+		t.Fatalf("expected %q, got %q", content, out)
+	}
+
+	if _, err := NewDictionaryDecoder(bytes.NewReader(encoded), "dcz", []byte("other")); !errors.Is(err, ErrDictionaryMismatch) {
+		t.Fatalf("expected error %v, got %v", ErrDictionaryMismatch, err)
+	}
+	if _, err := NewDictionaryEncoder(&buf, "dcb", dict, 0); !errors.Is(err, ErrUnknownCoding) {
+		t.Fatalf("expected error %v, got %v", ErrUnknownCoding, err)
+	}
+}
@@ -0,0 +1,155 @@
+// Copyright 2026 Franklin "Snaipe" Mathieu.
+//
+// Use of this source code is governed by the MIT license that can be
+// found in the LICENSE file.
+
+package htutil
+
+import (
+	"fmt"
+	"strings"
+)
+
+// isCtext returns whether c may appear unescaped in a comment, as per
+// RFC 9110 §5.6.5.
+func isCtext(c byte) bool {
+	return c == '\t' || c == ' ' || (c >= 0x21 && c <= 0x27) || (c >= 0x2A && c <= 0x5B) || (c >= 0x5D && c <= 0x7E) || c >= 0x80
+}
+
+// ParseComment parses the comment at the start of s, as per RFC 9110 §5.6.5,
+// and returns its content without the enclosing parentheses, as well as the
+// remainder of s.
+//
+// Quoted-pairs in the comment are unescaped, but nested comments are
+// returned verbatim, parentheses included.
+func ParseComment(s string) (comment, rest string, err error) {
+	if s == "" || s[0] != '(' {
+		return "", s, fmt.Errorf("%q does not start with a comment", s)
+	}
+
+	var (
+		out   strings.Builder
+		depth = 1
+	)
+	for i := 1; i < len(s); i++ {
+		switch c := s[i]; {
+		case c == '\\':
+			i++
+			if i == len(s) || !isQuotable(s[i]) {
+				return "", s, fmt.Errorf("invalid quoted-pair at offset %d in comment", i)
+			}
+			if depth > 1 {
+				out.WriteByte('\\')
+			}
+			out.WriteByte(s[i])
+		case c == '(':
+			depth++
+			out.WriteByte(c)
+		case c == ')':
+			depth--
+			if depth == 0 {
+				return out.String(), s[i+1:], nil
+			}
+			out.WriteByte(c)
+		case isCtext(c):
+			out.WriteByte(c)
+		default:
+			return "", s, fmt.Errorf("invalid character %q at offset %d in comment", c, i)
+		}
+	}
+	return "", s, fmt.Errorf("unterminated comment")
+}
+
+// Word is an element of a field value that allows comments: either a
+// sequence of non-whitespace characters, or a comment.
+type Word struct {
+	// Text is the word itself, or the content of the comment.
+	Text string
+
+	// Comment is true if the word is a comment.
+	Comment bool
+}
+
+// SplitWords splits a field value that allows comments, like User-Agent,
+// Server, or a single member of Via, into whitespace-separated words and
+// comments.
+func SplitWords(value string) ([]Word, error) {
+	var words []Word
+	for {
+		value = trimOWS(value)
+		if value == "" {
+			return words, nil
+		}
+		if value[0] == '(' {
+			comment, rest, err := ParseComment(value)
+			if err != nil {
+				return nil, err
+			}
+			words = append(words, Word{Text: comment, Comment: true})
+			value = rest
+			continue
+		}
+		end := strings.IndexAny(value, " \t(")
+		if end == -1 {
+			end = len(value)
+		}
+		words = append(words, Word{Text: value[:end]})
+		value = value[end:]
+	}
+}
+
+// SplitCommentedList is like SplitList, but for list-based fields whose
+// members allow comments, like Via. Commas within comments do not delimit
+// members.
+func SplitCommentedList(value string) []string {
+	var (
+		out    []string
+		depth  int
+		quoted bool
+		start  int
+	)
+	for i := 0; i < len(value); i++ {
+		switch c := value[i]; {
+		case (quoted || depth > 0) && c == '\\':
+			i++
+		case depth == 0 && c == '"':
+			quoted = !quoted
+		case quoted:
+		case c == '(':
+			depth++
+		case c == ')' && depth > 0:
+			depth--
+		case depth == 0 && c == ',':
+			if member := trimOWS(value[start:i]); member != "" {
+				out = append(out, member)
+			}
+			start = i + 1
+		}
+	}
+	if member := trimOWS(value[start:]); member != "" {
+		out = append(out, member)
+	}
+	return out
+}
+
+// quoteComment returns s as a comment, escaping any parenthesis or
+// backslash. Control characters other than horizontal tabs cannot be
+// represented in a comment, and are replaced by spaces.
+func quoteComment(s string) string {
+	var out strings.Builder
+	out.Grow(len(s) + 2)
+	out.WriteByte('(')
+	for i := 0; i < len(s); i++ {
+		switch c := s[i]; {
+		case c == '(' || c == ')' || c == '\\':
+			out.WriteByte('\\')
+			out.WriteByte(c)
+		case isCtext(c):
+			out.WriteByte(c)
+		default:
+			out.WriteByte(' ')
+		}
+	}
+	out.WriteByte(')')
+	return out.String()
+}
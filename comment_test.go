@@ -0,0 +1,103 @@
+// Copyright 2026 Franklin "Snaipe" Mathieu.
+//
+// Use of this source code is governed by the MIT license that can be
+// found in the LICENSE file.
+
+package htutil
+
+import (
+	"fmt"
+	"reflect"
+	"testing"
+)
+
+func TestParseComment(t *testing.T) {
+	t.Parallel()
+
+	tcases := []struct {
+		In      string
+		Comment string
+		Rest    string
+		Err     bool
+	}{
+		{In: "(hello) world", Comment: "hello", Rest: " world"},
+		{In: "()", Comment: ""},
+		{In: `(a \(b\) c)`, Comment: "a (b) c"},
+		{In: `(outer (inner \) x) y)z`, Comment: `outer (inner \) x) y`, Rest: "z"},
+		{In: "(unterminated", Err: true},
+		{In: "(a (b)", Err: true},
+		{In: "no comment", Err: true},
+		{In: "(ctl\x01)", Err: true},
+	}
+
+	for i, tcase := range tcases {
+		t.Run(fmt.Sprintf("%d", i), func(t *testing.T) {
+			comment, rest, err := ParseComment(tcase.In)
+			if tcase.Err {
+				if err == nil {
+					t.Fatalf("expected error, got %q", comment)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatal(err)
+			}
+			if comment != tcase.Comment || rest != tcase.Rest {
+				t.Fatalf("expected (%q, %q), got (%q, %q)", tcase.Comment, tcase.Rest, comment, rest)
+			}
+		})
+	}
+}
+
+func TestSplitWords(t *testing.T) {
+	t.Parallel()
+
+	tcases := []struct {
+		In  string
+		Out []Word
+	}{
+		{
+			In: "Mozilla/5.0 (X11; Linux x86_64; rv:109.0) Gecko/20100101 Firefox/115.0",
+			Out: []Word{
+				{Text: "Mozilla/5.0"},
+				{Text: "X11; Linux x86_64; rv:109.0", Comment: true},
+				{Text: "Gecko/20100101"},
+				{Text: "Firefox/115.0"},
+			},
+		},
+		{
+			In: "1.1 proxy.example.com(squid (nested))",
+			Out: []Word{
+				{Text: "1.1"},
+				{Text: "proxy.example.com"},
+				{Text: "squid (nested)", Comment: true},
+			},
+		},
+	}
+
+	for i, tcase := range tcases {
+		t.Run(fmt.Sprintf("%d", i), func(t *testing.T) {
+			actual, err := SplitWords(tcase.In)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if !reflect.DeepEqual(actual, tcase.Out) {
+				t.Fatalf("expected %v, got %v", tcase.Out, actual)
+			}
+		})
+	}
+}
+
+func TestSplitCommentedList(t *testing.T) {
+	t.Parallel()
+
+	actual := SplitCommentedList(`1.0 fred, 1.1 p.example.net (Apache/1.1, "x"), ,1.1 "y,z" (a \) ,)`)
+	expected := []string{
+		"1.0 fred",
+		`1.1 p.example.net (Apache/1.1, "x")`,
+		`1.1 "y,z" (a \) ,)`,
+	}
+	if !reflect.DeepEqual(actual, expected) {
+		t.Fatalf("expected %q, got %q", expected, actual)
+	}
+}
@@ -0,0 +1,477 @@
+// Copyright 2026 Franklin "Snaipe" Mathieu.
+//
+// Use of this source code is governed by the MIT license that can be
+// found in the LICENSE file.
+
+package htutil
+
+import (
+	"bytes"
+	"context"
+	"io"
+	"log/slog"
+	"mime"
+	"net/http"
+	"strconv"
+	"strings"
+)
+
+// codingPreference is the order in which well-known codings are offered
+// by default, from the most to the least efficient.
+var codingPreference = []string{"dcz", "dcb", "zstd", "br", "gzip", "deflate"}
+
+func preferredCodings(names []string) []string {
+	var out []string
+	for _, name := range codingPreference {
+		if containsString(names, name) {
+			out = append(out, name)
+		}
+	}
+	for _, name := range names {
+		if !containsString(out, name) {
+			out = append(out, name)
+		}
+	}
+	return out
+}
+
+// incompressibleTypes are the media types that are excluded from
+// compression by default, as they are already compressed, or are streams
+// that must not be held back.
+var incompressibleTypes = []string{
+	"image/avif",
+	"image/gif",
+	"image/jpeg",
+	"image/png",
+	"image/webp",
+	"audio/*",
+	"video/*",
+	"font/woff",
+	"font/woff2",
+	"application/gzip",
+	"application/x-gzip",
+	"application/zip",
+	"application/zstd",
+	"application/x-7z-compressed",
+	"application/x-rar-compressed",
+	"text/event-stream",
+}
+
+// Compression compresses responses with the content coding preferred by
+// the client, among the registered codings.
+//
+// Responses that have no content, that already have a Content-Encoding,
+// or that are partial (206) are left untouched. The ETag of compressed
+// responses is weakened, as they are no longer byte-for-byte identical to
+// the uncompressed representation.
+//
+// Handlers may adjust the compression of their own responses with
+// SetCompressionLevel and DisableCompression.
+type Compression struct {
+	// Codings are the content codings to offer, in order of preference.
+	// Defaults to all the registered codings, with the most efficient
+	// well-known codings first.
+	Codings []string
+
+	// Level is the compression level passed to the codings.
+	Level int
+
+	// MinSize is the size under which responses are not compressed. The
+	// first MinSize bytes of responses without a Content-Length are
+	// buffered to decide whether to compress them, unless they get
+	// flushed earlier.
+	MinSize int
+
+	// ContentTypes, if not empty, restricts compression to responses
+	// whose media type matches one of the specified patterns, like
+	// "text/*" or "application/json".
+	ContentTypes []string
+
+	// ExcludeContentTypes are patterns of media types that are never
+	// compressed. Defaults to common formats that are already compressed,
+	// like images, audio, video, and archives, as well as event streams.
+	ExcludeContentTypes []string
+
+	// DisableCrossSite, if set, disables compression of the responses to
+	// cross-site requests carrying credentials (cookies, or an
+	// Authorization header field), as reported by Sec-Fetch-Site.
+	//
+	// This mitigates BREACH-style attacks, where an attacker makes a
+	// victim send requests with chosen inputs, and observes the size of
+	// the compressed responses reflecting them alongside secrets.
+	// Responses carrying secrets, like CSRF tokens, can also be excluded
+	// individually with DisableCompression.
+	DisableCrossSite bool
+
+	// Dictionary returns the dictionary identified by the specified hash
+	// and Dictionary-ID, or nil if it is not available. If set, requests
+	// advertising an available dictionary get responses compressed with
+	// the registered dictionary codings, as per RFC 9842.
+	Dictionary func(req *http.Request, hash []byte, id string) []byte
+
+	// RejectUnacceptable, if set, makes requests that accept none of the
+	// offered codings, and refuse the identity coding with identity;q=0 or
+	// *;q=0, get a 406 Not Acceptable response. Otherwise, their responses
+	// are sent uncompressed, as most clients cope with them anyway.
+	RejectUnacceptable bool
+}
+
+type compressionKey struct{}
+
+// SetCompressionLevel overrides the compression level of the response to
+// req, if served through a Compression middleware. It must be called
+// before the response body gets written.
+func SetCompressionLevel(req *http.Request, level int) {
+	if cw, ok := req.Context().Value(compressionKey{}).(*compressWriter); ok {
+		cw.level = level
+	}
+}
+
+// DisableCompression disables the compression of the response to req, if
+// served through a Compression middleware. It must be called before the
+// response body gets written.
+//
+// Responses that reflect user input alongside secrets should not be
+// compressed, as their size would leak information about the secrets.
+func DisableCompression(req *http.Request) {
+	if cw, ok := req.Context().Value(compressionKey{}).(*compressWriter); ok {
+		cw.disabled = true
+	}
+}
+
+// Middleware returns a middleware that compresses the responses of next.
+func (c *Compression) Middleware(next http.Handler) http.Handler {
+	offers := c.Codings
+	if len(offers) == 0 {
+		offers = preferredCodings(Codings())
+	}
+	// The identity coding is always offered, as a fallback. The capacity
+	// of offers is capped so that appending copies it rather than writing
+	// into the spare capacity of c.Codings, which requests share.
+	offers = append(offers[:len(offers):len(offers)], "identity")
+	dictOffers := preferredCodings(DictionaryCodings())
+	exclude := c.ExcludeContentTypes
+	if exclude == nil {
+		exclude = incompressibleTypes
+	}
+
+	return http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+		if c.RejectUnacceptable {
+			if coding, _ := NegotiateContent(req.Header, "Accept-Encoding", offers...); coding == "" {
+				AddVary(w.Header(), "Accept-Encoding")
+				RespondError(w, req, NewProblem(http.StatusNotAcceptable,
+					"Available content codings: "+strings.Join(offers[:len(offers)-1], ", ")+"."))
+				return
+			}
+		}
+		cw := &compressWriter{
+			w:          w,
+			req:        req,
+			c:          c,
+			offers:     offers,
+			dictOffers: dictOffers,
+			exclude:    exclude,
+			level:      c.Level,
+			disabled:   c.DisableCrossSite && crossSiteCredentialed(req),
+		}
+		req = req.WithContext(context.WithValue(req.Context(), compressionKey{}, cw))
+		next.ServeHTTP(Wrap(w, WriterHooks{
+			WriteHeader: func(WriteHeaderFunc) WriteHeaderFunc { return cw.writeHeader },
+			Write:       func(WriteFunc) WriteFunc { return cw.write },
+			Flush:       func(next FlushFunc) FlushFunc { return func() { cw.flush(next) } },
+		}), req)
+		cw.finish()
+	})
+}
+
+// NegotiateEncoding returns a middleware that compresses the responses of
+// next with the content coding negotiated among offers, which must be
+// registered codings (see RegisterCoding). Requests refusing the identity
+// coding and accepting none of offers get a 406 Not Acceptable response.
+//
+// It is a shorthand for a Compression middleware with RejectUnacceptable
+// set, which allows finer control over what gets compressed.
+func NegotiateEncoding(next http.Handler, offers ...string) http.Handler {
+	c := &Compression{Codings: offers, RejectUnacceptable: true}
+	return c.Middleware(next)
+}
+
+// crossSiteCredentialed returns whether req is a cross-site request that
+// carries credentials.
+func crossSiteCredentialed(req *http.Request) bool {
+	if req.Header.Get("Sec-Fetch-Site") != "cross-site" {
+		return false
+	}
+	return req.Header.Get("Cookie") != "" || req.Header.Get("Authorization") != ""
+}
+
+// compressWriter holds back the header of a response until enough of its
+// body is known to decide whether to compress it.
+type compressWriter struct {
+	w          http.ResponseWriter
+	req        *http.Request
+	c          *Compression
+	offers     []string
+	dictOffers []string
+	exclude    []string
+	level      int
+	disabled   bool
+
+	status    int
+	committed bool
+	buf       []byte
+	coding    string
+	dict      []byte
+	enc       io.WriteCloser
+}
+
+func (cw *compressWriter) writeHeader(status int) {
+	if cw.committed || (status >= 100 && status < 200 && status != http.StatusSwitchingProtocols) {
+		// Let the underlying writer deal with informational responses,
+		// and complain about superfluous calls.
+		cw.w.WriteHeader(status)
+		return
+	}
+	if cw.status != 0 {
+		return
+	}
+	cw.status = status
+	if !Status(status).AllowsBody() {
+		cw.commit(true)
+	}
+}
+
+func (cw *compressWriter) write(p []byte) (int, error) {
+	if cw.status == 0 {
+		cw.writeHeader(http.StatusOK)
+	}
+	if !cw.committed {
+		cw.buf = append(cw.buf, p...)
+		if len(cw.buf) >= cw.c.MinSize || cw.w.Header().Get("Content-Length") != "" {
+			if err := cw.commit(false); err != nil {
+				return 0, err
+			}
+		}
+		return len(p), nil
+	}
+	if cw.enc == nil {
+		return cw.w.Write(p)
+	}
+	return cw.enc.Write(p)
+}
+
+func (cw *compressWriter) flush(next FlushFunc) {
+	if cw.status == 0 {
+		cw.writeHeader(http.StatusOK)
+	}
+	if !cw.committed {
+		cw.commit(false)
+	}
+	if f, ok := cw.enc.(interface{ Flush() error }); ok {
+		f.Flush()
+	}
+	next()
+}
+
+func (cw *compressWriter) finish() {
+	if cw.status == 0 {
+		// Nothing was written; let the server send its default response.
+		return
+	}
+	if !cw.committed {
+		cw.commit(true)
+	}
+	if cw.enc != nil {
+		if err := cw.enc.Close(); err != nil {
+			cw.logger().Error("closing encoder", slog.String("coding", cw.coding), slog.String("error", err.Error()))
+		}
+	}
+}
+
+func (cw *compressWriter) logger() *slog.Logger {
+	return ContextLogger(cw.req.Context())
+}
+
+// commit selects the coding of the response, and writes its header, then
+// the buffered part of its body. complete reports whether the buffered
+// part is the whole body.
+func (cw *compressWriter) commit(complete bool) error {
+	cw.committed = true
+	h := cw.w.Header()
+	if h.Get("Content-Type") == "" && len(cw.buf) > 0 && h.Get("X-Content-Type-Options") != "nosniff" {
+		// The server would otherwise sniff the compressed bytes.
+		h.Set("Content-Type", http.DetectContentType(cw.buf))
+	}
+	var out *deferredWriter
+	if cw.compressible(complete) {
+		etag, length := h.Get("ETag"), h.Get("Content-Length")
+		cw.negotiate()
+		var err error
+		if out, err = cw.openEncoder(); err != nil {
+			// Serve the response as is rather than failing it.
+			cw.logger().Error("opening encoder", slog.String("coding", cw.coding), slog.String("error", err.Error()))
+			cw.coding, cw.dict = "", nil
+			h.Del("Content-Encoding")
+			if etag != "" {
+				h.Set("ETag", etag)
+			}
+			if length != "" {
+				h.Set("Content-Length", length)
+			}
+		}
+	}
+	cw.w.WriteHeader(cw.status)
+	if out != nil {
+		if err := out.flush(cw.w); err != nil {
+			return err
+		}
+	}
+
+	buf := cw.buf
+	cw.buf = nil
+	if len(buf) == 0 {
+		return nil
+	}
+	_, err := cw.write(buf)
+	return err
+}
+
+// openEncoder creates the encoder of the selected coding, if any. Since
+// the header of the response is not written yet, the encoder writes to the
+// returned deferredWriter, which must be flushed once it is.
+func (cw *compressWriter) openEncoder() (*deferredWriter, error) {
+	if cw.coding == "" {
+		return nil, nil
+	}
+	dw := &deferredWriter{}
+	var (
+		enc io.WriteCloser
+		err error
+	)
+	if cw.dict != nil {
+		enc, err = NewDictionaryEncoder(dw, cw.coding, cw.dict, cw.level)
+	} else {
+		enc, err = NewEncoder(dw, cw.coding, cw.level)
+	}
+	if err != nil {
+		// enc may be a typed nil, which must not end up in cw.enc.
+		return nil, err
+	}
+	cw.enc = enc
+	return dw, nil
+}
+
+// deferredWriter buffers the writes of an encoder until the header of the
+// response is written, after which they go to the response.
+type deferredWriter struct {
+	w   io.Writer
+	buf []byte
+}
+
+func (dw *deferredWriter) Write(p []byte) (int, error) {
+	if dw.w == nil {
+		dw.buf = append(dw.buf, p...)
+		return len(p), nil
+	}
+	return dw.w.Write(p)
+}
+
+// flush writes the buffered writes to w, and sends the next ones there.
+func (dw *deferredWriter) flush(w io.Writer) error {
+	dw.w = w
+	buf := dw.buf
+	dw.buf = nil
+	if len(buf) == 0 {
+		return nil
+	}
+	_, err := w.Write(buf)
+	return err
+}
+
+// compressible returns whether the compression policy allows compressing
+// the response.
+func (cw *compressWriter) compressible(complete bool) bool {
+	h := cw.w.Header()
+	if cw.disabled || !Status(cw.status).AllowsBody() || cw.status == http.StatusPartialContent {
+		return false
+	}
+	if complete && len(cw.buf) < cw.c.MinSize {
+		return false
+	}
+	if n, err := strconv.ParseInt(h.Get("Content-Length"), 10, 64); err == nil && n < int64(cw.c.MinSize) {
+		return false
+	}
+	mediaType, _, _ := mime.ParseMediaType(h.Get("Content-Type"))
+	if len(cw.c.ContentTypes) > 0 && !matchesMediaType(cw.c.ContentTypes, mediaType) {
+		return false
+	}
+	return !matchesMediaType(cw.exclude, mediaType)
+}
+
+func matchesMediaType(patterns []string, mediaType string) bool {
+	for _, pattern := range patterns {
+		if dumbglob(strings.ToLower(pattern), mediaType) {
+			return true
+		}
+	}
+	return false
+}
+
+// negotiate selects the coding of the response, and adjusts its header
+// accordingly.
+func (cw *compressWriter) negotiate() {
+	h := cw.w.Header()
+	AddVary(h, "Accept-Encoding")
+	if h.Get("Content-Encoding") != "" || h.Get("Content-Range") != "" {
+		return
+	}
+
+	if coding, dict := cw.negotiateDictionary(); coding != "" {
+		cw.coding, cw.dict = coding, dict
+		AddVary(h, "Available-Dictionary")
+		if cw.req.Header.Get("Dictionary-ID") != "" {
+			AddVary(h, "Dictionary-ID")
+		}
+	} else {
+		if len(cw.req.Header.Values("Accept-Encoding")) == 0 {
+			// Although any coding is acceptable then, clients that do not
+			// send Accept-Encoding are unlikely to handle any.
+			return
+		}
+		n := NewNegotiation(cw.req.Header, "Accept-Encoding", cw.offers...)
+		cw.logger().Debug("negotiated content coding", slog.Any("negotiation", n))
+		if n.Selected == "" || n.Selected == "identity" {
+			return
+		}
+		cw.coding = n.Selected
+	}
+
+	h.Set("Content-Encoding", cw.coding)
+	h.Del("Content-Length")
+	if etag := h.Get("ETag"); etag != "" && !strings.HasPrefix(etag, "W/") {
+		h.Set("ETag", "W/"+etag)
+	}
+}
+
+// negotiateDictionary returns the dictionary coding and dictionary to use
+// for the response, if the client advertised a dictionary that is
+// available, and accepts a registered dictionary coding.
+func (cw *compressWriter) negotiateDictionary() (string, []byte) {
+	if cw.c.Dictionary == nil || len(cw.dictOffers) == 0 {
+		return "", nil
+	}
+	hash, id, ok := AvailableDictionary(cw.req.Header)
+	if !ok {
+		return "", nil
+	}
+	dict := cw.c.Dictionary(cw.req, hash, id)
+	if dict == nil || !bytes.Equal(DictionaryHash(dict), hash) {
+		return "", nil
+	}
+	coding, acc := NegotiateContent(cw.req.Header, "Accept-Encoding", cw.dictOffers...)
+	if acc == nil || acc.Value == "*" {
+		// Dictionary codings must be explicitly accepted.
+		return "", nil
+	}
+	return coding, dict
+}
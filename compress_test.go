@@ -0,0 +1,393 @@
+// Copyright 2026 Franklin "Snaipe" Mathieu.
+//
+// Use of this source code is governed by the MIT license that can be
+// found in the LICENSE file.
+
+package htutil
+
+import (
+	"bytes"
+	"compress/gzip"
+	"encoding/base64"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+)
+
+func TestCompression(t *testing.T) {
+	t.Parallel()
+
+	dict := []byte("hello, dictionary world! ")
+	content := strings.Repeat("hello, world! ", 64)
+	availableDict := ":" + base64.StdEncoding.EncodeToString(DictionaryHash(dict)) + ":"
+
+	compression := &Compression{
+		Dictionary: func(req *http.Request, hash []byte, id string) []byte {
+			if bytes.Equal(hash, DictionaryHash(dict)) {
+				return dict
+			}
+			return nil
+		},
+	}
+
+	tcases := []struct {
+		Status   int
+		Header   http.Header
+		Request  http.Header
+		Encoding string
+		ETag     string
+		Vary     string
+	}{
+		{
+			Status:   http.StatusOK,
+			Header:   http.Header{"Etag": {`"v1"`}, "Content-Length": {fmt.Sprint(len(content))}},
+			Request:  http.Header{"Accept-Encoding": {"gzip, deflate"}},
+			Encoding: "gzip",
+			ETag:     `W/"v1"`,
+			Vary:     "Accept-Encoding",
+		},
+		{
+			Status:   http.StatusOK,
+			Request:  http.Header{"Accept-Encoding": {"gzip;q=0.5, deflate"}},
+			Encoding: "deflate",
+			Vary:     "Accept-Encoding",
+		},
+		{
+			Status:  http.StatusOK,
+			Header:  http.Header{"Etag": {`"v1"`}},
+			Request: http.Header{"Accept-Encoding": {"identity"}},
+			ETag:    `"v1"`,
+			Vary:    "Accept-Encoding",
+		},
+		{
+			Status: http.StatusOK,
+			Vary:   "Accept-Encoding",
+		},
+		{
+			Status:   http.StatusOK,
+			Header:   http.Header{"Content-Encoding": {"br"}},
+			Request:  http.Header{"Accept-Encoding": {"gzip, br"}},
+			Encoding: "br",
+			Vary:     "Accept-Encoding",
+		},
+		{
+			Status:  http.StatusPartialContent,
+			Header:  http.Header{"Content-Range": {"bytes 0-9/100"}},
+			Request: http.Header{"Accept-Encoding": {"gzip"}},
+		},
+		{
+			Status:  http.StatusNoContent,
+			Request: http.Header{"Accept-Encoding": {"gzip"}},
+		},
+		{
+			Status:   http.StatusOK,
+			Request:  http.Header{"Accept-Encoding": {"gzip, dcz"}, "Available-Dictionary": {availableDict}, "Dictionary-Id": {`"v1"`}},
+			Encoding: "dcz",
+			Vary:     "Accept-Encoding, Available-Dictionary, Dictionary-ID",
+		},
+		{
+			Status:   http.StatusOK,
+			Request:  http.Header{"Accept-Encoding": {"gzip, dcz"}, "Available-Dictionary": {":" + base64.StdEncoding.EncodeToString(DictionaryHash(nil)) + ":"}},
+			Encoding: "gzip",
+			Vary:     "Accept-Encoding",
+		},
+		{
+			Status:   http.StatusOK,
+			Request:  http.Header{"Accept-Encoding": {"*"}, "Available-Dictionary": {availableDict}},
+			Encoding: "gzip",
+			Vary:     "Accept-Encoding",
+		},
+	}
+
+	for i, tcase := range tcases {
+		t.Run(fmt.Sprintf("%d", i), func(t *testing.T) {
+			handler := compression.Middleware(http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+				for k, v := range tcase.Header {
+					w.Header()[k] = v
+				}
+				w.WriteHeader(tcase.Status)
+				if Status(tcase.Status).AllowsBody() {
+					io.WriteString(w, content[:len(content)/2])
+					w.(http.Flusher).Flush()
+					io.WriteString(w, content[len(content)/2:])
+				}
+			}))
+
+			req := httptest.NewRequest("GET", "/", nil)
+			for k, v := range tcase.Request {
+				req.Header[k] = v
+			}
+			w := httptest.NewRecorder()
+			handler.ServeHTTP(w, req)
+
+			resp := w.Result()
+			if v := resp.Header.Get("Content-Encoding"); v != tcase.Encoding {
+				t.Fatalf("expected Content-Encoding %q, got %q", tcase.Encoding, v)
+			}
+			if v := resp.Header.Get("ETag"); v != tcase.ETag {
+				t.Fatalf("expected ETag %q, got %q", tcase.ETag, v)
+			}
+			if v := strings.Join(resp.Header.Values("Vary"), ", "); v != tcase.Vary {
+				t.Fatalf("expected Vary %q, got %q", tcase.Vary, v)
+			}
+			if tcase.Encoding != "" && resp.Header.Get("Content-Length") != "" {
+				t.Fatalf("expected no Content-Length, got %q", resp.Header.Get("Content-Length"))
+			}
+			if !Status(tcase.Status).AllowsBody() || tcase.Encoding == "br" {
+				return
+			}
+
+			var body io.Reader = resp.Body
+			var err error
+			if tcase.Encoding == "dcz" {
+				body, err = NewDictionaryDecoder(body, tcase.Encoding, dict)
+			} else if tcase.Encoding != "" {
+				body, err = NewDecoder(body, tcase.Encoding)
+			}
+			if err != nil {
+				t.Fatal(err)
+			}
+			out, err := io.ReadAll(body)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if string(out) != content {
+				t.Fatalf("expected %q, got %q", content, out)
+			}
+		})
+	}
+}
+
+func TestCompressionConcurrent(t *testing.T) {
+	t.Parallel()
+
+	// Spare capacity in Codings must not be written to by the middleware,
+	// since it is shared by all requests; run with -race.
+	codings := make([]string, 1, 8)
+	codings[0] = "gzip"
+	c := &Compression{Codings: codings}
+	handler := c.Middleware(http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+		w.Header().Set("Content-Type", "text/plain")
+		fmt.Fprint(w, strings.Repeat("hello, world! ", 64))
+	}))
+
+	var wg sync.WaitGroup
+	for _, ae := range []string{"gzip", "br", "identity", "gzip;q=0", "*"} {
+		wg.Add(1)
+		go func(ae string) {
+			defer wg.Done()
+			req := httptest.NewRequest("GET", "/", nil)
+			req.Header.Set("Accept-Encoding", ae)
+			handler.ServeHTTP(httptest.NewRecorder(), req)
+		}(ae)
+	}
+	wg.Wait()
+
+	if extra := codings[:cap(codings)][1]; extra != "" {
+		t.Fatalf("expected Codings to be left untouched, got %q appended", extra)
+	}
+}
+
+func TestCompressionPolicy(t *testing.T) {
+	t.Parallel()
+
+	content := strings.Repeat("hello, world! ", 64)
+
+	tcases := []struct {
+		Compression Compression
+		Header      http.Header
+		Request     http.Header
+		Body        string
+		Handler     func(req *http.Request)
+		Encoding    string
+		ETag        string
+		MinLength   int
+	}{
+		{
+			Compression: Compression{MinSize: 1024},
+			Body:        "tiny",
+			Encoding:    "",
+		},
+		{
+			Compression: Compression{MinSize: 128},
+			Body:        content,
+			Encoding:    "gzip",
+		},
+		{
+			Compression: Compression{MinSize: 1024},
+			Header:      http.Header{"Content-Length": {"896"}},
+			Body:        content,
+			Encoding:    "",
+		},
+		{
+			Compression: Compression{ContentTypes: []string{"text/*"}},
+			Header:      http.Header{"Content-Type": {"application/json"}},
+			Body:        content,
+			Encoding:    "",
+		},
+		{
+			Compression: Compression{ContentTypes: []string{"text/*"}},
+			Header:      http.Header{"Content-Type": {"text/html; charset=utf-8"}},
+			Body:        content,
+			Encoding:    "gzip",
+		},
+		{
+			Header:   http.Header{"Content-Type": {"image/png"}},
+			Body:     content,
+			Encoding: "",
+		},
+		{
+			Compression: Compression{ExcludeContentTypes: []string{}},
+			Header:      http.Header{"Content-Type": {"image/png"}},
+			Body:        content,
+			Encoding:    "gzip",
+		},
+		{
+			Body:     content,
+			Handler:  DisableCompression,
+			Encoding: "",
+		},
+		{
+			Body:      content,
+			Handler:   func(req *http.Request) { SetCompressionLevel(req, gzip.HuffmanOnly) },
+			Encoding:  "gzip",
+			MinLength: 256,
+		},
+		{
+			Compression: Compression{DisableCrossSite: true},
+			Request:     http.Header{"Sec-Fetch-Site": {"cross-site"}, "Cookie": {"session=secret"}},
+			Body:        content,
+			Encoding:    "",
+		},
+		{
+			Compression: Compression{DisableCrossSite: true},
+			Request:     http.Header{"Sec-Fetch-Site": {"same-origin"}, "Cookie": {"session=secret"}},
+			Body:        content,
+			Encoding:    "gzip",
+		},
+		{
+			Compression: Compression{Level: 42},
+			Header:      http.Header{"Etag": {`"abc"`}},
+			Body:        content,
+			Encoding:    "",
+			ETag:        `"abc"`,
+		},
+		{
+			Body:     content,
+			Handler:  func(req *http.Request) { SetCompressionLevel(req, -42) },
+			Encoding: "",
+		},
+	}
+
+	for i, tcase := range tcases {
+		t.Run(fmt.Sprintf("%d", i), func(t *testing.T) {
+			handler := tcase.Compression.Middleware(http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+				if tcase.Handler != nil {
+					tcase.Handler(req)
+				}
+				for k, v := range tcase.Header {
+					w.Header()[k] = v
+				}
+				io.WriteString(w, tcase.Body)
+			}))
+
+			req := httptest.NewRequest("GET", "/", nil)
+			req.Header.Set("Accept-Encoding", "gzip")
+			for k, v := range tcase.Request {
+				req.Header[k] = v
+			}
+			w := httptest.NewRecorder()
+			handler.ServeHTTP(w, req)
+
+			resp := w.Result()
+			if v := resp.Header.Get("Content-Encoding"); v != tcase.Encoding {
+				t.Fatalf("expected Content-Encoding %q, got %q", tcase.Encoding, v)
+			}
+			if v := resp.Header.Get("ETag"); tcase.ETag != "" && v != tcase.ETag {
+				t.Fatalf("expected ETag %q, got %q", tcase.ETag, v)
+			}
+			if w.Body.Len() < tcase.MinLength {
+				t.Fatalf("expected at least %d bytes, got %d", tcase.MinLength, w.Body.Len())
+			}
+
+			coding := tcase.Encoding
+			if coding == "" {
+				coding = "identity"
+			}
+			body, err := NewDecoder(resp.Body, coding)
+			if err != nil {
+				t.Fatal(err)
+			}
+			out, err := io.ReadAll(body)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if string(out) != tcase.Body {
+				t.Fatalf("expected %q, got %q", tcase.Body, out)
+			}
+		})
+	}
+}
+
+func TestNegotiateEncoding(t *testing.T) {
+	t.Parallel()
+
+	content := strings.Repeat("hello, world! ", 64)
+	handler := NegotiateEncoding(http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+		w.Header().Set("Content-Type", "text/plain")
+		io.WriteString(w, content)
+	}), "deflate", "gzip")
+
+	tcases := []struct {
+		AcceptEncoding string
+		Status         int
+		Coding         string
+	}{
+		{AcceptEncoding: "<none>", Status: 200, Coding: ""},
+		{AcceptEncoding: "gzip, deflate;q=0.9", Status: 200, Coding: "gzip"},
+		{AcceptEncoding: "deflate, gzip;q=0.9", Status: 200, Coding: "deflate"},
+		{AcceptEncoding: "gzip;q=1, deflate;q=0.5", Status: 200, Coding: "gzip"},
+		{AcceptEncoding: "br", Status: 200, Coding: ""},
+		{AcceptEncoding: "br, identity;q=0", Status: 406},
+		{AcceptEncoding: "br, *;q=0", Status: 406},
+		{AcceptEncoding: "gzip, *;q=0", Status: 200, Coding: "gzip"},
+	}
+
+	for i, tcase := range tcases {
+		t.Run(fmt.Sprintf("%d", i), func(t *testing.T) {
+			req := httptest.NewRequest("GET", "/", nil)
+			if tcase.AcceptEncoding != "<none>" {
+				req.Header.Set("Accept-Encoding", tcase.AcceptEncoding)
+			}
+			rw := httptest.NewRecorder()
+			handler.ServeHTTP(rw, req)
+
+			if rw.Code != tcase.Status {
+				t.Fatalf("expected status %d, got %d", tcase.Status, rw.Code)
+			}
+			if vary := rw.Header().Values("Vary"); len(vary) == 0 || vary[0] != "Accept-Encoding" {
+				t.Fatalf("expected Vary: Accept-Encoding, got %v", vary)
+			}
+			if tcase.Status != 200 {
+				return
+			}
+			if coding := rw.Header().Get("Content-Encoding"); coding != tcase.Coding {
+				t.Fatalf("expected coding %q, got %q", tcase.Coding, coding)
+			}
+			dec, err := NewDecoder(rw.Body, "identity")
+			if tcase.Coding != "" {
+				dec, err = NewDecoder(rw.Body, tcase.Coding)
+			}
+			if err != nil {
+				t.Fatalf("expected no error, got %v", err)
+			}
+			if body, _ := io.ReadAll(dec); string(body) != content {
+				t.Fatalf("expected decoded content, got %q", body)
+			}
+		})
+	}
+}
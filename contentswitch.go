@@ -0,0 +1,72 @@
+// Copyright 2026 Franklin "Snaipe" Mathieu.
+//
+// Use of this source code is governed by the MIT license that can be
+// found in the LICENSE file.
+
+package htutil
+
+import (
+	"context"
+	"mime"
+	"net/http"
+	"strings"
+)
+
+// ContentSwitch is a http.Handler that serves requests with the handler
+// registered for the media type negotiated with their Accept header field.
+//
+// The zero value is an empty ContentSwitch, replying 406 Not Acceptable to
+// all requests. Handlers must be registered before serving requests.
+type ContentSwitch struct {
+	offers   []string
+	handlers map[string]contentSwitchCase
+}
+
+type contentSwitchCase struct {
+	contentType string
+	handler     http.Handler
+}
+
+// Handle registers the handler for the media type mediaType, which may
+// have parameters, like "text/plain; charset=utf-8". Media types are
+// offered in registration order; registering a media type again replaces
+// its handler.
+func (cs *ContentSwitch) Handle(mediaType string, handler http.Handler) {
+	essence, _, err := mime.ParseMediaType(mediaType)
+	if err != nil {
+		panic("htutil: invalid media type " + mediaType)
+	}
+	if cs.handlers == nil {
+		cs.handlers = make(map[string]contentSwitchCase)
+	}
+	if _, ok := cs.handlers[essence]; !ok {
+		cs.offers = append(cs.offers, essence)
+	}
+	cs.handlers[essence] = contentSwitchCase{contentType: mediaType, handler: handler}
+}
+
+// HandleFunc registers the handler function for the media type mediaType.
+func (cs *ContentSwitch) HandleFunc(mediaType string, handler func(http.ResponseWriter, *http.Request)) {
+	cs.Handle(mediaType, http.HandlerFunc(handler))
+}
+
+// ServeHTTP negotiates the media type of the response, and serves req with
+// its handler, with the Content-Type header field set to the media type as
+// registered. The outcome of the negotiation is available to the handler
+// through NegotiationFromContext. Responses Vary on Accept.
+//
+// If no media type is acceptable, the response is a 406 Not Acceptable
+// problem listing the available media types.
+func (cs *ContentSwitch) ServeHTTP(w http.ResponseWriter, req *http.Request) {
+	n := NewNegotiation(req.Header, "Accept", cs.offers...)
+	n.Vary(w.Header())
+	if n.Selected == "" {
+		RespondError(w, req, NewProblem(http.StatusNotAcceptable,
+			"Available representations: "+strings.Join(cs.offers, ", ")+"."))
+		return
+	}
+	c := cs.handlers[n.Selected]
+	w.Header().Set("Content-Type", c.contentType)
+	ctx := context.WithValue(req.Context(), negotiationKey("Accept"), n)
+	c.handler.ServeHTTP(w, req.WithContext(ctx))
+}
@@ -0,0 +1,78 @@
+// Copyright 2026 Franklin "Snaipe" Mathieu.
+//
+// Use of this source code is governed by the MIT license that can be
+// found in the LICENSE file.
+
+package htutil
+
+import (
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+func TestContentSwitch(t *testing.T) {
+	t.Parallel()
+
+	var cs ContentSwitch
+	cs.HandleFunc("application/json", func(w http.ResponseWriter, req *http.Request) {
+		io.WriteString(w, `{"message":"OK"}`)
+	})
+	cs.HandleFunc("text/plain; charset=utf-8", func(w http.ResponseWriter, req *http.Request) {
+		n, _ := NegotiationFromContext(req.Context(), "Accept")
+		io.WriteString(w, "OK "+n.Match.Value)
+	})
+	cs.HandleFunc("application/json", func(w http.ResponseWriter, req *http.Request) {
+		io.WriteString(w, `{"message":"replaced"}`)
+	})
+
+	tcases := []struct {
+		Accept      string
+		Status      int
+		ContentType string
+		Body        string
+	}{
+		{Accept: "", Status: 200, ContentType: "application/json", Body: `{"message":"replaced"}`},
+		{Accept: "text/*", Status: 200, ContentType: "text/plain; charset=utf-8", Body: "OK text/*"},
+		{Accept: "application/json;q=0.5, text/plain", Status: 200, ContentType: "text/plain; charset=utf-8", Body: "OK text/plain"},
+		{Accept: "image/png", Status: 406, ContentType: "text/plain; charset=utf-8", Body: "Available representations: application/json, text/plain."},
+	}
+
+	for i, tcase := range tcases {
+		t.Run(fmt.Sprintf("%d", i), func(t *testing.T) {
+			req := httptest.NewRequest("GET", "/", nil)
+			if tcase.Accept != "" {
+				req.Header.Set("Accept", tcase.Accept)
+			}
+			rw := httptest.NewRecorder()
+			cs.ServeHTTP(rw, req)
+
+			if rw.Code != tcase.Status {
+				t.Fatalf("expected status %d, got %d", tcase.Status, rw.Code)
+			}
+			if ctype := rw.Header().Get("Content-Type"); ctype != tcase.ContentType {
+				t.Fatalf("expected Content-Type %q, got %q", tcase.ContentType, ctype)
+			}
+			if !strings.Contains(rw.Body.String(), tcase.Body) {
+				t.Fatalf("expected body containing %q, got %q", tcase.Body, rw.Body.String())
+			}
+			if vary := rw.Header().Get("Vary"); vary != "Accept" {
+				t.Fatalf("expected Vary: Accept, got %q", vary)
+			}
+		})
+	}
+}
+
+func TestContentSwitchEmpty(t *testing.T) {
+	t.Parallel()
+
+	var cs ContentSwitch
+	rw := httptest.NewRecorder()
+	cs.ServeHTTP(rw, httptest.NewRequest("GET", "/", nil))
+	if rw.Code != http.StatusNotAcceptable {
+		t.Fatalf("expected status 406, got %d", rw.Code)
+	}
+}
@@ -0,0 +1,200 @@
+// Copyright 2026 Franklin "Snaipe" Mathieu.
+//
+// Use of this source code is governed by the MIT license that can be
+// found in the LICENSE file.
+
+package htutil
+
+import (
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"net/http"
+	"strings"
+	"time"
+)
+
+// ValidateCookie returns an error if c is not a valid cookie, or if it
+// does not meet the requirements of its name prefix, as per
+// draft-ietf-httpbis-rfc6265bis §4.1.3:
+//
+//   - __Secure- cookies must be Secure;
+//   - __Host- cookies must be Secure, have a Path of "/", and no Domain.
+//
+// Cookies with SameSite=None must also be Secure, as browsers reject them
+// otherwise.
+func ValidateCookie(c *http.Cookie) error {
+	if err := c.Valid(); err != nil {
+		return err
+	}
+	switch {
+	case strings.HasPrefix(c.Name, "__Host-"):
+		if !c.Secure || c.Path != "/" || c.Domain != "" {
+			return fmt.Errorf("cookie %q: __Host- prefix requires Secure, Path=/, and no Domain", c.Name)
+		}
+	case strings.HasPrefix(c.Name, "__Secure-"):
+		if !c.Secure {
+			return fmt.Errorf("cookie %q: __Secure- prefix requires Secure", c.Name)
+		}
+	}
+	if c.SameSite == http.SameSiteNoneMode && !c.Secure {
+		return fmt.Errorf("cookie %q: SameSite=None requires Secure", c.Name)
+	}
+	return nil
+}
+
+// SetCookie adds a Set-Cookie header field for c to the response of w,
+// like http.SetCookie, after validating it with ValidateCookie.
+func SetCookie(w http.ResponseWriter, c *http.Cookie) error {
+	if err := ValidateCookie(c); err != nil {
+		return err
+	}
+	http.SetCookie(w, c)
+	return nil
+}
+
+// ErrInvalidCookie is returned when a cookie value has an invalid
+// signature, cannot be decrypted, or has expired.
+var ErrInvalidCookie = errors.New("invalid cookie")
+
+// CookieSigner signs or encrypts cookie values, so that clients can
+// neither forge nor, if encrypted, read them.
+//
+// Values are bound to the name of their cookie, and to its expiration
+// time, if any, so that they cannot be moved to another cookie, nor
+// replayed after having expired.
+type CookieSigner struct {
+	// Keys are the secret keys. Values are signed or encrypted with the
+	// first key, and verified with any key, so that keys can be rotated by
+	// prepending new ones, and removing old ones once the cookies that they
+	// signed have expired.
+	Keys [][]byte
+
+	// Encrypt is whether values are encrypted with AES-GCM, rather than
+	// only signed with HMAC-SHA256.
+	Encrypt bool
+}
+
+// Sign replaces the value of c with its signed or encrypted form.
+func (s *CookieSigner) Sign(c *http.Cookie) error {
+	if len(s.Keys) == 0 {
+		return errors.New("signing cookie: no keys")
+	}
+	var expires int64
+	switch {
+	case c.MaxAge > 0:
+		expires = time.Now().Add(time.Duration(c.MaxAge) * time.Second).Unix()
+	case !c.Expires.IsZero():
+		expires = c.Expires.Unix()
+	}
+	data := binary.BigEndian.AppendUint64(nil, uint64(expires))
+	data = append(data, c.Value...)
+
+	if !s.Encrypt {
+		payload := base64.RawURLEncoding.EncodeToString(data)
+		c.Value = payload + "." + signCookie(s.Keys[0], c.Name, payload)
+		return nil
+	}
+	aead, err := cookieAEAD(s.Keys[0])
+	if err != nil {
+		return fmt.Errorf("encrypting cookie: %w", err)
+	}
+	nonce := make([]byte, aead.NonceSize(), aead.NonceSize()+len(data)+aead.Overhead())
+	if _, err := rand.Read(nonce); err != nil {
+		return fmt.Errorf("encrypting cookie: %w", err)
+	}
+	sealed := aead.Seal(nonce, nonce, data, []byte(c.Name))
+	c.Value = base64.RawURLEncoding.EncodeToString(sealed)
+	return nil
+}
+
+// Verify returns the original value of c, signed or encrypted with Sign.
+// It returns ErrInvalidCookie if the value was tampered with, or has
+// expired.
+func (s *CookieSigner) Verify(c *http.Cookie) (string, error) {
+	for _, key := range s.Keys {
+		data, ok := s.open(key, c)
+		if !ok {
+			continue
+		}
+		if len(data) < 8 {
+			break
+		}
+		expires := int64(binary.BigEndian.Uint64(data))
+		if expires != 0 && time.Now().Unix() >= expires {
+			break
+		}
+		return string(data[8:]), nil
+	}
+	return "", fmt.Errorf("%w %q", ErrInvalidCookie, c.Name)
+}
+
+// open returns the data in the value of c, if it was signed or encrypted
+// with key.
+func (s *CookieSigner) open(key []byte, c *http.Cookie) ([]byte, bool) {
+	if !s.Encrypt {
+		payload, sig, ok := strings.Cut(c.Value, ".")
+		if !ok || !hmac.Equal([]byte(sig), []byte(signCookie(key, c.Name, payload))) {
+			return nil, false
+		}
+		data, err := base64.RawURLEncoding.DecodeString(payload)
+		return data, err == nil
+	}
+	sealed, err := base64.RawURLEncoding.DecodeString(c.Value)
+	if err != nil {
+		return nil, false
+	}
+	aead, err := cookieAEAD(key)
+	if err != nil || len(sealed) < aead.NonceSize() {
+		return nil, false
+	}
+	nonce, ciphertext := sealed[:aead.NonceSize()], sealed[aead.NonceSize():]
+	data, err := aead.Open(nil, nonce, ciphertext, []byte(c.Name))
+	return data, err == nil
+}
+
+func signCookie(key []byte, name, payload string) string {
+	mac := hmac.New(sha256.New, key)
+	mac.Write([]byte(name))
+	mac.Write([]byte{0})
+	mac.Write([]byte(payload))
+	return base64.RawURLEncoding.EncodeToString(mac.Sum(nil))
+}
+
+// cookieAEAD returns the AES-256-GCM cipher for key, which may have any
+// length, as the AES key is derived from it.
+func cookieAEAD(key []byte) (cipher.AEAD, error) {
+	derived := sha256.Sum256(key)
+	block, err := aes.NewCipher(derived[:])
+	if err != nil {
+		return nil, err
+	}
+	return cipher.NewGCM(block)
+}
+
+// SetSignedCookie adds a Set-Cookie header field for c to the response of
+// w, with its value signed or encrypted by s. c is not modified.
+func SetSignedCookie(w http.ResponseWriter, c *http.Cookie, s *CookieSigner) error {
+	signed := *c
+	if err := s.Sign(&signed); err != nil {
+		return err
+	}
+	return SetCookie(w, &signed)
+}
+
+// ReadSignedCookie returns the original value of the cookie name of req,
+// as set by SetSignedCookie. It returns http.ErrNoCookie if req has no such
+// cookie, and ErrInvalidCookie if its value cannot be verified by s.
+func ReadSignedCookie(req *http.Request, name string, s *CookieSigner) (string, error) {
+	c, err := req.Cookie(name)
+	if err != nil {
+		return "", err
+	}
+	return s.Verify(c)
+}
@@ -0,0 +1,151 @@
+// Copyright 2026 Franklin "Snaipe" Mathieu.
+//
+// Use of this source code is governed by the MIT license that can be
+// found in the LICENSE file.
+
+package htutil
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestValidateCookie(t *testing.T) {
+	t.Parallel()
+
+	tcases := []struct {
+		Cookie http.Cookie
+		Err    bool
+	}{
+		{Cookie: http.Cookie{Name: "id", Value: "1"}},
+		{Cookie: http.Cookie{Name: "bad name", Value: "1"}, Err: true},
+		{Cookie: http.Cookie{Name: "__Secure-id", Value: "1", Secure: true}},
+		{Cookie: http.Cookie{Name: "__Secure-id", Value: "1"}, Err: true},
+		{Cookie: http.Cookie{Name: "__Host-id", Value: "1", Secure: true, Path: "/"}},
+		{Cookie: http.Cookie{Name: "__Host-id", Value: "1", Path: "/"}, Err: true},
+		{Cookie: http.Cookie{Name: "__Host-id", Value: "1", Secure: true}, Err: true},
+		{Cookie: http.Cookie{Name: "__Host-id", Value: "1", Secure: true, Path: "/", Domain: "example.com"}, Err: true},
+		{Cookie: http.Cookie{Name: "id", Value: "1", SameSite: http.SameSiteNoneMode, Secure: true}},
+		{Cookie: http.Cookie{Name: "id", Value: "1", SameSite: http.SameSiteNoneMode}, Err: true},
+	}
+
+	for i, tcase := range tcases {
+		t.Run(fmt.Sprintf("%d", i), func(t *testing.T) {
+			err := ValidateCookie(&tcase.Cookie)
+			if (err != nil) != tcase.Err {
+				t.Fatalf("expected error %v, got %v", tcase.Err, err)
+			}
+
+			w := httptest.NewRecorder()
+			err = SetCookie(w, &tcase.Cookie)
+			if (err != nil) != tcase.Err || (w.Header().Get("Set-Cookie") == "") != tcase.Err {
+				t.Fatalf("expected error %v, got %v (Set-Cookie: %q)", tcase.Err, err, w.Header().Get("Set-Cookie"))
+			}
+		})
+	}
+}
+
+func TestCookieSigner(t *testing.T) {
+	t.Parallel()
+
+	oldKey, newKey := []byte("old secret"), []byte("new secret")
+
+	tcases := []struct {
+		Encrypt    bool
+		SignKeys   [][]byte
+		VerifyKeys [][]byte
+		MaxAge     int
+		Expires    time.Time
+		Rename     string
+		Tamper     bool
+		Err        bool
+	}{
+		{SignKeys: [][]byte{oldKey}, VerifyKeys: [][]byte{oldKey}},
+		{Encrypt: true, SignKeys: [][]byte{oldKey}, VerifyKeys: [][]byte{oldKey}},
+		{SignKeys: [][]byte{oldKey}, VerifyKeys: [][]byte{newKey, oldKey}},
+		{Encrypt: true, SignKeys: [][]byte{oldKey}, VerifyKeys: [][]byte{newKey, oldKey}},
+		{SignKeys: [][]byte{oldKey}, VerifyKeys: [][]byte{newKey}, Err: true},
+		{Encrypt: true, SignKeys: [][]byte{oldKey}, VerifyKeys: [][]byte{newKey}, Err: true},
+		{SignKeys: [][]byte{oldKey}, VerifyKeys: [][]byte{oldKey}, Rename: "other", Err: true},
+		{Encrypt: true, SignKeys: [][]byte{oldKey}, VerifyKeys: [][]byte{oldKey}, Rename: "other", Err: true},
+		{SignKeys: [][]byte{oldKey}, VerifyKeys: [][]byte{oldKey}, Tamper: true, Err: true},
+		{Encrypt: true, SignKeys: [][]byte{oldKey}, VerifyKeys: [][]byte{oldKey}, Tamper: true, Err: true},
+		{SignKeys: [][]byte{oldKey}, VerifyKeys: [][]byte{oldKey}, MaxAge: 3600},
+		{SignKeys: [][]byte{oldKey}, VerifyKeys: [][]byte{oldKey}, Expires: time.Now().Add(time.Hour)},
+		{SignKeys: [][]byte{oldKey}, VerifyKeys: [][]byte{oldKey}, Expires: time.Now().Add(-time.Hour), Err: true},
+		{Encrypt: true, SignKeys: [][]byte{oldKey}, VerifyKeys: [][]byte{oldKey}, Expires: time.Now().Add(-time.Hour), Err: true},
+	}
+
+	for i, tcase := range tcases {
+		t.Run(fmt.Sprintf("%d", i), func(t *testing.T) {
+			const value = `user=42; "admin"`
+			c := &http.Cookie{Name: "id", Value: value, MaxAge: tcase.MaxAge, Expires: tcase.Expires}
+			signer := &CookieSigner{Keys: tcase.SignKeys, Encrypt: tcase.Encrypt}
+			if err := signer.Sign(c); err != nil {
+				t.Fatal(err)
+			}
+			if err := c.Valid(); err != nil {
+				t.Fatalf("signed cookie is invalid: %v", err)
+			}
+			if tcase.Encrypt && strings.Contains(c.Value, "user") {
+				t.Fatalf("encrypted value %q leaks its plaintext", c.Value)
+			}
+			if tcase.Rename != "" {
+				c.Name = tcase.Rename
+			}
+			if tcase.Tamper {
+				b := []byte(c.Value)
+				b[len(b)/2] ^= 1
+				c.Value = string(b)
+			}
+
+			verifier := &CookieSigner{Keys: tcase.VerifyKeys, Encrypt: tcase.Encrypt}
+			got, err := verifier.Verify(c)
+			if (err != nil) != tcase.Err {
+				t.Fatalf("expected error %v, got %v", tcase.Err, err)
+			}
+			if err != nil && !errors.Is(err, ErrInvalidCookie) {
+				t.Fatalf("expected ErrInvalidCookie, got %v", err)
+			}
+			if !tcase.Err && got != value {
+				t.Fatalf("expected %q, got %q", value, got)
+			}
+		})
+	}
+}
+
+func TestSignedCookieRoundTrip(t *testing.T) {
+	t.Parallel()
+
+	signer := &CookieSigner{Keys: [][]byte{[]byte("secret")}, Encrypt: true}
+	c := &http.Cookie{Name: "__Host-prefs", Value: "dark", Path: "/", Secure: true, HttpOnly: true}
+
+	w := httptest.NewRecorder()
+	if err := SetSignedCookie(w, c, signer); err != nil {
+		t.Fatal(err)
+	}
+	if c.Value != "dark" {
+		t.Fatalf("expected cookie to be left untouched, got value %q", c.Value)
+	}
+
+	req := httptest.NewRequest("GET", "/", nil)
+	for _, cookie := range w.Result().Cookies() {
+		req.AddCookie(cookie)
+	}
+	if v, err := ReadSignedCookie(req, "__Host-prefs", signer); err != nil || v != "dark" {
+		t.Fatalf("expected %q, got %q (error: %v)", "dark", v, err)
+	}
+	if _, err := ReadSignedCookie(req, "missing", signer); !errors.Is(err, http.ErrNoCookie) {
+		t.Fatalf("expected http.ErrNoCookie, got %v", err)
+	}
+
+	w = httptest.NewRecorder()
+	if err := SetSignedCookie(w, &http.Cookie{Name: "__Host-prefs", Value: "dark"}, signer); err == nil {
+		t.Fatal("expected an error for an insecure __Host- cookie")
+	}
+}
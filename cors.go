@@ -0,0 +1,160 @@
+// Copyright 2026 Franklin "Snaipe" Mathieu.
+//
+// Use of this source code is governed by the MIT license that can be
+// found in the LICENSE file.
+
+package htutil
+
+import (
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// CORS is a Cross-Origin Resource Sharing policy, as per the Fetch
+// standard, which lets the documents of other origins access resources.
+type CORS struct {
+	// Policy decides which origins may access resources. A nil policy
+	// allows no origin.
+	Policy *OriginPolicy
+
+	// AllowMethods lists the methods that cross-origin requests may use,
+	// besides the CORS-safelisted GET, HEAD, and POST. "*" allows any
+	// method.
+	AllowMethods []string
+
+	// AllowHeaders lists the header fields that cross-origin requests may
+	// carry, besides the CORS-safelisted ones. "*" allows any header
+	// field but Authorization.
+	AllowHeaders []string
+
+	// ExposeHeaders lists the response header fields that the documents of
+	// allowed origins may read, besides the CORS-safelisted ones. "*"
+	// exposes all of them.
+	ExposeHeaders []string
+
+	// AllowCredentials lets cross-origin requests include credentials, such
+	// as cookies, and the documents of allowed origins read the responses.
+	// Allowed origins are then always echoed, and "*" has no special
+	// meaning in AllowMethods, AllowHeaders, and ExposeHeaders, as required
+	// by the Fetch standard.
+	AllowCredentials bool
+
+	// MaxAge is how long the results of preflight requests may be cached.
+	// Zero leaves it up to the client.
+	MaxAge time.Duration
+}
+
+// Middleware returns a middleware applying the CORS policy to the requests
+// served by next.
+//
+// Preflight requests are answered directly, with 204 No Content if the
+// origin, method, and header fields are allowed, or a 403 Forbidden
+// problem otherwise. Other requests are passed to next, and their
+// responses get the Access-Control-* header fields of allowed origins.
+// Origin is added to the Vary header field whenever the response depends
+// on it; header fields set by next are merged rather than replaced.
+func (c *CORS) Middleware(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+		if req.Method == http.MethodOptions && len(req.Header.Values("Origin")) > 0 && req.Header.Get("Access-Control-Request-Method") != "" {
+			c.preflight(w, req)
+			return
+		}
+
+		if !c.wildcard() {
+			AddVary(w.Header(), "Origin")
+		}
+		if o, ok := RequestOrigin(req); ok && c.allows(o) {
+			c.setAllowOrigin(w.Header(), o)
+			if len(c.ExposeHeaders) > 0 {
+				MergeHeaders(w.Header(), http.Header{
+					"Access-Control-Expose-Headers": {strings.Join(c.ExposeHeaders, ", ")},
+				}, MergeKeep)
+			}
+		}
+		next.ServeHTTP(w, req)
+	})
+}
+
+func (c *CORS) preflight(w http.ResponseWriter, req *http.Request) {
+	AddVary(w.Header(), "Origin", "Access-Control-Request-Method", "Access-Control-Request-Headers")
+
+	o, ok := RequestOrigin(req)
+	if !ok || !c.allows(o) {
+		RespondError(w, req, NewProblem(http.StatusForbidden, "The origin is not allowed to access the resource."))
+		return
+	}
+	method := req.Header.Get("Access-Control-Request-Method")
+	if !c.allowsMethod(method) {
+		RespondError(w, req, NewProblem(http.StatusForbidden, "The method is not allowed for cross-origin requests."))
+		return
+	}
+	var headers []string
+	for _, v := range req.Header.Values("Access-Control-Request-Headers") {
+		for _, name := range SplitList(v) {
+			if !c.allowsHeader(name) {
+				RespondError(w, req, NewProblem(http.StatusForbidden, "The header field "+name+" is not allowed for cross-origin requests."))
+				return
+			}
+			headers = append(headers, strings.ToLower(name))
+		}
+	}
+
+	c.setAllowOrigin(w.Header(), o)
+	if len(c.AllowMethods) > 0 {
+		w.Header().Set("Access-Control-Allow-Methods", strings.Join(c.AllowMethods, ", "))
+	}
+	if len(headers) > 0 {
+		w.Header().Set("Access-Control-Allow-Headers", strings.Join(headers, ", "))
+	}
+	if c.MaxAge > 0 {
+		w.Header().Set("Access-Control-Max-Age", strconv.FormatInt(int64(c.MaxAge/time.Second), 10))
+	}
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// wildcard returns whether all origins get the same "*" response.
+func (c *CORS) wildcard() bool {
+	return c.Policy != nil && c.Policy.AllowsAny() && !c.Policy.AllowNull && !c.AllowCredentials
+}
+
+func (c *CORS) allows(o Origin) bool {
+	return c.Policy != nil && c.Policy.Allows(o)
+}
+
+func (c *CORS) setAllowOrigin(h http.Header, o Origin) {
+	if c.wildcard() {
+		h.Set("Access-Control-Allow-Origin", "*")
+	} else {
+		h.Set("Access-Control-Allow-Origin", o.String())
+	}
+	if c.AllowCredentials {
+		h.Set("Access-Control-Allow-Credentials", "true")
+	}
+}
+
+func (c *CORS) allowsMethod(method string) bool {
+	switch method {
+	case http.MethodGet, http.MethodHead, http.MethodPost:
+		return true
+	}
+	for _, m := range c.AllowMethods {
+		if m == method || (m == "*" && !c.AllowCredentials) {
+			return true
+		}
+	}
+	return false
+}
+
+func (c *CORS) allowsHeader(name string) bool {
+	for _, h := range c.AllowHeaders {
+		if strings.EqualFold(h, name) {
+			return true
+		}
+		if h == "*" && !c.AllowCredentials && !strings.EqualFold(name, "Authorization") {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,183 @@
+// Copyright 2026 Franklin "Snaipe" Mathieu.
+//
+// Use of this source code is governed by the MIT license that can be
+// found in the LICENSE file.
+
+package htutil
+
+import (
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+)
+
+func TestCORS(t *testing.T) {
+	t.Parallel()
+
+	restricted := &CORS{
+		Policy:           &OriginPolicy{Origins: []string{"https://example.com", "https://*.example.org"}},
+		AllowMethods:     []string{"PUT", "DELETE"},
+		AllowHeaders:     []string{"Content-Type", "X-Token"},
+		ExposeHeaders:    []string{"ETag"},
+		AllowCredentials: true,
+		MaxAge:           10 * time.Minute,
+	}
+	public := &CORS{
+		Policy:       &OriginPolicy{Origins: []string{"*"}},
+		AllowMethods: []string{"*"},
+		AllowHeaders: []string{"*"},
+	}
+
+	tcases := []struct {
+		CORS     *CORS
+		Method   string
+		Header   http.Header
+		Status   int
+		Expected http.Header
+	}{
+		{
+			CORS:   restricted,
+			Header: http.Header{"Origin": {"https://example.com"}},
+			Status: 200,
+			Expected: http.Header{
+				"Access-Control-Allow-Origin":      {"https://example.com"},
+				"Access-Control-Allow-Credentials": {"true"},
+				"Access-Control-Expose-Headers":    {"ETag, X-Handler"},
+				"Vary":                             {"Origin", "Accept"},
+			},
+		},
+		{
+			CORS:     restricted,
+			Header:   http.Header{"Origin": {"https://evil.test"}},
+			Status:   200,
+			Expected: http.Header{"Access-Control-Expose-Headers": {"X-Handler"}, "Vary": {"Origin", "Accept"}},
+		},
+		{
+			CORS:     restricted,
+			Status:   200,
+			Expected: http.Header{"Access-Control-Expose-Headers": {"X-Handler"}, "Vary": {"Origin", "Accept"}},
+		},
+		{
+			CORS:   restricted,
+			Method: "OPTIONS",
+			Header: http.Header{
+				"Origin":                         {"https://api.example.org"},
+				"Access-Control-Request-Method":  {"PUT"},
+				"Access-Control-Request-Headers": {"content-type,X-Token"},
+			},
+			Status: 204,
+			Expected: http.Header{
+				"Access-Control-Allow-Origin":      {"https://api.example.org"},
+				"Access-Control-Allow-Credentials": {"true"},
+				"Access-Control-Allow-Methods":     {"PUT, DELETE"},
+				"Access-Control-Allow-Headers":     {"content-type, x-token"},
+				"Access-Control-Max-Age":           {"600"},
+				"Vary":                             {"Origin, Access-Control-Request-Method, Access-Control-Request-Headers"},
+			},
+		},
+		{
+			CORS:   restricted,
+			Method: "OPTIONS",
+			Header: http.Header{"Origin": {"https://example.com"}, "Access-Control-Request-Method": {"PATCH"}},
+			Status: 403,
+		},
+		{
+			CORS:   restricted,
+			Method: "OPTIONS",
+			Header: http.Header{"Origin": {"https://example.com"}, "Access-Control-Request-Method": {"GET"}, "Access-Control-Request-Headers": {"x-other"}},
+			Status: 403,
+		},
+		{
+			CORS:   restricted,
+			Method: "OPTIONS",
+			Header: http.Header{"Origin": {"https://evil.test"}, "Access-Control-Request-Method": {"GET"}},
+			Status: 403,
+		},
+		{
+			// OPTIONS requests without Access-Control-Request-Method are
+			// not preflights.
+			CORS:   restricted,
+			Method: "OPTIONS",
+			Header: http.Header{"Origin": {"https://example.com"}},
+			Status: 200,
+		},
+		{
+			CORS:   public,
+			Header: http.Header{"Origin": {"https://any.test"}},
+			Status: 200,
+			Expected: http.Header{
+				"Access-Control-Allow-Origin":      {"*"},
+				"Access-Control-Allow-Credentials": nil,
+				"Vary":                             {"Accept"},
+			},
+		},
+		{
+			CORS:   public,
+			Method: "OPTIONS",
+			Header: http.Header{
+				"Origin":                         {"https://any.test"},
+				"Access-Control-Request-Method":  {"PATCH"},
+				"Access-Control-Request-Headers": {"x-anything"},
+			},
+			Status: 204,
+			Expected: http.Header{
+				"Access-Control-Allow-Origin":  {"*"},
+				"Access-Control-Allow-Methods": {"*"},
+				"Access-Control-Allow-Headers": {"x-anything"},
+				"Access-Control-Max-Age":       nil,
+			},
+		},
+		{
+			CORS:   public,
+			Method: "OPTIONS",
+			Header: http.Header{
+				"Origin":                         {"https://any.test"},
+				"Access-Control-Request-Method":  {"GET"},
+				"Access-Control-Request-Headers": {"authorization"},
+			},
+			Status: 403,
+		},
+		{
+			CORS:   &CORS{},
+			Header: http.Header{"Origin": {"https://example.com"}},
+			Status: 200,
+			Expected: http.Header{
+				"Access-Control-Allow-Origin": nil,
+				"Vary":                        {"Origin", "Accept"},
+			},
+		},
+	}
+
+	for i, tcase := range tcases {
+		t.Run(fmt.Sprintf("%d", i), func(t *testing.T) {
+			handler := tcase.CORS.Middleware(http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+				AddVary(w.Header(), "Accept")
+				MergeHeaders(w.Header(), http.Header{"Access-Control-Expose-Headers": {"X-Handler"}}, MergeKeep)
+			}))
+
+			method := tcase.Method
+			if method == "" {
+				method = "GET"
+			}
+			req := httptest.NewRequest(method, "/", nil)
+			for k, vs := range tcase.Header {
+				for _, v := range vs {
+					req.Header.Add(k, v)
+				}
+			}
+			rw := httptest.NewRecorder()
+			handler.ServeHTTP(rw, req)
+
+			if rw.Code != tcase.Status {
+				t.Fatalf("expected status %d, got %d", tcase.Status, rw.Code)
+			}
+			for k, expected := range tcase.Expected {
+				if actual := rw.Header().Values(k); fmt.Sprint(actual) != fmt.Sprint(expected) {
+					t.Fatalf("expected %s %q, got %q", k, expected, actual)
+				}
+			}
+		})
+	}
+}
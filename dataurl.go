@@ -0,0 +1,187 @@
+// Copyright 2026 Franklin "Snaipe" Mathieu.
+//
+// Use of this source code is governed by the MIT license that can be
+// found in the LICENSE file.
+
+package htutil
+
+import (
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"net/url"
+	"strings"
+)
+
+// ErrDataURLTooLarge is returned when the payload of a data URL is larger
+// than the limit.
+var ErrDataURLTooLarge = errors.New("data url payload too large")
+
+// defaultDataMediaType is the media type of data URLs that do not specify
+// one, as per RFC 2397 §2.
+func defaultDataMediaType() MediaType {
+	return MediaType{Type: "text", Subtype: "plain", Params: map[string]string{"charset": "US-ASCII"}}
+}
+
+// ParseDataURL parses a data URL, as per RFC 2397, and returns its media
+// type and decoded payload. A negative limit defaults to
+// DefaultMaxBodySize; payloads larger than limit fail with
+// ErrDataURLTooLarge, without being decoded in full.
+//
+// The media type defaults to text/plain;charset=US-ASCII, or text/plain
+// with the parameters of the URL if only these are present. The payload
+// is base64-encoded if the media type is followed by ";base64", where
+// whitespace and missing padding are tolerated, and percent-encoded
+// otherwise. The fragment, if any, is ignored.
+func ParseDataURL(s string, limit int64) (MediaType, []byte, error) {
+	if limit < 0 {
+		limit = DefaultMaxBodySize
+	}
+	const scheme = "data:"
+	if len(s) < len(scheme) || !strings.EqualFold(s[:len(scheme)], scheme) {
+		return MediaType{}, nil, fmt.Errorf("parsing data url: scheme is not data")
+	}
+	s, _, _ = strings.Cut(s[len(scheme):], "#")
+	header, payload, ok := strings.Cut(s, ",")
+	if !ok {
+		return MediaType{}, nil, fmt.Errorf("parsing data url: missing comma before the payload")
+	}
+
+	var isBase64 bool
+	header = trimASCIISpace(header)
+	if i := strings.LastIndexByte(header, ';'); i != -1 && strings.EqualFold(trimASCIISpace(header[i+1:]), "base64") {
+		header, isBase64 = header[:i], true
+	}
+	mt, err := parseDataMediaType(header)
+	if err != nil {
+		return MediaType{}, nil, err
+	}
+
+	// Each decoded byte takes at least one character in base64, and at
+	// most three percent-encoded, which bounds the payload size before
+	// decoding it.
+	if int64(len(payload)) > 3*limit {
+		return MediaType{}, nil, fmt.Errorf("parsing data url: %w", ErrDataURLTooLarge)
+	}
+	data, err := url.PathUnescape(payload)
+	if err != nil {
+		return MediaType{}, nil, fmt.Errorf("parsing data url: %w", err)
+	}
+	var decoded []byte
+	if isBase64 {
+		if decoded, err = decodeForgivingBase64(data); err != nil {
+			return MediaType{}, nil, fmt.Errorf("parsing data url: %w", err)
+		}
+	} else {
+		decoded = []byte(data)
+	}
+	if int64(len(decoded)) > limit {
+		return MediaType{}, nil, fmt.Errorf("parsing data url: %w", ErrDataURLTooLarge)
+	}
+	return mt, decoded, nil
+}
+
+// parseDataMediaType parses the percent-encoded media type of a data URL.
+func parseDataMediaType(header string) (MediaType, error) {
+	value, err := url.PathUnescape(header)
+	if err != nil {
+		return MediaType{}, fmt.Errorf("parsing data url: %w", err)
+	}
+	value = trimASCIISpace(value)
+	switch {
+	case value == "":
+		return defaultDataMediaType(), nil
+	case value[0] == ';':
+		value = "text/plain" + value
+	}
+	mt, err := ParseMediaType(value)
+	if err != nil {
+		return MediaType{}, fmt.Errorf("parsing data url: %w", err)
+	}
+	if mt.Type == "*" || mt.Subtype == "*" {
+		return MediaType{}, fmt.Errorf("parsing data url: %s is a media range", mt.Essence())
+	}
+	return mt, nil
+}
+
+// decodeForgivingBase64 decodes base64 with optional padding, ignoring
+// ASCII whitespace, like browsers do for data URLs.
+func decodeForgivingBase64(s string) ([]byte, error) {
+	s = strings.Map(func(r rune) rune {
+		if r < 0x80 && isASCIISpace(byte(r)) {
+			return -1
+		}
+		return r
+	}, s)
+	if len(s)%4 == 0 {
+		s = strings.TrimSuffix(s, "=")
+		s = strings.TrimSuffix(s, "=")
+	}
+	return base64.RawStdEncoding.DecodeString(s)
+}
+
+// FormatDataURL returns a data URL with the media type mt and the payload
+// data, as per RFC 2397. The payload is percent-encoded if that is shorter
+// than base64, like for most text, and base64-encoded otherwise. The media
+// type is omitted if mt is zero, which stands for
+// text/plain;charset=US-ASCII.
+func FormatDataURL(mt MediaType, data []byte) string {
+	var out strings.Builder
+	out.WriteString("data:")
+	if mt.Type != "" {
+		out.WriteString(escapeDataURL(mt.String()))
+	}
+
+	escaped := 0
+	for _, c := range data {
+		if !isDataURLChar(c) {
+			escaped++
+		}
+	}
+	if len(data)+2*escaped <= base64.StdEncoding.EncodedLen(len(data))+len(";base64") {
+		out.WriteByte(',')
+		out.WriteString(escapeDataURL(string(data)))
+	} else {
+		out.WriteString(";base64,")
+		out.WriteString(base64.StdEncoding.EncodeToString(data))
+	}
+	return out.String()
+}
+
+// isDataURLChar reports whether c may appear unescaped in a data URL. This
+// excludes the comma separating the payload, and the delimiters of the
+// query and fragment.
+func isDataURLChar(c byte) bool {
+	switch {
+	case 'a' <= c && c <= 'z', 'A' <= c && c <= 'Z', '0' <= c && c <= '9':
+		return true
+	}
+	return strings.IndexByte("-._~!$&'()*+;=:@/", c) != -1
+}
+
+func escapeDataURL(s string) string {
+	const hex = "0123456789ABCDEF"
+	var out strings.Builder
+	out.Grow(len(s))
+	for i := 0; i < len(s); i++ {
+		if c := s[i]; isDataURLChar(c) {
+			out.WriteByte(c)
+		} else {
+			out.WriteByte('%')
+			out.WriteByte(hex[c>>4])
+			out.WriteByte(hex[c&0xf])
+		}
+	}
+	return out.String()
+}
+
+// Data returns the media type and decoded payload of u if its scheme is
+// data; see ParseDataURL.
+func (u URL) Data(limit int64) (MediaType, []byte, error) {
+	if u.URL == nil || !strings.EqualFold(u.Scheme, "data") {
+		return MediaType{}, nil, fmt.Errorf("parsing data url: scheme is not data")
+	}
+	data := *u.URL
+	data.Fragment, data.RawFragment = "", ""
+	return ParseDataURL(data.String(), limit)
+}
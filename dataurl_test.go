@@ -0,0 +1,156 @@
+// Copyright 2026 Franklin "Snaipe" Mathieu.
+//
+// Use of this source code is governed by the MIT license that can be
+// found in the LICENSE file.
+
+package htutil
+
+import (
+	"errors"
+	"fmt"
+	"net/url"
+	"testing"
+)
+
+func TestParseDataURL(t *testing.T) {
+	t.Parallel()
+
+	tcases := []struct {
+		In        string
+		Limit     int64
+		MediaType string
+		Data      string
+		Err       error
+		Bad       bool
+	}{
+		{In: "data:,Hello%2C%20World%21", Limit: -1, MediaType: "text/plain;charset=US-ASCII", Data: "Hello, World!"},
+		{In: "data:text/plain;base64,SGVsbG8sIFdvcmxkIQ==", Limit: -1, MediaType: "text/plain", Data: "Hello, World!"},
+		{In: "DATA:Text/HTML;Charset=UTF-8,%3Ch1%3Ehi%3C%2Fh1%3E", Limit: -1, MediaType: "text/html;charset=UTF-8", Data: "<h1>hi</h1>"},
+		{In: "data:;charset=utf-8,caf%C3%A9", Limit: -1, MediaType: "text/plain;charset=utf-8", Data: "café"},
+		{In: "data:image/png ; BASE64 ,iVBO Rw0K\nGgo", Limit: -1, MediaType: "image/png", Data: "\x89PNG\r\n\x1a\n"},
+		{In: "data:text/plain;base64,SGk", Limit: -1, MediaType: "text/plain", Data: "Hi"},
+		{In: "data:text/plain;base64,SGk%3D", Limit: -1, MediaType: "text/plain", Data: "Hi"},
+		{In: "data:text/plain;title=%22a%20b%22,x#fragment", Limit: -1, MediaType: `text/plain;title="a b"`, Data: "x"},
+		{In: "data:,a,b?c", Limit: -1, MediaType: "text/plain;charset=US-ASCII", Data: "a,b?c"},
+		{In: "data:,", Limit: 0, MediaType: "text/plain;charset=US-ASCII", Data: ""},
+		{In: "data:,abcd", Limit: 3, Err: ErrDataURLTooLarge},
+		{In: "data:;base64,YWJjZA==", Limit: 3, Err: ErrDataURLTooLarge},
+		{In: "data:," + string(make([]byte, 10)), Limit: 3, Err: ErrDataURLTooLarge},
+		{In: "data:text/plain", Limit: -1, Bad: true},
+		{In: "http://example.com/", Limit: -1, Bad: true},
+		{In: "data:text/*,x", Limit: -1, Bad: true},
+		{In: "data:text,x", Limit: -1, Bad: true},
+		{In: "data:,%zz", Limit: -1, Bad: true},
+		{In: "data:;base64,S", Limit: -1, Bad: true},
+		{In: "data:;base64,S$==", Limit: -1, Bad: true},
+	}
+
+	for i, tcase := range tcases {
+		t.Run(fmt.Sprintf("%d", i), func(t *testing.T) {
+			mt, data, err := ParseDataURL(tcase.In, tcase.Limit)
+			if tcase.Err != nil && !errors.Is(err, tcase.Err) {
+				t.Fatalf("expected error %v, got %v", tcase.Err, err)
+			}
+			if tcase.Err == nil && (err != nil) != tcase.Bad {
+				t.Fatalf("expected error=%v, got %v", tcase.Bad, err)
+			}
+			if err != nil {
+				return
+			}
+			if mt.String() != tcase.MediaType {
+				t.Fatalf("expected media type %s, got %s", tcase.MediaType, mt)
+			}
+			if string(data) != tcase.Data {
+				t.Fatalf("expected %q, got %q", tcase.Data, data)
+			}
+		})
+	}
+}
+
+func TestFormatDataURL(t *testing.T) {
+	t.Parallel()
+
+	tcases := []struct {
+		MediaType MediaType
+		Data      string
+		Expect    string
+	}{
+		{Data: "Hello, World!", Expect: "data:,Hello%2C%20World!"},
+		{
+			MediaType: MediaType{Type: "text", Subtype: "plain", Params: map[string]string{"charset": "utf-8"}},
+			Data:      "a/b",
+			Expect:    "data:text/plain;charset=utf-8,a/b",
+		},
+		{
+			MediaType: MediaType{Type: "image", Subtype: "png"},
+			Data:      "\x00\x01\x02\x03\x04\x05\x06\x07\x08\x09\x0a\x0b\x0c\x0d\x0e\x0f",
+			Expect:    "data:image/png;base64,AAECAwQFBgcICQoLDA0ODw==",
+		},
+		{
+			MediaType: MediaType{Type: "image", Subtype: "png"},
+			Data:      "\x89PNG\r\n\x1a\n",
+			Expect:    "data:image/png,%89PNG%0D%0A%1A%0A",
+		},
+		{
+			MediaType: MediaType{Type: "text", Subtype: "plain", Params: map[string]string{"title": "a, b"}},
+			Data:      "#?%",
+			Expect:    "data:text/plain;title=%22a%2C%20b%22,%23%3F%25",
+		},
+		{Data: "", Expect: "data:,"},
+	}
+
+	for i, tcase := range tcases {
+		t.Run(fmt.Sprintf("%d", i), func(t *testing.T) {
+			got := FormatDataURL(tcase.MediaType, []byte(tcase.Data))
+			if got != tcase.Expect {
+				t.Fatalf("expected %s, got %s", tcase.Expect, got)
+			}
+
+			mt, data, err := ParseDataURL(got, -1)
+			if err != nil {
+				t.Fatalf("expected %s to parse, got %v", got, err)
+			}
+			expected := tcase.MediaType
+			if expected.Type == "" {
+				expected = defaultDataMediaType()
+			}
+			if mt.String() != expected.String() || string(data) != tcase.Data {
+				t.Fatalf("expected %s %q, got %s %q", expected, tcase.Data, mt, data)
+			}
+		})
+	}
+}
+
+func TestURLData(t *testing.T) {
+	t.Parallel()
+
+	tcases := []struct {
+		In   string
+		Data string
+		Err  bool
+	}{
+		{In: "data:text/plain;base64,SGVsbG8=#frag", Data: "Hello"},
+		{In: "data:,a%20b?c", Data: "a b?c"},
+		{In: "https://example.com/data:,x", Err: true},
+	}
+
+	for i, tcase := range tcases {
+		t.Run(fmt.Sprintf("%d", i), func(t *testing.T) {
+			u, err := url.Parse(tcase.In)
+			if err != nil {
+				t.Fatal(err)
+			}
+			_, data, err := URL{u}.Data(-1)
+			if (err != nil) != tcase.Err {
+				t.Fatalf("expected error=%v, got %v", tcase.Err, err)
+			}
+			if string(data) != tcase.Data {
+				t.Fatalf("expected %q, got %q", tcase.Data, data)
+			}
+		})
+	}
+
+	if _, _, err := (URL{}).Data(-1); err == nil {
+		t.Fatalf("expected an absent URL to have no data")
+	}
+}
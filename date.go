@@ -0,0 +1,125 @@
+// Copyright 2026 Franklin "Snaipe" Mathieu.
+//
+// Use of this source code is governed by the MIT license that can be
+// found in the LICENSE file.
+
+package htutil
+
+import (
+	"log/slog"
+	"net/http"
+	"sync"
+	"time"
+)
+
+// StampDate sets the Date header field of h to the current time, unless it
+// already has a valid one, and returns the date of h.
+//
+// The net/http server stamps the responses it writes, but responses that
+// are buffered, signed, or serialized by other means need to be stamped
+// beforehand.
+func StampDate(h http.Header) time.Time {
+	if date, err := GetHeader[time.Time](h, "Date"); err == nil {
+		return date
+	}
+	now := time.Now().UTC().Truncate(time.Second)
+	SetHeader(h, "Date", now)
+	return now
+}
+
+// ClockSkew estimates the offset between the local clock and the clock of
+// a server, from the Date of its responses, so that absolute times sent by
+// the server can be interpreted on clients with a bad clock.
+//
+// Estimates are smoothed across responses with an exponential moving
+// average. A ClockSkew is safe for concurrent use.
+type ClockSkew struct {
+	// Alpha is the weight of each new sample in the moving average,
+	// between 0 and 1. Defaults to 0.2.
+	Alpha float64
+
+	mu      sync.Mutex
+	skew    float64
+	sampled bool
+}
+
+// Observe adds a sample from a response with the specified Date, to a
+// request sent at requestTime, and received at responseTime.
+func (s *ClockSkew) Observe(date, requestTime, responseTime time.Time) {
+	// The server generated Date somewhere between the request and the
+	// response, and truncated it to the second; assume the midpoints.
+	local := requestTime.Add(responseTime.Sub(requestTime) / 2)
+	sample := float64(date.Add(500 * time.Millisecond).Sub(local))
+
+	alpha := s.Alpha
+	if alpha <= 0 || alpha > 1 {
+		alpha = 0.2
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if !s.sampled {
+		s.skew, s.sampled = sample, true
+		return
+	}
+	s.skew = alpha*sample + (1-alpha)*s.skew
+}
+
+// Skew returns the estimated offset of the server clock relative to the
+// local clock; it is positive if the local clock is late. It is zero until
+// a sample has been observed.
+func (s *ClockSkew) Skew() time.Duration {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return time.Duration(s.skew)
+}
+
+// Now returns the estimated current time of the server clock.
+func (s *ClockSkew) Now() time.Time {
+	return time.Now().Add(s.Skew())
+}
+
+// Local converts t, a time of the server clock, like the value of Expires
+// or Last-Modified, to the local clock.
+func (s *ClockSkew) Local(t time.Time) time.Time {
+	return t.Add(-s.Skew())
+}
+
+// RetryAfter returns the delay requested by the Retry-After field of h,
+// which is either a number of seconds, or a date of the server clock. It
+// returns ok=false if h has no valid Retry-After.
+func (s *ClockSkew) RetryAfter(h http.Header) (delay time.Duration, ok bool) {
+	delay, err := ParseRetryAfter(h.Get("Retry-After"), s.Now())
+	return delay, err == nil
+}
+
+// SkewTransport is a http.RoundTripper observing the Date of the responses
+// it receives to estimate the skew of the server clock.
+type SkewTransport struct {
+	// Base is the underlying transport. Defaults to http.DefaultTransport.
+	Base http.RoundTripper
+
+	// Skew is updated with the Date of each response.
+	Skew *ClockSkew
+}
+
+func (t *SkewTransport) base() http.RoundTripper {
+	if t.Base != nil {
+		return t.Base
+	}
+	return http.DefaultTransport
+}
+
+func (t *SkewTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	requestTime := time.Now()
+	resp, err := t.base().RoundTrip(req)
+	if err != nil {
+		return nil, err
+	}
+	if date, err := GetHeader[time.Time](resp.Header, "Date"); err == nil {
+		t.Skew.Observe(date, requestTime, time.Now())
+		ContextLogger(req.Context()).Debug("observed server clock",
+			slog.String("host", req.URL.Host), slog.Duration("skew", t.Skew.Skew()))
+	}
+	return resp, nil
+}
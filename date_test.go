@@ -0,0 +1,127 @@
+// Copyright 2026 Franklin "Snaipe" Mathieu.
+//
+// Use of this source code is governed by the MIT license that can be
+// found in the LICENSE file.
+
+package htutil
+
+import (
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+)
+
+func TestStampDate(t *testing.T) {
+	t.Parallel()
+
+	tcases := []struct {
+		Date    string
+		Stamped bool
+	}{
+		{Date: "Fri, 02 Jan 2026 03:04:05 GMT"},
+		{Date: "", Stamped: true},
+		{Date: "yesterday", Stamped: true},
+	}
+
+	for i, tcase := range tcases {
+		t.Run(fmt.Sprintf("%d", i), func(t *testing.T) {
+			h := http.Header{}
+			if tcase.Date != "" {
+				h.Set("Date", tcase.Date)
+			}
+			before := time.Now().Truncate(time.Second)
+			date := StampDate(h)
+			if v := h.Get("Date"); v != date.Format(http.TimeFormat) {
+				t.Fatalf("expected Date %v, got %v", date.Format(http.TimeFormat), v)
+			}
+			if tcase.Stamped && (date.Before(before) || date.After(time.Now())) {
+				t.Fatalf("expected current date, got %v", date)
+			}
+			if !tcase.Stamped && h.Get("Date") != tcase.Date {
+				t.Fatalf("expected %v, got %v", tcase.Date, h.Get("Date"))
+			}
+		})
+	}
+}
+
+func TestClockSkew(t *testing.T) {
+	t.Parallel()
+
+	local := time.Date(2026, 1, 2, 3, 4, 5, 0, time.UTC)
+
+	var skew ClockSkew
+	if skew.Skew() != 0 {
+		t.Fatalf("expected no skew, got %v", skew.Skew())
+	}
+
+	// The server is 10.5s ahead; its Date is truncated to the second.
+	skew.Observe(local.Add(10*time.Second), local.Add(-time.Second), local.Add(time.Second))
+	if v := skew.Skew(); v != 10500*time.Millisecond {
+		t.Fatalf("expected skew %v, got %v", 10500*time.Millisecond, v)
+	}
+
+	// Outliers are smoothed.
+	skew.Observe(local.Add(60*time.Second), local, local)
+	if v := skew.Skew(); v != 20500*time.Millisecond {
+		t.Fatalf("expected skew %v, got %v", 20500*time.Millisecond, v)
+	}
+
+	if v := skew.Local(local); v != local.Add(-20500*time.Millisecond) {
+		t.Fatalf("expected %v, got %v", local.Add(-20500*time.Millisecond), v)
+	}
+}
+
+func TestClockSkewRetryAfter(t *testing.T) {
+	t.Parallel()
+
+	// The local clock is an hour late.
+	var skew ClockSkew
+	now := time.Now()
+	skew.Observe(now.Add(time.Hour).Truncate(time.Second), now, now)
+
+	tcases := []struct {
+		RetryAfter string
+		Delay      time.Duration
+		OK         bool
+	}{
+		{RetryAfter: "120", Delay: 2 * time.Minute, OK: true},
+		{RetryAfter: now.Add(time.Hour + 5*time.Minute).UTC().Format(http.TimeFormat), Delay: 5 * time.Minute, OK: true},
+		{RetryAfter: now.Add(-time.Minute).UTC().Format(http.TimeFormat), Delay: 0, OK: true},
+		{RetryAfter: "soon"},
+	}
+
+	for i, tcase := range tcases {
+		t.Run(fmt.Sprintf("%d", i), func(t *testing.T) {
+			delay, ok := skew.RetryAfter(http.Header{"Retry-After": {tcase.RetryAfter}})
+			if ok != tcase.OK {
+				t.Fatalf("expected ok %v, got %v", tcase.OK, ok)
+			}
+			if d := delay - tcase.Delay; d < -2*time.Second || d > 2*time.Second {
+				t.Fatalf("expected delay around %v, got %v", tcase.Delay, delay)
+			}
+		})
+	}
+}
+
+func TestSkewTransport(t *testing.T) {
+	t.Parallel()
+
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+		w.Header().Set("Date", time.Now().Add(-time.Hour).UTC().Format(http.TimeFormat))
+	}))
+	defer srv.Close()
+
+	skew := &ClockSkew{}
+	client := &http.Client{Transport: &SkewTransport{Skew: skew}}
+	resp, err := client.Get(srv.URL)
+	if err != nil {
+		t.Fatal(err)
+	}
+	resp.Body.Close()
+
+	if d := skew.Skew() + time.Hour; d < -2*time.Second || d > 2*time.Second {
+		t.Fatalf("expected skew around -1h, got %v", skew.Skew())
+	}
+}
@@ -0,0 +1,175 @@
+// Copyright 2026 Franklin "Snaipe" Mathieu.
+//
+// Use of this source code is governed by the MIT license that can be
+// found in the LICENSE file.
+
+package htutil
+
+import (
+	"fmt"
+	"net/http"
+	"time"
+
+	"snai.pe/go-htutil/sfv"
+)
+
+// FormatDeprecation formats t as a Deprecation header value, as per
+// RFC 9745 §2.1.
+func FormatDeprecation(t time.Time) string {
+	s, _ := sfv.FormatItem(sfv.Item{Value: t})
+	return s
+}
+
+// ParseDeprecation parses a Deprecation header value, and returns the date
+// at which the resource is, or will be, deprecated.
+//
+// For compatibility with earlier drafts of RFC 9745, the boolean structured
+// field "?1" is also accepted, and means that the resource is deprecated
+// as of an unspecified date; in that case, the returned time is zero.
+func ParseDeprecation(value string) (time.Time, error) {
+	item, err := sfv.ParseItem(value)
+	if err != nil {
+		return time.Time{}, fmt.Errorf("parsing deprecation: %w", err)
+	}
+	switch v := item.Value.(type) {
+	case time.Time:
+		return v, nil
+	case bool:
+		if v {
+			return time.Time{}, nil
+		}
+	}
+	return time.Time{}, fmt.Errorf("parsing deprecation: %s is not a date", value)
+}
+
+// FormatSunset formats t as a Sunset header value, as per RFC 8594 §3.
+func FormatSunset(t time.Time) string {
+	return t.UTC().Format(http.TimeFormat)
+}
+
+// ParseSunset parses a Sunset header value, and returns the date at which
+// the resource is expected to become unresponsive.
+func ParseSunset(value string) (time.Time, error) {
+	t, err := http.ParseTime(value)
+	if err != nil {
+		return time.Time{}, fmt.Errorf("parsing sunset: %w", err)
+	}
+	return t, nil
+}
+
+// Deprecation describes the deprecation of a resource.
+type Deprecation struct {
+	// Date is the date at which the resource is, or will be, deprecated.
+	// If zero, the announce date is advertised, or else the time of the
+	// response, as RFC 9745 requires a date.
+	Date time.Time
+
+	// Sunset is the date at which the resource is expected to become
+	// unresponsive. If zero, no sunset date is advertised.
+	Sunset time.Time
+
+	// Policy, if set, is the URL of a human-readable deprecation policy,
+	// advertised with a Link header of relation type "deprecation".
+	Policy string
+
+	// Successor, if set, is the URL of the resource superseding the
+	// deprecated one, advertised with a Link header of relation type
+	// "successor-version", as per RFC 5829.
+	Successor string
+
+	// Announce, if set, is the date from which the deprecation is
+	// advertised; responses to earlier requests are not stamped.
+	Announce time.Time
+
+	// Enforce makes the resource respond 410 Gone to requests made after
+	// the sunset date.
+	Enforce bool
+}
+
+// Stamp sets the Deprecation, Sunset, and Link header fields of h
+// according to d.
+func (d Deprecation) Stamp(h http.Header) {
+	d.stamp(h, time.Now())
+}
+
+func (d Deprecation) stamp(h http.Header, now time.Time) {
+	date := d.Date
+	switch {
+	case !date.IsZero():
+	case !d.Announce.IsZero():
+		date = d.Announce
+	default:
+		date = now
+	}
+	h.Set("Deprecation", FormatDeprecation(date))
+	if !d.Sunset.IsZero() {
+		h.Set("Sunset", FormatSunset(d.Sunset))
+	}
+	if d.Policy != "" {
+		h.Add("Link", Link{Target: d.Policy, Rel: []string{"deprecation"}}.String())
+	}
+	if d.Successor != "" {
+		h.Add("Link", Link{Target: d.Successor, Rel: []string{"successor-version"}}.String())
+	}
+}
+
+// ResponseDeprecation returns the deprecation advertised by the Deprecation,
+// Sunset, and Link header fields of resp, with link targets resolved
+// against the URL of the request that resp answers. ok is false if resp
+// advertises neither a deprecation nor a sunset.
+//
+// Enforce and Announce are never set, as they are not advertised.
+func ResponseDeprecation(resp *http.Response) (d Deprecation, ok bool, err error) {
+	deprecation, sunset := resp.Header.Get("Deprecation"), resp.Header.Get("Sunset")
+	if deprecation == "" && sunset == "" {
+		return Deprecation{}, false, nil
+	}
+	if deprecation != "" {
+		if d.Date, err = ParseDeprecation(deprecation); err != nil {
+			return Deprecation{}, false, err
+		}
+	}
+	if sunset != "" {
+		if d.Sunset, err = ParseSunset(sunset); err != nil {
+			return Deprecation{}, false, err
+		}
+	}
+	links, err := ResponseLinks(resp)
+	if err != nil {
+		return Deprecation{}, false, err
+	}
+	for _, l := range links {
+		switch {
+		case d.Policy == "" && l.HasRel("deprecation"):
+			d.Policy = l.Target
+		case d.Successor == "" && l.HasRel("successor-version"):
+			d.Successor = l.Target
+		}
+	}
+	return d, true, nil
+}
+
+// Deprecate returns a middleware that stamps the Deprecation, Sunset, and
+// Link header fields described by d on all responses, or on the responses
+// to requests made from the announce date.
+//
+// If d.Enforce is set, requests made after the sunset date are rejected
+// with a 410 Gone response, rendered with RespondError.
+func Deprecate(next http.Handler, d Deprecation) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+		now := time.Now()
+		if !d.Announce.IsZero() && now.Before(d.Announce) {
+			next.ServeHTTP(w, req)
+			return
+		}
+		d.stamp(w.Header(), now)
+
+		if !d.Enforce || d.Sunset.IsZero() || now.Before(d.Sunset) {
+			next.ServeHTTP(w, req)
+			return
+		}
+
+		detail := fmt.Sprintf("This resource was sunset on %s.", FormatSunset(d.Sunset))
+		RespondError(w, req, NewProblem(http.StatusGone, detail))
+	})
+}
@@ -0,0 +1,235 @@
+// Copyright 2026 Franklin "Snaipe" Mathieu.
+//
+// Use of this source code is governed by the MIT license that can be
+// found in the LICENSE file.
+
+package htutil
+
+import (
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+)
+
+func TestParseDeprecation(t *testing.T) {
+	t.Parallel()
+
+	tcases := []struct {
+		In  string
+		Out time.Time
+		Err bool
+	}{
+		{In: "@1688169599", Out: time.Unix(1688169599, 0).UTC()},
+		{In: "?1", Out: time.Time{}},
+		{In: "?0", Err: true},
+		{In: "1688169599", Err: true},
+		{In: "Sun, 30 Jun 2023 23:59:59 GMT", Err: true},
+	}
+
+	for i, tcase := range tcases {
+		t.Run(fmt.Sprintf("%d", i), func(t *testing.T) {
+			actual, err := ParseDeprecation(tcase.In)
+			if tcase.Err {
+				if err == nil {
+					t.Fatalf("expected error, got %v", actual)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatal(err)
+			}
+			if !actual.Equal(tcase.Out) {
+				t.Fatalf("expected %v, got %v", tcase.Out, actual)
+			}
+		})
+	}
+
+	date := time.Date(2023, 6, 30, 23, 59, 59, 0, time.UTC)
+	if s := FormatDeprecation(date); s != "@1688169599" {
+		t.Fatalf("expected @1688169599, got %s", s)
+	}
+	if s := FormatSunset(date); s != "Fri, 30 Jun 2023 23:59:59 GMT" {
+		t.Fatalf("expected HTTP-date, got %s", s)
+	}
+	if sunset, err := ParseSunset(FormatSunset(date)); err != nil || !sunset.Equal(date) {
+		t.Fatalf("expected %v, got %v (%v)", date, sunset, err)
+	}
+}
+
+func TestDeprecate(t *testing.T) {
+	t.Parallel()
+
+	ok := http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+		fmt.Fprint(w, "ok")
+	})
+
+	past := time.Now().Add(-time.Hour).Truncate(time.Second)
+	future := time.Now().Add(time.Hour).Truncate(time.Second)
+
+	tcases := []struct {
+		Deprecation Deprecation
+		Accept      string
+		Status      int
+		ContentType string
+	}{
+		{
+			Deprecation: Deprecation{Date: past, Sunset: future, Enforce: true},
+			Status:      http.StatusOK,
+		},
+		{
+			Deprecation: Deprecation{Date: past, Sunset: past},
+			Status:      http.StatusOK,
+		},
+		{
+			Deprecation: Deprecation{Date: past, Sunset: past, Enforce: true},
+			Accept:      "application/json",
+			Status:      http.StatusGone,
+			ContentType: "application/problem+json",
+		},
+		{
+			Deprecation: Deprecation{Date: past, Sunset: past, Enforce: true},
+			Accept:      "text/html, */*;q=0.1",
+			Status:      http.StatusGone,
+			ContentType: "text/plain; charset=utf-8",
+		},
+	}
+
+	for i, tcase := range tcases {
+		t.Run(fmt.Sprintf("%d", i), func(t *testing.T) {
+			req := httptest.NewRequest("GET", "/", nil)
+			req.Header.Set("Accept", tcase.Accept)
+			w := httptest.NewRecorder()
+			Deprecate(ok, tcase.Deprecation).ServeHTTP(w, req)
+
+			if w.Code != tcase.Status {
+				t.Fatalf("expected status %d, got %d", tcase.Status, w.Code)
+			}
+			if tcase.ContentType != "" && w.Header().Get("Content-Type") != tcase.ContentType {
+				t.Fatalf("expected content type %s, got %s", tcase.ContentType, w.Header().Get("Content-Type"))
+			}
+			date, err := ParseDeprecation(w.Header().Get("Deprecation"))
+			if err != nil || !date.Equal(past) {
+				t.Fatalf("expected deprecation date %v, got %v (%v)", past, date, err)
+			}
+			sunset, err := ParseSunset(w.Header().Get("Sunset"))
+			if err != nil || !sunset.Equal(tcase.Deprecation.Sunset) {
+				t.Fatalf("expected sunset date %v, got %v (%v)", tcase.Deprecation.Sunset, sunset, err)
+			}
+		})
+	}
+}
+
+func TestDeprecationStampDate(t *testing.T) {
+	t.Parallel()
+
+	date := time.Date(2026, 1, 2, 3, 4, 5, 0, time.UTC)
+	announce := time.Date(2025, 1, 2, 3, 4, 5, 0, time.UTC)
+
+	tcases := []struct {
+		Deprecation Deprecation
+		Expect      time.Time
+	}{
+		{Deprecation: Deprecation{Date: date, Announce: announce}, Expect: date},
+		{Deprecation: Deprecation{Announce: announce}, Expect: announce},
+		{Deprecation: Deprecation{}, Expect: date},
+	}
+
+	for i, tcase := range tcases {
+		t.Run(fmt.Sprintf("%d", i), func(t *testing.T) {
+			h := http.Header{}
+			tcase.Deprecation.stamp(h, date)
+			if got := h.Get("Deprecation"); got != FormatDeprecation(tcase.Expect) {
+				t.Fatalf("expected %s, got %s", FormatDeprecation(tcase.Expect), got)
+			}
+		})
+	}
+}
+
+func TestDeprecateAnnounce(t *testing.T) {
+	t.Parallel()
+
+	ok := http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+		fmt.Fprint(w, "ok")
+	})
+
+	past := time.Now().Add(-time.Hour).Truncate(time.Second)
+	future := time.Now().Add(time.Hour).Truncate(time.Second)
+
+	tcases := []struct {
+		Announce time.Time
+		Stamped  bool
+	}{
+		{Announce: time.Time{}, Stamped: true},
+		{Announce: past, Stamped: true},
+		{Announce: future, Stamped: false},
+	}
+
+	for i, tcase := range tcases {
+		t.Run(fmt.Sprintf("%d", i), func(t *testing.T) {
+			d := Deprecation{Date: future, Successor: "/v2/items", Announce: tcase.Announce}
+			w := httptest.NewRecorder()
+			Deprecate(ok, d).ServeHTTP(w, httptest.NewRequest("GET", "/v1/items", nil))
+
+			if stamped := w.Header().Get("Deprecation") != ""; stamped != tcase.Stamped {
+				t.Fatalf("expected stamped=%v, got %v", tcase.Stamped, stamped)
+			}
+			if stamped := w.Header().Get("Link") != ""; stamped != tcase.Stamped {
+				t.Fatalf("expected successor link stamped=%v, got %v", tcase.Stamped, stamped)
+			}
+		})
+	}
+}
+
+func TestResponseDeprecation(t *testing.T) {
+	t.Parallel()
+
+	date := time.Date(2026, 1, 2, 3, 4, 5, 0, time.UTC)
+	sunset := time.Date(2027, 1, 2, 3, 4, 5, 0, time.UTC)
+
+	stamped := make(http.Header)
+	Deprecation{Date: date, Sunset: sunset, Policy: "/policy", Successor: "/v2/items"}.Stamp(stamped)
+
+	tcases := []struct {
+		Header http.Header
+		Expect Deprecation
+		OK     bool
+		Err    bool
+	}{
+		{
+			Header: stamped,
+			Expect: Deprecation{
+				Date:      date,
+				Sunset:    sunset,
+				Policy:    "https://api.example/policy",
+				Successor: "https://api.example/v2/items",
+			},
+			OK: true,
+		},
+		{Header: http.Header{"Deprecation": {"?1"}}, OK: true},
+		{Header: http.Header{"Sunset": {FormatSunset(sunset)}}, Expect: Deprecation{Sunset: sunset}, OK: true},
+		{Header: http.Header{}},
+		{Header: http.Header{"Deprecation": {"tomorrow"}}, Err: true},
+		{Header: http.Header{"Sunset": {"tomorrow"}}, Err: true},
+	}
+
+	for i, tcase := range tcases {
+		t.Run(fmt.Sprintf("%d", i), func(t *testing.T) {
+			req := httptest.NewRequest("GET", "https://api.example/v1/items", nil)
+			resp := &http.Response{Header: tcase.Header, Request: req}
+
+			d, ok, err := ResponseDeprecation(resp)
+			if (err != nil) != tcase.Err {
+				t.Fatalf("expected error=%v, got %v", tcase.Err, err)
+			}
+			if ok != tcase.OK {
+				t.Fatalf("expected ok=%v, got %v", tcase.OK, ok)
+			}
+			if !d.Date.Equal(tcase.Expect.Date) || !d.Sunset.Equal(tcase.Expect.Sunset) ||
+				d.Policy != tcase.Expect.Policy || d.Successor != tcase.Expect.Successor {
+				t.Fatalf("expected %+v, got %+v", tcase.Expect, d)
+			}
+		})
+	}
+}
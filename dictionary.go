@@ -0,0 +1,141 @@
+// Copyright 2026 Franklin "Snaipe" Mathieu.
+//
+// Use of this source code is governed by the MIT license that can be
+// found in the LICENSE file.
+
+package htutil
+
+import (
+	"crypto/sha256"
+	"fmt"
+	"net/http"
+
+	"snai.pe/go-htutil/sfv"
+)
+
+// This file implements the header fields of Compression Dictionary
+// Transport, as per RFC 9842.
+
+// UseAsDictionary is the value of a Use-As-Dictionary header field, with
+// which a response advertises that clients may use it as a dictionary to
+// decompress subsequent responses.
+type UseAsDictionary struct {
+	// Match is the URL pattern of the requests for which the dictionary
+	// may be used, relative to the URL of the response.
+	Match string
+
+	// MatchDest restricts the dictionary to requests with the specified
+	// request destinations (Sec-Fetch-Dest), like "script" or "document".
+	MatchDest []string
+
+	// ID is an opaque identifier for the dictionary, which clients send
+	// back in Dictionary-ID.
+	ID string
+
+	// Type is the format of the dictionary. Defaults to "raw".
+	Type string
+}
+
+// ParseUseAsDictionary parses a Use-As-Dictionary header value.
+func ParseUseAsDictionary(value string) (UseAsDictionary, error) {
+	dict, err := sfv.ParseDictionary(value)
+	if err != nil {
+		return UseAsDictionary{}, fmt.Errorf("parsing use-as-dictionary: %w", err)
+	}
+	var d UseAsDictionary
+	for _, m := range dict {
+		var ok bool
+		switch m.Key {
+		case "match":
+			d.Match, ok = sfItemValue(m.Member).(string)
+		case "match-dest":
+			var list sfv.InnerList
+			if list, ok = m.Member.(sfv.InnerList); ok {
+				d.MatchDest, err = sfInnerListStrings(list)
+				ok = err == nil
+			}
+		case "id":
+			d.ID, ok = sfItemValue(m.Member).(string)
+		case "type":
+			var tok sfv.Token
+			tok, ok = sfItemValue(m.Member).(sfv.Token)
+			d.Type = string(tok)
+		default:
+			ok = true
+		}
+		if !ok {
+			return UseAsDictionary{}, fmt.Errorf("parsing use-as-dictionary: invalid %s", m.Key)
+		}
+	}
+	if d.Match == "" {
+		return UseAsDictionary{}, fmt.Errorf("parsing use-as-dictionary: missing match")
+	}
+	if d.Type == "" {
+		d.Type = "raw"
+	}
+	return d, nil
+}
+
+func sfItemValue(member sfv.Member) interface{} {
+	item, ok := member.(sfv.Item)
+	if !ok {
+		return nil
+	}
+	return item.Value
+}
+
+// String returns the Use-As-Dictionary header value for d.
+func (d UseAsDictionary) String() string {
+	dict := sfv.Dictionary{{Key: "match", Member: sfv.Item{Value: d.Match}}}
+	if len(d.MatchDest) > 0 {
+		var dests sfv.InnerList
+		for _, dest := range d.MatchDest {
+			dests.Items = append(dests.Items, sfv.Item{Value: dest})
+		}
+		dict = append(dict, sfv.DictMember{Key: "match-dest", Member: dests})
+	}
+	if d.ID != "" {
+		dict = append(dict, sfv.DictMember{Key: "id", Member: sfv.Item{Value: d.ID}})
+	}
+	if d.Type != "" && d.Type != "raw" {
+		dict = append(dict, sfv.DictMember{Key: "type", Member: sfv.Item{Value: sfv.Token(d.Type)}})
+	}
+	s, _ := sfv.FormatDictionary(dict)
+	return s
+}
+
+// SetUseAsDictionary sets the Use-As-Dictionary header field of h to d.
+//
+// Clients store dictionaries in their HTTP cache, so the response must be
+// cacheable, and should remain fresh for as long as the dictionary is
+// meant to be used.
+func SetUseAsDictionary(h http.Header, d UseAsDictionary) {
+	h.Set("Use-As-Dictionary", d.String())
+}
+
+// DictionaryHash returns the SHA-256 hash identifying dict, as sent by
+// clients in Available-Dictionary.
+func DictionaryHash(dict []byte) []byte {
+	sum := sha256.Sum256(dict)
+	return sum[:]
+}
+
+// AvailableDictionary returns the hash of the dictionary advertised in the
+// Available-Dictionary field of h, and its identifier from Dictionary-ID,
+// if any. It returns ok=false if h does not advertise a valid dictionary.
+func AvailableDictionary(h http.Header) (hash []byte, id string, ok bool) {
+	item, err := sfv.ParseItem(h.Get("Available-Dictionary"))
+	if err != nil {
+		return nil, "", false
+	}
+	hash, ok = item.Value.([]byte)
+	if !ok || len(hash) != sha256.Size {
+		return nil, "", false
+	}
+	if v := h.Get("Dictionary-ID"); v != "" {
+		if item, err := sfv.ParseItem(v); err == nil {
+			id, _ = item.Value.(string)
+		}
+	}
+	return hash, id, true
+}
@@ -0,0 +1,96 @@
+// Copyright 2026 Franklin "Snaipe" Mathieu.
+//
+// Use of this source code is governed by the MIT license that can be
+// found in the LICENSE file.
+
+package htutil
+
+import (
+	"encoding/base64"
+	"fmt"
+	"net/http"
+	"reflect"
+	"testing"
+)
+
+func TestUseAsDictionary(t *testing.T) {
+	t.Parallel()
+
+	tcases := []struct {
+		Value      string
+		Dictionary UseAsDictionary
+		Formatted  string
+		Err        bool
+	}{
+		{
+			Value:      `match="/js/app.*.js"`,
+			Dictionary: UseAsDictionary{Match: "/js/app.*.js", Type: "raw"},
+		},
+		{
+			Value:      `match="/app/*/main.js", match-dest=("script"), id="dictionary-12345"`,
+			Dictionary: UseAsDictionary{Match: "/app/*/main.js", MatchDest: []string{"script"}, ID: "dictionary-12345", Type: "raw"},
+			Formatted:  `match="/app/*/main.js", match-dest=("script"), id="dictionary-12345"`,
+		},
+		{
+			Value:      `match="/", type=custom, unknown=1`,
+			Dictionary: UseAsDictionary{Match: "/", Type: "custom"},
+			Formatted:  `match="/", type=custom`,
+		},
+		{Value: `id="no-match"`, Err: true},
+		{Value: `match=/`, Err: true},
+		{Value: `match="/", match-dest="script"`, Err: true},
+	}
+
+	for i, tcase := range tcases {
+		t.Run(fmt.Sprintf("%d", i), func(t *testing.T) {
+			d, err := ParseUseAsDictionary(tcase.Value)
+			if (err != nil) != tcase.Err {
+				t.Fatalf("expected error %v, got %v", tcase.Err, err)
+			}
+			if err != nil {
+				return
+			}
+			if !reflect.DeepEqual(d, tcase.Dictionary) {
+				t.Fatalf("expected %+v, got %+v", tcase.Dictionary, d)
+			}
+			formatted := tcase.Formatted
+			if formatted == "" {
+				formatted = tcase.Value
+			}
+			if s := d.String(); s != formatted {
+				t.Fatalf("expected %q, got %q", formatted, s)
+			}
+		})
+	}
+}
+
+func TestAvailableDictionary(t *testing.T) {
+	t.Parallel()
+
+	hash := DictionaryHash([]byte("dictionary"))
+	encoded := ":" + base64.StdEncoding.EncodeToString(hash) + ":"
+
+	tcases := []struct {
+		Header http.Header
+		ID     string
+		OK     bool
+	}{
+		{Header: http.Header{"Available-Dictionary": {encoded}}, OK: true},
+		{Header: http.Header{"Available-Dictionary": {encoded}, "Dictionary-Id": {`"v1"`}}, ID: "v1", OK: true},
+		{Header: http.Header{"Available-Dictionary": {":aGVsbG8=:"}}},
+		{Header: http.Header{"Available-Dictionary": {"token"}}},
+		{Header: http.Header{}},
+	}
+
+	for i, tcase := range tcases {
+		t.Run(fmt.Sprintf("%d", i), func(t *testing.T) {
+			h, id, ok := AvailableDictionary(tcase.Header)
+			if ok != tcase.OK {
+				t.Fatalf("expected ok %v, got %v", tcase.OK, ok)
+			}
+			if ok && (!reflect.DeepEqual(h, hash) || id != tcase.ID) {
+				t.Fatalf("expected %x %q, got %x %q", hash, tcase.ID, h, id)
+			}
+		})
+	}
+}
@@ -0,0 +1,577 @@
+// Copyright 2026 Franklin "Snaipe" Mathieu.
+//
+// Use of this source code is governed by the MIT license that can be
+// found in the LICENSE file.
+
+package htutil
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"crypto/sha512"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"hash"
+	"io"
+	"net/http"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+
+	"snai.pe/go-htutil/sfv"
+)
+
+// Digest is a digest of the content or of the representation of a message,
+// as carried by the Content-Digest and Repr-Digest header fields of
+// RFC 9530.
+type Digest struct {
+	// Algorithm is the lowercased name of the hash algorithm, like
+	// "sha-256".
+	Algorithm string
+
+	// Value is the hash of the content or representation.
+	Value []byte
+}
+
+var digestAlgorithms = struct {
+	sync.RWMutex
+	m map[string]func() hash.Hash
+}{
+	m: map[string]func() hash.Hash{
+		"sha-256": sha256.New,
+		"sha-512": sha512.New,
+	},
+}
+
+// RegisterDigestAlgorithm registers the hash algorithm name for use in
+// Content-Digest and Repr-Digest, replacing any previously registered
+// implementation. The sha-256 and sha-512 algorithms are registered by
+// default; the insecure md5, sha, and crc32c algorithms of RFC 9530 are
+// left for applications to register if they must.
+func RegisterDigestAlgorithm(name string, fn func() hash.Hash) {
+	digestAlgorithms.Lock()
+	defer digestAlgorithms.Unlock()
+	digestAlgorithms.m[strings.ToLower(name)] = fn
+}
+
+func digestAlgorithm(name string) func() hash.Hash {
+	digestAlgorithms.RLock()
+	defer digestAlgorithms.RUnlock()
+	return digestAlgorithms.m[name]
+}
+
+// registeredDigests returns the registered digest algorithms among algs,
+// lowercased.
+func registeredDigests(algs []string) []string {
+	var registered []string
+	for _, alg := range algs {
+		alg = strings.ToLower(alg)
+		if digestAlgorithm(alg) != nil {
+			registered = append(registered, alg)
+		}
+	}
+	return registered
+}
+
+// ParseDigest parses the value of a Content-Digest or Repr-Digest header
+// field. Members that are not byte sequences are dropped.
+func ParseDigest(value string) ([]Digest, error) {
+	dict, err := sfv.ParseDictionary(value)
+	if err != nil {
+		return nil, fmt.Errorf("parsing digest: %w", err)
+	}
+	digests := make([]Digest, 0, len(dict))
+	for _, m := range dict {
+		item, ok := m.Member.(sfv.Item)
+		if !ok {
+			continue
+		}
+		if v, ok := item.Value.([]byte); ok {
+			digests = append(digests, Digest{Algorithm: m.Key, Value: v})
+		}
+	}
+	return digests, nil
+}
+
+// FormatDigest formats digests as the value of a Content-Digest or
+// Repr-Digest header field.
+func FormatDigest(digests ...Digest) string {
+	var out strings.Builder
+	for i, d := range digests {
+		if i > 0 {
+			out.WriteString(", ")
+		}
+		out.WriteString(strings.ToLower(d.Algorithm) + "=:" + base64.StdEncoding.EncodeToString(d.Value) + ":")
+	}
+	return out.String()
+}
+
+// ComputeDigest returns the digests of content with each of the specified
+// registered algorithms.
+func ComputeDigest(content []byte, algs ...string) ([]Digest, error) {
+	dw, err := NewDigestWriter(io.Discard, algs...)
+	if err != nil {
+		return nil, err
+	}
+	dw.Write(content)
+	return dw.Digests(), nil
+}
+
+// SetDigest sets the header field name of h, Content-Digest or
+// Repr-Digest, to the digests of content with each of the specified
+// registered algorithms.
+func SetDigest(h http.Header, name string, content []byte, algs ...string) error {
+	digests, err := ComputeDigest(content, algs...)
+	if err != nil {
+		return err
+	}
+	h.Set(name, FormatDigest(digests...))
+	return nil
+}
+
+// ParseWantDigest parses the value of a Want-Content-Digest or
+// Want-Repr-Digest header field, and returns the algorithms it asks for,
+// by decreasing preference. Algorithms with a preference of 0, which are
+// refused, are left out.
+func ParseWantDigest(value string) ([]string, error) {
+	dict, err := sfv.ParseDictionary(value)
+	if err != nil {
+		return nil, fmt.Errorf("parsing digest preferences: %w", err)
+	}
+	type pref struct {
+		alg  string
+		pref int64
+	}
+	prefs := make([]pref, 0, len(dict))
+	for _, m := range dict {
+		item, ok := m.Member.(sfv.Item)
+		if !ok {
+			continue
+		}
+		if p, ok := item.Value.(int64); ok && p > 0 {
+			prefs = append(prefs, pref{m.Key, p})
+		}
+	}
+	sort.SliceStable(prefs, func(i, j int) bool { return prefs[i].pref > prefs[j].pref })
+	algs := make([]string, len(prefs))
+	for i, p := range prefs {
+		algs[i] = p.alg
+	}
+	return algs, nil
+}
+
+// FormatWantDigest formats the value of a Want-Content-Digest or
+// Want-Repr-Digest header field asking for algs, by decreasing preference.
+func FormatWantDigest(algs ...string) string {
+	var out strings.Builder
+	for i, alg := range algs {
+		if i > 0 {
+			out.WriteString(", ")
+		}
+		pref := 10 - i
+		if pref < 1 {
+			pref = 1
+		}
+		out.WriteString(strings.ToLower(alg) + "=" + strconv.Itoa(pref))
+	}
+	return out.String()
+}
+
+// ErrDigestUnverifiable is returned when a message carries no digest with
+// an algorithm that could be verified.
+var ErrDigestUnverifiable = errors.New("no verifiable digest")
+
+// DigestMismatchError is returned when the content of a message does not
+// match its digest.
+type DigestMismatchError struct {
+	// Field is the header field carrying the digest, like Content-Digest.
+	Field string
+
+	// Expected is the digest carried by the message.
+	Expected Digest
+
+	// Actual is the digest of the received content.
+	Actual Digest
+}
+
+func (e *DigestMismatchError) Error() string {
+	return fmt.Sprintf("%s mismatch: expected %s, got %s", e.Field, FormatDigest(e.Expected), FormatDigest(e.Actual))
+}
+
+// Unwrap returns a 400 Bad Request problem, so that handlers responding
+// with RespondError to errors reading request bodies reject requests whose
+// content does not match their digest.
+func (e *DigestMismatchError) Unwrap() error {
+	return NewProblem(http.StatusBadRequest, "The content does not match its "+e.Field+".")
+}
+
+// digester hashes content with several algorithms at once.
+type digester struct {
+	algs   []string
+	hashes []hash.Hash
+	w      io.Writer
+}
+
+func newDigester(algs []string) (*digester, error) {
+	d := &digester{algs: make([]string, len(algs)), hashes: make([]hash.Hash, len(algs))}
+	writers := make([]io.Writer, len(algs))
+	for i, alg := range algs {
+		alg = strings.ToLower(alg)
+		fn := digestAlgorithm(alg)
+		if fn == nil {
+			return nil, fmt.Errorf("digest algorithm %q is not registered", alg)
+		}
+		d.algs[i], d.hashes[i] = alg, fn()
+		writers[i] = d.hashes[i]
+	}
+	d.w = io.MultiWriter(writers...)
+	return d, nil
+}
+
+func (d *digester) Digests() []Digest {
+	digests := make([]Digest, len(d.algs))
+	for i, alg := range d.algs {
+		digests[i] = Digest{Algorithm: alg, Value: d.hashes[i].Sum(nil)}
+	}
+	return digests
+}
+
+// Verify compares the digests of the content so far against expected,
+// carried by the header field name. It returns a *DigestMismatchError on
+// the first mismatch, and ErrDigestUnverifiable if no expected digest has
+// an algorithm that was computed.
+func (d *digester) Verify(name string, expected []Digest) error {
+	verified := false
+	for _, exp := range expected {
+		for i, alg := range d.algs {
+			if alg != exp.Algorithm {
+				continue
+			}
+			actual := Digest{Algorithm: alg, Value: d.hashes[i].Sum(nil)}
+			if !bytes.Equal(actual.Value, exp.Value) {
+				return &DigestMismatchError{Field: name, Expected: exp, Actual: actual}
+			}
+			verified = true
+		}
+	}
+	if !verified {
+		return digestUnverifiableError(name)
+	}
+	return nil
+}
+
+// digestUnverifiableError is ErrDigestUnverifiable for the header field it
+// names, which also unwraps to a 400 Bad Request problem, like
+// *DigestMismatchError.
+type digestUnverifiableError string
+
+func (e digestUnverifiableError) Error() string {
+	return "verifying " + string(e) + ": " + ErrDigestUnverifiable.Error()
+}
+
+func (e digestUnverifiableError) Is(target error) bool {
+	return target == ErrDigestUnverifiable
+}
+
+func (e digestUnverifiableError) Unwrap() error {
+	return NewProblem(http.StatusBadRequest, "The content has no verifiable "+string(e)+".")
+}
+
+// DigestWriter is an io.Writer computing the digests of the content
+// written through it, without buffering it.
+type DigestWriter struct {
+	*digester
+}
+
+// NewDigestWriter returns a DigestWriter writing to w, and computing
+// digests with each of the specified registered algorithms.
+func NewDigestWriter(w io.Writer, algs ...string) (*DigestWriter, error) {
+	d, err := newDigester(algs)
+	if err != nil {
+		return nil, err
+	}
+	d.w = io.MultiWriter(w, d.w)
+	return &DigestWriter{d}, nil
+}
+
+func (dw *DigestWriter) Write(p []byte) (int, error) {
+	return dw.w.Write(p)
+}
+
+// DigestReader is an io.Reader computing the digests of the content read
+// through it, without buffering it.
+type DigestReader struct {
+	*digester
+	r io.Reader
+}
+
+// NewDigestReader returns a DigestReader reading from r, and computing
+// digests with each of the specified registered algorithms.
+func NewDigestReader(r io.Reader, algs ...string) (*DigestReader, error) {
+	d, err := newDigester(algs)
+	if err != nil {
+		return nil, err
+	}
+	return &DigestReader{digester: d, r: io.TeeReader(r, d.w)}, nil
+}
+
+func (dr *DigestReader) Read(p []byte) (int, error) {
+	return dr.r.Read(p)
+}
+
+// verifyingBody is a message body whose Content-Digest is verified once
+// it has been read in full. The digests are taken from the header, or
+// from the trailer, which is only known at the end of the body.
+type verifyingBody struct {
+	dr       *DigestReader
+	body     io.ReadCloser
+	header   http.Header
+	trailer  func() http.Header
+	required bool
+	err      error
+}
+
+// newVerifyingBody returns a body verifying the Content-Digest of body.
+// Whether there is a digest to verify is only known at the end of the
+// body, since the trailer may carry one even if it was not announced, so
+// the digests are computed with the algorithms of the digest in the
+// header, if registered, or with algs otherwise.
+func newVerifyingBody(body io.ReadCloser, header http.Header, trailer func() http.Header, algs []string, required bool) *verifyingBody {
+	if v := header.Get("Content-Digest"); v != "" {
+		digests, _ := ParseDigest(v)
+		names := make([]string, len(digests))
+		for i, d := range digests {
+			names[i] = d.Algorithm
+		}
+		if registered := registeredDigests(names); len(registered) > 0 {
+			algs = registered
+		}
+	}
+	dr, err := NewDigestReader(body, algs...)
+	if err != nil {
+		// Without any digest computed, the content is unverifiable.
+		dr, _ = NewDigestReader(body)
+	}
+	return &verifyingBody{dr: dr, body: body, header: header, trailer: trailer, required: required}
+}
+
+func (b *verifyingBody) Read(p []byte) (int, error) {
+	if b.err != nil {
+		return 0, b.err
+	}
+	n, err := b.dr.Read(p)
+	if err == io.EOF {
+		v := b.header.Get("Content-Digest")
+		if v == "" {
+			v = b.trailer().Get("Content-Digest")
+		}
+		digests, perr := ParseDigest(v)
+		if perr != nil {
+			err = perr
+		} else if verr := b.dr.Verify("Content-Digest", digests); verr != nil {
+			var mismatch *DigestMismatchError
+			if b.required || errors.As(verr, &mismatch) {
+				err = verr
+			}
+		}
+		b.err = err
+	}
+	return n, err
+}
+
+func (b *verifyingBody) Close() error {
+	return b.body.Close()
+}
+
+// ContentDigest adds Content-Digest header fields to responses, and
+// verifies the Content-Digest header fields of requests, as per RFC 9530.
+//
+// The digests of responses are computed while they are written, and sent
+// in their trailer, so that responses are never buffered. Trailers are
+// only sent with chunked HTTP/1.1 responses, or with HTTP/2; they are
+// dropped from responses with a Content-Length. Handlers that know the
+// digest of their content up front may set Content-Digest themselves,
+// which is then left untouched.
+//
+// Since Content-Digest covers the content as sent, after content coding,
+// ContentDigest must wrap compressing middleware like Compression.
+type ContentDigest struct {
+	// Algorithms are the registered digest algorithms to use, by
+	// decreasing preference. Unregistered algorithms are ignored.
+	// Defaults to sha-256.
+	Algorithms []string
+
+	// Required rejects requests with content but without a verifiable
+	// Content-Digest, with a 400 Bad Request problem asking for one with
+	// Want-Content-Digest.
+	Required bool
+}
+
+// Middleware returns a middleware verifying the content of requests, and
+// computing the digests of responses. Requests whose content does not
+// match their digest fail to be read with a *DigestMismatchError.
+func (cd *ContentDigest) Middleware(next http.Handler) http.Handler {
+	algs := registeredDigests(cd.Algorithms)
+	if len(algs) == 0 {
+		algs = []string{"sha-256"}
+	}
+	return http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+		hasContent := req.Body != nil && req.Body != http.NoBody && req.ContentLength != 0
+		if hasContent {
+			// Requests that announce no digest at all are rejected up
+			// front; the others once their content was read.
+			_, announced := req.Trailer["Content-Digest"]
+			if cd.Required && req.Header.Get("Content-Digest") == "" && !announced {
+				w.Header().Set("Want-Content-Digest", FormatWantDigest(algs...))
+				RespondError(w, req, NewProblem(http.StatusBadRequest, "The request content must have a Content-Digest."))
+				return
+			}
+			req.Body = newVerifyingBody(req.Body, req.Header, func() http.Header { return req.Trailer }, algs, cd.Required)
+		}
+
+		respAlgs := algs
+		if want := req.Header.Get("Want-Content-Digest"); want != "" {
+			if wanted, err := ParseWantDigest(want); err == nil {
+				respAlgs = nil
+				for _, alg := range wanted {
+					if digestAlgorithm(alg) != nil {
+						respAlgs = []string{alg}
+						break
+					}
+				}
+			}
+		}
+		if len(respAlgs) == 0 || req.Method == http.MethodHead {
+			next.ServeHTTP(w, req)
+			return
+		}
+
+		serveDigested(w, req, next, respAlgs)
+	})
+}
+
+// serveDigested serves req with next, computing the Content-Digest of the
+// response with the registered algorithms algs, and sending it in the
+// trailer, or in the header if the response is empty.
+func serveDigested(w http.ResponseWriter, req *http.Request, next http.Handler, algs []string) {
+	d, err := newDigester(algs)
+	if err != nil {
+		next.ServeHTTP(w, req)
+		return
+	}
+	dw := &digestResponseWriter{w: w, req: req, d: d}
+	next.ServeHTTP(Wrap(w, WriterHooks{
+		WriteHeader: func(next WriteHeaderFunc) WriteHeaderFunc { return dw.writeHeader(next) },
+		Write:       func(next WriteFunc) WriteFunc { return dw.write(next) },
+	}), req)
+	dw.finish()
+}
+
+// digestResponseWriter computes the digest of a response, and sets it in
+// the trailer once the response is complete.
+type digestResponseWriter struct {
+	w         http.ResponseWriter
+	req       *http.Request
+	d         *digester
+	status    int
+	announced bool
+	disabled  bool
+}
+
+func (dw *digestResponseWriter) writeHeader(next WriteHeaderFunc) WriteHeaderFunc {
+	return func(status int) {
+		if dw.status == 0 && status >= 200 {
+			dw.status = status
+			h := dw.w.Header()
+			switch {
+			case status == http.StatusNoContent || status == http.StatusNotModified || h.Get("Content-Digest") != "":
+				dw.disabled = true
+			case dw.req.ProtoAtLeast(1, 1) && h.Get("Content-Length") == "":
+				// Trailers can only be sent with chunked content in
+				// HTTP/1.1.
+				AnnounceTrailer(h, "Content-Digest")
+				dw.announced = true
+			}
+		}
+		next(status)
+	}
+}
+
+func (dw *digestResponseWriter) write(next WriteFunc) WriteFunc {
+	return func(p []byte) (int, error) {
+		if dw.status == 0 {
+			dw.writeHeader(dw.w.WriteHeader)(http.StatusOK)
+		}
+		n, err := next(p)
+		dw.d.w.Write(p[:n])
+		return n, err
+	}
+}
+
+func (dw *digestResponseWriter) finish() {
+	switch {
+	case dw.disabled:
+	case dw.status == 0:
+		// Nothing was written: the response is empty, and its header is
+		// still pending.
+		if dw.w.Header().Get("Content-Digest") == "" {
+			dw.w.Header().Set("Content-Digest", FormatDigest(dw.d.Digests()...))
+		}
+	case dw.announced:
+		SetTrailer(dw.w.Header(), "Content-Digest", FormatDigest(dw.d.Digests()...))
+	}
+}
+
+// DigestTransport is a http.RoundTripper asking for, and verifying, the
+// Content-Digest of responses, as per RFC 9530.
+//
+// Responses whose content does not match their digest fail to be read
+// with a *DigestMismatchError, once read in full. Responses that the base
+// transport transparently decompressed are not verified, as their digests
+// cover the compressed content; set DisableCompression on the base
+// http.Transport to verify them.
+type DigestTransport struct {
+	// Base is the underlying transport. Defaults to http.DefaultTransport.
+	Base http.RoundTripper
+
+	// Algorithms are the registered digest algorithms asked for with
+	// Want-Content-Digest, by decreasing preference. Unregistered
+	// algorithms are ignored. Defaults to sha-256.
+	Algorithms []string
+
+	// Required fails the reading of responses with content but without
+	// a verifiable Content-Digest with ErrDigestUnverifiable.
+	Required bool
+}
+
+func (t *DigestTransport) base() http.RoundTripper {
+	if t.Base != nil {
+		return t.Base
+	}
+	return http.DefaultTransport
+}
+
+func (t *DigestTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	algs := registeredDigests(t.Algorithms)
+	if len(algs) == 0 {
+		algs = []string{"sha-256"}
+	}
+	if req.Header.Get("Want-Content-Digest") == "" {
+		req = req.Clone(req.Context())
+		req.Header.Set("Want-Content-Digest", FormatWantDigest(algs...))
+	}
+	resp, err := t.base().RoundTrip(req)
+	if err != nil {
+		return nil, err
+	}
+	// Responses decompressed by the base transport lost the content that
+	// their digests cover.
+	if req.Method == http.MethodHead || resp.StatusCode == http.StatusNoContent ||
+		resp.StatusCode == http.StatusNotModified || resp.Uncompressed {
+		return resp, nil
+	}
+	resp.Body = newVerifyingBody(resp.Body, resp.Header, func() http.Header { return resp.Trailer }, algs, t.Required)
+	return resp, nil
+}
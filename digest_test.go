@@ -0,0 +1,299 @@
+// Copyright 2026 Franklin "Snaipe" Mathieu.
+//
+// Use of this source code is governed by the MIT license that can be
+// found in the LICENSE file.
+
+package htutil
+
+import (
+	"compress/gzip"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+// helloDigest is the sha-256 Content-Digest of helloContent, from
+// RFC 9530 §2.
+const (
+	helloContent = `{"hello": "world"}`
+	helloDigest  = "sha-256=:X48E9qOokqqrvdts8nOJRJN3OWDUoyWxBf7kbu9DBPE=:"
+)
+
+func TestDigest(t *testing.T) {
+	t.Parallel()
+
+	digests, err := ComputeDigest([]byte(helloContent), "SHA-256")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if s := FormatDigest(digests...); s != helloDigest {
+		t.Fatalf("expected %q, got %q", helloDigest, s)
+	}
+	parsed, err := ParseDigest(helloDigest + ", unixsum=3, sha-512=:AA==:")
+	if err != nil {
+		t.Fatal(err)
+	}
+	expected := append(digests, Digest{Algorithm: "sha-512", Value: []byte{0}})
+	if !reflect.DeepEqual(parsed, expected) {
+		t.Fatalf("expected %v, got %v", expected, parsed)
+	}
+	if _, err := ComputeDigest(nil, "md5"); err == nil {
+		t.Fatal("expected an error for an unregistered algorithm")
+	}
+	if _, err := ParseDigest("sha-256=:"); err == nil {
+		t.Fatal("expected an error for an invalid digest")
+	}
+}
+
+func TestParseWantDigest(t *testing.T) {
+	t.Parallel()
+
+	tcases := []struct {
+		Value  string
+		Expect []string
+		Err    bool
+	}{
+		{Value: "sha-256=1, sha-512=3", Expect: []string{"sha-512", "sha-256"}},
+		{Value: "sha-256=1, md5=0", Expect: []string{"sha-256"}},
+		{Value: "sha-256", Expect: []string{}},
+		{Value: "sha-256=", Err: true},
+	}
+
+	for i, tcase := range tcases {
+		t.Run(fmt.Sprintf("%d", i), func(t *testing.T) {
+			algs, err := ParseWantDigest(tcase.Value)
+			if (err != nil) != tcase.Err {
+				t.Fatalf("expected error %v, got %v", tcase.Err, err)
+			}
+			if err == nil && !reflect.DeepEqual(algs, tcase.Expect) {
+				t.Fatalf("expected %v, got %v", tcase.Expect, algs)
+			}
+		})
+	}
+
+	if s := FormatWantDigest("sha-512", "sha-256"); s != "sha-512=10, sha-256=9" {
+		t.Fatalf("unexpected Want-Content-Digest %q", s)
+	}
+}
+
+func TestContentDigestRequests(t *testing.T) {
+	t.Parallel()
+
+	tcases := []struct {
+		Digest     string
+		Trailer    string
+		Required   bool
+		Algorithms []string
+		Status     int
+	}{
+		{Status: 200},
+		{Digest: helloDigest, Status: 200},
+		{Digest: "sha-256=:AAAA:", Status: 400},
+		{Digest: "md5=:AAAA:", Status: 200},
+		{Trailer: helloDigest, Status: 200},
+		{Trailer: "sha-256=:AAAA:", Status: 400},
+		{Required: true, Status: 400},
+		{Required: true, Digest: "md5=:AAAA:", Status: 400},
+		{Required: true, Digest: helloDigest, Status: 200},
+		{Required: true, Trailer: "md5=:AAAA:", Status: 400},
+		{Algorithms: []string{"sha-384"}, Status: 200},
+		{Algorithms: []string{"sha-384"}, Trailer: "sha-256=:AAAA:", Status: 400},
+	}
+
+	for i, tcase := range tcases {
+		t.Run(fmt.Sprintf("%d", i), func(t *testing.T) {
+			cd := &ContentDigest{Algorithms: tcase.Algorithms, Required: tcase.Required}
+			handler := cd.Middleware(http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+				if _, err := io.ReadAll(req.Body); err != nil {
+					RespondError(w, req, err)
+				}
+			}))
+			srv := httptest.NewServer(handler)
+			defer srv.Close()
+
+			req, err := http.NewRequest("POST", srv.URL, io.NopCloser(strings.NewReader(helloContent)))
+			if err != nil {
+				t.Fatal(err)
+			}
+			if tcase.Digest != "" {
+				req.Header.Set("Content-Digest", tcase.Digest)
+			}
+			if tcase.Trailer != "" {
+				req.Trailer = http.Header{"Content-Digest": nil}
+				req.Body = &trailingBody{r: strings.NewReader(helloContent), set: func() {
+					req.Trailer.Set("Content-Digest", tcase.Trailer)
+				}}
+			}
+			resp, err := srv.Client().Do(req)
+			if err != nil {
+				t.Fatal(err)
+			}
+			resp.Body.Close()
+			if resp.StatusCode != tcase.Status {
+				t.Fatalf("expected status %d, got %d", tcase.Status, resp.StatusCode)
+			}
+			if tcase.Status == 400 && tcase.Required && tcase.Digest == "" && tcase.Trailer == "" {
+				if want := resp.Header.Get("Want-Content-Digest"); want != "sha-256=10" {
+					t.Fatalf("expected Want-Content-Digest, got %q", want)
+				}
+			}
+		})
+	}
+}
+
+// trailingBody is a request body that sets the request trailer when read
+// in full.
+type trailingBody struct {
+	r   io.Reader
+	set func()
+}
+
+func (b *trailingBody) Read(p []byte) (int, error) {
+	n, err := b.r.Read(p)
+	if err == io.EOF {
+		b.set()
+	}
+	return n, err
+}
+
+func (b *trailingBody) Close() error { return nil }
+
+func TestContentDigestResponses(t *testing.T) {
+	t.Parallel()
+
+	tcases := []struct {
+		Method  string
+		Want    string
+		Status  int
+		Body    string
+		Set     string
+		Header  string
+		Trailer string
+		Length  bool
+	}{
+		{Status: 200, Body: helloContent, Trailer: helloDigest},
+		{Status: 200, Set: "sha-256=:AAAA:", Header: "sha-256=:AAAA:", Body: helloContent},
+		{Status: 200, Want: "sha-512=10, sha-256=1", Body: "", Header: "sha-512=:z4PhNX7vuL3xVChQ1m2AB9Yg5AULVxXcg/SpIdNs6c5H0NE8XYXysP+DGNKHfuwvY7kxvUdBeoGlODJ6+SfaPg==:"},
+		{Status: 200, Want: "md5=10", Body: helloContent},
+		{Status: 200, Want: "sha-256=10", Body: helloContent, Trailer: helloDigest},
+		{Status: 204},
+		{Method: "HEAD", Status: 200},
+		{Status: 200, Length: true, Body: helloContent},
+	}
+
+	for i, tcase := range tcases {
+		t.Run(fmt.Sprintf("%d", i), func(t *testing.T) {
+			handler := (&ContentDigest{}).Middleware(http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+				if tcase.Set != "" {
+					w.Header().Set("Content-Digest", tcase.Set)
+				}
+				if tcase.Length {
+					w.Header().Set("Content-Length", fmt.Sprint(len(tcase.Body)))
+				}
+				if tcase.Status != 200 {
+					w.WriteHeader(tcase.Status)
+				}
+				if tcase.Body != "" {
+					io.WriteString(w, tcase.Body)
+				}
+			}))
+			method := tcase.Method
+			if method == "" {
+				method = "GET"
+			}
+			req := httptest.NewRequest(method, "/", nil)
+			if tcase.Want != "" {
+				req.Header.Set("Want-Content-Digest", tcase.Want)
+			}
+			rw := httptest.NewRecorder()
+			handler.ServeHTTP(rw, req)
+			resp := rw.Result()
+
+			if h := resp.Header.Get("Content-Digest"); h != tcase.Header {
+				t.Fatalf("expected Content-Digest %q, got %q", tcase.Header, h)
+			}
+			if tr := resp.Trailer.Get("Content-Digest"); tr != tcase.Trailer {
+				t.Fatalf("expected Content-Digest trailer %q, got %q", tcase.Trailer, tr)
+			}
+			if announced := resp.Header.Get("Trailer") == "Content-Digest"; announced != (tcase.Trailer != "") {
+				t.Fatalf("expected Content-Digest to be announced: %v, got Trailer %q", tcase.Trailer != "", resp.Header.Get("Trailer"))
+			}
+		})
+	}
+}
+
+func TestDigestTransport(t *testing.T) {
+	t.Parallel()
+
+	tcases := []struct {
+		Digest     string
+		Trailer    string
+		Gzip       bool
+		Required   bool
+		Algorithms []string
+		Err        error
+	}{
+		{},
+		{Digest: helloDigest},
+		{Digest: "sha-256=:AAAA:", Err: &DigestMismatchError{}},
+		{Digest: "md5=:AAAA:"},
+		{Digest: "md5=:AAAA:", Required: true, Err: ErrDigestUnverifiable},
+		{Required: true, Err: ErrDigestUnverifiable},
+		{Trailer: helloDigest, Required: true},
+		{Trailer: "sha-256=:AAAA:", Err: &DigestMismatchError{}},
+		{Digest: "sha-256=:AAAA:", Gzip: true},
+		{Algorithms: []string{"sha-384"}},
+		{Algorithms: []string{"sha-384"}, Trailer: "sha-256=:AAAA:", Err: &DigestMismatchError{}},
+	}
+
+	for i, tcase := range tcases {
+		t.Run(fmt.Sprintf("%d", i), func(t *testing.T) {
+			var want string
+			srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+				want = req.Header.Get("Want-Content-Digest")
+				if tcase.Digest != "" {
+					w.Header().Set("Content-Digest", tcase.Digest)
+				}
+				if tcase.Trailer != "" {
+					// Not announced, which the client learns at the end.
+					w.Header().Set(http.TrailerPrefix+"Content-Digest", tcase.Trailer)
+				}
+				if tcase.Gzip {
+					w.Header().Set("Content-Encoding", "gzip")
+					zw := gzip.NewWriter(w)
+					io.WriteString(zw, helloContent)
+					zw.Close()
+					return
+				}
+				io.WriteString(w, helloContent)
+			}))
+			defer srv.Close()
+
+			client := &http.Client{Transport: &DigestTransport{Base: srv.Client().Transport, Algorithms: tcase.Algorithms, Required: tcase.Required}}
+			resp, err := client.Get(srv.URL)
+			if err != nil {
+				t.Fatal(err)
+			}
+			defer resp.Body.Close()
+			if want != "sha-256=10" {
+				t.Fatalf("expected Want-Content-Digest, got %q", want)
+			}
+
+			_, err = io.ReadAll(resp.Body)
+			var mismatch *DigestMismatchError
+			switch {
+			case tcase.Err == nil && err != nil:
+				t.Fatalf("expected no error, got %v", err)
+			case tcase.Err == ErrDigestUnverifiable && !errors.Is(err, ErrDigestUnverifiable):
+				t.Fatalf("expected ErrDigestUnverifiable, got %v", err)
+			case tcase.Err != nil && tcase.Err != ErrDigestUnverifiable && !errors.As(err, &mismatch):
+				t.Fatalf("expected a *DigestMismatchError, got %v", err)
+			}
+		})
+	}
+}
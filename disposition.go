@@ -0,0 +1,152 @@
+// Copyright 2026 Franklin "Snaipe" Mathieu.
+//
+// Use of this source code is governed by the MIT license that can be
+// found in the LICENSE file.
+
+package htutil
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+	"unicode/utf8"
+)
+
+// FormatContentDisposition returns a Content-Disposition header value, as
+// per RFC 6266, of the specified disposition type, like "attachment" or
+// "inline", with the specified filename, if not empty, and parameters.
+//
+// Filenames that are not printable US-ASCII, or that contain percent signs
+// that some user agents would decode, are sent both in filename*, encoded
+// as per RFC 8187, and in filename, with an ASCII fallback for user agents
+// that do not support filename*.
+func FormatContentDisposition(dispType, filename string, params map[string]string) string {
+	var out strings.Builder
+	out.WriteString(strings.ToLower(dispType))
+	if filename != "" {
+		fallback, lossy := asciiFilename(filename)
+		out.WriteString("; filename=" + QuoteString(fallback))
+		if lossy {
+			out.WriteString("; filename*=UTF-8''" + encodeExtValue(filename))
+		}
+	}
+
+	names := make([]string, 0, len(params))
+	for name := range params {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	for _, name := range names {
+		switch lower := strings.ToLower(name); lower {
+		case "filename", "filename*":
+			continue
+		default:
+			out.WriteString("; " + lower + "=" + tokenOrQuoted(params[name]))
+		}
+	}
+	return out.String()
+}
+
+// asciiFilename returns the ASCII fallback of filename, and whether it
+// differs from filename.
+func asciiFilename(filename string) (string, bool) {
+	var (
+		out   strings.Builder
+		lossy bool
+	)
+	for _, r := range filename {
+		switch {
+		case r == '%' || r < 0x20 || r >= 0x7f:
+			out.WriteByte('_')
+			lossy = true
+		default:
+			out.WriteRune(r)
+		}
+	}
+	return out.String(), lossy
+}
+
+// ParseContentDisposition parses a Content-Disposition header value, and
+// returns its lowercased disposition type, its filename, and its other
+// parameters, with lowercased names.
+//
+// The filename is taken from filename* if present and decodable, and from
+// filename otherwise. It is sanitized: only its last path element is kept,
+// and control characters are dropped, so that it can be used as a local
+// file name, though callers must still guard against clobbering files.
+func ParseContentDisposition(value string) (dispType, filename string, params map[string]string, err error) {
+	parts := splitUnquoted(value, ';')
+	dispType = strings.ToLower(trimOWS(parts[0]))
+	if !IsToken(dispType) {
+		return "", "", nil, fmt.Errorf("parsing content-disposition: invalid disposition type %q", dispType)
+	}
+
+	var (
+		plain, ext       string
+		hasPlain, hasExt bool
+	)
+	for _, param := range parts[1:] {
+		param = trimOWS(param)
+		if param == "" {
+			continue
+		}
+		name, v, ok := strings.Cut(param, "=")
+		name, v = strings.ToLower(trimOWS(name)), trimOWS(v)
+		if !ok || !IsToken(strings.TrimSuffix(name, "*")) {
+			return "", "", nil, fmt.Errorf("parsing content-disposition: invalid parameter %q", param)
+		}
+		if strings.HasSuffix(name, "*") {
+			decoded, err := decodeExtValue(v)
+			if err != nil {
+				// Fall back to the plain parameter, if any.
+				continue
+			}
+			v = decoded
+		} else if strings.HasPrefix(v, `"`) {
+			unquoted, err := UnquoteString(v)
+			if err != nil {
+				return "", "", nil, fmt.Errorf("parsing content-disposition: %w", err)
+			}
+			v = unquoted
+		}
+
+		switch name {
+		case "filename":
+			plain, hasPlain = v, true
+		case "filename*":
+			ext, hasExt = v, true
+		default:
+			if params == nil {
+				params = make(map[string]string)
+			}
+			params[name] = v
+		}
+	}
+
+	switch {
+	case hasExt:
+		filename = sanitizeFilename(ext)
+	case hasPlain:
+		filename = sanitizeFilename(plain)
+	}
+	return dispType, filename, params, nil
+}
+
+// sanitizeFilename returns the last path element of name, without control
+// characters, or "" if it does not name a file.
+func sanitizeFilename(name string) string {
+	if i := strings.LastIndexAny(name, `/\`); i != -1 {
+		name = name[i+1:]
+	}
+	name = strings.Map(func(r rune) rune {
+		if r < 0x20 || r == 0x7f || r == utf8.RuneError {
+			return -1
+		}
+		return r
+	}, name)
+	name = strings.TrimSpace(name)
+	if name == "." || name == ".." {
+		return ""
+	}
+	return name
+}
@@ -0,0 +1,93 @@
+// Copyright 2026 Franklin "Snaipe" Mathieu.
+//
+// Use of this source code is governed by the MIT license that can be
+// found in the LICENSE file.
+
+package htutil
+
+import (
+	"fmt"
+	"reflect"
+	"testing"
+)
+
+func TestFormatContentDisposition(t *testing.T) {
+	t.Parallel()
+
+	tcases := []struct {
+		Type     string
+		Filename string
+		Params   map[string]string
+		Expect   string
+	}{
+		{Type: "inline", Expect: "inline"},
+		{Type: "attachment", Filename: "report.pdf", Expect: `attachment; filename="report.pdf"`},
+		{Type: "Attachment", Filename: `say "hi".txt`, Expect: `attachment; filename="say \"hi\".txt"`},
+		{Type: "attachment", Filename: "€ rates.pdf", Expect: `attachment; filename="_ rates.pdf"; filename*=UTF-8''%E2%82%AC%20rates.pdf`},
+		{Type: "attachment", Filename: "100%.txt", Expect: `attachment; filename="100_.txt"; filename*=UTF-8''100%25.txt`},
+		{Type: "form-data", Filename: "a.txt", Params: map[string]string{"name": "file", "filename": "ignored"}, Expect: `form-data; filename="a.txt"; name=file`},
+	}
+
+	for i, tcase := range tcases {
+		t.Run(fmt.Sprintf("%d", i), func(t *testing.T) {
+			out := FormatContentDisposition(tcase.Type, tcase.Filename, tcase.Params)
+			if out != tcase.Expect {
+				t.Fatalf("expected %q, got %q", tcase.Expect, out)
+			}
+			_, filename, _, err := ParseContentDisposition(out)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if filename != tcase.Filename {
+				t.Fatalf("expected filename %q, got %q", tcase.Filename, filename)
+			}
+		})
+	}
+}
+
+func TestParseContentDisposition(t *testing.T) {
+	t.Parallel()
+
+	tcases := []struct {
+		Value    string
+		Type     string
+		Filename string
+		Params   map[string]string
+		Err      bool
+	}{
+		{Value: "inline", Type: "inline"},
+		{Value: `ATTACHMENT; FileName="a.txt"`, Type: "attachment", Filename: "a.txt"},
+		{Value: `attachment; filename*=UTF-8''%e2%82%ac%20rates; filename="EUR rates"`, Type: "attachment", Filename: "€ rates"},
+		{Value: `attachment; filename*=iso-8859-1'en'%A3%20rates`, Type: "attachment", Filename: "£ rates"},
+		{Value: `attachment; filename*=koi8-r''x; filename="fallback"`, Type: "attachment", Filename: "fallback"},
+		{Value: `attachment; filename="../../etc/passwd"`, Type: "attachment", Filename: "passwd"},
+		{Value: `attachment; filename="C:\\Windows\\win.ini"`, Type: "attachment", Filename: "win.ini"},
+		{Value: `attachment; filename=".."`, Type: "attachment", Filename: ""},
+		{Value: "attachment; filename*=UTF-8''a%0Ab", Type: "attachment", Filename: "ab"},
+		{Value: `form-data; name="field"; filename=x.bin`, Type: "form-data", Filename: "x.bin", Params: map[string]string{"name": "field"}},
+		{Value: `attachment; filename`, Err: true},
+		{Value: `attachment; filename="a`, Err: true},
+		{Value: `"attachment"`, Err: true},
+	}
+
+	for i, tcase := range tcases {
+		t.Run(fmt.Sprintf("%d", i), func(t *testing.T) {
+			dispType, filename, params, err := ParseContentDisposition(tcase.Value)
+			if (err != nil) != tcase.Err {
+				t.Fatalf("expected error %v, got %v", tcase.Err, err)
+			}
+			if err != nil {
+				return
+			}
+			if dispType != tcase.Type {
+				t.Fatalf("expected type %q, got %q", tcase.Type, dispType)
+			}
+			if filename != tcase.Filename {
+				t.Fatalf("expected filename %q, got %q", tcase.Filename, filename)
+			}
+			if !reflect.DeepEqual(params, tcase.Params) {
+				t.Fatalf("expected params %v, got %v", tcase.Params, params)
+			}
+		})
+	}
+}
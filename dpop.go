@@ -0,0 +1,420 @@
+// Copyright 2026 Franklin "Snaipe" Mathieu.
+//
+// Use of this source code is governed by the MIT license that can be
+// found in the LICENSE file.
+
+package htutil
+
+import (
+	"container/heap"
+	"crypto"
+	"crypto/ecdh"
+	"crypto/ecdsa"
+	"crypto/ed25519"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"math/big"
+	"net/http"
+	"net/url"
+	"strings"
+	"sync"
+	"time"
+)
+
+// This file implements Demonstrating Proof of Possession (DPoP), as per
+// RFC 9449, with the ES256 and EdDSA (Ed25519) algorithms.
+
+var b64url = base64.RawURLEncoding
+
+// dpopJWK is a public JSON Web Key, as embedded in DPoP proofs.
+type dpopJWK struct {
+	Kty string `json:"kty"`
+	Crv string `json:"crv"`
+	X   string `json:"x"`
+	Y   string `json:"y,omitempty"`
+	D   string `json:"d,omitempty"`
+}
+
+type dpopHeader struct {
+	Typ string  `json:"typ"`
+	Alg string  `json:"alg"`
+	JWK dpopJWK `json:"jwk"`
+}
+
+type dpopClaims struct {
+	JTI   string `json:"jti"`
+	HTM   string `json:"htm"`
+	HTU   string `json:"htu"`
+	IAT   int64  `json:"iat"`
+	ATH   string `json:"ath,omitempty"`
+	Nonce string `json:"nonce,omitempty"`
+}
+
+// thumbprint returns the JWK thumbprint of k, as per RFC 7638.
+func (k dpopJWK) thumbprint() string {
+	// The required members, in lexicographic order, without whitespace.
+	var canonical string
+	switch k.Kty {
+	case "EC":
+		canonical = fmt.Sprintf(`{"crv":%q,"kty":"EC","x":%q,"y":%q}`, k.Crv, k.X, k.Y)
+	default:
+		canonical = fmt.Sprintf(`{"crv":%q,"kty":%q,"x":%q}`, k.Crv, k.Kty, k.X)
+	}
+	sum := sha256.Sum256([]byte(canonical))
+	return b64url.EncodeToString(sum[:])
+}
+
+// accessTokenHash returns the value of the ath claim for token.
+func accessTokenHash(token string) string {
+	sum := sha256.Sum256([]byte(token))
+	return b64url.EncodeToString(sum[:])
+}
+
+// dpopTarget returns the htu claim value for u: the URL without its query
+// and fragment, in normal form (see URL.Normalize), so that e.g. an
+// explicit default port does not make otherwise equal URLs differ.
+func dpopTarget(u *url.URL) string {
+	target := *u
+	target.RawQuery, target.ForceQuery, target.Fragment, target.RawFragment = "", false, "", ""
+	target.User = nil
+	return URL{&target}.Normalize().String()
+}
+
+// DPoPProver creates DPoP proofs, binding requests to a private key.
+type DPoPProver struct {
+	// Key is either an *ecdsa.PrivateKey on the P-256 curve, or an
+	// ed25519.PrivateKey. Other signers are not supported.
+	Key crypto.Signer
+}
+
+func (p *DPoPProver) jwk() (dpopJWK, string, error) {
+	switch key := p.Key.(type) {
+	case *ecdsa.PrivateKey:
+		if key.Curve != elliptic.P256() {
+			return dpopJWK{}, "", errors.New("dpop: unsupported ecdsa curve")
+		}
+		x, y := make([]byte, 32), make([]byte, 32)
+		key.X.FillBytes(x)
+		key.Y.FillBytes(y)
+		return dpopJWK{Kty: "EC", Crv: "P-256", X: b64url.EncodeToString(x), Y: b64url.EncodeToString(y)}, "ES256", nil
+	case ed25519.PrivateKey:
+		pub := key.Public().(ed25519.PublicKey)
+		return dpopJWK{Kty: "OKP", Crv: "Ed25519", X: b64url.EncodeToString(pub)}, "EdDSA", nil
+	}
+	return dpopJWK{}, "", fmt.Errorf("dpop: unsupported key type %T", p.Key)
+}
+
+// Thumbprint returns the JWK thumbprint of the public key of p, which
+// authorization servers bind access tokens to (the jkt confirmation).
+func (p *DPoPProver) Thumbprint() (string, error) {
+	jwk, _, err := p.jwk()
+	if err != nil {
+		return "", err
+	}
+	return jwk.thumbprint(), nil
+}
+
+// Proof returns a DPoP proof for a request with the specified method and
+// URL. If accessToken is not empty, the proof is bound to it. nonce is
+// the last nonce provided by the server through the DPoP-Nonce header, if
+// any.
+func (p *DPoPProver) Proof(method string, u *url.URL, accessToken, nonce string) (string, error) {
+	jwk, alg, err := p.jwk()
+	if err != nil {
+		return "", err
+	}
+
+	jti := make([]byte, 16)
+	if _, err := rand.Read(jti); err != nil {
+		return "", err
+	}
+	claims := dpopClaims{
+		JTI:   b64url.EncodeToString(jti),
+		HTM:   method,
+		HTU:   dpopTarget(u),
+		IAT:   time.Now().Unix(),
+		Nonce: nonce,
+	}
+	if accessToken != "" {
+		claims.ATH = accessTokenHash(accessToken)
+	}
+
+	header, err := json.Marshal(dpopHeader{Typ: "dpop+jwt", Alg: alg, JWK: jwk})
+	if err != nil {
+		return "", err
+	}
+	payload, err := json.Marshal(claims)
+	if err != nil {
+		return "", err
+	}
+	signingInput := b64url.EncodeToString(header) + "." + b64url.EncodeToString(payload)
+
+	var sig []byte
+	switch key := p.Key.(type) {
+	case *ecdsa.PrivateKey:
+		digest := sha256.Sum256([]byte(signingInput))
+		r, s, err := ecdsa.Sign(rand.Reader, key, digest[:])
+		if err != nil {
+			return "", err
+		}
+		sig = make([]byte, 64)
+		r.FillBytes(sig[:32])
+		s.FillBytes(sig[32:])
+	case ed25519.PrivateKey:
+		sig = ed25519.Sign(key, []byte(signingInput))
+	}
+	return signingInput + "." + b64url.EncodeToString(sig), nil
+}
+
+// SetDPoP sets the DPoP header of req to a proof for it, and binds the
+// proof to accessToken, which is sent with the DPoP authentication scheme.
+func (p *DPoPProver) SetDPoP(req *http.Request, accessToken, nonce string) error {
+	proof, err := p.Proof(req.Method, req.URL, accessToken, nonce)
+	if err != nil {
+		return err
+	}
+	req.Header.Set("DPoP", proof)
+	if accessToken != "" {
+		req.Header.Set("Authorization", "DPoP "+accessToken)
+	}
+	return nil
+}
+
+// ErrInvalidDPoPProof is wrapped by the errors returned by
+// DPoPVerifier.Verify.
+var ErrInvalidDPoPProof = errors.New("invalid DPoP proof")
+
+// DPoPReplayCache remembers the identifiers of the DPoP proofs that were
+// already used, to detect replays.
+type DPoPReplayCache interface {
+	// Use marks jti as used until expiry, and returns false if it was
+	// already used.
+	Use(jti string, expiry time.Time) bool
+}
+
+// MemoryReplayCache is an in-memory DPoPReplayCache, suitable for single
+// instance deployments.
+type MemoryReplayCache struct {
+	mu      sync.Mutex
+	used    map[string]time.Time
+	expires replayHeap
+}
+
+func (c *MemoryReplayCache) Use(jti string, expiry time.Time) bool {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if c.used == nil {
+		c.used = make(map[string]time.Time)
+	}
+	// Only the identifiers that expired are visited, soonest first.
+	now := time.Now()
+	for len(c.expires) > 0 && now.After(c.expires[0].expiry) {
+		delete(c.used, heap.Pop(&c.expires).(replayEntry).jti)
+	}
+	if _, ok := c.used[jti]; ok {
+		return false
+	}
+	c.used[jti] = expiry
+	heap.Push(&c.expires, replayEntry{jti: jti, expiry: expiry})
+	return true
+}
+
+type replayEntry struct {
+	jti    string
+	expiry time.Time
+}
+
+// replayHeap is a min-heap of replay cache entries, by expiry.
+type replayHeap []replayEntry
+
+func (h replayHeap) Len() int           { return len(h) }
+func (h replayHeap) Less(i, j int) bool { return h[i].expiry.Before(h[j].expiry) }
+func (h replayHeap) Swap(i, j int)      { h[i], h[j] = h[j], h[i] }
+func (h *replayHeap) Push(x any)        { *h = append(*h, x.(replayEntry)) }
+
+func (h *replayHeap) Pop() any {
+	old := *h
+	e := old[len(old)-1]
+	*h = old[:len(old)-1]
+	return e
+}
+
+// DPoPProof is a verified DPoP proof.
+type DPoPProof struct {
+	// Thumbprint is the JWK thumbprint of the key that signed the proof,
+	// to check against the jkt confirmation of the access token.
+	Thumbprint string
+
+	// ID is the unique identifier of the proof (the jti claim).
+	ID string
+
+	// IssuedAt is the time at which the proof was created.
+	IssuedAt time.Time
+}
+
+// DPoPVerifier validates DPoP proofs on incoming requests.
+type DPoPVerifier struct {
+	// MaxAge is how long proofs are accepted after their creation.
+	// Defaults to 1 minute.
+	MaxAge time.Duration
+
+	// Leeway is the tolerated clock skew for proofs created in the future.
+	// Defaults to 5 seconds.
+	Leeway time.Duration
+
+	// Replay, if not nil, is used to reject proofs that were already used.
+	Replay DPoPReplayCache
+
+	// Nonce, if not nil, returns whether the nonce of a proof is valid.
+	// Servers issuing nonces send them in DPoP-Nonce.
+	Nonce func(nonce string) bool
+}
+
+// Verify validates the DPoP proof of req. If accessToken is not empty,
+// the proof must be bound to it.
+func (v *DPoPVerifier) Verify(req *http.Request, accessToken string) (*DPoPProof, error) {
+	values := req.Header.Values("DPoP")
+	if len(values) != 1 {
+		return nil, fmt.Errorf("%w: expected exactly one DPoP header field, got %d", ErrInvalidDPoPProof, len(values))
+	}
+	proof, err := v.verify(values[0], req, accessToken)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrInvalidDPoPProof, err)
+	}
+	return proof, nil
+}
+
+func (v *DPoPVerifier) verify(jwt string, req *http.Request, accessToken string) (*DPoPProof, error) {
+	parts := strings.Split(jwt, ".")
+	if len(parts) != 3 {
+		return nil, errors.New("malformed JWT")
+	}
+	rawHeader, err := b64url.DecodeString(parts[0])
+	if err != nil {
+		return nil, fmt.Errorf("decoding header: %w", err)
+	}
+	rawClaims, err := b64url.DecodeString(parts[1])
+	if err != nil {
+		return nil, fmt.Errorf("decoding claims: %w", err)
+	}
+	sig, err := b64url.DecodeString(parts[2])
+	if err != nil {
+		return nil, fmt.Errorf("decoding signature: %w", err)
+	}
+
+	var header dpopHeader
+	if err := json.Unmarshal(rawHeader, &header); err != nil {
+		return nil, fmt.Errorf("decoding header: %w", err)
+	}
+	if header.Typ != "dpop+jwt" {
+		return nil, fmt.Errorf("unexpected typ %q", header.Typ)
+	}
+	if header.JWK.D != "" {
+		return nil, errors.New("jwk contains a private key")
+	}
+	if err := verifyDPoPSignature(header, []byte(parts[0]+"."+parts[1]), sig); err != nil {
+		return nil, err
+	}
+
+	var claims dpopClaims
+	if err := json.Unmarshal(rawClaims, &claims); err != nil {
+		return nil, fmt.Errorf("decoding claims: %w", err)
+	}
+	if claims.JTI == "" {
+		return nil, errors.New("missing jti")
+	}
+	if claims.HTM != req.Method {
+		return nil, fmt.Errorf("htm %q does not match method %s", claims.HTM, req.Method)
+	}
+	htu, err := url.Parse(claims.HTU)
+	if err != nil {
+		return nil, fmt.Errorf("parsing htu: %w", err)
+	}
+	if dpopTarget(htu) != dpopTarget(RequestURL(req)) {
+		return nil, fmt.Errorf("htu %q does not match request URL", claims.HTU)
+	}
+
+	maxAge, leeway := v.MaxAge, v.Leeway
+	if maxAge <= 0 {
+		maxAge = time.Minute
+	}
+	if leeway <= 0 {
+		leeway = 5 * time.Second
+	}
+	iat := time.Unix(claims.IAT, 0)
+	now := time.Now()
+	if iat.After(now.Add(leeway)) || iat.Before(now.Add(-maxAge)) {
+		return nil, fmt.Errorf("iat %v is outside of the acceptable window", iat)
+	}
+
+	if accessToken != "" && claims.ATH != accessTokenHash(accessToken) {
+		return nil, errors.New("ath does not match the access token")
+	}
+	if v.Nonce != nil && !v.Nonce(claims.Nonce) {
+		return nil, errors.New("invalid nonce")
+	}
+	if v.Replay != nil && !v.Replay.Use(claims.JTI, iat.Add(maxAge+leeway)) {
+		return nil, errors.New("proof was already used")
+	}
+
+	return &DPoPProof{
+		Thumbprint: header.JWK.thumbprint(),
+		ID:         claims.JTI,
+		IssuedAt:   iat,
+	}, nil
+}
+
+func verifyDPoPSignature(header dpopHeader, signingInput, sig []byte) error {
+	x, err := b64url.DecodeString(header.JWK.X)
+	if err != nil {
+		return fmt.Errorf("decoding jwk: %w", err)
+	}
+
+	switch header.Alg {
+	case "ES256":
+		if header.JWK.Kty != "EC" || header.JWK.Crv != "P-256" {
+			return errors.New("jwk does not match alg ES256")
+		}
+		y, err := b64url.DecodeString(header.JWK.Y)
+		if err != nil {
+			return fmt.Errorf("decoding jwk: %w", err)
+		}
+		if len(x) != 32 || len(y) != 32 {
+			return errors.New("invalid P-256 public key")
+		}
+		// crypto/ecdh rejects points that are not on the curve.
+		point := append(append([]byte{4}, x...), y...)
+		if _, err := ecdh.P256().NewPublicKey(point); err != nil {
+			return errors.New("invalid P-256 public key")
+		}
+		key := &ecdsa.PublicKey{
+			Curve: elliptic.P256(),
+			X:     new(big.Int).SetBytes(x),
+			Y:     new(big.Int).SetBytes(y),
+		}
+		if len(sig) != 64 {
+			return errors.New("invalid signature")
+		}
+		digest := sha256.Sum256(signingInput)
+		r, s := new(big.Int).SetBytes(sig[:32]), new(big.Int).SetBytes(sig[32:])
+		if !ecdsa.Verify(key, digest[:], r, s) {
+			return errors.New("invalid signature")
+		}
+	case "EdDSA":
+		if header.JWK.Kty != "OKP" || header.JWK.Crv != "Ed25519" || len(x) != ed25519.PublicKeySize {
+			return errors.New("jwk does not match alg EdDSA")
+		}
+		if !ed25519.Verify(ed25519.PublicKey(x), signingInput, sig) {
+			return errors.New("invalid signature")
+		}
+	default:
+		return fmt.Errorf("unsupported alg %q", header.Alg)
+	}
+	return nil
+}
@@ -0,0 +1,156 @@
+// Copyright 2026 Franklin "Snaipe" Mathieu.
+//
+// Use of this source code is governed by the MIT license that can be
+// found in the LICENSE file.
+
+package htutil
+
+import (
+	"bytes"
+	"crypto"
+	"crypto/ecdsa"
+	"crypto/ed25519"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/rsa"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"testing"
+	"time"
+)
+
+func TestDPoP(t *testing.T) {
+	t.Parallel()
+
+	ecKey, _ := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	_, edKey, _ := ed25519.GenerateKey(nil)
+	_, otherKey, _ := ed25519.GenerateKey(nil)
+
+	tcases := []struct {
+		Key         crypto.Signer
+		Method      string
+		URL         string
+		Token       string
+		VerifyToken string
+		Tamper      func(req *http.Request)
+		Valid       bool
+	}{
+		{Key: ecKey, Method: "GET", URL: "https://api.example.com/resource?x=1", Token: "tok", VerifyToken: "tok", Valid: true},
+		{Key: edKey, Method: "POST", URL: "https://API.example.com/resource", Valid: true},
+		{Key: edKey, Method: "POST", URL: "https://api.example.com:443/resource", Valid: true},
+		{Key: edKey, Method: "POST", URL: "https://api.example.com/other", Valid: false},
+		{Key: edKey, Method: "GET", URL: "https://api.example.com/resource", Token: "tok", VerifyToken: "other", Valid: false},
+		{
+			Key:    ecKey,
+			Method: "GET",
+			URL:    "https://api.example.com/resource",
+			Tamper: func(req *http.Request) { req.Method = "DELETE" },
+			Valid:  false,
+		},
+		{
+			Key:    edKey,
+			Method: "GET",
+			URL:    "https://api.example.com/resource",
+			Tamper: func(req *http.Request) {
+				forged, _ := (&DPoPProver{Key: otherKey}).Proof("GET", req.URL, "", "")
+				// Splice the signature of another key onto the proof.
+				req.Header.Set("DPoP", req.Header.Get("DPoP")[:len(forged)-86]+forged[len(forged)-86:])
+			},
+			Valid: false,
+		},
+	}
+
+	for i, tcase := range tcases {
+		t.Run(fmt.Sprintf("%d", i), func(t *testing.T) {
+			prover := &DPoPProver{Key: tcase.Key}
+			client := httptest.NewRequest(tcase.Method, tcase.URL, nil)
+			if err := prover.SetDPoP(client, tcase.Token, ""); err != nil {
+				t.Fatal(err)
+			}
+
+			// The server sees the request with an origin-form target.
+			req := httptest.NewRequest(tcase.Method, "https://api.example.com/resource?x=1", nil)
+			req.Header = client.Header
+			if tcase.Tamper != nil {
+				tcase.Tamper(req)
+			}
+
+			v := &DPoPVerifier{Replay: &MemoryReplayCache{}}
+			proof, err := v.Verify(req, tcase.VerifyToken)
+			if !tcase.Valid {
+				if !errors.Is(err, ErrInvalidDPoPProof) {
+					t.Fatalf("expected ErrInvalidDPoPProof, got %v", err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("expected no error, got %v", err)
+			}
+			thumbprint, _ := prover.Thumbprint()
+			if proof.Thumbprint != thumbprint {
+				t.Fatalf("expected thumbprint %s, got %s", thumbprint, proof.Thumbprint)
+			}
+			if _, err := v.Verify(req, tcase.VerifyToken); !errors.Is(err, ErrInvalidDPoPProof) {
+				t.Fatalf("expected replayed proof to be rejected, got %v", err)
+			}
+		})
+	}
+}
+
+func TestDPoPThumbprint(t *testing.T) {
+	t.Parallel()
+
+	// Example from RFC 8037 §A.3.
+	jwk := dpopJWK{Kty: "OKP", Crv: "Ed25519", X: "11qYAYKxCrfVS_7TyWQHOg7hcvPapiMlrwIaaPcHURo"}
+	if tp := jwk.thumbprint(); tp != "kPrK_qmxVWaYVA9wwBF6Iuo3vVzz7TxHCTwXBygrS4k" {
+		t.Fatalf("expected thumbprint %s, got %s", "kPrK_qmxVWaYVA9wwBF6Iuo3vVzz7TxHCTwXBygrS4k", tp)
+	}
+}
+
+func TestDPoPUnsupportedKey(t *testing.T) {
+	t.Parallel()
+
+	rsaKey, err := rsa.GenerateKey(rand.Reader, 1024)
+	if err != nil {
+		t.Fatal(err)
+	}
+	p384Key, _ := ecdsa.GenerateKey(elliptic.P384(), rand.Reader)
+	for _, key := range []crypto.Signer{rsaKey, p384Key} {
+		u, _ := url.Parse("https://api.example.com/")
+		if _, err := (&DPoPProver{Key: key}).Proof("GET", u, "", ""); err == nil {
+			t.Fatalf("expected an error for %T", key)
+		}
+	}
+}
+
+func TestDPoPOffCurveKey(t *testing.T) {
+	t.Parallel()
+
+	coord := b64url.EncodeToString(bytes.Repeat([]byte{1}, 32))
+	header := dpopHeader{Typ: "dpop+jwt", Alg: "ES256", JWK: dpopJWK{Kty: "EC", Crv: "P-256", X: coord, Y: coord}}
+	if err := verifyDPoPSignature(header, []byte("input"), make([]byte, 64)); err == nil || err.Error() != "invalid P-256 public key" {
+		t.Fatalf("expected an invalid key error, got %v", err)
+	}
+}
+
+func TestMemoryReplayCache(t *testing.T) {
+	t.Parallel()
+
+	var c MemoryReplayCache
+	now := time.Now()
+	if !c.Use("a", now.Add(-time.Second)) || !c.Use("b", now.Add(time.Hour)) {
+		t.Fatalf("expected fresh identifiers to be accepted")
+	}
+	if c.Use("b", now.Add(time.Hour)) {
+		t.Fatalf("expected a replayed identifier to be rejected")
+	}
+	if !c.Use("a", now.Add(time.Hour)) {
+		t.Fatalf("expected an expired identifier to be accepted again")
+	}
+	if len(c.used) != 2 || len(c.expires) != 2 {
+		t.Fatalf("expected 2 live entries, got %d and %d", len(c.used), len(c.expires))
+	}
+}
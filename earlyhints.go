@@ -0,0 +1,62 @@
+// Copyright 2026 Franklin "Snaipe" Mathieu.
+//
+// Use of this source code is governed by the MIT license that can be
+// found in the LICENSE file.
+
+package htutil
+
+import (
+	"net/http"
+)
+
+// PreloadLink returns a link to a resource that the client should fetch
+// early, as per the preload relation type of the HTML standard. as is the
+// destination of the resource, like "style", "script", "font", or "image".
+//
+// Fonts, and other resources fetched in CORS mode, also need a
+// "crossorigin" parameter.
+func PreloadLink(target, as string) Link {
+	return Link{Target: target, Rel: []string{"preload"}, Params: map[string]string{"as": as}}
+}
+
+// PreconnectLink returns a link to an origin that the client should connect
+// to early, as per the preconnect relation type of the HTML standard.
+func PreconnectLink(origin string) Link {
+	return Link{Target: origin, Rel: []string{"preconnect"}}
+}
+
+// WriteEarlyHints sends a 103 Early Hints informational response to req,
+// as per RFC 8297, carrying links in a Link header field, so that clients
+// may preload resources or preconnect to origins while the final response
+// is being prepared.
+//
+// The other header fields already set on w are not sent in the
+// informational response, and the same Link header field is added to the
+// final response, as clients may ignore early hints. WriteEarlyHints must
+// be called before the final response header is written, and only adds
+// the Link header field if the client does not support informational
+// responses, as HTTP/1.0 clients do not. The buffering middlewares of this
+// package, like Cache and Conditional, send early hints right away.
+func WriteEarlyHints(w http.ResponseWriter, req *http.Request, links ...Link) {
+	if len(links) == 0 {
+		return
+	}
+	link := FormatLink(links...)
+	h := w.Header()
+	if req.ProtoAtLeast(1, 1) {
+		saved := h.Clone()
+		for name := range h {
+			delete(h, name)
+		}
+		h.Set("Link", link)
+		w.WriteHeader(http.StatusEarlyHints)
+
+		for name := range h {
+			delete(h, name)
+		}
+		for name, values := range saved {
+			h[name] = values
+		}
+	}
+	h.Add("Link", link)
+}
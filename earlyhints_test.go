@@ -0,0 +1,128 @@
+// Copyright 2026 Franklin "Snaipe" Mathieu.
+//
+// Use of this source code is governed by the MIT license that can be
+// found in the LICENSE file.
+
+package htutil
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"net/http/httptrace"
+	"net/textproto"
+	"strings"
+	"testing"
+)
+
+func TestWriteEarlyHints(t *testing.T) {
+	t.Parallel()
+
+	const link = `</style.css>; rel="preload"; as=style, <https://cdn.example.com>; rel="preconnect"`
+
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+		w.Header().Set("Set-Cookie", "id=1")
+		WriteEarlyHints(w, req, PreloadLink("/style.css", "style"), PreconnectLink("https://cdn.example.com"))
+		w.Write([]byte("hello"))
+	}))
+	defer srv.Close()
+
+	var hints []textproto.MIMEHeader
+	trace := &httptrace.ClientTrace{
+		Got1xxResponse: func(code int, header textproto.MIMEHeader) error {
+			if code == http.StatusEarlyHints {
+				hints = append(hints, header)
+			}
+			return nil
+		},
+	}
+	req, err := http.NewRequestWithContext(httptrace.WithClientTrace(context.Background(), trace), "GET", srv.URL, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	resp, err := srv.Client().Do(req)
+	if err != nil {
+		t.Fatal(err)
+	}
+	resp.Body.Close()
+
+	if len(hints) != 1 {
+		t.Fatalf("expected one 103 response, got %d", len(hints))
+	}
+	if got := hints[0].Get("Link"); got != link {
+		t.Fatalf("expected early Link %q, got %q", link, got)
+	}
+	if got := hints[0].Get("Set-Cookie"); got != "" {
+		t.Fatalf("expected no Set-Cookie in early hints, got %q", got)
+	}
+	if got := resp.Header.Get("Link"); got != link {
+		t.Fatalf("expected final Link %q, got %q", link, got)
+	}
+	if got := resp.Header.Get("Set-Cookie"); got != "id=1" {
+		t.Fatalf("expected final Set-Cookie %q, got %q", "id=1", got)
+	}
+
+	// HTTP/1.0 clients only get the final Link header field.
+	req = httptest.NewRequest("GET", "/", nil)
+	req.Proto, req.ProtoMajor, req.ProtoMinor = "HTTP/1.0", 1, 0
+	rw := httptest.NewRecorder()
+	WriteEarlyHints(rw, req, PreloadLink("/style.css", "style"))
+	if rw.Code != http.StatusOK || rw.Header().Get("Link") != `</style.css>; rel="preload"; as=style` {
+		t.Fatalf("expected only a Link header field, got status %d and %v", rw.Code, rw.Header())
+	}
+}
+
+func TestEarlyHintsBuffered(t *testing.T) {
+	t.Parallel()
+
+	signing := &ResponseSigning{Signer: NewHMACSigner("k", []byte("secret"))}
+	tcases := []struct {
+		Wrap   func(http.Handler) http.Handler
+		Header string
+		Expect string
+	}{
+		{Wrap: (&Cache{}).Middleware, Header: "Cache-Status", Expect: "stored"},
+		{Wrap: func(next http.Handler) http.Handler { return Conditional(next, DigestETagger{}) }, Header: "ETag", Expect: `"`},
+		{Wrap: func(next http.Handler) http.Handler { return Coalesce(next) }, Header: "Link", Expect: "preload"},
+		{Wrap: signing.Middleware, Header: "Signature", Expect: "sig1"},
+	}
+
+	for i, tcase := range tcases {
+		t.Run(fmt.Sprintf("%d", i), func(t *testing.T) {
+			srv := httptest.NewServer(tcase.Wrap(http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+				w.Header().Set("Cache-Control", "max-age=60")
+				WriteEarlyHints(w, req, PreloadLink("/style.css", "style"))
+				w.Write([]byte("hello"))
+			})))
+			defer srv.Close()
+
+			var hints []textproto.MIMEHeader
+			trace := &httptrace.ClientTrace{
+				Got1xxResponse: func(code int, header textproto.MIMEHeader) error {
+					hints = append(hints, header)
+					return nil
+				},
+			}
+			req, err := http.NewRequestWithContext(httptrace.WithClientTrace(context.Background(), trace), "GET", srv.URL, nil)
+			if err != nil {
+				t.Fatal(err)
+			}
+			resp, err := srv.Client().Do(req)
+			if err != nil {
+				t.Fatal(err)
+			}
+			resp.Body.Close()
+
+			if len(hints) != 1 || hints[0].Get("Link") == "" || hints[0].Get("Cache-Control") != "" {
+				t.Fatalf("expected one 103 response with only a Link, got %v", hints)
+			}
+			if resp.StatusCode != http.StatusOK {
+				t.Fatalf("expected status 200, got %d", resp.StatusCode)
+			}
+			if got := resp.Header.Get(tcase.Header); !strings.Contains(got, tcase.Expect) {
+				t.Fatalf("expected %s containing %q, got %q", tcase.Header, tcase.Expect, got)
+			}
+		})
+	}
+}
@@ -0,0 +1,41 @@
+// Copyright 2026 Franklin "Snaipe" Mathieu.
+//
+// Use of this source code is governed by the MIT license that can be
+// found in the LICENSE file.
+
+package htutil
+
+import (
+	"html/template"
+	"sync"
+)
+
+var (
+	errorPagesMu sync.RWMutex
+	errorPages   = map[int]*template.Template{}
+)
+
+// RegisterErrorPage registers the HTML template rendered by RespondError
+// for browsers, on errors with the specified status. A status of 0
+// registers the page used for statuses without a page of their own.
+//
+// The template is executed with the Problem describing the error. A nil
+// template unregisters the page.
+func RegisterErrorPage(status int, tmpl *template.Template) {
+	errorPagesMu.Lock()
+	defer errorPagesMu.Unlock()
+	if tmpl == nil {
+		delete(errorPages, status)
+		return
+	}
+	errorPages[status] = tmpl
+}
+
+func errorPage(status int) *template.Template {
+	errorPagesMu.RLock()
+	defer errorPagesMu.RUnlock()
+	if tmpl, ok := errorPages[status]; ok {
+		return tmpl
+	}
+	return errorPages[0]
+}
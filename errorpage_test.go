@@ -0,0 +1,61 @@
+// Copyright 2026 Franklin "Snaipe" Mathieu.
+//
+// Use of this source code is governed by the MIT license that can be
+// found in the LICENSE file.
+
+package htutil
+
+import (
+	"fmt"
+	"html/template"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestRegisterErrorPage(t *testing.T) {
+	t.Parallel()
+
+	// 418 is not used by any other test, which may run concurrently.
+	RegisterErrorPage(418, template.Must(template.New("").Parse(
+		`<h1>{{.Title}}</h1><p>{{.Detail}}</p>`,
+	)))
+	defer RegisterErrorPage(418, nil)
+
+	tcases := []struct {
+		Accept      string
+		ContentType string
+		Body        string
+	}{
+		{
+			Accept:      "text/html,application/xhtml+xml,*/*;q=0.8",
+			ContentType: "text/html; charset=utf-8",
+			Body:        "<h1>I&#39;m a teapot</h1><p>&lt;short&gt; and stout</p>",
+		},
+		{
+			Accept:      "*/*",
+			ContentType: "text/plain; charset=utf-8",
+			Body:        "<short> and stout\n",
+		},
+		{
+			Accept:      "application/problem+json",
+			ContentType: "application/problem+json",
+			Body:        `{"title":"I'm a teapot","status":418,"detail":"\u003cshort\u003e and stout"}` + "\n",
+		},
+	}
+
+	for i, tcase := range tcases {
+		t.Run(fmt.Sprintf("%d", i), func(t *testing.T) {
+			req := httptest.NewRequest("GET", "/", nil)
+			req.Header.Set("Accept", tcase.Accept)
+			w := httptest.NewRecorder()
+			RespondError(w, req, NewProblem(418, "<short> and stout"))
+
+			if ct := w.Header().Get("Content-Type"); ct != tcase.ContentType {
+				t.Fatalf("expected content type %s, got %s", tcase.ContentType, ct)
+			}
+			if body := w.Body.String(); body != tcase.Body {
+				t.Fatalf("expected body %q, got %q", tcase.Body, body)
+			}
+		})
+	}
+}
@@ -0,0 +1,415 @@
+// Copyright 2026 Franklin "Snaipe" Mathieu.
+//
+// Use of this source code is governed by the MIT license that can be
+// found in the LICENSE file.
+
+package htutil
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/base64"
+	"fmt"
+	"io"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// ETag is an entity tag, as per RFC 9110 §8.8.3.
+//
+// The zero ETag stands for the absence of an entity tag.
+type ETag struct {
+	// Tag is the opaque tag, without quotes.
+	Tag string
+
+	// Weak is true for weak validators.
+	Weak bool
+}
+
+// ParseETag parses an entity tag in its quoted form, like "xyzzy" or
+// W/"xyzzy".
+func ParseETag(s string) (ETag, error) {
+	var etag ETag
+	s = trimOWS(s)
+	if strings.HasPrefix(s, "W/") {
+		etag.Weak = true
+		s = s[2:]
+	}
+	if len(s) < 2 || s[0] != '"' || s[len(s)-1] != '"' {
+		return ETag{}, fmt.Errorf("parsing entity tag: %q is not quoted", s)
+	}
+	etag.Tag = s[1 : len(s)-1]
+	for i := 0; i < len(etag.Tag); i++ {
+		if c := etag.Tag[i]; c == '"' || c < 0x21 || c == 0x7f {
+			return ETag{}, fmt.Errorf("parsing entity tag: invalid character %q", c)
+		}
+	}
+	return etag, nil
+}
+
+// String returns the quoted form of etag, or "" for the zero ETag.
+func (etag ETag) String() string {
+	if etag == (ETag{}) {
+		return ""
+	}
+	if etag.Weak {
+		return `W/"` + etag.Tag + `"`
+	}
+	return `"` + etag.Tag + `"`
+}
+
+// IsZero returns whether etag is the zero ETag.
+func (etag ETag) IsZero() bool {
+	return etag == ETag{}
+}
+
+func (etag ETag) MarshalText() ([]byte, error) {
+	return []byte(etag.String()), nil
+}
+
+func (etag *ETag) UnmarshalText(text []byte) error {
+	v, err := ParseETag(string(text))
+	if err != nil {
+		return err
+	}
+	*etag = v
+	return nil
+}
+
+// StrongMatch returns whether etag and other match with the strong
+// comparison function of RFC 9110 §8.8.3.2: both must be strong and equal.
+func (etag ETag) StrongMatch(other ETag) bool {
+	return !etag.IsZero() && !etag.Weak && !other.Weak && etag.Tag == other.Tag
+}
+
+// WeakMatch returns whether etag and other match with the weak comparison
+// function of RFC 9110 §8.8.3.2: their tags must be equal, regardless of
+// their weakness.
+func (etag ETag) WeakMatch(other ETag) bool {
+	return !etag.IsZero() && etag.Tag == other.Tag
+}
+
+// ETagList is the value of an If-Match or If-None-Match field: either "*",
+// matching any current representation, or a list of entity tags.
+type ETagList struct {
+	// Any is true for "*".
+	Any bool
+
+	// Tags are the listed entity tags.
+	Tags []ETag
+}
+
+// ParseETagList parses the values of an If-Match or If-None-Match field.
+//
+// Entity tags may contain commas, so the values cannot be split with
+// SplitList.
+func ParseETagList(values ...string) (ETagList, error) {
+	var list ETagList
+	for _, v := range values {
+		if trimOWS(v) == "*" {
+			list.Any = true
+			continue
+		}
+		for i := 0; ; {
+			for i < len(v) && (v[i] == ',' || isOWS(v[i])) {
+				i++
+			}
+			if i == len(v) {
+				break
+			}
+			start := i
+			if strings.HasPrefix(v[i:], "W/") {
+				i += 2
+			}
+			if i == len(v) || v[i] != '"' {
+				return ETagList{}, fmt.Errorf("parsing entity tag list: expected entity tag at offset %d", start)
+			}
+			end := strings.IndexByte(v[i+1:], '"')
+			if end == -1 {
+				return ETagList{}, fmt.Errorf("parsing entity tag list: unterminated entity tag at offset %d", start)
+			}
+			i += end + 2
+			etag, err := ParseETag(v[start:i])
+			if err != nil {
+				return ETagList{}, fmt.Errorf("parsing entity tag list: %w", err)
+			}
+			list.Tags = append(list.Tags, etag)
+			if i < len(v) && v[i] != ',' && !isOWS(v[i]) {
+				return ETagList{}, fmt.Errorf("parsing entity tag list: expected ',' at offset %d", i)
+			}
+		}
+	}
+	return list, nil
+}
+
+// IsZero returns whether list is empty, as when the field is absent.
+func (list ETagList) IsZero() bool {
+	return !list.Any && len(list.Tags) == 0
+}
+
+// MatchStrong returns whether etag, the entity tag of a current
+// representation, is matched by list with the strong comparison function,
+// as for If-Match.
+func (list ETagList) MatchStrong(etag ETag) bool {
+	if list.Any {
+		return true
+	}
+	for _, tag := range list.Tags {
+		if tag.StrongMatch(etag) {
+			return true
+		}
+	}
+	return false
+}
+
+// MatchWeak returns whether etag, the entity tag of a current
+// representation, is matched by list with the weak comparison function,
+// as for If-None-Match.
+func (list ETagList) MatchWeak(etag ETag) bool {
+	if list.Any {
+		return true
+	}
+	for _, tag := range list.Tags {
+		if tag.WeakMatch(etag) {
+			return true
+		}
+	}
+	return false
+}
+
+// Entity describes a representation for which to generate an entity tag.
+type Entity struct {
+	// Content is the content of the representation, or nil if it is not
+	// available. ETaggers reading it must seek it back to its start.
+	Content io.ReadSeeker
+
+	// Size is the size of the content. ServeEntity determines it from
+	// Content if zero.
+	Size int64
+
+	// ModTime is the last modification time of the representation, or
+	// zero if unknown.
+	ModTime time.Time
+}
+
+// ETagger generates entity tags for the representations of resources.
+type ETagger interface {
+	// ETag returns the entity tag of the representation e, selected for
+	// req. It returns the zero ETag if it cannot tag the representation.
+	ETag(req *http.Request, e *Entity) (ETag, error)
+}
+
+// DigestETagger generates strong entity tags from the SHA-256 digest of
+// the content of representations.
+type DigestETagger struct{}
+
+func (DigestETagger) ETag(req *http.Request, e *Entity) (ETag, error) {
+	if e.Content == nil {
+		return ETag{}, nil
+	}
+	etag, err := digestETag(e.Content)
+	if err != nil {
+		return ETag{}, err
+	}
+	if _, err := e.Content.Seek(0, io.SeekStart); err != nil {
+		return ETag{}, err
+	}
+	return etag, nil
+}
+
+// digestETag returns the strong entity tag of the content of r, from its
+// SHA-256 digest.
+func digestETag(r io.Reader) (ETag, error) {
+	h := sha256.New()
+	if _, err := io.Copy(h, r); err != nil {
+		return ETag{}, fmt.Errorf("hashing content: %w", err)
+	}
+	return ETag{Tag: base64.RawURLEncoding.EncodeToString(h.Sum(nil))}, nil
+}
+
+// ModTimeETagger generates weak entity tags from the size and last
+// modification time of representations, in the fashion of file servers.
+// They are cheap to compute, but may not change if the content changes
+// without affecting its size within the resolution of the modification
+// time, hence their weakness.
+type ModTimeETagger struct{}
+
+func (ModTimeETagger) ETag(req *http.Request, e *Entity) (ETag, error) {
+	return modTimeETag(e.ModTime, e.Size), nil
+}
+
+// modTimeETag returns the weak entity tag of content of the specified size
+// and modification time, or the zero ETag if modtime is zero.
+func modTimeETag(modtime time.Time, size int64) ETag {
+	if modtime.IsZero() {
+		return ETag{}
+	}
+	tag := strconv.FormatInt(modtime.UnixNano(), 16) + "-" + strconv.FormatInt(size, 16)
+	return ETag{Tag: tag, Weak: true}
+}
+
+// VersionETagger generates strong entity tags from the version of the
+// target resource of a request, as returned by the function, for resources
+// that keep a version counter incremented on each modification.
+type VersionETagger func(req *http.Request) (uint64, error)
+
+func (fn VersionETagger) ETag(req *http.Request, e *Entity) (ETag, error) {
+	version, err := fn(req)
+	if err != nil {
+		return ETag{}, err
+	}
+	return ETag{Tag: "v" + strconv.FormatUint(version, 10)}, nil
+}
+
+// Conditional returns a middleware that tags the successful responses of
+// next to GET and HEAD requests with an entity tag generated by tagger,
+// unless they already have one, and evaluates the conditional requests
+// against them, as per RFC 9110 §13.2.2.
+//
+// Responses are buffered in full, unless they are streamed with a Stream.
+// Fresh representations are answered with 304 Not Modified, and failed
+// If-Match preconditions with 412 Precondition Failed.
+func Conditional(next http.Handler, tagger ETagger) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+		if req.Method != http.MethodGet && req.Method != http.MethodHead {
+			next.ServeHTTP(w, req)
+			return
+		}
+
+		buf, req := newBypassableBuffer(w, req)
+		next.ServeHTTP(buf, req)
+		if buf.bypassed {
+			return
+		}
+		if buf.status != 0 && buf.status != http.StatusOK {
+			buf.replay(w, req)
+			return
+		}
+
+		h := buf.header
+		modtime, _ := http.ParseTime(h.Get("Last-Modified"))
+		if h.Get("ETag") == "" {
+			etag, err := tagger.ETag(req, &Entity{
+				Content: bytes.NewReader(buf.body.Bytes()),
+				Size:    int64(buf.body.Len()),
+				ModTime: modtime,
+			})
+			if err != nil {
+				RespondError(w, req, err)
+				return
+			}
+			if !etag.IsZero() {
+				h.Set("ETag", etag.String())
+			}
+		}
+
+		etag, _ := ParseETag(h.Get("ETag"))
+		switch EvaluateConditionals(req, etag, modtime) {
+		case http.StatusNotModified:
+			NotModified(w, h)
+		case http.StatusPreconditionFailed:
+			RespondError(w, req, NewProblem(http.StatusPreconditionFailed,
+				"The resource was modified since it was last retrieved."))
+		default:
+			buf.replay(w, req)
+		}
+	})
+}
+
+// EvaluateConditionals evaluates the preconditions of req against the
+// entity tag and last modification date of the current representation of
+// its target resource, in the order of RFC 9110 §13.2.2, and returns the
+// status with which to respond: 304 Not Modified, 412 Precondition Failed,
+// or 0 if the request should proceed.
+//
+// The target resource is assumed to have a current representation, and
+// either validator may be zero if the representation does not have it.
+// An If-Match that cannot be parsed fails, and the other fields that cannot
+// be parsed are ignored, as are the date-based preconditions when their
+// entity tag counterparts are present.
+func EvaluateConditionals(req *http.Request, etag ETag, lastModified time.Time) int {
+	lastModified = lastModified.Truncate(time.Second)
+	safe := req.Method == http.MethodGet || req.Method == http.MethodHead
+
+	if values := req.Header.Values("If-Match"); len(values) > 0 {
+		// An If-Match that cannot be parsed matches nothing, like in
+		// RequirePreconditions: proceeding could overwrite a concurrent update.
+		ifMatch, err := ParseETagList(values...)
+		if err != nil || !ifMatch.MatchStrong(etag) {
+			return http.StatusPreconditionFailed
+		}
+	} else if since, err := http.ParseTime(req.Header.Get("If-Unmodified-Since")); err == nil && !lastModified.IsZero() {
+		if lastModified.After(since) {
+			return http.StatusPreconditionFailed
+		}
+	}
+
+	ifNoneMatch, _ := ParseETagList(req.Header.Values("If-None-Match")...)
+	if !ifNoneMatch.IsZero() {
+		if ifNoneMatch.MatchWeak(etag) {
+			if safe {
+				return http.StatusNotModified
+			}
+			return http.StatusPreconditionFailed
+		}
+	} else if since, err := http.ParseTime(req.Header.Get("If-Modified-Since")); err == nil && safe && !lastModified.IsZero() {
+		if !lastModified.After(since) {
+			return http.StatusNotModified
+		}
+	}
+	return 0
+}
+
+// ServeEntity replies to req with the content of e, tagged with an entity
+// tag generated by tagger, using http.ServeContent. Content-Type and
+// Last-Modified are set from name and e.ModTime, as for http.ServeContent,
+// and range requests are handled accordingly. An entity without Content is
+// served with an empty body.
+//
+// Conditional requests are evaluated with EvaluateConditionals, like in
+// Conditional: fresh representations are answered with 304 Not Modified,
+// and failed preconditions with a 412 Precondition Failed problem.
+func ServeEntity(w http.ResponseWriter, req *http.Request, name string, e *Entity, tagger ETagger) {
+	if e.Size == 0 && e.Content != nil {
+		size, err := e.Content.Seek(0, io.SeekEnd)
+		if err == nil {
+			_, err = e.Content.Seek(0, io.SeekStart)
+		}
+		if err != nil {
+			RespondError(w, req, err)
+			return
+		}
+		e.Size = size
+	}
+	if w.Header().Get("ETag") == "" {
+		etag, err := tagger.ETag(req, e)
+		if err != nil {
+			RespondError(w, req, err)
+			return
+		}
+		if !etag.IsZero() {
+			w.Header().Set("ETag", etag.String())
+		}
+	}
+
+	etag, _ := ParseETag(w.Header().Get("ETag"))
+	switch EvaluateConditionals(req, etag, e.ModTime) {
+	case http.StatusNotModified:
+		if !e.ModTime.IsZero() && !e.ModTime.Equal(time.Unix(0, 0)) {
+			w.Header().Set("Last-Modified", e.ModTime.UTC().Format(http.TimeFormat))
+		}
+		NotModified(w, w.Header())
+		return
+	case http.StatusPreconditionFailed:
+		RespondError(w, req, NewProblem(http.StatusPreconditionFailed,
+			"The resource was modified since it was last retrieved."))
+		return
+	}
+	content := e.Content
+	if content == nil {
+		content = strings.NewReader("")
+	}
+	http.ServeContent(w, req, name, e.ModTime, content)
+}
@@ -0,0 +1,312 @@
+// Copyright 2026 Franklin "Snaipe" Mathieu.
+//
+// Use of this source code is governed by the MIT license that can be
+// found in the LICENSE file.
+
+package htutil
+
+import (
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestParseETag(t *testing.T) {
+	t.Parallel()
+
+	tcases := []struct {
+		Value string
+		ETag  ETag
+		Err   bool
+	}{
+		{Value: `"xyzzy"`, ETag: ETag{Tag: "xyzzy"}},
+		{Value: `W/"xyzzy"`, ETag: ETag{Tag: "xyzzy", Weak: true}},
+		{Value: `""`, ETag: ETag{}},
+		{Value: `xyzzy`, Err: true},
+		{Value: `"xy"zzy"`, Err: true},
+		{Value: `"xy zzy"`, Err: true},
+		{Value: `w/"xyzzy"`, Err: true},
+	}
+
+	for i, tcase := range tcases {
+		t.Run(fmt.Sprintf("%d", i), func(t *testing.T) {
+			etag, err := ParseETag(tcase.Value)
+			if (err != nil) != tcase.Err {
+				t.Fatalf("expected error %v, got %v", tcase.Err, err)
+			}
+			if err != nil {
+				return
+			}
+			if etag != tcase.ETag {
+				t.Fatalf("expected %v, got %v", tcase.ETag, etag)
+			}
+			if tcase.Value != `""` && etag.String() != tcase.Value {
+				t.Fatalf("expected %v, got %v", tcase.Value, etag.String())
+			}
+		})
+	}
+
+	h := http.Header{}
+	if err := SetHeader(h, "ETag", ETag{Tag: "v1", Weak: true}); err != nil {
+		t.Fatal(err)
+	}
+	if etag, err := GetHeader[ETag](h, "ETag"); err != nil || etag != (ETag{Tag: "v1", Weak: true}) {
+		t.Fatalf("expected W/\"v1\", got %v (%v)", etag, err)
+	}
+}
+
+func TestParseETagList(t *testing.T) {
+	t.Parallel()
+
+	tcases := []struct {
+		Values []string
+		List   ETagList
+		Err    bool
+	}{
+		{Values: nil, List: ETagList{}},
+		{Values: []string{"*"}, List: ETagList{Any: true}},
+		{Values: []string{`"a", W/"b"`}, List: ETagList{Tags: []ETag{{Tag: "a"}, {Tag: "b", Weak: true}}}},
+		{Values: []string{`"a,b"`, ` "c" ,, `}, List: ETagList{Tags: []ETag{{Tag: "a,b"}, {Tag: "c"}}}},
+		{Values: []string{`"a\"`}, List: ETagList{Tags: []ETag{{Tag: `a\`}}}},
+		{Values: []string{`"a"W/"b"`}, Err: true},
+		{Values: []string{`a`}, Err: true},
+		{Values: []string{`"a`}, Err: true},
+		{Values: []string{`"a", *`}, Err: true},
+	}
+
+	for i, tcase := range tcases {
+		t.Run(fmt.Sprintf("%d", i), func(t *testing.T) {
+			list, err := ParseETagList(tcase.Values...)
+			if (err != nil) != tcase.Err {
+				t.Fatalf("expected error %v, got %v", tcase.Err, err)
+			}
+			if fmt.Sprint(list) != fmt.Sprint(tcase.List) {
+				t.Fatalf("expected %v, got %v", tcase.List, list)
+			}
+		})
+	}
+}
+
+func TestEvaluateConditionals(t *testing.T) {
+	t.Parallel()
+
+	modtime := time.Date(2026, 1, 2, 3, 4, 5, 600, time.UTC)
+	before := modtime.Add(-time.Hour).Format(http.TimeFormat)
+	after := modtime.Add(time.Hour).Format(http.TimeFormat)
+	etag := ETag{Tag: "v2"}
+
+	tcases := []struct {
+		Method string
+		Header map[string]string
+		ETag   ETag
+		Expect int
+	}{
+		{Method: "GET", Expect: 0},
+		{Method: "GET", Header: map[string]string{"If-None-Match": `"v1", "v2"`}, Expect: http.StatusNotModified},
+		{Method: "GET", Header: map[string]string{"If-None-Match": `W/"v2"`}, Expect: http.StatusNotModified},
+		{Method: "GET", Header: map[string]string{"If-None-Match": `"v1"`}, Expect: 0},
+		{Method: "GET", Header: map[string]string{"If-None-Match": `*`}, Expect: http.StatusNotModified},
+		{Method: "HEAD", Header: map[string]string{"If-Modified-Since": modtime.Format(http.TimeFormat)}, Expect: http.StatusNotModified},
+		{Method: "GET", Header: map[string]string{"If-Modified-Since": before}, Expect: 0},
+		{Method: "GET", Header: map[string]string{"If-Modified-Since": after, "If-None-Match": `"v1"`}, Expect: 0},
+		{Method: "POST", Header: map[string]string{"If-Modified-Since": after}, Expect: 0},
+		{Method: "PUT", Header: map[string]string{"If-None-Match": `*`}, Expect: http.StatusPreconditionFailed},
+		{Method: "PUT", Header: map[string]string{"If-Match": `"v2"`}, Expect: 0},
+		{Method: "PUT", Header: map[string]string{"If-Match": `W/"v2"`}, Expect: http.StatusPreconditionFailed},
+		{Method: "PUT", Header: map[string]string{"If-Match": `"v2"`}, ETag: ETag{Tag: "v2", Weak: true}, Expect: http.StatusPreconditionFailed},
+		{Method: "PUT", Header: map[string]string{"If-Match": `*`}, Expect: 0},
+		{Method: "PUT", Header: map[string]string{"If-Unmodified-Since": before}, Expect: http.StatusPreconditionFailed},
+		{Method: "PUT", Header: map[string]string{"If-Unmodified-Since": before, "If-Match": `"v2"`}, Expect: 0},
+		{Method: "GET", Header: map[string]string{"If-Match": `"v1"`, "If-None-Match": `"v2"`}, Expect: http.StatusPreconditionFailed},
+		{Method: "GET", Header: map[string]string{"If-Match": `"v2"`, "If-None-Match": `"v2"`}, Expect: http.StatusNotModified},
+		{Method: "GET", Header: map[string]string{"If-None-Match": `"v2`}, Expect: 0},
+		{Method: "PUT", Header: map[string]string{"If-Match": `"v2`}, Expect: http.StatusPreconditionFailed},
+		{Method: "PUT", Header: map[string]string{"If-Match": `v2`, "If-Unmodified-Since": after}, Expect: http.StatusPreconditionFailed},
+	}
+
+	for i, tcase := range tcases {
+		t.Run(fmt.Sprintf("%d", i), func(t *testing.T) {
+			req := httptest.NewRequest(tcase.Method, "/", nil)
+			for k, v := range tcase.Header {
+				req.Header.Set(k, v)
+			}
+			tag := tcase.ETag
+			if tag.IsZero() {
+				tag = etag
+			}
+			if status := EvaluateConditionals(req, tag, modtime); status != tcase.Expect {
+				t.Fatalf("expected %d, got %d", tcase.Expect, status)
+			}
+		})
+	}
+}
+
+func TestETaggers(t *testing.T) {
+	t.Parallel()
+
+	modtime := time.Date(2026, 1, 2, 3, 4, 5, 0, time.UTC)
+	req := httptest.NewRequest("GET", "/", nil)
+
+	tcases := []struct {
+		Tagger ETagger
+		Entity Entity
+		ETag   string
+	}{
+		{
+			Tagger: DigestETagger{},
+			Entity: Entity{Content: strings.NewReader("hello")},
+			ETag:   `"LPJNul-wow4m6DsqxbninhsWHlwfp0JecwQzYpOLmCQ"`,
+		},
+		{
+			Tagger: DigestETagger{},
+			Entity: Entity{ModTime: modtime},
+			ETag:   "",
+		},
+		{
+			Tagger: ModTimeETagger{},
+			Entity: Entity{Size: 5, ModTime: modtime},
+			ETag:   `W/"1886caf21c963200-5"`,
+		},
+		{
+			Tagger: ModTimeETagger{},
+			Entity: Entity{Size: 5},
+			ETag:   "",
+		},
+		{
+			Tagger: VersionETagger(func(req *http.Request) (uint64, error) { return 42, nil }),
+			ETag:   `"v42"`,
+		},
+	}
+
+	for i, tcase := range tcases {
+		t.Run(fmt.Sprintf("%d", i), func(t *testing.T) {
+			etag, err := tcase.Tagger.ETag(req, &tcase.Entity)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if etag.String() != tcase.ETag {
+				t.Fatalf("expected %v, got %v", tcase.ETag, etag)
+			}
+		})
+	}
+}
+
+func TestConditional(t *testing.T) {
+	t.Parallel()
+
+	lastModified := "Fri, 02 Jan 2026 03:04:05 GMT"
+	handler := Conditional(http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+		if req.URL.Path == "/missing" {
+			http.NotFound(w, req)
+			return
+		}
+		w.Header().Set("Content-Type", "text/plain")
+		w.Header().Set("Last-Modified", lastModified)
+		w.Header().Set("Cache-Control", "max-age=60")
+		fmt.Fprint(w, "hello")
+	}), DigestETagger{})
+
+	const etag = `"LPJNul-wow4m6DsqxbninhsWHlwfp0JecwQzYpOLmCQ"`
+
+	tcases := []struct {
+		Method string
+		Path   string
+		Header http.Header
+		Status int
+		Body   string
+	}{
+		{Method: "GET", Path: "/", Status: 200, Body: "hello"},
+		{Method: "GET", Path: "/", Header: http.Header{"If-None-Match": {etag}}, Status: 304},
+		{Method: "HEAD", Path: "/", Header: http.Header{"If-None-Match": {`"other", W/` + etag}}, Status: 304},
+		{Method: "GET", Path: "/", Header: http.Header{"If-None-Match": {`"other"`}}, Status: 200, Body: "hello"},
+		{Method: "GET", Path: "/", Header: http.Header{"If-Modified-Since": {lastModified}}, Status: 304},
+		{Method: "GET", Path: "/", Header: http.Header{"If-None-Match": {`"other"`}, "If-Modified-Since": {lastModified}}, Status: 200, Body: "hello"},
+		{Method: "GET", Path: "/", Header: http.Header{"If-Match": {`"other"`}}, Status: 412},
+		{Method: "GET", Path: "/missing", Header: http.Header{"If-None-Match": {"*"}}, Status: 404, Body: "404 page not found\n"},
+	}
+
+	for i, tcase := range tcases {
+		t.Run(fmt.Sprintf("%d", i), func(t *testing.T) {
+			req := httptest.NewRequest(tcase.Method, tcase.Path, nil)
+			for k, v := range tcase.Header {
+				req.Header[k] = v
+			}
+			w := httptest.NewRecorder()
+			handler.ServeHTTP(w, req)
+
+			if w.Code != tcase.Status {
+				t.Fatalf("expected status %d, got %d", tcase.Status, w.Code)
+			}
+			if tcase.Status == 200 || tcase.Status == 304 {
+				if v := w.Header().Get("ETag"); v != etag {
+					t.Fatalf("expected ETag %v, got %v", etag, v)
+				}
+				if v := w.Header().Get("Cache-Control"); v != "max-age=60" {
+					t.Fatalf("expected Cache-Control max-age=60, got %v", v)
+				}
+			}
+			if tcase.Status == 304 && w.Header().Get("Content-Type") != "" {
+				t.Fatalf("expected no Content-Type, got %v", w.Header().Get("Content-Type"))
+			}
+			if tcase.Status != 412 && w.Body.String() != tcase.Body {
+				t.Fatalf("expected body %q, got %q", tcase.Body, w.Body.String())
+			}
+		})
+	}
+}
+
+func TestServeEntity(t *testing.T) {
+	t.Parallel()
+
+	modtime := time.Date(2026, 1, 2, 3, 4, 5, 0, time.UTC)
+
+	tcases := []struct {
+		Header http.Header
+		Status int
+		Body   string
+	}{
+		{Status: 200, Body: "hello, world"},
+		{Header: http.Header{"If-None-Match": {`W/"1886caf21c963200-c"`}}, Status: 304},
+		{Header: http.Header{"Range": {"bytes=0-4"}}, Status: 206, Body: "hello"},
+		{Header: http.Header{"Range": {"bytes=0-4"}, "If-Range": {`W/"1886caf21c963200-c"`}}, Status: 200, Body: "hello, world"},
+	}
+
+	for i, tcase := range tcases {
+		t.Run(fmt.Sprintf("%d", i), func(t *testing.T) {
+			req := httptest.NewRequest("GET", "/", nil)
+			for k, v := range tcase.Header {
+				req.Header[k] = v
+			}
+			w := httptest.NewRecorder()
+			ServeEntity(w, req, "hello.txt", &Entity{
+				Content: strings.NewReader("hello, world"),
+				ModTime: modtime,
+			}, ModTimeETagger{})
+
+			if w.Code != tcase.Status {
+				t.Fatalf("expected status %d, got %d", tcase.Status, w.Code)
+			}
+			if v := w.Header().Get("ETag"); v != `W/"1886caf21c963200-c"` {
+				t.Fatalf("expected ETag %v, got %v", `W/"1886caf21c963200-c"`, v)
+			}
+			if w.Body.String() != tcase.Body {
+				t.Fatalf("expected body %q, got %q", tcase.Body, w.Body.String())
+			}
+		})
+	}
+}
+
+func TestServeEntityNoContent(t *testing.T) {
+	t.Parallel()
+
+	for _, tagger := range []ETagger{ModTimeETagger{}, DigestETagger{}} {
+		w := httptest.NewRecorder()
+		ServeEntity(w, httptest.NewRequest("GET", "/", nil), "empty.txt", &Entity{}, tagger)
+		if w.Code != http.StatusOK || w.Body.Len() != 0 {
+			t.Fatalf("expected an empty 200 response, got %d %q", w.Code, w.Body.String())
+		}
+	}
+}
@@ -0,0 +1,173 @@
+// Copyright 2026 Franklin "Snaipe" Mathieu.
+//
+// Use of this source code is governed by the MIT license that can be
+// found in the LICENSE file.
+
+package htutil
+
+import (
+	"container/list"
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"net/http"
+	"sync"
+	"time"
+)
+
+// ReaderAtETag returns the strong entity tag of the size bytes of content
+// of r, from their SHA-256 digest, as generated by DigestETagger.
+func ReaderAtETag(r io.ReaderAt, size int64) (ETag, error) {
+	return digestETag(io.NewSectionReader(r, 0, size))
+}
+
+// FileInfoETag returns the weak entity tag of the file described by fi,
+// from its size and last modification time, as generated by
+// ModTimeETagger. It returns the zero ETag if fi has no modification time.
+func FileInfoETag(fi fs.FileInfo) ETag {
+	return modTimeETag(fi.ModTime(), fi.Size())
+}
+
+// CachingETagger caches the entity tags generated by another ETagger, which
+// are only generated again when the size or the modification time of the
+// representation changes. It makes strong entity tags from the digests of
+// large contents affordable, as long as their modification times are
+// reliable.
+//
+// Representations without a modification time are tagged every time.
+type CachingETagger struct {
+	// Tagger generates the entity tags to cache. Defaults to
+	// DigestETagger.
+	Tagger ETagger
+
+	// Key returns the cache key of the representation selected for req.
+	// Defaults to the path of the request URL.
+	Key func(req *http.Request) string
+
+	// MaxEntries is the maximum number of cached entity tags, beyond which
+	// the least recently used ones are evicted. Defaults to 1024.
+	MaxEntries int
+
+	mu      sync.Mutex
+	lru     *list.List
+	entries map[string]*list.Element
+}
+
+type etagCacheEntry struct {
+	key     string
+	size    int64
+	modtime time.Time
+	etag    ETag
+}
+
+func (c *CachingETagger) ETag(req *http.Request, e *Entity) (ETag, error) {
+	tagger := c.Tagger
+	if tagger == nil {
+		tagger = DigestETagger{}
+	}
+	if e.ModTime.IsZero() {
+		return tagger.ETag(req, e)
+	}
+	key := req.URL.Path
+	if c.Key != nil {
+		key = c.Key(req)
+	}
+
+	c.mu.Lock()
+	if elem, ok := c.entries[key]; ok {
+		entry := elem.Value.(*etagCacheEntry)
+		if entry.size == e.Size && entry.modtime.Equal(e.ModTime) {
+			c.lru.MoveToFront(elem)
+			c.mu.Unlock()
+			return entry.etag, nil
+		}
+	}
+	c.mu.Unlock()
+
+	etag, err := tagger.ETag(req, e)
+	if err != nil {
+		return ETag{}, err
+	}
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if c.entries == nil {
+		c.lru = list.New()
+		c.entries = make(map[string]*list.Element)
+	}
+	entry := &etagCacheEntry{key: key, size: e.Size, modtime: e.ModTime, etag: etag}
+	if elem, ok := c.entries[key]; ok {
+		elem.Value = entry
+		c.lru.MoveToFront(elem)
+		return etag, nil
+	}
+	c.entries[key] = c.lru.PushFront(entry)
+
+	limit := c.MaxEntries
+	if limit <= 0 {
+		limit = 1024
+	}
+	for c.lru.Len() > limit {
+		oldest := c.lru.Back()
+		c.lru.Remove(oldest)
+		delete(c.entries, oldest.Value.(*etagCacheEntry).key)
+	}
+	return etag, nil
+}
+
+// FileEntity returns the Entity of the file f, whose content is f itself if
+// it is an io.ReadSeeker, or a section of it if it is an io.ReaderAt.
+func FileEntity(f fs.File) (*Entity, error) {
+	fi, err := f.Stat()
+	if err != nil {
+		return nil, err
+	}
+	e := &Entity{Size: fi.Size(), ModTime: fi.ModTime()}
+	switch f := f.(type) {
+	case io.ReadSeeker:
+		e.Content = f
+	case io.ReaderAt:
+		e.Content = io.NewSectionReader(f, 0, fi.Size())
+	default:
+		return nil, fmt.Errorf("file %s cannot seek", fi.Name())
+	}
+	return e, nil
+}
+
+// ServeFS replies to req with the content of the file name of fsys, tagged
+// with an entity tag generated by tagger, as per ServeEntity.
+//
+// Missing files and directories are answered with a 404 Not Found problem,
+// and files that cannot be read with a 403 Forbidden problem.
+func ServeFS(w http.ResponseWriter, req *http.Request, fsys fs.FS, name string, tagger ETagger) {
+	f, err := fsys.Open(name)
+	if err != nil {
+		RespondError(w, req, fsProblem(err))
+		return
+	}
+	defer f.Close()
+
+	if fi, err := f.Stat(); err == nil && fi.IsDir() {
+		RespondError(w, req, fsProblem(fs.ErrNotExist))
+		return
+	}
+	e, err := FileEntity(f)
+	if err != nil {
+		RespondError(w, req, fsProblem(err))
+		return
+	}
+	ServeEntity(w, req, name, e, tagger)
+}
+
+// fsProblem returns the problem describing the file system error err, or
+// err itself if it has no HTTP counterpart.
+func fsProblem(err error) error {
+	switch {
+	case errors.Is(err, fs.ErrNotExist):
+		return NewProblem(http.StatusNotFound, "The file does not exist.")
+	case errors.Is(err, fs.ErrPermission):
+		return NewProblem(http.StatusForbidden, "The file cannot be read.")
+	}
+	return err
+}
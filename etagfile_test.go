@@ -0,0 +1,137 @@
+// Copyright 2026 Franklin "Snaipe" Mathieu.
+//
+// Use of this source code is governed by the MIT license that can be
+// found in the LICENSE file.
+
+package htutil
+
+import (
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"testing/fstest"
+	"time"
+)
+
+func TestFileETags(t *testing.T) {
+	t.Parallel()
+
+	modtime := time.Date(2026, 1, 2, 3, 4, 5, 0, time.UTC)
+	fsys := fstest.MapFS{"hello.txt": {Data: []byte("hello"), ModTime: modtime}}
+	fi, err := fsys.Stat("hello.txt")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if etag := FileInfoETag(fi); etag.String() != `W/"1886caf21c963200-5"` {
+		t.Fatalf("expected %v, got %v", `W/"1886caf21c963200-5"`, etag)
+	}
+
+	etag, err := ReaderAtETag(strings.NewReader("hello, world"), 5)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if etag.String() != `"LPJNul-wow4m6DsqxbninhsWHlwfp0JecwQzYpOLmCQ"` {
+		t.Fatalf("expected %v, got %v", `"LPJNul-wow4m6DsqxbninhsWHlwfp0JecwQzYpOLmCQ"`, etag)
+	}
+}
+
+// countingETagger counts the entity tags it generates.
+type countingETagger struct {
+	calls int
+}
+
+func (c *countingETagger) ETag(req *http.Request, e *Entity) (ETag, error) {
+	c.calls++
+	return ETag{Tag: fmt.Sprint(c.calls)}, nil
+}
+
+func TestCachingETagger(t *testing.T) {
+	t.Parallel()
+
+	modtime := time.Date(2026, 1, 2, 3, 4, 5, 0, time.UTC)
+	counter := &countingETagger{}
+	tagger := &CachingETagger{Tagger: counter, MaxEntries: 2}
+
+	tcases := []struct {
+		Path    string
+		Size    int64
+		ModTime time.Time
+		ETag    string
+	}{
+		{Path: "/a", Size: 1, ModTime: modtime, ETag: `"1"`},
+		{Path: "/a", Size: 1, ModTime: modtime, ETag: `"1"`},
+		{Path: "/a", Size: 2, ModTime: modtime, ETag: `"2"`},
+		{Path: "/a", Size: 2, ModTime: modtime.Add(time.Second), ETag: `"3"`},
+		{Path: "/a", Size: 2, ModTime: modtime.Add(time.Second), ETag: `"3"`},
+		{Path: "/b", Size: 1, ModTime: modtime, ETag: `"4"`},
+		{Path: "/c", Size: 1, ModTime: modtime, ETag: `"5"`},
+		{Path: "/b", Size: 1, ModTime: modtime, ETag: `"4"`},
+		{Path: "/a", Size: 2, ModTime: modtime.Add(time.Second), ETag: `"6"`},
+		{Path: "/d", Size: 1, ETag: `"7"`},
+		{Path: "/d", Size: 1, ETag: `"8"`},
+	}
+
+	for i, tcase := range tcases {
+		req := httptest.NewRequest("GET", tcase.Path, nil)
+		etag, err := tagger.ETag(req, &Entity{Size: tcase.Size, ModTime: tcase.ModTime})
+		if err != nil {
+			t.Fatal(err)
+		}
+		if etag.String() != tcase.ETag {
+			t.Fatalf("step %d: expected %v, got %v", i, tcase.ETag, etag)
+		}
+	}
+}
+
+func TestServeFS(t *testing.T) {
+	t.Parallel()
+
+	modtime := time.Date(2026, 1, 2, 3, 4, 5, 0, time.UTC)
+	fsys := fstest.MapFS{
+		"hello.txt":  {Data: []byte("hello, world"), ModTime: modtime},
+		"dir/nested": {Data: []byte("nested"), ModTime: modtime},
+	}
+	const etag = `"Ccp-TqpuiunH0mEWcSkYSINkTQffuny_vEyKLgg2DVs"`
+
+	tcases := []struct {
+		Name   string
+		Header http.Header
+		Status int
+		Body   string
+	}{
+		{Name: "hello.txt", Status: 200, Body: "hello, world"},
+		{Name: "hello.txt", Header: http.Header{"If-None-Match": {etag}}, Status: 304},
+		{Name: "hello.txt", Header: http.Header{"If-Match": {`"other"`}}, Status: 412},
+		{Name: "hello.txt", Header: http.Header{"If-Modified-Since": {modtime.Format(http.TimeFormat)}}, Status: 304},
+		{Name: "hello.txt", Header: http.Header{"Range": {"bytes=7-"}, "If-Range": {etag}}, Status: 206, Body: "world"},
+		{Name: "missing.txt", Status: 404},
+		{Name: "dir", Status: 404},
+	}
+
+	for i, tcase := range tcases {
+		t.Run(fmt.Sprintf("%d", i), func(t *testing.T) {
+			req := httptest.NewRequest("GET", "/"+tcase.Name, nil)
+			for k, vs := range tcase.Header {
+				for _, v := range vs {
+					req.Header.Add(k, v)
+				}
+			}
+			w := httptest.NewRecorder()
+			ServeFS(w, req, fsys, tcase.Name, &CachingETagger{})
+
+			if w.Code != tcase.Status {
+				t.Fatalf("expected status %d, got %d", tcase.Status, w.Code)
+			}
+			if tcase.Status == 200 || tcase.Status == 304 {
+				if v := w.Header().Get("ETag"); v != etag {
+					t.Fatalf("expected ETag %v, got %v", etag, v)
+				}
+			}
+			if tcase.Status != 404 && tcase.Status != 412 && w.Body.String() != tcase.Body {
+				t.Fatalf("expected body %q, got %q", tcase.Body, w.Body.String())
+			}
+		})
+	}
+}
@@ -11,6 +11,7 @@ import (
 	"io"
 	"log"
 	"net/http"
+	"net/http/httptest"
 	"strings"
 
 	"snai.pe/go-htutil"
@@ -78,3 +79,31 @@ func ExampleNegotiateContent() {
 	// {"message":"OK"}
 	// 406 Not Acceptable
 }
+
+func ExampleContentSwitch() {
+	var cs htutil.ContentSwitch
+	cs.HandleFunc("text/plain", func(w http.ResponseWriter, req *http.Request) {
+		fmt.Fprint(w, `OK`)
+	})
+	cs.HandleFunc("application/json", func(w http.ResponseWriter, req *http.Request) {
+		fmt.Fprint(w, `{"message":"OK"}`)
+	})
+
+	get := func(accept string) string {
+		req := httptest.NewRequest("GET", "/", nil)
+		req.Header.Set("Accept", accept)
+		rw := httptest.NewRecorder()
+		cs.ServeHTTP(rw, req)
+		if rw.Code != 200 {
+			return fmt.Sprintf("%d %s", rw.Code, http.StatusText(rw.Code))
+		}
+		return rw.Header().Get("Content-Type") + ": " + rw.Body.String()
+	}
+
+	fmt.Println(get("*/*"))
+	fmt.Println(get("application/json, text/*;q=0.5"))
+	fmt.Println(get("text/html"))
+	// Output: text/plain: OK
+	// application/json: {"message":"OK"}
+	// 406 Not Acceptable
+}
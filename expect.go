@@ -0,0 +1,101 @@
+// Copyright 2026 Franklin "Snaipe" Mathieu.
+//
+// Use of this source code is governed by the MIT license that can be
+// found in the LICENSE file.
+
+package htutil
+
+import (
+	"net/http"
+	"strings"
+)
+
+// ExpectsContinue returns whether the client sending req waits for a
+// 100 Continue interim response before sending the request content.
+func ExpectsContinue(req *http.Request) bool {
+	for _, v := range req.Header.Values("Expect") {
+		for _, exp := range SplitList(v) {
+			if strings.EqualFold(exp, "100-continue") {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// UnsupportedExpectation returns the first expectation of req other than
+// 100-continue, which is the only one defined by RFC 9110 §10.1.1.
+func UnsupportedExpectation(req *http.Request) (string, bool) {
+	for _, v := range req.Header.Values("Expect") {
+		for _, exp := range SplitList(v) {
+			if !strings.EqualFold(exp, "100-continue") {
+				return exp, true
+			}
+		}
+	}
+	return "", false
+}
+
+// SendContinue sends a 100 Continue interim response to the client if it
+// is waiting for one. Servers from net/http otherwise only send it when the
+// handler first reads the request body.
+func SendContinue(req *http.Request) error {
+	if !ExpectsContinue(req) || req.Body == nil || req.Body == http.NoBody {
+		return nil
+	}
+	// Reading from the body, even nothing, makes the server send 100 Continue.
+	_, err := req.Body.Read(nil)
+	return err
+}
+
+// RejectContinue responds to req with the specified final status, without
+// letting the client send the request content it is holding back. The
+// connection is closed afterwards, since the client may send the content
+// anyway.
+func RejectContinue(w http.ResponseWriter, status int, msg string) {
+	w.Header().Set("Connection", "close")
+	http.Error(w, msg, status)
+}
+
+// ExpectContinue returns a middleware that validates requests before their
+// content is sent.
+//
+// Requests with expectations other than 100-continue are rejected with
+// 417 Expectation Failed. Requests expecting 100-continue are passed to
+// check, which returns 0 to accept them, or the status code to reject them
+// with; only accepted requests are served by next, at which point the
+// client is asked to continue.
+//
+// check is only called for requests expecting 100-continue; other requests
+// already sent their content, and go straight to next.
+func ExpectContinue(next http.Handler, check func(req *http.Request) int) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+		if exp, ok := UnsupportedExpectation(req); ok {
+			RejectContinue(w, http.StatusExpectationFailed, "unsupported expectation "+exp)
+			return
+		}
+		if ExpectsContinue(req) {
+			if status := check(req); status != 0 {
+				RejectContinue(w, status, http.StatusText(status))
+				return
+			}
+		}
+		next.ServeHTTP(w, req)
+	})
+}
+
+// LimitContentLength returns a check function for ExpectContinue that
+// rejects requests whose declared content is larger than max with 413
+// Content Too Large, and requests of unknown length with 411 Length
+// Required.
+func LimitContentLength(max int64) func(req *http.Request) int {
+	return func(req *http.Request) int {
+		switch {
+		case req.ContentLength < 0:
+			return http.StatusLengthRequired
+		case req.ContentLength > max:
+			return http.StatusRequestEntityTooLarge
+		}
+		return 0
+	}
+}
@@ -0,0 +1,108 @@
+// Copyright 2026 Franklin "Snaipe" Mathieu.
+//
+// Use of this source code is governed by the MIT license that can be
+// found in the LICENSE file.
+
+package htutil
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestExpectContinue(t *testing.T) {
+	t.Parallel()
+
+	handler := ExpectContinue(http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+		body, _ := io.ReadAll(req.Body)
+		fmt.Fprintf(w, "got %s", body)
+	}), LimitContentLength(10))
+
+	srv := httptest.NewServer(handler)
+	defer srv.Close()
+
+	tcases := []struct {
+		Expect  string
+		Body    string
+		Status  int
+		Interim bool
+	}{
+		{Expect: "100-continue", Body: "hello", Status: 200, Interim: true},
+		{Expect: "100-continue", Body: "hello, world!", Status: 413},
+		{Expect: "", Body: "hello, world!", Status: 200},
+		{Expect: "teapot", Body: "hello", Status: 417},
+	}
+
+	for i, tcase := range tcases {
+		t.Run(fmt.Sprintf("%d", i), func(t *testing.T) {
+			conn, err := net.Dial("tcp", srv.Listener.Addr().String())
+			if err != nil {
+				t.Fatal(err)
+			}
+			defer conn.Close()
+			conn.SetDeadline(time.Now().Add(5 * time.Second))
+
+			fmt.Fprintf(conn, "POST / HTTP/1.1\r\nHost: test\r\nContent-Length: %d\r\n", len(tcase.Body))
+			if tcase.Expect != "" {
+				fmt.Fprintf(conn, "Expect: %s\r\n\r\n", tcase.Expect)
+			} else {
+				fmt.Fprintf(conn, "\r\n%s", tcase.Body)
+			}
+
+			r := bufio.NewReader(conn)
+			line, err := r.ReadString('\n')
+			if err != nil {
+				t.Fatal(err)
+			}
+			if tcase.Interim {
+				if !strings.HasPrefix(line, "HTTP/1.1 100 ") {
+					t.Fatalf("expected 100 Continue, got %q", line)
+				}
+				r.ReadString('\n')
+				fmt.Fprint(conn, tcase.Body)
+				line, _ = r.ReadString('\n')
+			}
+			expected := fmt.Sprintf("HTTP/1.1 %d ", tcase.Status)
+			if !strings.HasPrefix(line, expected) {
+				t.Fatalf("expected %q, got %q", expected, line)
+			}
+		})
+	}
+}
+
+func TestSendContinue(t *testing.T) {
+	t.Parallel()
+
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+		if err := SendContinue(req); err != nil {
+			t.Errorf("expected no error, got %v", err)
+		}
+		// Respond after the interim response, without reading the body.
+		w.WriteHeader(http.StatusAccepted)
+	}))
+	defer srv.Close()
+
+	conn, err := net.Dial("tcp", srv.Listener.Addr().String())
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer conn.Close()
+	conn.SetDeadline(time.Now().Add(5 * time.Second))
+
+	fmt.Fprint(conn, "PUT / HTTP/1.1\r\nHost: test\r\nContent-Length: 5\r\nExpect: 100-continue\r\n\r\n")
+	r := bufio.NewReader(conn)
+	line, err := r.ReadString('\n')
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !strings.HasPrefix(line, "HTTP/1.1 100 ") {
+		t.Fatalf("expected 100 Continue, got %q", line)
+	}
+}
@@ -0,0 +1,151 @@
+// Copyright 2026 Franklin "Snaipe" Mathieu.
+//
+// Use of this source code is governed by the MIT license that can be
+// found in the LICENSE file.
+
+package htutil
+
+import (
+	"context"
+	"log/slog"
+	"math/rand"
+	"net/http"
+	"time"
+)
+
+// ExperimentBucket is a bucket of an Experiment, with its relative weight.
+type ExperimentBucket struct {
+	Name   string
+	Weight int
+}
+
+// Experiment assigns clients to the buckets of an A/B experiment, and
+// persists the assignment in a signed cookie, so that clients cannot pick
+// their own bucket.
+//
+// Handlers retrieve the assigned bucket with ExperimentBucketOf. Since
+// responses then depend on the cookie, they are made private to shared
+// caches, and Vary on Cookie.
+type Experiment struct {
+	// Name identifies the experiment, and is used as the cookie name.
+	Name string
+
+	// Buckets are the buckets clients get assigned to, at random,
+	// proportionally to their weight.
+	Buckets []ExperimentBucket
+
+	// Signer signs the cookies.
+	Signer *CookieSigner
+
+	// MaxAge is the lifetime of the cookie. Defaults to 30 days.
+	MaxAge time.Duration
+}
+
+type experimentKey string
+
+// ExperimentBucketOf returns the bucket assigned by the named experiment
+// to the client making the request that ctx belongs to.
+func ExperimentBucketOf(ctx context.Context, name string) (string, bool) {
+	bucket, ok := ctx.Value(experimentKey(name)).(string)
+	return bucket, ok
+}
+
+// verify returns the bucket in cookie, if it has a valid signature and is
+// still part of the experiment.
+func (e *Experiment) verify(cookie *http.Cookie) (string, bool) {
+	bucket, err := e.Signer.Verify(cookie)
+	if err != nil {
+		return "", false
+	}
+	for _, b := range e.Buckets {
+		if b.Name == bucket {
+			return bucket, true
+		}
+	}
+	return "", false
+}
+
+func (e *Experiment) pick() string {
+	total := 0
+	for _, b := range e.Buckets {
+		total += b.Weight
+	}
+	if total <= 0 {
+		return ""
+	}
+	roll := rand.Intn(total)
+	for _, b := range e.Buckets {
+		if roll < b.Weight {
+			return b.Name
+		}
+		roll -= b.Weight
+	}
+	return ""
+}
+
+// Middleware returns a middleware that assigns a bucket to the clients of
+// next, and makes it available in the request context.
+func (e *Experiment) Middleware(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+		logger := ContextLogger(req.Context())
+		var bucket string
+		if cookie, err := req.Cookie(e.Name); err == nil {
+			var ok bool
+			if bucket, ok = e.verify(cookie); !ok {
+				// Either tampered with, or from a bucket that was removed.
+				logger.Warn("invalid experiment cookie", slog.String("experiment", e.Name))
+			}
+		}
+		// Without any bucket with a positive weight, clients are left
+		// unassigned rather than persisted in no bucket at all.
+		if bucket == "" {
+			if bucket = e.pick(); bucket != "" {
+				logger.Debug("assigned experiment bucket",
+					slog.String("experiment", e.Name), slog.String("bucket", bucket))
+				maxAge := e.MaxAge
+				if maxAge <= 0 {
+					maxAge = 30 * 24 * time.Hour
+				}
+				err := SetSignedCookie(w, &http.Cookie{
+					Name:     e.Name,
+					Value:    bucket,
+					Path:     "/",
+					MaxAge:   int(maxAge / time.Second),
+					HttpOnly: true,
+					SameSite: http.SameSiteLaxMode,
+				}, e.Signer)
+				if err != nil {
+					logger.Warn("setting experiment cookie",
+						slog.String("experiment", e.Name), slog.String("error", err.Error()))
+				}
+			}
+		}
+
+		rec := NewRecorder(w)
+		rec.OnWriteHeader(func(status int) int {
+			h := w.Header()
+			AddVary(h, "Cookie")
+			h.Set("Cache-Control", privateCacheControl(h.Values("Cache-Control")))
+			return status
+		})
+
+		if bucket != "" {
+			req = req.WithContext(context.WithValue(req.Context(), experimentKey(e.Name), bucket))
+		}
+		next.ServeHTTP(rec.Writer, req)
+		if !rec.Written() {
+			rec.Writer.WriteHeader(http.StatusOK)
+		}
+	})
+}
+
+// privateCacheControl turns the Cache-Control field values into ones that
+// forbid shared caches from storing the response.
+func privateCacheControl(values []string) string {
+	cc, _ := ParseCacheControl(values...)
+	if !cc.NoStore {
+		cc.Public, cc.SMaxAge = false, nil
+		cc.Private, cc.PrivateFields = true, nil
+	}
+	return cc.String()
+}
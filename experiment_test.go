@@ -0,0 +1,114 @@
+// Copyright 2026 Franklin "Snaipe" Mathieu.
+//
+// Use of this source code is governed by the MIT license that can be
+// found in the LICENSE file.
+
+package htutil
+
+import (
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestExperiment(t *testing.T) {
+	t.Parallel()
+
+	e := &Experiment{
+		Name:    "exp-checkout",
+		Buckets: []ExperimentBucket{{Name: "a", Weight: 1}, {Name: "b", Weight: 0}},
+		Signer:  &CookieSigner{Keys: [][]byte{[]byte("secret")}},
+	}
+	handler := e.Middleware(http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+		bucket, _ := ExperimentBucketOf(req.Context(), "exp-checkout")
+		w.Header().Set("Cache-Control", "public, max-age=60, s-maxage=600")
+		fmt.Fprint(w, bucket)
+	}))
+
+	signed := func(bucket string) string {
+		c := &http.Cookie{Name: "exp-checkout", Value: bucket}
+		if err := e.Signer.Sign(c); err != nil {
+			t.Fatal(err)
+		}
+		return c.Value
+	}
+
+	tcases := []struct {
+		Cookie    string
+		Bucket    string
+		SetCookie bool
+	}{
+		{Bucket: "a", SetCookie: true},
+		{Cookie: signed("b"), Bucket: "b"},
+		{Cookie: "b.forged", Bucket: "a", SetCookie: true},
+		{Cookie: signed("c"), Bucket: "a", SetCookie: true},
+	}
+
+	for i, tcase := range tcases {
+		t.Run(fmt.Sprintf("%d", i), func(t *testing.T) {
+			req := httptest.NewRequest("GET", "/", nil)
+			if tcase.Cookie != "" {
+				req.AddCookie(&http.Cookie{Name: "exp-checkout", Value: tcase.Cookie})
+			}
+			w := httptest.NewRecorder()
+			handler.ServeHTTP(w, req)
+
+			if body := w.Body.String(); body != tcase.Bucket {
+				t.Fatalf("expected bucket %q, got %q", tcase.Bucket, body)
+			}
+			if set := w.Header().Get("Set-Cookie") != ""; set != tcase.SetCookie {
+				t.Fatalf("expected Set-Cookie %v, got %q", tcase.SetCookie, w.Header().Get("Set-Cookie"))
+			}
+			if cc := w.Header().Get("Cache-Control"); cc != "private, max-age=60" {
+				t.Fatalf("expected Cache-Control %q, got %q", "private, max-age=60", cc)
+			}
+			if vary := w.Header().Get("Vary"); vary != "Cookie" {
+				t.Fatalf("expected Vary Cookie, got %q", vary)
+			}
+		})
+	}
+}
+
+func TestExperimentNoEligibleBucket(t *testing.T) {
+	t.Parallel()
+
+	e := &Experiment{
+		Name:    "exp-checkout",
+		Buckets: []ExperimentBucket{{Name: "a", Weight: 0}},
+		Signer:  &CookieSigner{Keys: [][]byte{[]byte("secret")}},
+	}
+	handler := e.Middleware(http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+		if bucket, ok := ExperimentBucketOf(req.Context(), "exp-checkout"); ok {
+			t.Errorf("expected no bucket, got %q", bucket)
+		}
+	}))
+
+	w := httptest.NewRecorder()
+	handler.ServeHTTP(w, httptest.NewRequest("GET", "/", nil))
+	if set := w.Header().Get("Set-Cookie"); set != "" {
+		t.Fatalf("expected no Set-Cookie, got %q", set)
+	}
+}
+
+func TestPrivateCacheControl(t *testing.T) {
+	t.Parallel()
+
+	tcases := []struct {
+		Input    []string
+		Expected string
+	}{
+		{Input: nil, Expected: "private"},
+		{Input: []string{"public", "max-age=60"}, Expected: "private, max-age=60"},
+		{Input: []string{"no-store"}, Expected: "no-store"},
+		{Input: []string{`private="Set-Cookie", no-cache`}, Expected: "private, no-cache"},
+	}
+
+	for i, tcase := range tcases {
+		t.Run(fmt.Sprintf("%d", i), func(t *testing.T) {
+			if cc := privateCacheControl(tcase.Input); cc != tcase.Expected {
+				t.Fatalf("expected %q, got %q", tcase.Expected, cc)
+			}
+		})
+	}
+}
@@ -0,0 +1,74 @@
+// Copyright 2026 Franklin "Snaipe" Mathieu.
+//
+// Use of this source code is governed by the MIT license that can be
+// found in the LICENSE file.
+
+package htutil
+
+import (
+	"fmt"
+	"net/url"
+	"strings"
+	"unicode/utf8"
+)
+
+// This file implements the encoding of parameter values with characters
+// outside of US-ASCII, as per RFC 8187, for parameters like title* in Link
+// or filename* in Content-Disposition.
+
+func isASCII(s string) bool {
+	for i := 0; i < len(s); i++ {
+		if s[i] >= utf8.RuneSelf {
+			return false
+		}
+	}
+	return true
+}
+
+// encodeExtValue percent-encodes s as the value-chars of an ext-value, as
+// per RFC 8187 §3.2.
+func encodeExtValue(s string) string {
+	const attrChar = "!#$&+-.^_`|~"
+	var out strings.Builder
+	for i := 0; i < len(s); i++ {
+		c := s[i]
+		if c < utf8.RuneSelf && (isAlpha(c) || isDigit(c) || strings.IndexByte(attrChar, c) != -1) {
+			out.WriteByte(c)
+		} else {
+			fmt.Fprintf(&out, "%%%02X", c)
+		}
+	}
+	return out.String()
+}
+
+// decodeExtValue decodes an ext-value, as per RFC 8187 §3.2, in the UTF-8
+// or ISO-8859-1 charsets.
+func decodeExtValue(s string) (string, error) {
+	charset, rest, ok := strings.Cut(s, "'")
+	if !ok {
+		return "", fmt.Errorf("missing charset")
+	}
+	_, value, ok := strings.Cut(rest, "'")
+	if !ok {
+		return "", fmt.Errorf("missing language")
+	}
+	decoded, err := url.PathUnescape(value)
+	if err != nil {
+		return "", err
+	}
+	switch strings.ToLower(charset) {
+	case "utf-8":
+		if !utf8.ValidString(decoded) {
+			return "", fmt.Errorf("invalid UTF-8")
+		}
+		return decoded, nil
+	case "iso-8859-1":
+		runes := make([]rune, len(decoded))
+		for i := 0; i < len(decoded); i++ {
+			runes[i] = rune(decoded[i])
+		}
+		return string(runes), nil
+	default:
+		return "", fmt.Errorf("unsupported charset %q", charset)
+	}
+}
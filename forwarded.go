@@ -0,0 +1,143 @@
+// Copyright 2026 Franklin "Snaipe" Mathieu.
+//
+// Use of this source code is governed by the MIT license that can be
+// found in the LICENSE file.
+
+package htutil
+
+import (
+	"fmt"
+	"net/http"
+	"sort"
+	"strings"
+)
+
+// ForwardedElement is an element of a Forwarded header field, as per
+// RFC 7239 §4, describing a request as received by a proxy.
+type ForwardedElement struct {
+	// For is the node that made the request to the proxy, like
+	// "192.0.2.43", "[2001:db8:cafe::17]:4711", "unknown", or an
+	// obfuscated identifier like "_hidden".
+	For string
+
+	// By is the node of the proxy that received the request.
+	By string
+
+	// Host is the Host of the request received by the proxy.
+	Host string
+
+	// Proto is the scheme of the request received by the proxy, like
+	// "http" or "https".
+	Proto string
+
+	// Extensions contains the other parameters, with lowercased names.
+	Extensions map[string]string
+}
+
+// String returns the forwarded-element for e.
+func (e ForwardedElement) String() string {
+	var pairs []string
+	add := func(name, value string) {
+		if value != "" {
+			pairs = append(pairs, name+"="+tokenOrQuoted(value))
+		}
+	}
+	add("for", e.For)
+	add("by", e.By)
+	add("host", e.Host)
+	add("proto", e.Proto)
+	names := make([]string, 0, len(e.Extensions))
+	for name := range e.Extensions {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	for _, name := range names {
+		add(name, e.Extensions[name])
+	}
+	return strings.Join(pairs, ";")
+}
+
+// ParseForwarded parses the values of Forwarded header fields, and returns
+// their elements in order, from the one added by the proxy closest to the
+// client to the one added by the closest proxy.
+func ParseForwarded(values ...string) ([]ForwardedElement, error) {
+	var elems []ForwardedElement
+	for _, member := range SplitList(strings.Join(values, ",")) {
+		e, err := parseForwardedElement(member)
+		if err != nil {
+			return nil, err
+		}
+		elems = append(elems, e)
+	}
+	return elems, nil
+}
+
+func parseForwardedElement(elem string) (ForwardedElement, error) {
+	var e ForwardedElement
+	for _, pair := range splitUnquoted(elem, ';') {
+		name, value, ok := strings.Cut(trimOWS(pair), "=")
+		name = strings.ToLower(name)
+		if !ok || !IsToken(name) {
+			return ForwardedElement{}, fmt.Errorf("parsing forwarded: invalid pair %q", pair)
+		}
+		if strings.HasPrefix(value, `"`) {
+			unquoted, err := UnquoteString(value)
+			if err != nil {
+				return ForwardedElement{}, fmt.Errorf("parsing forwarded: %w", err)
+			}
+			value = unquoted
+		} else if !IsToken(value) {
+			return ForwardedElement{}, fmt.Errorf("parsing forwarded: invalid value %q", value)
+		}
+		switch name {
+		case "for":
+			e.For = value
+		case "by":
+			e.By = value
+		case "host":
+			e.Host = value
+		case "proto":
+			e.Proto = strings.ToLower(value)
+		default:
+			if e.Extensions == nil {
+				e.Extensions = make(map[string]string)
+			}
+			e.Extensions[name] = value
+		}
+	}
+	return e, nil
+}
+
+// ParseXForwarded returns the elements described by the legacy
+// X-Forwarded-For, X-Forwarded-Host, and X-Forwarded-Proto fields of h, in
+// the same order as ParseForwarded.
+//
+// There is one element per X-Forwarded-For address. Since proxies
+// typically set X-Forwarded-Host and X-Forwarded-Proto rather than append
+// to them, they are attributed to the first element, unless they list as
+// many values as there are addresses.
+func ParseXForwarded(h http.Header) []ForwardedElement {
+	var elems []ForwardedElement
+	for _, addr := range SplitList(strings.Join(h.Values("X-Forwarded-For"), ",")) {
+		elems = append(elems, ForwardedElement{For: addr})
+	}
+	if len(elems) == 0 {
+		return nil
+	}
+
+	attribute := func(name string, set func(e *ForwardedElement, v string)) {
+		values := SplitList(strings.Join(h.Values(name), ","))
+		switch len(values) {
+		case 0:
+		case len(elems):
+			for i, v := range values {
+				set(&elems[i], v)
+			}
+		default:
+			set(&elems[0], values[0])
+		}
+	}
+	attribute("X-Forwarded-Host", func(e *ForwardedElement, v string) { e.Host = v })
+	attribute("X-Forwarded-Proto", func(e *ForwardedElement, v string) { e.Proto = strings.ToLower(v) })
+	return elems
+}
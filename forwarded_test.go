@@ -0,0 +1,82 @@
+// Copyright 2026 Franklin "Snaipe" Mathieu.
+//
+// Use of this source code is governed by the MIT license that can be
+// found in the LICENSE file.
+
+package htutil
+
+import (
+	"fmt"
+	"net/http"
+	"reflect"
+	"testing"
+)
+
+func TestParseForwarded(t *testing.T) {
+	t.Parallel()
+
+	tcases := []struct {
+		Values []string
+		Elems  []ForwardedElement
+		Err    bool
+	}{
+		{Values: nil, Elems: nil},
+		{Values: []string{`for="_gazonk"`}, Elems: []ForwardedElement{{For: "_gazonk"}}},
+		{Values: []string{`For="[2001:db8:cafe::17]:4711"`}, Elems: []ForwardedElement{{For: "[2001:db8:cafe::17]:4711"}}},
+		{Values: []string{`for=192.0.2.60;proto=HTTP;by=203.0.113.43;host=example.com`}, Elems: []ForwardedElement{
+			{For: "192.0.2.60", Proto: "http", By: "203.0.113.43", Host: "example.com"},
+		}},
+		{Values: []string{`for=192.0.2.43, for=198.51.100.17`, `for=unknown;secret=x`}, Elems: []ForwardedElement{
+			{For: "192.0.2.43"}, {For: "198.51.100.17"}, {For: "unknown", Extensions: map[string]string{"secret": "x"}},
+		}},
+		{Values: []string{`for=[2001:db8::1]`}, Err: true},
+		{Values: []string{`for`}, Err: true},
+		{Values: []string{`for="192.0.2.1`}, Err: true},
+	}
+
+	for i, tcase := range tcases {
+		t.Run(fmt.Sprintf("%d", i), func(t *testing.T) {
+			elems, err := ParseForwarded(tcase.Values...)
+			if (err != nil) != tcase.Err {
+				t.Fatalf("expected error %v, got %v", tcase.Err, err)
+			}
+			if !reflect.DeepEqual(elems, tcase.Elems) {
+				t.Fatalf("expected %v, got %v", tcase.Elems, elems)
+			}
+			for _, e := range elems {
+				parsed, err := ParseForwarded(e.String())
+				if err != nil || !reflect.DeepEqual(parsed, []ForwardedElement{e}) {
+					t.Fatalf("expected %v to round-trip, got %v (%v)", e, parsed, err)
+				}
+			}
+		})
+	}
+}
+
+func TestParseXForwarded(t *testing.T) {
+	t.Parallel()
+
+	tcases := []struct {
+		Header http.Header
+		Elems  []ForwardedElement
+	}{
+		{Header: http.Header{"X-Forwarded-Proto": {"https"}}, Elems: nil},
+		{
+			Header: http.Header{"X-Forwarded-For": {"203.0.113.9, 10.0.0.2"}, "X-Forwarded-Proto": {"HTTPS"}, "X-Forwarded-Host": {"example.com"}},
+			Elems:  []ForwardedElement{{For: "203.0.113.9", Proto: "https", Host: "example.com"}, {For: "10.0.0.2"}},
+		},
+		{
+			Header: http.Header{"X-Forwarded-For": {"203.0.113.9", "10.0.0.2"}, "X-Forwarded-Proto": {"https, http"}},
+			Elems:  []ForwardedElement{{For: "203.0.113.9", Proto: "https"}, {For: "10.0.0.2", Proto: "http"}},
+		},
+	}
+
+	for i, tcase := range tcases {
+		t.Run(fmt.Sprintf("%d", i), func(t *testing.T) {
+			elems := ParseXForwarded(tcase.Header)
+			if !reflect.DeepEqual(elems, tcase.Elems) {
+				t.Fatalf("expected %v, got %v", tcase.Elems, elems)
+			}
+		})
+	}
+}
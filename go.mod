@@ -1,3 +1,3 @@
 module snai.pe/go-htutil
 
-go 1.17
+go 1.21
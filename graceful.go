@@ -0,0 +1,180 @@
+// Copyright 2026 Franklin "Snaipe" Mathieu.
+//
+// Use of this source code is governed by the MIT license that can be
+// found in the LICENSE file.
+
+package htutil
+
+import (
+	"context"
+	"net/http"
+	"strconv"
+	"sync"
+	"time"
+)
+
+// GracefulServer embeds *http.Server, and drains connections more
+// gracefully on Shutdown than http.Server does on its own.
+//
+// When shutting down, a GracefulServer:
+//
+//  1. flags its Health as not ready, and runs the RegisterOnDrain hooks;
+//  2. keeps serving requests for DrainDelay, to let load balancers observe
+//     the readiness change and stop routing new requests to it;
+//  3. starts rejecting new requests with a 503 Service Unavailable,
+//     Retry-After, and Connection: close;
+//  4. waits for in-flight requests to complete;
+//  5. calls http.Server.Shutdown.
+type GracefulServer struct {
+	*http.Server
+
+	// Health, if not nil, gets flagged as not ready when shutting down.
+	Health *Health
+
+	// RetryAfter is the delay advertised via Retry-After to clients whose
+	// requests get rejected while shutting down. Defaults to 1 second.
+	RetryAfter time.Duration
+
+	// DrainDelay is how long to keep serving requests after the server is
+	// flagged as not ready, before rejecting them.
+	DrainDelay time.Duration
+
+	mu        sync.Mutex
+	inflight  int
+	draining  bool
+	rejecting bool
+	idle      chan struct{}
+	onDrain   []func()
+}
+
+// NewGracefulServer wraps srv, replacing its handler with one that keeps
+// track of in-flight requests. If srv.Handler is nil, http.DefaultServeMux
+// is used.
+func NewGracefulServer(srv *http.Server, health *Health) *GracefulServer {
+	s := &GracefulServer{Server: srv, Health: health}
+
+	next := srv.Handler
+	if next == nil {
+		next = http.DefaultServeMux
+	}
+	srv.Handler = http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+		if !s.enter() {
+			retry := s.RetryAfter
+			if retry <= 0 {
+				retry = time.Second
+			}
+			secs := int64((retry + time.Second - 1) / time.Second)
+			w.Header().Set("Retry-After", strconv.FormatInt(secs, 10))
+			w.Header().Set("Connection", "close")
+			w.Header().Set("Cache-Control", "no-store")
+			http.Error(w, http.StatusText(http.StatusServiceUnavailable), http.StatusServiceUnavailable)
+			return
+		}
+		defer s.leave()
+		next.ServeHTTP(w, req)
+	})
+	return s
+}
+
+// RegisterOnDrain registers a function to call when the server starts
+// draining, before any request gets rejected.
+func (s *GracefulServer) RegisterOnDrain(f func()) {
+	s.mu.Lock()
+	s.onDrain = append(s.onDrain, f)
+	s.mu.Unlock()
+}
+
+// Draining returns whether the server is shutting down.
+func (s *GracefulServer) Draining() bool {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.draining
+}
+
+// InFlight returns the number of requests currently being served.
+func (s *GracefulServer) InFlight() int {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.inflight
+}
+
+func (s *GracefulServer) enter() bool {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.rejecting {
+		return false
+	}
+	s.inflight++
+	return true
+}
+
+func (s *GracefulServer) leave() {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.inflight--
+	if s.inflight == 0 && s.idle != nil {
+		close(s.idle)
+		s.idle = nil
+	}
+}
+
+// Shutdown drains the server, then shuts it down. If ctx expires before
+// all in-flight requests complete, the underlying http.Server is still
+// shut down, and the context's error is returned.
+func (s *GracefulServer) Shutdown(ctx context.Context) error {
+	s.mu.Lock()
+	if s.draining {
+		s.mu.Unlock()
+		return s.Server.Shutdown(ctx)
+	}
+	hooks := s.onDrain
+	s.mu.Unlock()
+
+	if s.Health != nil {
+		s.Health.SetReady(false)
+	}
+	for _, hook := range hooks {
+		hook()
+	}
+
+	s.mu.Lock()
+	s.draining = true
+	s.rejecting = s.DrainDelay <= 0
+	s.mu.Unlock()
+
+	s.Server.SetKeepAlivesEnabled(false)
+
+	var err error
+	if s.DrainDelay > 0 {
+		timer := time.NewTimer(s.DrainDelay)
+		select {
+		case <-timer.C:
+		case <-ctx.Done():
+			timer.Stop()
+			err = ctx.Err()
+		}
+	}
+
+	idle := make(chan struct{})
+	s.mu.Lock()
+	s.rejecting = true
+	if s.inflight == 0 {
+		close(idle)
+	} else {
+		s.idle = idle
+	}
+	s.mu.Unlock()
+
+	if err == nil {
+		select {
+		case <-idle:
+		case <-ctx.Done():
+			err = ctx.Err()
+		}
+	}
+
+	if serr := s.Server.Shutdown(ctx); err == nil {
+		err = serr
+	}
+	return err
+}
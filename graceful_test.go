@@ -0,0 +1,130 @@
+// Copyright 2026 Franklin "Snaipe" Mathieu.
+//
+// Use of this source code is governed by the MIT license that can be
+// found in the LICENSE file.
+
+package htutil
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+)
+
+func TestGracefulServer(t *testing.T) {
+	t.Parallel()
+
+	started := make(chan struct{})
+	release := make(chan struct{})
+
+	var health Health
+	srv := NewGracefulServer(&http.Server{
+		Handler: http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+			close(started)
+			<-release
+		}),
+	}, &health)
+	srv.RetryAfter = 5 * time.Second
+
+	drained := false
+	srv.RegisterOnDrain(func() { drained = true })
+
+	go srv.Handler.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest("GET", "/", nil))
+	<-started
+
+	done := make(chan error, 1)
+	go func() { done <- srv.Shutdown(context.Background()) }()
+
+	for !srv.Draining() {
+		time.Sleep(time.Millisecond)
+	}
+	if !drained {
+		t.Fatalf("expected drain hooks to have run")
+	}
+	if health.Ready() {
+		t.Fatalf("expected readiness to be flipped")
+	}
+
+	w := httptest.NewRecorder()
+	srv.Handler.ServeHTTP(w, httptest.NewRequest("GET", "/", nil))
+	if w.Code != http.StatusServiceUnavailable {
+		t.Fatalf("expected status 503, got %v", w.Code)
+	}
+	if ra := w.Header().Get("Retry-After"); ra != "5" {
+		t.Fatalf("expected Retry-After 5, got %q", ra)
+	}
+	if conn := w.Header().Get("Connection"); conn != "close" {
+		t.Fatalf("expected Connection close, got %q", conn)
+	}
+
+	select {
+	case err := <-done:
+		t.Fatalf("shutdown returned before in-flight request completed: %v", err)
+	case <-time.After(10 * time.Millisecond):
+	}
+
+	close(release)
+	if err := <-done; err != nil {
+		t.Fatalf("expected nil error, got %v", err)
+	}
+}
+
+func TestGracefulServerDeadline(t *testing.T) {
+	t.Parallel()
+
+	started := make(chan struct{})
+	release := make(chan struct{})
+	defer close(release)
+
+	srv := NewGracefulServer(&http.Server{
+		Handler: http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+			close(started)
+			<-release
+		}),
+	}, nil)
+
+	go srv.Handler.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest("GET", "/", nil))
+	<-started
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+	defer cancel()
+	if err := srv.Shutdown(ctx); err != context.DeadlineExceeded {
+		t.Fatalf("expected deadline exceeded, got %v", err)
+	}
+}
+
+func TestGracefulServerDrainDelay(t *testing.T) {
+	t.Parallel()
+
+	var health Health
+	srv := NewGracefulServer(&http.Server{
+		Handler: http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {}),
+	}, &health)
+	srv.DrainDelay = 50 * time.Millisecond
+
+	done := make(chan error, 1)
+	go func() { done <- srv.Shutdown(context.Background()) }()
+
+	for !srv.Draining() {
+		time.Sleep(time.Millisecond)
+	}
+	if health.Ready() {
+		t.Fatalf("expected readiness to be flipped during the drain delay")
+	}
+	w := httptest.NewRecorder()
+	srv.Handler.ServeHTTP(w, httptest.NewRequest("GET", "/", nil))
+	if w.Code != http.StatusOK {
+		t.Fatalf("expected requests to be served during the drain delay, got status %v", w.Code)
+	}
+
+	if err := <-done; err != nil {
+		t.Fatalf("expected nil error, got %v", err)
+	}
+	w = httptest.NewRecorder()
+	srv.Handler.ServeHTTP(w, httptest.NewRequest("GET", "/", nil))
+	if w.Code != http.StatusServiceUnavailable {
+		t.Fatalf("expected status 503 after the drain delay, got %v", w.Code)
+	}
+}
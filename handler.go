@@ -0,0 +1,112 @@
+// Copyright 2026 Franklin "Snaipe" Mathieu.
+//
+// Use of this source code is governed by the MIT license that can be
+// found in the LICENSE file.
+
+package htutil
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"encoding/xml"
+	"io"
+	"net/http"
+	"strconv"
+	"strings"
+	"sync"
+)
+
+// BodyEncoder encodes v as a response body.
+type BodyEncoder func(w io.Writer, v any) error
+
+var bodyEncoders = struct {
+	sync.RWMutex
+	m     map[string]BodyEncoder
+	order []string
+}{
+	m: map[string]BodyEncoder{
+		"application/json": func(w io.Writer, v any) error {
+			return json.NewEncoder(w).Encode(v)
+		},
+		"application/xml": func(w io.Writer, v any) error {
+			if _, err := io.WriteString(w, xml.Header); err != nil {
+				return err
+			}
+			return xml.NewEncoder(w).Encode(v)
+		},
+	},
+	order: []string{"application/json", "application/xml"},
+}
+
+// RegisterBodyEncoder registers the encoder used by Render for responses
+// of the specified media type, replacing any previously registered
+// encoder. Media types are offered in registration order, after the JSON
+// and XML encoders registered by default.
+func RegisterBodyEncoder(mediaType string, enc BodyEncoder) {
+	mediaType = strings.ToLower(mediaType)
+	bodyEncoders.Lock()
+	defer bodyEncoders.Unlock()
+	if _, ok := bodyEncoders.m[mediaType]; !ok {
+		bodyEncoders.order = append(bodyEncoders.order, mediaType)
+	}
+	bodyEncoders.m[mediaType] = enc
+}
+
+// Render writes a response with the specified status, and v encoded in
+// the media type negotiated with the Accept header of req, among those
+// with a registered encoder; see RegisterBodyEncoder. If none is
+// acceptable, the response is a 406 Not Acceptable.
+func Render(w http.ResponseWriter, req *http.Request, status int, v any) {
+	bodyEncoders.RLock()
+	offers := append([]string(nil), bodyEncoders.order...)
+	bodyEncoders.RUnlock()
+
+	AddVary(w.Header(), "Accept")
+	ctype, _ := NegotiateContent(req.Header, "Accept", offers...)
+	if ctype == "" {
+		RespondError(w, req, NewProblem(http.StatusNotAcceptable,
+			"Available representations: "+strings.Join(offers, ", ")+"."))
+		return
+	}
+	bodyEncoders.RLock()
+	enc := bodyEncoders.m[ctype]
+	bodyEncoders.RUnlock()
+
+	// Encode before writing the header, so that encoding errors can still
+	// be reported.
+	var buf bytes.Buffer
+	if err := enc(&buf, v); err != nil {
+		RespondError(w, req, err)
+		return
+	}
+	w.Header().Set("Content-Type", ctype)
+	w.Header().Set("Content-Length", strconv.Itoa(buf.Len()))
+	w.WriteHeader(status)
+	if req.Method != http.MethodHead {
+		buf.WriteTo(w)
+	}
+}
+
+// HandlerFunc adapts a typed function to a http.Handler.
+//
+// The request is bound to a value of type In with Bind, and the value of
+// type Out returned by the function is rendered with Render, as a 200 OK
+// response. Errors, including binding errors, are rendered with
+// RespondError; the function may return a *Problem to control the error
+// response.
+type HandlerFunc[In, Out any] func(ctx context.Context, in In) (Out, error)
+
+func (fn HandlerFunc[In, Out]) ServeHTTP(w http.ResponseWriter, req *http.Request) {
+	var in In
+	if err := Bind(req, &in); err != nil {
+		RespondError(w, req, err)
+		return
+	}
+	out, err := fn(req.Context(), in)
+	if err != nil {
+		RespondError(w, req, err)
+		return
+	}
+	Render(w, req, http.StatusOK, out)
+}
@@ -0,0 +1,87 @@
+// Copyright 2026 Franklin "Snaipe" Mathieu.
+//
+// Use of this source code is governed by the MIT license that can be
+// found in the LICENSE file.
+
+package htutil
+
+import (
+	"context"
+	"fmt"
+	"net/http/httptest"
+	"testing"
+)
+
+type greetRequest struct {
+	Name string `query:"name"`
+}
+
+type greetResponse struct {
+	Greeting string `json:"greeting" xml:"greeting"`
+}
+
+func TestHandlerFunc(t *testing.T) {
+	t.Parallel()
+
+	handler := HandlerFunc[greetRequest, greetResponse](func(ctx context.Context, in greetRequest) (greetResponse, error) {
+		if in.Name == "" {
+			return greetResponse{}, NewProblem(422, "name is required")
+		}
+		return greetResponse{Greeting: "Hello, " + in.Name}, nil
+	})
+
+	tcases := []struct {
+		URL         string
+		Accept      string
+		Status      int
+		ContentType string
+		Body        string
+	}{
+		{
+			URL:         "/?name=Alice",
+			Status:      200,
+			ContentType: "application/json",
+			Body:        `{"greeting":"Hello, Alice"}` + "\n",
+		},
+		{
+			URL:         "/?name=Alice",
+			Accept:      "application/xml",
+			Status:      200,
+			ContentType: "application/xml",
+			Body:        `<?xml version="1.0" encoding="UTF-8"?>` + "\n" + `<greetResponse><greeting>Hello, Alice</greeting></greetResponse>`,
+		},
+		{
+			URL:         "/",
+			Accept:      "application/json",
+			Status:      422,
+			ContentType: "application/problem+json",
+			Body:        `{"title":"Unprocessable Entity","status":422,"detail":"name is required"}` + "\n",
+		},
+		{
+			URL:    "/?name=Alice",
+			Accept: "text/csv",
+			Status: 406,
+		},
+	}
+
+	for i, tcase := range tcases {
+		t.Run(fmt.Sprintf("%d", i), func(t *testing.T) {
+			req := httptest.NewRequest("GET", tcase.URL, nil)
+			if tcase.Accept != "" {
+				req.Header.Set("Accept", tcase.Accept)
+			}
+			rw := httptest.NewRecorder()
+			handler.ServeHTTP(rw, req)
+
+			if rw.Code != tcase.Status {
+				t.Fatalf("expected status %d, got %d", tcase.Status, rw.Code)
+			}
+			if tcase.ContentType != "" && rw.Header().Get("Content-Type") != tcase.ContentType {
+				t.Fatalf("expected content type %q, got %q", tcase.ContentType, rw.Header().Get("Content-Type"))
+			}
+			if tcase.Body != "" && rw.Body.String() != tcase.Body {
+				t.Fatalf("expected body %q, got %q", tcase.Body, rw.Body.String())
+			}
+		})
+	}
+}
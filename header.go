@@ -0,0 +1,169 @@
+// Copyright 2026 Franklin "Snaipe" Mathieu.
+//
+// Use of this source code is governed by the MIT license that can be
+// found in the LICENSE file.
+
+package htutil
+
+import (
+	"encoding"
+	"errors"
+	"fmt"
+	"net/http"
+	"reflect"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"snai.pe/go-htutil/sfv"
+)
+
+// HeaderCodec parses and formats header field values of type T.
+type HeaderCodec[T any] struct {
+	Parse  func(value string) (T, error)
+	Format func(v T) (string, error)
+}
+
+var headerCodecs sync.Map // reflect.Type -> HeaderCodec[T]
+
+// RegisterHeaderCodec registers the codec used by GetHeader and SetHeader
+// for values of type T, replacing any previously registered codec.
+//
+// Types without a registered codec may still be used if they implement
+// both encoding.TextMarshaler and encoding.TextUnmarshaler.
+func RegisterHeaderCodec[T any](codec HeaderCodec[T]) {
+	headerCodecs.Store(reflect.TypeOf((*T)(nil)).Elem(), codec)
+}
+
+func init() {
+	RegisterHeaderCodec(HeaderCodec[string]{
+		Parse:  func(s string) (string, error) { return s, nil },
+		Format: func(s string) (string, error) { return s, nil },
+	})
+	RegisterHeaderCodec(HeaderCodec[int]{
+		Parse: strconv.Atoi,
+		Format: func(v int) (string, error) {
+			return strconv.Itoa(v), nil
+		},
+	})
+	RegisterHeaderCodec(HeaderCodec[int64]{
+		Parse: func(s string) (int64, error) {
+			return strconv.ParseInt(s, 10, 64)
+		},
+		Format: func(v int64) (string, error) {
+			return strconv.FormatInt(v, 10), nil
+		},
+	})
+	// Booleans use the structured field representation, like in
+	// Sec-CH-UA-Mobile.
+	RegisterHeaderCodec(HeaderCodec[bool]{
+		Parse: func(s string) (bool, error) {
+			item, err := sfv.ParseItem(s)
+			if err != nil {
+				return false, err
+			}
+			v, ok := item.Value.(bool)
+			if !ok {
+				return false, fmt.Errorf("%s is not a boolean", s)
+			}
+			return v, nil
+		},
+		Format: func(v bool) (string, error) {
+			return sfv.FormatItem(sfv.Item{Value: v})
+		},
+	})
+	RegisterHeaderCodec(HeaderCodec[time.Time]{
+		Parse: http.ParseTime,
+		Format: func(t time.Time) (string, error) {
+			return t.UTC().Format(http.TimeFormat), nil
+		},
+	})
+	// Durations are delta-seconds, like in Age, Retry-After, or max-age.
+	RegisterHeaderCodec(HeaderCodec[time.Duration]{
+		Parse: func(s string) (time.Duration, error) {
+			secs, err := strconv.ParseUint(s, 10, 32)
+			if err != nil {
+				return 0, err
+			}
+			return time.Duration(secs) * time.Second, nil
+		},
+		Format: func(d time.Duration) (string, error) {
+			if d < 0 {
+				return "", fmt.Errorf("negative duration %v", d)
+			}
+			return strconv.FormatInt(int64(d/time.Second), 10), nil
+		},
+	})
+	RegisterHeaderCodec(HeaderCodec[[]string]{
+		Parse: func(s string) ([]string, error) {
+			return SplitList(s), nil
+		},
+		Format: func(v []string) (string, error) {
+			return strings.Join(v, ", "), nil
+		},
+	})
+}
+
+func headerCodecOf[T any]() (HeaderCodec[T], error) {
+	typ := reflect.TypeOf((*T)(nil)).Elem()
+	if codec, ok := headerCodecs.Load(typ); ok {
+		return codec.(HeaderCodec[T]), nil
+	}
+
+	var zero T
+	_, canParse := any(&zero).(encoding.TextUnmarshaler)
+	_, canFormat := any(&zero).(encoding.TextMarshaler)
+	if !canParse || !canFormat {
+		return HeaderCodec[T]{}, fmt.Errorf("no header codec for %v", typ)
+	}
+	return HeaderCodec[T]{
+		Parse: func(s string) (T, error) {
+			var v T
+			err := any(&v).(encoding.TextUnmarshaler).UnmarshalText([]byte(s))
+			return v, err
+		},
+		Format: func(v T) (string, error) {
+			text, err := any(&v).(encoding.TextMarshaler).MarshalText()
+			return string(text), err
+		},
+	}, nil
+}
+
+// ErrNoHeader is returned by GetHeader when the header field is absent.
+var ErrNoHeader = errors.New("no such header field")
+
+// GetHeader parses the value of the header field name of h as a value of
+// type T, using the codec registered for T. Multiple field lines are
+// combined into a single, comma-separated value before parsing.
+func GetHeader[T any](h http.Header, name string) (T, error) {
+	var zero T
+	codec, err := headerCodecOf[T]()
+	if err != nil {
+		return zero, err
+	}
+	values := h.Values(name)
+	if len(values) == 0 {
+		return zero, ErrNoHeader
+	}
+	v, err := codec.Parse(strings.Join(values, ", "))
+	if err != nil {
+		return zero, fmt.Errorf("parsing %s: %w", strings.ToLower(name), err)
+	}
+	return v, nil
+}
+
+// SetHeader formats v using the codec registered for T, and sets it as the
+// value of the header field name of h.
+func SetHeader[T any](h http.Header, name string, v T) error {
+	codec, err := headerCodecOf[T]()
+	if err != nil {
+		return err
+	}
+	value, err := codec.Format(v)
+	if err != nil {
+		return fmt.Errorf("formatting %s: %w", strings.ToLower(name), err)
+	}
+	h.Set(name, value)
+	return nil
+}
@@ -0,0 +1,107 @@
+// Copyright 2026 Franklin "Snaipe" Mathieu.
+//
+// Use of this source code is governed by the MIT license that can be
+// found in the LICENSE file.
+
+package htutil
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+)
+
+type upperString string
+
+func (s upperString) MarshalText() ([]byte, error) {
+	return []byte(strings.ToUpper(string(s))), nil
+}
+
+func (s *upperString) UnmarshalText(text []byte) error {
+	*s = upperString(strings.ToLower(string(text)))
+	return nil
+}
+
+func TestGetHeader(t *testing.T) {
+	t.Parallel()
+
+	h := http.Header{
+		"Content-Length":   {"42"},
+		"Date":             {"Sun, 06 Nov 1994 08:49:37 GMT"},
+		"Age":              {"120"},
+		"Sec-Ch-Ua-Mobile": {"?1"},
+		"Vary":             {"Accept, Origin", "Accept-Encoding"},
+		"X-Upper":          {"HELLO"},
+		"X-Bad":            {"nope"},
+	}
+
+	tcases := []struct {
+		Get      func() (interface{}, error)
+		Expected interface{}
+		Err      bool
+	}{
+		{Get: func() (interface{}, error) { return GetHeader[int](h, "Content-Length") }, Expected: 42},
+		{Get: func() (interface{}, error) { return GetHeader[int64](h, "Content-Length") }, Expected: int64(42)},
+		{Get: func() (interface{}, error) { return GetHeader[time.Time](h, "Date") }, Expected: time.Date(1994, 11, 6, 8, 49, 37, 0, time.UTC)},
+		{Get: func() (interface{}, error) { return GetHeader[time.Duration](h, "Age") }, Expected: 2 * time.Minute},
+		{Get: func() (interface{}, error) { return GetHeader[bool](h, "Sec-CH-UA-Mobile") }, Expected: true},
+		{Get: func() (interface{}, error) { return GetHeader[[]string](h, "Vary") }, Expected: []string{"Accept", "Origin", "Accept-Encoding"}},
+		{Get: func() (interface{}, error) { return GetHeader[upperString](h, "X-Upper") }, Expected: upperString("hello")},
+		{Get: func() (interface{}, error) { return GetHeader[int](h, "X-Bad") }, Err: true},
+		{Get: func() (interface{}, error) { return GetHeader[complex64](h, "Content-Length") }, Err: true},
+	}
+
+	for i, tcase := range tcases {
+		t.Run(fmt.Sprintf("%d", i), func(t *testing.T) {
+			v, err := tcase.Get()
+			if tcase.Err {
+				if err == nil {
+					t.Fatalf("expected error, got %v", v)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("expected no error, got %v", err)
+			}
+			if !reflect.DeepEqual(v, tcase.Expected) {
+				t.Fatalf("expected %v, got %v", tcase.Expected, v)
+			}
+		})
+	}
+
+	if _, err := GetHeader[int](h, "X-Missing"); !errors.Is(err, ErrNoHeader) {
+		t.Fatalf("expected ErrNoHeader, got %v", err)
+	}
+}
+
+func TestSetHeader(t *testing.T) {
+	t.Parallel()
+
+	h := http.Header{}
+	SetHeader(h, "Content-Length", 42)
+	SetHeader(h, "Last-Modified", time.Date(1994, 11, 6, 9, 49, 37, 0, time.FixedZone("CET", 3600)))
+	SetHeader(h, "Retry-After", 90*time.Second)
+	SetHeader(h, "Sec-CH-UA-Mobile", false)
+	SetHeader(h, "Vary", []string{"Accept", "Origin"})
+	SetHeader(h, "X-Upper", upperString("hello"))
+
+	expected := http.Header{
+		"Content-Length":   {"42"},
+		"Last-Modified":    {"Sun, 06 Nov 1994 08:49:37 GMT"},
+		"Retry-After":      {"90"},
+		"Sec-Ch-Ua-Mobile": {"?0"},
+		"Vary":             {"Accept, Origin"},
+		"X-Upper":          {"HELLO"},
+	}
+	if !reflect.DeepEqual(h, expected) {
+		t.Fatalf("expected %v, got %v", expected, h)
+	}
+
+	if err := SetHeader(h, "Age", -time.Second); err == nil {
+		t.Fatalf("expected error on negative duration")
+	}
+}
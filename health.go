@@ -0,0 +1,236 @@
+// Copyright 2026 Franklin "Snaipe" Mathieu.
+//
+// Use of this source code is governed by the MIT license that can be
+// found in the LICENSE file.
+
+package htutil
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"sort"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+// CheckKind selects which health endpoints a check participates in.
+// Kinds may be or-ed together.
+type CheckKind int
+
+const (
+	// Liveness checks are served by /healthz, and should only fail when
+	// the process is in a state it cannot recover from without a restart.
+	Liveness CheckKind = 1 << iota
+
+	// Readiness checks are served by /readyz, and should fail whenever
+	// the process should temporarily stop receiving traffic.
+	Readiness
+)
+
+// HealthStatus is the outcome of a health check, or of a set of health checks.
+type HealthStatus string
+
+const (
+	HealthPass HealthStatus = "pass"
+	HealthFail HealthStatus = "fail"
+)
+
+// CheckResult is the outcome of a single named health check.
+type CheckResult struct {
+	Name     string        `json:"name"`
+	Status   HealthStatus  `json:"status"`
+	Error    string        `json:"error,omitempty"`
+	Duration time.Duration `json:"duration"`
+}
+
+// HealthReport is the aggregated outcome of all the health checks of a
+// particular kind. Its status is HealthFail if any of the checks failed.
+type HealthReport struct {
+	Status HealthStatus  `json:"status"`
+	Checks []CheckResult `json:"checks,omitempty"`
+}
+
+type healthCheck struct {
+	name    string
+	kind    CheckKind
+	timeout time.Duration
+	check   func(context.Context) error
+}
+
+// Health is a registry of named health checks, served over HTTP as
+// /healthz (liveness) and /readyz (readiness).
+//
+// The zero value is ready to use, and reports itself as ready.
+type Health struct {
+	mu       sync.RWMutex
+	checks   []healthCheck
+	notReady int32
+}
+
+// Register adds a named check of the specified kinds. Each time the check
+// runs, it is given a context that expires after timeout; a timeout of 0
+// means that the check only gets cancelled when the request does.
+//
+// Registering a check with a name that already exists replaces it.
+func (h *Health) Register(name string, kind CheckKind, timeout time.Duration, check func(ctx context.Context) error) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	hc := healthCheck{name: name, kind: kind, timeout: timeout, check: check}
+	for i := range h.checks {
+		if h.checks[i].name == name {
+			h.checks[i] = hc
+			return
+		}
+	}
+	h.checks = append(h.checks, hc)
+}
+
+// SetReady flips the readiness of the process. While not ready, /readyz
+// fails regardless of the outcome of the registered readiness checks.
+func (h *Health) SetReady(ready bool) {
+	var v int32
+	if !ready {
+		v = 1
+	}
+	atomic.StoreInt32(&h.notReady, v)
+}
+
+// Ready returns whether the process was flagged as ready with SetReady.
+func (h *Health) Ready() bool {
+	return atomic.LoadInt32(&h.notReady) == 0
+}
+
+// Check runs concurrently all the registered checks of the specified kind,
+// and returns the aggregated report. Results are sorted by check name.
+func (h *Health) Check(ctx context.Context, kind CheckKind) HealthReport {
+	h.mu.RLock()
+	var checks []healthCheck
+	for _, hc := range h.checks {
+		if hc.kind&kind != 0 {
+			checks = append(checks, hc)
+		}
+	}
+	h.mu.RUnlock()
+
+	results := make([]CheckResult, len(checks))
+
+	var wg sync.WaitGroup
+	wg.Add(len(checks))
+	for i, hc := range checks {
+		go func(res *CheckResult, hc healthCheck) {
+			defer wg.Done()
+			*res = runCheck(ctx, hc)
+		}(&results[i], hc)
+	}
+	wg.Wait()
+
+	sort.Slice(results, func(i, j int) bool { return results[i].Name < results[j].Name })
+
+	report := HealthReport{Status: HealthPass, Checks: results}
+	if kind&Readiness != 0 && !h.Ready() {
+		report.Status = HealthFail
+	}
+	for _, res := range results {
+		if res.Status != HealthPass {
+			report.Status = HealthFail
+		}
+	}
+	return report
+}
+
+func runCheck(ctx context.Context, hc healthCheck) (res CheckResult) {
+	res.Name = hc.name
+
+	if hc.timeout > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, hc.timeout)
+		defer cancel()
+	}
+
+	start := time.Now()
+	done := make(chan error, 1)
+	go func() {
+		defer func() {
+			if r := recover(); r != nil {
+				done <- fmt.Errorf("panic: %v", r)
+			}
+		}()
+		done <- hc.check(ctx)
+	}()
+
+	var err error
+	select {
+	case err = <-done:
+	case <-ctx.Done():
+		// Don't wait on checks that do not honor context cancellation.
+		err = ctx.Err()
+	}
+	res.Duration = time.Since(start)
+
+	res.Status = HealthPass
+	if err != nil {
+		res.Status = HealthFail
+		res.Error = err.Error()
+	}
+	return res
+}
+
+// Handler returns an http.Handler serving the report of the checks of
+// the specified kind.
+//
+// The report is served as text/plain or application/json depending on
+// the Accept header of the request, with a status of 200 when passing,
+// and 503 when failing. Responses are never cacheable.
+func (h *Health) Handler(kind CheckKind) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+		w.Header().Set("Cache-Control", "no-store")
+		AddVary(w.Header(), "Accept")
+
+		ctype, _ := NegotiateContent(req.Header, "Accept",
+			"text/plain",
+			"application/json",
+		)
+		if ctype == "" {
+			w.WriteHeader(http.StatusNotAcceptable)
+			return
+		}
+
+		report := h.Check(req.Context(), kind)
+
+		status := http.StatusOK
+		if report.Status != HealthPass {
+			status = http.StatusServiceUnavailable
+		}
+
+		w.Header().Set("Content-Type", ctype+"; charset=utf-8")
+		w.WriteHeader(status)
+		if req.Method == http.MethodHead {
+			return
+		}
+
+		switch ctype {
+		case "application/json":
+			json.NewEncoder(w).Encode(report)
+		case "text/plain":
+			fmt.Fprintln(w, report.Status)
+			for _, res := range report.Checks {
+				if res.Error != "" {
+					fmt.Fprintf(w, "%s %s: %s\n", res.Name, res.Status, res.Error)
+				} else {
+					fmt.Fprintf(w, "%s %s\n", res.Name, res.Status)
+				}
+			}
+		}
+	})
+}
+
+// Install registers the liveness and readiness handlers on mux, under
+// /healthz and /readyz respectively.
+func (h *Health) Install(mux *http.ServeMux) {
+	mux.Handle("/healthz", h.Handler(Liveness))
+	mux.Handle("/readyz", h.Handler(Readiness))
+}
@@ -0,0 +1,114 @@
+// Copyright 2026 Franklin "Snaipe" Mathieu.
+//
+// Use of this source code is governed by the MIT license that can be
+// found in the LICENSE file.
+
+package htutil
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+)
+
+func TestHealth(t *testing.T) {
+	t.Parallel()
+
+	var health Health
+	health.Register("db", Liveness|Readiness, 0, func(ctx context.Context) error {
+		return nil
+	})
+	health.Register("cache", Readiness, 0, func(ctx context.Context) error {
+		return errors.New("unreachable")
+	})
+	health.Register("slow", Liveness, 10*time.Millisecond, func(ctx context.Context) error {
+		<-ctx.Done()
+		return ctx.Err()
+	})
+
+	tcases := []struct {
+		Kind   CheckKind
+		Status HealthStatus
+		Checks map[string]HealthStatus
+	}{
+		{
+			Kind:   Liveness,
+			Status: HealthFail,
+			Checks: map[string]HealthStatus{"db": HealthPass, "slow": HealthFail},
+		},
+		{
+			Kind:   Readiness,
+			Status: HealthFail,
+			Checks: map[string]HealthStatus{"db": HealthPass, "cache": HealthFail},
+		},
+	}
+
+	for i, tcase := range tcases {
+		t.Run(fmt.Sprintf("%d", i), func(t *testing.T) {
+			report := health.Check(context.Background(), tcase.Kind)
+			if report.Status != tcase.Status {
+				t.Fatalf("expected status %v, got %v", tcase.Status, report.Status)
+			}
+			if len(report.Checks) != len(tcase.Checks) {
+				t.Fatalf("expected %d checks, got %v", len(tcase.Checks), report.Checks)
+			}
+			for _, res := range report.Checks {
+				if res.Status != tcase.Checks[res.Name] {
+					t.Fatalf("expected check %s to be %v, got %v", res.Name, tcase.Checks[res.Name], res.Status)
+				}
+			}
+		})
+	}
+}
+
+func TestHealthHandler(t *testing.T) {
+	t.Parallel()
+
+	var health Health
+	health.Register("db", Readiness, 0, func(ctx context.Context) error { return nil })
+
+	mux := http.NewServeMux()
+	health.Install(mux)
+
+	get := func(accept string) *http.Response {
+		req := httptest.NewRequest("GET", "/readyz", nil)
+		req.Header.Set("Accept", accept)
+		w := httptest.NewRecorder()
+		mux.ServeHTTP(w, req)
+		return w.Result()
+	}
+
+	resp := get("application/json")
+	if resp.StatusCode != http.StatusOK {
+		t.Fatalf("expected status 200, got %v", resp.StatusCode)
+	}
+	if cc := resp.Header.Get("Cache-Control"); cc != "no-store" {
+		t.Fatalf("expected Cache-Control no-store, got %q", cc)
+	}
+	var report HealthReport
+	if err := json.NewDecoder(resp.Body).Decode(&report); err != nil {
+		t.Fatal(err)
+	}
+	if report.Status != HealthPass || len(report.Checks) != 1 {
+		t.Fatalf("unexpected report %+v", report)
+	}
+
+	health.SetReady(false)
+	resp = get("text/plain")
+	if resp.StatusCode != http.StatusServiceUnavailable {
+		t.Fatalf("expected status 503, got %v", resp.StatusCode)
+	}
+	if ctype := resp.Header.Get("Content-Type"); ctype != "text/plain; charset=utf-8" {
+		t.Fatalf("expected text/plain content type, got %q", ctype)
+	}
+
+	resp = get("image/png")
+	if resp.StatusCode != http.StatusNotAcceptable {
+		t.Fatalf("expected status 406, got %v", resp.StatusCode)
+	}
+}
@@ -0,0 +1,58 @@
+// Copyright 2026 Franklin "Snaipe" Mathieu.
+//
+// Use of this source code is governed by the MIT license that can be
+// found in the LICENSE file.
+
+package htutil
+
+import (
+	"net/http"
+	"net/textproto"
+)
+
+// hopByHopHeaders is the set of header fields that are always hop-by-hop,
+// as per RFC 9110 §7.6.1, as well as the non-standard but widely used
+// Proxy-Connection.
+var hopByHopHeaders = map[string]struct{}{
+	"Connection":          {},
+	"Proxy-Connection":    {},
+	"Keep-Alive":          {},
+	"Proxy-Authenticate":  {},
+	"Proxy-Authorization": {},
+	"Te":                  {},
+	"Trailer":             {},
+	"Transfer-Encoding":   {},
+	"Upgrade":             {},
+}
+
+// IsHopByHop returns whether the named header field is hop-by-hop, and as
+// such must not be forwarded by proxies or stored by caches. connection is
+// the list of values of the Connection header field, which may name
+// additional hop-by-hop fields.
+func IsHopByHop(name string, connection []string) bool {
+	name = textproto.CanonicalMIMEHeaderKey(name)
+	if _, ok := hopByHopHeaders[name]; ok {
+		return true
+	}
+	for _, v := range connection {
+		for _, opt := range SplitList(v) {
+			if textproto.CanonicalMIMEHeaderKey(opt) == name {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// StripHopByHop removes from h all the hop-by-hop header fields, including
+// the ones listed in its Connection header field.
+func StripHopByHop(h http.Header) {
+	for _, v := range h.Values("Connection") {
+		for _, opt := range SplitList(v) {
+			h.Del(opt)
+		}
+	}
+	for name := range hopByHopHeaders {
+		h.Del(name)
+	}
+}
@@ -0,0 +1,61 @@
+// Copyright 2026 Franklin "Snaipe" Mathieu.
+//
+// Use of this source code is governed by the MIT license that can be
+// found in the LICENSE file.
+
+package htutil
+
+import (
+	"fmt"
+	"net/http"
+	"reflect"
+	"testing"
+)
+
+func TestIsHopByHop(t *testing.T) {
+	t.Parallel()
+
+	tcases := []struct {
+		Name       string
+		Connection []string
+		Expected   bool
+	}{
+		{Name: "Connection", Expected: true},
+		{Name: "transfer-encoding", Expected: true},
+		{Name: "TE", Expected: true},
+		{Name: "Content-Type", Expected: false},
+		{Name: "X-Custom", Connection: []string{"keep-alive, x-custom"}, Expected: true},
+		{Name: "X-Custom", Connection: []string{"close", "X-Other"}, Expected: false},
+	}
+
+	for i, tcase := range tcases {
+		t.Run(fmt.Sprintf("%d", i), func(t *testing.T) {
+			actual := IsHopByHop(tcase.Name, tcase.Connection)
+			if actual != tcase.Expected {
+				t.Fatalf("expected %v, got %v", tcase.Expected, actual)
+			}
+		})
+	}
+}
+
+func TestStripHopByHop(t *testing.T) {
+	t.Parallel()
+
+	h := http.Header{
+		"Connection":        {"close, X-Private"},
+		"Keep-Alive":        {"timeout=5"},
+		"Transfer-Encoding": {"chunked"},
+		"X-Private":         {"secret"},
+		"Content-Type":      {"text/plain"},
+		"Cache-Control":     {"max-age=60"},
+	}
+	StripHopByHop(h)
+
+	expected := http.Header{
+		"Content-Type":  {"text/plain"},
+		"Cache-Control": {"max-age=60"},
+	}
+	if !reflect.DeepEqual(h, expected) {
+		t.Fatalf("expected %v, got %v", expected, h)
+	}
+}
@@ -0,0 +1,49 @@
+// Copyright 2026 Franklin "Snaipe" Mathieu.
+//
+// Use of this source code is governed by the MIT license that can be
+// found in the LICENSE file.
+
+// Package httptestutil provides test helpers for HTTP handlers, with an
+// emphasis on content negotiation and caching behavior.
+package httptestutil
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+// Serve serves req with h, and returns the recorded response.
+func Serve(h http.Handler, req *http.Request) *http.Response {
+	w := httptest.NewRecorder()
+	h.ServeHTTP(w, req)
+	return w.Result()
+}
+
+// AssertNegotiates checks that h responds to a GET request with the
+// specified Accept header with a representation of type want, and that the
+// response declares that it varies on Accept.
+func AssertNegotiates(t testing.TB, h http.Handler, accept, want string) {
+	t.Helper()
+
+	req := httptest.NewRequest(http.MethodGet, "/", nil)
+	if accept != "" {
+		req.Header.Set("Accept", accept)
+	}
+	AssertResponse(t, Serve(h, req), ContentType(want), Vary("Accept"))
+}
+
+// AssertVary checks that the Vary header field of resp lists all the
+// specified request header fields.
+func AssertVary(t testing.TB, resp *http.Response, fields ...string) {
+	t.Helper()
+	AssertResponse(t, resp, Vary(fields...))
+}
+
+// AssertCacheControl checks that the Cache-Control header field of resp has
+// all the specified directives. A directive of the form "name=value" also
+// checks the value of the directive.
+func AssertCacheControl(t testing.TB, resp *http.Response, directives ...string) {
+	t.Helper()
+	AssertResponse(t, resp, CacheControl(directives...))
+}
@@ -0,0 +1,59 @@
+// Copyright 2026 Franklin "Snaipe" Mathieu.
+//
+// Use of this source code is governed by the MIT license that can be
+// found in the LICENSE file.
+
+package httptestutil
+
+import (
+	"fmt"
+	"net/http"
+	"testing"
+
+	"snai.pe/go-htutil"
+)
+
+// recordingT records the failures reported by assertions.
+type recordingT struct {
+	testing.TB
+	errors []string
+}
+
+func (t *recordingT) Helper() {}
+
+func (t *recordingT) Errorf(format string, args ...interface{}) {
+	t.errors = append(t.errors, fmt.Sprintf(format, args...))
+}
+
+var negotiating = http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+	ctype, _ := htutil.NegotiateContent(req.Header, "Accept", "text/plain", "application/json")
+	w.Header().Set("Vary", "Accept, Accept-Encoding")
+	w.Header().Set("Cache-Control", `public, max-age=60, no-cache="Set-Cookie"`)
+	w.Header().Set("Content-Type", ctype+"; charset=utf-8")
+	fmt.Fprint(w, "hello")
+})
+
+func TestAssertNegotiates(t *testing.T) {
+	t.Parallel()
+
+	tcases := []struct {
+		Accept string
+		Want   string
+		Fails  bool
+	}{
+		{Accept: "", Want: "text/plain"},
+		{Accept: "application/json", Want: "application/json"},
+		{Accept: "application/*;q=0.5, text/plain;q=0.1", Want: "application/json"},
+		{Accept: "application/json", Want: "text/plain", Fails: true},
+	}
+
+	for i, tcase := range tcases {
+		t.Run(fmt.Sprintf("%d", i), func(t *testing.T) {
+			rt := &recordingT{TB: t}
+			AssertNegotiates(rt, negotiating, tcase.Accept, tcase.Want)
+			if failed := len(rt.errors) > 0; failed != tcase.Fails {
+				t.Fatalf("expected failure %v, got %v", tcase.Fails, rt.errors)
+			}
+		})
+	}
+}
@@ -0,0 +1,217 @@
+// Copyright 2026 Franklin "Snaipe" Mathieu.
+//
+// Use of this source code is governed by the MIT license that can be
+// found in the LICENSE file.
+
+package httptestutil
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"mime"
+	"net/http"
+	"strings"
+	"testing"
+
+	"snai.pe/go-htutil"
+)
+
+// Matcher checks a property of a response, and returns a descriptive error
+// if the response does not have it.
+type Matcher func(resp *http.Response) error
+
+// AssertResponse checks resp against all the specified matchers, and reports
+// every mismatch to t. The body of resp is buffered so that each matcher can
+// read it in full, and remains readable afterwards.
+func AssertResponse(t testing.TB, resp *http.Response, matchers ...Matcher) {
+	t.Helper()
+
+	body, err := bufferBody(resp)
+	if err != nil {
+		t.Errorf("%v", err)
+	}
+	for _, match := range matchers {
+		resp.Body = io.NopCloser(bytes.NewReader(body))
+		if err := match(resp); err != nil {
+			t.Errorf("%v", err)
+		}
+	}
+	resp.Body = io.NopCloser(bytes.NewReader(body))
+}
+
+// bufferBody reads and closes the body of resp.
+func bufferBody(resp *http.Response) ([]byte, error) {
+	if resp.Body == nil {
+		return nil, nil
+	}
+	body, err := io.ReadAll(resp.Body)
+	resp.Body.Close()
+	if err != nil {
+		return body, fmt.Errorf("reading response body: %w", err)
+	}
+	return body, nil
+}
+
+// All returns a matcher that matches responses matched by all the specified
+// matchers. Like with AssertResponse, each matcher can read the body in
+// full.
+func All(matchers ...Matcher) Matcher {
+	return func(resp *http.Response) error {
+		body, err := bufferBody(resp)
+		defer func() { resp.Body = io.NopCloser(bytes.NewReader(body)) }()
+		if err != nil {
+			return err
+		}
+		for _, match := range matchers {
+			resp.Body = io.NopCloser(bytes.NewReader(body))
+			if err := match(resp); err != nil {
+				return err
+			}
+		}
+		return nil
+	}
+}
+
+// Status matches responses with the specified status code.
+func Status(code int) Matcher {
+	return func(resp *http.Response) error {
+		if resp.StatusCode != code {
+			return fmt.Errorf("expected status %d, got %d", code, resp.StatusCode)
+		}
+		return nil
+	}
+}
+
+// Header matches responses whose header field name has exactly the
+// specified value.
+func Header(name, value string) Matcher {
+	return func(resp *http.Response) error {
+		if got := resp.Header.Values(name); len(got) != 1 || got[0] != value {
+			return fmt.Errorf("expected %s %q, got %q", name, value, got)
+		}
+		return nil
+	}
+}
+
+// NoHeader matches responses without the header field name.
+func NoHeader(name string) Matcher {
+	return func(resp *http.Response) error {
+		if got := resp.Header.Values(name); len(got) != 0 {
+			return fmt.Errorf("expected no %s, got %q", name, got)
+		}
+		return nil
+	}
+}
+
+// HeaderContains matches responses whose list-based header field name has
+// the specified members, compared case-insensitively.
+func HeaderContains(name string, members ...string) Matcher {
+	return func(resp *http.Response) error {
+		got := listMembers(resp.Header.Values(name))
+		for _, member := range members {
+			if !containsFold(got, member) {
+				return fmt.Errorf("expected %s to contain %q, got %q", name, member, got)
+			}
+		}
+		return nil
+	}
+}
+
+// ContentType matches responses with the specified media type, regardless
+// of its parameters.
+func ContentType(mediaType string) Matcher {
+	return func(resp *http.Response) error {
+		got, _, err := mime.ParseMediaType(resp.Header.Get("Content-Type"))
+		if err != nil || !strings.EqualFold(got, mediaType) {
+			return fmt.Errorf("expected Content-Type %s, got %q", mediaType, resp.Header.Get("Content-Type"))
+		}
+		return nil
+	}
+}
+
+// Vary matches responses whose Vary header field lists all the specified
+// request header fields.
+func Vary(fields ...string) Matcher {
+	return HeaderContains("Vary", fields...)
+}
+
+// CacheControl matches responses whose Cache-Control header field has all
+// the specified directives. A directive of the form "name=value" also
+// checks the value of the directive.
+func CacheControl(directives ...string) Matcher {
+	return func(resp *http.Response) error {
+		got := listMembers(resp.Header.Values("Cache-Control"))
+		for _, directive := range directives {
+			if !hasDirective(got, directive) {
+				return fmt.Errorf("expected Cache-Control to have %s, got %q", directive, got)
+			}
+		}
+		return nil
+	}
+}
+
+// Body matches responses whose body is exactly s.
+func Body(s string) Matcher {
+	return func(resp *http.Response) error {
+		body, err := io.ReadAll(resp.Body)
+		if err != nil {
+			return fmt.Errorf("reading response body: %w", err)
+		}
+		if string(body) != s {
+			return fmt.Errorf("expected body %q, got %q", s, body)
+		}
+		return nil
+	}
+}
+
+// BodyContains matches responses whose body contains s.
+func BodyContains(s string) Matcher {
+	return func(resp *http.Response) error {
+		body, err := io.ReadAll(resp.Body)
+		if err != nil {
+			return fmt.Errorf("reading response body: %w", err)
+		}
+		if !strings.Contains(string(body), s) {
+			return fmt.Errorf("expected body containing %q, got %q", s, body)
+		}
+		return nil
+	}
+}
+
+func listMembers(values []string) []string {
+	var members []string
+	for _, v := range values {
+		members = append(members, htutil.SplitList(v)...)
+	}
+	return members
+}
+
+func containsFold(members []string, want string) bool {
+	for _, m := range members {
+		if strings.EqualFold(m, want) {
+			return true
+		}
+	}
+	return false
+}
+
+func hasDirective(members []string, want string) bool {
+	wname, wvalue, hasValue := strings.Cut(want, "=")
+	for _, m := range members {
+		name, value, _ := strings.Cut(m, "=")
+		if !strings.EqualFold(strings.TrimSpace(name), wname) {
+			continue
+		}
+		if !hasValue {
+			return true
+		}
+		if uv, err := htutil.UnquoteString(strings.TrimSpace(value)); err == nil {
+			value = uv
+		}
+		if strings.TrimSpace(value) == wvalue {
+			return true
+		}
+	}
+	return false
+}
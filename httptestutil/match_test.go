@@ -0,0 +1,46 @@
+// Copyright 2026 Franklin "Snaipe" Mathieu.
+//
+// Use of this source code is governed by the MIT license that can be
+// found in the LICENSE file.
+
+package httptestutil
+
+import (
+	"fmt"
+	"net/http"
+	"testing"
+)
+
+func TestAssertResponse(t *testing.T) {
+	t.Parallel()
+
+	tcases := []struct {
+		Matchers []Matcher
+		Failures int
+	}{
+		{Matchers: []Matcher{Status(200), ContentType("text/plain"), Body("hello"), BodyContains("ell")}},
+		{Matchers: []Matcher{Vary("accept-encoding", "Accept"), NoHeader("Set-Cookie")}},
+		{Matchers: []Matcher{CacheControl("public", "max-age=60", "no-cache=Set-Cookie")}},
+		{Matchers: []Matcher{All(Status(200), Header("Content-Type", "text/plain; charset=utf-8"))}},
+		{Matchers: []Matcher{All(Body("hello"), BodyContains("ell"), Body("hello")), Body("hello")}},
+		{Matchers: []Matcher{All(Body("hello"), Body("bye"))}, Failures: 1},
+		{Matchers: []Matcher{Status(404), Body("bye")}, Failures: 2},
+		{Matchers: []Matcher{Vary("Origin"), CacheControl("max-age=30"), CacheControl("private")}, Failures: 3},
+	}
+
+	for i, tcase := range tcases {
+		t.Run(fmt.Sprintf("%d", i), func(t *testing.T) {
+			rt := &recordingT{TB: t}
+			resp := Serve(negotiating, newRequest())
+			AssertResponse(rt, resp, tcase.Matchers...)
+			if len(rt.errors) != tcase.Failures {
+				t.Fatalf("expected %d failures, got %v", tcase.Failures, rt.errors)
+			}
+		})
+	}
+}
+
+func newRequest() *http.Request {
+	req, _ := http.NewRequest("GET", "http://example.com/", nil)
+	return req
+}
@@ -0,0 +1,290 @@
+// Copyright 2026 Franklin "Snaipe" Mathieu.
+//
+// Use of this source code is governed by the MIT license that can be
+// found in the LICENSE file.
+
+package httptestutil
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"net/http"
+	"regexp"
+	"strings"
+	"sync"
+	"testing"
+)
+
+// MockTransport is a http.RoundTripper serving stubbed responses to
+// expected requests. Requests matching no expectation fail the test, and
+// so do expectations that were not satisfied by the end of the test.
+type MockTransport struct {
+	t            testing.TB
+	mu           sync.Mutex
+	expectations []*Expectation
+}
+
+// NewMockTransport returns a new MockTransport reporting failures to t.
+// Unsatisfied expectations are reported when the test completes.
+func NewMockTransport(t testing.TB) *MockTransport {
+	m := &MockTransport{t: t}
+	t.Cleanup(m.Verify)
+	return m
+}
+
+// Client returns a http.Client using m as its transport.
+func (m *MockTransport) Client() *http.Client {
+	return &http.Client{Transport: m}
+}
+
+// Expect declares an expected request with the specified method, whose URL
+// matches pattern.
+//
+// The pattern is a level 1 URI template (RFC 6570), optionally with
+// reserved expansions: {var} matches any sequence of characters except
+// '/', '?', and '#', while {+var} matches any sequence of characters.
+// Patterns starting with '/' are matched against the path of the request
+// URL, or against its path and query if the pattern contains '?'; other
+// patterns are matched against the full request URL.
+//
+// By default, an expectation must be met exactly once, and results in an
+// empty 200 OK response.
+func (m *MockTransport) Expect(method, pattern string) *Expectation {
+	re, names := compileTemplate(pattern)
+	e := &Expectation{
+		method:  method,
+		pattern: pattern,
+		re:      re,
+		names:   names,
+		times:   1,
+		respond: func(*http.Request, map[string]string) (*http.Response, error) {
+			return stubResponse(http.StatusOK, nil, ""), nil
+		},
+	}
+	m.mu.Lock()
+	m.expectations = append(m.expectations, e)
+	m.mu.Unlock()
+	return e
+}
+
+// RoundTrip implements http.RoundTripper. Expectations are tried in the
+// order in which they were declared, skipping the ones that were already
+// met the expected number of times.
+func (m *MockTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	var body []byte
+	if req.Body != nil {
+		var err error
+		body, err = io.ReadAll(req.Body)
+		req.Body.Close()
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	m.mu.Lock()
+	var (
+		matched *Expectation
+		vars    map[string]string
+		reasons []string
+	)
+	for _, e := range m.expectations {
+		if e.times >= 0 && e.calls >= e.times {
+			continue
+		}
+		v, err := e.match(req, body)
+		if err != nil {
+			reasons = append(reasons, fmt.Sprintf("%s %s: %v", e.method, e.pattern, err))
+			continue
+		}
+		matched, vars = e, v
+		e.calls++
+		break
+	}
+	m.mu.Unlock()
+
+	if matched == nil {
+		m.t.Errorf("unexpected request %s %s\n\t%s", req.Method, req.URL, strings.Join(reasons, "\n\t"))
+		return nil, fmt.Errorf("unexpected request %s %s", req.Method, req.URL)
+	}
+
+	req.Body = io.NopCloser(bytes.NewReader(body))
+	resp, err := matched.respond(req, vars)
+	if resp != nil && resp.Request == nil {
+		resp.Request = req
+	}
+	return resp, err
+}
+
+// Verify reports every expectation that was not met the expected number of
+// times. It is called automatically when the test completes.
+func (m *MockTransport) Verify() {
+	m.t.Helper()
+
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	for _, e := range m.expectations {
+		if e.times >= 0 && e.calls != e.times {
+			m.t.Errorf("expected %d calls to %s %s, got %d", e.times, e.method, e.pattern, e.calls)
+		}
+	}
+}
+
+// Expectation is an expected request declared with MockTransport.Expect.
+// Its methods must not be called concurrently with requests.
+type Expectation struct {
+	method  string
+	pattern string
+	re      *regexp.Regexp
+	names   []string
+	header  http.Header
+	body    []func([]byte) error
+	times   int
+	calls   int
+	respond func(*http.Request, map[string]string) (*http.Response, error)
+}
+
+// WithHeader restricts e to requests whose header field name has the
+// specified value.
+func (e *Expectation) WithHeader(name, value string) *Expectation {
+	if e.header == nil {
+		e.header = http.Header{}
+	}
+	e.header.Add(name, value)
+	return e
+}
+
+// WithBody restricts e to requests whose body is exactly body.
+func (e *Expectation) WithBody(body string) *Expectation {
+	return e.WithBodyMatching(func(b []byte) error {
+		if string(b) != body {
+			return fmt.Errorf("expected body %q, got %q", body, b)
+		}
+		return nil
+	})
+}
+
+// WithBodyMatching restricts e to requests whose body is accepted by match.
+func (e *Expectation) WithBodyMatching(match func(body []byte) error) *Expectation {
+	e.body = append(e.body, match)
+	return e
+}
+
+// Times sets the number of times e must be met.
+func (e *Expectation) Times(n int) *Expectation {
+	e.times = n
+	return e
+}
+
+// AnyTimes allows e to be met any number of times, including none.
+func (e *Expectation) AnyTimes() *Expectation {
+	e.times = -1
+	return e
+}
+
+// Respond sets the response returned to requests meeting e.
+func (e *Expectation) Respond(status int, header http.Header, body string) *Expectation {
+	e.respond = func(*http.Request, map[string]string) (*http.Response, error) {
+		return stubResponse(status, header, body), nil
+	}
+	return e
+}
+
+// RespondWith sets a function computing the response returned to requests
+// meeting e, given the request and the values of the template variables
+// of the pattern.
+func (e *Expectation) RespondWith(respond func(req *http.Request, vars map[string]string) (*http.Response, error)) *Expectation {
+	e.respond = respond
+	return e
+}
+
+func (e *Expectation) match(req *http.Request, body []byte) (map[string]string, error) {
+	if req.Method != e.method {
+		return nil, fmt.Errorf("method is %s", req.Method)
+	}
+
+	target := req.URL.String()
+	if strings.HasPrefix(e.pattern, "/") {
+		target = req.URL.EscapedPath()
+		if strings.Contains(e.pattern, "?") {
+			target = req.URL.RequestURI()
+		}
+	}
+	m := e.re.FindStringSubmatch(target)
+	if m == nil {
+		return nil, fmt.Errorf("URL is %s", target)
+	}
+	vars := make(map[string]string, len(e.names))
+	for i, name := range e.names {
+		vars[name] = m[i+1]
+	}
+
+	for name, values := range e.header {
+		for _, v := range values {
+			if !containsValue(req.Header.Values(name), v) {
+				return nil, fmt.Errorf("%s is %q", name, req.Header.Values(name))
+			}
+		}
+	}
+	for _, match := range e.body {
+		if err := match(body); err != nil {
+			return nil, err
+		}
+	}
+	return vars, nil
+}
+
+func containsValue(values []string, want string) bool {
+	for _, v := range values {
+		if v == want {
+			return true
+		}
+	}
+	return false
+}
+
+// compileTemplate compiles a URI template into a regular expression, and
+// returns it along with the names of the template variables, in order.
+func compileTemplate(pattern string) (*regexp.Regexp, []string) {
+	var (
+		expr  strings.Builder
+		names []string
+	)
+	expr.WriteByte('^')
+	for {
+		start := strings.IndexByte(pattern, '{')
+		end := strings.IndexByte(pattern, '}')
+		if start < 0 || end < start {
+			expr.WriteString(regexp.QuoteMeta(pattern))
+			break
+		}
+		expr.WriteString(regexp.QuoteMeta(pattern[:start]))
+		name := pattern[start+1 : end]
+		if strings.HasPrefix(name, "+") {
+			expr.WriteString("(.*)")
+			name = name[1:]
+		} else {
+			expr.WriteString("([^/?#]*)")
+		}
+		names = append(names, name)
+		pattern = pattern[end+1:]
+	}
+	expr.WriteByte('$')
+	return regexp.MustCompile(expr.String()), names
+}
+
+func stubResponse(status int, header http.Header, body string) *http.Response {
+	if header == nil {
+		header = http.Header{}
+	}
+	return &http.Response{
+		Status:        fmt.Sprintf("%d %s", status, http.StatusText(status)),
+		StatusCode:    status,
+		Proto:         "HTTP/1.1",
+		ProtoMajor:    1,
+		ProtoMinor:    1,
+		Header:        header.Clone(),
+		Body:          io.NopCloser(strings.NewReader(body)),
+		ContentLength: int64(len(body)),
+	}
+}
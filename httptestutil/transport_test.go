@@ -0,0 +1,112 @@
+// Copyright 2026 Franklin "Snaipe" Mathieu.
+//
+// Use of this source code is governed by the MIT license that can be
+// found in the LICENSE file.
+
+package httptestutil
+
+import (
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+	"testing"
+)
+
+func TestMockTransport(t *testing.T) {
+	t.Parallel()
+
+	m := NewMockTransport(t)
+	m.Expect("GET", "/users/{id}").
+		WithHeader("Accept", "application/json").
+		Times(2).
+		RespondWith(func(req *http.Request, vars map[string]string) (*http.Response, error) {
+			return stubResponse(200, http.Header{"Content-Type": {"application/json"}}, `{"id":"`+vars["id"]+`"}`), nil
+		})
+	m.Expect("POST", "https://api.example.com/users").
+		WithBody(`{"name":"alice"}`).
+		Respond(201, http.Header{"Location": {"/users/2"}}, "")
+	m.Expect("GET", "/files/{+path}?v={v}").AnyTimes()
+
+	client := m.Client()
+
+	for _, id := range []string{"1", "2"} {
+		req, _ := http.NewRequest("GET", "https://api.example.com/users/"+id, nil)
+		req.Header.Set("Accept", "application/json")
+		resp, err := client.Do(req)
+		if err != nil {
+			t.Fatal(err)
+		}
+		body, _ := io.ReadAll(resp.Body)
+		if expected := `{"id":"` + id + `"}`; string(body) != expected {
+			t.Fatalf("expected body %q, got %q", expected, body)
+		}
+	}
+
+	resp, err := client.Post("https://api.example.com/users", "application/json", strings.NewReader(`{"name":"alice"}`))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if resp.StatusCode != 201 || resp.Header.Get("Location") != "/users/2" {
+		t.Fatalf("expected 201 with Location /users/2, got %d %q", resp.StatusCode, resp.Header.Get("Location"))
+	}
+
+	if _, err := client.Get("https://api.example.com/files/a/b/c.txt?v=3"); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestMockTransportFailures(t *testing.T) {
+	t.Parallel()
+
+	tcases := []struct {
+		Declare  func(m *MockTransport)
+		Requests []string
+		Failures int
+	}{
+		{
+			// Unexpected request.
+			Declare:  func(m *MockTransport) {},
+			Requests: []string{"/a"},
+			Failures: 1,
+		},
+		{
+			// Missing call.
+			Declare:  func(m *MockTransport) { m.Expect("GET", "/a") },
+			Failures: 1,
+		},
+		{
+			// Called too many times.
+			Declare:  func(m *MockTransport) { m.Expect("GET", "/a") },
+			Requests: []string{"/a", "/a"},
+			Failures: 1,
+		},
+		{
+			// Header mismatch, and missing call.
+			Declare:  func(m *MockTransport) { m.Expect("GET", "/a").WithHeader("Accept", "text/plain") },
+			Requests: []string{"/a"},
+			Failures: 2,
+		},
+		{
+			// Template variables do not match across segments.
+			Declare:  func(m *MockTransport) { m.Expect("GET", "/{id}").AnyTimes() },
+			Requests: []string{"/a", "/a/b"},
+			Failures: 1,
+		},
+	}
+
+	for i, tcase := range tcases {
+		t.Run(fmt.Sprintf("%d", i), func(t *testing.T) {
+			rt := &recordingT{TB: t}
+			m := &MockTransport{t: rt}
+			tcase.Declare(m)
+			for _, path := range tcase.Requests {
+				m.Client().Get("http://example.com" + path)
+			}
+			m.Verify()
+			if len(rt.errors) != tcase.Failures {
+				t.Fatalf("expected %d failures, got %v", tcase.Failures, rt.errors)
+			}
+		})
+	}
+}
@@ -0,0 +1,121 @@
+// Copyright 2026 Franklin "Snaipe" Mathieu.
+//
+// Use of this source code is governed by the MIT license that can be
+// found in the LICENSE file.
+
+package htutil
+
+import (
+	"net/http"
+	"strings"
+)
+
+// languageRangeMatches returns whether the language range matches tag,
+// as per the basic filtering scheme of RFC 4647 §3.3.1: the range is either
+// "*", or equal to tag or to one of its prefixes ending at a subtag
+// boundary, case-insensitively.
+func languageRangeMatches(rng, tag string) bool {
+	if rng == "*" || strings.EqualFold(rng, tag) {
+		return true
+	}
+	return len(tag) > len(rng) && tag[len(rng)] == '-' && strings.EqualFold(tag[:len(rng)], rng)
+}
+
+// languageLookupMatches returns whether tag is found by progressively
+// truncating the language range, as per the lookup scheme of RFC 4647
+// §3.4; e.g. the range de-CH-1996 looks up de-CH, then de.
+func languageLookupMatches(rng, tag string) bool {
+	for {
+		i := strings.LastIndexByte(rng, '-')
+		if i < 0 {
+			return false
+		}
+		rng = rng[:i]
+		if j := strings.LastIndexByte(rng, '-'); j >= 0 && len(rng)-j == 2 {
+			// Singletons are truncated along with the subtag that
+			// follows them.
+			rng = rng[:j]
+		}
+		if strings.EqualFold(rng, tag) {
+			return true
+		}
+	}
+}
+
+// NegotiateLanguage returns the offered language tag best matching the
+// Accept-Language field of hdr, as well as the language range that it
+// matched against.
+//
+// Offers match the ranges that are prefixes of them, like en for en-US,
+// as per the basic filtering scheme of RFC 4647; each offer gets the
+// quality of the most specific range matching it, so that
+// "fr-CH, fr;q=0" accepts fr-CH but no other French. Offers that no range
+// matches may still be looked up from ranges that are more specific, like
+// en for en-US, as per the lookup scheme of RFC 4647. Among offers of the
+// same quality, filtered offers take precedence over looked up ones, which
+// take precedence over the ones only matching the wildcard.
+//
+// Ties are broken by the order of offers. If hdr has no Accept-Language,
+// the first offer is returned. If no offer is acceptable, ("", nil) is
+// returned.
+func NegotiateLanguage(hdr http.Header, offers ...string) (string, *Acceptable) {
+	values := hdr.Values("Accept-Language")
+	if len(values) == 0 {
+		if len(offers) == 0 {
+			return "", nil
+		}
+		return offers[0], &Acceptable{Value: "*", Quality: 1}
+	}
+	ranges := ParseAccept(values...)
+
+	var (
+		best      string
+		bestMatch *Acceptable
+		bestRank  int
+	)
+	for _, offer := range offers {
+		match, rank := languageOfferMatch(ranges, offer)
+		if match == nil || match.Quality == 0 {
+			continue
+		}
+		if bestMatch == nil || (match.Quality > bestMatch.Quality && !qualityEq(match.Quality, bestMatch.Quality)) ||
+			(qualityEq(match.Quality, bestMatch.Quality) && languageMatchClass(rank) > languageMatchClass(bestRank)) {
+			best, bestMatch, bestRank = offer, match, rank
+		}
+	}
+	return best, bestMatch
+}
+
+// languageOfferMatch returns the range of ranges determining the quality
+// of offer, along with its match rank, or nil if no range matches offer.
+// The range is, by order of precedence, the most specific range filtering
+// offer, then the preferred range looking it up, then the wildcard.
+func languageOfferMatch(ranges []Acceptable, offer string) (*Acceptable, int) {
+	var match *Acceptable
+	rank := -1
+	for i := range ranges {
+		rng := &ranges[i]
+		r := -1
+		switch {
+		case rng.Value == "*":
+			r = 0
+		case languageRangeMatches(rng.Value, offer):
+			r = 2 + len(rng.Value)
+		case languageLookupMatches(rng.Value, offer):
+			r = 1
+		}
+		if r > rank || (r == 1 && rank == 1 && rng.Quality > match.Quality) {
+			match, rank = rng, r
+		}
+	}
+	return match, rank
+}
+
+// languageMatchClass returns the class of a match rank of NegotiateLanguage:
+// 0 for the wildcard, 1 for lookups, and 2 for filters.
+func languageMatchClass(rank int) int {
+	if rank > 2 {
+		return 2
+	}
+	return rank
+}
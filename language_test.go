@@ -0,0 +1,66 @@
+// Copyright 2026 Franklin "Snaipe" Mathieu.
+//
+// Use of this source code is governed by the MIT license that can be
+// found in the LICENSE file.
+
+package htutil
+
+import (
+	"fmt"
+	"net/http"
+	"testing"
+)
+
+func TestNegotiateLanguage(t *testing.T) {
+	t.Parallel()
+
+	tcases := []struct {
+		Accept string
+		Offers []string
+		Expect string
+		Match  string
+	}{
+		{Accept: "<none>", Offers: []string{"en-US", "fr"}, Expect: "en-US", Match: "*"},
+		{Accept: "en", Offers: []string{"fr", "en-US"}, Expect: "en-US", Match: "en"},
+		{Accept: "EN", Offers: []string{"fr", "en-us"}, Expect: "en-us", Match: "en"},
+		{Accept: "en", Offers: []string{"fr", "eng"}, Expect: ""},
+		{Accept: "de-CH-1996", Offers: []string{"fr", "de"}, Expect: "de", Match: "de-ch-1996"},
+		{Accept: "zh-Hant-CN-x-private1", Offers: []string{"zh-Hant-CN"}, Expect: "zh-Hant-CN", Match: "zh-hant-cn-x-private1"},
+		{Accept: "en-US, fr;q=0.9", Offers: []string{"fr", "en"}, Expect: "en", Match: "en-us"},
+		{Accept: "en-US;q=0.5, fr;q=0.9", Offers: []string{"en", "fr-CA"}, Expect: "fr-CA", Match: "fr"},
+		{Accept: "fr-CH, fr;q=0", Offers: []string{"fr-FR", "fr-CH"}, Expect: "fr-CH", Match: "fr-ch"},
+		{Accept: "fr-CH, fr;q=0", Offers: []string{"fr-FR", "fr"}, Expect: ""},
+		{Accept: "*;q=0.1, en-US", Offers: []string{"ja", "en"}, Expect: "en", Match: "en-us"},
+		{Accept: "*", Offers: []string{"ja", "en"}, Expect: "ja", Match: "*"},
+		{Accept: "*, ja;q=0", Offers: []string{"ja", "en"}, Expect: "en", Match: "*"},
+		{Accept: "en, de", Offers: []string{"de-AT", "en-GB"}, Expect: "de-AT", Match: "de"},
+		{Accept: "en", Offers: nil, Expect: ""},
+	}
+
+	for i, tcase := range tcases {
+		t.Run(fmt.Sprintf("%d", i), func(t *testing.T) {
+			hdr := http.Header{}
+			if tcase.Accept != "<none>" {
+				hdr.Set("Accept-Language", tcase.Accept)
+			}
+			offer, match := NegotiateLanguage(hdr, tcase.Offers...)
+			if offer != tcase.Expect {
+				t.Fatalf("expected %q, got %q", tcase.Expect, offer)
+			}
+			if tcase.Expect == "" {
+				if match != nil {
+					t.Fatalf("expected no match, got %v", match)
+				}
+				return
+			}
+			if match == nil || match.Value != tcase.Match {
+				t.Fatalf("expected match %q, got %v", tcase.Match, match)
+			}
+
+			// NegotiateContent delegates to NegotiateLanguage.
+			if offer, _ := NegotiateContent(hdr, "accept-language", tcase.Offers...); offer != tcase.Expect {
+				t.Fatalf("expected NegotiateContent to return %q, got %q", tcase.Expect, offer)
+			}
+		})
+	}
+}
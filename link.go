@@ -0,0 +1,298 @@
+// Copyright 2026 Franklin "Snaipe" Mathieu.
+//
+// Use of this source code is governed by the MIT license that can be
+// found in the LICENSE file.
+
+package htutil
+
+import (
+	"fmt"
+	"net/http"
+	"net/url"
+	"sort"
+	"strings"
+)
+
+// Link is a web link, as conveyed by the Link header field of RFC 8288.
+type Link struct {
+	// Target is the URI reference of the link target.
+	Target string
+
+	// Rel are the relation types of the link, like "next" or
+	// "alternate". Registered relation types are compared
+	// case-insensitively.
+	Rel []string
+
+	// Anchor, if set, is the URI reference of the link context, which
+	// otherwise is the resource that the link was received from.
+	Anchor string
+
+	// Type is a hint of the media type of the target.
+	Type string
+
+	// Title is a human-readable label of the target. It is serialized as
+	// title* if it is not US-ASCII.
+	Title string
+
+	// HrefLang are hints of the languages of the target.
+	HrefLang []string
+
+	// Params contains the extension target attributes, with lowercased
+	// names. Attributes with no value are present with an empty value.
+	Params map[string]string
+}
+
+// HasRel returns whether l has the relation type rel.
+func (l Link) HasRel(rel string) bool {
+	for _, r := range l.Rel {
+		if strings.EqualFold(r, rel) {
+			return true
+		}
+	}
+	return false
+}
+
+// Resolve returns the target of l, resolved against base, which is usually
+// the URL of the request that the link was received in response to.
+func (l Link) Resolve(base *url.URL) (URL, error) {
+	u, err := url.Parse(l.Target)
+	if err != nil {
+		return URL{}, fmt.Errorf("parsing link target: %w", err)
+	}
+	if base != nil {
+		u = base.ResolveReference(u)
+	}
+	return URL{u}, nil
+}
+
+// String returns the link-value of l.
+func (l Link) String() string {
+	var out strings.Builder
+	out.WriteString("<" + l.Target + ">")
+	if len(l.Rel) > 0 {
+		out.WriteString("; rel=" + QuoteString(strings.Join(l.Rel, " ")))
+	}
+	if l.Anchor != "" {
+		out.WriteString("; anchor=" + QuoteString(l.Anchor))
+	}
+	if l.Type != "" {
+		out.WriteString("; type=" + QuoteString(l.Type))
+	}
+	if l.Title != "" {
+		if isASCII(l.Title) {
+			out.WriteString("; title=" + QuoteString(l.Title))
+		} else {
+			out.WriteString("; title*=UTF-8''" + encodeExtValue(l.Title))
+		}
+	}
+	for _, lang := range l.HrefLang {
+		out.WriteString("; hreflang=" + tokenOrQuoted(lang))
+	}
+	names := make([]string, 0, len(l.Params))
+	for name := range l.Params {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	for _, name := range names {
+		out.WriteString("; " + name)
+		if v := l.Params[name]; v != "" {
+			out.WriteString("=" + tokenOrQuoted(v))
+		}
+	}
+	return out.String()
+}
+
+// FormatLink returns the value of a Link header field conveying links.
+func FormatLink(links ...Link) string {
+	values := make([]string, len(links))
+	for i, l := range links {
+		values[i] = l.String()
+	}
+	return strings.Join(values, ", ")
+}
+
+// ParseLink parses the values of Link header fields.
+//
+// As per RFC 8288 §3, only the first occurrence of the rel, anchor, type,
+// and title parameters is considered, and title* takes precedence over
+// title.
+func ParseLink(values ...string) ([]Link, error) {
+	var links []Link
+	for _, v := range values {
+		for i := 0; ; {
+			for i < len(v) && (v[i] == ',' || isOWS(v[i])) {
+				i++
+			}
+			if i == len(v) {
+				break
+			}
+			l, n, err := parseLinkValue(v[i:])
+			if err != nil {
+				return nil, fmt.Errorf("parsing link: %w", err)
+			}
+			links = append(links, l)
+			i += n
+		}
+	}
+	return links, nil
+}
+
+// parseLinkValue parses the link-value at the start of s, and returns it
+// along with its length.
+func parseLinkValue(s string) (Link, int, error) {
+	var l Link
+	if s[0] != '<' {
+		return Link{}, 0, fmt.Errorf("expected '<', got %q", s[0])
+	}
+	end := strings.IndexByte(s, '>')
+	if end == -1 {
+		return Link{}, 0, fmt.Errorf("unterminated link target")
+	}
+	l.Target = s[1:end]
+
+	// The parameters extend up to the next comma that is not part of a
+	// quoted-string.
+	params := splitUnquoted(s[end+1:], ',')[0]
+	n := end + 1 + len(params)
+
+	seen := make(map[string]bool)
+	var title, titleStar string
+	for i, param := range splitUnquoted(params, ';') {
+		param = trimOWS(param)
+		if i == 0 {
+			if param != "" {
+				return Link{}, 0, fmt.Errorf("unexpected %q after link target", param)
+			}
+			continue
+		}
+		name, value, _ := strings.Cut(param, "=")
+		name, value = strings.ToLower(trimOWS(name)), trimOWS(value)
+		if !IsToken(strings.TrimSuffix(name, "*")) {
+			return Link{}, 0, fmt.Errorf("invalid parameter name %q", name)
+		}
+		if strings.HasPrefix(value, `"`) {
+			unquoted, err := UnquoteString(value)
+			if err != nil {
+				return Link{}, 0, err
+			}
+			value = unquoted
+		}
+
+		first := !seen[name]
+		seen[name] = true
+		switch name {
+		case "rel":
+			if first {
+				l.Rel = strings.Fields(value)
+			}
+		case "anchor":
+			if first {
+				l.Anchor = value
+			}
+		case "type":
+			if first {
+				l.Type = value
+			}
+		case "title":
+			if first {
+				title = value
+			}
+		case "title*":
+			if first {
+				decoded, err := decodeExtValue(value)
+				if err != nil {
+					return Link{}, 0, fmt.Errorf("invalid title*: %w", err)
+				}
+				titleStar = decoded
+			}
+		case "hreflang":
+			l.HrefLang = append(l.HrefLang, value)
+		default:
+			if l.Params == nil {
+				l.Params = make(map[string]string)
+			}
+			if _, ok := l.Params[name]; !ok {
+				l.Params[name] = value
+			}
+		}
+	}
+	l.Title = title
+	if seen["title*"] {
+		l.Title = titleStar
+	}
+	return l, n, nil
+}
+
+// ResponseLinks returns the links of the Link header fields of resp, whose
+// targets and anchors are resolved against the URL of the request that resp
+// answers.
+func ResponseLinks(resp *http.Response) ([]Link, error) {
+	links, err := ParseLink(resp.Header.Values("Link")...)
+	if err != nil {
+		return nil, err
+	}
+	if resp.Request == nil || resp.Request.URL == nil {
+		return links, nil
+	}
+	base := resp.Request.URL
+	for i := range links {
+		u, err := links[i].Resolve(base)
+		if err != nil {
+			return nil, err
+		}
+		links[i].Target = u.String()
+		if links[i].Anchor != "" {
+			u, err := Link{Target: links[i].Anchor}.Resolve(base)
+			if err != nil {
+				return nil, err
+			}
+			links[i].Anchor = u.String()
+		}
+	}
+	return links, nil
+}
+
+// Pagination are the pagination links of a response. Absent links have a
+// nil URL.
+type Pagination struct {
+	First, Prev, Next, Last URL
+}
+
+// ResponsePagination returns the targets of the links of resp with the
+// first, prev (or previous), next, and last relation types, resolved
+// against the URL of the request that resp answers. Links with an anchor
+// are not about the response, and are ignored.
+func ResponsePagination(resp *http.Response) (Pagination, error) {
+	links, err := ResponseLinks(resp)
+	if err != nil {
+		return Pagination{}, err
+	}
+	var p Pagination
+	for _, l := range links {
+		if l.Anchor != "" {
+			continue
+		}
+		for _, rel := range l.Rel {
+			var dst *URL
+			switch strings.ToLower(rel) {
+			case "first":
+				dst = &p.First
+			case "prev", "previous":
+				dst = &p.Prev
+			case "next":
+				dst = &p.Next
+			case "last":
+				dst = &p.Last
+			default:
+				continue
+			}
+			if dst.URL == nil {
+				*dst, err = l.Resolve(nil)
+				if err != nil {
+					return Pagination{}, err
+				}
+			}
+		}
+	}
+	return p, nil
+}
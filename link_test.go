@@ -0,0 +1,128 @@
+// Copyright 2026 Franklin "Snaipe" Mathieu.
+//
+// Use of this source code is governed by the MIT license that can be
+// found in the LICENSE file.
+
+package htutil
+
+import (
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"testing"
+)
+
+func TestParseLink(t *testing.T) {
+	t.Parallel()
+
+	tcases := []struct {
+		Values []string
+		Links  []Link
+		Err    bool
+	}{
+		{Values: nil, Links: nil},
+		{Values: []string{`<https://example.com/?page=2>; rel="next"`}, Links: []Link{{Target: "https://example.com/?page=2", Rel: []string{"next"}}}},
+		{Values: []string{`</a,b>;rel=next, </c> ; rel="prev start"`}, Links: []Link{
+			{Target: "/a,b", Rel: []string{"next"}},
+			{Target: "/c", Rel: []string{"prev", "start"}},
+		}},
+		{Values: []string{`</x>; rel=a`, `</y>; rel=b`}, Links: []Link{{Target: "/x", Rel: []string{"a"}}, {Target: "/y", Rel: []string{"b"}}}},
+		{Values: []string{`</x>; REL=a; rel=b; Anchor="#s"; type="text/html"; hreflang=en; hreflang=fr`}, Links: []Link{
+			{Target: "/x", Rel: []string{"a"}, Anchor: "#s", Type: "text/html", HrefLang: []string{"en", "fr"}},
+		}},
+		{Values: []string{`</x>; title="a, b; c"; foo; bar=baz`}, Links: []Link{
+			{Target: "/x", Title: "a, b; c", Params: map[string]string{"foo": "", "bar": "baz"}},
+		}},
+		{Values: []string{`</x>; title*=UTF-8'de'n%c3%a4chstes; title="next"`}, Links: []Link{{Target: "/x", Title: "nächstes"}}},
+		{Values: []string{`</x>; title*=iso-8859-1''%E4`}, Links: []Link{{Target: "/x", Title: "ä"}}},
+		{Values: []string{`</x>; title*=utf-8''%ff`}, Err: true},
+		{Values: []string{`/x; rel=next`}, Err: true},
+		{Values: []string{`</x; rel=next`}, Err: true},
+		{Values: []string{`</x> rel=next`}, Err: true},
+		{Values: []string{`</x>; rel="next`}, Err: true},
+	}
+
+	for i, tcase := range tcases {
+		t.Run(fmt.Sprintf("%d", i), func(t *testing.T) {
+			links, err := ParseLink(tcase.Values...)
+			if (err != nil) != tcase.Err {
+				t.Fatalf("expected error %v, got %v", tcase.Err, err)
+			}
+			if !reflect.DeepEqual(links, tcase.Links) {
+				t.Fatalf("expected %#v, got %#v", tcase.Links, links)
+			}
+		})
+	}
+}
+
+func TestFormatLink(t *testing.T) {
+	t.Parallel()
+
+	tcases := []struct {
+		Links  []Link
+		Expect string
+	}{
+		{Links: []Link{{Target: "/next", Rel: []string{"next"}}}, Expect: `</next>; rel="next"`},
+		{Links: []Link{{Target: "/a", Rel: []string{"prev"}}, {Target: "/b", Rel: []string{"next", "last"}}}, Expect: `</a>; rel="prev", </b>; rel="next last"`},
+		{Links: []Link{{Target: "/x", Anchor: "#s", Type: "text/html", Title: `say "hi"`, HrefLang: []string{"en"}, Params: map[string]string{"z": "", "a": "b c"}}},
+			Expect: `</x>; anchor="#s"; type="text/html"; title="say \"hi\""; hreflang=en; a="b c"; z`},
+		{Links: []Link{{Target: "/x", Title: "nächstes"}}, Expect: `</x>; title*=UTF-8''n%C3%A4chstes`},
+	}
+
+	for i, tcase := range tcases {
+		t.Run(fmt.Sprintf("%d", i), func(t *testing.T) {
+			out := FormatLink(tcase.Links...)
+			if out != tcase.Expect {
+				t.Fatalf("expected %q, got %q", tcase.Expect, out)
+			}
+			links, err := ParseLink(out)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if !reflect.DeepEqual(links, tcase.Links) {
+				t.Fatalf("expected %#v, got %#v", tcase.Links, links)
+			}
+		})
+	}
+}
+
+func TestResponsePagination(t *testing.T) {
+	t.Parallel()
+
+	resp := &http.Response{
+		Header: http.Header{"Link": {
+			`<?page=1>; rel="first", <?page=4>; rel="previous"`,
+			`<https://api.example.com/items?page=6>; rel="next", </items?page=9>; rel=last`,
+			`<?page=0>; rel="next"; anchor="/other"`,
+		}},
+		Request: httptest.NewRequest("GET", "https://example.com/items?page=5", nil),
+	}
+	p, err := ResponsePagination(resp)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	tcases := []struct {
+		URL    URL
+		Expect string
+	}{
+		{URL: p.First, Expect: "https://example.com/items?page=1"},
+		{URL: p.Prev, Expect: "https://example.com/items?page=4"},
+		{URL: p.Next, Expect: "https://api.example.com/items?page=6"},
+		{URL: p.Last, Expect: "https://example.com/items?page=9"},
+	}
+
+	for i, tcase := range tcases {
+		t.Run(fmt.Sprintf("%d", i), func(t *testing.T) {
+			if tcase.URL.URL == nil || tcase.URL.String() != tcase.Expect {
+				t.Fatalf("expected %v, got %v", tcase.Expect, tcase.URL)
+			}
+		})
+	}
+
+	p, err = ResponsePagination(&http.Response{Header: http.Header{}})
+	if err != nil || p.Next.URL != nil {
+		t.Fatalf("expected no pagination, got %v (%v)", p, err)
+	}
+}
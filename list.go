@@ -0,0 +1,138 @@
+// Copyright 2026 Franklin "Snaipe" Mathieu.
+//
+// Use of this source code is governed by the MIT license that can be
+// found in the LICENSE file.
+
+package htutil
+
+import (
+	"fmt"
+	"strings"
+)
+
+// isOWS returns whether c is optional whitespace, as per RFC 9110 §5.6.3.
+func isOWS(c byte) bool {
+	return c == ' ' || c == '\t'
+}
+
+func trimOWS(s string) string {
+	return strings.TrimFunc(s, func(r rune) bool { return r == ' ' || r == '\t' })
+}
+
+// splitUnquoted splits s around each instance of sep that is not part of
+// a quoted-string.
+func splitUnquoted(s string, sep byte) []string {
+	var (
+		out    []string
+		quoted bool
+		start  int
+	)
+	for i := 0; i < len(s); i++ {
+		switch c := s[i]; {
+		case quoted && c == '\\':
+			i++
+		case c == '"':
+			quoted = !quoted
+		case !quoted && c == sep:
+			out = append(out, s[start:i])
+			start = i + 1
+		}
+	}
+	return append(out, s[start:])
+}
+
+// cutListMember returns the first member of the list-based field value s,
+// up to the first comma that is not part of a quoted-string, trimmed of
+// optional whitespace, and the rest of s after that comma.
+func cutListMember(s string) (member, rest string) {
+	var quoted bool
+	for i := 0; i < len(s); i++ {
+		switch c := s[i]; {
+		case quoted && c == '\\':
+			i++
+		case c == '"':
+			quoted = !quoted
+		case !quoted && c == ',':
+			return trimOWS(s[:i]), s[i+1:]
+		}
+	}
+	return trimOWS(s), ""
+}
+
+// SplitList splits the value of a list-based header field into its members,
+// as per RFC 9110 §5.6.1. Commas within quoted strings do not delimit
+// members, optional whitespace around members is trimmed, and empty members
+// are dropped.
+func SplitList(value string) []string {
+	var out []string
+	for _, member := range splitUnquoted(value, ',') {
+		if member = trimOWS(member); member != "" {
+			out = append(out, member)
+		}
+	}
+	return out
+}
+
+// ListMember is a member of a list-based header field of the form
+// `value;name=value;...`, like the elements of Accept, Cache-Control, or
+// Prefer.
+type ListMember struct {
+	// Value is the member value, without its parameters.
+	Value string
+
+	// Params contains the parameters of the member. Parameter names are
+	// lowercased, and quoted values are unquoted. Parameters with no
+	// value are present with an empty value.
+	Params map[string]string
+}
+
+// ParseListMember parses a single member of a list-based header field.
+func ParseListMember(member string) (ListMember, error) {
+	parts := splitUnquoted(member, ';')
+
+	out := ListMember{Value: trimOWS(parts[0])}
+	if out.Value == "" {
+		return ListMember{}, fmt.Errorf("parsing %q: empty value", member)
+	}
+	for _, param := range parts[1:] {
+		param = trimOWS(param)
+		if param == "" {
+			continue
+		}
+		var value string
+		if i := strings.IndexByte(param, '='); i != -1 {
+			param, value = trimOWS(param[:i]), trimOWS(param[i+1:])
+		}
+		if param == "" {
+			return ListMember{}, fmt.Errorf("parsing %q: empty parameter name", member)
+		}
+		if strings.HasPrefix(value, `"`) {
+			unquoted, err := UnquoteString(value)
+			if err != nil {
+				return ListMember{}, fmt.Errorf("parsing %q: %w", member, err)
+			}
+			value = unquoted
+		}
+		if out.Params == nil {
+			out.Params = make(map[string]string)
+		}
+		out.Params[strings.ToLower(param)] = value
+	}
+	return out, nil
+}
+
+// ParseListParams parses the value of a list-based header field, whose
+// members are of the form `value;name=value;...`. Any unparseable member
+// is silently dropped.
+func ParseListParams(value string) []ListMember {
+	members := SplitList(value)
+	out := make([]ListMember, 0, len(members))
+	for _, member := range members {
+		m, err := ParseListMember(member)
+		if err != nil {
+			continue
+		}
+		out = append(out, m)
+	}
+	return out
+}
@@ -0,0 +1,76 @@
+// Copyright 2026 Franklin "Snaipe" Mathieu.
+//
+// Use of this source code is governed by the MIT license that can be
+// found in the LICENSE file.
+
+package htutil
+
+import (
+	"fmt"
+	"reflect"
+	"testing"
+)
+
+func TestSplitList(t *testing.T) {
+	t.Parallel()
+
+	tcases := []struct {
+		In  string
+		Out []string
+	}{
+		{In: "", Out: nil},
+		{In: "gzip", Out: []string{"gzip"}},
+		{In: "gzip, deflate ,br", Out: []string{"gzip", "deflate", "br"}},
+		{In: " , ,gzip,,\tbr ,", Out: []string{"gzip", "br"}},
+		{In: `text/html;title="a, b", */*`, Out: []string{`text/html;title="a, b"`, "*/*"}},
+		{In: `a;x="\", b", c`, Out: []string{`a;x="\", b"`, "c"}},
+	}
+
+	for i, tcase := range tcases {
+		t.Run(fmt.Sprintf("%d", i), func(t *testing.T) {
+			actual := SplitList(tcase.In)
+			if !reflect.DeepEqual(actual, tcase.Out) {
+				t.Fatalf("expected %q, got %q", tcase.Out, actual)
+			}
+		})
+	}
+}
+
+func TestParseListParams(t *testing.T) {
+	t.Parallel()
+
+	tcases := []struct {
+		In  string
+		Out []ListMember
+	}{
+		{
+			In: `no-cache, max-age=0`,
+			Out: []ListMember{
+				{Value: "no-cache"},
+				{Value: "max-age=0"},
+			},
+		},
+		{
+			In: `respond-async, wait=10;Foo="a\"b;c" ; bar`,
+			Out: []ListMember{
+				{Value: "respond-async"},
+				{Value: "wait=10", Params: map[string]string{"foo": `a"b;c`, "bar": ""}},
+			},
+		},
+		{
+			In: `;x=1, text/plain ;q=0.5`,
+			Out: []ListMember{
+				{Value: "text/plain", Params: map[string]string{"q": "0.5"}},
+			},
+		},
+	}
+
+	for i, tcase := range tcases {
+		t.Run(fmt.Sprintf("%d", i), func(t *testing.T) {
+			actual := ParseListParams(tcase.In)
+			if !reflect.DeepEqual(actual, tcase.Out) {
+				t.Fatalf("expected %v, got %v", tcase.Out, actual)
+			}
+		})
+	}
+}
@@ -0,0 +1,104 @@
+// Copyright 2026 Franklin "Snaipe" Mathieu.
+//
+// Use of this source code is governed by the MIT license that can be
+// found in the LICENSE file.
+
+package htutil
+
+import (
+	"log/slog"
+	"net/http"
+	"net/url"
+	"strings"
+)
+
+// LocaleRedirect redirects requests for paths that are not localized to
+// the localized path best matching the Accept-Language of the client,
+// e.g. from /about to /fr/about.
+type LocaleRedirect struct {
+	// Locales are the available locales, as language tags, which are also
+	// the first segment of localized paths. The first one is the default,
+	// used when none is acceptable.
+	Locales []string
+
+	// Cookie, if set, is the name of a cookie overriding negotiation: if it
+	// holds one of the available locales, it is used instead; if it holds
+	// any other value, requests are not redirected.
+	Cookie string
+
+	// Exclude lists path prefixes that are never localized, like /static/
+	// or /api/.
+	Exclude []string
+
+	// Status is the status code of the redirects, typically 302 Found or
+	// 307 Temporary Redirect. Defaults to 302 Found.
+	Status int
+}
+
+// locale returns the first segment of path if it is an available locale.
+func (l *LocaleRedirect) locale(path string) (string, bool) {
+	segment := strings.TrimPrefix(path, "/")
+	if i := strings.IndexByte(segment, '/'); i >= 0 {
+		segment = segment[:i]
+	}
+	for _, locale := range l.Locales {
+		if strings.EqualFold(segment, locale) {
+			return locale, true
+		}
+	}
+	return "", false
+}
+
+// Middleware returns a middleware that redirects GET and HEAD requests for
+// paths that are not localized, and passes all other requests to next.
+func (l *LocaleRedirect) Middleware(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+		if len(l.Locales) == 0 || (req.Method != http.MethodGet && req.Method != http.MethodHead) {
+			next.ServeHTTP(w, req)
+			return
+		}
+		if _, ok := l.locale(req.URL.Path); ok {
+			next.ServeHTTP(w, req)
+			return
+		}
+		for _, prefix := range l.Exclude {
+			if strings.HasPrefix(req.URL.Path, prefix) {
+				next.ServeHTTP(w, req)
+				return
+			}
+		}
+
+		AddVary(w.Header(), "Accept-Language")
+
+		var locale string
+		if l.Cookie != "" {
+			AddVary(w.Header(), "Cookie")
+			if cookie, err := req.Cookie(l.Cookie); err == nil {
+				var ok bool
+				if locale, ok = l.locale(cookie.Value); !ok {
+					// The client opted out of localized redirects.
+					next.ServeHTTP(w, req)
+					return
+				}
+			}
+		}
+		if locale == "" {
+			locale = negotiateAxis(req.Header, VariantAxis{
+				Field:  "Accept-Language",
+				Values: l.Locales,
+			})[0]
+		}
+
+		status := l.Status
+		if status == 0 {
+			status = http.StatusFound
+		}
+		target := url.URL{
+			Path:     "/" + locale + req.URL.Path,
+			RawQuery: req.URL.RawQuery,
+		}
+		ContextLogger(req.Context()).Debug("redirecting to locale",
+			slog.String("locale", locale), slog.Any("url", URL{req.URL}))
+		http.Redirect(w, req, target.String(), status)
+	})
+}
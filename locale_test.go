@@ -0,0 +1,73 @@
+// Copyright 2026 Franklin "Snaipe" Mathieu.
+//
+// Use of this source code is governed by the MIT license that can be
+// found in the LICENSE file.
+
+package htutil
+
+import (
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestLocaleRedirect(t *testing.T) {
+	t.Parallel()
+
+	l := &LocaleRedirect{
+		Locales: []string{"en", "fr", "pt-BR"},
+		Cookie:  "lang",
+		Exclude: []string{"/static/"},
+	}
+	handler := l.Middleware(http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+		fmt.Fprint(w, "ok")
+	}))
+
+	tcases := []struct {
+		Method         string
+		Target         string
+		AcceptLanguage string
+		Cookie         string
+		Status         int
+		Location       string
+	}{
+		{Target: "/", Status: 302, Location: "/en/"},
+		{Target: "/", AcceptLanguage: "fr-CH, fr;q=0.9, en;q=0.8", Status: 302, Location: "/fr/"},
+		{Target: "/about?x=1", AcceptLanguage: "pt, en;q=0.1", Status: 302, Location: "/pt-BR/about?x=1"},
+		{Target: "/", AcceptLanguage: "de", Status: 302, Location: "/en/"},
+		{Target: "/", AcceptLanguage: "fr", Cookie: "en", Status: 302, Location: "/en/"},
+		{Target: "/", AcceptLanguage: "fr", Cookie: "off", Status: 200},
+		{Target: "/fr/about", AcceptLanguage: "en", Status: 200},
+		{Target: "/static/app.js", Status: 200},
+		{Method: "POST", Target: "/", Status: 200},
+	}
+
+	for i, tcase := range tcases {
+		t.Run(fmt.Sprintf("%d", i), func(t *testing.T) {
+			method := tcase.Method
+			if method == "" {
+				method = "GET"
+			}
+			req := httptest.NewRequest(method, tcase.Target, nil)
+			if tcase.AcceptLanguage != "" {
+				req.Header.Set("Accept-Language", tcase.AcceptLanguage)
+			}
+			if tcase.Cookie != "" {
+				req.AddCookie(&http.Cookie{Name: "lang", Value: tcase.Cookie})
+			}
+			w := httptest.NewRecorder()
+			handler.ServeHTTP(w, req)
+
+			if w.Code != tcase.Status {
+				t.Fatalf("expected status %d, got %d", tcase.Status, w.Code)
+			}
+			if loc := w.Header().Get("Location"); loc != tcase.Location {
+				t.Fatalf("expected Location %q, got %q", tcase.Location, loc)
+			}
+			if tcase.Location != "" && w.Header().Values("Vary")[0] != "Accept-Language" {
+				t.Fatalf("expected Vary Accept-Language, got %q", w.Header().Values("Vary"))
+			}
+		})
+	}
+}
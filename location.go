@@ -0,0 +1,102 @@
+// Copyright 2026 Franklin "Snaipe" Mathieu.
+//
+// Use of this source code is governed by the MIT license that can be
+// found in the LICENSE file.
+
+package htutil
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"net/url"
+)
+
+// RequestURL reconstructs the absolute URL that the client used to make
+// req, from its target, Host header field, and connection security.
+func RequestURL(req *http.Request) *url.URL {
+	u := *req.URL
+	if u.Scheme == "" {
+		u.Scheme = "http"
+		if req.TLS != nil {
+			u.Scheme = "https"
+		}
+	}
+	if u.Host == "" {
+		u.Host = req.Host
+	}
+	return &u
+}
+
+// resolveRef resolves ref against the reconstructed URL of req.
+func resolveRef(req *http.Request, ref string) (string, error) {
+	u, err := url.Parse(ref)
+	if err != nil {
+		return "", err
+	}
+	return RequestURL(req).ResolveReference(u).String(), nil
+}
+
+// SetLocation sets the Location header field of h to ref, resolved against
+// the URL of req.
+func SetLocation(h http.Header, req *http.Request, ref string) error {
+	loc, err := resolveRef(req, ref)
+	if err != nil {
+		return fmt.Errorf("setting location: %w", err)
+	}
+	h.Set("Location", loc)
+	return nil
+}
+
+// SetContentLocation sets the Content-Location header field of h to ref,
+// resolved against the URL of req.
+func SetContentLocation(h http.Header, req *http.Request, ref string) error {
+	loc, err := resolveRef(req, ref)
+	if err != nil {
+		return fmt.Errorf("setting content location: %w", err)
+	}
+	h.Set("Content-Location", loc)
+	return nil
+}
+
+// Created sets the Location header field of the response to ref, resolved
+// against the URL of req, and writes a 201 Created status. The caller may
+// then write a representation of the created resource.
+func Created(w http.ResponseWriter, req *http.Request, ref string) error {
+	if err := SetLocation(w.Header(), req, ref); err != nil {
+		return err
+	}
+	w.WriteHeader(http.StatusCreated)
+	return nil
+}
+
+// ErrNoLocation is returned by ResponseLocation and ResponseContentLocation
+// when the response has no such header field.
+var ErrNoLocation = errors.New("response has no location")
+
+func responseRef(resp *http.Response, key string) (URL, error) {
+	ref := resp.Header.Get(key)
+	if ref == "" {
+		return URL{}, ErrNoLocation
+	}
+	u, err := url.Parse(ref)
+	if err != nil {
+		return URL{}, fmt.Errorf("parsing %s: %w", key, err)
+	}
+	if resp.Request != nil && resp.Request.URL != nil {
+		u = resp.Request.URL.ResolveReference(u)
+	}
+	return URL{u}, nil
+}
+
+// ResponseLocation returns the Location of resp, resolved against the URL
+// of the request that resp answers.
+func ResponseLocation(resp *http.Response) (URL, error) {
+	return responseRef(resp, "Location")
+}
+
+// ResponseContentLocation returns the Content-Location of resp, resolved
+// against the URL of the request that resp answers.
+func ResponseContentLocation(resp *http.Response) (URL, error) {
+	return responseRef(resp, "Content-Location")
+}
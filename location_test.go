@@ -0,0 +1,81 @@
+// Copyright 2026 Franklin "Snaipe" Mathieu.
+//
+// Use of this source code is governed by the MIT license that can be
+// found in the LICENSE file.
+
+package htutil
+
+import (
+	"crypto/tls"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"testing"
+)
+
+func TestSetLocation(t *testing.T) {
+	t.Parallel()
+
+	tcases := []struct {
+		Target   string
+		TLS      bool
+		Ref      string
+		Expected string
+	}{
+		{Target: "/items/", Ref: "42", Expected: "http://example.com/items/42"},
+		{Target: "/items", Ref: "42", Expected: "http://example.com/42"},
+		{Target: "/items/", TLS: true, Ref: "/other?x=1", Expected: "https://example.com/other?x=1"},
+		{Target: "/a/b/c", Ref: "../d", Expected: "http://example.com/a/d"},
+		{Target: "/", Ref: "https://elsewhere.test/x", Expected: "https://elsewhere.test/x"},
+	}
+
+	for i, tcase := range tcases {
+		t.Run(fmt.Sprintf("%d", i), func(t *testing.T) {
+			req := httptest.NewRequest("POST", tcase.Target, nil)
+			if tcase.TLS {
+				req.TLS = &tls.ConnectionState{}
+			}
+			w := httptest.NewRecorder()
+			if err := Created(w, req, tcase.Ref); err != nil {
+				t.Fatal(err)
+			}
+			if w.Code != http.StatusCreated {
+				t.Fatalf("expected status 201, got %d", w.Code)
+			}
+			if loc := w.Header().Get("Location"); loc != tcase.Expected {
+				t.Fatalf("expected %s, got %s", tcase.Expected, loc)
+			}
+		})
+	}
+}
+
+func TestResponseLocation(t *testing.T) {
+	t.Parallel()
+
+	reqURL, _ := url.Parse("https://api.example.com/v1/items/")
+	resp := &http.Response{
+		Header:  http.Header{"Location": {"42"}, "Content-Location": {"/v1/items/42?v=1"}},
+		Request: &http.Request{URL: reqURL},
+	}
+
+	loc, err := ResponseLocation(resp)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if loc.String() != "https://api.example.com/v1/items/42" {
+		t.Fatalf("unexpected location %s", loc)
+	}
+	cloc, err := ResponseContentLocation(resp)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if cloc.String() != "https://api.example.com/v1/items/42?v=1" {
+		t.Fatalf("unexpected content location %s", cloc)
+	}
+
+	resp.Header.Del("Location")
+	if _, err := ResponseLocation(resp); err != ErrNoLocation {
+		t.Fatalf("expected ErrNoLocation, got %v", err)
+	}
+}
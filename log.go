@@ -0,0 +1,118 @@
+// Copyright 2026 Franklin "Snaipe" Mathieu.
+//
+// Use of this source code is governed by the MIT license that can be
+// found in the LICENSE file.
+
+package htutil
+
+import (
+	"context"
+	"log/slog"
+	"net/http"
+	"strconv"
+)
+
+// The components of this package log through log/slog. They all use the
+// logger carried by the request context, as set by WithLogger for handlers
+// and middlewares, or by ContextWithLogger for clients and transports, or
+// slog.Default() if there is none.
+
+type loggerKey struct{}
+
+// ContextWithLogger returns a copy of ctx carrying logger.
+func ContextWithLogger(ctx context.Context, logger *slog.Logger) context.Context {
+	return context.WithValue(ctx, loggerKey{}, logger)
+}
+
+// ContextLogger returns the logger carried by ctx, or slog.Default() if
+// there is none.
+func ContextLogger(ctx context.Context) *slog.Logger {
+	if logger, ok := ctx.Value(loggerKey{}).(*slog.Logger); ok {
+		return logger
+	}
+	return slog.Default()
+}
+
+// WithLogger returns a middleware that makes the components of this package
+// log the requests served by next through logger.
+func WithLogger(next http.Handler, logger *slog.Logger) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+		next.ServeHTTP(w, req.WithContext(ContextWithLogger(req.Context(), logger)))
+	})
+}
+
+// LogValue logs acc as a group of its value, quality, parameters, and
+// extensions.
+func (acc Acceptable) LogValue() slog.Value {
+	attrs := []slog.Attr{
+		slog.String("value", acc.Value),
+		slog.String("q", strconv.FormatFloat(float64(acc.Quality), 'g', 3, 32)),
+	}
+	if len(acc.Params) > 0 {
+		params := make([]slog.Attr, 0, len(acc.Params))
+		for k, v := range acc.Params {
+			params = append(params, slog.String(k, v))
+		}
+		attrs = append(attrs, slog.Attr{Key: "params", Value: slog.GroupValue(params...)})
+	}
+	if len(acc.Extensions) > 0 {
+		exts := make([]slog.Attr, 0, len(acc.Extensions))
+		for k, v := range acc.Extensions {
+			exts = append(exts, slog.String(k, v))
+		}
+		attrs = append(attrs, slog.Attr{Key: "ext", Value: slog.GroupValue(exts...)})
+	}
+	return slog.GroupValue(attrs...)
+}
+
+// LogValue logs u with its password and the values of its query parameters
+// redacted, as they often carry credentials.
+func (u URL) LogValue() slog.Value {
+	if u.URL == nil {
+		return slog.StringValue("")
+	}
+	redacted := *u.URL
+	if redacted.RawQuery != "" {
+		query := redacted.Query()
+		for k, vs := range query {
+			for i := range vs {
+				vs[i] = "xxxxx"
+			}
+			query[k] = vs
+		}
+		redacted.RawQuery = query.Encode()
+	}
+	return slog.StringValue(redacted.Redacted())
+}
+
+// LogValue logs p as a group of its non-empty members.
+func (p Problem) LogValue() slog.Value {
+	attrs := []slog.Attr{slog.Int("status", p.Status)}
+	for _, m := range [...]struct{ key, value string }{
+		{"type", p.Type},
+		{"title", p.Title},
+		{"detail", p.Detail},
+		{"instance", p.Instance},
+	} {
+		if m.value != "" {
+			attrs = append(attrs, slog.String(m.key, m.value))
+		}
+	}
+	if c := Status(p.Status).Category(); c != Uncategorized {
+		attrs = append(attrs, slog.String("category", c.String()))
+	}
+	return slog.GroupValue(attrs...)
+}
+
+// LogValue logs n as a group of its members.
+func (n Negotiation) LogValue() slog.Value {
+	attrs := []slog.Attr{
+		slog.String("field", n.Field),
+		slog.Any("offers", n.Offers),
+		slog.String("selected", n.Selected),
+	}
+	if n.Match != nil {
+		attrs = append(attrs, slog.Any("match", *n.Match))
+	}
+	return slog.GroupValue(attrs...)
+}
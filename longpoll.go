@@ -0,0 +1,168 @@
+// Copyright 2026 Franklin "Snaipe" Mathieu.
+//
+// Use of this source code is governed by the MIT license that can be
+// found in the LICENSE file.
+
+package htutil
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"strings"
+	"time"
+)
+
+// LongPollEvent is the payload delivered to a long-polling client.
+type LongPollEvent struct {
+	// Token is the resume token of the event. Clients send it back in
+	// If-None-Match to only be notified of subsequent events.
+	Token string
+
+	// ContentType is the media type of Body.
+	ContentType string
+
+	// Body is the payload of the event.
+	Body []byte
+}
+
+// LongPoll is a handler serving long-polling notification endpoints.
+//
+// Each request waits until Wait returns an event, which is then sent back
+// with its resume token as a strong ETag. Clients pass the last token they
+// received in If-None-Match, and Wait is called with it, so that no event
+// gets lost between two polls.
+//
+// If no event arrives before Timeout, the request is completed with a
+// 204 No Content response carrying the current resume token.
+//
+// When Heartbeat is set, HeartbeatData is written at that interval while
+// waiting, to keep intermediaries from closing idle connections. As this
+// commits the response header before the event is known, the ETag is then
+// sent as a trailer field, and the response ends without a body on timeout.
+type LongPoll struct {
+	// Wait blocks until an event more recent than the specified resume
+	// token is available, and returns it. The token is empty if the client
+	// did not provide one. Wait must return promptly once ctx is done.
+	Wait func(ctx context.Context, token string) (*LongPollEvent, error)
+
+	// Timeout is the maximum amount of time a request waits for an event.
+	// Defaults to 30 seconds.
+	Timeout time.Duration
+
+	// Heartbeat is the interval at which HeartbeatData is written while
+	// waiting. Heartbeats are disabled if zero.
+	Heartbeat time.Duration
+
+	// HeartbeatData is the data written on each heartbeat. Defaults to a
+	// single newline.
+	HeartbeatData []byte
+}
+
+type longPollResult struct {
+	event *LongPollEvent
+	err   error
+}
+
+func (lp *LongPoll) ServeHTTP(w http.ResponseWriter, req *http.Request) {
+	timeout := lp.Timeout
+	if timeout <= 0 {
+		timeout = 30 * time.Second
+	}
+
+	token := resumeToken(req.Header.Get("If-None-Match"))
+
+	w.Header().Set("Cache-Control", "no-cache, no-store")
+
+	ctx, cancel := context.WithTimeout(req.Context(), timeout)
+	defer cancel()
+
+	done := make(chan longPollResult, 1)
+	go func() {
+		event, err := lp.Wait(ctx, token)
+		done <- longPollResult{event, err}
+	}()
+
+	var (
+		tick      <-chan time.Time
+		committed bool
+	)
+	if lp.Heartbeat > 0 {
+		ticker := time.NewTicker(lp.Heartbeat)
+		defer ticker.Stop()
+		tick = ticker.C
+	}
+
+	for {
+		select {
+		case <-tick:
+			if !committed {
+				w.Header().Set("Trailer", "ETag")
+				w.WriteHeader(http.StatusOK)
+				committed = true
+			}
+			data := lp.HeartbeatData
+			if data == nil {
+				data = []byte("\n")
+			}
+			if _, err := w.Write(data); err != nil {
+				return
+			}
+			if f, ok := w.(http.Flusher); ok {
+				f.Flush()
+			}
+			continue
+
+		case res := <-done:
+			event, err := res.event, res.err
+			if req.Context().Err() != nil {
+				// The client went away; nobody is listening anymore.
+				return
+			}
+			if errors.Is(err, context.DeadlineExceeded) {
+				event, err = nil, nil
+			}
+			if err != nil {
+				if !committed {
+					http.Error(w, http.StatusText(http.StatusInternalServerError), http.StatusInternalServerError)
+				}
+				return
+			}
+			if event != nil {
+				token = event.Token
+			}
+			if token != "" {
+				w.Header().Set("ETag", `"`+token+`"`)
+			}
+			if event == nil {
+				if !committed {
+					w.WriteHeader(http.StatusNoContent)
+				}
+				return
+			}
+			if !committed {
+				if event.ContentType != "" {
+					w.Header().Set("Content-Type", event.ContentType)
+				}
+				w.WriteHeader(http.StatusOK)
+			}
+			w.Write(event.Body)
+			return
+		}
+	}
+}
+
+// resumeToken extracts the opaque tag of the first entity tag of an
+// If-None-Match header value.
+func resumeToken(value string) string {
+	value = trimOWS(value)
+	value = strings.TrimPrefix(value, "W/")
+	if len(value) < 2 || value[0] != '"' {
+		return ""
+	}
+	end := strings.IndexByte(value[1:], '"')
+	if end < 0 {
+		return ""
+	}
+	return value[1 : end+1]
+}
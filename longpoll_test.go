@@ -0,0 +1,94 @@
+// Copyright 2026 Franklin "Snaipe" Mathieu.
+//
+// Use of this source code is governed by the MIT license that can be
+// found in the LICENSE file.
+
+package htutil
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestLongPoll(t *testing.T) {
+	t.Parallel()
+
+	// The event source holds a single event, with token "2".
+	wait := func(ctx context.Context, token string) (*LongPollEvent, error) {
+		if token != "2" {
+			return &LongPollEvent{Token: "2", ContentType: "text/plain", Body: []byte("event 2")}, nil
+		}
+		<-ctx.Done()
+		return nil, ctx.Err()
+	}
+
+	tcases := []struct {
+		IfNoneMatch string
+		Heartbeat   time.Duration
+		Status      int
+		ETag        string
+		Body        string
+	}{
+		{IfNoneMatch: "", Status: 200, ETag: `"2"`, Body: "event 2"},
+		{IfNoneMatch: `"1"`, Status: 200, ETag: `"2"`, Body: "event 2"},
+		{IfNoneMatch: `"2"`, Status: 204, ETag: `"2"`},
+		{IfNoneMatch: `W/"2"`, Status: 204, ETag: `"2"`},
+		{IfNoneMatch: `"2"`, Heartbeat: 10 * time.Millisecond, Status: 200, ETag: `"2"`},
+	}
+
+	for i, tcase := range tcases {
+		tcase := tcase
+		t.Run(fmt.Sprintf("%d", i), func(t *testing.T) {
+			t.Parallel()
+
+			lp := &LongPoll{Wait: wait, Timeout: 50 * time.Millisecond, Heartbeat: tcase.Heartbeat}
+			req := httptest.NewRequest("GET", "/events", nil)
+			if tcase.IfNoneMatch != "" {
+				req.Header.Set("If-None-Match", tcase.IfNoneMatch)
+			}
+			w := httptest.NewRecorder()
+			lp.ServeHTTP(w, req)
+
+			resp := w.Result()
+			if resp.StatusCode != tcase.Status {
+				t.Fatalf("expected status %d, got %d", tcase.Status, resp.StatusCode)
+			}
+			etag := resp.Header.Get("ETag")
+			if tcase.Heartbeat > 0 {
+				etag = resp.Trailer.Get("ETag")
+			}
+			if etag != tcase.ETag {
+				t.Fatalf("expected ETag %q, got %q", tcase.ETag, etag)
+			}
+			if cc := resp.Header.Get("Cache-Control"); !strings.Contains(cc, "no-store") {
+				t.Fatalf("expected no-store Cache-Control, got %q", cc)
+			}
+			body := w.Body.String()
+			if tcase.Heartbeat > 0 {
+				if body == "" || strings.Trim(body, "\n") != "" {
+					t.Fatalf("expected heartbeats, got %q", body)
+				}
+			} else if body != tcase.Body {
+				t.Fatalf("expected body %q, got %q", tcase.Body, body)
+			}
+		})
+	}
+}
+
+func TestLongPollError(t *testing.T) {
+	t.Parallel()
+
+	lp := &LongPoll{Wait: func(ctx context.Context, token string) (*LongPollEvent, error) {
+		return nil, fmt.Errorf("source unavailable")
+	}}
+	w := httptest.NewRecorder()
+	lp.ServeHTTP(w, httptest.NewRequest("GET", "/events", nil))
+	if w.Code != http.StatusInternalServerError {
+		t.Fatalf("expected status 500, got %d", w.Code)
+	}
+}
@@ -0,0 +1,109 @@
+// Copyright 2026 Franklin "Snaipe" Mathieu.
+//
+// Use of this source code is governed by the MIT license that can be
+// found in the LICENSE file.
+
+package htutil
+
+import (
+	"net/http"
+	"net/netip"
+	"strconv"
+	"sync/atomic"
+	"time"
+)
+
+// Maintenance is a switchable maintenance mode. While enabled, its
+// middleware rejects requests with a 503 Service Unavailable response,
+// rendered with RespondError, so that browsers get the HTML page
+// registered for 503 while API clients get problem details.
+//
+// Maintenance is safe for concurrent use; operators may toggle it at any
+// time while requests are being served.
+type Maintenance struct {
+	// RetryAfter is the delay advertised via Retry-After when the end of
+	// the maintenance is not known. Defaults to 5 minutes.
+	RetryAfter time.Duration
+
+	// Detail is the explanation given to clients. Defaults to a generic
+	// message.
+	Detail string
+
+	// BypassPrefixes lists the client addresses allowed through during
+	// maintenance, typically operators checking that the service works.
+	// The client address is taken from http.Request.RemoteAddr.
+	BypassPrefixes []netip.Prefix
+
+	// BypassHeader lets requests carrying any of its header field values
+	// through during maintenance, e.g. a secret token sent by operators.
+	BypassHeader http.Header
+
+	state atomic.Pointer[maintenanceState]
+}
+
+type maintenanceState struct {
+	until time.Time
+}
+
+// Enable turns maintenance mode on. If until is not zero, it is advertised
+// to clients as the time at which they may retry.
+func (m *Maintenance) Enable(until time.Time) {
+	m.state.Store(&maintenanceState{until: until})
+}
+
+// Disable turns maintenance mode off.
+func (m *Maintenance) Disable() {
+	m.state.Store(nil)
+}
+
+// Enabled returns whether maintenance mode is on, and the time at which it
+// is expected to end, if known.
+func (m *Maintenance) Enabled() (until time.Time, enabled bool) {
+	state := m.state.Load()
+	if state == nil {
+		return time.Time{}, false
+	}
+	return state.until, true
+}
+
+// Bypasses returns whether req is allowed through during maintenance.
+func (m *Maintenance) Bypasses(req *http.Request) bool {
+	for name, values := range m.BypassHeader {
+		for _, v := range req.Header.Values(name) {
+			if containsString(values, v) {
+				return true
+			}
+		}
+	}
+	return peerIn(req, m.BypassPrefixes)
+}
+
+// Middleware returns a middleware that serves requests with next while
+// maintenance mode is off, and rejects them otherwise.
+func (m *Maintenance) Middleware(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+		until, enabled := m.Enabled()
+		if !enabled || m.Bypasses(req) {
+			next.ServeHTTP(w, req)
+			return
+		}
+
+		if !until.IsZero() && until.After(time.Now()) {
+			w.Header().Set("Retry-After", until.UTC().Format(http.TimeFormat))
+		} else {
+			retry := m.RetryAfter
+			if retry <= 0 {
+				retry = 5 * time.Minute
+			}
+			secs := int64((retry + time.Second - 1) / time.Second)
+			w.Header().Set("Retry-After", strconv.FormatInt(secs, 10))
+		}
+		w.Header().Set("Cache-Control", "no-store")
+
+		detail := m.Detail
+		if detail == "" {
+			detail = "The service is undergoing maintenance, please try again later."
+		}
+		RespondError(w, req, NewProblem(http.StatusServiceUnavailable, detail))
+	})
+}
@@ -0,0 +1,97 @@
+// Copyright 2026 Franklin "Snaipe" Mathieu.
+//
+// Use of this source code is governed by the MIT license that can be
+// found in the LICENSE file.
+
+package htutil
+
+import (
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"net/netip"
+	"testing"
+	"time"
+)
+
+func TestMaintenance(t *testing.T) {
+	t.Parallel()
+
+	ok := http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+		fmt.Fprint(w, "ok")
+	})
+
+	until := time.Now().Add(time.Hour).Truncate(time.Second)
+
+	tcases := []struct {
+		Enable      bool
+		Until       time.Time
+		RemoteAddr  string
+		Header      http.Header
+		Accept      string
+		Status      int
+		RetryAfter  string
+		ContentType string
+	}{
+		{Enable: false, Status: 200},
+		{Enable: true, Status: 503, RetryAfter: "300", ContentType: "text/plain; charset=utf-8"},
+		{Enable: true, Until: until, Accept: "application/json", Status: 503, RetryAfter: until.UTC().Format(http.TimeFormat), ContentType: "application/problem+json"},
+		{Enable: true, RemoteAddr: "10.1.2.3:1234", Status: 200},
+		{Enable: true, RemoteAddr: "[::ffff:10.1.2.3]:1234", Status: 200},
+		{Enable: true, RemoteAddr: "192.0.2.1:1234", Status: 503, RetryAfter: "300"},
+		{Enable: true, Header: http.Header{"X-Bypass": {"letmein"}}, Status: 200},
+		{Enable: true, Header: http.Header{"X-Bypass": {"wrong"}}, Status: 503, RetryAfter: "300"},
+	}
+
+	for i, tcase := range tcases {
+		t.Run(fmt.Sprintf("%d", i), func(t *testing.T) {
+			m := &Maintenance{
+				BypassPrefixes: []netip.Prefix{netip.MustParsePrefix("10.0.0.0/8")},
+				BypassHeader:   http.Header{"X-Bypass": {"letmein"}},
+			}
+			if tcase.Enable {
+				m.Enable(tcase.Until)
+			}
+
+			req := httptest.NewRequest("GET", "/", nil)
+			if tcase.RemoteAddr != "" {
+				req.RemoteAddr = tcase.RemoteAddr
+			}
+			for k, v := range tcase.Header {
+				req.Header[k] = v
+			}
+			if tcase.Accept != "" {
+				req.Header.Set("Accept", tcase.Accept)
+			}
+			w := httptest.NewRecorder()
+			m.Middleware(ok).ServeHTTP(w, req)
+
+			if w.Code != tcase.Status {
+				t.Fatalf("expected status %d, got %d", tcase.Status, w.Code)
+			}
+			if ra := w.Header().Get("Retry-After"); ra != tcase.RetryAfter {
+				t.Fatalf("expected Retry-After %q, got %q", tcase.RetryAfter, ra)
+			}
+			if tcase.ContentType != "" && w.Header().Get("Content-Type") != tcase.ContentType {
+				t.Fatalf("expected content type %s, got %s", tcase.ContentType, w.Header().Get("Content-Type"))
+			}
+		})
+	}
+}
+
+func TestMaintenanceToggle(t *testing.T) {
+	t.Parallel()
+
+	var m Maintenance
+	if _, enabled := m.Enabled(); enabled {
+		t.Fatalf("expected maintenance to be off initially")
+	}
+	m.Enable(time.Time{})
+	if _, enabled := m.Enabled(); !enabled {
+		t.Fatalf("expected maintenance to be on")
+	}
+	m.Disable()
+	if _, enabled := m.Enabled(); enabled {
+		t.Fatalf("expected maintenance to be off")
+	}
+}
@@ -0,0 +1,130 @@
+// Copyright 2026 Franklin "Snaipe" Mathieu.
+//
+// Use of this source code is governed by the MIT license that can be
+// found in the LICENSE file.
+
+package htutil
+
+import (
+	"fmt"
+	"mime"
+	"sort"
+	"strings"
+)
+
+// MediaType is a media type or media range, as per RFC 9110 §8.3.1, with
+// the facets of its subtype as per RFC 6838 §3 and §4.2.8.
+type MediaType struct {
+	// Type is the lowercased top-level type, like "application", or "*".
+	Type string
+
+	// Subtype is the lowercased subtype, like "vnd.example+json", or "*".
+	Subtype string
+
+	// Tree is the registration tree of the subtype: "vnd", "prs", or "x",
+	// or "" for the standards tree.
+	Tree string
+
+	// Suffix is the structured syntax suffix of the subtype, like "json",
+	// or "" if there is none.
+	Suffix string
+
+	// Params contains the parameters, with lowercased names.
+	Params map[string]string
+}
+
+// ParseMediaType parses a media type or media range, like a Content-Type
+// header value.
+func ParseMediaType(s string) (MediaType, error) {
+	value, params, err := mime.ParseMediaType(s)
+	if err != nil {
+		return MediaType{}, fmt.Errorf("parsing media type: %w", err)
+	}
+	mt, ok := newMediaType(value, params)
+	if !ok {
+		return MediaType{}, fmt.Errorf("parsing media type: %q has no subtype", value)
+	}
+	return mt, nil
+}
+
+// newMediaType returns the media type of the lowercased type/subtype value,
+// with the specified parameters.
+func newMediaType(value string, params map[string]string) (MediaType, bool) {
+	typ, subtype, ok := strings.Cut(value, "/")
+	if !ok || typ == "" || subtype == "" {
+		return MediaType{}, false
+	}
+	if len(params) == 0 {
+		params = nil
+	}
+	mt := MediaType{Type: typ, Subtype: subtype, Params: params}
+	if i := strings.LastIndexByte(subtype, '+'); i != -1 {
+		mt.Suffix = subtype[i+1:]
+	}
+	switch {
+	case strings.HasPrefix(subtype, "vnd."):
+		mt.Tree = "vnd"
+	case strings.HasPrefix(subtype, "prs."):
+		mt.Tree = "prs"
+	case strings.HasPrefix(subtype, "x."), strings.HasPrefix(subtype, "x-"):
+		// x- is the unregistered prefix of RFC 2046, deprecated by RFC 6838.
+		mt.Tree = "x"
+	}
+	return mt, true
+}
+
+// MediaType returns the media range of acc, or ok=false if its value is not
+// a media range, like the values of Accept-Encoding.
+func (acc Acceptable) MediaType() (mt MediaType, ok bool) {
+	return newMediaType(acc.Value, acc.Params)
+}
+
+// Essence returns the type and subtype of mt, without parameters.
+func (mt MediaType) Essence() string {
+	return mt.Type + "/" + mt.Subtype
+}
+
+// String returns the media type formatted with its parameters, sorted by
+// name.
+func (mt MediaType) String() string {
+	var out strings.Builder
+	out.WriteString(mt.Essence())
+	names := make([]string, 0, len(mt.Params))
+	for name := range mt.Params {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	for _, name := range names {
+		out.WriteString(";" + name + "=" + tokenOrQuoted(mt.Params[name]))
+	}
+	return out.String()
+}
+
+// Match returns whether mt matches the media range pattern: their types
+// and subtypes are equal, or wildcards in pattern, and each parameter of
+// pattern is present in mt, with a value that is equal ignoring case.
+func (mt MediaType) Match(pattern MediaType) bool {
+	return mt.match(pattern, false)
+}
+
+// MatchSuffix is like Match, but also matches media types whose structured
+// syntax suffix is the subtype of pattern, within the same type, so that
+// application/vnd.example+json matches application/json.
+func (mt MediaType) MatchSuffix(pattern MediaType) bool {
+	return mt.match(pattern, true)
+}
+
+func (mt MediaType) match(pattern MediaType, suffix bool) bool {
+	if pattern.Type != "*" && pattern.Type != mt.Type {
+		return false
+	}
+	if pattern.Subtype != "*" && pattern.Subtype != mt.Subtype && !(suffix && pattern.Subtype == mt.Suffix) {
+		return false
+	}
+	for name, value := range pattern.Params {
+		if v, ok := mt.Params[name]; !ok || !strings.EqualFold(v, value) {
+			return false
+		}
+	}
+	return true
+}
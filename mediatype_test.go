@@ -0,0 +1,130 @@
+// Copyright 2026 Franklin "Snaipe" Mathieu.
+//
+// Use of this source code is governed by the MIT license that can be
+// found in the LICENSE file.
+
+package htutil
+
+import (
+	"fmt"
+	"reflect"
+	"testing"
+)
+
+func TestParseMediaType(t *testing.T) {
+	t.Parallel()
+
+	tcases := []struct {
+		In     string
+		Expect MediaType
+		String string
+		Err    bool
+	}{
+		{In: "text/html", Expect: MediaType{Type: "text", Subtype: "html"}, String: "text/html"},
+		{In: "Application/VND.Example+JSON; Charset=UTF-8", Expect: MediaType{
+			Type: "application", Subtype: "vnd.example+json", Tree: "vnd", Suffix: "json",
+			Params: map[string]string{"charset": "UTF-8"},
+		}, String: "application/vnd.example+json;charset=UTF-8"},
+		{In: "application/prs.foo", Expect: MediaType{Type: "application", Subtype: "prs.foo", Tree: "prs"}, String: "application/prs.foo"},
+		{In: "application/x-www-form-urlencoded", Expect: MediaType{Type: "application", Subtype: "x-www-form-urlencoded", Tree: "x"}, String: "application/x-www-form-urlencoded"},
+		{In: "application/problem+json", Expect: MediaType{Type: "application", Subtype: "problem+json", Suffix: "json"}, String: "application/problem+json"},
+		{In: "image/svg+xml;b=\"x y\";a=1", Expect: MediaType{
+			Type: "image", Subtype: "svg+xml", Suffix: "xml",
+			Params: map[string]string{"a": "1", "b": "x y"},
+		}, String: `image/svg+xml;a=1;b="x y"`},
+		{In: "*/*", Expect: MediaType{Type: "*", Subtype: "*"}, String: "*/*"},
+		{In: "text", Err: true},
+		{In: "text/", Err: true},
+		{In: "", Err: true},
+	}
+
+	for i, tcase := range tcases {
+		t.Run(fmt.Sprintf("%d", i), func(t *testing.T) {
+			mt, err := ParseMediaType(tcase.In)
+			if (err != nil) != tcase.Err {
+				t.Fatalf("expected error %v, got %v", tcase.Err, err)
+			}
+			if err != nil {
+				return
+			}
+			if !reflect.DeepEqual(mt, tcase.Expect) {
+				t.Fatalf("expected %#v, got %#v", tcase.Expect, mt)
+			}
+			if s := mt.String(); s != tcase.String {
+				t.Fatalf("expected %q, got %q", tcase.String, s)
+			}
+		})
+	}
+}
+
+func TestMediaTypeMatch(t *testing.T) {
+	t.Parallel()
+
+	tcases := []struct {
+		MediaType string
+		Pattern   string
+		Match     bool
+		Suffix    bool
+	}{
+		{MediaType: "text/html", Pattern: "text/html", Match: true, Suffix: true},
+		{MediaType: "text/html", Pattern: "text/*", Match: true, Suffix: true},
+		{MediaType: "text/html", Pattern: "*/*", Match: true, Suffix: true},
+		{MediaType: "text/html", Pattern: "text/plain", Match: false, Suffix: false},
+		{MediaType: "text/html", Pattern: "image/*", Match: false, Suffix: false},
+		{MediaType: "text/html;level=1", Pattern: "text/html;level=1", Match: true, Suffix: true},
+		{MediaType: "text/html", Pattern: "text/html;level=1", Match: false, Suffix: false},
+		{MediaType: "text/plain;charset=UTF-8", Pattern: "text/plain;charset=utf-8", Match: true, Suffix: true},
+		{MediaType: "application/vnd.example+json", Pattern: "application/json", Match: false, Suffix: true},
+		{MediaType: "application/vnd.example+json", Pattern: "application/*", Match: true, Suffix: true},
+		{MediaType: "application/vnd.example+json", Pattern: "text/json", Match: false, Suffix: false},
+		{MediaType: "application/json", Pattern: "application/vnd.example+json", Match: false, Suffix: false},
+	}
+
+	for i, tcase := range tcases {
+		t.Run(fmt.Sprintf("%d", i), func(t *testing.T) {
+			mt, err := ParseMediaType(tcase.MediaType)
+			if err != nil {
+				t.Fatal(err)
+			}
+			acc, err := ParseAcceptable(tcase.Pattern)
+			if err != nil {
+				t.Fatal(err)
+			}
+			pattern, ok := acc.MediaType()
+			if !ok {
+				t.Fatalf("expected %q to be a media range", tcase.Pattern)
+			}
+			if match := mt.Match(pattern); match != tcase.Match {
+				t.Fatalf("expected Match %v, got %v", tcase.Match, match)
+			}
+			if match := mt.MatchSuffix(pattern); match != tcase.Suffix {
+				t.Fatalf("expected MatchSuffix %v, got %v", tcase.Suffix, match)
+			}
+		})
+	}
+}
+
+func TestAcceptableMediaType(t *testing.T) {
+	t.Parallel()
+
+	acc, err := ParseAcceptable("application/vnd.example+json;version=2;q=0.5")
+	if err != nil {
+		t.Fatal(err)
+	}
+	mt, ok := acc.MediaType()
+	expect := MediaType{
+		Type: "application", Subtype: "vnd.example+json", Tree: "vnd", Suffix: "json",
+		Params: map[string]string{"version": "2"},
+	}
+	if !ok || !reflect.DeepEqual(mt, expect) {
+		t.Fatalf("expected %#v, got %#v (%v)", expect, mt, ok)
+	}
+
+	acc, err = ParseAcceptable("gzip")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if mt, ok := acc.MediaType(); ok {
+		t.Fatalf("expected no media type, got %#v", mt)
+	}
+}
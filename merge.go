@@ -0,0 +1,304 @@
+// Copyright 2026 Franklin "Snaipe" Mathieu.
+//
+// Use of this source code is governed by the MIT license that can be
+// found in the LICENSE file.
+
+package htutil
+
+import (
+	"fmt"
+	"net/http"
+	"net/textproto"
+	"sort"
+	"strings"
+)
+
+// listHeaders is the set of well-known header fields whose values are
+// comma-separated lists, and may as such be combined member by member.
+// Other fields are treated as singletons: their values are opaque, and
+// may legitimately contain commas (e.g. HTTP-dates in Expires).
+var listHeaders = map[string]struct{}{
+	"Accept":                         {},
+	"Accept-Charset":                 {},
+	"Accept-Encoding":                {},
+	"Accept-Language":                {},
+	"Accept-Patch":                   {},
+	"Accept-Post":                    {},
+	"Accept-Ranges":                  {},
+	"Access-Control-Allow-Headers":   {},
+	"Access-Control-Allow-Methods":   {},
+	"Access-Control-Expose-Headers":  {},
+	"Access-Control-Request-Headers": {},
+	"Allow":                          {},
+	"Cache-Control":                  {},
+	"Cache-Status":                   {},
+	"Clear-Site-Data":                {},
+	"Connection":                     {},
+	"Content-Encoding":               {},
+	"Content-Language":               {},
+	"Forwarded":                      {},
+	"If-Match":                       {},
+	"If-None-Match":                  {},
+	"Link":                           {},
+	"Pragma":                         {},
+	"Prefer":                         {},
+	"Preference-Applied":             {},
+	"Proxy-Status":                   {},
+	"Server-Timing":                  {},
+	"Te":                             {},
+	"Timing-Allow-Origin":            {},
+	"Trailer":                        {},
+	"Transfer-Encoding":              {},
+	"Upgrade":                        {},
+	"Vary":                           {},
+	"Via":                            {},
+	"Warning":                        {},
+	"X-Forwarded-For":                {},
+}
+
+func isListHeader(name string) bool {
+	_, ok := listHeaders[textproto.CanonicalMIMEHeaderKey(name)]
+	return ok
+}
+
+// forwardingHeaders is the set of list-based header fields that record the
+// hops a message went through. Their members are ordered, and repeated
+// members are distinct hops, so they are never deduplicated.
+var forwardingHeaders = map[string]struct{}{
+	"Forwarded":       {},
+	"Via":             {},
+	"X-Forwarded-For": {},
+}
+
+func isForwardingHeader(name string) bool {
+	_, ok := forwardingHeaders[textproto.CanonicalMIMEHeaderKey(name)]
+	return ok
+}
+
+// MergePolicy determines how MergeHeaders resolves conflicts between
+// singleton header fields, and between cookies of the same identity.
+type MergePolicy int
+
+const (
+	// MergeKeep keeps the conflicting values already present in the
+	// destination.
+	MergeKeep MergePolicy = iota
+
+	// MergeOverwrite replaces the conflicting values of the destination by
+	// the ones of the source.
+	MergeOverwrite
+)
+
+// MergeHeaders merges the header fields of src into dst.
+//
+// List-based fields are merged member by member, without duplicates, and
+// are combined into a single field line. Forwarding fields (Forwarded, Via,
+// and X-Forwarded-For) are the exception: the field lines of src are
+// appended to the ones of dst unchanged. Singleton fields present in both
+// headers are resolved according to policy. Set-Cookie fields are never
+// combined; cookies of src are appended to dst, and cookies with the same
+// name, domain, and path are resolved according to policy.
+func MergeHeaders(dst, src http.Header, policy MergePolicy) {
+	for name, values := range src {
+		name = textproto.CanonicalMIMEHeaderKey(name)
+		existing := dst[name]
+		switch {
+		case len(existing) == 0:
+			dst[name] = append([]string(nil), values...)
+		case name == "Set-Cookie":
+			dst[name] = mergeCookies(existing, values, policy)
+		case isForwardingHeader(name):
+			dst[name] = append(append([]string(nil), existing...), values...)
+		case isListHeader(name):
+			members := fieldMembers(name, existing)
+			for _, m := range fieldMembers(name, values) {
+				if !containsString(members, m) {
+					members = append(members, m)
+				}
+			}
+			dst[name] = []string{strings.Join(members, ", ")}
+		case policy == MergeOverwrite:
+			dst[name] = append([]string(nil), values...)
+		}
+	}
+}
+
+// fieldMembers returns the members of the values of the list-based field
+// name. Link members are split with the Link parser, since their targets
+// may contain commas; forwarding members are kept in order, repeats
+// included.
+func fieldMembers(name string, values []string) []string {
+	switch textproto.CanonicalMIMEHeaderKey(name) {
+	case "Link":
+		return linkMembers(values)
+	case "Forwarded", "Via", "X-Forwarded-For":
+		var members []string
+		for _, v := range values {
+			members = append(members, SplitList(v)...)
+		}
+		return members
+	}
+	return listMembers(values)
+}
+
+// linkMembers returns the link-values of the values of Link header fields,
+// without duplicates. A value that cannot be parsed is kept whole.
+func linkMembers(values []string) []string {
+	var members []string
+	add := func(m string) {
+		if m != "" && !containsString(members, m) {
+			members = append(members, m)
+		}
+	}
+	for _, v := range values {
+		var parsed []string
+		for i := 0; ; {
+			for i < len(v) && (v[i] == ',' || isOWS(v[i])) {
+				i++
+			}
+			if i == len(v) {
+				break
+			}
+			_, n, err := parseLinkValue(v[i:])
+			if err != nil {
+				parsed = []string{trimOWS(v)}
+				break
+			}
+			parsed = append(parsed, trimOWS(v[i:i+n]))
+			i += n
+		}
+		for _, m := range parsed {
+			add(m)
+		}
+	}
+	return members
+}
+
+func listMembers(values []string) []string {
+	var members []string
+	for _, v := range values {
+		for _, m := range SplitList(v) {
+			if !containsString(members, m) {
+				members = append(members, m)
+			}
+		}
+	}
+	return members
+}
+
+func containsString(values []string, s string) bool {
+	for _, v := range values {
+		if v == s {
+			return true
+		}
+	}
+	return false
+}
+
+// cookieIdentity returns the name, domain, and path of the cookie set by
+// a Set-Cookie field value, which together identify it.
+func cookieIdentity(setCookie string) string {
+	cookies := (&http.Response{Header: http.Header{"Set-Cookie": {setCookie}}}).Cookies()
+	if len(cookies) == 0 {
+		return setCookie
+	}
+	c := cookies[0]
+	return c.Name + "\x00" + strings.ToLower(c.Domain) + "\x00" + c.Path
+}
+
+func mergeCookies(dst, src []string, policy MergePolicy) []string {
+	out := append([]string(nil), dst...)
+	index := make(map[string]int, len(out))
+	for i, v := range out {
+		index[cookieIdentity(v)] = i
+	}
+	for _, v := range src {
+		id := cookieIdentity(v)
+		i, ok := index[id]
+		switch {
+		case !ok:
+			index[id] = len(out)
+			out = append(out, v)
+		case policy == MergeOverwrite:
+			out[i] = v
+		}
+	}
+	return out
+}
+
+// HeaderDiff is a difference between the values of a header field in two
+// headers, as reported by DiffHeaders.
+type HeaderDiff struct {
+	Name string
+
+	// Removed are the values, or list members, that are only present in
+	// the first header.
+	Removed []string
+
+	// Added are the values, or list members, that are only present in the
+	// second header.
+	Added []string
+}
+
+func (d HeaderDiff) String() string {
+	var out strings.Builder
+	for _, v := range d.Removed {
+		fmt.Fprintf(&out, "- %s: %s\n", d.Name, v)
+	}
+	for _, v := range d.Added {
+		fmt.Fprintf(&out, "+ %s: %s\n", d.Name, v)
+	}
+	return out.String()
+}
+
+// DiffHeaders returns the differences between the header fields of a and
+// b, sorted by field name.
+//
+// List-based fields are compared member by member, regardless of how the
+// members are split across field lines, and of their order. Other fields,
+// including Set-Cookie, are compared value by value, regardless of order.
+func DiffHeaders(a, b http.Header) []HeaderDiff {
+	names := make(map[string]struct{}, len(a)+len(b))
+	for name := range a {
+		names[textproto.CanonicalMIMEHeaderKey(name)] = struct{}{}
+	}
+	for name := range b {
+		names[textproto.CanonicalMIMEHeaderKey(name)] = struct{}{}
+	}
+
+	var diffs []HeaderDiff
+	for name := range names {
+		av, bv := a.Values(name), b.Values(name)
+		if isListHeader(name) {
+			av, bv = fieldMembers(name, av), fieldMembers(name, bv)
+		}
+		d := HeaderDiff{
+			Name:    name,
+			Removed: subtractStrings(av, bv),
+			Added:   subtractStrings(bv, av),
+		}
+		if len(d.Removed) > 0 || len(d.Added) > 0 {
+			diffs = append(diffs, d)
+		}
+	}
+	sort.Slice(diffs, func(i, j int) bool { return diffs[i].Name < diffs[j].Name })
+	return diffs
+}
+
+// subtractStrings returns the values of lhs that are not in rhs, as a
+// multiset difference.
+func subtractStrings(lhs, rhs []string) []string {
+	count := make(map[string]int, len(rhs))
+	for _, v := range rhs {
+		count[v]++
+	}
+	var out []string
+	for _, v := range lhs {
+		if count[v] > 0 {
+			count[v]--
+			continue
+		}
+		out = append(out, v)
+	}
+	return out
+}
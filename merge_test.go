@@ -0,0 +1,112 @@
+// Copyright 2026 Franklin "Snaipe" Mathieu.
+//
+// Use of this source code is governed by the MIT license that can be
+// found in the LICENSE file.
+
+package htutil
+
+import (
+	"fmt"
+	"net/http"
+	"reflect"
+	"testing"
+)
+
+func TestMergeHeaders(t *testing.T) {
+	t.Parallel()
+
+	tcases := []struct {
+		Dst, Src http.Header
+		Policy   MergePolicy
+		Expected http.Header
+	}{
+		{
+			Dst:      http.Header{"Vary": {"Accept, Origin"}},
+			Src:      http.Header{"Vary": {"Origin", "Accept-Encoding"}},
+			Expected: http.Header{"Vary": {"Accept, Origin, Accept-Encoding"}},
+		},
+		{
+			Dst:      http.Header{"Content-Type": {"text/plain"}, "Expires": {"Thu, 01 Jan 1970 00:00:00 GMT"}},
+			Src:      http.Header{"Content-Type": {"text/html"}, "Age": {"3"}},
+			Expected: http.Header{"Content-Type": {"text/plain"}, "Expires": {"Thu, 01 Jan 1970 00:00:00 GMT"}, "Age": {"3"}},
+		},
+		{
+			Dst:      http.Header{"Content-Type": {"text/plain"}},
+			Src:      http.Header{"Content-Type": {"text/html"}},
+			Policy:   MergeOverwrite,
+			Expected: http.Header{"Content-Type": {"text/html"}},
+		},
+		{
+			Dst:      http.Header{"Set-Cookie": {"a=1; Path=/", "b=1"}},
+			Src:      http.Header{"Set-Cookie": {"a=2; Path=/", "a=3; Path=/sub", "c=1"}},
+			Expected: http.Header{"Set-Cookie": {"a=1; Path=/", "b=1", "a=3; Path=/sub", "c=1"}},
+		},
+		{
+			Dst:      http.Header{"Set-Cookie": {"a=1; Path=/", "b=1"}},
+			Src:      http.Header{"Set-Cookie": {"a=2; Path=/"}},
+			Policy:   MergeOverwrite,
+			Expected: http.Header{"Set-Cookie": {"a=2; Path=/", "b=1"}},
+		},
+		{
+			Dst:      http.Header{"X-Forwarded-For": {"203.0.113.1, 10.0.0.1"}, "Forwarded": {"for=10.0.0.1"}},
+			Src:      http.Header{"X-Forwarded-For": {"10.0.0.1"}, "Forwarded": {"for=10.0.0.1"}},
+			Expected: http.Header{"X-Forwarded-For": {"203.0.113.1, 10.0.0.1", "10.0.0.1"}, "Forwarded": {"for=10.0.0.1", "for=10.0.0.1"}},
+		},
+		{
+			Dst:      http.Header{"Link": {"</a,b>; rel=next"}},
+			Src:      http.Header{"Link": {"</a,b>; rel=next, </c>; rel=\"prev, x\""}},
+			Expected: http.Header{"Link": {"</a,b>; rel=next, </c>; rel=\"prev, x\""}},
+		},
+	}
+
+	for i, tcase := range tcases {
+		t.Run(fmt.Sprintf("%d", i), func(t *testing.T) {
+			MergeHeaders(tcase.Dst, tcase.Src, tcase.Policy)
+			if !reflect.DeepEqual(tcase.Dst, tcase.Expected) {
+				t.Fatalf("expected %v, got %v", tcase.Expected, tcase.Dst)
+			}
+		})
+	}
+}
+
+func TestDiffHeaders(t *testing.T) {
+	t.Parallel()
+
+	tcases := []struct {
+		A, B     http.Header
+		Expected []HeaderDiff
+	}{
+		{
+			A: http.Header{"Vary": {"Accept, Origin"}, "Content-Type": {"text/plain"}},
+			B: http.Header{"Vary": {"Origin", "Accept"}, "Content-Type": {"text/plain"}},
+		},
+		{
+			A: http.Header{"Vary": {"Accept, Origin"}, "Content-Type": {"text/plain"}},
+			B: http.Header{"Vary": {"Accept-Encoding, Accept"}, "Content-Type": {"text/html"}, "Age": {"1"}},
+			Expected: []HeaderDiff{
+				{Name: "Age", Added: []string{"1"}},
+				{Name: "Content-Type", Removed: []string{"text/plain"}, Added: []string{"text/html"}},
+				{Name: "Vary", Removed: []string{"Origin"}, Added: []string{"Accept-Encoding"}},
+			},
+		},
+		{
+			A:        http.Header{"Set-Cookie": {"a=1", "b=1"}},
+			B:        http.Header{"Set-Cookie": {"b=1", "a=1", "a=1"}},
+			Expected: []HeaderDiff{{Name: "Set-Cookie", Added: []string{"a=1"}}},
+		},
+		{
+			A:        http.Header{"X-Forwarded-For": {"10.0.0.1"}, "Link": {"</a,b>; rel=next"}},
+			B:        http.Header{"X-Forwarded-For": {"10.0.0.1, 10.0.0.1"}, "Link": {"</a,b>; rel=next"}},
+			Expected: []HeaderDiff{{Name: "X-Forwarded-For", Added: []string{"10.0.0.1"}}},
+		},
+	}
+
+	for i, tcase := range tcases {
+		t.Run(fmt.Sprintf("%d", i), func(t *testing.T) {
+			diffs := DiffHeaders(tcase.A, tcase.B)
+			if !reflect.DeepEqual(diffs, tcase.Expected) {
+				t.Fatalf("expected %v, got %v", tcase.Expected, diffs)
+			}
+		})
+	}
+}
@@ -0,0 +1,155 @@
+// Copyright 2026 Franklin "Snaipe" Mathieu.
+//
+// Use of this source code is governed by the MIT license that can be
+// found in the LICENSE file.
+
+package htutil
+
+import (
+	"fmt"
+	"io"
+	"net/http"
+	"strconv"
+	"strings"
+)
+
+// MethodSwitch is a http.Handler that serves requests with the handler
+// registered for their method.
+//
+// OPTIONS requests are answered with the allowed methods in the Allow
+// header field, and HEAD requests are served by the GET handler, unless
+// handlers are registered for these methods. Other methods are answered
+// with a 405 Method Not Allowed problem.
+//
+// The zero value is an empty MethodSwitch, allowing only OPTIONS. Handlers
+// must be registered before serving requests.
+type MethodSwitch struct {
+	methods  []string
+	handlers map[string]http.Handler
+}
+
+// Handle registers the handler for the method, which is case-sensitive.
+// Registering a method again replaces its handler.
+func (ms *MethodSwitch) Handle(method string, handler http.Handler) {
+	if !IsToken(method) {
+		panic("htutil: invalid method " + method)
+	}
+	if ms.handlers == nil {
+		ms.handlers = make(map[string]http.Handler)
+	}
+	if _, ok := ms.handlers[method]; !ok {
+		ms.methods = append(ms.methods, method)
+	}
+	ms.handlers[method] = handler
+}
+
+// HandleFunc registers the handler function for the method.
+func (ms *MethodSwitch) HandleFunc(method string, handler func(http.ResponseWriter, *http.Request)) {
+	ms.Handle(method, http.HandlerFunc(handler))
+}
+
+// Allow returns the allowed methods, in registration order, with HEAD
+// following GET, and OPTIONS last, unless registered.
+func (ms *MethodSwitch) Allow() []string {
+	allow := make([]string, 0, len(ms.methods)+2)
+	for _, method := range ms.methods {
+		allow = append(allow, method)
+		if _, ok := ms.handlers[http.MethodHead]; method == http.MethodGet && !ok {
+			allow = append(allow, http.MethodHead)
+		}
+	}
+	if _, ok := ms.handlers[http.MethodOptions]; !ok {
+		allow = append(allow, http.MethodOptions)
+	}
+	return allow
+}
+
+func (ms *MethodSwitch) ServeHTTP(w http.ResponseWriter, req *http.Request) {
+	if h, ok := ms.handlers[req.Method]; ok {
+		h.ServeHTTP(w, req)
+		return
+	}
+	get, hasGet := ms.handlers[http.MethodGet]
+	switch {
+	case req.Method == http.MethodHead && hasGet:
+		ServeHead(get, w, req)
+	case req.Method == http.MethodOptions:
+		w.Header().Set("Allow", strings.Join(ms.Allow(), ", "))
+		w.Header().Set("Content-Length", "0")
+		if req.ContentLength != 0 {
+			io.Copy(io.Discard, http.MaxBytesReader(w, req.Body, 4<<10))
+		}
+		w.WriteHeader(http.StatusOK)
+	default:
+		w.Header().Set("Allow", strings.Join(ms.Allow(), ", "))
+		RespondError(w, req, NewProblem(http.StatusMethodNotAllowed,
+			fmt.Sprintf("The method %s is not allowed.", req.Method)))
+	}
+}
+
+// ServeHead serves the HEAD request req with the handler of GET requests
+// get, discarding the content that it writes. Unless get sets it, or
+// flushes the response, the Content-Length header field is set to the size
+// of the discarded content, as it would be for a GET request.
+func ServeHead(get http.Handler, w http.ResponseWriter, req *http.Request) {
+	hw := &headWriter{w: w}
+	get.ServeHTTP(Wrap(w, WriterHooks{
+		WriteHeader: func(next WriteHeaderFunc) WriteHeaderFunc { return hw.writeHeader },
+		Write:       func(next WriteFunc) WriteFunc { return hw.Write },
+		Flush: func(next FlushFunc) FlushFunc {
+			return func() {
+				hw.commit(false)
+				next()
+			}
+		},
+		ReadFrom: func(next ReadFromFunc) ReadFromFunc {
+			return func(r io.Reader) (int64, error) {
+				return io.Copy(hw, r)
+			}
+		},
+	}), req)
+	hw.commit(true)
+}
+
+// headWriter discards the content of the response to a HEAD request,
+// holding its header until the size of the content is known.
+type headWriter struct {
+	w         http.ResponseWriter
+	status    int
+	size      countingWriter
+	committed bool
+}
+
+func (hw *headWriter) writeHeader(status int) {
+	switch {
+	case hw.committed:
+	case status >= 100 && status < 200:
+		hw.w.WriteHeader(status)
+	case hw.status == 0:
+		hw.status = status
+	}
+}
+
+func (hw *headWriter) Write(p []byte) (int, error) {
+	if hw.status == 0 {
+		hw.status = http.StatusOK
+	}
+	return hw.size.Write(p)
+}
+
+// commit writes the held header, with the size of the content if complete.
+func (hw *headWriter) commit(complete bool) {
+	if hw.committed {
+		return
+	}
+	hw.committed = true
+	if hw.status == 0 {
+		hw.status = http.StatusOK
+	}
+	h := hw.w.Header()
+	if complete && h.Get("Content-Length") == "" && h.Get("Transfer-Encoding") == "" &&
+		hw.status != http.StatusNoContent && hw.status != http.StatusNotModified {
+		h.Set("Content-Length", strconv.FormatInt(int64(hw.size), 10))
+	}
+	hw.w.WriteHeader(hw.status)
+}
@@ -0,0 +1,158 @@
+// Copyright 2026 Franklin "Snaipe" Mathieu.
+//
+// Use of this source code is governed by the MIT license that can be
+// found in the LICENSE file.
+
+package htutil
+
+import (
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+func TestMethodSwitch(t *testing.T) {
+	t.Parallel()
+
+	var ms MethodSwitch
+	ms.HandleFunc("GET", func(w http.ResponseWriter, req *http.Request) {
+		w.Header().Set("Content-Type", "text/plain")
+		io.Copy(w, strings.NewReader("hello, "))
+		fmt.Fprint(w, "world")
+	})
+	ms.HandleFunc("PUT", func(w http.ResponseWriter, req *http.Request) {
+		w.WriteHeader(http.StatusNoContent)
+	})
+
+	tcases := []struct {
+		Method        string
+		Status        int
+		Body          string
+		Allow         string
+		ContentLength string
+	}{
+		{Method: "GET", Status: 200, Body: "hello, world", ContentLength: ""},
+		{Method: "HEAD", Status: 200, Body: "", ContentLength: "12"},
+		{Method: "PUT", Status: 204},
+		{Method: "OPTIONS", Status: 200, Allow: "GET, HEAD, PUT, OPTIONS", ContentLength: "0"},
+		{Method: "DELETE", Status: 405, Allow: "GET, HEAD, PUT, OPTIONS"},
+		{Method: "get", Status: 405, Allow: "GET, HEAD, PUT, OPTIONS"},
+	}
+
+	for i, tcase := range tcases {
+		t.Run(fmt.Sprintf("%d", i), func(t *testing.T) {
+			req := httptest.NewRequest(tcase.Method, "/", nil)
+			w := httptest.NewRecorder()
+			ms.ServeHTTP(w, req)
+
+			if w.Code != tcase.Status {
+				t.Fatalf("expected status %d, got %d", tcase.Status, w.Code)
+			}
+			if got := w.Header().Get("Allow"); got != tcase.Allow {
+				t.Fatalf("expected Allow %q, got %q", tcase.Allow, got)
+			}
+			if got := w.Header().Get("Content-Length"); got != tcase.ContentLength {
+				t.Fatalf("expected Content-Length %q, got %q", tcase.ContentLength, got)
+			}
+			if tcase.Status != 405 && w.Body.String() != tcase.Body {
+				t.Fatalf("expected body %q, got %q", tcase.Body, w.Body.String())
+			}
+		})
+	}
+}
+
+func TestMethodSwitchAllow(t *testing.T) {
+	t.Parallel()
+
+	nop := http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {})
+
+	tcases := []struct {
+		Methods []string
+		Expect  string
+	}{
+		{Methods: nil, Expect: "OPTIONS"},
+		{Methods: []string{"POST", "GET"}, Expect: "POST, GET, HEAD, OPTIONS"},
+		{Methods: []string{"HEAD", "GET", "OPTIONS"}, Expect: "HEAD, GET, OPTIONS"},
+		{Methods: []string{"GET", "GET"}, Expect: "GET, HEAD, OPTIONS"},
+	}
+
+	for i, tcase := range tcases {
+		t.Run(fmt.Sprintf("%d", i), func(t *testing.T) {
+			var ms MethodSwitch
+			for _, method := range tcase.Methods {
+				ms.Handle(method, nop)
+			}
+			if got := strings.Join(ms.Allow(), ", "); got != tcase.Expect {
+				t.Fatalf("expected %q, got %q", tcase.Expect, got)
+			}
+		})
+	}
+}
+
+func TestServeHead(t *testing.T) {
+	t.Parallel()
+
+	tcases := []struct {
+		Handler       http.HandlerFunc
+		Status        int
+		ContentLength string
+	}{
+		{
+			Handler:       func(w http.ResponseWriter, req *http.Request) {},
+			Status:        200,
+			ContentLength: "0",
+		},
+		{
+			Handler: func(w http.ResponseWriter, req *http.Request) {
+				w.WriteHeader(http.StatusNotFound)
+				fmt.Fprint(w, "not found")
+			},
+			Status:        404,
+			ContentLength: "9",
+		},
+		{
+			Handler: func(w http.ResponseWriter, req *http.Request) {
+				w.Header().Set("Content-Length", "100")
+			},
+			Status:        200,
+			ContentLength: "100",
+		},
+		{
+			Handler: func(w http.ResponseWriter, req *http.Request) {
+				fmt.Fprint(w, "partial")
+				http.NewResponseController(w).Flush()
+				fmt.Fprint(w, "rest")
+			},
+			Status:        200,
+			ContentLength: "",
+		},
+		{
+			Handler: func(w http.ResponseWriter, req *http.Request) {
+				w.WriteHeader(http.StatusNotModified)
+			},
+			Status:        304,
+			ContentLength: "",
+		},
+	}
+
+	for i, tcase := range tcases {
+		t.Run(fmt.Sprintf("%d", i), func(t *testing.T) {
+			req := httptest.NewRequest("HEAD", "/", nil)
+			w := httptest.NewRecorder()
+			ServeHead(tcase.Handler, w, req)
+
+			if w.Code != tcase.Status {
+				t.Fatalf("expected status %d, got %d", tcase.Status, w.Code)
+			}
+			if got := w.Header().Get("Content-Length"); got != tcase.ContentLength {
+				t.Fatalf("expected Content-Length %q, got %q", tcase.ContentLength, got)
+			}
+			if w.Body.Len() != 0 {
+				t.Fatalf("expected no body, got %q", w.Body.String())
+			}
+		})
+	}
+}
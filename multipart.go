@@ -0,0 +1,277 @@
+// Copyright 2026 Franklin "Snaipe" Mathieu.
+//
+// Use of this source code is governed by the MIT license that can be
+// found in the LICENSE file.
+
+package htutil
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"mime/multipart"
+	"net/http"
+	"net/textproto"
+	"strings"
+)
+
+// ContentTooLargeError is the error of request content exceeding a size
+// limit, or a limit on its number of parts. It unwraps to a 413 Content Too
+// Large problem.
+type ContentTooLargeError struct {
+	// Part is the name of the form part exceeding its size limit, or "" if
+	// the whole content exceeds it.
+	Part string
+
+	// Limit is the exceeded size limit, in bytes.
+	Limit int64
+
+	// Parts is the exceeded limit on the number of parts, or 0 if a size
+	// limit was exceeded.
+	Parts int
+}
+
+func (e *ContentTooLargeError) Error() string {
+	switch {
+	case e.Parts > 0:
+		return fmt.Sprintf("request content has more than %d parts", e.Parts)
+	case e.Part == "":
+		return fmt.Sprintf("request content exceeds %d bytes", e.Limit)
+	}
+	return fmt.Sprintf("part %q exceeds %d bytes", e.Part, e.Limit)
+}
+
+// Unwrap returns the 413 Content Too Large problem describing e.
+func (e *ContentTooLargeError) Unwrap() error {
+	switch {
+	case e.Parts > 0:
+		return NewProblem(http.StatusRequestEntityTooLarge,
+			fmt.Sprintf("The request content has more than %d parts.", e.Parts))
+	case e.Part == "":
+		return NewProblem(http.StatusRequestEntityTooLarge,
+			fmt.Sprintf("The request content exceeds %d bytes.", e.Limit))
+	}
+	return NewProblem(http.StatusRequestEntityTooLarge,
+		fmt.Sprintf("The content of part %s exceeds %d bytes.", e.Part, e.Limit))
+}
+
+// UnsupportedPartError is the error of multipart/form-data parts whose
+// content is not of an accepted media type. It unwraps to a 415
+// Unsupported Media Type problem.
+type UnsupportedPartError struct {
+	// Part is the name of the form part.
+	Part string
+
+	// MediaType is the media type of the part, or "" if it has no valid
+	// media type.
+	MediaType string
+
+	// Accepted are the accepted media ranges.
+	Accepted []string
+}
+
+func (e *UnsupportedPartError) Error() string {
+	mediaType := e.MediaType
+	if mediaType == "" {
+		mediaType = "no media type"
+	}
+	return fmt.Sprintf("unsupported content of %s in part %q, accepted: %s",
+		mediaType, e.Part, strings.Join(e.Accepted, ", "))
+}
+
+// Unwrap returns the 415 Unsupported Media Type problem describing e.
+func (e *UnsupportedPartError) Unwrap() error {
+	if e.MediaType == "" {
+		return NewProblem(http.StatusUnsupportedMediaType,
+			fmt.Sprintf("The content of part %s has no valid media type.", e.Part))
+	}
+	return NewProblem(http.StatusUnsupportedMediaType,
+		fmt.Sprintf("Content of type %s is not supported in part %s.", e.MediaType, e.Part))
+}
+
+// MultipartStreamer streams the parts of multipart/form-data requests,
+// unlike http.Request.ParseMultipartForm, which buffers them in memory or
+// on disk, with limits on their size and media types.
+type MultipartStreamer struct {
+	// MaxPartSize is the maximum size of the content of a part. Defaults
+	// to DefaultMaxBodySize.
+	MaxPartSize int64
+
+	// MaxTotalSize is the maximum size of the request content. Defaults to
+	// 32 MiB.
+	MaxTotalSize int64
+
+	// MaxParts is the maximum number of parts. Defaults to 1000.
+	MaxParts int
+
+	// Accept are the media ranges accepted for the content of parts, as
+	// members of an Accept header field: "image/*, image/svg+xml;q=0"
+	// accepts any image but SVG. Parts without a Content-Type are of type
+	// text/plain, as per RFC 7578 §4.4. If empty, parts of any media type
+	// are accepted.
+	Accept []string
+}
+
+func (s *MultipartStreamer) maxPartSize() int64 {
+	if s.MaxPartSize > 0 {
+		return s.MaxPartSize
+	}
+	return DefaultMaxBodySize
+}
+
+func (s *MultipartStreamer) maxTotalSize() int64 {
+	if s.MaxTotalSize > 0 {
+		return s.MaxTotalSize
+	}
+	return 32 << 20
+}
+
+func (s *MultipartStreamer) maxParts() int {
+	if s.MaxParts > 0 {
+		return s.MaxParts
+	}
+	return 1000
+}
+
+// PartReader reads the parts of a multipart/form-data request, as started
+// by MultipartStreamer.Stream.
+type PartReader struct {
+	s       *MultipartStreamer
+	mr      *multipart.Reader
+	body    *io.LimitedReader
+	accepts []Acceptable
+	parts   int
+}
+
+// Stream starts reading the parts of the multipart/form-data request req.
+// It returns an *UnsupportedMediaTypeError if req is not of that type.
+func (s *MultipartStreamer) Stream(req *http.Request) (*PartReader, error) {
+	field := ""
+	switch req.Method {
+	case http.MethodPost:
+		field = "Accept-Post"
+	case http.MethodPatch:
+		field = "Accept-Patch"
+	}
+	mt, err := ParseMediaType(req.Header.Get("Content-Type"))
+	if err != nil || mt.Essence() != "multipart/form-data" || mt.Params["boundary"] == "" {
+		uerr := &UnsupportedMediaTypeError{Field: field, Accepted: []string{"multipart/form-data"}}
+		if err == nil {
+			uerr.MediaType = mt.Essence()
+		}
+		return nil, uerr
+	}
+
+	body := req.Body
+	if body == nil {
+		body = http.NoBody
+	}
+	pr := &PartReader{
+		s:    s,
+		body: &io.LimitedReader{R: body, N: s.maxTotalSize() + 1},
+	}
+	pr.mr = multipart.NewReader(pr.body, mt.Params["boundary"])
+	if len(s.Accept) > 0 {
+		pr.accepts = ParseAccept(s.Accept...)
+	}
+	return pr, nil
+}
+
+// Next returns the next part, or io.EOF once all parts are read. The
+// content of the previous part is skipped if it was not read entirely.
+//
+// If the part is not of an accepted media type, Next returns an
+// *UnsupportedPartError, and Next may be called again to skip it. A
+// *ContentTooLargeError is returned if the request content exceeds
+// MaxTotalSize, or if it has more than MaxParts parts, and a 400 Bad
+// Request problem if it is malformed.
+func (r *PartReader) Next() (*Part, error) {
+	p, err := r.mr.NextPart()
+	if err == io.EOF {
+		return nil, io.EOF
+	}
+	if err != nil {
+		return nil, r.readError(err)
+	}
+	r.parts++
+	if r.parts > r.s.maxParts() {
+		return nil, &ContentTooLargeError{Parts: r.s.maxParts()}
+	}
+
+	ctype := p.Header.Get("Content-Type")
+	if ctype == "" {
+		ctype = "text/plain"
+	}
+	mt, err := ParseMediaType(ctype)
+	if err != nil {
+		return nil, &UnsupportedPartError{Part: p.FormName(), Accepted: r.s.Accept}
+	}
+	if r.accepts != nil {
+		match := mostSpecificMatch("Accept", r.accepts, Offer{Value: mt.Essence(), Params: mt.Params})
+		if match == nil || match.Quality == 0 {
+			return nil, &UnsupportedPartError{Part: p.FormName(), MediaType: mt.Essence(), Accepted: r.s.Accept}
+		}
+	}
+
+	return &Part{
+		Name:      p.FormName(),
+		FileName:  p.FileName(),
+		MediaType: mt,
+		Header:    p.Header,
+		r:         r,
+		content:   &io.LimitedReader{R: p, N: r.s.maxPartSize() + 1},
+	}, nil
+}
+
+// readError returns the error to report for the error err reading the
+// request content.
+func (r *PartReader) readError(err error) error {
+	var tooLarge *http.MaxBytesError
+	switch {
+	case r.body.N <= 0:
+		return &ContentTooLargeError{Limit: r.s.maxTotalSize()}
+	case errors.As(err, &tooLarge):
+		return &ContentTooLargeError{Limit: tooLarge.Limit}
+	}
+	return NewProblem(http.StatusBadRequest, fmt.Sprintf("Invalid multipart content: %v.", err))
+}
+
+// Part is a part of a multipart/form-data request, whose content is
+// streamed by reading it.
+type Part struct {
+	// Name is the name of the form field of the part.
+	Name string
+
+	// FileName is the file name of the part, or "" if it is not a file.
+	FileName string
+
+	// MediaType is the media type of the content of the part.
+	MediaType MediaType
+
+	// Header is the header of the part.
+	Header textproto.MIMEHeader
+
+	r       *PartReader
+	content *io.LimitedReader
+	err     error
+}
+
+// Read reads the content of the part. It returns a *ContentTooLargeError
+// once the part exceeds MaxPartSize, or the request content exceeds
+// MaxTotalSize.
+func (p *Part) Read(b []byte) (int, error) {
+	if p.err != nil {
+		return 0, p.err
+	}
+	n, err := p.content.Read(b)
+	if p.content.N <= 0 {
+		// The byte beyond the limit is withheld.
+		p.err = &ContentTooLargeError{Part: p.Name, Limit: p.r.s.maxPartSize()}
+		return n - 1, p.err
+	}
+	if err != nil && err != io.EOF {
+		p.err = p.r.readError(err)
+		return n, p.err
+	}
+	return n, err
+}
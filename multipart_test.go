@@ -0,0 +1,170 @@
+// Copyright 2026 Franklin "Snaipe" Mathieu.
+//
+// Use of this source code is governed by the MIT license that can be
+// found in the LICENSE file.
+
+package htutil
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"io"
+	"mime/multipart"
+	"net/http"
+	"net/http/httptest"
+	"net/textproto"
+	"strings"
+	"testing"
+)
+
+type testPart struct {
+	Name, FileName, Type, Content string
+}
+
+func newMultipartRequest(t *testing.T, parts ...testPart) *http.Request {
+	t.Helper()
+
+	var body bytes.Buffer
+	mw := multipart.NewWriter(&body)
+	for _, p := range parts {
+		hdr := textproto.MIMEHeader{}
+		disposition := fmt.Sprintf("form-data; name=%q", p.Name)
+		if p.FileName != "" {
+			disposition += fmt.Sprintf("; filename=%q", p.FileName)
+		}
+		hdr.Set("Content-Disposition", disposition)
+		if p.Type != "" {
+			hdr.Set("Content-Type", p.Type)
+		}
+		w, err := mw.CreatePart(hdr)
+		if err != nil {
+			t.Fatal(err)
+		}
+		io.WriteString(w, p.Content)
+	}
+	mw.Close()
+
+	req := httptest.NewRequest("POST", "/upload", &body)
+	req.Header.Set("Content-Type", mw.FormDataContentType())
+	return req
+}
+
+func TestMultipartStreamer(t *testing.T) {
+	t.Parallel()
+
+	field := testPart{Name: "title", Content: "holiday"}
+	png := testPart{Name: "photo", FileName: "a.png", Type: "image/png", Content: "PNG"}
+	svg := testPart{Name: "photo", FileName: "a.svg", Type: "image/svg+xml", Content: "<svg/>"}
+	accept := []string{"text/plain", "image/*", "image/svg+xml;q=0"}
+
+	tcases := []struct {
+		Streamer MultipartStreamer
+		Parts    []testPart
+		Expect   string
+		Status   int
+	}{
+		{Streamer: MultipartStreamer{Accept: accept}, Parts: []testPart{field, png},
+			Expect: "title(text/plain)=holiday photo[a.png](image/png)=PNG "},
+		{Streamer: MultipartStreamer{}, Parts: []testPart{svg}, Expect: "photo[a.svg](image/svg+xml)=<svg/> "},
+		{Streamer: MultipartStreamer{Accept: accept}, Parts: []testPart{field, svg},
+			Expect: "title(text/plain)=holiday ", Status: http.StatusUnsupportedMediaType},
+		{Streamer: MultipartStreamer{Accept: []string{"image/*"}}, Parts: []testPart{field},
+			Status: http.StatusUnsupportedMediaType},
+		{Streamer: MultipartStreamer{Accept: accept}, Parts: []testPart{{Name: "x", Type: "bogus", Content: "?"}},
+			Status: http.StatusUnsupportedMediaType},
+		{Streamer: MultipartStreamer{MaxPartSize: 3}, Parts: []testPart{png, field},
+			Expect: "photo[a.png](image/png)=PNG ", Status: http.StatusRequestEntityTooLarge},
+		{Streamer: MultipartStreamer{MaxTotalSize: 256}, Parts: []testPart{field, {Name: "big", Content: strings.Repeat("x", 512)}},
+			Expect: "title(text/plain)=holiday ", Status: http.StatusRequestEntityTooLarge},
+		{Streamer: MultipartStreamer{MaxParts: 1}, Parts: []testPart{field, png},
+			Expect: "title(text/plain)=holiday ", Status: http.StatusRequestEntityTooLarge},
+	}
+
+	for i, tcase := range tcases {
+		t.Run(fmt.Sprintf("%d", i), func(t *testing.T) {
+			pr, err := tcase.Streamer.Stream(newMultipartRequest(t, tcase.Parts...))
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			var (
+				got    strings.Builder
+				status int
+			)
+			for {
+				part, err := pr.Next()
+				if err == io.EOF {
+					break
+				}
+				var content []byte
+				if err == nil {
+					content, err = io.ReadAll(part)
+				}
+				if err != nil {
+					var p *Problem
+					if !errors.As(err, &p) {
+						t.Fatalf("expected a problem, got %v", err)
+					}
+					var tooLarge *ContentTooLargeError
+					if p.Status == http.StatusRequestEntityTooLarge && !errors.As(err, &tooLarge) {
+						t.Fatalf("expected a *ContentTooLargeError, got %v", err)
+					}
+					status = p.Status
+					break
+				}
+				got.WriteString(part.Name)
+				if part.FileName != "" {
+					got.WriteString("[" + part.FileName + "]")
+				}
+				fmt.Fprintf(&got, "(%s)=%s ", part.MediaType.Essence(), content)
+			}
+			if status != tcase.Status {
+				t.Fatalf("expected status %d, got %d", tcase.Status, status)
+			}
+			if got.String() != tcase.Expect {
+				t.Fatalf("expected %q, got %q", tcase.Expect, got.String())
+			}
+		})
+	}
+}
+
+func TestMultipartStreamerMediaType(t *testing.T) {
+	t.Parallel()
+
+	tcases := []struct {
+		Method      string
+		ContentType string
+		Field       string
+		Err         bool
+	}{
+		{Method: "POST", ContentType: "multipart/form-data; boundary=x"},
+		{Method: "POST", ContentType: "multipart/form-data", Field: "Accept-Post", Err: true},
+		{Method: "POST", ContentType: "application/json", Field: "Accept-Post", Err: true},
+		{Method: "PATCH", ContentType: "multipart/mixed; boundary=x", Field: "Accept-Patch", Err: true},
+		{Method: "PUT", ContentType: "text/plain", Err: true},
+	}
+
+	for i, tcase := range tcases {
+		t.Run(fmt.Sprintf("%d", i), func(t *testing.T) {
+			req := httptest.NewRequest(tcase.Method, "/", strings.NewReader("--x--"))
+			req.Header.Set("Content-Type", tcase.ContentType)
+
+			_, err := (&MultipartStreamer{}).Stream(req)
+			if (err != nil) != tcase.Err {
+				t.Fatalf("expected error=%v, got %v", tcase.Err, err)
+			}
+			if err == nil {
+				return
+			}
+			w := httptest.NewRecorder()
+			RespondError(w, req, err)
+			if w.Code != http.StatusUnsupportedMediaType {
+				t.Fatalf("expected status 415, got %d", w.Code)
+			}
+			if tcase.Field != "" && w.Header().Get(tcase.Field) != "multipart/form-data" {
+				t.Fatalf("expected %s to advertise multipart/form-data, got %q", tcase.Field, w.Header().Get(tcase.Field))
+			}
+		})
+	}
+}
@@ -6,10 +6,7 @@
 package htutil
 
 import (
-	"fmt"
-	"mime"
 	"net/http"
-	"sort"
 	"strconv"
 	"strings"
 )
@@ -26,7 +23,7 @@ func qualityEq(lhs, rhs float32) bool {
 // and character set (Accept-Charset).
 type Acceptable struct {
 	// The value that is acceptable for a response. May contain wildcard
-	// ('*') characters.
+	// ('*') characters. Media ranges can be broken down with MediaType.
 	Value string
 
 	// The quality, between 0 and 1, of the accepted value.
@@ -34,38 +31,31 @@ type Acceptable struct {
 	// Default quality is 1.
 	Quality float32
 
-	// Params contains any extra optional parameters for this value.
+	// QualitySet is whether the quality was explicitly specified with a q
+	// parameter, which tells "text/html;q=1" apart from "text/html".
+	QualitySet bool
+
+	// Params contains any extra optional parameters for this value, like
+	// the parameters of a media range, which precede the quality.
 	Params map[string]string
+
+	// Extensions contains the accept-ext parameters that follow the
+	// quality, as per RFC 9110 §12.5.1. They are not part of the value,
+	// and are ignored when matching.
+	Extensions map[string]string
 }
 
 // ParseAcceptable parses a single acceptable value, as laid out in an
 // Accept{,-*} or Content-* header as per RFC2616 §14.1
+//
+// Values and parameter names are lowercased. Extensions is nil if no
+// parameters follow the quality factor.
 func ParseAcceptable(v string) (Acceptable, error) {
-	// mime.ParseMediaType actually understands other things than pure media
-	// types, like encoding, language, and charsets. It also ensures that
-	//
-	value, params, err := mime.ParseMediaType(v)
-	if err != nil {
-		return Acceptable{}, err
-	}
-
-	quality := 1.0
-	if qstr, ok := params["q"]; ok {
-		quality, err = strconv.ParseFloat(qstr, 32)
-		if err != nil {
-			return Acceptable{}, fmt.Errorf("parsing quality factor: %w", err)
-		}
-		if quality > 1 || quality < 0 {
-			return Acceptable{}, fmt.Errorf("parsing quality factor: %s is not between 0 and 1", qstr)
-		}
-		delete(params, "q")
+	acc, err := parseAcceptableValue(v)
+	if err == nil && acc.Params == nil {
+		acc.Params = make(map[string]string)
 	}
-
-	return Acceptable{
-		Value:   value,
-		Quality: float32(quality),
-		Params:  params,
-	}, nil
+	return acc, err
 }
 
 // Less is a comparison function for two Acceptables. lhs is less than rhs if:
@@ -97,41 +87,32 @@ func (lhs Acceptable) Less(rhs Acceptable) bool {
 	return len(lhs.Params) > len(rhs.Params)
 }
 
+// String returns acc as a member of an Accept header field value. Parameters
+// and accept-ext parameters are written in lexicographic order, so that the
+// result is deterministic.
 func (acc Acceptable) String() string {
 	var out strings.Builder
-	fmt.Fprintf(&out, "%s", acc.Value)
-	if !qualityEq(acc.Quality, 1.0) {
-		out.WriteString(strings.TrimRight(fmt.Sprintf(";q=%.3f", acc.Quality), "0."))
-	}
-	for k, v := range acc.Params {
-		fmt.Fprintf(&out, ";%s=%s", k, v)
+	out.WriteString(acc.Value)
+	writeAcceptParams(&out, acc.Params)
+	if acc.QualitySet || len(acc.Extensions) > 0 || !qualityEq(acc.Quality, 1.0) {
+		out.WriteString(";q=" + strconv.FormatFloat(float64(acc.Quality), 'f', -1, 32))
 	}
+	writeAcceptParams(&out, acc.Extensions)
 	return out.String()
 }
 
 // ParseAccept parses the accept header, and returns a list of acceptable values,
 // sorted by precedence. Any unparseable value is silently dropped.
+//
+// Values of equal precedence keep their order in the header. Hot paths can
+// avoid allocating the list with AcceptBuffer or ForEachAcceptable.
 func ParseAccept(accepts ...string) []Acceptable {
-	sz := 0
-	for _, accept := range accepts {
-		sz += strings.Count(accept, ",") + 1
-	}
-
-	types := make([]Acceptable, sz)
-	i := 0
-	for _, accept := range accepts {
-		values := strings.Split(accept, ",")
-		for _, value := range values {
-			acc, err := ParseAcceptable(value)
-			if err != nil {
-				continue
-			}
-			types[i] = acc
-			i++
+	types := AppendAccept(nil, accepts...)
+	for i := range types {
+		if types[i].Params == nil {
+			types[i].Params = make(map[string]string)
 		}
 	}
-	types = types[:i]
-	sort.Slice(types, func(i, j int) bool { return Acceptable.Less(types[i], types[j]) })
 	return types
 }
 
@@ -158,7 +139,12 @@ func dumbglob(pattern, value string) bool {
 // over the accepted media types by order of precedence.
 //
 // If no offer matches, ("", nil) is returned.
+//
+// Accept-Language is negotiated with NegotiateLanguage.
 func NegotiateContent(hdr http.Header, key string, offers ...string) (string, *Acceptable) {
+	if http.CanonicalHeaderKey(key) == "Accept-Language" {
+		return NegotiateLanguage(hdr, offers...)
+	}
 	values := hdr.Values(key)
 	if len(values) == 0 {
 		switch key {
@@ -175,9 +161,13 @@ func NegotiateContent(hdr http.Header, key string, offers ...string) (string, *A
 			break
 		}
 	}
-	for _, acc := range ParseAccept(values...) {
+	buf := AcquireAcceptBuffer()
+	defer buf.Release()
+	for _, acc := range buf.Parse(values...) {
 		if acc.Quality == 0 {
-			if acc.Value == "identity" {
+			// *;q=0 refuses identity too, unless it is explicitly
+			// accepted, in which case it was matched already.
+			if acc.Value == "identity" || acc.Value == "*" {
 				identityRefused = true
 			}
 			continue
@@ -90,6 +90,12 @@ func TestNegotiateContent(t *testing.T) {
 			Offers: []string{},
 			Expect: "",
 		},
+		{
+			Header: "Accept",
+			Accept: `text/plain; title="a, b"; q=0.1, application/json; q=0.5`,
+			Offers: []string{"text/plain"},
+			Expect: "text/plain",
+		},
 		{
 			Header: "Accept-Encoding",
 			Accept: "gzip",
@@ -123,6 +129,18 @@ func TestNegotiateContent(t *testing.T) {
 			Offers: []string{"identity"},
 			Expect: "",
 		},
+		{
+			Header: "Accept-Encoding",
+			Accept: "gzip, *;q=0",
+			Offers: []string{"identity"},
+			Expect: "",
+		},
+		{
+			Header: "Accept-Encoding",
+			Accept: "identity;q=0.5, *;q=0",
+			Offers: []string{"gzip", "identity"},
+			Expect: "identity",
+		},
 	}
 
 	for i, tcase := range tcases {
@@ -139,3 +157,32 @@ func TestNegotiateContent(t *testing.T) {
 		})
 	}
 }
+
+func TestAcceptableString(t *testing.T) {
+	t.Parallel()
+
+	tcases := []struct {
+		In     string
+		Expect string
+	}{
+		{In: "text/html", Expect: "text/html"},
+		{In: "text/html;q=1", Expect: "text/html;q=1"},
+		{In: "text/html;level=1;q=0.5", Expect: "text/html;level=1;q=0.5"},
+		{In: "text/html;q=0.5;ext=1", Expect: "text/html;q=0.5;ext=1"},
+		{In: "gzip;q=0", Expect: "gzip;q=0"},
+		{In: "text/plain;format=flowed;charset=utf-8;delsp=no", Expect: "text/plain;charset=utf-8;delsp=no;format=flowed"},
+		{In: "text/html;q=0.5;z=1;a=2", Expect: "text/html;q=0.5;a=2;z=1"},
+	}
+
+	for i, tcase := range tcases {
+		t.Run(fmt.Sprintf("%d", i), func(t *testing.T) {
+			acc, err := ParseAcceptable(tcase.In)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if s := acc.String(); s != tcase.Expect {
+				t.Fatalf("expected %q, got %q", tcase.Expect, s)
+			}
+		})
+	}
+}
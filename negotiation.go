@@ -0,0 +1,69 @@
+// Copyright 2026 Franklin "Snaipe" Mathieu.
+//
+// Use of this source code is governed by the MIT license that can be
+// found in the LICENSE file.
+
+package htutil
+
+import (
+	"context"
+	"net/http"
+	"net/textproto"
+)
+
+// Negotiation describes the outcome of a content negotiation.
+type Negotiation struct {
+	// Field is the name of the request header field negotiated against,
+	// like Accept or Accept-Encoding.
+	Field string
+
+	// Offers are the offered values.
+	Offers []string
+
+	// Selected is the selected offer, or "" if none was acceptable.
+	Selected string
+
+	// Match is the acceptable value that Selected matched, if any.
+	Match *Acceptable
+}
+
+// NewNegotiation negotiates the field of hdr with NegotiateContent, and
+// returns the outcome.
+func NewNegotiation(hdr http.Header, field string, offers ...string) Negotiation {
+	selected, match := NegotiateContent(hdr, field, offers...)
+	return Negotiation{Field: field, Offers: offers, Selected: selected, Match: match}
+}
+
+// Vary adds the negotiated field to the Vary header field of h, the
+// response header. Handlers negotiating with NewNegotiation rather than
+// the Negotiate middleware should call it, so that caches do not serve
+// the response to requests with other preferences.
+func (n Negotiation) Vary(h http.Header) {
+	AddVary(h, n.Field)
+}
+
+type negotiationKey string
+
+// Negotiate returns a middleware that negotiates the request header field
+// against offers, and makes the outcome available to next through
+// NegotiationFromContext. Responses Vary on field.
+//
+// Requests for which no offer is acceptable are still served by next, with
+// an empty Selected value; it is up to next to reply with 406 Not
+// Acceptable, or to disregard the preferences of the client.
+func Negotiate(next http.Handler, field string, offers ...string) http.Handler {
+	field = textproto.CanonicalMIMEHeaderKey(field)
+	return http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+		n := NewNegotiation(req.Header, field, offers...)
+		n.Vary(w.Header())
+		ctx := context.WithValue(req.Context(), negotiationKey(field), n)
+		next.ServeHTTP(w, req.WithContext(ctx))
+	})
+}
+
+// NegotiationFromContext returns the outcome of the negotiation of field
+// by a Negotiate middleware, for the request that ctx belongs to.
+func NegotiationFromContext(ctx context.Context, field string) (Negotiation, bool) {
+	n, ok := ctx.Value(negotiationKey(textproto.CanonicalMIMEHeaderKey(field))).(Negotiation)
+	return n, ok
+}
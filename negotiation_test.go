@@ -0,0 +1,57 @@
+// Copyright 2026 Franklin "Snaipe" Mathieu.
+//
+// Use of this source code is governed by the MIT license that can be
+// found in the LICENSE file.
+
+package htutil
+
+import (
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestNegotiate(t *testing.T) {
+	t.Parallel()
+
+	tcases := []struct {
+		Accept   string
+		Selected string
+		Match    string
+	}{
+		{Accept: "", Selected: "application/json", Match: "*/*"},
+		{Accept: "text/*, application/json;q=0.5", Selected: "text/csv", Match: "text/*"},
+		{Accept: "image/png", Selected: ""},
+	}
+
+	for i, tcase := range tcases {
+		t.Run(fmt.Sprintf("%d", i), func(t *testing.T) {
+			var n Negotiation
+			var ok bool
+			handler := Negotiate(http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+				n, ok = NegotiationFromContext(req.Context(), "accept")
+			}), "Accept", "application/json", "text/csv")
+
+			req := httptest.NewRequest("GET", "/", nil)
+			if tcase.Accept != "" {
+				req.Header.Set("Accept", tcase.Accept)
+			}
+			rw := httptest.NewRecorder()
+			handler.ServeHTTP(rw, req)
+
+			if !ok {
+				t.Fatalf("expected negotiation in context")
+			}
+			if n.Selected != tcase.Selected {
+				t.Fatalf("expected %q, got %q", tcase.Selected, n.Selected)
+			}
+			if tcase.Match != "" && (n.Match == nil || n.Match.Value != tcase.Match) {
+				t.Fatalf("expected match %q, got %v", tcase.Match, n.Match)
+			}
+			if vary := rw.Header().Get("Vary"); vary != "Accept" {
+				t.Fatalf("expected Vary: Accept, got %q", vary)
+			}
+		})
+	}
+}
@@ -0,0 +1,174 @@
+// Copyright 2026 Franklin "Snaipe" Mathieu.
+//
+// Use of this source code is governed by the MIT license that can be
+// found in the LICENSE file.
+
+package htutil
+
+import (
+	"fmt"
+	"net/http"
+	"strings"
+)
+
+// RejectReason is the rule by which an offer was not selected by
+// NegotiateContentDetailed.
+type RejectReason int
+
+const (
+	// RejectUnmatched offers match no member of the field.
+	RejectUnmatched RejectReason = iota
+
+	// RejectRefused offers are explicitly refused by a member with a
+	// quality of 0.
+	RejectRefused
+
+	// RejectOutranked offers are acceptable, but another offer was
+	// preferred.
+	RejectOutranked
+)
+
+var rejectReasonNames = [...]string{
+	RejectUnmatched: "unmatched",
+	RejectRefused:   "refused",
+	RejectOutranked: "outranked",
+}
+
+func (r RejectReason) String() string {
+	if r < 0 || int(r) >= len(rejectReasonNames) {
+		return fmt.Sprintf("RejectReason(%d)", int(r))
+	}
+	return rejectReasonNames[r]
+}
+
+// Rejection is an offer that was not selected by NegotiateContentDetailed.
+type Rejection struct {
+	Offer  string
+	Reason RejectReason
+
+	// Match is the member of the field that determined the quality of the
+	// offer, if any: the one refusing it, or the one accepting it for
+	// outranked offers. Implicitly accepted offers, like identity for
+	// Accept-Encoding, have no match.
+	Match *Acceptable
+}
+
+// NegotiationResult is the outcome of NegotiateContentDetailed.
+type NegotiationResult struct {
+	// Field is the negotiated header field.
+	Field string
+
+	// Selected is the selected offer, or "" if no offer is acceptable.
+	Selected string
+
+	// Match is the member of the field that the selected offer matched.
+	Match *Acceptable
+
+	// Preferences are the members of the field, by order of precedence,
+	// as returned by ParseAccept.
+	Preferences []Acceptable
+
+	// Rejected are the offers that were not selected, in order.
+	Rejected []Rejection
+}
+
+// NegotiateContentDetailed negotiates offers like NegotiateContent, and
+// explains the outcome: besides the selected offer, the result lists the
+// preferences of the client, and why each of the other offers was
+// rejected.
+func NegotiateContentDetailed(hdr http.Header, key string, offers ...string) NegotiationResult {
+	res := NegotiationResult{Field: http.CanonicalHeaderKey(key)}
+	res.Selected, res.Match = NegotiateContent(hdr, key, offers...)
+	res.Preferences = ParseAccept(hdr.Values(key)...)
+
+	language := res.Field == "Accept-Language"
+	for _, offer := range offers {
+		if offer == res.Selected && res.Selected != "" {
+			continue
+		}
+		var rej Rejection
+		if language {
+			rej = languageRejection(res.Preferences, offer)
+		} else {
+			rej = contentRejection(res.Field, res.Preferences, offer)
+		}
+		res.Rejected = append(res.Rejected, rej)
+	}
+	return res
+}
+
+// contentRejection returns why offer was rejected by the members accepts
+// of field, as matched by NegotiateContent.
+func contentRejection(field string, accepts []Acceptable, offer string) Rejection {
+	rej := Rejection{Offer: offer}
+	var refusal *Acceptable
+	for i := range accepts {
+		acc := &accepts[i]
+		if !dumbglob(acc.Value, offer) {
+			continue
+		}
+		if acc.Quality != 0 {
+			rej.Reason, rej.Match = RejectOutranked, acc
+			return rej
+		}
+		if refusal == nil {
+			refusal = acc
+		}
+	}
+	switch {
+	case refusal != nil:
+		rej.Reason, rej.Match = RejectRefused, refusal
+	case len(accepts) == 0 || (field == "Accept-Encoding" && offer == "identity"):
+		// Everything is acceptable without the field, and identity is
+		// implicitly acceptable unless refused.
+		rej.Reason = RejectOutranked
+	}
+	return rej
+}
+
+// languageRejection returns why offer was rejected by the language ranges
+// of Accept-Language, as matched by NegotiateLanguage.
+func languageRejection(ranges []Acceptable, offer string) Rejection {
+	rej := Rejection{Offer: offer}
+	if len(ranges) == 0 {
+		rej.Reason = RejectOutranked
+		return rej
+	}
+	match, _ := languageOfferMatch(ranges, offer)
+	switch {
+	case match == nil:
+	case match.Quality == 0:
+		rej.Reason, rej.Match = RejectRefused, match
+	default:
+		rej.Reason, rej.Match = RejectOutranked, match
+	}
+	return rej
+}
+
+// WriteNotAcceptable responds to req with 406 Not Acceptable, listing the
+// available representations offers, as recommended by RFC 9110 §15.5.7.
+//
+// The response is negotiated as text/plain, listing one offer per line, or
+// as problem details written by WriteProblem, listing them in an
+// "available" extension member; text/plain is sent if neither is
+// acceptable.
+func WriteNotAcceptable(w http.ResponseWriter, req *http.Request, offers ...string) {
+	p := NewProblem(http.StatusNotAcceptable, "Available representations: "+strings.Join(offers, ", ")+".")
+	p.Extensions = map[string]interface{}{"available": append([]string{}, offers...)}
+
+	ctype, _ := NegotiateContent(req.Header, "Accept", "text/plain",
+		"application/problem+json", "application/json", "application/problem+xml", "application/xml")
+	if ctype != "" && ctype != "text/plain" {
+		WriteProblem(w, req, p)
+		return
+	}
+
+	AddVary(w.Header(), "Accept")
+	w.Header().Set("X-Content-Type-Options", "nosniff")
+	w.Header().Set("Content-Type", "text/plain; charset=utf-8")
+	w.WriteHeader(p.Status)
+	fmt.Fprintln(w, "Available representations:")
+	for _, offer := range offers {
+		fmt.Fprintln(w, "- "+offer)
+	}
+}
@@ -0,0 +1,177 @@
+// Copyright 2026 Franklin "Snaipe" Mathieu.
+//
+// Use of this source code is governed by the MIT license that can be
+// found in the LICENSE file.
+
+package htutil
+
+import (
+	"encoding/json"
+	"encoding/xml"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+func TestNegotiateContentDetailed(t *testing.T) {
+	t.Parallel()
+
+	type rejection struct {
+		Offer  string
+		Reason RejectReason
+		Match  string
+	}
+
+	tcases := []struct {
+		Field       string
+		Value       string
+		Offers      []string
+		Selected    string
+		Preferences int
+		Rejected    []rejection
+	}{
+		{
+			Field:       "Accept",
+			Value:       "text/html, application/json;q=0.5, image/*;q=0",
+			Offers:      []string{"application/json", "text/html", "image/png", "text/csv"},
+			Selected:    "text/html",
+			Preferences: 3,
+			Rejected: []rejection{
+				{Offer: "application/json", Reason: RejectOutranked, Match: "application/json"},
+				{Offer: "image/png", Reason: RejectRefused, Match: "image/*"},
+				{Offer: "text/csv", Reason: RejectUnmatched},
+			},
+		},
+		{
+			Field:       "Accept",
+			Value:       "image/*;q=0, */*;q=0.1",
+			Offers:      []string{"image/png", "text/plain"},
+			Selected:    "image/png",
+			Preferences: 2,
+			Rejected:    []rejection{{Offer: "text/plain", Reason: RejectOutranked, Match: "*/*"}},
+		},
+		{
+			Field:    "Accept",
+			Offers:   []string{"text/html", "application/json"},
+			Selected: "text/html",
+			Rejected: []rejection{{Offer: "application/json", Reason: RejectOutranked}},
+		},
+		{
+			Field:       "Accept-Encoding",
+			Value:       "gzip, *;q=0",
+			Offers:      []string{"br", "identity"},
+			Preferences: 2,
+			Rejected: []rejection{
+				{Offer: "br", Reason: RejectRefused, Match: "*"},
+				{Offer: "identity", Reason: RejectRefused, Match: "*"},
+			},
+		},
+		{
+			Field:       "Accept-Encoding",
+			Value:       "gzip",
+			Offers:      []string{"identity", "gzip"},
+			Selected:    "gzip",
+			Preferences: 1,
+			Rejected:    []rejection{{Offer: "identity", Reason: RejectOutranked}},
+		},
+		{
+			Field:       "Accept-Language",
+			Value:       "fr-CH, fr;q=0, en;q=0.5",
+			Offers:      []string{"fr-FR", "en-US", "fr-CH", "de"},
+			Selected:    "fr-CH",
+			Preferences: 3,
+			Rejected: []rejection{
+				{Offer: "fr-FR", Reason: RejectRefused, Match: "fr"},
+				{Offer: "en-US", Reason: RejectOutranked, Match: "en"},
+				{Offer: "de", Reason: RejectUnmatched},
+			},
+		},
+	}
+
+	for i, tcase := range tcases {
+		t.Run(fmt.Sprintf("%d", i), func(t *testing.T) {
+			hdr := http.Header{}
+			if tcase.Value != "" {
+				hdr.Set(tcase.Field, tcase.Value)
+			}
+			res := NegotiateContentDetailed(hdr, tcase.Field, tcase.Offers...)
+			if res.Selected != tcase.Selected {
+				t.Fatalf("expected %q to be selected, got %q", tcase.Selected, res.Selected)
+			}
+			if selected, _ := NegotiateContent(hdr, tcase.Field, tcase.Offers...); selected != res.Selected {
+				t.Fatalf("expected the same outcome as NegotiateContent (%q), got %q", selected, res.Selected)
+			}
+			if len(res.Preferences) != tcase.Preferences {
+				t.Fatalf("expected %d preferences, got %v", tcase.Preferences, res.Preferences)
+			}
+			if len(res.Rejected) != len(tcase.Rejected) {
+				t.Fatalf("expected %d rejections, got %v", len(tcase.Rejected), res.Rejected)
+			}
+			for j, expected := range tcase.Rejected {
+				actual := res.Rejected[j]
+				match := ""
+				if actual.Match != nil {
+					match = actual.Match.Value
+				}
+				if actual.Offer != expected.Offer || actual.Reason != expected.Reason || match != expected.Match {
+					t.Fatalf("expected rejection %v, got {%v %v %v}", expected, actual.Offer, actual.Reason, match)
+				}
+			}
+		})
+	}
+}
+
+func TestWriteNotAcceptable(t *testing.T) {
+	t.Parallel()
+
+	tcases := []struct {
+		Accept      string
+		ContentType string
+		Body        string
+	}{
+		{Accept: "", ContentType: "text/plain; charset=utf-8", Body: "Available representations:\n- text/html\n- text/csv\n"},
+		{Accept: "image/png", ContentType: "text/plain; charset=utf-8", Body: "Available representations:\n- text/html\n- text/csv\n"},
+		{Accept: "application/json", ContentType: "application/problem+json"},
+		{Accept: "application/problem+json, text/plain;q=0.5", ContentType: "application/problem+json"},
+		{Accept: "application/xml", ContentType: "application/problem+xml"},
+	}
+
+	for i, tcase := range tcases {
+		t.Run(fmt.Sprintf("%d", i), func(t *testing.T) {
+			req := httptest.NewRequest("GET", "/", nil)
+			if tcase.Accept != "" {
+				req.Header.Set("Accept", tcase.Accept)
+			}
+			rw := httptest.NewRecorder()
+			WriteNotAcceptable(rw, req, "text/html", "text/csv")
+
+			if rw.Code != http.StatusNotAcceptable {
+				t.Fatalf("expected status 406, got %d", rw.Code)
+			}
+			if ctype := rw.Header().Get("Content-Type"); ctype != tcase.ContentType {
+				t.Fatalf("expected %q, got %q", tcase.ContentType, ctype)
+			}
+			if tcase.Body != "" {
+				if rw.Body.String() != tcase.Body {
+					t.Fatalf("expected body %q, got %q", tcase.Body, rw.Body.String())
+				}
+				return
+			}
+			var p Problem
+			var err error
+			if strings.HasSuffix(tcase.ContentType, "+xml") {
+				err = xml.Unmarshal(rw.Body.Bytes(), &p)
+			} else {
+				err = json.Unmarshal(rw.Body.Bytes(), &p)
+			}
+			if err != nil {
+				t.Fatal(err)
+			}
+			if p.Status != http.StatusNotAcceptable || fmt.Sprint(p.Extensions["available"]) != "[text/html text/csv]" {
+				t.Fatalf("expected a 406 problem listing the offers, got %+v", p)
+			}
+		})
+	}
+}
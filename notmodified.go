@@ -0,0 +1,58 @@
+// Copyright 2026 Franklin "Snaipe" Mathieu.
+//
+// Use of this source code is governed by the MIT license that can be
+// found in the LICENSE file.
+
+package htutil
+
+import (
+	"net/http"
+	"net/textproto"
+)
+
+// notModifiedExcluded are the header fields that are left out of 304
+// responses. They describe the content that a 304 response does not have.
+var notModifiedExcluded = map[string]struct{}{
+	"Accept-Ranges":       {},
+	"Content-Digest":      {},
+	"Content-Disposition": {},
+	"Content-Encoding":    {},
+	"Content-Language":    {},
+	"Content-Length":      {},
+	"Content-Md5":         {},
+	"Content-Range":       {},
+	"Content-Type":        {},
+	"Digest":              {},
+	"Repr-Digest":         {},
+	"Trailer":             {},
+	"Transfer-Encoding":   {},
+}
+
+// NotModified writes a 304 Not Modified response standing for a 200
+// response with the header fields h, as per RFC 9110 §15.4.5.
+//
+// The fields that would have been sent in the 200 response are copied,
+// which includes the ones that a 304 response must carry (Cache-Control,
+// Content-Location, Date, ETag, Expires, and Vary) when present, except for
+// the fields describing the content, like Content-Type and Content-Length,
+// as they could be mistaken for the metadata of an empty representation.
+// Other Content-* fields, like Content-Security-Policy, are kept.
+func NotModified(w http.ResponseWriter, h http.Header) {
+	dst := w.Header()
+	for k := range dst {
+		if excludedFromNotModified(k) {
+			delete(dst, k)
+		}
+	}
+	for k, v := range h {
+		if !excludedFromNotModified(k) {
+			dst[textproto.CanonicalMIMEHeaderKey(k)] = append([]string(nil), v...)
+		}
+	}
+	w.WriteHeader(http.StatusNotModified)
+}
+
+func excludedFromNotModified(name string) bool {
+	_, ok := notModifiedExcluded[textproto.CanonicalMIMEHeaderKey(name)]
+	return ok
+}
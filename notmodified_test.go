@@ -0,0 +1,81 @@
+// Copyright 2026 Franklin "Snaipe" Mathieu.
+//
+// Use of this source code is governed by the MIT license that can be
+// found in the LICENSE file.
+
+package htutil
+
+import (
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestNotModified(t *testing.T) {
+	t.Parallel()
+
+	tcases := []struct {
+		Preset   http.Header
+		Original http.Header
+		Expected http.Header
+	}{
+		{
+			Original: http.Header{
+				"Cache-Control":    {"max-age=60"},
+				"Content-Encoding": {"gzip"},
+				"Content-Length":   {"42"},
+				"Content-Location": {"/doc.en"},
+				"Content-Type":     {"text/html"},
+				"Date":             {"Fri, 02 Jan 2026 03:04:05 GMT"},
+				"Etag":             {`"v1"`},
+				"Expires":          {"Fri, 02 Jan 2026 03:05:05 GMT"},
+				"Last-Modified":    {"Thu, 01 Jan 2026 00:00:00 GMT"},
+				"Vary":             {"Accept-Encoding", "Accept-Language"},
+				"Set-Cookie":       {"a=b"},
+				"Accept-Ranges":    {"bytes"},
+				"Trailer":          {"Server-Timing"},
+			},
+			Expected: http.Header{
+				"Cache-Control":    {"max-age=60"},
+				"Content-Location": {"/doc.en"},
+				"Date":             {"Fri, 02 Jan 2026 03:04:05 GMT"},
+				"Etag":             {`"v1"`},
+				"Expires":          {"Fri, 02 Jan 2026 03:05:05 GMT"},
+				"Last-Modified":    {"Thu, 01 Jan 2026 00:00:00 GMT"},
+				"Vary":             {"Accept-Encoding", "Accept-Language"},
+				"Set-Cookie":       {"a=b"},
+			},
+		},
+		{
+			Preset:   http.Header{"Content-Type": {"text/plain"}, "X-Request-Id": {"1"}},
+			Original: http.Header{"etag": {`"v1"`}},
+			Expected: http.Header{"Etag": {`"v1"`}, "X-Request-Id": {"1"}},
+		},
+		{
+			Original: http.Header{
+				"Content-Security-Policy": {"default-src 'self'"},
+				"Content-Disposition":     {"attachment"},
+				"Content-Digest":          {"sha-256=:AAAA:"},
+			},
+			Expected: http.Header{"Content-Security-Policy": {"default-src 'self'"}},
+		},
+	}
+
+	for i, tcase := range tcases {
+		t.Run(fmt.Sprintf("%d", i), func(t *testing.T) {
+			w := httptest.NewRecorder()
+			for k, v := range tcase.Preset {
+				w.Header()[k] = v
+			}
+			NotModified(w, tcase.Original)
+
+			if w.Code != http.StatusNotModified {
+				t.Fatalf("expected status 304, got %d", w.Code)
+			}
+			if diff := DiffHeaders(tcase.Expected, w.Header()); len(diff) > 0 {
+				t.Fatalf("unexpected header fields:\n%v", diff)
+			}
+		})
+	}
+}
@@ -0,0 +1,144 @@
+// Copyright 2026 Franklin "Snaipe" Mathieu.
+//
+// Use of this source code is governed by the MIT license that can be
+// found in the LICENSE file.
+
+package htutil
+
+import (
+	"net/http"
+	"net/textproto"
+	"strings"
+)
+
+// Offer is a value offered for a response, with the preference of the
+// server for it, like the qs-values of Apache httpd type maps.
+type Offer struct {
+	// Value is the offered value, like a media type, a content coding, or
+	// a language tag.
+	Value string
+
+	// Quality is the preference of the server for the offer, between 0
+	// and 1. Zero defaults to 1; offers that the server does not want to
+	// serve should not be offered at all.
+	Quality float32
+
+	// Params are the parameters of the offer, like level for text/html.
+	// Acceptable values with parameters only match offers that have the
+	// same parameters.
+	Params map[string]string
+}
+
+func (o Offer) quality() float32 {
+	if o.Quality == 0 {
+		return 1
+	}
+	return o.Quality
+}
+
+// implicitIdentityQuality is the quality of the identity coding when the
+// Accept-Encoding field does not mention it: acceptable, but only as a
+// last resort.
+const implicitIdentityQuality = 0.001
+
+// NegotiateOffers returns the offer best matching the header field key of
+// hdr, as well as the acceptable value that it matched against.
+//
+// Each offer is scored by multiplying the quality of the most specific
+// acceptable value it matches, as per RFC 9110 §12.5.1, with the quality of
+// the offer, like Apache httpd does. A server preferring JSON over XML can
+// then offer application/json with a quality of 1 and application/xml
+// with a quality of 0.8, and still serve XML to clients that accept JSON
+// with a quality below 0.8. Ties are broken by the order of offers.
+//
+// As for NegotiateContent, Accept-Language ranges match language tags as
+// per the basic filtering scheme of RFC 4647, and the identity coding is
+// implicitly acceptable with the lowest quality, unless refused.
+//
+// If no offer is acceptable, a zero Offer and a nil Acceptable are returned.
+func NegotiateOffers(hdr http.Header, key string, offers ...Offer) (Offer, *Acceptable) {
+	key = textproto.CanonicalMIMEHeaderKey(key)
+	values := hdr.Values(key)
+	if len(values) == 0 {
+		switch key {
+		case "Accept":
+			values = []string{"*/*"}
+		default:
+			values = []string{"*"}
+		}
+	}
+	buf := AcquireAcceptBuffer()
+	defer buf.Release()
+	accepts := buf.Parse(values...)
+
+	var (
+		best      Offer
+		bestMatch *Acceptable
+		bestScore float32
+	)
+	for _, offer := range offers {
+		match := mostSpecificMatch(key, accepts, offer)
+		var q float32
+		switch {
+		case match != nil:
+			q = match.Quality
+		case key == "Accept-Encoding" && strings.EqualFold(offer.Value, "identity"):
+			q = implicitIdentityQuality
+		}
+		score := q * offer.quality()
+		// Products of qualities have up to 6 precision digits.
+		if score > bestScore+1e-6 {
+			best, bestMatch, bestScore = offer, match, score
+		}
+	}
+	if bestMatch != nil {
+		// The match points into buf, which is released on return.
+		match := *bestMatch
+		bestMatch = &match
+	}
+	return best, bestMatch
+}
+
+// mostSpecificMatch returns the most specific of the acceptable values
+// matching offer, or nil if there is none.
+func mostSpecificMatch(key string, accepts []Acceptable, offer Offer) *Acceptable {
+	var match *Acceptable
+	specificity := -1
+	for i := range accepts {
+		acc := &accepts[i]
+		var s int
+		switch key {
+		case "Accept-Language":
+			if !languageRangeMatches(acc.Value, offer.Value) {
+				continue
+			}
+			if acc.Value != "*" {
+				s = 1 + len(acc.Value)
+			}
+		default:
+			if !dumbglob(acc.Value, strings.ToLower(offer.Value)) {
+				continue
+			}
+			s = 4 - 2*strings.Count(acc.Value, "*")
+		}
+		if !paramsMatch(acc.Params, offer.Params) {
+			continue
+		}
+		s = s*16 + len(acc.Params)
+		if s > specificity {
+			match, specificity = acc, s
+		}
+	}
+	return match
+}
+
+// paramsMatch returns whether all the parameters of an acceptable value
+// are those of an offer.
+func paramsMatch(accepted, offered map[string]string) bool {
+	for k, v := range accepted {
+		if ov, ok := offered[k]; !ok || !strings.EqualFold(ov, v) {
+			return false
+		}
+	}
+	return true
+}
@@ -0,0 +1,75 @@
+// Copyright 2026 Franklin "Snaipe" Mathieu.
+//
+// Use of this source code is governed by the MIT license that can be
+// found in the LICENSE file.
+
+package htutil
+
+import (
+	"fmt"
+	"net/http"
+	"testing"
+)
+
+func TestNegotiateOffers(t *testing.T) {
+	t.Parallel()
+
+	jsonXML := []Offer{
+		{Value: "application/json", Quality: 1},
+		{Value: "application/xml", Quality: 0.8},
+	}
+	html := []Offer{
+		{Value: "text/html", Quality: 0.5, Params: map[string]string{"level": "1"}},
+		{Value: "text/html", Quality: 0.7},
+		{Value: "text/plain", Quality: 0.3},
+	}
+
+	tcases := []struct {
+		Key    string
+		Accept string
+		Offers []Offer
+		Expect string
+		Match  string
+	}{
+		{Key: "Accept", Accept: "<none>", Offers: jsonXML, Expect: "application/json", Match: "*/*"},
+		{Key: "Accept", Accept: "application/xml, application/json", Offers: jsonXML, Expect: "application/json", Match: "application/json"},
+		{Key: "Accept", Accept: "application/xml, application/json;q=0.9", Offers: jsonXML, Expect: "application/json", Match: "application/json"},
+		{Key: "Accept", Accept: "application/xml, application/json;q=0.7", Offers: jsonXML, Expect: "application/xml", Match: "application/xml"},
+		{Key: "Accept", Accept: "application/*, application/json;q=0", Offers: jsonXML, Expect: "application/xml", Match: "application/*"},
+		{Key: "Accept", Accept: "text/*", Offers: jsonXML, Expect: ""},
+		{Key: "Accept", Accept: "text/html;level=1, text/*;q=0.5", Offers: html, Expect: "text/html", Match: "text/html"},
+		{Key: "Accept", Accept: "text/html;level=1;q=0.2, text/html;q=0.1, */*;q=0.9", Offers: html, Expect: "text/plain", Match: "*/*"},
+		{Key: "Accept", Accept: "text/*;q=0.3, text/html;q=0.7, text/html;level=1", Offers: html, Expect: "text/html", Match: "text/html"},
+		{Key: "Accept", Accept: "text/html;q=0.9;level=1, text/*;q=0.1", Offers: html, Expect: "text/html", Match: "text/html"},
+		{Key: "Accept", Accept: "*/*", Offers: []Offer{{Value: "text/plain"}, {Value: "text/html", Quality: 0.9}}, Expect: "text/plain", Match: "*/*"},
+		{Key: "Accept", Accept: "*/*", Offers: []Offer{{Value: "text/plain", Quality: 0.9}, {Value: "text/html", Quality: 0.9}}, Expect: "text/plain", Match: "*/*"},
+		{Key: "Accept-Encoding", Accept: "gzip;q=0.5", Offers: []Offer{{Value: "identity"}, {Value: "gzip", Quality: 0.8}}, Expect: "gzip", Match: "gzip"},
+		{Key: "Accept-Encoding", Accept: "br", Offers: []Offer{{Value: "gzip"}, {Value: "identity"}}, Expect: "identity"},
+		{Key: "Accept-Encoding", Accept: "br, identity;q=0", Offers: []Offer{{Value: "gzip"}, {Value: "identity"}}, Expect: ""},
+		{Key: "Accept-Language", Accept: "en, fr;q=0.8", Offers: []Offer{{Value: "en-US", Quality: 0.5}, {Value: "fr-FR"}}, Expect: "fr-FR", Match: "fr"},
+		{Key: "Accept-Language", Accept: "en, en-GB;q=0.2", Offers: []Offer{{Value: "en-GB"}, {Value: "en-US", Quality: 0.5}}, Expect: "en-US", Match: "en"},
+		{Key: "Accept", Accept: "text/html", Offers: nil, Expect: ""},
+	}
+
+	for i, tcase := range tcases {
+		t.Run(fmt.Sprintf("%d", i), func(t *testing.T) {
+			hdr := http.Header{}
+			if tcase.Accept != "<none>" {
+				hdr.Set(tcase.Key, tcase.Accept)
+			}
+			offer, match := NegotiateOffers(hdr, tcase.Key, tcase.Offers...)
+			if offer.Value != tcase.Expect {
+				t.Fatalf("expected %q, got %q", tcase.Expect, offer.Value)
+			}
+			if tcase.Match == "" {
+				if match != nil {
+					t.Fatalf("expected no match, got %v", match)
+				}
+				return
+			}
+			if match == nil || match.Value != tcase.Match {
+				t.Fatalf("expected match %q, got %v", tcase.Match, match)
+			}
+		})
+	}
+}
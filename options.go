@@ -0,0 +1,69 @@
+// Copyright 2026 Franklin "Snaipe" Mathieu.
+//
+// Use of this source code is governed by the MIT license that can be
+// found in the LICENSE file.
+
+package htutil
+
+import (
+	"io"
+	"net/http"
+	"strings"
+)
+
+// ServerCapabilities describes the server-wide capabilities advertised in
+// responses to "OPTIONS *" requests (RFC 9110 §9.3.7).
+type ServerCapabilities struct {
+	// Allow lists the methods supported by the server, regardless of the
+	// target resource.
+	Allow []string
+
+	// Header holds additional header fields to advertise, like Accept-Ranges,
+	// Accept-Patch, Alt-Svc, or server limits.
+	Header http.Header
+}
+
+// IsOptionsAsterisk returns whether req is an "OPTIONS *" request, which
+// targets the server itself rather than any resource.
+func IsOptionsAsterisk(req *http.Request) bool {
+	return req.Method == http.MethodOptions && req.RequestURI == "*"
+}
+
+// OptionsAsterisk returns a middleware serving "OPTIONS *" requests with
+// caps, and passing all other requests to next.
+//
+// Servers from net/http answer "OPTIONS *" requests on their own unless
+// http.Server.DisableGeneralOptionsHandler is set; see ServeOptionsAsterisk.
+func OptionsAsterisk(next http.Handler, caps ServerCapabilities) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+		if !IsOptionsAsterisk(req) {
+			next.ServeHTTP(w, req)
+			return
+		}
+
+		for k, v := range caps.Header {
+			w.Header()[k] = append([]string(nil), v...)
+		}
+		if len(caps.Allow) > 0 {
+			w.Header().Set("Allow", strings.Join(caps.Allow, ", "))
+		}
+		w.Header().Set("Content-Length", "0")
+		if req.ContentLength != 0 {
+			// The content of OPTIONS requests has no defined semantics;
+			// consume a reasonable amount of it and give up on the rest.
+			io.Copy(io.Discard, http.MaxBytesReader(w, req.Body, 4<<10))
+		}
+		w.WriteHeader(http.StatusOK)
+	})
+}
+
+// ServeOptionsAsterisk configures srv to serve "OPTIONS *" requests with
+// caps, instead of the minimal response of net/http.
+func ServeOptionsAsterisk(srv *http.Server, caps ServerCapabilities) {
+	handler := srv.Handler
+	if handler == nil {
+		handler = http.DefaultServeMux
+	}
+	srv.DisableGeneralOptionsHandler = true
+	srv.Handler = OptionsAsterisk(handler, caps)
+}
@@ -0,0 +1,67 @@
+// Copyright 2026 Franklin "Snaipe" Mathieu.
+//
+// Use of this source code is governed by the MIT license that can be
+// found in the LICENSE file.
+
+package htutil
+
+import (
+	"bufio"
+	"fmt"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+)
+
+func TestOptionsAsterisk(t *testing.T) {
+	t.Parallel()
+
+	srv := httptest.NewUnstartedServer(http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+		w.Header().Set("Allow", "GET")
+		w.WriteHeader(http.StatusNoContent)
+	}))
+	ServeOptionsAsterisk(srv.Config, ServerCapabilities{
+		Allow:  []string{"GET", "HEAD", "POST"},
+		Header: http.Header{"Accept-Ranges": {"bytes"}},
+	})
+	srv.Start()
+	defer srv.Close()
+
+	tcases := []struct {
+		Target       string
+		Status       int
+		Allow        string
+		AcceptRanges string
+	}{
+		{Target: "*", Status: 200, Allow: "GET, HEAD, POST", AcceptRanges: "bytes"},
+		{Target: "/", Status: 204, Allow: "GET"},
+	}
+
+	for i, tcase := range tcases {
+		t.Run(fmt.Sprintf("%d", i), func(t *testing.T) {
+			conn, err := net.Dial("tcp", srv.Listener.Addr().String())
+			if err != nil {
+				t.Fatal(err)
+			}
+			defer conn.Close()
+			conn.SetDeadline(time.Now().Add(5 * time.Second))
+
+			fmt.Fprintf(conn, "OPTIONS %s HTTP/1.1\r\nHost: test\r\n\r\n", tcase.Target)
+			resp, err := http.ReadResponse(bufio.NewReader(conn), nil)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if resp.StatusCode != tcase.Status {
+				t.Fatalf("expected status %d, got %d", tcase.Status, resp.StatusCode)
+			}
+			if allow := resp.Header.Get("Allow"); allow != tcase.Allow {
+				t.Fatalf("expected Allow %q, got %q", tcase.Allow, allow)
+			}
+			if ar := resp.Header.Get("Accept-Ranges"); ar != tcase.AcceptRanges {
+				t.Fatalf("expected Accept-Ranges %q, got %q", tcase.AcceptRanges, ar)
+			}
+		})
+	}
+}
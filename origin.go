@@ -0,0 +1,172 @@
+// Copyright 2026 Franklin "Snaipe" Mathieu.
+//
+// Use of this source code is governed by the MIT license that can be
+// found in the LICENSE file.
+
+package htutil
+
+import (
+	"fmt"
+	"net"
+	"net/http"
+	"net/url"
+	"strings"
+)
+
+var defaultPorts = map[string]string{
+	"http":  "80",
+	"https": "443",
+	"ws":    "80",
+	"wss":   "443",
+}
+
+// Origin is a web origin, as per RFC 6454: a (scheme, host, port) tuple.
+// The zero value is the opaque "null" origin.
+type Origin struct {
+	// Scheme is the lowercased scheme of the origin.
+	Scheme string
+
+	// Host is the lowercased host name or IP address of the origin,
+	// without brackets for IPv6 addresses.
+	Host string
+
+	// Port is the port of the origin. It is always set for schemes with a
+	// known default port.
+	Port string
+}
+
+// ParseOrigin parses the value of an Origin header field. The value "null"
+// parses as the null origin.
+func ParseOrigin(value string) (Origin, error) {
+	if value == "null" {
+		return Origin{}, nil
+	}
+	u, err := url.Parse(value)
+	if err != nil {
+		return Origin{}, fmt.Errorf("parsing origin: %w", err)
+	}
+	if u.Scheme == "" || u.Host == "" {
+		return Origin{}, fmt.Errorf("parsing origin: %s is not an absolute URL", value)
+	}
+	if u.User != nil || (u.Path != "" && u.Path != "/") || u.RawQuery != "" || u.Fragment != "" || u.Opaque != "" {
+		return Origin{}, fmt.Errorf("parsing origin: %s has more than a scheme, host, and port", value)
+	}
+	return OriginOf(u), nil
+}
+
+// OriginOf returns the origin of an absolute URL.
+func OriginOf(u *url.URL) Origin {
+	o := Origin{
+		Scheme: strings.ToLower(u.Scheme),
+		Host:   strings.ToLower(u.Hostname()),
+		Port:   u.Port(),
+	}
+	if o.Port == "" {
+		o.Port = defaultPorts[o.Scheme]
+	}
+	return o
+}
+
+// IsNull returns whether o is the null origin.
+func (o Origin) IsNull() bool {
+	return o.Scheme == ""
+}
+
+// Equal returns whether o and other are the same origin. The null origin
+// is never the same as any other origin, including itself.
+func (o Origin) Equal(other Origin) bool {
+	return !o.IsNull() && o == other
+}
+
+// String serializes o, omitting the port if it is the default one for the
+// scheme.
+func (o Origin) String() string {
+	if o.IsNull() {
+		return "null"
+	}
+	host := o.Host
+	if strings.IndexByte(host, ':') != -1 {
+		host = "[" + host + "]"
+	}
+	if o.Port != "" && o.Port != defaultPorts[o.Scheme] {
+		host = net.JoinHostPort(o.Host, o.Port)
+	}
+	return o.Scheme + "://" + host
+}
+
+// RequestOrigin returns the origin of the request, as indicated by its
+// Origin header field. ok is false if the request has no Origin header
+// field, or if it cannot be parsed.
+func RequestOrigin(req *http.Request) (o Origin, ok bool) {
+	value := req.Header.Get("Origin")
+	if value == "" {
+		return Origin{}, false
+	}
+	o, err := ParseOrigin(value)
+	return o, err == nil
+}
+
+// OriginPolicy decides which origins are allowed to access a resource.
+// It is shared by the cross-origin features of the package.
+type OriginPolicy struct {
+	// Origins is a list of allowed origins, of the following forms:
+	//
+	//  - an exact origin, like "https://example.com";
+	//  - a wildcard subdomain pattern, like "https://*.example.com", which
+	//    matches any subdomain of example.com, but not example.com itself;
+	//  - "*", which matches any origin but the null origin.
+	Origins []string
+
+	// AllowNull allows the null origin, sent for instance by sandboxed
+	// documents or file: URLs. Allowing it is rarely a good idea.
+	AllowNull bool
+
+	// Func, if set, is called for any origin that is not allowed by the
+	// other settings, and allows the origin if it returns true.
+	Func func(o Origin) bool
+}
+
+// Allows returns whether the policy allows the origin o.
+func (p *OriginPolicy) Allows(o Origin) bool {
+	if o.IsNull() {
+		return p.AllowNull
+	}
+	for _, pattern := range p.Origins {
+		if matchOrigin(pattern, o) {
+			return true
+		}
+	}
+	if p.Func != nil {
+		return p.Func(o)
+	}
+	return false
+}
+
+// AllowsAny returns whether the policy allows all non-null origins.
+func (p *OriginPolicy) AllowsAny() bool {
+	for _, pattern := range p.Origins {
+		if pattern == "*" {
+			return true
+		}
+	}
+	return false
+}
+
+func matchOrigin(pattern string, o Origin) bool {
+	if pattern == "*" {
+		return true
+	}
+	wildcard := false
+	if i := strings.Index(pattern, "://*."); i != -1 {
+		wildcard = true
+		pattern = pattern[:i+3] + pattern[i+5:]
+	}
+	po, err := ParseOrigin(pattern)
+	if err != nil || po.IsNull() {
+		return false
+	}
+	if !wildcard {
+		return po.Equal(o)
+	}
+	return po.Scheme == o.Scheme && po.Port == o.Port && strings.HasSuffix(o.Host, "."+po.Host)
+}
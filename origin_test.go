@@ -0,0 +1,96 @@
+// Copyright 2026 Franklin "Snaipe" Mathieu.
+//
+// Use of this source code is governed by the MIT license that can be
+// found in the LICENSE file.
+
+package htutil
+
+import (
+	"fmt"
+	"testing"
+)
+
+func TestParseOrigin(t *testing.T) {
+	t.Parallel()
+
+	tcases := []struct {
+		In     string
+		Out    Origin
+		String string
+		Err    bool
+	}{
+		{In: "https://example.com", Out: Origin{"https", "example.com", "443"}, String: "https://example.com"},
+		{In: "HTTP://Example.COM:80", Out: Origin{"http", "example.com", "80"}, String: "http://example.com"},
+		{In: "http://localhost:8080", Out: Origin{"http", "localhost", "8080"}, String: "http://localhost:8080"},
+		{In: "https://[::1]:8443", Out: Origin{"https", "::1", "8443"}, String: "https://[::1]:8443"},
+		{In: "https://[::1]", Out: Origin{"https", "::1", "443"}, String: "https://[::1]"},
+		{In: "null", Out: Origin{}, String: "null"},
+		{In: "https://example.com/path", Err: true},
+		{In: "https://user@example.com", Err: true},
+		{In: "example.com", Err: true},
+	}
+
+	for i, tcase := range tcases {
+		t.Run(fmt.Sprintf("%d", i), func(t *testing.T) {
+			actual, err := ParseOrigin(tcase.In)
+			if tcase.Err {
+				if err == nil {
+					t.Fatalf("expected error, got %v", actual)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatal(err)
+			}
+			if actual != tcase.Out {
+				t.Fatalf("expected %#v, got %#v", tcase.Out, actual)
+			}
+			if actual.String() != tcase.String {
+				t.Fatalf("expected %s, got %s", tcase.String, actual.String())
+			}
+		})
+	}
+}
+
+func TestOriginPolicy(t *testing.T) {
+	t.Parallel()
+
+	policy := OriginPolicy{
+		Origins: []string{
+			"https://example.com",
+			"https://*.example.org",
+			"http://localhost:3000",
+		},
+		Func: func(o Origin) bool { return o.Host == "trusted.test" },
+	}
+
+	tcases := []struct {
+		Origin   string
+		Expected bool
+	}{
+		{Origin: "https://example.com", Expected: true},
+		{Origin: "https://example.com:443", Expected: true},
+		{Origin: "http://example.com", Expected: false},
+		{Origin: "https://sub.example.com", Expected: false},
+		{Origin: "https://api.example.org", Expected: true},
+		{Origin: "https://a.b.example.org", Expected: true},
+		{Origin: "https://example.org", Expected: false},
+		{Origin: "https://evilexample.org", Expected: false},
+		{Origin: "http://localhost:3000", Expected: true},
+		{Origin: "http://localhost:3001", Expected: false},
+		{Origin: "https://trusted.test", Expected: true},
+		{Origin: "null", Expected: false},
+	}
+
+	for i, tcase := range tcases {
+		t.Run(fmt.Sprintf("%d", i), func(t *testing.T) {
+			o, err := ParseOrigin(tcase.Origin)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if actual := policy.Allows(o); actual != tcase.Expected {
+				t.Fatalf("expected %v, got %v", tcase.Expected, actual)
+			}
+		})
+	}
+}
@@ -0,0 +1,91 @@
+// Copyright 2026 Franklin "Snaipe" Mathieu.
+//
+// Use of this source code is governed by the MIT license that can be
+// found in the LICENSE file.
+
+package htutil
+
+import (
+	"net/http"
+	"time"
+)
+
+// Validators are the current validators of the selected representation of
+// a resource, against which preconditions are evaluated.
+type Validators struct {
+	// ETag is the entity tag of the representation, including its quotes
+	// and weakness indicator, or "" if it has none.
+	ETag string
+
+	// LastModified is the last modification date of the representation,
+	// or zero if unknown.
+	LastModified time.Time
+
+	// Exists is false if the resource has no current representation.
+	Exists bool
+}
+
+// RequirePreconditions returns a middleware for routes that modify
+// resources, enforcing optimistic concurrency as per RFC 6585 §3.
+//
+// Requests with one of the specified methods (PUT, PATCH, and DELETE if
+// none are specified) must carry If-Match or If-Unmodified-Since, and are
+// otherwise rejected with 428 Precondition Required. If validators is not
+// nil, it is called to get the current validators of the target resource,
+// and requests whose preconditions do not hold are rejected with 412
+// Precondition Failed, as per RFC 9110 §13.2.2.
+func RequirePreconditions(next http.Handler, validators func(req *http.Request) (Validators, error), methods ...string) http.Handler {
+	if len(methods) == 0 {
+		methods = []string{http.MethodPut, http.MethodPatch, http.MethodDelete}
+	}
+	return http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+		if !containsString(methods, req.Method) {
+			next.ServeHTTP(w, req)
+			return
+		}
+
+		ifMatch := req.Header.Values("If-Match")
+		ifUnmodifiedSince := req.Header.Get("If-Unmodified-Since")
+		if len(ifMatch) == 0 && ifUnmodifiedSince == "" {
+			RespondError(w, req, NewProblem(http.StatusPreconditionRequired,
+				"This request must be conditional; use If-Match with the entity tag of the resource."))
+			return
+		}
+
+		if validators != nil {
+			v, err := validators(req)
+			if err != nil {
+				RespondError(w, req, err)
+				return
+			}
+			if !preconditionsHold(v, ifMatch, ifUnmodifiedSince) {
+				RespondError(w, req, NewProblem(http.StatusPreconditionFailed,
+					"The resource was modified since it was last retrieved."))
+				return
+			}
+		}
+
+		next.ServeHTTP(w, req)
+	})
+}
+
+// preconditionsHold evaluates If-Match, or If-Unmodified-Since in its
+// absence, against the current validators v.
+func preconditionsHold(v Validators, ifMatch []string, ifUnmodifiedSince string) bool {
+	if len(ifMatch) > 0 {
+		if !v.Exists {
+			return false
+		}
+		list, err := ParseETagList(ifMatch...)
+		etag, _ := ParseETag(v.ETag)
+		return err == nil && list.MatchStrong(etag)
+	}
+
+	since, err := http.ParseTime(ifUnmodifiedSince)
+	if err != nil || v.LastModified.IsZero() {
+		// Invalid dates, and resources without dates, make the
+		// precondition ignored.
+		return true
+	}
+	return !v.LastModified.Truncate(time.Second).After(since)
+}
@@ -0,0 +1,71 @@
+// Copyright 2026 Franklin "Snaipe" Mathieu.
+//
+// Use of this source code is governed by the MIT license that can be
+// found in the LICENSE file.
+
+package htutil
+
+import (
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+)
+
+func TestRequirePreconditions(t *testing.T) {
+	t.Parallel()
+
+	modified := time.Date(2026, 1, 1, 12, 0, 0, 0, time.UTC)
+	validators := func(req *http.Request) (Validators, error) {
+		if req.URL.Path == "/missing" {
+			return Validators{}, nil
+		}
+		return Validators{ETag: `"v2"`, LastModified: modified, Exists: true}, nil
+	}
+	handler := RequirePreconditions(http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+		w.WriteHeader(http.StatusNoContent)
+	}), validators)
+
+	tcases := []struct {
+		Method string
+		Path   string
+		Header http.Header
+		Status int
+	}{
+		{Method: "GET", Path: "/", Status: 204},
+		{Method: "PUT", Path: "/", Status: 428},
+		{Method: "PUT", Path: "/", Header: http.Header{"If-Match": {`"v2"`}}, Status: 204},
+		{Method: "PUT", Path: "/", Header: http.Header{"If-Match": {`"v1", "v2"`}}, Status: 204},
+		{Method: "PATCH", Path: "/", Header: http.Header{"If-Match": {`"v1"`}}, Status: 412},
+		{Method: "PATCH", Path: "/", Header: http.Header{"If-Match": {`W/"v2"`}}, Status: 412},
+		{Method: "DELETE", Path: "/", Header: http.Header{"If-Match": {"*"}}, Status: 204},
+		{Method: "DELETE", Path: "/missing", Header: http.Header{"If-Match": {"*"}}, Status: 412},
+		{Method: "PUT", Path: "/", Header: http.Header{"If-Unmodified-Since": {modified.Format(http.TimeFormat)}}, Status: 204},
+		{Method: "PUT", Path: "/", Header: http.Header{"If-Unmodified-Since": {modified.Add(-time.Hour).Format(http.TimeFormat)}}, Status: 412},
+		{
+			// If-Match takes precedence over If-Unmodified-Since.
+			Method: "PUT",
+			Path:   "/",
+			Header: http.Header{
+				"If-Match":            {`"v2"`},
+				"If-Unmodified-Since": {modified.Add(-time.Hour).Format(http.TimeFormat)},
+			},
+			Status: 204,
+		},
+	}
+
+	for i, tcase := range tcases {
+		t.Run(fmt.Sprintf("%d", i), func(t *testing.T) {
+			req := httptest.NewRequest(tcase.Method, tcase.Path, nil)
+			for k, v := range tcase.Header {
+				req.Header[k] = v
+			}
+			w := httptest.NewRecorder()
+			handler.ServeHTTP(w, req)
+			if w.Code != tcase.Status {
+				t.Fatalf("expected status %d, got %d", tcase.Status, w.Code)
+			}
+		})
+	}
+}
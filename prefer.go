@@ -0,0 +1,180 @@
+// Copyright 2026 Franklin "Snaipe" Mathieu.
+//
+// Use of this source code is governed by the MIT license that can be
+// found in the LICENSE file.
+
+package htutil
+
+import (
+	"net/http"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Preference is a preference of a Prefer header field, as per RFC 7240 §2.
+type Preference struct {
+	// Name is the lowercased name of the preference, like "return".
+	Name string
+
+	// Value is the unquoted value of the preference, or "" if it has none.
+	Value string
+
+	// Params contains the parameters of the preference, with lowercased
+	// names and unquoted values. Parameters with no value are present with
+	// an empty value.
+	Params map[string]string
+}
+
+// String returns the preference as formatted in a Prefer header field.
+func (p Preference) String() string {
+	var out strings.Builder
+	out.WriteString(p.Name)
+	if p.Value != "" {
+		out.WriteString("=" + tokenOrQuoted(p.Value))
+	}
+	names := make([]string, 0, len(p.Params))
+	for name := range p.Params {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	for _, name := range names {
+		out.WriteString("; " + name)
+		if v := p.Params[name]; v != "" {
+			out.WriteString("=" + tokenOrQuoted(v))
+		}
+	}
+	return out.String()
+}
+
+// Preferences holds the preferences of a request, as per RFC 7240 §4.
+type Preferences struct {
+	// Return is the value of the return preference: "minimal",
+	// "representation", or "" if absent.
+	Return string
+
+	// Wait is the value of the wait preference, or 0 if absent.
+	Wait time.Duration
+
+	// RespondAsync is whether the respond-async preference is present.
+	RespondAsync bool
+
+	// Handling is the value of the handling preference: "strict",
+	// "lenient", or "" if absent.
+	Handling string
+
+	// Extensions are the other preferences, in order.
+	Extensions []Preference
+}
+
+// Get returns the extension preference name.
+func (p Preferences) Get(name string) (Preference, bool) {
+	for _, pref := range p.Extensions {
+		if strings.EqualFold(pref.Name, name) {
+			return pref, true
+		}
+	}
+	return Preference{}, false
+}
+
+// ParsePrefer returns the preferences of the Prefer header fields of hdr.
+//
+// As per RFC 7240 §2, only the first occurrence of a preference is
+// considered, and invalid preferences, or preferences with invalid values,
+// are ignored.
+func ParsePrefer(hdr http.Header) Preferences {
+	var (
+		prefs Preferences
+		seen  = make(map[string]bool)
+	)
+	for _, member := range listMembers(hdr.Values("Prefer")) {
+		pref, ok := parsePreference(member)
+		if !ok || seen[pref.Name] {
+			continue
+		}
+		seen[pref.Name] = true
+
+		switch pref.Name {
+		case "return":
+			switch v := strings.ToLower(pref.Value); v {
+			case "minimal", "representation":
+				prefs.Return = v
+			}
+		case "wait":
+			if n, err := strconv.ParseInt(pref.Value, 10, 64); err == nil && n >= 0 && n <= int64(maxAge/time.Second) {
+				prefs.Wait = time.Duration(n) * time.Second
+			}
+		case "respond-async":
+			prefs.RespondAsync = true
+		case "handling":
+			switch v := strings.ToLower(pref.Value); v {
+			case "strict", "lenient":
+				prefs.Handling = v
+			}
+		default:
+			prefs.Extensions = append(prefs.Extensions, pref)
+		}
+	}
+	return prefs
+}
+
+// parsePreference parses a preference, of the form
+// `token [= word] *(; token [= word])`.
+func parsePreference(s string) (Preference, bool) {
+	parts := splitUnquoted(s, ';')
+	name, value, ok := parsePreferenceParam(parts[0])
+	if !ok {
+		return Preference{}, false
+	}
+	pref := Preference{Name: name, Value: value}
+	for _, part := range parts[1:] {
+		if trimOWS(part) == "" {
+			continue
+		}
+		name, value, ok := parsePreferenceParam(part)
+		if !ok {
+			return Preference{}, false
+		}
+		if pref.Params == nil {
+			pref.Params = make(map[string]string)
+		}
+		if _, dup := pref.Params[name]; !dup {
+			pref.Params[name] = value
+		}
+	}
+	return pref, true
+}
+
+func parsePreferenceParam(s string) (name, value string, ok bool) {
+	name, value, _ = strings.Cut(s, "=")
+	name, value = strings.ToLower(trimOWS(name)), trimOWS(value)
+	if !IsToken(name) {
+		return "", "", false
+	}
+	if strings.HasPrefix(value, `"`) {
+		unquoted, err := UnquoteString(value)
+		if err != nil {
+			return "", "", false
+		}
+		return name, unquoted, true
+	}
+	if value != "" && !IsToken(value) {
+		return "", "", false
+	}
+	return name, value, true
+}
+
+// ApplyPreference adds the preference name, with the specified value if
+// not empty, to the Preference-Applied header field of h, as per
+// RFC 7240 §3, to indicate that the response honors it.
+//
+// Cacheable responses that depend on the preferences of the request should
+// also vary on Prefer.
+func ApplyPreference(h http.Header, name, value string) {
+	applied := strings.ToLower(name)
+	if value != "" {
+		applied += "=" + tokenOrQuoted(value)
+	}
+	h.Add("Preference-Applied", applied)
+}
@@ -0,0 +1,98 @@
+// Copyright 2026 Franklin "Snaipe" Mathieu.
+//
+// Use of this source code is governed by the MIT license that can be
+// found in the LICENSE file.
+
+package htutil
+
+import (
+	"fmt"
+	"net/http"
+	"reflect"
+	"testing"
+	"time"
+)
+
+func TestParsePrefer(t *testing.T) {
+	t.Parallel()
+
+	tcases := []struct {
+		Values []string
+		Expect Preferences
+	}{
+		{Values: nil, Expect: Preferences{}},
+		{Values: []string{"return=minimal"}, Expect: Preferences{Return: "minimal"}},
+		{Values: []string{"Return=Representation, wait=10", "respond-async"}, Expect: Preferences{
+			Return: "representation", Wait: 10 * time.Second, RespondAsync: true,
+		}},
+		{Values: []string{"handling=lenient, handling=strict, return=minimal, return=representation"}, Expect: Preferences{
+			Return: "minimal", Handling: "lenient",
+		}},
+		{Values: []string{"return=other, wait=soon, handling=\"strict\""}, Expect: Preferences{Handling: "strict"}},
+		{Values: []string{`odata.maxpagesize=50; Strategy = "a, b";flag, foo="bar;baz"`}, Expect: Preferences{
+			Extensions: []Preference{
+				{Name: "odata.maxpagesize", Value: "50", Params: map[string]string{"strategy": "a, b", "flag": ""}},
+				{Name: "foo", Value: "bar;baz"},
+			},
+		}},
+		{Values: []string{"bad value, =x, ok;, wait=-1"}, Expect: Preferences{
+			Extensions: []Preference{{Name: "ok"}},
+		}},
+	}
+
+	for i, tcase := range tcases {
+		t.Run(fmt.Sprintf("%d", i), func(t *testing.T) {
+			prefs := ParsePrefer(http.Header{"Prefer": tcase.Values})
+			if !reflect.DeepEqual(prefs, tcase.Expect) {
+				t.Fatalf("expected %+v, got %+v", tcase.Expect, prefs)
+			}
+		})
+	}
+}
+
+func TestPreferencesGet(t *testing.T) {
+	t.Parallel()
+
+	prefs := ParsePrefer(http.Header{"Prefer": {"odata.include-annotations=\"*\", return=minimal"}})
+	pref, ok := prefs.Get("OData.Include-Annotations")
+	if !ok || pref.Value != "*" {
+		t.Fatalf("expected odata.include-annotations=*, got %+v (%v)", pref, ok)
+	}
+	if _, ok := prefs.Get("return"); ok {
+		t.Fatalf("expected return to be a typed preference")
+	}
+}
+
+func TestPreferenceString(t *testing.T) {
+	t.Parallel()
+
+	tcases := []struct {
+		Pref   Preference
+		Expect string
+	}{
+		{Pref: Preference{Name: "respond-async"}, Expect: "respond-async"},
+		{Pref: Preference{Name: "wait", Value: "5"}, Expect: "wait=5"},
+		{Pref: Preference{Name: "foo", Value: "a b", Params: map[string]string{"z": "", "a": "1"}}, Expect: `foo="a b"; a=1; z`},
+	}
+
+	for i, tcase := range tcases {
+		t.Run(fmt.Sprintf("%d", i), func(t *testing.T) {
+			if out := tcase.Pref.String(); out != tcase.Expect {
+				t.Fatalf("expected %q, got %q", tcase.Expect, out)
+			}
+		})
+	}
+}
+
+func TestApplyPreference(t *testing.T) {
+	t.Parallel()
+
+	h := http.Header{}
+	ApplyPreference(h, "Return", "minimal")
+	ApplyPreference(h, "respond-async", "")
+	ApplyPreference(h, "foo", "a b")
+	expect := []string{"return=minimal", "respond-async", `foo="a b"`}
+	if !reflect.DeepEqual(h.Values("Preference-Applied"), expect) {
+		t.Fatalf("expected %v, got %v", expect, h.Values("Preference-Applied"))
+	}
+}
@@ -0,0 +1,330 @@
+// Copyright 2026 Franklin "Snaipe" Mathieu.
+//
+// Use of this source code is governed by the MIT license that can be
+// found in the LICENSE file.
+
+package htutil
+
+import (
+	"bytes"
+	"encoding/json"
+	"encoding/xml"
+	"errors"
+	"fmt"
+	"io"
+	"log/slog"
+	"net/http"
+	"sort"
+	"strconv"
+)
+
+// Problem is a machine-readable description of an error, as per RFC 9457
+// (Problem Details for HTTP APIs). It implements the error interface, so
+// that handlers can return it and have it rendered by RespondError.
+type Problem struct {
+	// Type is a URI reference identifying the problem type. It defaults
+	// to "about:blank", in which case Title is the reason phrase of Status.
+	Type string `json:"type,omitempty"`
+
+	// Title is a short, human-readable summary of the problem type.
+	Title string `json:"title,omitempty"`
+
+	// Status is the status code of the response.
+	Status int `json:"status,omitempty"`
+
+	// Detail is a human-readable explanation of this occurrence of the
+	// problem.
+	Detail string `json:"detail,omitempty"`
+
+	// Instance is a URI reference identifying this occurrence of the
+	// problem.
+	Instance string `json:"instance,omitempty"`
+
+	// Extensions are the extension members of the problem, like "balance"
+	// in {"type": "...", "balance": 30}. Values decoded from JSON are as
+	// decoded by encoding/json into an interface{}; values decoded from
+	// XML are strings.
+	Extensions map[string]interface{} `json:"-"`
+}
+
+// NewProblem returns a Problem with the specified status and detail.
+func NewProblem(status int, detail string) *Problem {
+	return &Problem{
+		Title:  StatusText(status),
+		Status: status,
+		Detail: detail,
+	}
+}
+
+func (p *Problem) Error() string {
+	title := p.Title
+	if title == "" {
+		title = StatusText(p.Status)
+	}
+	if p.Detail == "" {
+		return fmt.Sprintf("%d %s", p.Status, title)
+	}
+	return fmt.Sprintf("%d %s: %s", p.Status, title, p.Detail)
+}
+
+// problemMembers are the standard members of problem details.
+var problemMembers = []string{"type", "title", "status", "detail", "instance"}
+
+// MarshalJSON encodes p as application/problem+json, with its extension
+// members following its standard members.
+func (p Problem) MarshalJSON() ([]byte, error) {
+	type problem Problem
+	data, err := json.Marshal(problem(p))
+	if err != nil || len(p.Extensions) == 0 {
+		return data, err
+	}
+	ext := make(map[string]interface{}, len(p.Extensions))
+	for k, v := range p.Extensions {
+		ext[k] = v
+	}
+	for _, name := range problemMembers {
+		delete(ext, name)
+	}
+	extData, err := json.Marshal(ext)
+	if err != nil || len(ext) == 0 {
+		return data, err
+	}
+	if len(data) == 2 {
+		return extData, nil
+	}
+	return append(append(data[:len(data)-1], ','), extData[1:]...), nil
+}
+
+// UnmarshalJSON decodes application/problem+json data into p.
+func (p *Problem) UnmarshalJSON(data []byte) error {
+	type problem Problem
+	var std problem
+	if err := json.Unmarshal(data, &std); err != nil {
+		return err
+	}
+	var members map[string]interface{}
+	if err := json.Unmarshal(data, &members); err != nil {
+		return err
+	}
+	for _, name := range problemMembers {
+		delete(members, name)
+	}
+	if len(members) > 0 {
+		std.Extensions = members
+	}
+	*p = Problem(std)
+	return nil
+}
+
+// xmlProblem is the XML representation of problem details, as per
+// RFC 9457 Appendix B, for decoding.
+type xmlProblem struct {
+	XMLName  xml.Name    `xml:"urn:ietf:rfc:7807 problem"`
+	Type     string      `xml:"type"`
+	Title    string      `xml:"title"`
+	Status   string      `xml:"status"`
+	Detail   string      `xml:"detail"`
+	Instance string      `xml:"instance"`
+	Members  []xmlMember `xml:",any"`
+}
+
+type xmlMember struct {
+	XMLName xml.Name
+	Value   string `xml:",chardata"`
+}
+
+// MarshalXML encodes p as application/problem+xml. Extension members are
+// encoded in name order, with their values formatted by fmt.Sprint.
+func (p Problem) MarshalXML(e *xml.Encoder, start xml.StartElement) error {
+	// Members are encoded token by token, as encoding/xml would otherwise
+	// redeclare the namespace of the problem on each of them.
+	members := []xmlMember{
+		{XMLName: xml.Name{Local: "type"}, Value: p.Type},
+		{XMLName: xml.Name{Local: "title"}, Value: p.Title},
+		{XMLName: xml.Name{Local: "status"}},
+		{XMLName: xml.Name{Local: "detail"}, Value: p.Detail},
+		{XMLName: xml.Name{Local: "instance"}, Value: p.Instance},
+	}
+	if p.Status != 0 {
+		members[2].Value = strconv.Itoa(p.Status)
+	}
+	names := make([]string, 0, len(p.Extensions))
+	for name := range p.Extensions {
+		if !containsString(problemMembers, name) {
+			names = append(names, name)
+		}
+	}
+	sort.Strings(names)
+	for _, name := range names {
+		members = append(members, xmlMember{
+			XMLName: xml.Name{Local: name},
+			Value:   fmt.Sprint(p.Extensions[name]),
+		})
+	}
+
+	start = xml.StartElement{Name: xml.Name{Space: "urn:ietf:rfc:7807", Local: "problem"}}
+	if err := e.EncodeToken(start); err != nil {
+		return err
+	}
+	for _, m := range members {
+		if m.Value == "" {
+			continue
+		}
+		elem := xml.StartElement{Name: m.XMLName}
+		for _, tok := range []xml.Token{elem, xml.CharData(m.Value), elem.End()} {
+			if err := e.EncodeToken(tok); err != nil {
+				return err
+			}
+		}
+	}
+	return e.EncodeToken(start.End())
+}
+
+// UnmarshalXML decodes application/problem+xml data into p.
+func (p *Problem) UnmarshalXML(d *xml.Decoder, start xml.StartElement) error {
+	var xp xmlProblem
+	if err := d.DecodeElement(&xp, &start); err != nil {
+		return err
+	}
+	if start.Name.Space != "urn:ietf:rfc:7807" || start.Name.Local != "problem" {
+		return fmt.Errorf("unexpected element <%s xmlns=%q>", start.Name.Local, start.Name.Space)
+	}
+	*p = Problem{Type: xp.Type, Title: xp.Title, Detail: xp.Detail, Instance: xp.Instance}
+	if xp.Status != "" {
+		status, err := strconv.Atoi(xp.Status)
+		if err != nil {
+			return fmt.Errorf("invalid status %q", xp.Status)
+		}
+		p.Status = status
+	}
+	for _, m := range xp.Members {
+		if p.Extensions == nil {
+			p.Extensions = make(map[string]interface{})
+		}
+		p.Extensions[m.XMLName.Local] = m.Value
+	}
+	return nil
+}
+
+// responseHeaderError is implemented by errors that set header fields of
+// the responses describing them, like the Accept-Patch field of 415
+// Unsupported Media Type responses.
+type responseHeaderError interface {
+	error
+	setResponseHeader(h http.Header)
+}
+
+// problemOf returns the Problem that err describes. Errors that are not
+// problems are reported as an opaque 500 Internal Server Error, since their
+// message may leak internal details.
+func problemOf(err error) Problem {
+	var p Problem
+	var perr *Problem
+	if errors.As(err, &perr) && perr != nil {
+		p = *perr
+	}
+	if p.Status == 0 {
+		p.Status = http.StatusInternalServerError
+	}
+	if p.Title == "" {
+		p.Title = StatusText(p.Status)
+	}
+	return p
+}
+
+// RespondError writes a response describing err to w.
+//
+// The representation of the error is negotiated with the Accept header of
+// req, between text/plain, application/problem+json, and text/html. HTML is
+// only offered when an error page is registered for the status of the
+// response (see RegisterErrorPage), so that browsers get a page meant for
+// humans while API clients get problem details.
+//
+// If err is, or wraps, a *Problem, it is rendered as-is. Otherwise, the
+// response is an opaque 500 Internal Server Error, and err, unless nil, is
+// logged through the logger of the request context (see ContextLogger). Some
+// errors also set header fields of the response, like the Accept-Patch or
+// Accept-Post field of an *UnsupportedMediaTypeError.
+func RespondError(w http.ResponseWriter, req *http.Request, err error) {
+	p := problemOf(err)
+	var herr responseHeaderError
+	if errors.As(err, &herr) {
+		herr.setResponseHeader(w.Header())
+	}
+
+	ctx := req.Context()
+	attrs := []slog.Attr{slog.String("method", req.Method), slog.Any("url", URL{req.URL})}
+	var perr *Problem
+	if errors.As(err, &perr) {
+		attrs = append(attrs, slog.Any("problem", p))
+		ContextLogger(ctx).LogAttrs(ctx, slog.LevelDebug, "responding with problem", attrs...)
+	} else {
+		if err != nil {
+			attrs = append(attrs, slog.String("error", err.Error()))
+		}
+		ContextLogger(ctx).LogAttrs(ctx, slog.LevelError, "internal error", attrs...)
+	}
+
+	offers := []string{"text/plain", "application/problem+json", "application/json", "application/problem+xml", "application/xml"}
+	page := errorPage(p.Status)
+	if page != nil {
+		offers = append(offers, "text/html")
+	}
+
+	AddVary(w.Header(), "Accept")
+	w.Header().Set("X-Content-Type-Options", "nosniff")
+
+	ctype, _ := NegotiateContent(req.Header, "Accept", offers...)
+	switch ctype {
+	case "text/html":
+		var buf bytes.Buffer
+		if err := page.Execute(&buf, p); err == nil {
+			w.Header().Set("Content-Type", "text/html; charset=utf-8")
+			w.WriteHeader(p.Status)
+			buf.WriteTo(w)
+			return
+		}
+		// Fall back to text/plain if the page cannot be rendered.
+	case "application/problem+json", "application/json", "application/problem+xml", "application/xml":
+		writeProblem(w, p, ctype)
+		return
+	}
+
+	w.Header().Set("Content-Type", "text/plain; charset=utf-8")
+	w.WriteHeader(p.Status)
+	if p.Detail != "" {
+		fmt.Fprintln(w, p.Detail)
+	} else {
+		fmt.Fprintln(w, p.Title)
+	}
+}
+
+// WriteProblem writes p to w, as application/problem+json or
+// application/problem+xml, as negotiated with the Accept header of req;
+// JSON is preferred when both are acceptable.
+//
+// Unlike RespondError, WriteProblem always responds with problem details,
+// and does not log anything. A nil p is written as a 500 Internal Server
+// Error.
+func WriteProblem(w http.ResponseWriter, req *http.Request, p *Problem) {
+	AddVary(w.Header(), "Accept")
+	w.Header().Set("X-Content-Type-Options", "nosniff")
+	ctype, _ := NegotiateContent(req.Header, "Accept",
+		"application/problem+json", "application/json", "application/problem+xml", "application/xml")
+	writeProblem(w, problemOf(p), ctype)
+}
+
+func writeProblem(w http.ResponseWriter, p Problem, ctype string) {
+	switch ctype {
+	case "application/problem+xml", "application/xml":
+		w.Header().Set("Content-Type", "application/problem+xml")
+		w.WriteHeader(p.Status)
+		io.WriteString(w, xml.Header)
+		xml.NewEncoder(w).Encode(p)
+		io.WriteString(w, "\n")
+	default:
+		w.Header().Set("Content-Type", "application/problem+json")
+		w.WriteHeader(p.Status)
+		json.NewEncoder(w).Encode(p)
+	}
+}
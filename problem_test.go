@@ -0,0 +1,175 @@
+// Copyright 2026 Franklin "Snaipe" Mathieu.
+//
+// Use of this source code is governed by the MIT license that can be
+// found in the LICENSE file.
+
+package htutil
+
+import (
+	"encoding/json"
+	"encoding/xml"
+	"errors"
+	"fmt"
+	"net/http/httptest"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestRespondError(t *testing.T) {
+	t.Parallel()
+
+	tcases := []struct {
+		Err         error
+		Accept      string
+		Status      int
+		ContentType string
+		Body        string
+	}{
+		{
+			Err:         NewProblem(404, "no such widget"),
+			Status:      404,
+			ContentType: "text/plain; charset=utf-8",
+			Body:        "no such widget\n",
+		},
+		{
+			Err:         fmt.Errorf("loading widget: %w", NewProblem(404, "no such widget")),
+			Accept:      "application/json",
+			Status:      404,
+			ContentType: "application/problem+json",
+			Body:        `{"title":"Not Found","status":404,"detail":"no such widget"}` + "\n",
+		},
+		{
+			Err:         errors.New("database password is hunter2"),
+			Accept:      "text/html, application/problem+json;q=0.5",
+			Status:      500,
+			ContentType: "application/problem+json",
+			Body:        `{"title":"Internal Server Error","status":500}` + "\n",
+		},
+		{
+			Err:         NewProblem(404, "no such widget"),
+			Accept:      "application/xml",
+			Status:      404,
+			ContentType: "application/problem+xml",
+			Body:        xml.Header + `<problem xmlns="urn:ietf:rfc:7807"><title>Not Found</title><status>404</status><detail>no such widget</detail></problem>` + "\n",
+		},
+		{
+			Err:         &Problem{Type: "https://example.com/out-of-stock", Title: "Out of stock", Status: 409},
+			Status:      409,
+			ContentType: "text/plain; charset=utf-8",
+			Body:        "Out of stock\n",
+		},
+	}
+
+	for i, tcase := range tcases {
+		t.Run(fmt.Sprintf("%d", i), func(t *testing.T) {
+			req := httptest.NewRequest("GET", "/", nil)
+			if tcase.Accept != "" {
+				req.Header.Set("Accept", tcase.Accept)
+			}
+			w := httptest.NewRecorder()
+			RespondError(w, req, tcase.Err)
+
+			if w.Code != tcase.Status {
+				t.Fatalf("expected status %d, got %d", tcase.Status, w.Code)
+			}
+			if ct := w.Header().Get("Content-Type"); ct != tcase.ContentType {
+				t.Fatalf("expected content type %s, got %s", tcase.ContentType, ct)
+			}
+			if body := w.Body.String(); body != tcase.Body {
+				t.Fatalf("expected body %q, got %q", tcase.Body, body)
+			}
+			if vary := w.Header().Get("Vary"); vary != "Accept" {
+				t.Fatalf("expected Vary Accept, got %q", vary)
+			}
+		})
+	}
+}
+
+func TestProblemError(t *testing.T) {
+	t.Parallel()
+
+	p := NewProblem(404, "no such widget")
+	if s := p.Error(); s != "404 Not Found: no such widget" {
+		t.Fatalf("expected %q, got %q", "404 Not Found: no such widget", s)
+	}
+
+	var decoded Problem
+	data, _ := json.Marshal(p)
+	if err := json.Unmarshal(data, &decoded); err != nil || !reflect.DeepEqual(decoded, *p) {
+		t.Fatalf("expected %v, got %v (%v)", *p, decoded, err)
+	}
+	if !strings.Contains(string(data), `"status":404`) {
+		t.Fatalf("expected status member, got %s", data)
+	}
+}
+
+func TestWriteProblem(t *testing.T) {
+	t.Parallel()
+
+	p := &Problem{
+		Type:       "https://example.com/probs/out-of-credit",
+		Title:      "You do not have enough credit.",
+		Status:     403,
+		Extensions: map[string]interface{}{"balance": 30, "title": "ignored"},
+	}
+
+	tcases := []struct {
+		Accept      string
+		ContentType string
+		Body        string
+	}{
+		{
+			ContentType: "application/problem+json",
+			Body:        `{"type":"https://example.com/probs/out-of-credit","title":"You do not have enough credit.","status":403,"balance":30}` + "\n",
+		},
+		{
+			Accept:      "application/xml, application/json;q=0.5",
+			ContentType: "application/problem+xml",
+			Body: xml.Header + `<problem xmlns="urn:ietf:rfc:7807"><type>https://example.com/probs/out-of-credit</type>` +
+				`<title>You do not have enough credit.</title><status>403</status><balance>30</balance></problem>` + "\n",
+		},
+		{
+			Accept:      "text/html",
+			ContentType: "application/problem+json",
+		},
+	}
+
+	for i, tcase := range tcases {
+		t.Run(fmt.Sprintf("%d", i), func(t *testing.T) {
+			req := httptest.NewRequest("GET", "/", nil)
+			if tcase.Accept != "" {
+				req.Header.Set("Accept", tcase.Accept)
+			}
+			w := httptest.NewRecorder()
+			WriteProblem(w, req, p)
+
+			resp := w.Result()
+			if resp.StatusCode != 403 {
+				t.Fatalf("expected status 403, got %d", resp.StatusCode)
+			}
+			if ctype := resp.Header.Get("Content-Type"); ctype != tcase.ContentType {
+				t.Fatalf("expected Content-Type %q, got %q", tcase.ContentType, ctype)
+			}
+			if tcase.Body != "" && w.Body.String() != tcase.Body {
+				t.Fatalf("expected body %q, got %q", tcase.Body, w.Body.String())
+			}
+
+			decoded, err := ParseProblem(resp)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if decoded.Type != p.Type || decoded.Title != p.Title || decoded.Status != p.Status {
+				t.Fatalf("expected %v, got %v", p, decoded)
+			}
+			if balance := fmt.Sprint(decoded.Extensions["balance"]); balance != "30" || len(decoded.Extensions) != 1 {
+				t.Fatalf("expected balance extension, got %v", decoded.Extensions)
+			}
+		})
+	}
+	w := httptest.NewRecorder()
+	WriteProblem(w, httptest.NewRequest("GET", "/", nil), nil)
+	if w.Code != 500 {
+		t.Fatalf("expected status 500 for a nil problem, got %d", w.Code)
+	}
+}
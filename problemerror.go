@@ -0,0 +1,129 @@
+// Copyright 2026 Franklin "Snaipe" Mathieu.
+//
+// Use of this source code is governed by the MIT license that can be
+// found in the LICENSE file.
+
+package htutil
+
+import (
+	"encoding/json"
+	"encoding/xml"
+	"fmt"
+	"io"
+	"mime"
+	"net/http"
+)
+
+// ProblemError is an error response received from a server, as returned by
+// CheckResponse. It carries the problem details of the response, if any.
+type ProblemError struct {
+	Problem
+
+	// Response is the error response, whose body has been consumed.
+	Response *http.Response
+}
+
+// Error returns the problem details of e as a string.
+//
+// A ProblemError deliberately does not unwrap to its *Problem: the problem
+// details of an upstream server are not meant to be relayed as-is to the
+// clients of a handler by RespondError. Handlers wishing to do so must
+// extract them explicitly.
+func (e *ProblemError) Error() string {
+	return e.Problem.Error()
+}
+
+// maxProblemSize is the size limit of the problem details bodies decoded
+// by CheckResponse.
+const maxProblemSize = 1 << 20
+
+// CheckResponse returns a *ProblemError if resp has a 4xx or 5xx status,
+// and nil otherwise.
+//
+// If the response is an application/problem+json or application/problem+xml
+// document, as per RFC 9457, it is decoded into the returned error with
+// ParseProblem, and the body of resp is consumed and closed. Otherwise, the
+// problem details only carry the status of the response and its reason
+// phrase, and the body is left untouched.
+func CheckResponse(resp *http.Response) error {
+	if resp.StatusCode < 400 || resp.StatusCode >= 600 {
+		return nil
+	}
+	perr := &ProblemError{
+		Problem: Problem{
+			Status: resp.StatusCode,
+			Title:  StatusText(resp.StatusCode),
+		},
+		Response: resp,
+	}
+	if !isProblemResponse(resp) {
+		return perr
+	}
+	p, err := ParseProblem(resp)
+	if err != nil {
+		return err
+	}
+	perr.Problem = *p
+	return perr
+}
+
+func isProblemResponse(resp *http.Response) bool {
+	ctype, _, _ := mime.ParseMediaType(resp.Header.Get("Content-Type"))
+	return ctype == "application/problem+json" || ctype == "application/problem+xml"
+}
+
+// ParseProblem decodes the problem details of resp, an
+// application/problem+json or application/problem+xml response, as per
+// RFC 9457. The body of resp is consumed and closed.
+//
+// Absent statuses default to the status of resp, and absent titles of
+// untyped problems to its reason phrase.
+func ParseProblem(resp *http.Response) (*Problem, error) {
+	defer resp.Body.Close()
+
+	ctype, _, _ := mime.ParseMediaType(resp.Header.Get("Content-Type"))
+	var unmarshal func(data []byte, v interface{}) error
+	switch ctype {
+	case "application/problem+json":
+		unmarshal = json.Unmarshal
+	case "application/problem+xml":
+		unmarshal = xml.Unmarshal
+	default:
+		return nil, fmt.Errorf("decoding problem details: unsupported media type %q", ctype)
+	}
+
+	data, err := io.ReadAll(io.LimitReader(resp.Body, maxProblemSize))
+	if err != nil {
+		return nil, fmt.Errorf("reading problem details: %w", err)
+	}
+	var p Problem
+	if err := unmarshal(data, &p); err != nil {
+		return nil, fmt.Errorf("decoding problem details: %w", err)
+	}
+	if p.Status == 0 {
+		p.Status = resp.StatusCode
+	}
+	if p.Title == "" && p.Type == "" {
+		p.Title = StatusText(p.Status)
+	}
+	return &p, nil
+}
+
+// DoChecked sends req with client, and checks the response with
+// CheckResponse, so that callers can handle error responses with errors.As
+// instead of checking every response.
+//
+// Unlike a http.RoundTripper, it returns error responses along with their
+// *ProblemError, so that their body remains available when it is not a
+// problem details document. The caller must close the body of the returned
+// response, if any.
+func DoChecked(client *http.Client, req *http.Request) (*http.Response, error) {
+	resp, err := client.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	if err := CheckResponse(resp); err != nil {
+		return resp, err
+	}
+	return resp, nil
+}
@@ -0,0 +1,188 @@
+// Copyright 2026 Franklin "Snaipe" Mathieu.
+//
+// Use of this source code is governed by the MIT license that can be
+// found in the LICENSE file.
+
+package htutil
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestCheckResponse(t *testing.T) {
+	t.Parallel()
+
+	tcases := []struct {
+		Status      int
+		ContentType string
+		Body        string
+		Problem     Problem
+		Extensions  map[string]interface{}
+		Err         bool
+	}{
+		{
+			Status: 200,
+		},
+		{
+			Status:      404,
+			ContentType: "text/plain",
+			Body:        "not found",
+			Problem:     Problem{Title: "Not Found", Status: 404},
+		},
+		{
+			Status:      403,
+			ContentType: "application/problem+json; charset=utf-8",
+			Body: `{"type": "https://example.com/probs/out-of-credit", "title": "You do not have enough credit.",
+				"detail": "Your current balance is 30, but that costs 50.", "instance": "/account/12345/msgs/abc",
+				"balance": 30, "accounts": ["/account/12345", "/account/67890"]}`,
+			Problem: Problem{
+				Type:     "https://example.com/probs/out-of-credit",
+				Title:    "You do not have enough credit.",
+				Status:   403,
+				Detail:   "Your current balance is 30, but that costs 50.",
+				Instance: "/account/12345/msgs/abc",
+			},
+			Extensions: map[string]interface{}{
+				"balance":  float64(30),
+				"accounts": []interface{}{"/account/12345", "/account/67890"},
+			},
+		},
+		{
+			Status:      503,
+			ContentType: "application/problem+xml",
+			Body: `<?xml version="1.0" encoding="UTF-8"?>
+				<problem xmlns="urn:ietf:rfc:7807">
+				  <type>https://example.com/probs/maintenance</type>
+				  <title>Down for maintenance</title>
+				  <status>503</status>
+				  <eta>2026-10-17T12:00:00Z</eta>
+				</problem>`,
+			Problem: Problem{
+				Type:   "https://example.com/probs/maintenance",
+				Title:  "Down for maintenance",
+				Status: 503,
+			},
+			Extensions: map[string]interface{}{"eta": "2026-10-17T12:00:00Z"},
+		},
+		{
+			Status:      400,
+			ContentType: "application/problem+json",
+			Body:        `{"title": "truncated`,
+			Err:         true,
+		},
+		{
+			Status:      400,
+			ContentType: "application/problem+xml",
+			Body:        `<problem xmlns="urn:ietf:rfc:7807"><status>bad</status></problem>`,
+			Err:         true,
+		},
+	}
+
+	for i, tcase := range tcases {
+		t.Run(fmt.Sprintf("%d", i), func(t *testing.T) {
+			resp := &http.Response{
+				StatusCode: tcase.Status,
+				Header:     http.Header{},
+				Body:       io.NopCloser(strings.NewReader(tcase.Body)),
+			}
+			if tcase.ContentType != "" {
+				resp.Header.Set("Content-Type", tcase.ContentType)
+			}
+
+			err := CheckResponse(resp)
+			var perr *ProblemError
+			switch {
+			case tcase.Status < 400:
+				if err != nil {
+					t.Fatalf("expected no error, got %v", err)
+				}
+				return
+			case tcase.Err:
+				if err == nil || errors.As(err, &perr) {
+					t.Fatalf("expected decoding error, got %v", err)
+				}
+				return
+			case !errors.As(err, &perr):
+				t.Fatalf("expected *ProblemError, got %v", err)
+			}
+			p := perr.Problem
+			p.Extensions = nil
+			if !reflect.DeepEqual(p, tcase.Problem) {
+				t.Fatalf("expected problem %+v, got %+v", tcase.Problem, p)
+			}
+			if !reflect.DeepEqual(perr.Extensions, tcase.Extensions) {
+				t.Fatalf("expected extensions %v, got %v", tcase.Extensions, perr.Extensions)
+			}
+			if perr.Response != resp {
+				t.Fatalf("expected response to be set")
+			}
+		})
+	}
+}
+
+func TestDoChecked(t *testing.T) {
+	t.Parallel()
+
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+		switch req.URL.Path {
+		case "/ok":
+			io.WriteString(w, "ok")
+		case "/text":
+			http.Error(w, "upstream is down", http.StatusBadGateway)
+		default:
+			req.Header.Set("Accept", "application/problem+json")
+			RespondError(w, req, &Problem{Type: "https://example.com/probs/gone", Status: 410, Detail: "widget was deleted"})
+		}
+	}))
+	defer srv.Close()
+
+	get := func(path string) (*http.Response, error) {
+		req, _ := http.NewRequest("GET", srv.URL+path, nil)
+		return DoChecked(srv.Client(), req)
+	}
+
+	resp, err := get("/ok")
+	if err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+	resp.Body.Close()
+
+	resp, err = get("/widget")
+	var perr *ProblemError
+	if !errors.As(err, &perr) || resp == nil {
+		t.Fatalf("expected *ProblemError along with the response, got %v", err)
+	}
+	resp.Body.Close()
+	if perr.Type != "https://example.com/probs/gone" || perr.Status != 410 {
+		t.Fatalf("expected gone problem, got %+v", perr.Problem)
+	}
+
+	// Upstream problem details are not relayed to our own clients.
+	var p *Problem
+	if errors.As(err, &p) {
+		t.Fatalf("expected no *Problem in the chain, got %v", p)
+	}
+	w := httptest.NewRecorder()
+	RespondError(w, httptest.NewRequest("GET", "/", nil), err)
+	if w.Code != http.StatusInternalServerError {
+		t.Fatalf("expected status 500, got %d", w.Code)
+	}
+
+	// Bodies that are not problem details remain readable.
+	resp, err = get("/text")
+	if !errors.As(err, &perr) || perr.Status != 502 {
+		t.Fatalf("expected 502 *ProblemError, got %v", err)
+	}
+	body, _ := io.ReadAll(resp.Body)
+	resp.Body.Close()
+	if string(body) != "upstream is down\n" {
+		t.Fatalf("expected the body to be readable, got %q", body)
+	}
+}
@@ -0,0 +1,205 @@
+// Copyright 2026 Franklin "Snaipe" Mathieu.
+//
+// Use of this source code is governed by the MIT license that can be
+// found in the LICENSE file.
+
+package htutil
+
+import (
+	"fmt"
+	"net/http"
+
+	"snai.pe/go-htutil/sfv"
+)
+
+// Proxy error types, as per RFC 9209 §2.3.
+const (
+	ProxyErrDNSTimeout                     = "dns_timeout"
+	ProxyErrDNSError                       = "dns_error"
+	ProxyErrDestinationNotFound            = "destination_not_found"
+	ProxyErrDestinationUnavailable         = "destination_unavailable"
+	ProxyErrDestinationIPProhibited        = "destination_ip_prohibited"
+	ProxyErrDestinationIPUnroutable        = "destination_ip_unroutable"
+	ProxyErrConnectionRefused              = "connection_refused"
+	ProxyErrConnectionTerminated           = "connection_terminated"
+	ProxyErrConnectionTimeout              = "connection_timeout"
+	ProxyErrConnectionReadTimeout          = "connection_read_timeout"
+	ProxyErrConnectionWriteTimeout         = "connection_write_timeout"
+	ProxyErrConnectionLimitReached         = "connection_limit_reached"
+	ProxyErrTLSProtocolError               = "tls_protocol_error"
+	ProxyErrTLSCertificateError            = "tls_certificate_error"
+	ProxyErrTLSAlertReceived               = "tls_alert_received"
+	ProxyErrHTTPRequestError               = "http_request_error"
+	ProxyErrHTTPRequestDenied              = "http_request_denied"
+	ProxyErrHTTPResponseIncomplete         = "http_response_incomplete"
+	ProxyErrHTTPResponseHeaderSectionSize  = "http_response_header_section_size"
+	ProxyErrHTTPResponseHeaderSize         = "http_response_header_size"
+	ProxyErrHTTPResponseBodySize           = "http_response_body_size"
+	ProxyErrHTTPResponseTrailerSectionSize = "http_response_trailer_section_size"
+	ProxyErrHTTPResponseTrailerSize        = "http_response_trailer_size"
+	ProxyErrHTTPResponseTransferCoding     = "http_response_transfer_coding"
+	ProxyErrHTTPResponseContentCoding      = "http_response_content_coding"
+	ProxyErrHTTPResponseTimeout            = "http_response_timeout"
+	ProxyErrHTTPUpgradeFailed              = "http_upgrade_failed"
+	ProxyErrHTTPProtocolError              = "http_protocol_error"
+	ProxyErrProxyInternalResponse          = "proxy_internal_response"
+	ProxyErrProxyInternalError             = "proxy_internal_error"
+	ProxyErrProxyConfigurationError        = "proxy_configuration_error"
+	ProxyErrProxyLoopDetected              = "proxy_loop_detected"
+)
+
+// ProxyStatus is a single entry of the Proxy-Status header field, as per
+// RFC 9209, describing how a particular intermediary handled a response.
+type ProxyStatus struct {
+	// Proxy identifies the intermediary that added the entry.
+	Proxy string
+
+	// Error is the proxy error type, if the intermediary generated the
+	// response because of an error. See the ProxyErr constants.
+	Error string
+
+	// NextHop identifies the next hop the request was forwarded to.
+	NextHop string
+
+	// NextProtocol is the ALPN protocol identifier used with the next hop.
+	NextProtocol string
+
+	// ReceivedStatus is the status code received from the next hop, or 0.
+	ReceivedStatus int
+
+	// Details is additional human-readable information about the error.
+	Details string
+
+	// RCode and InfoCode are the DNS response code and extended DNS error
+	// code, for the dns_error error type.
+	RCode    string
+	InfoCode int
+
+	// AlertID and AlertMessage describe the TLS alert received, for the
+	// tls_alert_received error type.
+	AlertID      int
+	AlertMessage string
+}
+
+func (ps ProxyStatus) item() sfv.Item {
+	item := sfv.Item{Value: ps.Proxy}
+	if sfv.IsToken(ps.Proxy) {
+		item.Value = sfv.Token(ps.Proxy)
+	}
+	add := func(key string, value interface{}) {
+		item.Params = append(item.Params, sfv.Param{Key: key, Value: value})
+	}
+	if ps.Error != "" {
+		add("error", sfv.Token(ps.Error))
+	}
+	if ps.NextHop != "" {
+		add("next-hop", ps.NextHop)
+	}
+	if ps.NextProtocol != "" {
+		if sfv.IsToken(ps.NextProtocol) {
+			add("next-protocol", sfv.Token(ps.NextProtocol))
+		} else {
+			add("next-protocol", []byte(ps.NextProtocol))
+		}
+	}
+	if ps.ReceivedStatus != 0 {
+		add("received-status", int64(ps.ReceivedStatus))
+	}
+	if ps.Details != "" {
+		add("details", ps.Details)
+	}
+	if ps.RCode != "" {
+		add("rcode", ps.RCode)
+	}
+	if ps.InfoCode != 0 {
+		add("info-code", int64(ps.InfoCode))
+	}
+	if ps.AlertID != 0 {
+		add("alert-id", int64(ps.AlertID))
+	}
+	if ps.AlertMessage != "" {
+		add("alert-message", ps.AlertMessage)
+	}
+	return item
+}
+
+// String formats ps as a single Proxy-Status list member.
+func (ps ProxyStatus) String() string {
+	s, err := sfv.FormatItem(ps.item())
+	if err != nil {
+		return ""
+	}
+	return s
+}
+
+// ParseProxyStatus parses a Proxy-Status header value into its entries,
+// ordered from the intermediary closest to the origin to the one closest
+// to the client. Unknown parameters are ignored.
+func ParseProxyStatus(value string) ([]ProxyStatus, error) {
+	list, err := sfv.ParseList(value)
+	if err != nil {
+		return nil, fmt.Errorf("parsing proxy-status: %w", err)
+	}
+	out := make([]ProxyStatus, 0, len(list))
+	for _, member := range list {
+		item, ok := member.(sfv.Item)
+		if !ok {
+			return nil, fmt.Errorf("parsing proxy-status: unexpected inner list")
+		}
+		var ps ProxyStatus
+		switch v := item.Value.(type) {
+		case sfv.Token:
+			ps.Proxy = string(v)
+		case string:
+			ps.Proxy = v
+		default:
+			return nil, fmt.Errorf("parsing proxy-status: %v is not a token or a string", v)
+		}
+		for _, param := range item.Params {
+			var str string
+			switch v := param.Value.(type) {
+			case sfv.Token:
+				str = string(v)
+			case string:
+				str = v
+			case []byte:
+				str = string(v)
+			case int64:
+				switch param.Key {
+				case "received-status":
+					ps.ReceivedStatus = int(v)
+				case "info-code":
+					ps.InfoCode = int(v)
+				case "alert-id":
+					ps.AlertID = int(v)
+				}
+				continue
+			default:
+				continue
+			}
+			switch param.Key {
+			case "error":
+				ps.Error = str
+			case "next-hop":
+				ps.NextHop = str
+			case "next-protocol":
+				ps.NextProtocol = str
+			case "details":
+				ps.Details = str
+			case "rcode":
+				ps.RCode = str
+			case "alert-message":
+				ps.AlertMessage = str
+			}
+		}
+		out = append(out, ps)
+	}
+	return out, nil
+}
+
+// AddProxyStatus appends ps to the Proxy-Status header field of h.
+// Intermediaries must append their entry after the ones of the
+// intermediaries before them.
+func AddProxyStatus(h http.Header, ps ProxyStatus) {
+	h.Add("Proxy-Status", ps.String())
+}
@@ -0,0 +1,60 @@
+// Copyright 2026 Franklin "Snaipe" Mathieu.
+//
+// Use of this source code is governed by the MIT license that can be
+// found in the LICENSE file.
+
+package htutil
+
+import (
+	"fmt"
+	"reflect"
+	"testing"
+)
+
+func TestProxyStatus(t *testing.T) {
+	t.Parallel()
+
+	tcases := []struct {
+		In        string
+		Out       []ProxyStatus
+		Canonical string
+	}{
+		{
+			In: `ExampleCDN; error=connection_timeout; next-hop="backend.example.org:8001"`,
+			Out: []ProxyStatus{
+				{Proxy: "ExampleCDN", Error: ProxyErrConnectionTimeout, NextHop: "backend.example.org:8001"},
+			},
+			Canonical: `ExampleCDN;error=connection_timeout;next-hop="backend.example.org:8001"`,
+		},
+		{
+			In: `"SomeReverseProxy"; next-protocol=h2; received-status=503, ExampleCDN; error=dns_error; rcode="NXDOMAIN"; info-code=6`,
+			Out: []ProxyStatus{
+				{Proxy: "SomeReverseProxy", NextProtocol: "h2", ReceivedStatus: 503},
+				{Proxy: "ExampleCDN", Error: ProxyErrDNSError, RCode: "NXDOMAIN", InfoCode: 6},
+			},
+			Canonical: `SomeReverseProxy;next-protocol=h2;received-status=503, ExampleCDN;error=dns_error;rcode="NXDOMAIN";info-code=6`,
+		},
+	}
+
+	for i, tcase := range tcases {
+		t.Run(fmt.Sprintf("%d", i), func(t *testing.T) {
+			actual, err := ParseProxyStatus(tcase.In)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if !reflect.DeepEqual(actual, tcase.Out) {
+				t.Fatalf("expected %+v, got %+v", tcase.Out, actual)
+			}
+			var canonical string
+			for i, ps := range actual {
+				if i > 0 {
+					canonical += ", "
+				}
+				canonical += ps.String()
+			}
+			if canonical != tcase.Canonical {
+				t.Fatalf("expected %s, got %s", tcase.Canonical, canonical)
+			}
+		})
+	}
+}
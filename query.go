@@ -0,0 +1,323 @@
+// Copyright 2026 Franklin "Snaipe" Mathieu.
+//
+// Use of this source code is governed by the MIT license that can be
+// found in the LICENSE file.
+
+package htutil
+
+import (
+	"encoding"
+	"fmt"
+	"net/url"
+	"reflect"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// QueryCodec maps query parameters to struct fields, and back.
+//
+// Struct fields are mapped according to their `url` tags: `url:"name"`
+// maps the field to the parameter name, and `url:"name,omitempty"` omits
+// it from encoded queries when it has its zero value. Fields without a
+// `url` tag, or tagged `url:"-"`, are ignored, except for embedded structs,
+// whose fields are mapped as if they were fields of the outer struct.
+//
+// Fields may be strings, booleans, numbers, or implement
+// encoding.TextUnmarshaler and encoding.TextMarshaler, like URL. Slices map
+// to repeated parameters, and pointers are allocated when their parameters
+// are present. time.Time fields use RFC 3339, or the layout of their
+// `layout` tag, as in `url:"since" layout:"2006-01-02"`. Fields of other
+// struct types map to nested parameters, named with Nesting.
+type QueryCodec struct {
+	// Nesting returns the parameter name of the field name of a struct
+	// mapped to the parameter prefix. If nil, DotNesting is used.
+	Nesting func(prefix, name string) string
+}
+
+// DotNesting names nested parameters like "prefix.name".
+func DotNesting(prefix, name string) string {
+	return prefix + "." + name
+}
+
+// BracketNesting names nested parameters like "prefix[name]".
+func BracketNesting(prefix, name string) string {
+	return prefix + "[" + name + "]"
+}
+
+// DecodeQuery populates the struct pointed to by v from the query
+// parameters values, with the default QueryCodec.
+func DecodeQuery(values url.Values, v any) error {
+	return QueryCodec{}.Decode(values, v)
+}
+
+// EncodeQuery returns the query parameters of the struct, or pointer to
+// struct, v, with the default QueryCodec.
+func EncodeQuery(v any) (url.Values, error) {
+	return QueryCodec{}.Encode(v)
+}
+
+var (
+	timeType          = reflect.TypeOf(time.Time{})
+	textMarshalerType = reflect.TypeOf((*encoding.TextMarshaler)(nil)).Elem()
+)
+
+func (c QueryCodec) nest(prefix, name string) string {
+	switch {
+	case prefix == "":
+		return name
+	case c.Nesting == nil:
+		return DotNesting(prefix, name)
+	default:
+		return c.Nesting(prefix, name)
+	}
+}
+
+// queryField returns the parameter name of field and its tag options, and
+// whether it is mapped at all.
+func queryField(field reflect.StructField) (name string, omitempty, ok bool) {
+	tag, ok := field.Tag.Lookup("url")
+	if !ok || tag == "-" {
+		return "", false, false
+	}
+	name, opts, _ := strings.Cut(tag, ",")
+	if name == "" {
+		name = field.Name
+	}
+	for _, opt := range strings.Split(opts, ",") {
+		if opt == "omitempty" {
+			omitempty = true
+		}
+	}
+	return name, omitempty, true
+}
+
+// isQueryScalar returns whether values of type t map to a single parameter
+// value.
+func isQueryScalar(t reflect.Type) bool {
+	if t.Implements(textMarshalerType) || reflect.PointerTo(t).Implements(textUnmarshalerType) {
+		return true
+	}
+	switch t.Kind() {
+	case reflect.String, reflect.Bool,
+		reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+		reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64,
+		reflect.Float32, reflect.Float64:
+		return true
+	}
+	return false
+}
+
+// isNestedStruct returns whether values of type t map to nested
+// parameters.
+func isNestedStruct(t reflect.Type) bool {
+	if t.Kind() == reflect.Pointer {
+		t = t.Elem()
+	}
+	return t.Kind() == reflect.Struct && !isQueryScalar(t)
+}
+
+// Decode populates the struct pointed to by v from the query parameters
+// values. Fields with no value in values are left untouched.
+func (c QueryCodec) Decode(values url.Values, v any) error {
+	rv := reflect.ValueOf(v)
+	if rv.Kind() != reflect.Pointer || rv.IsNil() || rv.Elem().Kind() != reflect.Struct {
+		return fmt.Errorf("decoding query: %T is not a non-nil pointer to struct", v)
+	}
+	_, err := c.decodeStruct(values, rv.Elem(), "")
+	return err
+}
+
+// decodeStruct populates the struct v from the parameters of values nested
+// under prefix, and returns whether any field was set.
+func (c QueryCodec) decodeStruct(values url.Values, v reflect.Value, prefix string) (bool, error) {
+	var set bool
+	typ := v.Type()
+	for i := 0; i < typ.NumField(); i++ {
+		field := typ.Field(i)
+		name, _, ok := queryField(field)
+		key := c.nest(prefix, name)
+		switch {
+		case !ok && field.Anonymous && isNestedStruct(field.Type):
+			key = prefix
+		case !ok || !field.IsExported():
+			continue
+		}
+		fset, err := c.decodeField(values, v.Field(i), key, field.Tag.Get("layout"))
+		if err != nil {
+			return set, err
+		}
+		set = set || fset
+	}
+	return set, nil
+}
+
+func (c QueryCodec) decodeField(values url.Values, v reflect.Value, key, layout string) (bool, error) {
+	switch {
+	case v.Kind() == reflect.Pointer:
+		if !v.IsNil() {
+			return c.decodeField(values, v.Elem(), key, layout)
+		}
+		if !v.CanSet() || !c.present(values, v.Type().Elem(), key) {
+			return false, nil
+		}
+		elem := reflect.New(v.Type().Elem())
+		set, err := c.decodeField(values, elem.Elem(), key, layout)
+		if set && err == nil {
+			v.Set(elem)
+		}
+		return set, err
+	case isNestedStruct(v.Type()):
+		return c.decodeStruct(values, v, key)
+	case !v.CanSet():
+		return false, nil
+	}
+
+	params := values[key]
+	if len(params) == 0 {
+		return false, nil
+	}
+	if v.Kind() == reflect.Slice && !isQueryScalar(v.Type()) {
+		out := reflect.MakeSlice(v.Type(), len(params), len(params))
+		for i, s := range params {
+			if err := decodeQueryValue(out.Index(i), s, layout); err != nil {
+				return false, fmt.Errorf("decoding query: %s: %w", key, err)
+			}
+		}
+		v.Set(out)
+		return true, nil
+	}
+	if err := decodeQueryValue(v, params[0], layout); err != nil {
+		return false, fmt.Errorf("decoding query: %s: %w", key, err)
+	}
+	return true, nil
+}
+
+// present returns whether values has parameters for a field of type t
+// mapped to key. Pointers are only allocated for present fields, which
+// keeps the decoding of recursive types finite.
+func (c QueryCodec) present(values url.Values, t reflect.Type, key string) bool {
+	if !isNestedStruct(t) {
+		return len(values[key]) > 0
+	}
+	if key == "" {
+		return len(values) > 0
+	}
+	// Nested parameter names start with what the nesting adds to key
+	// before the field name.
+	prefix, _, _ := strings.Cut(c.nest(key, "\x00"), "\x00")
+	for name := range values {
+		if strings.HasPrefix(name, prefix) {
+			return true
+		}
+	}
+	return false
+}
+
+func decodeQueryValue(v reflect.Value, s, layout string) error {
+	if v.Type() == timeType && layout != "" {
+		t, err := time.Parse(layout, s)
+		if err != nil {
+			return err
+		}
+		v.Set(reflect.ValueOf(t))
+		return nil
+	}
+	return bindValue(v, s)
+}
+
+// Encode returns the query parameters of the struct, or pointer to struct,
+// v.
+func (c QueryCodec) Encode(v any) (url.Values, error) {
+	rv := reflect.ValueOf(v)
+	if rv.Kind() == reflect.Pointer && !rv.IsNil() {
+		rv = rv.Elem()
+	}
+	if rv.Kind() != reflect.Struct {
+		return nil, fmt.Errorf("encoding query: %T is not a struct", v)
+	}
+	values := make(url.Values)
+	if err := c.encodeStruct(values, rv, ""); err != nil {
+		return nil, err
+	}
+	return values, nil
+}
+
+func (c QueryCodec) encodeStruct(values url.Values, v reflect.Value, prefix string) error {
+	typ := v.Type()
+	for i := 0; i < typ.NumField(); i++ {
+		field := typ.Field(i)
+		name, omitempty, ok := queryField(field)
+		key := c.nest(prefix, name)
+		switch {
+		case !ok && field.Anonymous && isNestedStruct(field.Type):
+			key = prefix
+		case !ok || !field.IsExported():
+			continue
+		}
+		fv := v.Field(i)
+		if omitempty && fv.IsZero() {
+			continue
+		}
+		if err := c.encodeField(values, fv, key, field.Tag.Get("layout")); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (c QueryCodec) encodeField(values url.Values, v reflect.Value, key, layout string) error {
+	if v.Kind() == reflect.Pointer {
+		if v.IsNil() {
+			return nil
+		}
+		v = v.Elem()
+	}
+	switch {
+	case isNestedStruct(v.Type()):
+		return c.encodeStruct(values, v, key)
+	case v.Kind() == reflect.Slice && !isQueryScalar(v.Type()):
+		for i := 0; i < v.Len(); i++ {
+			s, err := encodeQueryValue(v.Index(i), layout)
+			if err != nil {
+				return fmt.Errorf("encoding query: %s: %w", key, err)
+			}
+			values.Add(key, s)
+		}
+		return nil
+	}
+	s, err := encodeQueryValue(v, layout)
+	if err != nil {
+		return fmt.Errorf("encoding query: %s: %w", key, err)
+	}
+	values.Add(key, s)
+	return nil
+}
+
+func encodeQueryValue(v reflect.Value, layout string) (string, error) {
+	if v.Type() == timeType && layout != "" {
+		return v.Interface().(time.Time).Format(layout), nil
+	}
+	m, ok := v.Interface().(encoding.TextMarshaler)
+	if !ok && v.CanAddr() {
+		m, ok = v.Addr().Interface().(encoding.TextMarshaler)
+	}
+	if ok {
+		text, err := m.MarshalText()
+		return string(text), err
+	}
+	switch v.Kind() {
+	case reflect.String:
+		return v.String(), nil
+	case reflect.Bool:
+		return strconv.FormatBool(v.Bool()), nil
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		return strconv.FormatInt(v.Int(), 10), nil
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		return strconv.FormatUint(v.Uint(), 10), nil
+	case reflect.Float32, reflect.Float64:
+		return strconv.FormatFloat(v.Float(), 'g', -1, v.Type().Bits()), nil
+	default:
+		return "", fmt.Errorf("cannot encode values of type %v", v.Type())
+	}
+}
@@ -0,0 +1,209 @@
+// Copyright 2026 Franklin "Snaipe" Mathieu.
+//
+// Use of this source code is governed by the MIT license that can be
+// found in the LICENSE file.
+
+package htutil
+
+import (
+	"fmt"
+	"net/url"
+	"reflect"
+	"testing"
+	"time"
+)
+
+type queryPage struct {
+	Number int `url:"number,omitempty"`
+	Size   int `url:"size,omitempty"`
+}
+
+type queryNode struct {
+	Value int        `url:"value"`
+	Next  *queryNode `url:"next"`
+}
+
+type queryCommon struct {
+	Lang string `url:"lang,omitempty"`
+}
+
+type queryParams struct {
+	queryCommon
+	Search   string     `url:"q,omitempty"`
+	Tags     []string   `url:"tag,omitempty"`
+	Limit    *int       `url:"limit,omitempty"`
+	Exact    bool       `url:"exact,omitempty"`
+	Since    time.Time  `url:"since,omitempty" layout:"2006-01-02"`
+	Until    *time.Time `url:"until,omitempty"`
+	Callback URL        `url:"callback,omitempty"`
+	Page     queryPage  `url:"page"`
+	Filter   *queryPage `url:"filter,omitempty"`
+	Ignored  string
+	Skipped  string `url:"-"`
+}
+
+func TestDecodeQuery(t *testing.T) {
+	t.Parallel()
+
+	limit := 10
+	until := time.Date(2026, 1, 2, 3, 4, 5, 0, time.UTC)
+	callback, _ := url.Parse("https://example.com/cb")
+
+	tcases := []struct {
+		Codec  QueryCodec
+		Query  string
+		Expect queryParams
+		Err    bool
+	}{
+		{Query: "", Expect: queryParams{}},
+		{Query: "q=hello&tag=a&tag=b&limit=10&exact=true&lang=fr", Expect: queryParams{
+			queryCommon: queryCommon{Lang: "fr"},
+			Search:      "hello",
+			Tags:        []string{"a", "b"},
+			Limit:       &limit,
+			Exact:       true,
+		}},
+		{Query: "since=2026-01-02&until=2026-01-02T03:04:05Z", Expect: queryParams{
+			Since: time.Date(2026, 1, 2, 0, 0, 0, 0, time.UTC),
+			Until: &until,
+		}},
+		{Query: "callback=https%3A%2F%2Fexample.com%2Fcb", Expect: queryParams{Callback: URL{callback}}},
+		{Query: "page.number=2&page.size=50&filter.size=3", Expect: queryParams{
+			Page:   queryPage{Number: 2, Size: 50},
+			Filter: &queryPage{Size: 3},
+		}},
+		{Codec: QueryCodec{Nesting: BracketNesting}, Query: "page[number]=2&page.size=50", Expect: queryParams{
+			Page: queryPage{Number: 2},
+		}},
+		{Query: "Ignored=x&Skipped=y&-=z", Expect: queryParams{}},
+		{Query: "limit=ten", Err: true},
+		{Query: "since=2026-01-02T03:04:05Z", Err: true},
+		{Query: "page.number=x", Err: true},
+	}
+
+	for i, tcase := range tcases {
+		t.Run(fmt.Sprintf("%d", i), func(t *testing.T) {
+			values, err := url.ParseQuery(tcase.Query)
+			if err != nil {
+				t.Fatal(err)
+			}
+			var out queryParams
+			err = tcase.Codec.Decode(values, &out)
+			if (err != nil) != tcase.Err {
+				t.Fatalf("expected error %v, got %v", tcase.Err, err)
+			}
+			if err == nil && !reflect.DeepEqual(out, tcase.Expect) {
+				t.Fatalf("expected %+v, got %+v", tcase.Expect, out)
+			}
+		})
+	}
+}
+
+func TestEncodeQuery(t *testing.T) {
+	t.Parallel()
+
+	limit := 0
+	callback, _ := url.Parse("https://example.com/cb")
+
+	tcases := []struct {
+		Codec  QueryCodec
+		Value  interface{}
+		Expect string
+		Err    bool
+	}{
+		{Value: queryParams{}, Expect: ""},
+		{Value: &queryParams{
+			queryCommon: queryCommon{Lang: "fr"},
+			Search:      "a b",
+			Tags:        []string{"x", "y"},
+			Limit:       &limit,
+			Exact:       true,
+		}, Expect: "exact=true&lang=fr&limit=0&q=a+b&tag=x&tag=y"},
+		{Value: queryParams{
+			Since:    time.Date(2026, 1, 2, 3, 4, 5, 0, time.UTC),
+			Callback: URL{callback},
+			Page:     queryPage{Number: 2},
+		}, Expect: "callback=https%3A%2F%2Fexample.com%2Fcb&page.number=2&since=2026-01-02"},
+		{Codec: QueryCodec{Nesting: BracketNesting}, Value: queryParams{Filter: &queryPage{Size: 3}}, Expect: "filter%5Bsize%5D=3"},
+		{Value: struct {
+			N float64   `url:"n"`
+			U []uint8   `url:"u"`
+			T time.Time `url:"t"`
+		}{N: 1.5, U: []uint8{1, 2}, T: time.Date(2026, 1, 2, 3, 4, 5, 0, time.UTC)}, Expect: "n=1.5&t=2026-01-02T03%3A04%3A05Z&u=1&u=2"},
+		{Value: struct {
+			C chan int `url:"c"`
+		}{}, Err: true},
+		{Value: 42, Err: true},
+	}
+
+	for i, tcase := range tcases {
+		t.Run(fmt.Sprintf("%d", i), func(t *testing.T) {
+			values, err := tcase.Codec.Encode(tcase.Value)
+			if (err != nil) != tcase.Err {
+				t.Fatalf("expected error %v, got %v", tcase.Err, err)
+			}
+			if err != nil {
+				return
+			}
+			if out := values.Encode(); out != tcase.Expect {
+				t.Fatalf("expected %q, got %q", tcase.Expect, out)
+			}
+		})
+	}
+}
+
+func TestQueryRoundTrip(t *testing.T) {
+	t.Parallel()
+
+	limit := 5
+	in := queryParams{
+		Search: "x",
+		Tags:   []string{"a"},
+		Limit:  &limit,
+		Since:  time.Date(2026, 3, 4, 0, 0, 0, 0, time.UTC),
+		Page:   queryPage{Number: 1, Size: 20},
+		Filter: &queryPage{Number: 7},
+	}
+	values, err := EncodeQuery(in)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var out queryParams
+	if err := DecodeQuery(values, &out); err != nil {
+		t.Fatal(err)
+	}
+	if !reflect.DeepEqual(out, in) {
+		t.Fatalf("expected %+v, got %+v", in, out)
+	}
+	if err := DecodeQuery(values, out); err == nil {
+		t.Fatalf("expected error decoding into a non-pointer")
+	}
+}
+
+func TestDecodeQueryRecursive(t *testing.T) {
+	t.Parallel()
+
+	tcases := []struct {
+		Codec  QueryCodec
+		Query  string
+		Expect queryNode
+	}{
+		{Query: "", Expect: queryNode{}},
+		{Query: "value=1", Expect: queryNode{Value: 1}},
+		{Query: "value=1&next.next.value=3", Expect: queryNode{Value: 1, Next: &queryNode{Next: &queryNode{Value: 3}}}},
+		{Codec: QueryCodec{Nesting: BracketNesting}, Query: "next%5Bvalue%5D=2&next.value=4", Expect: queryNode{Next: &queryNode{Value: 2}}},
+	}
+
+	for i, tcase := range tcases {
+		t.Run(fmt.Sprintf("%d", i), func(t *testing.T) {
+			values, _ := url.ParseQuery(tcase.Query)
+			var got queryNode
+			if err := tcase.Codec.Decode(values, &got); err != nil {
+				t.Fatalf("expected no error, got %v", err)
+			}
+			if !reflect.DeepEqual(got, tcase.Expect) {
+				t.Fatalf("expected %+v, got %+v", tcase.Expect, got)
+			}
+		})
+	}
+}
@@ -0,0 +1,375 @@
+// Copyright 2026 Franklin "Snaipe" Mathieu.
+//
+// Use of this source code is governed by the MIT license that can be
+// found in the LICENSE file.
+
+package htutil
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"mime/multipart"
+	"net/http"
+	"net/textproto"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// ErrRangeNotSatisfiable is returned by ParseRange when none of the ranges
+// of a valid Range field overlap the representation.
+var ErrRangeNotSatisfiable = errors.New("range not satisfiable")
+
+// ParseRange parses the value of a Range header field of the bytes unit, as
+// per RFC 9110 §14.2, and returns its satisfiable ranges, in order, for a
+// representation of the specified length.
+//
+// Ranges past the end of the representation are clipped to it, and suffix
+// ranges, like "-500", select the last bytes of the representation. If the
+// field is valid but no range is satisfiable, ParseRange returns
+// ErrRangeNotSatisfiable; other errors must make the field ignored.
+func ParseRange(value string, length int64) ([]ContentRange, error) {
+	return ParseRanges(value, "bytes", length)
+}
+
+// ParseRanges is like ParseRange, for any range unit whose positions are
+// integers, like the items of a collection. Range units are
+// case-insensitive; the returned ranges have the specified unit.
+func ParseRanges(value, unit string, length int64) ([]ContentRange, error) {
+	u, set, err := ParseRangeUnit(value)
+	if err != nil {
+		return nil, err
+	}
+	if !strings.EqualFold(u, unit) {
+		return nil, fmt.Errorf("parsing range: unsupported range unit %q", u)
+	}
+	var ranges []ContentRange
+	for _, s := range set {
+		spec, err := ParseRangeSpec(s)
+		if err != nil {
+			return nil, err
+		}
+		if first, last, ok := spec.Resolve(length); ok {
+			ranges = append(ranges, ContentRange{Unit: unit, First: first, Last: last, Length: length})
+		}
+	}
+	if len(ranges) == 0 {
+		return nil, ErrRangeNotSatisfiable
+	}
+	return ranges, nil
+}
+
+// ParseRangeUnit parses the value of a Range header field, and returns its
+// lowercased range unit, and the elements of its range set, to be parsed
+// according to the unit; see ParseRangeSpec for units whose positions are
+// integers.
+func ParseRangeUnit(value string) (unit string, set []string, err error) {
+	unit, rest, ok := strings.Cut(trimOWS(value), "=")
+	if !ok || !IsToken(unit) {
+		return "", nil, fmt.Errorf("parsing range: missing range unit")
+	}
+	for _, spec := range strings.Split(rest, ",") {
+		// Empty list elements are allowed, but not an empty set.
+		if spec = trimOWS(spec); spec != "" {
+			set = append(set, spec)
+		}
+	}
+	if len(set) == 0 {
+		return "", nil, fmt.Errorf("parsing range: empty range set")
+	}
+	return strings.ToLower(unit), set, nil
+}
+
+// RangeSpec is an element of the range set of a Range header field, for
+// range units whose positions are integers, as per RFC 9110 §14.1.1. It is
+// either an IntRange or a SuffixRange.
+type RangeSpec interface {
+	// Resolve returns the positions of the first and last units selected
+	// in a representation of the specified length, clipped to it, or
+	// ok=false if the range is not satisfiable.
+	Resolve(length int64) (first, last int64, ok bool)
+
+	// String returns the range spec as formatted in a Range header field.
+	String() string
+}
+
+// IntRange is a range spec selecting the units from First to Last,
+// inclusive. Last is -1 for ranges extending to the end of the
+// representation, like "10-".
+type IntRange struct {
+	First, Last int64
+}
+
+func (r IntRange) Resolve(length int64) (first, last int64, ok bool) {
+	if r.First >= length {
+		return 0, 0, false
+	}
+	last = length - 1
+	if r.Last >= 0 {
+		last = minInt64(r.Last, last)
+	}
+	return r.First, last, true
+}
+
+func (r IntRange) String() string {
+	if r.Last < 0 {
+		return strconv.FormatInt(r.First, 10) + "-"
+	}
+	return strconv.FormatInt(r.First, 10) + "-" + strconv.FormatInt(r.Last, 10)
+}
+
+// SuffixRange is a range spec selecting the specified number of units at
+// the end of the representation, like "-500".
+type SuffixRange int64
+
+func (r SuffixRange) Resolve(length int64) (first, last int64, ok bool) {
+	if r == 0 || length == 0 {
+		return 0, 0, false
+	}
+	return maxInt64(0, length-int64(r)), length - 1, true
+}
+
+func (r SuffixRange) String() string {
+	return "-" + strconv.FormatInt(int64(r), 10)
+}
+
+// ParseRangeSpec parses an int-range, like "0-499" or "500-", or a
+// suffix-range, like "-500".
+func ParseRangeSpec(spec string) (RangeSpec, error) {
+	first, last, ok := strings.Cut(spec, "-")
+	if !ok {
+		return nil, fmt.Errorf("parsing range: invalid range %q", spec)
+	}
+	if first == "" {
+		n, err := parseRangePos(last)
+		if err != nil || last == "" {
+			return nil, fmt.Errorf("parsing range: invalid suffix range %q", spec)
+		}
+		return SuffixRange(n), nil
+	}
+	r := IntRange{Last: -1}
+	var err error
+	if r.First, err = parseRangePos(first); err != nil {
+		return nil, fmt.Errorf("parsing range: invalid range %q", spec)
+	}
+	if last != "" {
+		if r.Last, err = parseRangePos(last); err != nil || r.Last < r.First {
+			return nil, fmt.Errorf("parsing range: invalid range %q", spec)
+		}
+	}
+	return r, nil
+}
+
+// SetContentRange sets the Content-Range header field of h to cr, and
+// advertises its unit in Accept-Ranges if h does not advertise any, for
+// the responses of resources with ranges of other units than bytes, which
+// ServeRanges does not handle. Satisfied ranges must be sent with a 206
+// Partial Content status, and unsatisfied ones with 416 Range Not
+// Satisfiable.
+func SetContentRange(h http.Header, cr ContentRange) {
+	h.Set("Content-Range", cr.String())
+	if h.Get("Accept-Ranges") == "" {
+		SetAcceptRanges(h, cr.Unit)
+	}
+}
+
+func minInt64(a, b int64) int64 {
+	if a < b {
+		return a
+	}
+	return b
+}
+
+func maxInt64(a, b int64) int64 {
+	if a > b {
+		return a
+	}
+	return b
+}
+
+// ServeRanges replies to req with the content of a representation of the
+// specified length, honoring the Range field of GET requests.
+//
+// A single range is served as a 206 Partial Content response with a
+// Content-Range field, and multiple ranges as a multipart/byteranges body,
+// whose parts have the Content-Type of the representation, as set in the
+// header of w beforehand. Unsatisfiable ranges are answered with 416 Range
+// Not Satisfiable. Invalid Range fields, and ranges that add up to more than
+// the representation, are ignored, and the content is served in full.
+//
+// If-Range is evaluated against the ETag and Last-Modified fields set in the
+// header of w, if any. Other preconditions must be evaluated beforehand;
+// see EvaluateConditionals.
+func ServeRanges(w http.ResponseWriter, req *http.Request, content io.ReaderAt, length int64) {
+	h := w.Header()
+	SetAcceptRanges(h, "bytes")
+
+	var ranges []ContentRange
+	if rng := req.Header.Get("Range"); rng != "" && req.Method == http.MethodGet && ifRangeHolds(req, h) {
+		var err error
+		ranges, err = ParseRange(rng, length)
+		switch {
+		case errors.Is(err, ErrRangeNotSatisfiable):
+			h.Set("Content-Range", ContentRange{Unit: "bytes", First: -1, Last: -1, Length: length}.String())
+			RespondError(w, req, NewProblem(http.StatusRequestedRangeNotSatisfiable,
+				"None of the requested ranges overlap the content."))
+			return
+		case err != nil:
+			ranges = nil
+		}
+		var total int64
+		for _, cr := range ranges {
+			total += cr.Size()
+		}
+		if total > length {
+			ranges = nil
+		}
+	}
+
+	switch len(ranges) {
+	case 0:
+		h.Set("Content-Length", strconv.FormatInt(length, 10))
+		w.WriteHeader(http.StatusOK)
+		if req.Method != http.MethodHead {
+			io.Copy(w, io.NewSectionReader(content, 0, length))
+		}
+	case 1:
+		cr := ranges[0]
+		h.Set("Content-Range", cr.String())
+		h.Set("Content-Length", strconv.FormatInt(cr.Size(), 10))
+		w.WriteHeader(http.StatusPartialContent)
+		io.Copy(w, io.NewSectionReader(content, cr.First, cr.Size()))
+	default:
+		ctype := h.Get("Content-Type")
+		mw := multipart.NewWriter(w)
+		h.Set("Content-Type", "multipart/byteranges; boundary="+mw.Boundary())
+		h.Set("Content-Length", strconv.FormatInt(byteRangesSize(ranges, ctype, mw.Boundary()), 10))
+		w.WriteHeader(http.StatusPartialContent)
+		for _, cr := range ranges {
+			part, err := mw.CreatePart(byteRangeHeader(cr, ctype))
+			if err != nil {
+				return
+			}
+			if _, err := io.Copy(part, io.NewSectionReader(content, cr.First, cr.Size())); err != nil {
+				return
+			}
+		}
+		mw.Close()
+	}
+}
+
+// IfRange is the condition of an If-Range header field, as per RFC 9110
+// §13.1.5: an entity tag or a date validating the representation of which
+// the client requests ranges.
+type IfRange struct {
+	// ETag is the entity tag of the representation, or zero for a date
+	// condition.
+	ETag ETag
+
+	// Date is the last modification date of the representation, or zero
+	// for an entity tag condition.
+	Date time.Time
+}
+
+// ParseIfRange parses the value of an If-Range header field, which is an
+// entity tag if it starts with a double quote or "W/", and an HTTP-date
+// otherwise.
+func ParseIfRange(value string) (IfRange, error) {
+	value = strings.TrimSpace(value)
+	if strings.HasPrefix(value, `"`) || strings.HasPrefix(value, "W/") {
+		etag, err := ParseETag(value)
+		if err != nil {
+			return IfRange{}, fmt.Errorf("parsing if-range: %w", err)
+		}
+		return IfRange{ETag: etag}, nil
+	}
+	date, err := http.ParseTime(value)
+	if err != nil {
+		return IfRange{}, fmt.Errorf("parsing if-range: %w", err)
+	}
+	return IfRange{Date: date}, nil
+}
+
+// IsZero returns whether c has neither an entity tag nor a date.
+func (c IfRange) IsZero() bool {
+	return c.ETag.IsZero() && c.Date.IsZero()
+}
+
+// String returns c as formatted in an If-Range header field.
+func (c IfRange) String() string {
+	if !c.ETag.IsZero() {
+		return c.ETag.String()
+	}
+	if c.Date.IsZero() {
+		return ""
+	}
+	return c.Date.UTC().Format(http.TimeFormat)
+}
+
+// Matches returns whether c validates the current representation, with
+// the specified entity tag and last modification date, either of which may
+// be zero. Entity tags must match with the strong comparison function, so
+// weak entity tags never match, and dates must be the exact modification
+// date of the representation.
+func (c IfRange) Matches(etag ETag, lastModified time.Time) bool {
+	if !c.ETag.IsZero() {
+		return c.ETag.StrongMatch(etag)
+	}
+	return !c.Date.IsZero() && !lastModified.IsZero() && lastModified.Truncate(time.Second).Equal(c.Date)
+}
+
+// EvaluateIfRange returns whether the Range field of req must be honored,
+// as per RFC 9110 §13.1.5, given the entity tag and last modification date
+// of the current representation: either req has no If-Range field, or its
+// condition matches the representation. Otherwise, which includes invalid
+// If-Range fields, the representation must be served in full, with a 200
+// OK response.
+//
+// If-Range is only meaningful along with a Range field, and must be
+// evaluated after the other preconditions; see EvaluateConditionals.
+func EvaluateIfRange(req *http.Request, etag ETag, lastModified time.Time) bool {
+	value := req.Header.Get("If-Range")
+	if value == "" {
+		return true
+	}
+	c, err := ParseIfRange(value)
+	return err == nil && c.Matches(etag, lastModified)
+}
+
+// ifRangeHolds evaluates the If-Range field of req against the validators
+// in h.
+func ifRangeHolds(req *http.Request, h http.Header) bool {
+	etag, _ := ParseETag(h.Get("ETag"))
+	modtime, _ := http.ParseTime(h.Get("Last-Modified"))
+	return EvaluateIfRange(req, etag, modtime)
+}
+
+func byteRangeHeader(cr ContentRange, ctype string) textproto.MIMEHeader {
+	hdr := textproto.MIMEHeader{"Content-Range": {cr.String()}}
+	if ctype != "" {
+		hdr.Set("Content-Type", ctype)
+	}
+	return hdr
+}
+
+// byteRangesSize returns the size of the multipart/byteranges body of the
+// specified ranges.
+func byteRangesSize(ranges []ContentRange, ctype, boundary string) int64 {
+	var cw countingWriter
+	mw := multipart.NewWriter(&cw)
+	mw.SetBoundary(boundary)
+	for _, cr := range ranges {
+		mw.CreatePart(byteRangeHeader(cr, ctype))
+		cw += countingWriter(cr.Size())
+	}
+	mw.Close()
+	return int64(cw)
+}
+
+type countingWriter int64
+
+func (w *countingWriter) Write(p []byte) (int, error) {
+	*w += countingWriter(len(p))
+	return len(p), nil
+}
@@ -0,0 +1,361 @@
+// Copyright 2026 Franklin "Snaipe" Mathieu.
+//
+// Use of this source code is governed by the MIT license that can be
+// found in the LICENSE file.
+
+package htutil
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestParseRange(t *testing.T) {
+	t.Parallel()
+
+	tcases := []struct {
+		Value  string
+		Length int64
+		Expect string
+		Err    error
+	}{
+		{Value: "bytes=0-499", Length: 10000, Expect: "bytes 0-499/10000"},
+		{Value: "bytes=500-999", Length: 10000, Expect: "bytes 500-999/10000"},
+		{Value: "bytes=-500", Length: 10000, Expect: "bytes 9500-9999/10000"},
+		{Value: "bytes=9500-", Length: 10000, Expect: "bytes 9500-9999/10000"},
+		{Value: "bytes=0-0,-1", Length: 10000, Expect: "bytes 0-0/10000, bytes 9999-9999/10000"},
+		{Value: "Bytes = 0-99999", Length: 10000, Err: errors.New("")},
+		{Value: "BYTES=0-99999", Length: 10000, Expect: "bytes 0-9999/10000"},
+		{Value: "bytes=-99999", Length: 10000, Expect: "bytes 0-9999/10000"},
+		{Value: "bytes= 0-1 ,, 5-6", Length: 10, Expect: "bytes 0-1/10, bytes 5-6/10"},
+		{Value: "bytes=20-30, 2-3", Length: 10, Expect: "bytes 2-3/10"},
+		{Value: "bytes=20-30", Length: 10, Err: ErrRangeNotSatisfiable},
+		{Value: "bytes=-0", Length: 10, Err: ErrRangeNotSatisfiable},
+		{Value: "bytes=-5", Length: 0, Err: ErrRangeNotSatisfiable},
+		{Value: "bytes=5-2", Length: 10, Err: errors.New("")},
+		{Value: "bytes=-", Length: 10, Err: errors.New("")},
+		{Value: "bytes=a-b", Length: 10, Err: errors.New("")},
+		{Value: "bytes=", Length: 10, Err: errors.New("")},
+		{Value: "bytes=,", Length: 10, Err: errors.New("")},
+		{Value: "items=0-1", Length: 10, Err: errors.New("")},
+	}
+
+	for i, tcase := range tcases {
+		t.Run(fmt.Sprintf("%d", i), func(t *testing.T) {
+			ranges, err := ParseRange(tcase.Value, tcase.Length)
+			if tcase.Err != nil {
+				if err == nil {
+					t.Fatalf("expected error, got %v", ranges)
+				}
+				if tcase.Err == ErrRangeNotSatisfiable && !errors.Is(err, ErrRangeNotSatisfiable) {
+					t.Fatalf("expected %v, got %v", tcase.Err, err)
+				}
+				if tcase.Err != ErrRangeNotSatisfiable && errors.Is(err, ErrRangeNotSatisfiable) {
+					t.Fatalf("expected syntax error, got %v", err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatal(err)
+			}
+			var out []string
+			for _, cr := range ranges {
+				out = append(out, cr.String())
+			}
+			if strings.Join(out, ", ") != tcase.Expect {
+				t.Fatalf("expected %q, got %q", tcase.Expect, strings.Join(out, ", "))
+			}
+		})
+	}
+}
+
+func TestParseRanges(t *testing.T) {
+	t.Parallel()
+
+	tcases := []struct {
+		Value  string
+		Unit   string
+		Length int64
+		Expect string
+		Err    bool
+	}{
+		{Value: "items=0-24", Unit: "items", Length: 100, Expect: "items 0-24/100"},
+		{Value: "Items=90-", Unit: "items", Length: 100, Expect: "items 90-99/100"},
+		{Value: "items=-10, 0-4", Unit: "items", Length: 100, Expect: "items 90-99/100, items 0-4/100"},
+		{Value: "items=0-24", Unit: "Items", Length: 100, Expect: "Items 0-24/100"},
+		{Value: "seconds=30-59", Unit: "seconds", Length: 45, Expect: "seconds 30-44/45"},
+		{Value: "items=100-", Unit: "items", Length: 100, Err: true},
+		{Value: "bytes=0-24", Unit: "items", Length: 100, Err: true},
+		{Value: "items=24-0", Unit: "items", Length: 100, Err: true},
+	}
+
+	for i, tcase := range tcases {
+		t.Run(fmt.Sprintf("%d", i), func(t *testing.T) {
+			ranges, err := ParseRanges(tcase.Value, tcase.Unit, tcase.Length)
+			if (err != nil) != tcase.Err {
+				t.Fatalf("expected error %v, got %v", tcase.Err, err)
+			}
+			var out []string
+			for _, cr := range ranges {
+				out = append(out, cr.String())
+			}
+			if strings.Join(out, ", ") != tcase.Expect {
+				t.Fatalf("expected %q, got %q", tcase.Expect, strings.Join(out, ", "))
+			}
+		})
+	}
+}
+
+func TestParseRangeUnit(t *testing.T) {
+	t.Parallel()
+
+	tcases := []struct {
+		Value string
+		Unit  string
+		Set   []string
+		Err   bool
+	}{
+		{Value: "items=0-24", Unit: "items", Set: []string{"0-24"}},
+		{Value: "Cursor=abc, ,def", Unit: "cursor", Set: []string{"abc", "def"}},
+		{Value: "items=", Err: true},
+		{Value: "=0-24", Err: true},
+		{Value: "0-24", Err: true},
+	}
+
+	for i, tcase := range tcases {
+		t.Run(fmt.Sprintf("%d", i), func(t *testing.T) {
+			unit, set, err := ParseRangeUnit(tcase.Value)
+			if (err != nil) != tcase.Err {
+				t.Fatalf("expected error %v, got %v", tcase.Err, err)
+			}
+			if unit != tcase.Unit || fmt.Sprint(set) != fmt.Sprint(tcase.Set) {
+				t.Fatalf("expected %q %q, got %q %q", tcase.Unit, tcase.Set, unit, set)
+			}
+		})
+	}
+}
+
+func TestParseRangeSpec(t *testing.T) {
+	t.Parallel()
+
+	tcases := []struct {
+		Spec     string
+		Expected RangeSpec
+		Err      bool
+	}{
+		{Spec: "0-24", Expected: IntRange{First: 0, Last: 24}},
+		{Spec: "25-", Expected: IntRange{First: 25, Last: -1}},
+		{Spec: "-10", Expected: SuffixRange(10)},
+		{Spec: "-", Err: true},
+		{Spec: "10", Err: true},
+		{Spec: "+1-2", Err: true},
+	}
+
+	for i, tcase := range tcases {
+		t.Run(fmt.Sprintf("%d", i), func(t *testing.T) {
+			spec, err := ParseRangeSpec(tcase.Spec)
+			if (err != nil) != tcase.Err {
+				t.Fatalf("expected error %v, got %v", tcase.Err, err)
+			}
+			if spec != tcase.Expected {
+				t.Fatalf("expected %#v, got %#v", tcase.Expected, spec)
+			}
+			if spec != nil && spec.String() != tcase.Spec {
+				t.Fatalf("expected %q, got %q", tcase.Spec, spec.String())
+			}
+		})
+	}
+}
+
+func TestSetContentRange(t *testing.T) {
+	t.Parallel()
+
+	h := http.Header{}
+	SetContentRange(h, ContentRange{Unit: "items", First: 0, Last: 24, Length: 100})
+	if cr := h.Get("Content-Range"); cr != "items 0-24/100" {
+		t.Fatalf("expected Content-Range %q, got %q", "items 0-24/100", cr)
+	}
+	if !AcceptsRanges(h, "items") {
+		t.Fatalf("expected Accept-Ranges to advertise items, got %q", h.Get("Accept-Ranges"))
+	}
+
+	h = http.Header{"Accept-Ranges": {"bytes, items"}}
+	SetContentRange(h, ContentRange{Unit: "items", First: -1, Last: -1, Length: 100})
+	if cr := h.Get("Content-Range"); cr != "items */100" {
+		t.Fatalf("expected Content-Range %q, got %q", "items */100", cr)
+	}
+	if ar := h.Get("Accept-Ranges"); ar != "bytes, items" {
+		t.Fatalf("expected Accept-Ranges to be left untouched, got %q", ar)
+	}
+}
+
+func TestServeRanges(t *testing.T) {
+	t.Parallel()
+
+	const content = "hello, world"
+
+	tcases := []struct {
+		Method string
+		Header http.Header
+		Status int
+		Parts  []string
+		Body   string
+	}{
+		{Header: http.Header{}, Status: 200, Body: content},
+		{Method: "HEAD", Header: http.Header{"Range": {"bytes=0-4"}}, Status: 200, Body: ""},
+		{Header: http.Header{"Range": {"bytes=0-4"}}, Status: 206, Parts: []string{"bytes 0-4/12 hello"}},
+		{Header: http.Header{"Range": {"bytes=0-4,-5"}}, Status: 206, Parts: []string{"bytes 0-4/12 hello", "bytes 7-11/12 world"}},
+		{Header: http.Header{"Range": {"bytes=0-,0-"}}, Status: 200, Body: content},
+		{Header: http.Header{"Range": {"bytes=20-"}}, Status: 416},
+		{Header: http.Header{"Range": {"bytes=x"}}, Status: 200, Body: content},
+		{Header: http.Header{"Range": {"bytes=0-4"}, "If-Range": {`"v1"`}}, Status: 206, Parts: []string{"bytes 0-4/12 hello"}},
+		{Header: http.Header{"Range": {"bytes=0-4"}, "If-Range": {`"v0"`}}, Status: 200, Body: content},
+		{Header: http.Header{"Range": {"bytes=0-4"}, "If-Range": {`W/"v1"`}}, Status: 200, Body: content},
+		{Header: http.Header{"Range": {"bytes=0-4"}, "If-Range": {"Fri, 02 Jan 2026 03:04:05 GMT"}}, Status: 206, Parts: []string{"bytes 0-4/12 hello"}},
+		{Header: http.Header{"Range": {"bytes=0-4"}, "If-Range": {"Fri, 02 Jan 2026 03:04:04 GMT"}}, Status: 200, Body: content},
+	}
+
+	for i, tcase := range tcases {
+		t.Run(fmt.Sprintf("%d", i), func(t *testing.T) {
+			method := tcase.Method
+			if method == "" {
+				method = "GET"
+			}
+			req := httptest.NewRequest(method, "/", nil)
+			req.Header = tcase.Header
+			w := httptest.NewRecorder()
+			w.Header().Set("Content-Type", "text/plain")
+			w.Header().Set("ETag", `"v1"`)
+			w.Header().Set("Last-Modified", "Fri, 02 Jan 2026 03:04:05 GMT")
+			ServeRanges(w, req, strings.NewReader(content), int64(len(content)))
+
+			resp := w.Result()
+			if resp.StatusCode != tcase.Status {
+				t.Fatalf("expected status %d, got %d", tcase.Status, resp.StatusCode)
+			}
+			if resp.Header.Get("Accept-Ranges") != "bytes" {
+				t.Fatalf("expected Accept-Ranges: bytes, got %q", resp.Header.Get("Accept-Ranges"))
+			}
+			if n := strconv.Itoa(w.Body.Len()); tcase.Status != 416 && method == "GET" && resp.Header.Get("Content-Length") != n {
+				t.Fatalf("expected Content-Length %s, got %s", n, resp.Header.Get("Content-Length"))
+			}
+
+			switch tcase.Status {
+			case 416:
+				if cr := resp.Header.Get("Content-Range"); cr != "bytes */12" {
+					t.Fatalf("expected Content-Range %q, got %q", "bytes */12", cr)
+				}
+			case 206:
+				r, err := NewByteRangesReader(resp)
+				if err != nil {
+					t.Fatal(err)
+				}
+				var parts []string
+				for {
+					cr, body, err := r.Next()
+					if err == io.EOF {
+						break
+					}
+					if err != nil {
+						t.Fatal(err)
+					}
+					data, err := io.ReadAll(body)
+					if err != nil {
+						t.Fatal(err)
+					}
+					parts = append(parts, cr.String()+" "+string(data))
+				}
+				if fmt.Sprint(parts) != fmt.Sprint(tcase.Parts) {
+					t.Fatalf("expected %q, got %q", tcase.Parts, parts)
+				}
+			default:
+				if w.Body.String() != tcase.Body {
+					t.Fatalf("expected %q, got %q", tcase.Body, w.Body.String())
+				}
+			}
+		})
+	}
+}
+
+func TestParseIfRange(t *testing.T) {
+	t.Parallel()
+
+	date := time.Date(2026, 1, 2, 3, 4, 5, 0, time.UTC)
+
+	tcases := []struct {
+		Value  string
+		Expect IfRange
+		Err    bool
+	}{
+		{Value: `"v1"`, Expect: IfRange{ETag: ETag{Tag: "v1"}}},
+		{Value: `W/"v1"`, Expect: IfRange{ETag: ETag{Tag: "v1", Weak: true}}},
+		{Value: "Fri, 02 Jan 2026 03:04:05 GMT", Expect: IfRange{Date: date}},
+		{Value: "Friday, 02-Jan-26 03:04:05 GMT", Expect: IfRange{Date: date}},
+		{Value: `"v1`, Err: true},
+		{Value: "yesterday", Err: true},
+		{Value: "", Err: true},
+	}
+
+	for i, tcase := range tcases {
+		t.Run(fmt.Sprintf("%d", i), func(t *testing.T) {
+			c, err := ParseIfRange(tcase.Value)
+			if (err != nil) != tcase.Err {
+				t.Fatalf("expected error=%v, got %v", tcase.Err, err)
+			}
+			if c.ETag != tcase.Expect.ETag || !c.Date.Equal(tcase.Expect.Date) {
+				t.Fatalf("expected %+v, got %+v", tcase.Expect, c)
+			}
+			if err != nil {
+				return
+			}
+			if rt, err := ParseIfRange(c.String()); err != nil || rt.ETag != c.ETag || !rt.Date.Equal(c.Date) {
+				t.Fatalf("expected %s to round-trip, got %+v (%v)", c, rt, err)
+			}
+		})
+	}
+}
+
+func TestEvaluateIfRange(t *testing.T) {
+	t.Parallel()
+
+	modtime := time.Date(2026, 1, 2, 3, 4, 5, 600, time.UTC)
+	etag := ETag{Tag: "v1"}
+
+	tcases := []struct {
+		IfRange      string
+		ETag         ETag
+		LastModified time.Time
+		Expect       bool
+	}{
+		{IfRange: "", ETag: etag, LastModified: modtime, Expect: true},
+		{IfRange: `"v1"`, ETag: etag, LastModified: modtime, Expect: true},
+		{IfRange: `"v0"`, ETag: etag, LastModified: modtime, Expect: false},
+		{IfRange: `W/"v1"`, ETag: etag, LastModified: modtime, Expect: false},
+		{IfRange: `"v1"`, ETag: ETag{Tag: "v1", Weak: true}, Expect: false},
+		{IfRange: `"v1"`, LastModified: modtime, Expect: false},
+		{IfRange: "Fri, 02 Jan 2026 03:04:05 GMT", ETag: etag, LastModified: modtime, Expect: true},
+		{IfRange: "Fri, 02 Jan 2026 03:04:06 GMT", ETag: etag, LastModified: modtime, Expect: false},
+		{IfRange: "Fri, 02 Jan 2026 03:04:05 GMT", ETag: etag, Expect: false},
+		{IfRange: "garbage", ETag: etag, LastModified: modtime, Expect: false},
+	}
+
+	for i, tcase := range tcases {
+		t.Run(fmt.Sprintf("%d", i), func(t *testing.T) {
+			req := httptest.NewRequest("GET", "/", nil)
+			req.Header.Set("Range", "bytes=0-4")
+			if tcase.IfRange != "" {
+				req.Header.Set("If-Range", tcase.IfRange)
+			}
+			if got := EvaluateIfRange(req, tcase.ETag, tcase.LastModified); got != tcase.Expect {
+				t.Fatalf("expected %v, got %v", tcase.Expect, got)
+			}
+		})
+	}
+}
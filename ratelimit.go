@@ -0,0 +1,121 @@
+// Copyright 2026 Franklin "Snaipe" Mathieu.
+//
+// Use of this source code is governed by the MIT license that can be
+// found in the LICENSE file.
+
+package htutil
+
+import (
+	"fmt"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"snai.pe/go-htutil/sfv"
+)
+
+// ParseRetryAfter parses a Retry-After header value, as per RFC 9110
+// §10.2.3, which is either a number of seconds or an HTTP-date, and
+// returns the delay that it requests at now. Dates in the past request no
+// delay.
+func ParseRetryAfter(value string, now time.Time) (time.Duration, error) {
+	value = trimOWS(value)
+	if value != "" && isDigit(value[0]) {
+		secs, err := strconv.ParseUint(value, 10, 32)
+		if err != nil {
+			return 0, fmt.Errorf("parsing retry-after: %w", err)
+		}
+		return time.Duration(secs) * time.Second, nil
+	}
+	date, err := http.ParseTime(value)
+	if err != nil {
+		return 0, fmt.Errorf("parsing retry-after: %w", err)
+	}
+	return maxDuration(0, date.Sub(now)), nil
+}
+
+// RateLimit is the state of the quota of a client, as advertised by the
+// RateLimit header fields of draft-ietf-httpapi-ratelimit-headers.
+type RateLimit struct {
+	// Limit is the number of requests allowed in the current window.
+	Limit int64
+
+	// Remaining is the number of requests left in the current window.
+	Remaining int64
+
+	// Reset is the delay until the quota is reset.
+	Reset time.Duration
+}
+
+// ParseRateLimit returns the quota advertised by the header fields of h,
+// either in the combined RateLimit field, as a dictionary with the limit,
+// remaining, and reset keys, or in the separate RateLimit-Limit,
+// RateLimit-Remaining, and RateLimit-Reset fields. Only the first quota
+// policy of the separate fields is considered.
+//
+// It returns ErrNoHeader if h has none of these fields.
+func ParseRateLimit(h http.Header) (RateLimit, error) {
+	if fields := h.Values("RateLimit"); len(fields) > 0 {
+		dict, err := sfv.ParseDictionary(strings.Join(fields, ", "))
+		if err != nil {
+			return RateLimit{}, fmt.Errorf("parsing ratelimit: %w", err)
+		}
+		var values [3]int64
+		for i, key := range []string{"limit", "remaining", "reset"} {
+			member, ok := dict.Get(key)
+			if !ok {
+				return RateLimit{}, fmt.Errorf("parsing ratelimit: missing %s", key)
+			}
+			item, ok := member.(sfv.Item)
+			n, isInt := item.Value.(int64)
+			if !ok || !isInt || n < 0 {
+				return RateLimit{}, fmt.Errorf("parsing ratelimit: %s is not a non-negative integer", key)
+			}
+			values[i] = n
+		}
+		return newRateLimit(values), nil
+	}
+
+	var (
+		values [3]int64
+		found  bool
+	)
+	for i, name := range []string{"RateLimit-Limit", "RateLimit-Remaining", "RateLimit-Reset"} {
+		members := listMembers(h.Values(name))
+		if len(members) == 0 {
+			continue
+		}
+		found = true
+		value, _, _ := strings.Cut(members[0], ";")
+		n, err := strconv.ParseInt(trimOWS(value), 10, 64)
+		if err != nil || n < 0 {
+			return RateLimit{}, fmt.Errorf("parsing %s: %q is not a non-negative integer", strings.ToLower(name), value)
+		}
+		values[i] = n
+	}
+	if !found {
+		return RateLimit{}, ErrNoHeader
+	}
+	return newRateLimit(values), nil
+}
+
+// newRateLimit returns the quota with the limit, remaining, and reset
+// values.
+func newRateLimit(values [3]int64) RateLimit {
+	return RateLimit{Limit: values[0], Remaining: values[1], Reset: time.Duration(values[2]) * time.Second}
+}
+
+// SetRateLimit sets the RateLimit-Limit, RateLimit-Remaining, and
+// RateLimit-Reset header fields of h to rl. The combined RateLimit field
+// can be set to rl.String() instead.
+func SetRateLimit(h http.Header, rl RateLimit) {
+	h.Set("RateLimit-Limit", strconv.FormatInt(rl.Limit, 10))
+	h.Set("RateLimit-Remaining", strconv.FormatInt(rl.Remaining, 10))
+	h.Set("RateLimit-Reset", strconv.FormatInt(int64(rl.Reset/time.Second), 10))
+}
+
+// String returns the combined RateLimit header value for rl.
+func (rl RateLimit) String() string {
+	return fmt.Sprintf("limit=%d, remaining=%d, reset=%d", rl.Limit, rl.Remaining, int64(rl.Reset/time.Second))
+}
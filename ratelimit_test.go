@@ -0,0 +1,109 @@
+// Copyright 2026 Franklin "Snaipe" Mathieu.
+//
+// Use of this source code is governed by the MIT license that can be
+// found in the LICENSE file.
+
+package htutil
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"testing"
+	"time"
+)
+
+func TestParseRetryAfter(t *testing.T) {
+	t.Parallel()
+
+	now := time.Date(2026, 10, 17, 12, 0, 0, 0, time.UTC)
+
+	tcases := []struct {
+		Value  string
+		Expect time.Duration
+		Err    bool
+	}{
+		{Value: "120", Expect: 2 * time.Minute},
+		{Value: " 0 ", Expect: 0},
+		{Value: "Sat, 17 Oct 2026 12:05:00 GMT", Expect: 5 * time.Minute},
+		{Value: "Saturday, 17-Oct-26 12:00:30 GMT", Expect: 30 * time.Second},
+		{Value: "Sat, 17 Oct 2026 11:00:00 GMT", Expect: 0},
+		{Value: "-1", Err: true},
+		{Value: "1.5", Err: true},
+		{Value: "99999999999", Err: true},
+		{Value: "soon", Err: true},
+		{Value: "", Err: true},
+	}
+
+	for i, tcase := range tcases {
+		t.Run(fmt.Sprintf("%d", i), func(t *testing.T) {
+			d, err := ParseRetryAfter(tcase.Value, now)
+			if (err != nil) != tcase.Err {
+				t.Fatalf("expected error %v, got %v", tcase.Err, err)
+			}
+			if d != tcase.Expect {
+				t.Fatalf("expected %v, got %v", tcase.Expect, d)
+			}
+		})
+	}
+}
+
+func TestParseRateLimit(t *testing.T) {
+	t.Parallel()
+
+	tcases := []struct {
+		Header http.Header
+		Expect RateLimit
+		Err    bool
+	}{
+		{Header: http.Header{}, Err: true},
+		{Header: http.Header{"Ratelimit": {"limit=100, remaining=50, reset=30"}}, Expect: RateLimit{Limit: 100, Remaining: 50, Reset: 30 * time.Second}},
+		{Header: http.Header{"Ratelimit": {"limit=100, remaining=50", "reset=30"}, "Ratelimit-Limit": {"10"}}, Expect: RateLimit{Limit: 100, Remaining: 50, Reset: 30 * time.Second}},
+		{Header: http.Header{
+			"Ratelimit-Limit":     {"100, 100;w=60, 1000;w=3600"},
+			"Ratelimit-Remaining": {"7"},
+			"Ratelimit-Reset":     {"12"},
+		}, Expect: RateLimit{Limit: 100, Remaining: 7, Reset: 12 * time.Second}},
+		{Header: http.Header{"Ratelimit-Remaining": {"0"}}, Expect: RateLimit{}},
+		{Header: http.Header{"Ratelimit": {"limit=100, remaining=50"}}, Err: true},
+		{Header: http.Header{"Ratelimit": {"limit=100, remaining=-1, reset=3"}}, Err: true},
+		{Header: http.Header{"Ratelimit": {"limit=100, remaining=5.5, reset=3"}}, Err: true},
+		{Header: http.Header{"Ratelimit": {"limit=100; remaining"}}, Err: true},
+		{Header: http.Header{"Ratelimit-Reset": {"soon"}}, Err: true},
+	}
+
+	for i, tcase := range tcases {
+		t.Run(fmt.Sprintf("%d", i), func(t *testing.T) {
+			rl, err := ParseRateLimit(tcase.Header)
+			if (err != nil) != tcase.Err {
+				t.Fatalf("expected error %v, got %v", tcase.Err, err)
+			}
+			if err == nil && rl != tcase.Expect {
+				t.Fatalf("expected %+v, got %+v", tcase.Expect, rl)
+			}
+		})
+	}
+}
+
+func TestFormatRateLimit(t *testing.T) {
+	t.Parallel()
+
+	rl := RateLimit{Limit: 100, Remaining: 42, Reset: 90 * time.Second}
+	if s := rl.String(); s != "limit=100, remaining=42, reset=90" {
+		t.Fatalf("unexpected RateLimit value %q", s)
+	}
+
+	if _, err := ParseRateLimit(http.Header{}); !errors.Is(err, ErrNoHeader) {
+		t.Fatalf("expected ErrNoHeader, got %v", err)
+	}
+
+	h := http.Header{}
+	SetRateLimit(h, rl)
+	if parsed, err := ParseRateLimit(h); err != nil || parsed != rl {
+		t.Fatalf("expected %+v, got %+v (%v)", rl, parsed, err)
+	}
+	h = http.Header{"Ratelimit": {rl.String()}}
+	if parsed, err := ParseRateLimit(h); err != nil || parsed != rl {
+		t.Fatalf("expected %+v, got %+v (%v)", rl, parsed, err)
+	}
+}
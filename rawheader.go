@@ -0,0 +1,287 @@
+// Copyright 2026 Franklin "Snaipe" Mathieu.
+//
+// Use of this source code is governed by the MIT license that can be
+// found in the LICENSE file.
+
+package htutil
+
+import (
+	"bufio"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptrace"
+	"net/http/httputil"
+	"strings"
+	"sync"
+)
+
+// RawField is a single header field line, as it appeared on the wire.
+type RawField struct {
+	Name  string
+	Value string
+}
+
+// RawHeader is a header section that preserves the order and the casing
+// of its field lines, unlike http.Header, which canonicalizes field names
+// and groups values by name.
+//
+// It is meant for diagnostic proxies and compatibility shims that need to
+// forward messages to peers sensitive to either. Lookups are
+// case-insensitive.
+type RawHeader []RawField
+
+// ReadRawHeader reads a header section from r, up to and including the
+// empty line that terminates it. Obsolete line folding is replaced with a
+// single space, as per RFC 9112 §5.2.
+func ReadRawHeader(r *bufio.Reader) (RawHeader, error) {
+	var h RawHeader
+	for {
+		line, err := readHeaderLine(r)
+		if err != nil {
+			return nil, fmt.Errorf("reading header: %w", err)
+		}
+		if line == "" {
+			return h, nil
+		}
+		if line[0] == ' ' || line[0] == '\t' {
+			if len(h) == 0 {
+				return nil, fmt.Errorf("reading header: unexpected continuation line %q", line)
+			}
+			last := &h[len(h)-1]
+			last.Value = strings.TrimRight(last.Value+" "+trimOWS(line), " \t")
+			continue
+		}
+		colon := strings.IndexByte(line, ':')
+		if colon <= 0 || !IsToken(line[:colon]) {
+			return nil, fmt.Errorf("reading header: malformed field line %q", line)
+		}
+		h = append(h, RawField{Name: line[:colon], Value: trimOWS(line[colon+1:])})
+	}
+}
+
+func readHeaderLine(r *bufio.Reader) (string, error) {
+	line, err := r.ReadString('\n')
+	if err == io.EOF && line != "" {
+		err = io.ErrUnexpectedEOF
+	}
+	if err != nil {
+		return "", err
+	}
+	return strings.TrimSuffix(strings.TrimSuffix(line, "\n"), "\r"), nil
+}
+
+// WriteTo writes the field lines of h to w in order, followed by the empty
+// line that terminates a header section.
+func (h RawHeader) WriteTo(w io.Writer) (int64, error) {
+	var out strings.Builder
+	for _, f := range h {
+		out.WriteString(f.Name)
+		out.WriteString(": ")
+		out.WriteString(f.Value)
+		out.WriteString("\r\n")
+	}
+	out.WriteString("\r\n")
+	n, err := io.WriteString(w, out.String())
+	return int64(n), err
+}
+
+// Get returns the first value of the field name, or "" if it is absent.
+func (h RawHeader) Get(name string) string {
+	for _, f := range h {
+		if strings.EqualFold(f.Name, name) {
+			return f.Value
+		}
+	}
+	return ""
+}
+
+// Values returns all the values of the field name, in order.
+func (h RawHeader) Values(name string) []string {
+	var values []string
+	for _, f := range h {
+		if strings.EqualFold(f.Name, name) {
+			values = append(values, f.Value)
+		}
+	}
+	return values
+}
+
+// Add appends a field line to h.
+func (h *RawHeader) Add(name, value string) {
+	*h = append(*h, RawField{Name: name, Value: value})
+}
+
+// Set replaces the value of the first field line named name, keeping its
+// position and casing, and removes the others. If there is no such field
+// line, one is appended.
+func (h *RawHeader) Set(name, value string) {
+	out := (*h)[:0]
+	found := false
+	for _, f := range *h {
+		if strings.EqualFold(f.Name, name) {
+			if found {
+				continue
+			}
+			f.Value = value
+			found = true
+		}
+		out = append(out, f)
+	}
+	*h = out
+	if !found {
+		h.Add(name, value)
+	}
+}
+
+// Del removes all the field lines named name.
+func (h *RawHeader) Del(name string) {
+	out := (*h)[:0]
+	for _, f := range *h {
+		if !strings.EqualFold(f.Name, name) {
+			out = append(out, f)
+		}
+	}
+	*h = out
+}
+
+// Header returns h as a canonicalized http.Header.
+func (h RawHeader) Header() http.Header {
+	out := make(http.Header, len(h))
+	for _, f := range h {
+		out.Add(f.Name, f.Value)
+	}
+	return out
+}
+
+// ReadRawRequest reads an HTTP/1.x request from r, like http.ReadRequest,
+// and additionally returns its header section as it appeared on the wire.
+// The body of the request is read from r, which is left at the start of the
+// next pipelined request once the body has been read in full.
+func ReadRawRequest(r *bufio.Reader) (*http.Request, RawHeader, error) {
+	var wire strings.Builder
+	line, err := readHeaderLine(r)
+	if err != nil {
+		return nil, nil, fmt.Errorf("reading request line: %w", err)
+	}
+	wire.WriteString(line)
+	wire.WriteString("\r\n")
+
+	raw, err := ReadRawHeader(r)
+	if err != nil {
+		return nil, nil, err
+	}
+	raw.WriteTo(&wire)
+
+	// Let net/http interpret the header section only, and read the body
+	// from r directly: buffering r again would swallow the bytes of any
+	// pipelined request that follows.
+	req, err := http.ReadRequest(bufio.NewReader(strings.NewReader(wire.String())))
+	if err != nil {
+		return nil, nil, err
+	}
+	switch {
+	case len(req.TransferEncoding) > 0 && req.TransferEncoding[0] == "chunked":
+		req.Body = &rawBody{Reader: httputil.NewChunkedReader(r), r: r, req: req, chunked: true}
+	case req.ContentLength > 0:
+		req.Body = &rawBody{Reader: io.LimitReader(r, req.ContentLength), r: r, req: req, remaining: req.ContentLength}
+	default:
+		req.Body = http.NoBody
+	}
+	return req, raw, nil
+}
+
+// rawBody is the body of a request read by ReadRawRequest. It reads from
+// the connection reader, and consumes the trailer section of chunked
+// bodies, so that r is left at the start of the next request.
+type rawBody struct {
+	io.Reader
+	r         *bufio.Reader
+	req       *http.Request
+	chunked   bool
+	remaining int64
+	done      bool
+}
+
+func (b *rawBody) Read(p []byte) (int, error) {
+	if b.done {
+		return 0, io.EOF
+	}
+	n, err := b.Reader.Read(p)
+	b.remaining -= int64(n)
+	if err != io.EOF {
+		return n, err
+	}
+	b.done = true
+	if !b.chunked {
+		if b.remaining > 0 {
+			return n, io.ErrUnexpectedEOF
+		}
+		return n, io.EOF
+	}
+	trailer, terr := ReadRawHeader(b.r)
+	if terr != nil {
+		return n, fmt.Errorf("reading trailer section: %w", terr)
+	}
+	for _, f := range trailer {
+		if b.req.Trailer == nil {
+			b.req.Trailer = make(http.Header)
+		}
+		b.req.Trailer.Add(f.Name, f.Value)
+	}
+	return n, io.EOF
+}
+
+func (b *rawBody) Close() error {
+	return nil
+}
+
+// RawHeaderTrace is a capture of the header fields of an outgoing request,
+// in the order in which they were written by the transport.
+type RawHeaderTrace struct {
+	mu     sync.Mutex
+	header RawHeader
+	done   bool
+}
+
+// WithRawHeaderTrace returns a context that captures the header fields of
+// the requests made with it into the returned RawHeaderTrace. The field
+// names are the ones written by the transport, which may have
+// canonicalized them.
+func WithRawHeaderTrace(ctx context.Context) (context.Context, *RawHeaderTrace) {
+	tr := &RawHeaderTrace{}
+	ctx = httptrace.WithClientTrace(ctx, &httptrace.ClientTrace{
+		GotConn: func(httptrace.GotConnInfo) {
+			// The request may be retried on another connection.
+			tr.mu.Lock()
+			defer tr.mu.Unlock()
+			tr.header, tr.done = nil, false
+		},
+		WroteHeaderField: func(key string, values []string) {
+			tr.mu.Lock()
+			defer tr.mu.Unlock()
+			for _, v := range values {
+				tr.header = append(tr.header, RawField{Name: key, Value: v})
+			}
+		},
+		WroteHeaders: func() {
+			tr.mu.Lock()
+			defer tr.mu.Unlock()
+			tr.done = true
+		},
+	})
+	return ctx, tr
+}
+
+// Header returns the captured header fields. It returns an error if the
+// transport did not finish writing them.
+func (tr *RawHeaderTrace) Header() (RawHeader, error) {
+	tr.mu.Lock()
+	defer tr.mu.Unlock()
+	if !tr.done {
+		return nil, errors.New("request header was not captured")
+	}
+	return append(RawHeader(nil), tr.header...), nil
+}
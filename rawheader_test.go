@@ -0,0 +1,174 @@
+// Copyright 2026 Franklin "Snaipe" Mathieu.
+//
+// Use of this source code is governed by the MIT license that can be
+// found in the LICENSE file.
+
+package htutil
+
+import (
+	"bufio"
+	"context"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestReadRawHeader(t *testing.T) {
+	t.Parallel()
+
+	tcases := []struct {
+		Input    string
+		Expected RawHeader
+		Output   string
+		Err      bool
+	}{
+		{
+			Input: "x-lower: a\r\nHOST: example.com\r\nX-Lower: b\r\n\r\n",
+			Expected: RawHeader{
+				{Name: "x-lower", Value: "a"},
+				{Name: "HOST", Value: "example.com"},
+				{Name: "X-Lower", Value: "b"},
+			},
+			Output: "x-lower: a\r\nHOST: example.com\r\nX-Lower: b\r\n\r\n",
+		},
+		{
+			Input:    "X-Folded: a\r\n  b\r\n\tc \r\n\n",
+			Expected: RawHeader{{Name: "X-Folded", Value: "a b c"}},
+			Output:   "X-Folded: a b c\r\n\r\n",
+		},
+		{Input: "\r\n", Output: "\r\n"},
+		{Input: " continued\r\n\r\n", Err: true},
+		{Input: "Bad Name: x\r\n\r\n", Err: true},
+		{Input: "X-Truncated: x\r\n", Err: true},
+	}
+
+	for i, tcase := range tcases {
+		t.Run(fmt.Sprintf("%d", i), func(t *testing.T) {
+			h, err := ReadRawHeader(bufio.NewReader(strings.NewReader(tcase.Input)))
+			if tcase.Err {
+				if err == nil {
+					t.Fatalf("expected error, got %v", h)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("expected no error, got %v", err)
+			}
+			if !reflect.DeepEqual(h, tcase.Expected) {
+				t.Fatalf("expected %v, got %v", tcase.Expected, h)
+			}
+			var out strings.Builder
+			h.WriteTo(&out)
+			if out.String() != tcase.Output {
+				t.Fatalf("expected %q, got %q", tcase.Output, out.String())
+			}
+		})
+	}
+}
+
+func TestRawHeaderEdit(t *testing.T) {
+	t.Parallel()
+
+	h := RawHeader{
+		{Name: "x-a", Value: "1"},
+		{Name: "X-B", Value: "2"},
+		{Name: "X-A", Value: "3"},
+	}
+	if v := h.Get("X-A"); v != "1" {
+		t.Fatalf("expected 1, got %q", v)
+	}
+	if v := h.Values("x-a"); !reflect.DeepEqual(v, []string{"1", "3"}) {
+		t.Fatalf("expected [1 3], got %q", v)
+	}
+
+	h.Set("X-A", "4")
+	h.Add("x-c", "5")
+	h.Del("x-b")
+
+	expected := RawHeader{
+		{Name: "x-a", Value: "4"},
+		{Name: "x-c", Value: "5"},
+	}
+	if !reflect.DeepEqual(h, expected) {
+		t.Fatalf("expected %v, got %v", expected, h)
+	}
+	if hdr := h.Header(); !reflect.DeepEqual(hdr, http.Header{"X-A": {"4"}, "X-C": {"5"}}) {
+		t.Fatalf("expected canonical header, got %v", hdr)
+	}
+}
+
+func TestReadRawRequest(t *testing.T) {
+	t.Parallel()
+
+	input := "POST /path HTTP/1.1\r\nhost: example.com\r\ncontent-length: 5\r\nx-Weird-CASE: yes\r\n\r\nhello"
+	req, raw, err := ReadRawRequest(bufio.NewReader(strings.NewReader(input)))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if req.Host != "example.com" || req.Header.Get("X-Weird-Case") != "yes" {
+		t.Fatalf("unexpected request %v", req)
+	}
+	body, _ := io.ReadAll(req.Body)
+	if string(body) != "hello" {
+		t.Fatalf("expected body hello, got %q", body)
+	}
+	if raw[2].Name != "x-Weird-CASE" {
+		t.Fatalf("expected original casing, got %q", raw[2].Name)
+	}
+}
+
+func TestReadRawRequestPipelined(t *testing.T) {
+	t.Parallel()
+
+	input := "POST /a HTTP/1.1\r\nHost: example.com\r\nTransfer-Encoding: chunked\r\nTrailer: X-Sum\r\n\r\n" +
+		"5\r\nhello\r\n0\r\nX-Sum: 1\r\n\r\n" +
+		"POST /b HTTP/1.1\r\nHost: example.com\r\nContent-Length: 3\r\n\r\nbye" +
+		"GET /c HTTP/1.1\r\nHost: example.com\r\n\r\n"
+	r := bufio.NewReader(strings.NewReader(input))
+
+	expected := []struct{ Path, Body string }{{"/a", "hello"}, {"/b", "bye"}, {"/c", ""}}
+	for _, e := range expected {
+		req, _, err := ReadRawRequest(r)
+		if err != nil {
+			t.Fatal(err)
+		}
+		body, err := io.ReadAll(req.Body)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if req.URL.Path != e.Path || string(body) != e.Body {
+			t.Fatalf("expected %s %q, got %s %q", e.Path, e.Body, req.URL.Path, body)
+		}
+		if e.Path == "/a" && req.Trailer.Get("X-Sum") != "1" {
+			t.Fatalf("expected trailer X-Sum 1, got %v", req.Trailer)
+		}
+	}
+}
+
+func TestRawHeaderTrace(t *testing.T) {
+	t.Parallel()
+
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {}))
+	defer srv.Close()
+
+	ctx, tr := WithRawHeaderTrace(context.Background())
+	req, _ := http.NewRequestWithContext(ctx, "GET", srv.URL, nil)
+	req.Header.Set("X-First", "1")
+	resp, err := srv.Client().Do(req)
+	if err != nil {
+		t.Fatal(err)
+	}
+	resp.Body.Close()
+
+	h, err := tr.Header()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if v := h.Get("x-first"); v != "1" {
+		t.Fatalf("expected X-First 1, got %v", h)
+	}
+}
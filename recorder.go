@@ -0,0 +1,138 @@
+// Copyright 2026 Franklin "Snaipe" Mathieu.
+//
+// Use of this source code is governed by the MIT license that can be
+// found in the LICENSE file.
+
+package htutil
+
+import (
+	"bufio"
+	"io"
+	"net"
+	"net/http"
+	"time"
+)
+
+// Recorder keeps track of the status, size, and timing of a response being
+// written through its Writer, and allows middleware to intercept the
+// response right before its header gets written.
+type Recorder struct {
+	// Writer is the http.ResponseWriter to hand down to the next handler.
+	// It implements the same optional interfaces as the recorded writer,
+	// and forwards them to it; see Wrap.
+	Writer http.ResponseWriter
+
+	status   int
+	written  int64
+	wroteAt  time.Time
+	hijacked bool
+	hooks    []func(status int) int
+}
+
+// NewRecorder returns a Recorder for the response written to w.
+func NewRecorder(w http.ResponseWriter) *Recorder {
+	rec := &Recorder{}
+	rec.Writer = Wrap(w, WriterHooks{
+		WriteHeader: func(next WriteHeaderFunc) WriteHeaderFunc {
+			return func(status int) {
+				rec.writeHeader(next, status)
+			}
+		},
+		Write: func(next WriteFunc) WriteFunc {
+			return func(p []byte) (int, error) {
+				if rec.status == 0 {
+					rec.writeHeader(w.WriteHeader, http.StatusOK)
+				}
+				n, err := next(p)
+				rec.written += int64(n)
+				return n, err
+			}
+		},
+		ReadFrom: func(next ReadFromFunc) ReadFromFunc {
+			return func(r io.Reader) (int64, error) {
+				if rec.status == 0 {
+					rec.writeHeader(w.WriteHeader, http.StatusOK)
+				}
+				n, err := next(r)
+				rec.written += n
+				return n, err
+			}
+		},
+		Flush: func(next FlushFunc) FlushFunc {
+			return func() {
+				if rec.status == 0 {
+					rec.writeHeader(w.WriteHeader, http.StatusOK)
+				}
+				next()
+			}
+		},
+		Hijack: func(next HijackFunc) HijackFunc {
+			return func() (net.Conn, *bufio.ReadWriter, error) {
+				conn, brw, err := next()
+				if err == nil {
+					rec.hijacked = true
+				}
+				return conn, brw, err
+			}
+		},
+	})
+	return rec
+}
+
+func (rec *Recorder) writeHeader(next WriteHeaderFunc, status int) {
+	if rec.status != 0 {
+		// Let the underlying writer complain about superfluous calls.
+		next(status)
+		return
+	}
+	if status >= 100 && status < 200 && status != http.StatusSwitchingProtocols {
+		// Informational responses are not final, and may be sent multiple times.
+		next(status)
+		return
+	}
+	for _, hook := range rec.hooks {
+		status = hook(status)
+	}
+	rec.status = status
+	rec.wroteAt = time.Now()
+	next(status)
+}
+
+// OnWriteHeader registers a hook called with the final status of the
+// response, right before its header is written. Hooks may modify the
+// header, and return the status to actually write. Hooks are called in
+// the order they were registered.
+func (rec *Recorder) OnWriteHeader(hook func(status int) int) {
+	rec.hooks = append(rec.hooks, hook)
+}
+
+// Status returns the status code of the response, or 0 if the header of
+// the response has not been written yet.
+func (rec *Recorder) Status() int {
+	return rec.status
+}
+
+// BytesWritten returns the number of bytes of body written so far.
+func (rec *Recorder) BytesWritten() int64 {
+	return rec.written
+}
+
+// Written returns whether the header of the response has been written.
+func (rec *Recorder) Written() bool {
+	return rec.status != 0
+}
+
+// WroteAt returns the time at which the header of the response was
+// written, which is when its first byte was handed to the underlying
+// writer, or the zero time if it has not been written yet. Informational
+// responses are not considered.
+func (rec *Recorder) WroteAt() time.Time {
+	return rec.wroteAt
+}
+
+// Hijacked returns whether the connection of the response was hijacked
+// through Writer. Hijacked responses have no status unless one was written
+// beforehand, and the bytes written to the connection are not counted.
+func (rec *Recorder) Hijacked() bool {
+	return rec.hijacked
+}
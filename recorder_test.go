@@ -0,0 +1,126 @@
+// Copyright 2026 Franklin "Snaipe" Mathieu.
+//
+// Use of this source code is governed by the MIT license that can be
+// found in the LICENSE file.
+
+package htutil
+
+import (
+	"bufio"
+	"io"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestRecorder(t *testing.T) {
+	t.Parallel()
+
+	full := &fullWriter{ResponseRecorder: httptest.NewRecorder()}
+	rec := NewRecorder(full)
+	if rec.Written() {
+		t.Fatalf("expected recorder to not have written anything")
+	}
+
+	rec.OnWriteHeader(func(status int) int {
+		rec.Writer.Header().Set("X-Status", http.StatusText(status))
+		return http.StatusAccepted
+	})
+
+	rec.Writer.Write([]byte("hello, "))
+	rec.Writer.(io.ReaderFrom).ReadFrom(strings.NewReader("world"))
+
+	if !rec.Written() {
+		t.Fatalf("expected recorder to have written the header")
+	}
+	if rec.Status() != http.StatusAccepted {
+		t.Fatalf("expected status %d, got %d", http.StatusAccepted, rec.Status())
+	}
+	if full.Code != http.StatusAccepted {
+		t.Fatalf("expected hook to alter the written status, got %d", full.Code)
+	}
+	if hdr := full.Header().Get("X-Status"); hdr != "OK" {
+		t.Fatalf("expected hook to set X-Status to OK, got %q", hdr)
+	}
+	if rec.BytesWritten() != int64(len("hello, world")) {
+		t.Fatalf("expected %d bytes written, got %d", len("hello, world"), rec.BytesWritten())
+	}
+}
+
+func TestRecorderInterfaces(t *testing.T) {
+	t.Parallel()
+
+	full := &fullWriter{ResponseRecorder: httptest.NewRecorder()}
+	rec := NewRecorder(full)
+	if _, ok := rec.Writer.(http.Hijacker); !ok {
+		t.Fatalf("expected writer to implement http.Hijacker")
+	}
+	if _, ok := rec.Writer.(http.Pusher); !ok {
+		t.Fatalf("expected writer to implement http.Pusher")
+	}
+	if _, ok := rec.Writer.(io.ReaderFrom); !ok {
+		t.Fatalf("expected writer to implement io.ReaderFrom")
+	}
+
+	before := time.Now()
+	if err := http.NewResponseController(rec.Writer).Flush(); err != nil {
+		t.Fatal(err)
+	}
+	if !full.Flushed {
+		t.Fatalf("expected flush to be forwarded")
+	}
+	if rec.Status() != http.StatusOK {
+		t.Fatalf("expected flush to write the header with status %d, got %d", http.StatusOK, rec.Status())
+	}
+	if at := rec.WroteAt(); at.Before(before) || at.After(time.Now()) {
+		t.Fatalf("expected header to be written between %v and now, got %v", before, at)
+	}
+
+	if _, _, err := http.NewResponseController(rec.Writer).Hijack(); err == nil || !full.hijacked {
+		t.Fatalf("expected hijack to be forwarded")
+	}
+	if rec.Hijacked() {
+		t.Fatalf("expected failed hijack to not be recorded")
+	}
+
+	bare := NewRecorder(httptest.NewRecorder())
+	if _, ok := bare.Writer.(http.Hijacker); ok {
+		t.Fatalf("expected writer to not implement http.Hijacker")
+	}
+	if !bare.WroteAt().IsZero() {
+		t.Fatalf("expected no write time before the header is written")
+	}
+}
+
+type hijackableWriter struct {
+	*httptest.ResponseRecorder
+	conn net.Conn
+}
+
+func (w *hijackableWriter) Hijack() (net.Conn, *bufio.ReadWriter, error) {
+	return w.conn, bufio.NewReadWriter(bufio.NewReader(w.conn), bufio.NewWriter(w.conn)), nil
+}
+
+func TestRecorderHijack(t *testing.T) {
+	t.Parallel()
+
+	server, client := net.Pipe()
+	defer client.Close()
+
+	rec := NewRecorder(&hijackableWriter{ResponseRecorder: httptest.NewRecorder(), conn: server})
+	conn, _, err := rec.Writer.(http.Hijacker).Hijack()
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer conn.Close()
+
+	if !rec.Hijacked() {
+		t.Fatalf("expected hijack to be recorded")
+	}
+	if rec.Written() {
+		t.Fatalf("expected hijacked response to have no status")
+	}
+}
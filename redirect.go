@@ -0,0 +1,219 @@
+// Copyright 2026 Franklin "Snaipe" Mathieu.
+//
+// Use of this source code is governed by the MIT license that can be
+// found in the LICENSE file.
+
+package htutil
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"log/slog"
+	"net/http"
+	"net/url"
+	"strings"
+)
+
+var (
+	// ErrTooManyRedirects is returned by RedirectTransport when a request
+	// is redirected more than MaxHops times.
+	ErrTooManyRedirects = errors.New("too many redirects")
+
+	// ErrRedirectDowngrade is returned by RedirectTransport when an https
+	// request is redirected to another scheme, and downgrades are not
+	// allowed.
+	ErrRedirectDowngrade = errors.New("redirect downgrades from https")
+)
+
+// RedirectHop describes a redirect about to be followed by a
+// RedirectTransport.
+type RedirectHop struct {
+	// Request is the request about to be sent to the redirect target. Its
+	// header may be modified, for instance to authenticate it again.
+	Request *http.Request
+
+	// Response is the redirect response. Its body must not be read.
+	Response *http.Response
+
+	// Hop is the number of the redirect, starting at 1.
+	Hop int
+}
+
+// RedirectTransport is a http.RoundTripper that follows redirects, with a
+// finer control over them than http.Client.CheckRedirect.
+//
+// Responses with a 301 Moved Permanently or 302 Found status to POST
+// requests, and with a 303 See Other status to requests other than HEAD,
+// are followed with a GET request without content. The other requests are
+// redirected with their method and content, which must be replayable; see
+// RequestBody. Redirects that cannot be followed are returned as is.
+//
+// The Authorization and Cookie header fields are removed from requests
+// redirected to another origin.
+//
+// Clients using a RedirectTransport should not follow redirects
+// themselves, by having their CheckRedirect return
+// http.ErrUseLastResponse.
+type RedirectTransport struct {
+	// Base is the underlying transport. Defaults to http.DefaultTransport.
+	Base http.RoundTripper
+
+	// MaxHops is the maximum number of redirects followed for a request,
+	// beyond which ErrTooManyRedirects is returned. Defaults to 10.
+	MaxHops int
+
+	// AllowDowngrade allows redirecting https requests to http. They fail
+	// with ErrRedirectDowngrade otherwise.
+	AllowDowngrade bool
+
+	// StripHeaders are header fields removed from requests redirected to
+	// another origin, in addition to Authorization and Cookie.
+	StripHeaders []string
+
+	// OnRedirect, if set, is called before following every redirect. If it
+	// returns http.ErrUseLastResponse, the redirect response is returned
+	// instead; other errors are returned by RoundTrip.
+	OnRedirect func(RedirectHop) error
+}
+
+func (t *RedirectTransport) base() http.RoundTripper {
+	if t.Base != nil {
+		return t.Base
+	}
+	return http.DefaultTransport
+}
+
+func (t *RedirectTransport) maxHops() int {
+	if t.MaxHops > 0 {
+		return t.MaxHops
+	}
+	return 10
+}
+
+func (t *RedirectTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	logger := ContextLogger(req.Context())
+	cur := req
+	for hop := 1; ; hop++ {
+		resp, err := t.base().RoundTrip(cur)
+		if err != nil {
+			return nil, err
+		}
+		method, keepBody, ok := redirectMethod(cur.Method, resp.StatusCode)
+		loc := resp.Header.Get("Location")
+		if !ok || loc == "" {
+			return resp, nil
+		}
+		target, err := cur.URL.Parse(loc)
+		if err != nil {
+			logger.Debug("not following redirect",
+				slog.String("method", cur.Method), slog.Any("url", URL{cur.URL}), slog.String("error", err.Error()))
+			return resp, nil
+		}
+		if hop > t.maxHops() {
+			discardBody(resp)
+			return nil, fmt.Errorf("following redirect to %v: %w", target, ErrTooManyRedirects)
+		}
+		if !t.AllowDowngrade && strings.EqualFold(cur.URL.Scheme, "https") && !strings.EqualFold(target.Scheme, "https") {
+			discardBody(resp)
+			return nil, fmt.Errorf("following redirect to %v: %w", target, ErrRedirectDowngrade)
+		}
+
+		next, err := t.redirect(cur, resp, method, keepBody, target)
+		if err != nil {
+			logger.Debug("not following redirect",
+				slog.String("method", cur.Method), slog.Any("url", URL{cur.URL}), slog.String("error", err.Error()))
+			return resp, nil
+		}
+		if t.OnRedirect != nil {
+			if err := t.OnRedirect(RedirectHop{Request: next, Response: resp, Hop: hop}); err != nil {
+				closeBody(next)
+				if errors.Is(err, http.ErrUseLastResponse) {
+					return resp, nil
+				}
+				discardBody(resp)
+				return nil, err
+			}
+		}
+		logger.Debug("following redirect",
+			slog.String("method", cur.Method), slog.Any("url", URL{cur.URL}),
+			slog.Int("status", resp.StatusCode), slog.Any("location", URL{target}))
+		discardBody(resp)
+		cur = next
+	}
+}
+
+// redirect returns the request following the redirect response resp to
+// req, with the specified method, to target.
+func (t *RedirectTransport) redirect(req *http.Request, resp *http.Response, method string, keepBody bool, target *url.URL) (*http.Request, error) {
+	var next *http.Request
+	if keepBody {
+		var err error
+		if next, err = rewind(req); err != nil {
+			return nil, err
+		}
+		if next == req {
+			next = req.Clone(req.Context())
+		}
+	} else {
+		next = req.Clone(req.Context())
+		next.Body, next.GetBody, next.ContentLength = nil, nil, 0
+		for _, name := range contentHeaders {
+			next.Header.Del(name)
+		}
+	}
+	next.Method = method
+	next.URL = target
+	next.Host = ""
+	next.Response = resp
+
+	if !OriginOf(req.URL).Equal(OriginOf(target)) {
+		next.Header.Del("Authorization")
+		next.Header.Del("Cookie")
+		for _, name := range t.StripHeaders {
+			next.Header.Del(name)
+		}
+	}
+	return next, nil
+}
+
+// contentHeaders are the header fields describing the content of a
+// request, which are removed when it is redirected without it.
+var contentHeaders = []string{
+	"Content-Type",
+	"Content-Length",
+	"Content-Encoding",
+	"Content-Language",
+	"Content-Location",
+	"Content-Digest",
+	"Repr-Digest",
+}
+
+// redirectMethod returns the method of the request following a redirect
+// response of the specified status to a request with method, whether it
+// keeps the content of the request, and whether the redirect is followed
+// at all.
+func redirectMethod(method string, status int) (string, bool, bool) {
+	switch status {
+	case http.StatusMovedPermanently, http.StatusFound:
+		if method == http.MethodPost {
+			return http.MethodGet, false, true
+		}
+		return method, true, true
+	case http.StatusSeeOther:
+		if method == http.MethodHead {
+			return method, false, true
+		}
+		return http.MethodGet, false, true
+	case http.StatusTemporaryRedirect, http.StatusPermanentRedirect:
+		return method, true, true
+	}
+	return "", false, false
+}
+
+// discardBody drains a bit of the body of resp, so that its connection may
+// be reused, and closes it.
+func discardBody(resp *http.Response) {
+	io.Copy(io.Discard, io.LimitReader(resp.Body, 4<<10))
+	resp.Body.Close()
+}
@@ -0,0 +1,216 @@
+// Copyright 2026 Franklin "Snaipe" Mathieu.
+//
+// Use of this source code is governed by the MIT license that can be
+// found in the LICENSE file.
+
+package htutil
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+	"testing"
+)
+
+// redirectBase returns a transport redirecting requests as per routes,
+// from URLs to Location values with a status, and answering 200 OK to the
+// others, with a description of the request as body.
+func redirectBase(routes map[string]struct {
+	Status   int
+	Location string
+}) http.RoundTripper {
+	return roundTripFunc(func(req *http.Request) (*http.Response, error) {
+		resp := &http.Response{
+			StatusCode: http.StatusOK,
+			Header:     make(http.Header),
+			Request:    req,
+		}
+		if route, ok := routes[req.URL.String()]; ok {
+			resp.StatusCode = route.Status
+			resp.Header.Set("Location", route.Location)
+			resp.Body = io.NopCloser(strings.NewReader(""))
+			return resp, nil
+		}
+		var body []byte
+		if req.Body != nil {
+			body, _ = io.ReadAll(req.Body)
+		}
+		desc := fmt.Sprintf("%s %s auth=%q cookie=%q type=%q body=%q", req.Method, req.URL,
+			req.Header.Get("Authorization"), req.Header.Get("Cookie"), req.Header.Get("Content-Type"), body)
+		resp.Body = io.NopCloser(strings.NewReader(desc))
+		return resp, nil
+	})
+}
+
+func TestRedirectTransport(t *testing.T) {
+	t.Parallel()
+
+	type route = struct {
+		Status   int
+		Location string
+	}
+	routes := map[string]route{
+		"http://a.example/302":        {302, "/target"},
+		"http://a.example/303":        {303, "http://a.example/target"},
+		"http://a.example/307":        {307, "/target"},
+		"http://a.example/308-away":   {308, "http://b.example/target"},
+		"http://a.example/away":       {302, "http://b.example/target"},
+		"http://a.example/loop":       {302, "/loop"},
+		"http://a.example/300":        {300, "/target"},
+		"http://a.example/chain":      {301, "/302"},
+		"https://a.example/downgrade": {302, "http://a.example/target"},
+		"https://a.example/upgrade":   {302, "https://b.example/target"},
+	}
+
+	tcases := []struct {
+		Method     string
+		URL        string
+		Body       string
+		Replayable bool
+		Downgrade  bool
+		Status     int
+		Expect     string
+		Err        error
+	}{
+		{Method: "GET", URL: "http://a.example/302", Status: 200,
+			Expect: `GET http://a.example/target auth="secret" cookie="c=1" type="" body=""`},
+		{Method: "POST", URL: "http://a.example/302", Body: "payload", Replayable: true, Status: 200,
+			Expect: `GET http://a.example/target auth="secret" cookie="c=1" type="" body=""`},
+		{Method: "PUT", URL: "http://a.example/303", Body: "payload", Replayable: true, Status: 200,
+			Expect: `GET http://a.example/target auth="secret" cookie="c=1" type="" body=""`},
+		{Method: "POST", URL: "http://a.example/307", Body: "payload", Replayable: true, Status: 200,
+			Expect: `POST http://a.example/target auth="secret" cookie="c=1" type="text/plain" body="payload"`},
+		{Method: "POST", URL: "http://a.example/307", Body: "payload", Status: 307},
+		{Method: "PUT", URL: "http://a.example/308-away", Body: "payload", Replayable: true, Status: 200,
+			Expect: `PUT http://b.example/target auth="" cookie="" type="text/plain" body="payload"`},
+		{Method: "GET", URL: "http://a.example/away", Status: 200,
+			Expect: `GET http://b.example/target auth="" cookie="" type="" body=""`},
+		{Method: "GET", URL: "http://a.example/chain", Status: 200,
+			Expect: `GET http://a.example/target auth="secret" cookie="c=1" type="" body=""`},
+		{Method: "GET", URL: "http://a.example/300", Status: 300},
+		{Method: "GET", URL: "http://a.example/loop", Err: ErrTooManyRedirects},
+		{Method: "GET", URL: "https://a.example/downgrade", Err: ErrRedirectDowngrade},
+		{Method: "GET", URL: "https://a.example/downgrade", Downgrade: true, Status: 200,
+			Expect: `GET http://a.example/target auth="" cookie="" type="" body=""`},
+		{Method: "GET", URL: "https://a.example/upgrade", Status: 200,
+			Expect: `GET https://b.example/target auth="" cookie="" type="" body=""`},
+	}
+
+	for i, tcase := range tcases {
+		t.Run(fmt.Sprintf("%d", i), func(t *testing.T) {
+			transport := &RedirectTransport{
+				Base:           redirectBase(routes),
+				MaxHops:        3,
+				AllowDowngrade: tcase.Downgrade,
+			}
+
+			var body io.Reader
+			if tcase.Body != "" {
+				body = strings.NewReader(tcase.Body)
+				if !tcase.Replayable {
+					body = io.MultiReader(body)
+				}
+			}
+			req, err := http.NewRequest(tcase.Method, tcase.URL, body)
+			if err != nil {
+				t.Fatal(err)
+			}
+			req.Header.Set("Authorization", "secret")
+			req.Header.Set("Cookie", "c=1")
+			if tcase.Body != "" {
+				req.Header.Set("Content-Type", "text/plain")
+			}
+
+			resp, err := transport.RoundTrip(req)
+			if !errors.Is(err, tcase.Err) {
+				t.Fatalf("expected error %v, got %v", tcase.Err, err)
+			}
+			if err != nil {
+				return
+			}
+			defer resp.Body.Close()
+			if resp.StatusCode != tcase.Status {
+				t.Fatalf("expected status %d, got %d", tcase.Status, resp.StatusCode)
+			}
+			if tcase.Status != 200 {
+				return
+			}
+			got, _ := io.ReadAll(resp.Body)
+			if string(got) != tcase.Expect {
+				t.Fatalf("expected %s, got %s", tcase.Expect, got)
+			}
+		})
+	}
+}
+
+func TestRedirectTransportOnRedirect(t *testing.T) {
+	t.Parallel()
+
+	routes := map[string]struct {
+		Status   int
+		Location string
+	}{
+		"http://a.example/1": {302, "/2"},
+		"http://a.example/2": {302, "http://b.example/3"},
+	}
+	errStop := errors.New("stop")
+
+	tcases := []struct {
+		Hook   func(hop RedirectHop) error
+		Status int
+		Expect string
+		Err    error
+	}{
+		{
+			Hook: func(hop RedirectHop) error {
+				hop.Request.Header.Set("Authorization", fmt.Sprintf("hop %d", hop.Hop))
+				return nil
+			},
+			Status: 200,
+			Expect: `GET http://b.example/3 auth="hop 2" cookie="" type="" body=""`,
+		},
+		{
+			Hook: func(hop RedirectHop) error {
+				if hop.Request.URL.Host != hop.Response.Request.URL.Host {
+					return http.ErrUseLastResponse
+				}
+				return nil
+			},
+			Status: 302,
+		},
+		{
+			Hook: func(hop RedirectHop) error { return errStop },
+			Err:  errStop,
+		},
+	}
+
+	for i, tcase := range tcases {
+		t.Run(fmt.Sprintf("%d", i), func(t *testing.T) {
+			transport := &RedirectTransport{
+				Base:       redirectBase(routes),
+				OnRedirect: tcase.Hook,
+			}
+			req, _ := http.NewRequest("GET", "http://a.example/1", nil)
+			resp, err := transport.RoundTrip(req)
+			if !errors.Is(err, tcase.Err) {
+				t.Fatalf("expected error %v, got %v", tcase.Err, err)
+			}
+			if err != nil {
+				return
+			}
+			defer resp.Body.Close()
+			if resp.StatusCode != tcase.Status {
+				t.Fatalf("expected status %d, got %d", tcase.Status, resp.StatusCode)
+			}
+			if tcase.Status != 200 {
+				return
+			}
+			got, _ := io.ReadAll(resp.Body)
+			if string(got) != tcase.Expect {
+				t.Fatalf("expected %s, got %s", tcase.Expect, got)
+			}
+		})
+	}
+}
@@ -0,0 +1,123 @@
+// Copyright 2026 Franklin "Snaipe" Mathieu.
+//
+// Use of this source code is governed by the MIT license that can be
+// found in the LICENSE file.
+
+package htutil
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+)
+
+// RequestBody is a request body that can be read any number of times, so
+// that transports can replay requests on retries and redirects.
+//
+// http.NewRequest already makes bodies replayable for *bytes.Buffer,
+// *bytes.Reader, and *strings.Reader; RequestBody covers files and
+// arbitrary readers.
+type RequestBody struct {
+	r    io.ReaderAt
+	size int64
+	file *os.File
+}
+
+// BytesBody returns a RequestBody with the content b.
+func BytesBody(b []byte) *RequestBody {
+	return &RequestBody{r: bytes.NewReader(b), size: int64(len(b))}
+}
+
+// FileBody returns a RequestBody with the content of f, from its current
+// offset to its end. The content of f must not change until the request
+// is complete. Closing the RequestBody does not close f.
+func FileBody(f *os.File) (*RequestBody, error) {
+	info, err := f.Stat()
+	if err != nil {
+		return nil, err
+	}
+	offset, err := f.Seek(0, io.SeekCurrent)
+	if err != nil {
+		return nil, err
+	}
+	size := info.Size() - offset
+	if size < 0 {
+		size = 0
+	}
+	return &RequestBody{r: io.NewSectionReader(f, offset, size), size: size}, nil
+}
+
+// ReaderBody reads r to completion, and returns a RequestBody with its
+// content. Content up to threshold bytes is kept in memory; larger content
+// is spilled to a temporary file, which is removed when the RequestBody is
+// closed.
+func ReaderBody(r io.Reader, threshold int64) (*RequestBody, error) {
+	var buf bytes.Buffer
+	n, err := io.Copy(&buf, io.LimitReader(r, threshold+1))
+	if err != nil {
+		return nil, fmt.Errorf("reading request body: %w", err)
+	}
+	if n <= threshold {
+		return BytesBody(buf.Bytes()), nil
+	}
+
+	f, err := os.CreateTemp("", "htutil-body-*")
+	if err != nil {
+		return nil, fmt.Errorf("spilling request body: %w", err)
+	}
+	body := &RequestBody{r: f, file: f}
+	if body.size, err = io.Copy(f, io.MultiReader(&buf, r)); err != nil {
+		body.Close()
+		return nil, fmt.Errorf("spilling request body: %w", err)
+	}
+	return body, nil
+}
+
+// Size returns the size of the content of b.
+func (b *RequestBody) Size() int64 {
+	return b.size
+}
+
+// GetBody returns a new reader for the content of b, with the signature of
+// http.Request.GetBody.
+func (b *RequestBody) GetBody() (io.ReadCloser, error) {
+	if b.r == nil {
+		return nil, errors.New("request body is closed")
+	}
+	return io.NopCloser(io.NewSectionReader(b.r, 0, b.size)), nil
+}
+
+// Attach sets b as the body of req, setting its Body, GetBody, and
+// ContentLength accordingly.
+func (b *RequestBody) Attach(req *http.Request) error {
+	body, err := b.GetBody()
+	if err != nil {
+		return err
+	}
+	req.Body = body
+	req.GetBody = b.GetBody
+	req.ContentLength = b.size
+	if b.size == 0 {
+		req.Body = http.NoBody
+	}
+	return nil
+}
+
+// Close releases the resources of b, removing its temporary file if it
+// was spilled to disk. It must only be called once the requests using b
+// are complete.
+func (b *RequestBody) Close() error {
+	b.r = nil
+	if b.file == nil {
+		return nil
+	}
+	err := b.file.Close()
+	if rmErr := os.Remove(b.file.Name()); err == nil {
+		err = rmErr
+	}
+	b.file = nil
+	return err
+}
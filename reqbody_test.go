@@ -0,0 +1,124 @@
+// Copyright 2026 Franklin "Snaipe" Mathieu.
+//
+// Use of this source code is governed by the MIT license that can be
+// found in the LICENSE file.
+
+package htutil
+
+import (
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"testing"
+	"testing/iotest"
+)
+
+func TestRequestBody(t *testing.T) {
+	t.Parallel()
+
+	const content = "hello, world"
+
+	var (
+		mu       sync.Mutex
+		received []string
+	)
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+		data, _ := io.ReadAll(req.Body)
+		mu.Lock()
+		received = append(received, string(data))
+		mu.Unlock()
+		if req.URL.Path == "/redirect" {
+			http.Redirect(w, req, "/target", http.StatusTemporaryRedirect)
+		}
+	}))
+	defer srv.Close()
+
+	path := filepath.Join(t.TempDir(), "body")
+	if err := os.WriteFile(path, []byte("skipped: "+content), 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	tcases := []struct {
+		Body    func() (*RequestBody, error)
+		Spilled bool
+	}{
+		{Body: func() (*RequestBody, error) { return BytesBody([]byte(content)), nil }},
+		{Body: func() (*RequestBody, error) {
+			f, err := os.Open(path)
+			if err != nil {
+				return nil, err
+			}
+			t.Cleanup(func() { f.Close() })
+			f.Seek(int64(len("skipped: ")), io.SeekStart)
+			return FileBody(f)
+		}},
+		{Body: func() (*RequestBody, error) {
+			return ReaderBody(iotest.OneByteReader(strings.NewReader(content)), 64)
+		}},
+		{
+			Body: func() (*RequestBody, error) {
+				return ReaderBody(iotest.OneByteReader(strings.NewReader(content)), 4)
+			},
+			Spilled: true,
+		},
+	}
+
+	for i, tcase := range tcases {
+		t.Run(fmt.Sprintf("%d", i), func(t *testing.T) {
+			mu.Lock()
+			received = nil
+			mu.Unlock()
+
+			body, err := tcase.Body()
+			if err != nil {
+				t.Fatal(err)
+			}
+			if body.Size() != int64(len(content)) {
+				t.Fatalf("expected size %d, got %d", len(content), body.Size())
+			}
+			if (body.file != nil) != tcase.Spilled {
+				t.Fatalf("expected spilled %v, got %v", tcase.Spilled, body.file != nil)
+			}
+
+			req, err := http.NewRequest("POST", srv.URL+"/redirect", nil)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if err := body.Attach(req); err != nil {
+				t.Fatal(err)
+			}
+			resp, err := srv.Client().Do(req)
+			if err != nil {
+				t.Fatal(err)
+			}
+			resp.Body.Close()
+
+			mu.Lock()
+			defer mu.Unlock()
+			if fmt.Sprint(received) != fmt.Sprint([]string{content, content}) {
+				t.Fatalf("expected body to be sent twice, got %q", received)
+			}
+
+			var name string
+			if body.file != nil {
+				name = body.file.Name()
+			}
+			if err := body.Close(); err != nil {
+				t.Fatal(err)
+			}
+			if name != "" {
+				if _, err := os.Stat(name); !os.IsNotExist(err) {
+					t.Fatalf("expected spilled file to be removed, got %v", err)
+				}
+			}
+			if _, err := body.GetBody(); err == nil {
+				t.Fatalf("expected error after close")
+			}
+		})
+	}
+}
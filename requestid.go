@@ -0,0 +1,106 @@
+// Copyright 2026 Franklin "Snaipe" Mathieu.
+//
+// Use of this source code is governed by the MIT license that can be
+// found in the LICENSE file.
+
+package htutil
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"net/http"
+)
+
+type requestIDKey struct{}
+
+// maxRequestIDLength is the length above which incoming request IDs are
+// replaced.
+const maxRequestIDLength = 128
+
+// RequestID returns a middleware that identifies the requests served by
+// next, and makes their identifier available through RequestIDFromContext.
+// The identifier is also echoed in the header field of the response, and
+// added to the logger of the request context as "request_id".
+//
+// Requests carrying a header field, typically X-Request-Id, keep the
+// identifier assigned by an upstream proxy, provided that it is a token
+// of reasonable length; other requests get a random identifier.
+func RequestID(next http.Handler, header string) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+		id := req.Header.Get(header)
+		if id == "" || len(id) > maxRequestIDLength || !IsToken(id) {
+			id = newRequestID()
+		}
+		w.Header().Set(header, id)
+
+		ctx := context.WithValue(req.Context(), requestIDKey{}, id)
+		ctx = ContextWithLogger(ctx, ContextLogger(ctx).With("request_id", id))
+		next.ServeHTTP(w, req.WithContext(ctx))
+	})
+}
+
+func newRequestID() string {
+	var id [16]byte
+	if _, err := rand.Read(id[:]); err != nil {
+		panic(err)
+	}
+	return hex.EncodeToString(id[:])
+}
+
+// RequestIDFromContext returns the identifier assigned by a RequestID
+// middleware to the request that ctx belongs to.
+func RequestIDFromContext(ctx context.Context) (string, bool) {
+	id, ok := ctx.Value(requestIDKey{}).(string)
+	return id, ok
+}
+
+// RequestIDTransport is an http.RoundTripper that propagates the request
+// identifier and the trace context of the inbound request that the context
+// of outbound requests belongs to, as assigned by the RequestID and
+// TraceContext middlewares.
+//
+// The traceparent of outbound requests names the span of the inbound
+// request as their parent. Header fields already set on outbound requests
+// are left untouched.
+type RequestIDTransport struct {
+	// Base is the underlying transport. Defaults to http.DefaultTransport.
+	Base http.RoundTripper
+
+	// Header is the header field carrying the request identifier. Defaults
+	// to X-Request-Id.
+	Header string
+}
+
+func (t *RequestIDTransport) base() http.RoundTripper {
+	if t.Base != nil {
+		return t.Base
+	}
+	return http.DefaultTransport
+}
+
+func (t *RequestIDTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	header := t.Header
+	if header == "" {
+		header = "X-Request-Id"
+	}
+
+	set := make(http.Header)
+	ctx := req.Context()
+	if id, ok := RequestIDFromContext(ctx); ok && req.Header.Get(header) == "" {
+		set.Set(header, id)
+	}
+	if tp, ok := TraceParentFromContext(ctx); ok && req.Header.Get("Traceparent") == "" {
+		set.Set("Traceparent", tp.String())
+		if state := TraceStateFromContext(ctx); state != "" {
+			set.Set("Tracestate", state)
+		}
+	}
+	if len(set) > 0 {
+		req = req.Clone(ctx)
+		for k, vs := range set {
+			req.Header[k] = vs
+		}
+	}
+	return t.base().RoundTrip(req)
+}
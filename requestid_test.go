@@ -0,0 +1,114 @@
+// Copyright 2026 Franklin "Snaipe" Mathieu.
+//
+// Use of this source code is governed by the MIT license that can be
+// found in the LICENSE file.
+
+package htutil
+
+import (
+	"bytes"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+func TestRequestID(t *testing.T) {
+	t.Parallel()
+
+	tcases := []struct {
+		Incoming string
+		Kept     bool
+	}{
+		{Incoming: "", Kept: false},
+		{Incoming: "abc-123", Kept: true},
+		{Incoming: "not a token", Kept: false},
+		{Incoming: strings.Repeat("a", 129), Kept: false},
+	}
+
+	for i, tcase := range tcases {
+		t.Run(fmt.Sprintf("%d", i), func(t *testing.T) {
+			var buf bytes.Buffer
+			var id string
+			handler := WithLogger(RequestID(http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+				id, _ = RequestIDFromContext(req.Context())
+				ContextLogger(req.Context()).Info("hello")
+			}), "X-Request-Id"), newTestLogger(&buf))
+
+			req := httptest.NewRequest("GET", "/", nil)
+			if tcase.Incoming != "" {
+				req.Header.Set("X-Request-Id", tcase.Incoming)
+			}
+			rw := httptest.NewRecorder()
+			handler.ServeHTTP(rw, req)
+
+			if tcase.Kept && id != tcase.Incoming {
+				t.Fatalf("expected %q, got %q", tcase.Incoming, id)
+			}
+			if !tcase.Kept && (len(id) != 32 || id == tcase.Incoming) {
+				t.Fatalf("expected a new request ID, got %q", id)
+			}
+			if got := rw.Header().Get("X-Request-Id"); got != id {
+				t.Fatalf("expected response ID %q, got %q", id, got)
+			}
+			if !strings.Contains(buf.String(), "request_id="+id) {
+				t.Fatalf("expected request ID to be logged, got %q", buf.String())
+			}
+		})
+	}
+}
+
+func TestRequestIDTransport(t *testing.T) {
+	t.Parallel()
+
+	var outbound *http.Request
+	client := &http.Client{Transport: &RequestIDTransport{
+		Base: roundTripFunc(func(req *http.Request) (*http.Response, error) {
+			outbound = req
+			return &http.Response{StatusCode: 200, Body: http.NoBody, Request: req}, nil
+		}),
+	}}
+
+	var tp TraceParent
+	handler := RequestID(TraceContext(http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+		tp, _ = TraceParentFromContext(req.Context())
+		out, _ := http.NewRequestWithContext(req.Context(), "GET", "http://upstream/", nil)
+		resp, err := client.Do(out)
+		if err != nil {
+			t.Error(err)
+			return
+		}
+		resp.Body.Close()
+	})), "X-Request-Id")
+
+	req := httptest.NewRequest("GET", "/", nil)
+	req.Header.Set("X-Request-Id", "abc-123")
+	req.Header.Set("Traceparent", "00-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-01")
+	req.Header.Set("Tracestate", "a=1")
+	handler.ServeHTTP(httptest.NewRecorder(), req)
+
+	if outbound == nil {
+		t.Fatal("expected an outbound request")
+	}
+	if got := outbound.Header.Get("X-Request-Id"); got != "abc-123" {
+		t.Fatalf("expected request ID %q, got %q", "abc-123", got)
+	}
+	if got := outbound.Header.Get("Traceparent"); got != tp.String() {
+		t.Fatalf("expected traceparent %v, got %v", tp, got)
+	}
+	if got := outbound.Header.Get("Tracestate"); got != "a=1" {
+		t.Fatalf("expected tracestate %q, got %q", "a=1", got)
+	}
+
+	// Outbound requests outside of any inbound request are left untouched.
+	out, _ := http.NewRequest("GET", "http://upstream/", nil)
+	resp, err := client.Do(out)
+	if err != nil {
+		t.Fatal(err)
+	}
+	resp.Body.Close()
+	if len(outbound.Header) != 0 {
+		t.Fatalf("expected no header fields, got %v", outbound.Header)
+	}
+}
@@ -0,0 +1,121 @@
+// Copyright 2026 Franklin "Snaipe" Mathieu.
+//
+// Use of this source code is governed by the MIT license that can be
+// found in the LICENSE file.
+
+package htutil
+
+import (
+	"context"
+	"errors"
+	"log/slog"
+	"net/http"
+	"strings"
+)
+
+type principalKey struct{}
+
+// Principal returns the principal of the request that ctx belongs to, as
+// authenticated by RequireBasicAuth or RequireBearer, if it is a T. The
+// principal of Basic authentication is the user name, as a string.
+func Principal[T any](ctx context.Context) (T, bool) {
+	p, ok := ctx.Value(principalKey{}).(T)
+	return p, ok
+}
+
+// PasswordVerifier returns a verify function for RequireBasicAuth, checking
+// passwords against the hashes of lookup, which returns the password hash
+// of user, or ok=false if there is no such user.
+//
+// check returns whether password matches hash, with a slow and salted
+// password hashing function like bcrypt or argon2. Unknown users are
+// checked against dummy, the hash of any password, so that they take as
+// long to reject as wrong passwords.
+func PasswordVerifier(lookup func(user string) (hash []byte, ok bool), check func(hash []byte, password string) bool, dummy []byte) func(user, password string) bool {
+	return func(user, password string) bool {
+		hash, found := lookup(user)
+		if !found {
+			hash = dummy
+		}
+		return check(hash, password) && found
+	}
+}
+
+// RequireBasicAuth returns a middleware authenticating requests with Basic
+// credentials, as per RFC 7617, for the protection space realm.
+//
+// verify returns whether password is the password of user. It must take
+// as long to reject unknown users as wrong passwords, which the functions
+// returned by PasswordVerifier do. The user name is available to next
+// through Principal[string].
+//
+// Requests without valid credentials are rejected with a 401 Unauthorized
+// problem, and a Basic challenge in WWW-Authenticate.
+func RequireBasicAuth(realm string, verify func(user, password string) bool) Middleware {
+	challenge := FormatChallenges(BasicChallenge(realm))
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+			creds, err := ParseCredentials(req.Header.Get("Authorization"))
+			user, password, ok := creds.Basic()
+			if err != nil || !ok {
+				w.Header().Set("WWW-Authenticate", challenge)
+				RespondError(w, req, NewProblem(http.StatusUnauthorized, "Basic credentials are required."))
+				return
+			}
+
+			if !verify(user, password) {
+				ContextLogger(req.Context()).Warn("rejecting basic credentials",
+					slog.String("remote_addr", req.RemoteAddr), slog.String("user", user))
+				w.Header().Set("WWW-Authenticate", challenge)
+				RespondError(w, req, NewProblem(http.StatusUnauthorized, "The credentials are invalid."))
+				return
+			}
+			ctx := context.WithValue(req.Context(), principalKey{}, user)
+			next.ServeHTTP(w, req.WithContext(ctx))
+		})
+	}
+}
+
+// RequireBearer returns a middleware authenticating requests with Bearer
+// access tokens, as per RFC 6750, for the protection space realm, which
+// may be empty.
+//
+// validate returns the principal that token was issued to, which is
+// available to next through Principal, or an error if token is not valid.
+// Tokens known to the server must be compared in constant time, as with
+// crypto/subtle.ConstantTimeCompare, or looked up by their digest.
+//
+// Requests without a Bearer token are rejected with a 401 Unauthorized
+// problem, and a Bearer challenge in WWW-Authenticate, which reports an
+// invalid_token error for invalid tokens. If the error of validate unwraps
+// to a 403 Forbidden *Problem, the token is valid but lacks the scope of
+// the request, and the problem is sent with an insufficient_scope error.
+func RequireBearer(realm string, validate func(token string) (principal any, err error)) Middleware {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+			creds, err := ParseCredentials(req.Header.Get("Authorization"))
+			if err != nil || !strings.EqualFold(creds.Scheme, "Bearer") || creds.Token68 == "" {
+				w.Header().Set("WWW-Authenticate", FormatChallenges(BearerChallenge(realm, nil, "", "")))
+				RespondError(w, req, NewProblem(http.StatusUnauthorized, "A bearer token is required."))
+				return
+			}
+
+			principal, err := validate(creds.Token68)
+			if err != nil {
+				ContextLogger(req.Context()).Warn("rejecting bearer token",
+					slog.String("remote_addr", req.RemoteAddr), slog.String("error", err.Error()))
+				var p *Problem
+				if errors.As(err, &p) && p.Status == http.StatusForbidden {
+					w.Header().Set("WWW-Authenticate", FormatChallenges(BearerChallenge(realm, nil, "insufficient_scope", "")))
+					RespondError(w, req, p)
+					return
+				}
+				w.Header().Set("WWW-Authenticate", FormatChallenges(BearerChallenge(realm, nil, "invalid_token", "")))
+				RespondError(w, req, NewProblem(http.StatusUnauthorized, "The bearer token is invalid."))
+				return
+			}
+			ctx := context.WithValue(req.Context(), principalKey{}, principal)
+			next.ServeHTTP(w, req.WithContext(ctx))
+		})
+	}
+}
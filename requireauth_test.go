@@ -0,0 +1,143 @@
+// Copyright 2026 Franklin "Snaipe" Mathieu.
+//
+// Use of this source code is governed by the MIT license that can be
+// found in the LICENSE file.
+
+package htutil
+
+import (
+	"crypto/sha256"
+	"crypto/subtle"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestRequireBasicAuth(t *testing.T) {
+	t.Parallel()
+
+	// A salted digest stands for a password hashing function.
+	hash := func(salt, password string) []byte {
+		sum := sha256.Sum256([]byte(salt + password))
+		return append([]byte(salt), sum[:]...)
+	}
+	var checked []string
+	check := func(h []byte, password string) bool {
+		checked = append(checked, password)
+		return subtle.ConstantTimeCompare(h, hash(string(h[:4]), password)) == 1
+	}
+	users := map[string][]byte{"alice": hash("salt", "wonderland"), "dummy": hash("none", "")}
+	lookup := func(user string) ([]byte, bool) {
+		h, ok := users[user]
+		return h, ok && user != "dummy"
+	}
+	mw := RequireBasicAuth("admin area", PasswordVerifier(lookup, check, users["dummy"]))
+	handler := mw(http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+		user, _ := Principal[string](req.Context())
+		fmt.Fprint(w, user)
+	}))
+
+	tcases := []struct {
+		Authorization string
+		Status        int
+		Body          string
+		Challenge     string
+	}{
+		{BasicCredentials("alice", "wonderland").String(), http.StatusOK, "alice", ""},
+		{BasicCredentials("alice", "looking-glass").String(), http.StatusUnauthorized, "", `Basic realm="admin area", charset="UTF-8"`},
+		{BasicCredentials("bob", "wonderland").String(), http.StatusUnauthorized, "", `Basic realm="admin area", charset="UTF-8"`},
+		{"", http.StatusUnauthorized, "", `Basic realm="admin area", charset="UTF-8"`},
+		{"Basic !!!", http.StatusUnauthorized, "", `Basic realm="admin area", charset="UTF-8"`},
+		{BearerCredentials("alice").String(), http.StatusUnauthorized, "", `Basic realm="admin area", charset="UTF-8"`},
+	}
+
+	for i, tcase := range tcases {
+		t.Run(fmt.Sprintf("%d", i), func(t *testing.T) {
+			req := httptest.NewRequest("GET", "/", nil)
+			if tcase.Authorization != "" {
+				req.Header.Set("Authorization", tcase.Authorization)
+			}
+			w := httptest.NewRecorder()
+			handler.ServeHTTP(w, req)
+
+			if w.Code != tcase.Status {
+				t.Fatalf("expected status %d, got %d", tcase.Status, w.Code)
+			}
+			if got := w.Header().Get("WWW-Authenticate"); got != tcase.Challenge {
+				t.Fatalf("expected challenge %q, got %q", tcase.Challenge, got)
+			}
+			if tcase.Status == http.StatusOK && w.Body.String() != tcase.Body {
+				t.Fatalf("expected body %q, got %q", tcase.Body, w.Body.String())
+			}
+		})
+	}
+
+	// Unknown users are checked too, against the dummy hash.
+	if len(checked) != 3 || checked[2] != "wonderland" {
+		t.Fatalf("expected every password to be checked, got %v", checked)
+	}
+}
+
+func TestRequireBearer(t *testing.T) {
+	t.Parallel()
+
+	type claims struct{ Subject string }
+
+	mw := RequireBearer("api", func(token string) (any, error) {
+		switch token {
+		case "good":
+			return claims{Subject: "alice"}, nil
+		case "narrow":
+			return nil, fmt.Errorf("missing scope: %w", NewProblem(http.StatusForbidden, "The token lacks the write scope."))
+		}
+		return nil, errors.New("unknown token")
+	})
+	handler := mw(http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+		c, ok := Principal[claims](req.Context())
+		if !ok {
+			t.Errorf("expected claims in the request context")
+		}
+		if _, ok := Principal[string](req.Context()); ok {
+			t.Errorf("expected no string principal")
+		}
+		fmt.Fprint(w, c.Subject)
+	}))
+
+	tcases := []struct {
+		Authorization string
+		Status        int
+		Body          string
+		Challenge     string
+	}{
+		{"Bearer good", http.StatusOK, "alice", ""},
+		{"bearer good", http.StatusOK, "alice", ""},
+		{"Bearer bad", http.StatusUnauthorized, "", `Bearer realm="api", error="invalid_token"`},
+		{"Bearer narrow", http.StatusForbidden, "", `Bearer realm="api", error="insufficient_scope"`},
+		{"", http.StatusUnauthorized, "", `Bearer realm="api"`},
+		{BasicCredentials("alice", "good").String(), http.StatusUnauthorized, "", `Bearer realm="api"`},
+		{"Bearer realm=x", http.StatusUnauthorized, "", `Bearer realm="api"`},
+	}
+
+	for i, tcase := range tcases {
+		t.Run(fmt.Sprintf("%d", i), func(t *testing.T) {
+			req := httptest.NewRequest("GET", "/", nil)
+			if tcase.Authorization != "" {
+				req.Header.Set("Authorization", tcase.Authorization)
+			}
+			w := httptest.NewRecorder()
+			handler.ServeHTTP(w, req)
+
+			if w.Code != tcase.Status {
+				t.Fatalf("expected status %d, got %d", tcase.Status, w.Code)
+			}
+			if got := w.Header().Get("WWW-Authenticate"); got != tcase.Challenge {
+				t.Fatalf("expected challenge %q, got %q", tcase.Challenge, got)
+			}
+			if tcase.Status == http.StatusOK && w.Body.String() != tcase.Body {
+				t.Fatalf("expected body %q, got %q", tcase.Body, w.Body.String())
+			}
+		})
+	}
+}
@@ -0,0 +1,238 @@
+// Copyright 2026 Franklin "Snaipe" Mathieu.
+//
+// Use of this source code is governed by the MIT license that can be
+// found in the LICENSE file.
+
+package htutil
+
+import (
+	"context"
+	"errors"
+	"io"
+	"log/slog"
+	"math/rand"
+	"net"
+	"net/http"
+	"syscall"
+	"time"
+)
+
+// RetryAttempt describes an attempt of a RetryTransport at sending a
+// request.
+type RetryAttempt struct {
+	// Request is the request that was sent.
+	Request *http.Request
+
+	// Attempt is the number of the attempt, starting at 1.
+	Attempt int
+
+	// Response is the response to the attempt, if any. Its body must not be
+	// read, as it is either returned to the caller or discarded.
+	Response *http.Response
+
+	// Err is the error of the attempt, if any.
+	Err error
+
+	// Duration is the time that the attempt took.
+	Duration time.Duration
+
+	// Delay is the delay before the next attempt, or 0 if the request is
+	// not retried.
+	Delay time.Duration
+}
+
+// RetryTransport is a http.RoundTripper that retries requests that failed
+// transiently, with an exponential backoff.
+//
+// Requests are retried on transient network errors, like connection
+// resets, if their method is idempotent, and on responses whose status
+// allows retrying them, as per Status.IsRetryable, like 429 Too Many
+// Requests, or 503 Service Unavailable for idempotent requests. The
+// Retry-After of responses takes precedence over the backoff.
+//
+// Requests with a body are retried only if it can be replayed; see
+// RequestBody.
+type RetryTransport struct {
+	// Base is the underlying transport. Defaults to http.DefaultTransport.
+	Base http.RoundTripper
+
+	// MaxAttempts is the maximum number of attempts at sending a request.
+	// Defaults to 3.
+	MaxAttempts int
+
+	// MinBackoff is the delay before the first retry, which doubles for
+	// every subsequent retry. Defaults to 100ms. Delays are randomized
+	// between half and all of their value, so that clients do not retry in
+	// lockstep.
+	MinBackoff time.Duration
+
+	// MaxBackoff caps the backoff delay. Responses requesting a longer
+	// Retry-After are not retried. Defaults to 30s.
+	MaxBackoff time.Duration
+
+	// Skew, if set, is the clock skew of the server, with which Retry-After
+	// dates are interpreted.
+	Skew *ClockSkew
+
+	// OnAttempt, if set, is called after every attempt, for instance to
+	// collect metrics.
+	OnAttempt func(RetryAttempt)
+}
+
+func (t *RetryTransport) base() http.RoundTripper {
+	if t.Base != nil {
+		return t.Base
+	}
+	return http.DefaultTransport
+}
+
+func (t *RetryTransport) maxAttempts() int {
+	if t.MaxAttempts > 0 {
+		return t.MaxAttempts
+	}
+	return 3
+}
+
+func (t *RetryTransport) minBackoff() time.Duration {
+	if t.MinBackoff > 0 {
+		return t.MinBackoff
+	}
+	return 100 * time.Millisecond
+}
+
+func (t *RetryTransport) maxBackoff() time.Duration {
+	if t.MaxBackoff > 0 {
+		return t.MaxBackoff
+	}
+	return 30 * time.Second
+}
+
+func (t *RetryTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	ctx := req.Context()
+	logger := ContextLogger(ctx)
+	attemptReq := req
+	for attempt := 1; ; attempt++ {
+		start := time.Now()
+		resp, err := t.base().RoundTrip(attemptReq)
+
+		delay, retry := t.delay(req, attempt, resp, err)
+		var next *http.Request
+		if retry {
+			var rerr error
+			if next, rerr = rewind(req); rerr != nil {
+				logger.Debug("not retrying request",
+					slog.String("method", req.Method), slog.Any("url", URL{req.URL}), slog.String("error", rerr.Error()))
+				delay, retry = 0, false
+			}
+		}
+		if t.OnAttempt != nil {
+			t.OnAttempt(RetryAttempt{
+				Request:  attemptReq,
+				Attempt:  attempt,
+				Response: resp,
+				Err:      err,
+				Duration: time.Since(start),
+				Delay:    delay,
+			})
+		}
+		if !retry {
+			return resp, err
+		}
+
+		attrs := []any{slog.String("method", req.Method), slog.Any("url", URL{req.URL}),
+			slog.Int("attempt", attempt), slog.Duration("delay", delay)}
+		if err != nil {
+			attrs = append(attrs, slog.String("error", err.Error()))
+		} else {
+			attrs = append(attrs, slog.Int("status", resp.StatusCode))
+			io.Copy(io.Discard, io.LimitReader(resp.Body, 4<<10))
+			resp.Body.Close()
+		}
+		logger.Debug("retrying request", attrs...)
+
+		if err := sleepContext(ctx, delay); err != nil {
+			closeBody(next)
+			return nil, err
+		}
+		attemptReq = next
+	}
+}
+
+// delay returns the delay before retrying req after the specified attempt,
+// which got resp or err, and whether to retry it at all.
+func (t *RetryTransport) delay(req *http.Request, attempt int, resp *http.Response, err error) (time.Duration, bool) {
+	if attempt >= t.maxAttempts() || req.Context().Err() != nil {
+		return 0, false
+	}
+	if err != nil {
+		if !isIdempotent(req.Method) || !isTransient(err) {
+			return 0, false
+		}
+		return t.backoff(attempt), true
+	}
+	if !Status(resp.StatusCode).IsRetryable(req.Method) {
+		return 0, false
+	}
+	if resp.Header.Get("Retry-After") != "" {
+		var (
+			delay time.Duration
+			ok    bool
+		)
+		if t.Skew != nil {
+			delay, ok = t.Skew.RetryAfter(resp.Header)
+		} else {
+			var err error
+			delay, err = ParseRetryAfter(resp.Header.Get("Retry-After"), time.Now())
+			ok = err == nil
+		}
+		if ok && delay > t.maxBackoff() {
+			return 0, false
+		} else if ok {
+			return delay, true
+		}
+	}
+	return t.backoff(attempt), true
+}
+
+// backoff returns the randomized exponential backoff delay after the
+// specified attempt.
+func (t *RetryTransport) backoff(attempt int) time.Duration {
+	d, limit := t.minBackoff(), t.maxBackoff()
+	for i := 1; i < attempt && d < limit; i++ {
+		d *= 2
+	}
+	if d > limit {
+		d = limit
+	}
+	return d/2 + time.Duration(rand.Int63n(int64(d/2)+1))
+}
+
+// isTransient returns whether err is a network error that may not happen
+// again if the request is retried.
+func isTransient(err error) bool {
+	if errors.Is(err, context.Canceled) || errors.Is(err, context.DeadlineExceeded) {
+		return false
+	}
+	var netErr net.Error
+	if errors.As(err, &netErr) && netErr.Timeout() {
+		return true
+	}
+	return errors.Is(err, syscall.ECONNRESET) ||
+		errors.Is(err, syscall.ECONNREFUSED) ||
+		errors.Is(err, syscall.ECONNABORTED) ||
+		errors.Is(err, syscall.EPIPE) ||
+		errors.Is(err, io.ErrUnexpectedEOF) ||
+		errors.Is(err, io.EOF)
+}
+
+// sleepContext waits for d, or until ctx is done.
+func sleepContext(ctx context.Context, d time.Duration) error {
+	timer := time.NewTimer(d)
+	defer timer.Stop()
+	select {
+	case <-timer.C:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
@@ -0,0 +1,186 @@
+// Copyright 2026 Franklin "Snaipe" Mathieu.
+//
+// Use of this source code is governed by the MIT license that can be
+// found in the LICENSE file.
+
+package htutil
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync/atomic"
+	"syscall"
+	"testing"
+	"time"
+)
+
+func TestRetryTransport(t *testing.T) {
+	t.Parallel()
+
+	tcases := []struct {
+		Method     string
+		Body       string
+		Statuses   []int
+		RetryAfter string
+		Status     int
+		Attempts   int32
+	}{
+		{Method: "GET", Statuses: []int{200}, Status: 200, Attempts: 1},
+		{Method: "GET", Statuses: []int{503, 200}, Status: 200, Attempts: 2},
+		{Method: "GET", Statuses: []int{502, 504, 200}, Status: 200, Attempts: 3},
+		{Method: "GET", Statuses: []int{503, 503, 503, 200}, Status: 503, Attempts: 3},
+		{Method: "GET", Statuses: []int{500, 200}, Status: 500, Attempts: 1},
+		{Method: "GET", Statuses: []int{404, 200}, Status: 404, Attempts: 1},
+		{Method: "GET", Statuses: []int{429, 200}, RetryAfter: "0", Status: 200, Attempts: 2},
+		{Method: "GET", Statuses: []int{429, 200}, RetryAfter: "3600", Status: 429, Attempts: 1},
+		{Method: "PUT", Body: "payload", Statuses: []int{503, 200}, Status: 200, Attempts: 2},
+		{Method: "POST", Body: "payload", Statuses: []int{503, 200}, Status: 503, Attempts: 1},
+		{Method: "POST", Body: "payload", Statuses: []int{429, 200}, Status: 200, Attempts: 2},
+	}
+
+	for i, tcase := range tcases {
+		t.Run(fmt.Sprintf("%d", i), func(t *testing.T) {
+			var attempts atomic.Int32
+			srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+				n := attempts.Add(1)
+				body, _ := io.ReadAll(req.Body)
+				if string(body) != tcase.Body {
+					t.Errorf("attempt %d: expected body %q, got %q", n, tcase.Body, body)
+				}
+				if tcase.RetryAfter != "" {
+					w.Header().Set("Retry-After", tcase.RetryAfter)
+				}
+				w.WriteHeader(tcase.Statuses[n-1])
+			}))
+			defer srv.Close()
+
+			var hooked []RetryAttempt
+			client := &http.Client{Transport: &RetryTransport{
+				Base:       srv.Client().Transport,
+				MinBackoff: time.Millisecond,
+				OnAttempt:  func(a RetryAttempt) { hooked = append(hooked, a) },
+			}}
+
+			var body io.Reader
+			if tcase.Body != "" {
+				body = strings.NewReader(tcase.Body)
+			}
+			req, _ := http.NewRequest(tcase.Method, srv.URL, body)
+			resp, err := client.Do(req)
+			if err != nil {
+				t.Fatal(err)
+			}
+			resp.Body.Close()
+
+			if resp.StatusCode != tcase.Status {
+				t.Fatalf("expected status %d, got %d", tcase.Status, resp.StatusCode)
+			}
+			if n := attempts.Load(); n != tcase.Attempts {
+				t.Fatalf("expected %d attempts, got %d", tcase.Attempts, n)
+			}
+			if len(hooked) != int(tcase.Attempts) {
+				t.Fatalf("expected %d hooked attempts, got %d", tcase.Attempts, len(hooked))
+			}
+			for j, a := range hooked {
+				if a.Attempt != j+1 || a.Response == nil {
+					t.Fatalf("unexpected attempt %d: %+v", j+1, a)
+				}
+			}
+			if last := hooked[len(hooked)-1]; last.Delay != 0 {
+				t.Fatalf("expected no delay after the last attempt, got %v", last.Delay)
+			}
+		})
+	}
+}
+
+type roundTripFunc func(*http.Request) (*http.Response, error)
+
+func (f roundTripFunc) RoundTrip(req *http.Request) (*http.Response, error) {
+	return f(req)
+}
+
+func TestRetryTransportErrors(t *testing.T) {
+	t.Parallel()
+
+	tcases := []struct {
+		Method   string
+		Err      error
+		Attempts int
+	}{
+		{Method: "GET", Err: syscall.ECONNRESET, Attempts: 3},
+		{Method: "GET", Err: fmt.Errorf("reading response: %w", io.ErrUnexpectedEOF), Attempts: 3},
+		{Method: "GET", Err: errors.New("tls: bad certificate"), Attempts: 1},
+		{Method: "GET", Err: context.Canceled, Attempts: 1},
+		{Method: "POST", Err: syscall.ECONNRESET, Attempts: 1},
+	}
+
+	for i, tcase := range tcases {
+		t.Run(fmt.Sprintf("%d", i), func(t *testing.T) {
+			var attempts int
+			transport := &RetryTransport{
+				Base: roundTripFunc(func(req *http.Request) (*http.Response, error) {
+					attempts++
+					return nil, tcase.Err
+				}),
+				MinBackoff: time.Millisecond,
+			}
+			req, _ := http.NewRequest(tcase.Method, "http://example.com", nil)
+			_, err := transport.RoundTrip(req)
+			if !errors.Is(err, tcase.Err) {
+				t.Fatalf("expected error %v, got %v", tcase.Err, err)
+			}
+			if attempts != tcase.Attempts {
+				t.Fatalf("expected %d attempts, got %d", tcase.Attempts, attempts)
+			}
+		})
+	}
+}
+
+func TestRetryTransportCancel(t *testing.T) {
+	t.Parallel()
+
+	ctx, cancel := context.WithCancel(context.Background())
+	transport := &RetryTransport{
+		Base: roundTripFunc(func(req *http.Request) (*http.Response, error) {
+			cancel()
+			return nil, syscall.ECONNRESET
+		}),
+		MinBackoff: time.Hour,
+	}
+	req, _ := http.NewRequestWithContext(ctx, "GET", "http://example.com", nil)
+	if _, err := transport.RoundTrip(req); !errors.Is(err, syscall.ECONNRESET) {
+		t.Fatalf("expected error %v, got %v", syscall.ECONNRESET, err)
+	}
+}
+
+func TestRetryTransportBackoff(t *testing.T) {
+	t.Parallel()
+
+	transport := &RetryTransport{MinBackoff: time.Second, MaxBackoff: 5 * time.Second}
+	tcases := []struct {
+		Attempt  int
+		Min, Max time.Duration
+	}{
+		{Attempt: 1, Min: 500 * time.Millisecond, Max: time.Second},
+		{Attempt: 2, Min: time.Second, Max: 2 * time.Second},
+		{Attempt: 3, Min: 2 * time.Second, Max: 4 * time.Second},
+		{Attempt: 4, Min: 2500 * time.Millisecond, Max: 5 * time.Second},
+		{Attempt: 40, Min: 2500 * time.Millisecond, Max: 5 * time.Second},
+	}
+
+	for i, tcase := range tcases {
+		t.Run(fmt.Sprintf("%d", i), func(t *testing.T) {
+			for j := 0; j < 100; j++ {
+				d := transport.backoff(tcase.Attempt)
+				if d < tcase.Min || d > tcase.Max {
+					t.Fatalf("expected backoff between %v and %v, got %v", tcase.Min, tcase.Max, d)
+				}
+			}
+		})
+	}
+}
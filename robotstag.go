@@ -0,0 +1,89 @@
+// Copyright 2026 Franklin "Snaipe" Mathieu.
+//
+// Use of this source code is governed by the MIT license that can be
+// found in the LICENSE file.
+
+package htutil
+
+import (
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// RobotsTag is a set of indexing directives for crawlers, emitted via the
+// X-Robots-Tag header field.
+type RobotsTag struct {
+	// UserAgent scopes the directives to a particular crawler, like
+	// "googlebot". If empty, the directives apply to all crawlers.
+	UserAgent string
+
+	NoIndex         bool
+	NoFollow        bool
+	NoArchive       bool
+	NoSnippet       bool
+	NoImageIndex    bool
+	NoTranslate     bool
+	IndexIfEmbedded bool
+
+	// MaxSnippet and MaxVideoPreview limit the length in characters of text
+	// snippets and in seconds of video previews respectively. Zero omits
+	// the directive; -1 means no limit.
+	MaxSnippet      int
+	MaxVideoPreview int
+
+	// MaxImagePreview is one of "none", "standard", or "large", or empty
+	// to omit the directive.
+	MaxImagePreview string
+
+	// UnavailableAfter, if not zero, is the date after which the response
+	// should no longer appear in search results.
+	UnavailableAfter time.Time
+}
+
+// String formats t as an X-Robots-Tag header value.
+func (t RobotsTag) String() string {
+	var directives []string
+	flag := func(set bool, name string) {
+		if set {
+			directives = append(directives, name)
+		}
+	}
+	flag(t.NoIndex, "noindex")
+	flag(t.NoFollow, "nofollow")
+	flag(t.NoArchive, "noarchive")
+	flag(t.NoSnippet, "nosnippet")
+	flag(t.NoImageIndex, "noimageindex")
+	flag(t.NoTranslate, "notranslate")
+	flag(t.IndexIfEmbedded, "indexifembedded")
+	if t.MaxSnippet != 0 {
+		directives = append(directives, "max-snippet:"+strconv.Itoa(t.MaxSnippet))
+	}
+	if t.MaxImagePreview != "" {
+		directives = append(directives, "max-image-preview:"+t.MaxImagePreview)
+	}
+	if t.MaxVideoPreview != 0 {
+		directives = append(directives, "max-video-preview:"+strconv.Itoa(t.MaxVideoPreview))
+	}
+	if !t.UnavailableAfter.IsZero() {
+		directives = append(directives, "unavailable_after: "+t.UnavailableAfter.UTC().Format(http.TimeFormat))
+	}
+	if len(directives) == 0 {
+		directives = append(directives, "all")
+	}
+
+	value := strings.Join(directives, ", ")
+	if t.UserAgent != "" {
+		value = t.UserAgent + ": " + value
+	}
+	return value
+}
+
+// AddRobotsTag adds one X-Robots-Tag header field to h per tag, such that
+// directives scoped to different crawlers do not get mixed up.
+func AddRobotsTag(h http.Header, tags ...RobotsTag) {
+	for _, tag := range tags {
+		h.Add("X-Robots-Tag", tag.String())
+	}
+}
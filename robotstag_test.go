@@ -0,0 +1,46 @@
+// Copyright 2026 Franklin "Snaipe" Mathieu.
+//
+// Use of this source code is governed by the MIT license that can be
+// found in the LICENSE file.
+
+package htutil
+
+import (
+	"fmt"
+	"net/http"
+	"reflect"
+	"testing"
+	"time"
+)
+
+func TestRobotsTag(t *testing.T) {
+	t.Parallel()
+
+	tcases := []struct {
+		Tag RobotsTag
+		Out string
+	}{
+		{Tag: RobotsTag{}, Out: "all"},
+		{Tag: RobotsTag{NoIndex: true, NoFollow: true}, Out: "noindex, nofollow"},
+		{Tag: RobotsTag{UserAgent: "googlebot", NoArchive: true, MaxSnippet: -1, MaxImagePreview: "large"}, Out: "googlebot: noarchive, max-snippet:-1, max-image-preview:large"},
+		{
+			Tag: RobotsTag{UnavailableAfter: time.Date(2023, 6, 30, 23, 59, 59, 0, time.UTC)},
+			Out: "unavailable_after: Fri, 30 Jun 2023 23:59:59 GMT",
+		},
+	}
+
+	for i, tcase := range tcases {
+		t.Run(fmt.Sprintf("%d", i), func(t *testing.T) {
+			if actual := tcase.Tag.String(); actual != tcase.Out {
+				t.Fatalf("expected %q, got %q", tcase.Out, actual)
+			}
+		})
+	}
+
+	h := http.Header{}
+	AddRobotsTag(h, RobotsTag{NoIndex: true}, RobotsTag{UserAgent: "otherbot", NoFollow: true})
+	expected := []string{"noindex", "otherbot: nofollow"}
+	if !reflect.DeepEqual(h.Values("X-Robots-Tag"), expected) {
+		t.Fatalf("expected %q, got %q", expected, h.Values("X-Robots-Tag"))
+	}
+}
@@ -0,0 +1,213 @@
+// Copyright 2026 Franklin "Snaipe" Mathieu.
+//
+// Use of this source code is governed by the MIT license that can be
+// found in the LICENSE file.
+
+package htutil
+
+import (
+	"context"
+	"net/http"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+// ServerTimingMetric is a metric of the Server-Timing header field, as per
+// the W3C Server Timing specification.
+type ServerTimingMetric struct {
+	// Name identifies the metric. It must be a token.
+	Name string
+
+	// Duration is the duration of the metric, serialized in milliseconds
+	// with microsecond precision. Zero durations are omitted.
+	Duration time.Duration
+
+	// Description is a human-readable description of the metric.
+	Description string
+}
+
+// String returns the serialization of m as a Server-Timing metric.
+func (m ServerTimingMetric) String() string {
+	var out strings.Builder
+	out.WriteString(m.Name)
+	if m.Duration != 0 {
+		ms := float64(m.Duration.Round(time.Microsecond)) / float64(time.Millisecond)
+		out.WriteString(";dur=" + strconv.FormatFloat(ms, 'f', -1, 64))
+	}
+	if m.Description != "" {
+		out.WriteString(";desc=" + tokenOrQuoted(m.Description))
+	}
+	return out.String()
+}
+
+// FormatServerTiming returns the value of a Server-Timing header field
+// conveying metrics, in order. Metrics whose name is not a token are
+// skipped.
+func FormatServerTiming(metrics ...ServerTimingMetric) string {
+	values := make([]string, 0, len(metrics))
+	for _, m := range metrics {
+		if IsToken(m.Name) {
+			values = append(values, m.String())
+		}
+	}
+	return strings.Join(values, ", ")
+}
+
+type timingKey struct{}
+
+// Timing records the server timing metrics of a request, as installed in
+// the request context by a ServerTiming middleware. It is safe for
+// concurrent use, and a nil *Timing records nothing, so that handlers need
+// not check whether the middleware is present.
+type Timing struct {
+	mu      sync.Mutex
+	metrics []ServerTimingMetric
+}
+
+// TimingFromContext returns the Timing of the request that ctx belongs to,
+// or nil if there is none.
+func TimingFromContext(ctx context.Context) *Timing {
+	t, _ := ctx.Value(timingKey{}).(*Timing)
+	return t
+}
+
+// Add records a metric, with an optional duration and description.
+func (t *Timing) Add(name string, dur time.Duration, desc string) {
+	if t == nil {
+		return
+	}
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	t.metrics = append(t.metrics, ServerTimingMetric{Name: name, Duration: dur, Description: desc})
+}
+
+// Start starts measuring the duration of a metric, and returns a function
+// recording it once called:
+//
+//	defer htutil.TimingFromContext(ctx).Start("db", "Database")()
+func (t *Timing) Start(name, desc string) func() {
+	start := time.Now()
+	return func() {
+		t.Add(name, time.Since(start), desc)
+	}
+}
+
+// Metrics returns a copy of the metrics recorded so far, in order.
+func (t *Timing) Metrics() []ServerTimingMetric {
+	if t == nil {
+		return nil
+	}
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	return append([]ServerTimingMetric(nil), t.metrics...)
+}
+
+// ServerTiming is a middleware reporting the metrics recorded through the
+// Timing of the request context in the Server-Timing header field of the
+// response.
+type ServerTiming struct {
+	// Total, if set, is the name of a metric recording the total duration
+	// of the handler.
+	Total string
+
+	// Trailer reports the metrics recorded after the response header was
+	// written in a Server-Timing trailer field, for clients that support
+	// trailers; they are dropped otherwise, as well as from responses
+	// without content, or with a Content-Length, as trailers can only be
+	// sent with chunked content in HTTP/1.1. The trailer field is
+	// announced when the header is written, unless the header has a
+	// Server-Timing field already, whose values net/http would send in the
+	// trailer again.
+	Trailer bool
+}
+
+// Middleware returns a middleware reporting the server timing metrics of
+// the requests served by next. The metrics recorded before the response
+// header is written are added to its Server-Timing header field, in the
+// order they were recorded.
+func (st *ServerTiming) Middleware(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+		start := time.Now()
+		tw := &timingResponseWriter{
+			w:       w,
+			timing:  &Timing{},
+			trailer: st.Trailer && req.ProtoAtLeast(1, 1) && req.Method != http.MethodHead,
+		}
+		ctx := context.WithValue(req.Context(), timingKey{}, tw.timing)
+
+		next.ServeHTTP(Wrap(w, WriterHooks{
+			WriteHeader: func(next WriteHeaderFunc) WriteHeaderFunc { return tw.writeHeader(next) },
+			Write:       func(next WriteFunc) WriteFunc { return tw.write(next) },
+		}), req.WithContext(ctx))
+
+		if st.Total != "" {
+			tw.timing.Add(st.Total, time.Since(start), "")
+		}
+		switch {
+		case tw.status == 0:
+			// Nothing was written: the header is still pending.
+			tw.flushMetrics(w.Header())
+		case tw.trailing:
+			if v := tw.pendingMetrics(); v != "" {
+				SetTrailer(w.Header(), "Server-Timing", v)
+			}
+		}
+	})
+}
+
+// timingResponseWriter adds the metrics of a Timing to the response header
+// when it is written. If trailer is set, the response may end with the
+// metrics recorded after that, in which case trailing is set.
+type timingResponseWriter struct {
+	w        http.ResponseWriter
+	timing   *Timing
+	trailer  bool
+	status   int
+	written  int
+	trailing bool
+}
+
+func (tw *timingResponseWriter) writeHeader(next WriteHeaderFunc) WriteHeaderFunc {
+	return func(status int) {
+		if tw.status == 0 && status >= 200 {
+			tw.status = status
+			h := tw.w.Header()
+			tw.flushMetrics(h)
+			if tw.trailer && status != http.StatusNoContent && status != http.StatusNotModified &&
+				h.Get("Content-Length") == "" {
+				tw.trailing = true
+				if len(h.Values("Server-Timing")) == 0 {
+					AnnounceTrailer(h, "Server-Timing")
+				}
+			}
+		}
+		next(status)
+	}
+}
+
+func (tw *timingResponseWriter) write(next WriteFunc) WriteFunc {
+	return func(p []byte) (int, error) {
+		if tw.status == 0 {
+			tw.writeHeader(tw.w.WriteHeader)(http.StatusOK)
+		}
+		return next(p)
+	}
+}
+
+// flushMetrics adds the metrics not reported yet to the Server-Timing
+// field of h.
+func (tw *timingResponseWriter) flushMetrics(h http.Header) {
+	if v := tw.pendingMetrics(); v != "" {
+		h.Add("Server-Timing", v)
+	}
+}
+
+// pendingMetrics returns the metrics not reported yet, formatted as a
+// Server-Timing field value, and marks them as reported.
+func (tw *timingResponseWriter) pendingMetrics() string {
+	metrics := tw.timing.Metrics()[tw.written:]
+	tw.written += len(metrics)
+	return FormatServerTiming(metrics...)
+}
@@ -0,0 +1,125 @@
+// Copyright 2026 Franklin "Snaipe" Mathieu.
+//
+// Use of this source code is governed by the MIT license that can be
+// found in the LICENSE file.
+
+package htutil
+
+import (
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestFormatServerTiming(t *testing.T) {
+	t.Parallel()
+
+	tcases := []struct {
+		Metrics  []ServerTimingMetric
+		Expected string
+	}{
+		{Metrics: []ServerTimingMetric{{Name: "miss"}}, Expected: "miss"},
+		{Metrics: []ServerTimingMetric{{Name: "db", Duration: 53 * time.Millisecond}}, Expected: "db;dur=53"},
+		{Metrics: []ServerTimingMetric{{Name: "db", Duration: 1234567 * time.Nanosecond}}, Expected: "db;dur=1.235"},
+		{Metrics: []ServerTimingMetric{{Name: "cache", Description: "Cache Read", Duration: 2 * time.Millisecond}}, Expected: `cache;dur=2;desc="Cache Read"`},
+		{Metrics: []ServerTimingMetric{{Name: "cdn", Description: "edge"}, {Name: "app", Duration: time.Second}}, Expected: "cdn;desc=edge, app;dur=1000"},
+		{Metrics: []ServerTimingMetric{{Name: "not a token"}, {Name: "ok"}}, Expected: "ok"},
+		{Metrics: []ServerTimingMetric{{Name: "q", Description: `say "hi"`}}, Expected: `q;desc="say \"hi\""`},
+	}
+
+	for i, tcase := range tcases {
+		t.Run(fmt.Sprintf("%d", i), func(t *testing.T) {
+			if actual := FormatServerTiming(tcase.Metrics...); actual != tcase.Expected {
+				t.Fatalf("expected %q, got %q", tcase.Expected, actual)
+			}
+		})
+	}
+}
+
+func TestServerTiming(t *testing.T) {
+	t.Parallel()
+
+	tcases := []struct {
+		ServerTiming ServerTiming
+		Method       string
+		Handler      func(w http.ResponseWriter, req *http.Request)
+		Header       string
+		Trailer      string
+	}{
+		{
+			Handler: func(w http.ResponseWriter, req *http.Request) {
+				TimingFromContext(req.Context()).Add("db", 5*time.Millisecond, "")
+				w.Write([]byte("hello"))
+				TimingFromContext(req.Context()).Add("late", 0, "")
+			},
+			Header: "db;dur=5",
+		},
+		{
+			ServerTiming: ServerTiming{Trailer: true},
+			Handler: func(w http.ResponseWriter, req *http.Request) {
+				TimingFromContext(req.Context()).Add("db", 5*time.Millisecond, "")
+				w.Write([]byte("hello"))
+				TimingFromContext(req.Context()).Add("render", time.Millisecond, "Render")
+			},
+			Header:  "db;dur=5",
+			Trailer: "render;dur=1;desc=Render",
+		},
+		{
+			ServerTiming: ServerTiming{Trailer: true},
+			Method:       "HEAD",
+			Handler: func(w http.ResponseWriter, req *http.Request) {
+				w.WriteHeader(http.StatusOK)
+				TimingFromContext(req.Context()).Add("late", 0, "")
+			},
+		},
+		{
+			// Metrics of handlers that write nothing all end up in the
+			// header.
+			ServerTiming: ServerTiming{Trailer: true},
+			Handler: func(w http.ResponseWriter, req *http.Request) {
+				TimingFromContext(req.Context()).Add("a", 0, "")
+				TimingFromContext(req.Context()).Add("b", 0, "")
+			},
+			Header: "a, b",
+		},
+		{
+			ServerTiming: ServerTiming{Total: "total", Trailer: true},
+			Handler: func(w http.ResponseWriter, req *http.Request) {
+				w.Header().Set("Server-Timing", "upstream;dur=1")
+				defer TimingFromContext(req.Context()).Start("work", "")()
+				w.WriteHeader(http.StatusNoContent)
+			},
+			Header: "upstream;dur=1",
+		},
+	}
+
+	for i, tcase := range tcases {
+		t.Run(fmt.Sprintf("%d", i), func(t *testing.T) {
+			handler := tcase.ServerTiming.Middleware(http.HandlerFunc(tcase.Handler))
+			method := tcase.Method
+			if method == "" {
+				method = "GET"
+			}
+			rw := httptest.NewRecorder()
+			handler.ServeHTTP(rw, httptest.NewRequest(method, "/", nil))
+			resp := rw.Result()
+
+			if actual := strings.Join(resp.Header.Values("Server-Timing"), ", "); actual != tcase.Header {
+				t.Fatalf("expected header %q, got %q", tcase.Header, actual)
+			}
+			if actual := resp.Trailer.Get("Server-Timing"); actual != tcase.Trailer {
+				t.Fatalf("expected trailer %q, got %q", tcase.Trailer, actual)
+			}
+		})
+	}
+
+	var timing *Timing
+	timing.Add("ignored", 0, "")
+	timing.Start("ignored", "")()
+	if metrics := timing.Metrics(); metrics != nil {
+		t.Fatalf("expected no metrics, got %v", metrics)
+	}
+}
@@ -0,0 +1,24 @@
+// Copyright 2026 Franklin "Snaipe" Mathieu.
+//
+// Use of this source code is governed by the MIT license that can be
+// found in the LICENSE file.
+
+package htutil
+
+import "context"
+
+type sessionKey struct{}
+
+// ContextWithSession returns a copy of ctx carrying session, the state of
+// the client session as loaded by the session store of the application,
+// so that handlers retrieve it with SessionFromContext.
+func ContextWithSession(ctx context.Context, session any) context.Context {
+	return context.WithValue(ctx, sessionKey{}, session)
+}
+
+// SessionFromContext returns the session carried by ctx, as set by
+// ContextWithSession.
+func SessionFromContext(ctx context.Context) (any, bool) {
+	session := ctx.Value(sessionKey{})
+	return session, session != nil
+}
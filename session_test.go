@@ -0,0 +1,24 @@
+// Copyright 2026 Franklin "Snaipe" Mathieu.
+//
+// Use of this source code is governed by the MIT license that can be
+// found in the LICENSE file.
+
+package htutil
+
+import (
+	"context"
+	"testing"
+)
+
+func TestSessionFromContext(t *testing.T) {
+	t.Parallel()
+
+	if _, ok := SessionFromContext(context.Background()); ok {
+		t.Fatalf("expected no session")
+	}
+	type session struct{ user string }
+	ctx := ContextWithSession(context.Background(), &session{user: "alice"})
+	if s, ok := SessionFromContext(ctx); !ok || s.(*session).user != "alice" {
+		t.Fatalf("expected the session of alice, got %v", s)
+	}
+}
@@ -0,0 +1,647 @@
+// Copyright 2026 Franklin "Snaipe" Mathieu.
+//
+// Use of this source code is governed by the MIT license that can be
+// found in the LICENSE file.
+
+// Package sfv implements parsing and serialization of Structured Field
+// Values for HTTP, as per RFC 8941 and its Date extension from RFC 9651.
+//
+// Structured fields are used by many modern header fields, like Priority,
+// Cache-Status, Signature-Input, or client hints. Bare items are
+// represented with the following Go types:
+//
+//   - sf-integer: int64
+//   - sf-decimal: float64
+//   - sf-string: string
+//   - sf-token: Token
+//   - sf-binary: []byte
+//   - sf-boolean: bool
+//   - sf-date: time.Time
+//
+// When serializing, int is also accepted for sf-integer.
+//
+// Field values spanning multiple field lines must be combined, by joining
+// them with ", ", before parsing.
+package sfv
+
+import (
+	"encoding/base64"
+	"fmt"
+	"math"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Token is a sf-token bare item.
+type Token string
+
+// Param is a parameter of an item or inner list.
+type Param struct {
+	Key   string
+	Value interface{}
+}
+
+// Params are the parameters of an item or inner list, in order. Keys are
+// unique.
+type Params []Param
+
+// Get returns the value of the parameter with the specified key.
+func (params Params) Get(key string) (interface{}, bool) {
+	for _, p := range params {
+		if p.Key == key {
+			return p.Value, true
+		}
+	}
+	return nil, false
+}
+
+// Item is a bare item with parameters.
+type Item struct {
+	Value  interface{}
+	Params Params
+}
+
+// InnerList is a list of items, with parameters.
+type InnerList struct {
+	Items  []Item
+	Params Params
+}
+
+// Member is a member of a list or dictionary: either an Item or an
+// InnerList.
+type Member interface{}
+
+// List is a sf-list.
+type List []Member
+
+// DictMember is a member of a dictionary. Members with a boolean true
+// value are represented as an Item whose Value is true.
+type DictMember struct {
+	Key    string
+	Member Member
+}
+
+// Dictionary is a sf-dictionary. Keys are unique, and members keep the
+// order in which their key first appeared.
+type Dictionary []DictMember
+
+// Get returns the member with the specified key.
+func (dict Dictionary) Get(key string) (Member, bool) {
+	for _, m := range dict {
+		if m.Key == key {
+			return m.Member, true
+		}
+	}
+	return nil, false
+}
+
+type parser struct {
+	s string
+	i int
+}
+
+func (p *parser) errorf(format string, args ...interface{}) error {
+	return fmt.Errorf("offset %d: %s", p.i, fmt.Sprintf(format, args...))
+}
+
+func (p *parser) eof() bool { return p.i >= len(p.s) }
+
+func (p *parser) peek() byte {
+	if p.eof() {
+		return 0
+	}
+	return p.s[p.i]
+}
+
+func (p *parser) skipSP() {
+	for !p.eof() && p.s[p.i] == ' ' {
+		p.i++
+	}
+}
+
+func (p *parser) skipOWS() {
+	for !p.eof() && isOWS(p.s[p.i]) {
+		p.i++
+	}
+}
+
+func (p *parser) done() error {
+	p.skipSP()
+	if !p.eof() {
+		return p.errorf("unexpected trailing characters")
+	}
+	return nil
+}
+
+// ParseItem parses a field value as an item.
+func ParseItem(s string) (Item, error) {
+	p := parser{s: s}
+	p.skipSP()
+	item, err := p.parseItem()
+	if err == nil {
+		err = p.done()
+	}
+	if err != nil {
+		return Item{}, fmt.Errorf("parsing structured item: %w", err)
+	}
+	return item, nil
+}
+
+// ParseList parses a field value as a list.
+func ParseList(s string) (List, error) {
+	p := parser{s: s}
+	p.skipSP()
+	var list List
+	for !p.eof() {
+		member, err := p.parseMember()
+		if err != nil {
+			return nil, fmt.Errorf("parsing structured list: %w", err)
+		}
+		list = append(list, member)
+		if err := p.next(); err != nil {
+			return nil, fmt.Errorf("parsing structured list: %w", err)
+		}
+	}
+	return list, nil
+}
+
+// ParseDictionary parses a field value as a dictionary. Duplicate keys
+// override the value of previous members.
+func ParseDictionary(s string) (Dictionary, error) {
+	p := parser{s: s}
+	p.skipSP()
+	var dict Dictionary
+	for !p.eof() {
+		key, err := p.parseKey()
+		if err != nil {
+			return nil, fmt.Errorf("parsing structured dictionary: %w", err)
+		}
+		var member Member
+		if p.peek() == '=' {
+			p.i++
+			member, err = p.parseMember()
+		} else {
+			var params Params
+			params, err = p.parseParams()
+			member = Item{Value: true, Params: params}
+		}
+		if err != nil {
+			return nil, fmt.Errorf("parsing structured dictionary: %w", err)
+		}
+
+		replaced := false
+		for i := range dict {
+			if dict[i].Key == key {
+				dict[i].Member = member
+				replaced = true
+			}
+		}
+		if !replaced {
+			dict = append(dict, DictMember{Key: key, Member: member})
+		}
+
+		if err := p.next(); err != nil {
+			return nil, fmt.Errorf("parsing structured dictionary: %w", err)
+		}
+	}
+	return dict, nil
+}
+
+// next skips to the next member of a list or dictionary.
+func (p *parser) next() error {
+	p.skipOWS()
+	if p.eof() {
+		return nil
+	}
+	if p.s[p.i] != ',' {
+		return p.errorf("expected ',', got %q", p.s[p.i])
+	}
+	p.i++
+	p.skipOWS()
+	if p.eof() {
+		return p.errorf("trailing comma")
+	}
+	return nil
+}
+
+func (p *parser) parseMember() (Member, error) {
+	if p.peek() == '(' {
+		return p.parseInnerList()
+	}
+	return p.parseItem()
+}
+
+func (p *parser) parseInnerList() (InnerList, error) {
+	p.i++ // (
+	var list InnerList
+	for {
+		p.skipSP()
+		if p.eof() {
+			return InnerList{}, p.errorf("unterminated inner list")
+		}
+		if p.s[p.i] == ')' {
+			p.i++
+			params, err := p.parseParams()
+			if err != nil {
+				return InnerList{}, err
+			}
+			list.Params = params
+			return list, nil
+		}
+		item, err := p.parseItem()
+		if err != nil {
+			return InnerList{}, err
+		}
+		list.Items = append(list.Items, item)
+		if c := p.peek(); c != ' ' && c != ')' {
+			return InnerList{}, p.errorf("expected ' ' or ')' in inner list")
+		}
+	}
+}
+
+func (p *parser) parseItem() (Item, error) {
+	value, err := p.parseBareItem()
+	if err != nil {
+		return Item{}, err
+	}
+	params, err := p.parseParams()
+	if err != nil {
+		return Item{}, err
+	}
+	return Item{Value: value, Params: params}, nil
+}
+
+func (p *parser) parseParams() (Params, error) {
+	var params Params
+	for p.peek() == ';' {
+		p.i++
+		p.skipSP()
+		key, err := p.parseKey()
+		if err != nil {
+			return nil, err
+		}
+		var value interface{} = true
+		if p.peek() == '=' {
+			p.i++
+			value, err = p.parseBareItem()
+			if err != nil {
+				return nil, err
+			}
+		}
+		replaced := false
+		for i := range params {
+			if params[i].Key == key {
+				params[i].Value = value
+				replaced = true
+			}
+		}
+		if !replaced {
+			params = append(params, Param{Key: key, Value: value})
+		}
+	}
+	return params, nil
+}
+
+func isOWS(c byte) bool { return c == ' ' || c == '\t' }
+
+// isTchar returns whether c is a tchar, as per RFC 9110 §5.6.2.
+func isTchar(c byte) bool {
+	switch {
+	case c >= 'a' && c <= 'z', c >= 'A' && c <= 'Z', c >= '0' && c <= '9':
+		return true
+	}
+	return strings.IndexByte("!#$%&'*+-.^_`|~", c) != -1
+}
+
+func isLcalpha(c byte) bool { return c >= 'a' && c <= 'z' }
+func isDigit(c byte) bool   { return c >= '0' && c <= '9' }
+func isAlpha(c byte) bool   { return (c >= 'a' && c <= 'z') || (c >= 'A' && c <= 'Z') }
+
+func (p *parser) parseKey() (string, error) {
+	if c := p.peek(); !isLcalpha(c) && c != '*' {
+		return "", p.errorf("invalid key")
+	}
+	start := p.i
+	for !p.eof() {
+		c := p.s[p.i]
+		if !isLcalpha(c) && !isDigit(c) && strings.IndexByte("_-.*", c) == -1 {
+			break
+		}
+		p.i++
+	}
+	return p.s[start:p.i], nil
+}
+
+func (p *parser) parseBareItem() (interface{}, error) {
+	switch c := p.peek(); {
+	case c == '-' || isDigit(c):
+		return p.parseNumber()
+	case c == '"':
+		return p.parseString()
+	case c == '*' || isAlpha(c):
+		return p.parseToken()
+	case c == ':':
+		return p.parseBinary()
+	case c == '?':
+		return p.parseBoolean()
+	case c == '@':
+		p.i++
+		n, err := p.parseNumber()
+		if err != nil {
+			return nil, err
+		}
+		secs, ok := n.(int64)
+		if !ok {
+			return nil, p.errorf("date must be an integer")
+		}
+		return time.Unix(secs, 0).UTC(), nil
+	default:
+		return nil, p.errorf("unexpected character %q", c)
+	}
+}
+
+func (p *parser) parseNumber() (interface{}, error) {
+	start := p.i
+	if p.peek() == '-' {
+		p.i++
+	}
+	digits := p.i
+	if !isDigit(p.peek()) {
+		return nil, p.errorf("expected digit")
+	}
+	decimal := false
+	for !p.eof() {
+		c := p.s[p.i]
+		if c == '.' && !decimal {
+			if p.i-digits > 12 {
+				return nil, p.errorf("decimal integer part too long")
+			}
+			decimal = true
+		} else if !isDigit(c) {
+			break
+		}
+		p.i++
+		if !decimal && p.i-digits > 15 || decimal && p.i-digits > 16 {
+			return nil, p.errorf("number too long")
+		}
+	}
+	num := p.s[start:p.i]
+	if !decimal {
+		n, err := strconv.ParseInt(num, 10, 64)
+		if err != nil {
+			return nil, p.errorf("%v", err)
+		}
+		return n, nil
+	}
+	if strings.HasSuffix(num, ".") {
+		return nil, p.errorf("decimal ends with '.'")
+	}
+	if len(num)-strings.IndexByte(num, '.')-1 > 3 {
+		return nil, p.errorf("decimal fraction too long")
+	}
+	f, err := strconv.ParseFloat(num, 64)
+	if err != nil {
+		return nil, p.errorf("%v", err)
+	}
+	return f, nil
+}
+
+func (p *parser) parseString() (string, error) {
+	p.i++ // "
+	var out strings.Builder
+	for !p.eof() {
+		c := p.s[p.i]
+		p.i++
+		switch {
+		case c == '\\':
+			if p.eof() {
+				return "", p.errorf("unterminated string")
+			}
+			c = p.s[p.i]
+			p.i++
+			if c != '"' && c != '\\' {
+				return "", p.errorf("invalid escape %q", c)
+			}
+			out.WriteByte(c)
+		case c == '"':
+			return out.String(), nil
+		case c < 0x20 || c > 0x7E:
+			return "", p.errorf("invalid character %q in string", c)
+		default:
+			out.WriteByte(c)
+		}
+	}
+	return "", p.errorf("unterminated string")
+}
+
+func (p *parser) parseToken() (Token, error) {
+	start := p.i
+	for !p.eof() {
+		c := p.s[p.i]
+		if !isTchar(c) && c != ':' && c != '/' {
+			break
+		}
+		p.i++
+	}
+	return Token(p.s[start:p.i]), nil
+}
+
+func (p *parser) parseBinary() ([]byte, error) {
+	p.i++ // :
+	end := strings.IndexByte(p.s[p.i:], ':')
+	if end == -1 {
+		return nil, p.errorf("unterminated byte sequence")
+	}
+	data, err := base64.StdEncoding.DecodeString(p.s[p.i : p.i+end])
+	if err != nil {
+		return nil, p.errorf("%v", err)
+	}
+	p.i += end + 1
+	return data, nil
+}
+
+func (p *parser) parseBoolean() (bool, error) {
+	p.i++ // ?
+	switch p.peek() {
+	case '0':
+		p.i++
+		return false, nil
+	case '1':
+		p.i++
+		return true, nil
+	default:
+		return false, p.errorf("invalid boolean")
+	}
+}
+
+// IsToken returns whether s can be represented as a sf-token.
+func IsToken(s string) bool {
+	if s == "" || (s[0] != '*' && !isAlpha(s[0])) {
+		return false
+	}
+	for i := 0; i < len(s); i++ {
+		if c := s[i]; !isTchar(c) && c != ':' && c != '/' {
+			return false
+		}
+	}
+	return true
+}
+
+func formatBareItem(out *strings.Builder, v interface{}) error {
+	switch v := v.(type) {
+	case int64:
+		if v > 999999999999999 || v < -999999999999999 {
+			return fmt.Errorf("integer %d out of range", v)
+		}
+		out.WriteString(strconv.FormatInt(v, 10))
+	case int:
+		return formatBareItem(out, int64(v))
+	case float64:
+		v = math.RoundToEven(v*1000) / 1000
+		if math.Abs(v) >= 1e12 {
+			return fmt.Errorf("decimal %v out of range", v)
+		}
+		s := strconv.FormatFloat(v, 'f', -1, 64)
+		if !strings.Contains(s, ".") {
+			s += ".0"
+		}
+		out.WriteString(s)
+	case string:
+		out.WriteByte('"')
+		for i := 0; i < len(v); i++ {
+			c := v[i]
+			if c < 0x20 || c > 0x7E {
+				return fmt.Errorf("invalid character %q in string", c)
+			}
+			if c == '"' || c == '\\' {
+				out.WriteByte('\\')
+			}
+			out.WriteByte(c)
+		}
+		out.WriteByte('"')
+	case Token:
+		if !IsToken(string(v)) {
+			return fmt.Errorf("invalid token %q", v)
+		}
+		out.WriteString(string(v))
+	case []byte:
+		out.WriteByte(':')
+		out.WriteString(base64.StdEncoding.EncodeToString(v))
+		out.WriteByte(':')
+	case bool:
+		if v {
+			out.WriteString("?1")
+		} else {
+			out.WriteString("?0")
+		}
+	case time.Time:
+		out.WriteByte('@')
+		return formatBareItem(out, v.Unix())
+	default:
+		return fmt.Errorf("unsupported structured field value type %T", v)
+	}
+	return nil
+}
+
+func formatKey(out *strings.Builder, key string) error {
+	if key == "" || (!isLcalpha(key[0]) && key[0] != '*') {
+		return fmt.Errorf("invalid key %q", key)
+	}
+	for i := 0; i < len(key); i++ {
+		if c := key[i]; !isLcalpha(c) && !isDigit(c) && strings.IndexByte("_-.*", c) == -1 {
+			return fmt.Errorf("invalid key %q", key)
+		}
+	}
+	out.WriteString(key)
+	return nil
+}
+
+func formatParams(out *strings.Builder, params Params) error {
+	for _, param := range params {
+		out.WriteByte(';')
+		if err := formatKey(out, param.Key); err != nil {
+			return err
+		}
+		if v, ok := param.Value.(bool); ok && v {
+			continue
+		}
+		out.WriteByte('=')
+		if err := formatBareItem(out, param.Value); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func formatMember(out *strings.Builder, member Member) error {
+	switch m := member.(type) {
+	case Item:
+		if err := formatBareItem(out, m.Value); err != nil {
+			return err
+		}
+		return formatParams(out, m.Params)
+	case InnerList:
+		out.WriteByte('(')
+		for i, item := range m.Items {
+			if i > 0 {
+				out.WriteByte(' ')
+			}
+			if err := formatMember(out, item); err != nil {
+				return err
+			}
+		}
+		out.WriteByte(')')
+		return formatParams(out, m.Params)
+	default:
+		return fmt.Errorf("unsupported structured field member type %T", member)
+	}
+}
+
+// FormatItem serializes item.
+func FormatItem(item Item) (string, error) {
+	var out strings.Builder
+	err := formatMember(&out, item)
+	return out.String(), err
+}
+
+// FormatList serializes list.
+func FormatList(list List) (string, error) {
+	var out strings.Builder
+	for i, member := range list {
+		if i > 0 {
+			out.WriteString(", ")
+		}
+		if err := formatMember(&out, member); err != nil {
+			return "", err
+		}
+	}
+	return out.String(), nil
+}
+
+// FormatDictionary serializes dict. Members whose value is a boolean true
+// item are serialized with their key only.
+func FormatDictionary(dict Dictionary) (string, error) {
+	var out strings.Builder
+	for i, member := range dict {
+		if i > 0 {
+			out.WriteString(", ")
+		}
+		if err := formatKey(&out, member.Key); err != nil {
+			return "", err
+		}
+		if item, ok := member.Member.(Item); ok {
+			if v, ok := item.Value.(bool); ok && v {
+				if err := formatParams(&out, item.Params); err != nil {
+					return "", err
+				}
+				continue
+			}
+		}
+		out.WriteByte('=')
+		if err := formatMember(&out, member.Member); err != nil {
+			return "", err
+		}
+	}
+	return out.String(), nil
+}
@@ -0,0 +1,122 @@
+// Copyright 2026 Franklin "Snaipe" Mathieu.
+//
+// Use of this source code is governed by the MIT license that can be
+// found in the LICENSE file.
+
+package sfv
+
+import (
+	"encoding/base64"
+	"fmt"
+	"testing"
+)
+
+func TestStructuredFieldRoundTrip(t *testing.T) {
+	t.Parallel()
+
+	tcases := []struct {
+		Kind string
+		In   string
+		Out  string
+		Err  bool
+	}{
+		{Kind: "item", In: "42", Out: "42"},
+		{Kind: "item", In: "-4.50", Out: "-4.5"},
+		{Kind: "item", In: `"hello \"world\""`, Out: `"hello \"world\""`},
+		{Kind: "item", In: "foo/bar:baz;a=1;b", Out: "foo/bar:baz;a=1;b"},
+		{Kind: "item", In: ":aGVsbG8=:", Out: ":aGVsbG8=:"},
+		{Kind: "item", In: "?0;x=?1", Out: "?0;x"},
+		{Kind: "item", In: "@1659578233", Out: "@1659578233"},
+		{Kind: "item", In: "1234567890123456", Err: true},
+		{Kind: "item", In: "1.2345", Err: true},
+		{Kind: "item", In: `"unterminated`, Err: true},
+		{Kind: "item", In: "a, b", Err: true},
+		{Kind: "list", In: "a,  b;q=0.5 ,(c d);e", Out: "a, b;q=0.5, (c d);e"},
+		{Kind: "list", In: "", Out: ""},
+		{Kind: "list", In: "a,", Err: true},
+		{Kind: "dict", In: "a=1, b, c=(x y);z=?0, a=2", Out: "a=2, b, c=(x y);z=?0"},
+		{Kind: "dict", In: "A=1", Err: true},
+	}
+
+	for i, tcase := range tcases {
+		t.Run(fmt.Sprintf("%d", i), func(t *testing.T) {
+			var (
+				out string
+				err error
+			)
+			switch tcase.Kind {
+			case "item":
+				var item Item
+				if item, err = ParseItem(tcase.In); err == nil {
+					out, err = FormatItem(item)
+				}
+			case "list":
+				var list List
+				if list, err = ParseList(tcase.In); err == nil {
+					out, err = FormatList(list)
+				}
+			case "dict":
+				var dict Dictionary
+				if dict, err = ParseDictionary(tcase.In); err == nil {
+					out, err = FormatDictionary(dict)
+				}
+			}
+			if tcase.Err {
+				if err == nil {
+					t.Fatalf("expected error, got %q", out)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatal(err)
+			}
+			if out != tcase.Out {
+				t.Fatalf("expected %q, got %q", tcase.Out, out)
+			}
+		})
+	}
+}
+
+func TestDictionaryGet(t *testing.T) {
+	t.Parallel()
+
+	dict, err := ParseDictionary(`u=2, i, sig=:aGk=:;alg="ed25519"`)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	tcases := []struct {
+		Key   string
+		Value interface{}
+		Param interface{}
+	}{
+		{Key: "u", Value: int64(2)},
+		{Key: "i", Value: true},
+		{Key: "sig", Value: "aGk=", Param: "ed25519"},
+		{Key: "x"},
+	}
+
+	for i, tcase := range tcases {
+		t.Run(fmt.Sprintf("%d", i), func(t *testing.T) {
+			member, ok := dict.Get(tcase.Key)
+			if !ok {
+				if tcase.Value != nil {
+					t.Fatalf("expected %v, got nothing", tcase.Value)
+				}
+				return
+			}
+			item := member.(Item)
+			value := item.Value
+			if b, ok := value.([]byte); ok {
+				value = base64.StdEncoding.EncodeToString(b)
+			}
+			if value != tcase.Value {
+				t.Fatalf("expected %v, got %v", tcase.Value, value)
+			}
+			param, _ := item.Params.Get("alg")
+			if param != tcase.Param {
+				t.Fatalf("expected parameter %v, got %v", tcase.Param, param)
+			}
+		})
+	}
+}
@@ -0,0 +1,743 @@
+// Copyright 2026 Franklin "Snaipe" Mathieu.
+//
+// Use of this source code is governed by the MIT license that can be
+// found in the LICENSE file.
+
+package htutil
+
+import (
+	"context"
+	"crypto/ecdsa"
+	"crypto/ed25519"
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha256"
+	"errors"
+	"fmt"
+	"io"
+	"log/slog"
+	"math/big"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+
+	"snai.pe/go-htutil/sfv"
+)
+
+// This file implements HTTP Message Signatures, as per RFC 9421.
+
+// MessageSigner signs signature bases with a key.
+type MessageSigner interface {
+	// KeyID returns the identifier of the key, advertised in the keyid
+	// signature parameter.
+	KeyID() string
+
+	// Algorithm returns the name of the signature algorithm in the HTTP
+	// Signature Algorithms registry, advertised in the alg signature
+	// parameter.
+	Algorithm() string
+
+	// Sign returns the signature of base.
+	Sign(base []byte) ([]byte, error)
+}
+
+// MessageVerifier verifies signature bases signed with a key.
+type MessageVerifier interface {
+	// Algorithm returns the name of the signature algorithm in the HTTP
+	// Signature Algorithms registry. Signatures whose alg parameter names
+	// another algorithm are rejected.
+	Algorithm() string
+
+	// Verify returns an error if sig is not a signature of base.
+	Verify(base, sig []byte) error
+}
+
+// ErrInvalidSignature is returned when a message signature cannot be
+// verified.
+var ErrInvalidSignature = errors.New("invalid signature")
+
+type ed25519Signer struct {
+	keyID string
+	key   ed25519.PrivateKey
+}
+
+// NewEd25519Signer returns a MessageSigner using the ed25519 algorithm.
+func NewEd25519Signer(keyID string, key ed25519.PrivateKey) MessageSigner {
+	return ed25519Signer{keyID: keyID, key: key}
+}
+
+func (s ed25519Signer) KeyID() string     { return s.keyID }
+func (s ed25519Signer) Algorithm() string { return "ed25519" }
+
+func (s ed25519Signer) Sign(base []byte) ([]byte, error) {
+	return ed25519.Sign(s.key, base), nil
+}
+
+type ed25519Verifier struct {
+	key ed25519.PublicKey
+}
+
+// NewEd25519Verifier returns a MessageVerifier using the ed25519 algorithm.
+func NewEd25519Verifier(key ed25519.PublicKey) MessageVerifier {
+	return ed25519Verifier{key: key}
+}
+
+func (v ed25519Verifier) Algorithm() string { return "ed25519" }
+
+func (v ed25519Verifier) Verify(base, sig []byte) error {
+	if !ed25519.Verify(v.key, base, sig) {
+		return ErrInvalidSignature
+	}
+	return nil
+}
+
+type ecdsaSigner struct {
+	keyID string
+	key   *ecdsa.PrivateKey
+}
+
+// NewECDSAP256Signer returns a MessageSigner using the ecdsa-p256-sha256
+// algorithm. key must be on the P-256 curve.
+func NewECDSAP256Signer(keyID string, key *ecdsa.PrivateKey) MessageSigner {
+	return ecdsaSigner{keyID: keyID, key: key}
+}
+
+func (s ecdsaSigner) KeyID() string     { return s.keyID }
+func (s ecdsaSigner) Algorithm() string { return "ecdsa-p256-sha256" }
+
+func (s ecdsaSigner) Sign(base []byte) ([]byte, error) {
+	digest := sha256.Sum256(base)
+	r, rs, err := ecdsa.Sign(rand.Reader, s.key, digest[:])
+	if err != nil {
+		return nil, err
+	}
+	// The signature is the concatenation of r and s, as per RFC 9421
+	// §3.3.4, rather than their ASN.1 encoding.
+	sig := make([]byte, 64)
+	r.FillBytes(sig[:32])
+	rs.FillBytes(sig[32:])
+	return sig, nil
+}
+
+type ecdsaVerifier struct {
+	key *ecdsa.PublicKey
+}
+
+// NewECDSAP256Verifier returns a MessageVerifier using the
+// ecdsa-p256-sha256 algorithm. key must be on the P-256 curve.
+func NewECDSAP256Verifier(key *ecdsa.PublicKey) MessageVerifier {
+	return ecdsaVerifier{key: key}
+}
+
+func (v ecdsaVerifier) Algorithm() string { return "ecdsa-p256-sha256" }
+
+func (v ecdsaVerifier) Verify(base, sig []byte) error {
+	if len(sig) != 64 {
+		return ErrInvalidSignature
+	}
+	digest := sha256.Sum256(base)
+	r, s := new(big.Int).SetBytes(sig[:32]), new(big.Int).SetBytes(sig[32:])
+	if !ecdsa.Verify(v.key, digest[:], r, s) {
+		return ErrInvalidSignature
+	}
+	return nil
+}
+
+type hmacSigner struct {
+	keyID string
+	key   []byte
+}
+
+// NewHMACSigner returns a MessageSigner using the hmac-sha256 algorithm.
+func NewHMACSigner(keyID string, key []byte) MessageSigner {
+	return hmacSigner{keyID: keyID, key: key}
+}
+
+// NewHMACVerifier returns a MessageVerifier using the hmac-sha256
+// algorithm.
+func NewHMACVerifier(key []byte) MessageVerifier {
+	return hmacSigner{key: key}
+}
+
+func (s hmacSigner) KeyID() string     { return s.keyID }
+func (s hmacSigner) Algorithm() string { return "hmac-sha256" }
+
+func (s hmacSigner) Sign(base []byte) ([]byte, error) {
+	mac := hmac.New(sha256.New, s.key)
+	mac.Write(base)
+	return mac.Sum(nil), nil
+}
+
+func (s hmacSigner) Verify(base, sig []byte) error {
+	expected, _ := s.Sign(base)
+	if !hmac.Equal(expected, sig) {
+		return ErrInvalidSignature
+	}
+	return nil
+}
+
+// parseComponent parses a component identifier: either a plain component
+// name, like "@method" or "Content-Type", or a serialized string item with
+// parameters, like `"@query-param";name="id"`.
+func parseComponent(id string) (sfv.Item, error) {
+	if !strings.HasPrefix(id, `"`) {
+		return sfv.Item{Value: strings.ToLower(id)}, nil
+	}
+	item, err := sfv.ParseItem(id)
+	if err != nil {
+		return sfv.Item{}, fmt.Errorf("parsing component %s: %w", id, err)
+	}
+	name, ok := item.Value.(string)
+	if !ok {
+		return sfv.Item{}, fmt.Errorf("parsing component %s: not a string", id)
+	}
+	item.Value = strings.ToLower(name)
+	return item, nil
+}
+
+func parseComponents(ids []string) ([]sfv.Item, error) {
+	items := make([]sfv.Item, len(ids))
+	for i, id := range ids {
+		item, err := parseComponent(id)
+		if err != nil {
+			return nil, err
+		}
+		items[i] = item
+	}
+	return items, nil
+}
+
+// signatureBase builds the signature base of RFC 9421 §2.5 for the covered
+// components, whose values are given by component. It returns the base,
+// and the signature parameters to advertise in Signature-Input.
+func signatureBase(components []sfv.Item, component func(item sfv.Item) ([]string, error), params sfv.Params) (string, sfv.InnerList, error) {
+	var (
+		base  strings.Builder
+		input sfv.InnerList
+	)
+	for _, item := range components {
+		values, err := component(item)
+		if err != nil {
+			return "", sfv.InnerList{}, err
+		}
+		id, err := sfv.FormatItem(item)
+		if err != nil {
+			return "", sfv.InnerList{}, err
+		}
+		for _, v := range values {
+			fmt.Fprintf(&base, "%s: %s\n", id, v)
+		}
+		input.Items = append(input.Items, item)
+	}
+	input.Params = params
+	sigParams, err := sfv.FormatItem(sfv.Item{Value: "@signature-params"})
+	if err != nil {
+		return "", sfv.InnerList{}, err
+	}
+	list, err := sfv.FormatList(sfv.List{input})
+	if err != nil {
+		return "", sfv.InnerList{}, err
+	}
+	fmt.Fprintf(&base, "%s: %s", sigParams, list)
+	return base.String(), input, nil
+}
+
+// fieldComponent returns the value of the header field component name, as
+// per RFC 9421 §2.1.
+func fieldComponent(h http.Header, name string) (string, bool) {
+	values := h.Values(name)
+	if len(values) == 0 {
+		return "", false
+	}
+	trimmed := make([]string, len(values))
+	for i, v := range values {
+		trimmed[i] = trimOWS(v)
+	}
+	return strings.Join(trimmed, ", "), true
+}
+
+// signedMessage is a request, or a response to a request, whose components
+// are covered by a signature.
+type signedMessage struct {
+	req *http.Request
+
+	// header and status are those of the response, or nil and 0 for
+	// requests.
+	header http.Header
+	status int
+}
+
+// component returns the values of the component item of m, as per
+// RFC 9421 §2.1 and §2.2. Only the req and name parameters are supported.
+func (m signedMessage) component(item sfv.Item) ([]string, error) {
+	name, _ := item.Value.(string)
+	var (
+		fromReq = m.header == nil
+		param   string
+		hasName bool
+	)
+	for _, p := range item.Params {
+		switch p.Key {
+		case "req":
+			if p.Value != true || m.req == nil {
+				return nil, fmt.Errorf("component %s: no request to refer to", name)
+			}
+			fromReq = true
+		case "name":
+			if param, hasName = p.Value.(string); !hasName {
+				return nil, fmt.Errorf("component %s: name is not a string", name)
+			}
+		default:
+			return nil, fmt.Errorf("component %s: unsupported parameter %s", name, p.Key)
+		}
+	}
+
+	if !strings.HasPrefix(name, "@") {
+		h := m.header
+		if fromReq {
+			h = m.req.Header
+		}
+		v, ok := fieldComponent(h, name)
+		if !ok {
+			return nil, fmt.Errorf("component %s: field is absent", name)
+		}
+		return []string{v}, nil
+	}
+	if name == "@status" {
+		if fromReq {
+			return nil, fmt.Errorf("component %s: only applies to responses", name)
+		}
+		return []string{strconv.Itoa(m.status)}, nil
+	}
+	if !fromReq {
+		return nil, fmt.Errorf("component %s: needs the req parameter in responses", name)
+	}
+
+	u := RequestURL(m.req)
+	u.Fragment, u.RawFragment = "", ""
+	switch name {
+	case "@method":
+		return []string{m.req.Method}, nil
+	case "@target-uri":
+		return []string{u.String()}, nil
+	case "@authority":
+		return []string{URL{u}.Normalize().Host}, nil
+	case "@scheme":
+		return []string{strings.ToLower(u.Scheme)}, nil
+	case "@request-target":
+		return []string{u.RequestURI()}, nil
+	case "@path":
+		if p := u.EscapedPath(); p != "" {
+			return []string{p}, nil
+		}
+		return []string{"/"}, nil
+	case "@query":
+		return []string{"?" + u.RawQuery}, nil
+	case "@query-param":
+		if !hasName {
+			return nil, fmt.Errorf("component %s: missing name parameter", name)
+		}
+		key, err := url.QueryUnescape(param)
+		if err != nil {
+			return nil, fmt.Errorf("component %s: %w", name, err)
+		}
+		values, ok := u.Query()[key]
+		if !ok {
+			return nil, fmt.Errorf("component %s: query parameter %s is absent", name, param)
+		}
+		encoded := make([]string, len(values))
+		for i, v := range values {
+			encoded[i] = strings.ReplaceAll(url.QueryEscape(v), "+", "%20")
+		}
+		return encoded, nil
+	}
+	return nil, fmt.Errorf("component %s: unknown derived component", name)
+}
+
+// signMessage signs the components of a message, and adds the resulting
+// Signature-Input and Signature fields to h under label.
+func signMessage(h http.Header, signer MessageSigner, label string, components []sfv.Item, component func(item sfv.Item) ([]string, error)) error {
+	params := sfv.Params{
+		{Key: "created", Value: time.Now().Unix()},
+		{Key: "keyid", Value: signer.KeyID()},
+		{Key: "alg", Value: signer.Algorithm()},
+	}
+	base, input, err := signatureBase(components, component, params)
+	if err != nil {
+		return fmt.Errorf("signing message: %w", err)
+	}
+	sig, err := signer.Sign([]byte(base))
+	if err != nil {
+		return fmt.Errorf("signing message: %w", err)
+	}
+
+	sigInput, err := sfv.FormatDictionary(sfv.Dictionary{{Key: label, Member: input}})
+	if err != nil {
+		return fmt.Errorf("signing message: %w", err)
+	}
+	signature, err := sfv.FormatDictionary(sfv.Dictionary{{Key: label, Member: sfv.Item{Value: sig}}})
+	if err != nil {
+		return fmt.Errorf("signing message: %w", err)
+	}
+	h.Add("Signature-Input", sigInput)
+	h.Add("Signature", signature)
+	return nil
+}
+
+// SignRequest signs req with signer, covering the specified components,
+// and adds the resulting Signature-Input and Signature fields to req under
+// label.
+//
+// Components are either plain names, like "@method" or "content-type", or
+// serialized component identifiers with parameters, like
+// `"@query-param";name="id"`.
+func SignRequest(req *http.Request, signer MessageSigner, label string, components ...string) error {
+	items, err := parseComponents(components)
+	if err != nil {
+		return fmt.Errorf("signing message: %w", err)
+	}
+	return signMessage(req.Header, signer, label, items, signedMessage{req: req}.component)
+}
+
+// ResponseSigning signs responses with HTTP Message Signatures, so that
+// clients and intermediaries can verify their authenticity.
+//
+// Responses are buffered in full before being signed, except for the ones
+// streamed with a Streamer or an EventStreamer, which are sent unsigned.
+type ResponseSigning struct {
+	// Signer signs the responses.
+	Signer MessageSigner
+
+	// Label is the label of the signature. Defaults to "sig1".
+	Label string
+
+	// Components are the covered components, as for SignRequest; request
+	// components need the req parameter. Defaults to "@status",
+	// "content-digest", and "date". Content-Digest and Date are set on
+	// responses that lack them; other header fields that are absent from
+	// a response are left out of its signature.
+	Components []string
+}
+
+// Middleware returns a middleware that signs the responses of next.
+func (s *ResponseSigning) Middleware(next http.Handler) http.Handler {
+	label := s.Label
+	if label == "" {
+		label = "sig1"
+	}
+	components := s.Components
+	if len(components) == 0 {
+		components = []string{"@status", "content-digest", "date"}
+	}
+	items, err := parseComponents(components)
+	if err != nil {
+		panic("htutil: " + err.Error())
+	}
+
+	return http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+		buf, req := newBypassableBuffer(w, req)
+		next.ServeHTTP(buf, req)
+		if buf.bypassed {
+			return
+		}
+
+		status := buf.status
+		if status == 0 {
+			status = http.StatusOK
+		}
+		h := buf.header
+
+		msg := signedMessage{req: req, header: h, status: status}
+		covered := make([]sfv.Item, 0, len(items))
+		for _, item := range items {
+			name := item.Value.(string)
+			switch {
+			case name == "content-digest" && len(item.Params) == 0 && h.Get("Content-Digest") == "":
+				SetDigest(h, "Content-Digest", buf.body.Bytes(), "sha-256")
+			case name == "date" && len(item.Params) == 0 && h.Get("Date") == "":
+				StampDate(h)
+			case strings.HasPrefix(name, "@"):
+			default:
+				if _, err := msg.component(item); err != nil {
+					continue
+				}
+			}
+			covered = append(covered, item)
+		}
+
+		if err := signMessage(h, s.Signer, label, covered, msg.component); err != nil {
+			RespondError(w, req, err)
+			return
+		}
+		buf.replay(w, req)
+	})
+}
+
+// SigningTransport is a http.RoundTripper signing outgoing requests with
+// HTTP Message Signatures.
+type SigningTransport struct {
+	// Base is the underlying transport. Defaults to http.DefaultTransport.
+	Base http.RoundTripper
+
+	// Signer signs the requests.
+	Signer MessageSigner
+
+	// Label is the label of the signature. Defaults to "sig1".
+	Label string
+
+	// Components are the covered components, as for SignRequest. Defaults
+	// to "@method", "@target-uri", "content-type", and "content-digest".
+	// Content-Digest is set on requests that lack it and whose body can be
+	// replayed; other header fields that are absent from a request are
+	// left out of its signature.
+	Components []string
+}
+
+func (t *SigningTransport) base() http.RoundTripper {
+	if t.Base != nil {
+		return t.Base
+	}
+	return http.DefaultTransport
+}
+
+func (t *SigningTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	label := t.Label
+	if label == "" {
+		label = "sig1"
+	}
+	components := t.Components
+	if len(components) == 0 {
+		components = []string{"@method", "@target-uri", "content-type", "content-digest"}
+	}
+	items, err := parseComponents(components)
+	if err != nil {
+		closeBody(req)
+		return nil, fmt.Errorf("signing message: %w", err)
+	}
+
+	req = req.Clone(req.Context())
+	msg := signedMessage{req: req}
+	covered := make([]sfv.Item, 0, len(items))
+	for _, item := range items {
+		name := item.Value.(string)
+		if name == "content-digest" && len(item.Params) == 0 && req.Header.Get("Content-Digest") == "" {
+			if err := setRequestDigest(req); err != nil {
+				closeBody(req)
+				return nil, fmt.Errorf("signing message: %w", err)
+			}
+		}
+		if _, err := msg.component(item); err != nil && !strings.HasPrefix(name, "@") {
+			continue
+		}
+		covered = append(covered, item)
+	}
+	if err := signMessage(req.Header, t.Signer, label, covered, msg.component); err != nil {
+		closeBody(req)
+		return nil, err
+	}
+	return t.base().RoundTrip(req)
+}
+
+// setRequestDigest sets the Content-Digest of req, if it has a body that
+// can be replayed.
+func setRequestDigest(req *http.Request) error {
+	if req.Body == nil || req.Body == http.NoBody || req.GetBody == nil {
+		return nil
+	}
+	body, err := req.GetBody()
+	if err != nil {
+		return err
+	}
+	defer body.Close()
+	dr, err := NewDigestReader(body, "sha-256")
+	if err != nil {
+		return err
+	}
+	if _, err := io.Copy(io.Discard, dr); err != nil {
+		return err
+	}
+	req.Header.Set("Content-Digest", FormatDigest(dr.Digests()...))
+	return nil
+}
+
+type signatureKeyIDKey struct{}
+
+// SignatureKeyID returns the identifier of the key that signed the request
+// that ctx belongs to, as verified by SignatureVerifier.
+func SignatureKeyID(ctx context.Context) (string, bool) {
+	keyID, ok := ctx.Value(signatureKeyIDKey{}).(string)
+	return keyID, ok
+}
+
+// SignatureVerifier verifies the HTTP Message Signatures of requests and
+// responses.
+//
+// Signatures must have a keyid parameter, and cover at least the required
+// components. Only the req and name component parameters are supported;
+// signatures covering components with other parameters are rejected.
+type SignatureVerifier struct {
+	// Keys returns the verifier of the key identified by keyID.
+	Keys func(keyID string) (MessageVerifier, error)
+
+	// Label, if set, is the label of the signature to verify; signatures
+	// with other labels are ignored. Otherwise, any signature that
+	// verifies is accepted.
+	Label string
+
+	// Components are the components that signatures must cover, as for
+	// SignRequest. Defaults to "@method" and "@target-uri" for requests,
+	// and "@status" for responses.
+	Components []string
+
+	// MaxAge, if not zero, rejects signatures created longer ago.
+	// Signatures past their expires parameter are always rejected.
+	MaxAge time.Duration
+
+	// Required makes the middleware reject requests without a valid
+	// signature with 401 Unauthorized. Otherwise, they are served without
+	// a key identifier in their context.
+	Required bool
+}
+
+// VerifyRequest returns the identifier of the key that signed req, or ""
+// if req is not signed.
+func (v *SignatureVerifier) VerifyRequest(req *http.Request) (string, error) {
+	return v.verify(req.Header, signedMessage{req: req}, []string{"@method", "@target-uri"})
+}
+
+// VerifyResponse returns the identifier of the key that signed resp, or ""
+// if resp is not signed. Components with the req parameter refer to
+// resp.Request.
+func (v *SignatureVerifier) VerifyResponse(resp *http.Response) (string, error) {
+	msg := signedMessage{req: resp.Request, header: resp.Header, status: resp.StatusCode}
+	return v.verify(resp.Header, msg, []string{"@status"})
+}
+
+func (v *SignatureVerifier) verify(h http.Header, msg signedMessage, defaults []string) (string, error) {
+	if len(h.Values("Signature-Input")) == 0 {
+		return "", nil
+	}
+	inputs, err := sfv.ParseDictionary(strings.Join(h.Values("Signature-Input"), ", "))
+	if err != nil {
+		return "", fmt.Errorf("verifying signature: %w", err)
+	}
+	sigs, err := sfv.ParseDictionary(strings.Join(h.Values("Signature"), ", "))
+	if err != nil {
+		return "", fmt.Errorf("verifying signature: %w", err)
+	}
+	components := v.Components
+	if len(components) == 0 {
+		components = defaults
+	}
+	required, err := parseComponents(components)
+	if err != nil {
+		return "", fmt.Errorf("verifying signature: %w", err)
+	}
+
+	var firstErr error
+	for _, input := range inputs {
+		if v.Label != "" && input.Key != v.Label {
+			continue
+		}
+		keyID, err := v.verifySignature(input, sigs, required, msg)
+		if err == nil {
+			return keyID, nil
+		}
+		if firstErr == nil {
+			firstErr = fmt.Errorf("verifying signature %s: %w", input.Key, err)
+		}
+	}
+	return "", firstErr
+}
+
+func (v *SignatureVerifier) verifySignature(input sfv.DictMember, sigs sfv.Dictionary, required []sfv.Item, msg signedMessage) (string, error) {
+	list, ok := input.Member.(sfv.InnerList)
+	if !ok {
+		return "", fmt.Errorf("%w: malformed Signature-Input", ErrInvalidSignature)
+	}
+	member, _ := sigs.Get(input.Key)
+	item, _ := member.(sfv.Item)
+	sig, ok := item.Value.([]byte)
+	if !ok {
+		return "", fmt.Errorf("%w: missing signature", ErrInvalidSignature)
+	}
+
+	for _, req := range required {
+		id, _ := sfv.FormatItem(req)
+		var covered bool
+		for _, c := range list.Items {
+			if cid, _ := sfv.FormatItem(c); cid == id {
+				covered = true
+				break
+			}
+		}
+		if !covered {
+			return "", fmt.Errorf("%w: %s is not covered", ErrInvalidSignature, id)
+		}
+	}
+
+	now := time.Now()
+	keyID, _ := paramString(list.Params, "keyid")
+	if keyID == "" {
+		return "", fmt.Errorf("%w: missing keyid", ErrInvalidSignature)
+	}
+	if expires, ok := list.Params.Get("expires"); ok {
+		if t, ok := expires.(int64); !ok || now.After(time.Unix(t, 0)) {
+			return "", fmt.Errorf("%w: expired", ErrInvalidSignature)
+		}
+	}
+	if v.MaxAge != 0 {
+		created, ok := list.Params.Get("created")
+		t, isInt := created.(int64)
+		if !ok || !isInt || now.Sub(time.Unix(t, 0)) > v.MaxAge {
+			return "", fmt.Errorf("%w: too old", ErrInvalidSignature)
+		}
+	}
+
+	verifier, err := v.Keys(keyID)
+	if err != nil {
+		return "", err
+	}
+	if alg, ok := paramString(list.Params, "alg"); ok && alg != verifier.Algorithm() {
+		return "", fmt.Errorf("%w: algorithm %s does not match the key", ErrInvalidSignature, alg)
+	}
+	base, _, err := signatureBase(list.Items, msg.component, list.Params)
+	if err != nil {
+		return "", fmt.Errorf("%w: %v", ErrInvalidSignature, err)
+	}
+	if err := verifier.Verify([]byte(base), sig); err != nil {
+		return "", fmt.Errorf("%w: %v", ErrInvalidSignature, err)
+	}
+	return keyID, nil
+}
+
+func paramString(params sfv.Params, key string) (string, bool) {
+	v, ok := params.Get(key)
+	s, isString := v.(string)
+	return s, ok && isString
+}
+
+// Middleware returns a middleware that verifies the signatures of
+// requests, and makes the identifier of the signing key available to next
+// through SignatureKeyID.
+func (v *SignatureVerifier) Middleware(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+		keyID, err := v.VerifyRequest(req)
+		if err != nil {
+			ContextLogger(req.Context()).Warn("rejecting message signature",
+				slog.String("remote_addr", req.RemoteAddr), slog.String("error", err.Error()))
+		}
+		if err != nil || keyID == "" {
+			if v.Required {
+				RespondError(w, req, NewProblem(http.StatusUnauthorized, "A valid message signature is required."))
+				return
+			}
+			next.ServeHTTP(w, req)
+			return
+		}
+		ctx := context.WithValue(req.Context(), signatureKeyIDKey{}, keyID)
+		next.ServeHTTP(w, req.WithContext(ctx))
+	})
+}
@@ -0,0 +1,397 @@
+// Copyright 2026 Franklin "Snaipe" Mathieu.
+//
+// Use of this source code is governed by the MIT license that can be
+// found in the LICENSE file.
+
+package htutil
+
+import (
+	"crypto/ecdsa"
+	"crypto/ed25519"
+	"crypto/elliptic"
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha256"
+	"crypto/x509"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"regexp"
+	"strings"
+	"testing"
+	"time"
+
+	"snai.pe/go-htutil/sfv"
+)
+
+func TestResponseSigning(t *testing.T) {
+	t.Parallel()
+
+	pub, priv, err := ed25519.GenerateKey(nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	handler := http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		w.Header().Set("Date", "Tue, 20 Apr 2021 02:07:56 GMT")
+		w.WriteHeader(http.StatusCreated)
+		fmt.Fprint(w, `{"hello": "world"}`)
+	})
+
+	tcases := []struct {
+		Signing    ResponseSigning
+		Verify     func(base, sig []byte) bool
+		Input      string
+		Base       string
+		Components string
+	}{
+		{
+			Signing: ResponseSigning{Signer: NewEd25519Signer("test-key-ed25519", priv)},
+			Verify: func(base, sig []byte) bool {
+				return ed25519.Verify(pub, base, sig)
+			},
+			Input: `sig1=("@status" "content-digest" "date");created=%s;keyid="test-key-ed25519";alg="ed25519"`,
+			Base: `"@status": 201` + "\n" +
+				`"content-digest": sha-256=:X48E9qOokqqrvdts8nOJRJN3OWDUoyWxBf7kbu9DBPE=:` + "\n" +
+				`"date": Tue, 20 Apr 2021 02:07:56 GMT` + "\n" +
+				`"@signature-params": ("@status" "content-digest" "date");created=%s;keyid="test-key-ed25519";alg="ed25519"`,
+		},
+		{
+			Signing: ResponseSigning{
+				Signer:     NewHMACSigner("test-shared-secret", []byte("secret")),
+				Label:      "proxy",
+				Components: []string{"@status", "Content-Type", "X-Missing"},
+			},
+			Verify: func(base, sig []byte) bool {
+				mac := hmac.New(sha256.New, []byte("secret"))
+				mac.Write(base)
+				return hmac.Equal(mac.Sum(nil), sig)
+			},
+			Input: `proxy=("@status" "content-type");created=%s;keyid="test-shared-secret";alg="hmac-sha256"`,
+			Base: `"@status": 201` + "\n" +
+				`"content-type": application/json` + "\n" +
+				`"@signature-params": ("@status" "content-type");created=%s;keyid="test-shared-secret";alg="hmac-sha256"`,
+		},
+	}
+
+	for i, tcase := range tcases {
+		t.Run(fmt.Sprintf("%d", i), func(t *testing.T) {
+			w := httptest.NewRecorder()
+			tcase.Signing.Middleware(handler).ServeHTTP(w, httptest.NewRequest("GET", "/", nil))
+
+			if w.Code != http.StatusCreated || w.Body.String() != `{"hello": "world"}` {
+				t.Fatalf("expected the response to be preserved, got %d %q", w.Code, w.Body.String())
+			}
+
+			input := w.Header().Get("Signature-Input")
+			pattern := strings.Replace(regexp.QuoteMeta(tcase.Input), "%s", `(\d+)`, 1)
+			m := regexp.MustCompile("^" + pattern + "$").FindStringSubmatch(input)
+			if m == nil {
+				t.Fatalf("expected Signature-Input matching %q, got %q", tcase.Input, input)
+			}
+			base := fmt.Sprintf(tcase.Base, m[1])
+
+			dict, err := sfv.ParseDictionary(w.Header().Get("Signature"))
+			if err != nil || len(dict) != 1 {
+				t.Fatalf("expected a single signature, got %q (%v)", w.Header().Get("Signature"), err)
+			}
+			sig, _ := dict[0].Member.(sfv.Item).Value.([]byte)
+			if !tcase.Verify([]byte(base), sig) {
+				t.Fatalf("signature does not verify against base %q", base)
+			}
+		})
+	}
+}
+
+func TestResponseSigningDate(t *testing.T) {
+	t.Parallel()
+
+	handler := http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+		fmt.Fprint(w, "hello")
+	})
+
+	tcases := []struct {
+		Components []string
+		Date       bool
+	}{
+		{Components: nil, Date: true},
+		{Components: []string{"@status", "date"}, Date: true},
+		{Components: []string{"@status", "content-digest"}, Date: false},
+	}
+
+	for i, tcase := range tcases {
+		t.Run(fmt.Sprintf("%d", i), func(t *testing.T) {
+			signing := &ResponseSigning{Signer: NewHMACSigner("k", []byte("secret")), Components: tcase.Components}
+			w := httptest.NewRecorder()
+			signing.Middleware(handler).ServeHTTP(w, httptest.NewRequest("GET", "/", nil))
+			if date := w.Header().Get("Date") != ""; date != tcase.Date {
+				t.Fatalf("expected Date %v, got %q", tcase.Date, w.Header().Get("Date"))
+			}
+		})
+	}
+}
+
+func TestSignatureComponents(t *testing.T) {
+	t.Parallel()
+
+	req := httptest.NewRequest("GET", "https://Example.COM:443/a%20b/c?x=1&y=two%20words&y=3", nil)
+	req.Header.Add("X-List", " a ")
+	req.Header.Add("X-List", "b")
+	msg := signedMessage{req: req}
+	resp := signedMessage{req: req, header: http.Header{"Content-Type": {"text/plain"}}, status: 201}
+
+	tcases := []struct {
+		Message   signedMessage
+		Component string
+		Expect    []string
+		Err       bool
+	}{
+		{Message: msg, Component: "@method", Expect: []string{"GET"}},
+		{Message: msg, Component: "@target-uri", Expect: []string{"https://Example.COM:443/a%20b/c?x=1&y=two%20words&y=3"}},
+		{Message: msg, Component: "@authority", Expect: []string{"example.com"}},
+		{Message: msg, Component: "@scheme", Expect: []string{"https"}},
+		{Message: msg, Component: "@request-target", Expect: []string{"/a%20b/c?x=1&y=two%20words&y=3"}},
+		{Message: msg, Component: "@path", Expect: []string{"/a%20b/c"}},
+		{Message: msg, Component: "@query", Expect: []string{"?x=1&y=two%20words&y=3"}},
+		{Message: msg, Component: `"@query-param";name="y"`, Expect: []string{"two%20words", "3"}},
+		{Message: msg, Component: `"@query-param";name="z"`, Err: true},
+		{Message: msg, Component: "@query-param", Err: true},
+		{Message: msg, Component: "X-List", Expect: []string{"a, b"}},
+		{Message: msg, Component: "x-missing", Err: true},
+		{Message: msg, Component: "@status", Err: true},
+		{Message: msg, Component: "@unknown", Err: true},
+		{Message: msg, Component: `"x-list";sf`, Err: true},
+		{Message: resp, Component: "@status", Expect: []string{"201"}},
+		{Message: resp, Component: "content-type", Expect: []string{"text/plain"}},
+		{Message: resp, Component: "@method", Err: true},
+		{Message: resp, Component: `"@method";req`, Expect: []string{"GET"}},
+		{Message: resp, Component: `"x-list";req`, Expect: []string{"a, b"}},
+	}
+
+	for i, tcase := range tcases {
+		t.Run(fmt.Sprintf("%d", i), func(t *testing.T) {
+			item, err := parseComponent(tcase.Component)
+			if err != nil {
+				t.Fatal(err)
+			}
+			values, err := tcase.Message.component(item)
+			if (err != nil) != tcase.Err {
+				t.Fatalf("expected error %v, got %v", tcase.Err, err)
+			}
+			if err == nil && !reflect.DeepEqual(values, tcase.Expect) {
+				t.Fatalf("expected %q, got %q", tcase.Expect, values)
+			}
+		})
+	}
+}
+
+// TestVerifyRequestRFC9421 verifies the test vectors of RFC 9421 §B.2.
+func TestVerifyRequestRFC9421(t *testing.T) {
+	t.Parallel()
+
+	hmacKey, _ := base64.StdEncoding.DecodeString("uzvJfB4u3N0Jy4T7NZ75MDVcr8zSTInedJtkgcu46YW4XByzNJjxBdtjUkdJPBtbmHhIDi6pcl8jsasjlTMtDQ==")
+	der, _ := base64.StdEncoding.DecodeString("MC4CAQAwBQYDK2VwBCIEIJ+DYvh6SEqVTm50DFtMDoQikTmiCqirVv9mWG9qfSnF")
+	edKey, err := x509.ParsePKCS8PrivateKey(der)
+	if err != nil {
+		t.Fatal(err)
+	}
+	keys := func(keyID string) (MessageVerifier, error) {
+		switch keyID {
+		case "test-shared-secret":
+			return NewHMACVerifier(hmacKey), nil
+		case "test-key-ed25519":
+			return NewEd25519Verifier(edKey.(ed25519.PrivateKey).Public().(ed25519.PublicKey)), nil
+		}
+		return nil, errors.New("unknown key")
+	}
+
+	tcases := []struct {
+		Input      string
+		Signature  string
+		Components []string
+		Tamper     bool
+		Err        bool
+	}{
+		{
+			Input:      `sig-b25=("date" "@authority" "content-type");created=1618884473;keyid="test-shared-secret"`,
+			Signature:  `sig-b25=:pxcQw6G3AjtMBQjwo8XzkZf/bws5LelbaMk5rGIGtE8=:`,
+			Components: []string{"@authority"},
+		},
+		{
+			Input:      `sig-b26=("date" "@method" "@path" "@authority" "content-type" "content-length");created=1618884473;keyid="test-key-ed25519"`,
+			Signature:  `sig-b26=:wqcAqbmYJ2ji2glfAMaRy4gruYYnx2nEFN2HN6jrnDnQCK1u02Gb04v9EDgwUPiu4A0w6vuQv5lIp5WPpBKRCw==:`,
+			Components: []string{"@method", "@path"},
+		},
+		{
+			Input:      `sig-b26=("date" "@method" "@path" "@authority" "content-type" "content-length");created=1618884473;keyid="test-key-ed25519"`,
+			Signature:  `sig-b26=:wqcAqbmYJ2ji2glfAMaRy4gruYYnx2nEFN2HN6jrnDnQCK1u02Gb04v9EDgwUPiu4A0w6vuQv5lIp5WPpBKRCw==:`,
+			Components: []string{"@method", "@path"},
+			Tamper:     true,
+			Err:        true,
+		},
+		{
+			Input:     `sig-b25=("date" "@authority" "content-type");created=1618884473;keyid="test-shared-secret"`,
+			Signature: `sig-b25=:pxcQw6G3AjtMBQjwo8XzkZf/bws5LelbaMk5rGIGtE8=:`,
+			Err:       true,
+		},
+		{
+			Input:      `sig-b25=("date" "@authority" "content-type");created=1618884473;keyid="test-shared-secret";alg="ed25519"`,
+			Signature:  `sig-b25=:pxcQw6G3AjtMBQjwo8XzkZf/bws5LelbaMk5rGIGtE8=:`,
+			Components: []string{"@authority"},
+			Err:        true,
+		},
+	}
+
+	for i, tcase := range tcases {
+		t.Run(fmt.Sprintf("%d", i), func(t *testing.T) {
+			req := httptest.NewRequest("POST", "http://example.com/foo?param=Value&Pet=dog", strings.NewReader(`{"hello": "world"}`))
+			req.Header.Set("Date", "Tue, 20 Apr 2021 02:07:55 GMT")
+			req.Header.Set("Content-Type", "application/json")
+			req.Header.Set("Content-Length", "18")
+			req.Header.Set("Signature-Input", tcase.Input)
+			req.Header.Set("Signature", tcase.Signature)
+			if tcase.Tamper {
+				req.Method = "PUT"
+			}
+
+			v := &SignatureVerifier{Keys: keys, Components: tcase.Components}
+			keyID, err := v.VerifyRequest(req)
+			if (err != nil) != tcase.Err {
+				t.Fatalf("expected error %v, got %v", tcase.Err, err)
+			}
+			if err != nil && !errors.Is(err, ErrInvalidSignature) {
+				t.Fatalf("expected ErrInvalidSignature, got %v", err)
+			}
+			if err == nil && keyID == "" {
+				t.Fatal("expected a key identifier")
+			}
+		})
+	}
+}
+
+func TestSigningTransport(t *testing.T) {
+	t.Parallel()
+
+	_, edKey, err := ed25519.GenerateKey(nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	ecKey, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+	hmacKey := []byte("secret")
+
+	verifier := &SignatureVerifier{
+		Keys: func(keyID string) (MessageVerifier, error) {
+			switch keyID {
+			case "ed":
+				return NewEd25519Verifier(edKey.Public().(ed25519.PublicKey)), nil
+			case "ec":
+				return NewECDSAP256Verifier(&ecKey.PublicKey), nil
+			case "hmac":
+				return NewHMACVerifier(hmacKey), nil
+			}
+			return nil, errors.New("unknown key")
+		},
+		Components: []string{"@method", "@target-uri", "content-digest"},
+		MaxAge:     time.Minute,
+		Required:   true,
+	}
+	srv := httptest.NewServer(verifier.Middleware(http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+		keyID, _ := SignatureKeyID(req.Context())
+		fmt.Fprint(w, keyID)
+	})))
+	defer srv.Close()
+
+	tcases := []struct {
+		Signer MessageSigner
+		Body   string
+		Tamper func(req *http.Request)
+		Status int
+	}{
+		{Signer: NewEd25519Signer("ed", edKey), Body: "hello", Status: 200},
+		{Signer: NewECDSAP256Signer("ec", ecKey), Body: "hello", Status: 200},
+		{Signer: NewHMACSigner("hmac", hmacKey), Body: "hello", Status: 200},
+		{Signer: NewHMACSigner("hmac", []byte("wrong")), Body: "hello", Status: 401},
+		{Signer: NewHMACSigner("unknown", hmacKey), Body: "hello", Status: 401},
+		{Signer: NewEd25519Signer("ed", edKey), Body: "hello", Status: 401, Tamper: func(req *http.Request) {
+			req.URL.Path = "/other"
+		}},
+		{Signer: NewEd25519Signer("ed", edKey), Body: "hello", Status: 401, Tamper: func(req *http.Request) {
+			req.Header.Set("Content-Digest", "sha-256=:AAAA:")
+		}},
+		{Body: "hello", Status: 401},
+	}
+
+	for i, tcase := range tcases {
+		t.Run(fmt.Sprintf("%d", i), func(t *testing.T) {
+			var transport http.RoundTripper = srv.Client().Transport
+			if tcase.Tamper != nil {
+				base := transport
+				transport = roundTripFunc(func(req *http.Request) (*http.Response, error) {
+					tcase.Tamper(req)
+					return base.RoundTrip(req)
+				})
+			}
+			if tcase.Signer != nil {
+				transport = &SigningTransport{Base: transport, Signer: tcase.Signer}
+			}
+			client := &http.Client{Transport: transport}
+			resp, err := client.Post(srv.URL+"/path?q=1", "text/plain", strings.NewReader(tcase.Body))
+			if err != nil {
+				t.Fatal(err)
+			}
+			defer resp.Body.Close()
+			body, _ := io.ReadAll(resp.Body)
+			if resp.StatusCode != tcase.Status {
+				t.Fatalf("expected status %d, got %d (%s)", tcase.Status, resp.StatusCode, body)
+			}
+			if tcase.Status == 200 && string(body) != tcase.Signer.KeyID() {
+				t.Fatalf("expected key %q, got %q", tcase.Signer.KeyID(), body)
+			}
+		})
+	}
+}
+
+func TestVerifyResponse(t *testing.T) {
+	t.Parallel()
+
+	_, key, err := ed25519.GenerateKey(nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	signing := &ResponseSigning{
+		Signer:     NewEd25519Signer("ed", key),
+		Components: []string{"@status", "content-digest", `"@method";req`},
+	}
+	srv := httptest.NewServer(signing.Middleware(http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+		fmt.Fprint(w, "hello")
+	})))
+	defer srv.Close()
+
+	resp, err := srv.Client().Get(srv.URL)
+	if err != nil {
+		t.Fatal(err)
+	}
+	resp.Body.Close()
+
+	verifier := &SignatureVerifier{
+		Keys: func(string) (MessageVerifier, error) {
+			return NewEd25519Verifier(key.Public().(ed25519.PublicKey)), nil
+		},
+		Components: []string{"@status", `"@method";req`},
+	}
+	if keyID, err := verifier.VerifyResponse(resp); err != nil || keyID != "ed" {
+		t.Fatalf("expected key %q, got %q (error: %v)", "ed", keyID, err)
+	}
+	resp.StatusCode = 500
+	if _, err := verifier.VerifyResponse(resp); !errors.Is(err, ErrInvalidSignature) {
+		t.Fatalf("expected ErrInvalidSignature, got %v", err)
+	}
+}
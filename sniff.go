@@ -0,0 +1,143 @@
+// Copyright 2026 Franklin "Snaipe" Mathieu.
+//
+// Use of this source code is governed by the MIT license that can be
+// found in the LICENSE file.
+
+package htutil
+
+import (
+	"mime"
+	"net/http"
+	"path"
+	"strings"
+)
+
+// SniffPolicy determines how SniffContentType resolves disagreements
+// between the media type of the file extension and the one sniffed from
+// the content.
+type SniffPolicy int
+
+const (
+	// SniffPreferExtension prefers the media type of the file extension,
+	// like http.ServeContent does.
+	SniffPreferExtension SniffPolicy = iota
+
+	// SniffPreferContent prefers the media type sniffed from the content.
+	SniffPreferContent
+
+	// SniffStrict resolves disagreements to application/octet-stream, so
+	// that content disguised behind an extension, like HTML in a .png
+	// upload, is never served as either.
+	SniffStrict
+)
+
+// SniffContentType determines the Content-Type of a response serving
+// content, which is the beginning of the file name, or all of it.
+//
+// The candidates are the media type of the extension of name, as per
+// mime.TypeByExtension, and the one sniffed from content, as per
+// http.DetectContentType. Generic sniffing results, text/plain and
+// application/octet-stream, never disagree with the extension, nor do
+// container formats with the formats built on them, like XML with SVG
+// images, or ZIP with office documents. Otherwise, disagreements are
+// resolved according to policy, and the candidates are offered in that
+// order to the Accept header field of hdr, so that a client may select the
+// other one.
+//
+// If no candidate is acceptable, the preferred candidate is returned with
+// ok=false, and the caller may either serve it anyway, or reply 406 Not
+// Acceptable. Responses whose Content-Type is determined this way should
+// Vary on Accept.
+func SniffContentType(hdr http.Header, name string, content []byte, policy SniffPolicy) (contentType string, ok bool) {
+	sniffed := http.DetectContentType(content)
+	byExt := ""
+	if ext := path.Ext(name); ext != "" {
+		byExt = mime.TypeByExtension(ext)
+	}
+
+	var candidates []string
+	switch {
+	case byExt == "":
+		candidates = []string{sniffed}
+	case sniffAgrees(byExt, sniffed):
+		candidates = []string{byExt, sniffed}
+	case policy == SniffPreferContent:
+		candidates = []string{sniffed, byExt}
+	case policy == SniffStrict:
+		candidates = []string{"application/octet-stream"}
+	default:
+		candidates = []string{byExt, sniffed}
+	}
+
+	offers := make([]string, 0, len(candidates))
+	types := make(map[string]string, len(candidates))
+	for _, c := range candidates {
+		essence := essenceOf(c)
+		if _, ok := types[essence]; !ok {
+			offers = append(offers, essence)
+			types[essence] = c
+		}
+	}
+	selected, _ := NegotiateContent(hdr, "Accept", offers...)
+	if selected == "" {
+		return candidates[0], false
+	}
+	return types[selected], true
+}
+
+// sniffAgrees returns whether the media type sniffed from some content
+// agrees with the one of its file extension.
+func sniffAgrees(byExt, sniffed string) bool {
+	ext, err := ParseMediaType(byExt)
+	if err != nil {
+		return sniffedGeneric(sniffed)
+	}
+	switch essenceOf(sniffed) {
+	case ext.Essence():
+		return true
+	case "text/xml", "application/xml":
+		return ext.Suffix == "xml" || ext.Subtype == "xml"
+	case "application/zip":
+		return ext.Suffix == "zip" || zipBased(ext)
+	}
+	return sniffedGeneric(sniffed)
+}
+
+// zipBased returns whether mt is a ZIP-based format without a +zip
+// suffix, like office documents and Java archives.
+func zipBased(mt MediaType) bool {
+	if mt.Type != "application" {
+		return false
+	}
+	switch {
+	case strings.HasPrefix(mt.Subtype, "vnd.openxmlformats-officedocument."),
+		strings.HasPrefix(mt.Subtype, "vnd.oasis.opendocument."),
+		strings.HasPrefix(mt.Subtype, "vnd.ms-") && strings.HasSuffix(mt.Subtype, ".macroenabled.12"):
+		return true
+	}
+	switch mt.Subtype {
+	case "java-archive", "vnd.android.package-archive", "vnd.apple.keynote",
+		"vnd.apple.numbers", "vnd.apple.pages", "x-xpinstall":
+		return true
+	}
+	return false
+}
+
+// sniffedGeneric returns whether the sniffed media type is one of the
+// fallbacks of http.DetectContentType.
+func sniffedGeneric(sniffed string) bool {
+	switch essenceOf(sniffed) {
+	case "text/plain", "application/octet-stream":
+		return true
+	}
+	return false
+}
+
+// essenceOf returns the lowercased type and subtype of the media type mt.
+func essenceOf(mt string) string {
+	essence, _, err := mime.ParseMediaType(mt)
+	if err != nil {
+		return mt
+	}
+	return essence
+}
@@ -0,0 +1,70 @@
+// Copyright 2026 Franklin "Snaipe" Mathieu.
+//
+// Use of this source code is governed by the MIT license that can be
+// found in the LICENSE file.
+
+package htutil
+
+import (
+	"fmt"
+	"mime"
+	"net/http"
+	"testing"
+)
+
+func TestSniffContentType(t *testing.T) {
+	t.Parallel()
+
+	var (
+		html = []byte("<!DOCTYPE html><html><body>hi</body></html>")
+		png  = []byte("\x89PNG\x0D\x0A\x1A\x0A\x00\x00\x00\x0DIHDR")
+		text = []byte("body { color: red; }\n")
+		svg  = []byte(`<?xml version="1.0"?><svg xmlns="http://www.w3.org/2000/svg"/>`)
+		zip  = []byte("PK\x03\x04\x14\x00\x06\x00")
+	)
+
+	mime.AddExtensionType(".docx", "application/vnd.openxmlformats-officedocument.wordprocessingml.document")
+
+	tcases := []struct {
+		Accept  string
+		Name    string
+		Content []byte
+		Policy  SniffPolicy
+		Expect  string
+		NotOK   bool
+	}{
+		{Name: "index.html", Content: html, Expect: "text/html; charset=utf-8"},
+		{Name: "image.png", Content: png, Expect: "image/png"},
+		{Name: "noext", Content: png, Expect: "image/png"},
+		{Name: "style.css", Content: text, Expect: "text/css; charset=utf-8"},
+		{Name: "style.css", Content: text, Accept: "text/plain", Expect: "text/plain; charset=utf-8"},
+		{Name: "style.css", Content: text, Accept: "text/plain;q=0.5, text/css", Expect: "text/css; charset=utf-8"},
+		{Name: "image.png", Content: html, Expect: "image/png"},
+		{Name: "image.png", Content: html, Policy: SniffPreferContent, Expect: "text/html; charset=utf-8"},
+		{Name: "image.png", Content: html, Policy: SniffPreferContent, Accept: "image/*", Expect: "image/png"},
+		{Name: "image.png", Content: html, Accept: "text/html", Expect: "text/html; charset=utf-8"},
+		{Name: "image.png", Content: html, Policy: SniffStrict, Expect: "application/octet-stream"},
+		{Name: "image.png", Content: html, Policy: SniffStrict, Accept: "text/html", Expect: "application/octet-stream", NotOK: true},
+		{Name: "image.png", Content: png, Accept: "application/json", Expect: "image/png", NotOK: true},
+		{Name: "image.svg", Content: svg, Policy: SniffStrict, Expect: "image/svg+xml"},
+		{Name: "image.svg", Content: svg, Policy: SniffPreferContent, Expect: "image/svg+xml"},
+		{Name: "feed.xml", Content: svg, Policy: SniffStrict, Expect: "text/xml; charset=utf-8"},
+		{Name: "report.docx", Content: zip, Policy: SniffStrict, Expect: "application/vnd.openxmlformats-officedocument.wordprocessingml.document"},
+		{Name: "report.docx", Content: zip, Policy: SniffPreferContent, Expect: "application/vnd.openxmlformats-officedocument.wordprocessingml.document"},
+		{Name: "image.png", Content: zip, Policy: SniffStrict, Expect: "application/octet-stream"},
+		{Name: "image.png", Content: svg, Policy: SniffStrict, Expect: "application/octet-stream"},
+	}
+
+	for i, tcase := range tcases {
+		t.Run(fmt.Sprintf("%d", i), func(t *testing.T) {
+			hdr := make(http.Header)
+			if tcase.Accept != "" {
+				hdr.Set("Accept", tcase.Accept)
+			}
+			ct, ok := SniffContentType(hdr, tcase.Name, tcase.Content, tcase.Policy)
+			if ct != tcase.Expect || ok == tcase.NotOK {
+				t.Fatalf("expected %q (ok: %v), got %q (ok: %v)", tcase.Expect, !tcase.NotOK, ct, ok)
+			}
+		})
+	}
+}
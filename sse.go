@@ -0,0 +1,343 @@
+// Copyright 2026 Franklin "Snaipe" Mathieu.
+//
+// Use of this source code is governed by the MIT license that can be
+// found in the LICENSE file.
+
+package htutil
+
+import (
+	"bufio"
+	"bytes"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+// This file implements server-sent events, as per the HTML standard: the
+// text/event-stream format, and its server and client sides.
+
+// Event is a server-sent event.
+type Event struct {
+	// ID is the identifier of the event, which clients send back in the
+	// Last-Event-ID header field when reconnecting. When reading events,
+	// it is the last identifier received, and persists across events.
+	ID string
+
+	// Event is the type of the event. Events without a type are read as
+	// "message" events.
+	Event string
+
+	// Data is the payload of the event. It may span multiple lines.
+	Data string
+
+	// Retry, if set, is the reconnection delay that clients should use.
+	Retry time.Duration
+}
+
+// ErrInvalidEvent is returned when sending an event whose ID or type
+// contains line breaks, or whose ID contains NUL characters, which the
+// text/event-stream format cannot convey.
+var ErrInvalidEvent = errors.New("invalid event")
+
+// LastEventID returns the identifier of the last event that the client
+// received before reconnecting with req, or "" if it is a new connection.
+func LastEventID(req *http.Request) string {
+	return req.Header.Get("Last-Event-ID")
+}
+
+// EventStreamer streams server-sent events.
+type EventStreamer struct {
+	// KeepAlive is the interval at which comments are sent while no event
+	// is, to keep intermediaries from closing idle connections. Keep-alives
+	// are disabled if zero.
+	KeepAlive time.Duration
+
+	// Retry, if set, is the reconnection delay sent to clients when the
+	// stream starts.
+	Retry time.Duration
+
+	// WriteTimeout, if set, is the time that clients get to receive each
+	// event; see Streamer.
+	WriteTimeout time.Duration
+}
+
+// EventWriter is a stream of server-sent events being written, as started
+// by EventStreamer.Stream. It is safe for concurrent use.
+type EventWriter struct {
+	w    http.ResponseWriter
+	req  *http.Request
+	rc   *http.ResponseController
+	s    *EventStreamer
+	buf  bytes.Buffer
+	stop chan struct{}
+
+	mu     sync.Mutex
+	err    error
+	closed bool
+}
+
+// Stream starts streaming server-sent events in the response to req. It
+// returns a 406 Not Acceptable problem if the Accept header field of req
+// does not accept text/event-stream.
+//
+// Buffering middlewares of this package, like Cache, Coalesce, or
+// Conditional, are bypassed for the response; no header field may be set
+// once the stream is started. The stream must be closed once done.
+func (s *EventStreamer) Stream(w http.ResponseWriter, req *http.Request) (*EventWriter, error) {
+	AddVary(w.Header(), "Accept")
+	if ctype, _ := NegotiateContent(req.Header, "Accept", "text/event-stream"); ctype == "" {
+		return nil, NewProblem(http.StatusNotAcceptable, "Available representations: text/event-stream.")
+	}
+
+	bypassBuffers(req)
+	h := w.Header()
+	h.Set("Content-Type", "text/event-stream")
+	h.Set("Cache-Control", "no-cache")
+	h.Del("Content-Length")
+	h.Del("ETag")
+
+	ew := &EventWriter{
+		w:    w,
+		req:  req,
+		rc:   http.NewResponseController(w),
+		s:    s,
+		stop: make(chan struct{}),
+	}
+	w.WriteHeader(http.StatusOK)
+	if s.Retry > 0 {
+		ew.buf.WriteString("retry: " + strconv.FormatInt(s.Retry.Milliseconds(), 10) + "\n\n")
+	}
+	ew.mu.Lock()
+	err := ew.flush()
+	ew.mu.Unlock()
+	if err != nil {
+		return nil, err
+	}
+	if s.KeepAlive > 0 {
+		go ew.keepAlive()
+	}
+	return ew, nil
+}
+
+// LastEventID returns the identifier of the last event that the client
+// received before reconnecting, so that the stream resumes after it.
+func (ew *EventWriter) LastEventID() string {
+	return LastEventID(ew.req)
+}
+
+// Send sends ev to the client, and flushes it.
+//
+// Send returns an error if the client went away, or failed to keep up
+// within the WriteTimeout of the EventStreamer; the handler should then
+// stop producing events.
+func (ew *EventWriter) Send(ev Event) error {
+	if strings.ContainsAny(ev.ID, "\r\n\x00") || strings.ContainsAny(ev.Event, "\r\n") {
+		return ErrInvalidEvent
+	}
+
+	ew.mu.Lock()
+	defer ew.mu.Unlock()
+	if ev.Event != "" {
+		ew.buf.WriteString("event: " + ev.Event + "\n")
+	}
+	if ev.ID != "" {
+		ew.buf.WriteString("id: " + ev.ID + "\n")
+	}
+	if ev.Retry > 0 {
+		ew.buf.WriteString("retry: " + strconv.FormatInt(ev.Retry.Milliseconds(), 10) + "\n")
+	}
+	for _, line := range splitLines(ev.Data) {
+		ew.buf.WriteString("data: " + line + "\n")
+	}
+	ew.buf.WriteByte('\n')
+	return ew.flush()
+}
+
+// Comment sends a comment, which clients ignore, and flushes it.
+func (ew *EventWriter) Comment(text string) error {
+	ew.mu.Lock()
+	defer ew.mu.Unlock()
+	for _, line := range splitLines(text) {
+		ew.buf.WriteString(":" + line + "\n")
+	}
+	return ew.flush()
+}
+
+// Close terminates the stream, and stops its keep-alives.
+func (ew *EventWriter) Close() error {
+	ew.mu.Lock()
+	defer ew.mu.Unlock()
+	if !ew.closed {
+		ew.closed = true
+		close(ew.stop)
+	}
+	return ew.err
+}
+
+func (ew *EventWriter) keepAlive() {
+	ticker := time.NewTicker(ew.s.KeepAlive)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ew.stop:
+			return
+		case <-ew.req.Context().Done():
+			return
+		case <-ticker.C:
+			if ew.Comment("") != nil {
+				return
+			}
+		}
+	}
+}
+
+// flush writes the buffered fields to the client. ew.mu must be held.
+func (ew *EventWriter) flush() error {
+	if ew.err == nil && ew.closed {
+		ew.err = errors.New("event stream closed")
+	}
+	if ew.err == nil {
+		ew.err = ew.req.Context().Err()
+	}
+	if ew.err != nil {
+		ew.buf.Reset()
+		return ew.err
+	}
+	if ew.s.WriteTimeout > 0 {
+		ew.rc.SetWriteDeadline(time.Now().Add(ew.s.WriteTimeout))
+	}
+	if ew.req.Method != http.MethodHead {
+		if _, err := ew.buf.WriteTo(ew.w); err != nil {
+			ew.err = err
+			return err
+		}
+	}
+	ew.buf.Reset()
+	if err := ew.rc.Flush(); err != nil && !errors.Is(err, http.ErrNotSupported) {
+		ew.err = err
+		return err
+	}
+	return nil
+}
+
+// splitLines splits s at CRLF, LF, and CR line breaks.
+func splitLines(s string) []string {
+	s = strings.ReplaceAll(s, "\r\n", "\n")
+	s = strings.ReplaceAll(s, "\r", "\n")
+	return strings.Split(s, "\n")
+}
+
+// EventReader reads server-sent events from a text/event-stream, like the
+// body of a response to a request accepting it.
+type EventReader struct {
+	r      *bufio.Reader
+	lastID string
+	retry  time.Duration
+	skipLF bool
+	bom    bool
+}
+
+// NewEventReader returns an EventReader reading from r.
+func NewEventReader(r io.Reader) *EventReader {
+	return &EventReader{r: bufio.NewReader(r)}
+}
+
+// LastEventID returns the identifier of the last event read, to send in
+// the Last-Event-ID header field when reconnecting.
+func (er *EventReader) LastEventID() string {
+	return er.lastID
+}
+
+// Retry returns the reconnection delay last sent by the server, or 0 if
+// it did not send any.
+func (er *EventReader) Retry() time.Duration {
+	return er.retry
+}
+
+// Next reads the next event. It returns io.EOF at the end of the stream;
+// the data of an incomplete last event is discarded.
+func (er *EventReader) Next() (Event, error) {
+	var (
+		ev      Event
+		data    strings.Builder
+		hasData bool
+	)
+	for {
+		line, err := er.readLine()
+		if err != nil {
+			if errors.Is(err, io.EOF) {
+				return Event{}, io.EOF
+			}
+			return Event{}, fmt.Errorf("reading event stream: %w", err)
+		}
+		if line == "" {
+			if !hasData {
+				ev = Event{}
+				continue
+			}
+			ev.ID = er.lastID
+			ev.Data = strings.TrimSuffix(data.String(), "\n")
+			if ev.Event == "" {
+				ev.Event = "message"
+			}
+			return ev, nil
+		}
+		if line[0] == ':' {
+			continue
+		}
+		name, value, _ := strings.Cut(line, ":")
+		value = strings.TrimPrefix(value, " ")
+		switch name {
+		case "event":
+			ev.Event = value
+		case "data":
+			data.WriteString(value + "\n")
+			hasData = true
+		case "id":
+			if strings.IndexByte(value, 0) == -1 {
+				er.lastID = value
+			}
+		case "retry":
+			if ms, err := strconv.ParseUint(value, 10, 63); err == nil {
+				er.retry = time.Duration(ms) * time.Millisecond
+				ev.Retry = er.retry
+			}
+		}
+	}
+}
+
+// readLine reads a line terminated by CRLF, LF, or CR, without blocking
+// for the LF following a CR.
+func (er *EventReader) readLine() (string, error) {
+	var line []byte
+	for {
+		c, err := er.r.ReadByte()
+		if err != nil {
+			return "", err
+		}
+		if er.skipLF {
+			er.skipLF = false
+			if c == '\n' {
+				continue
+			}
+		}
+		switch c {
+		case '\r':
+			er.skipLF = true
+			fallthrough
+		case '\n':
+			s := string(line)
+			if !er.bom {
+				er.bom = true
+				s = strings.TrimPrefix(s, "\uFEFF")
+			}
+			return s, nil
+		}
+		line = append(line, c)
+	}
+}
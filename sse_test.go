@@ -0,0 +1,138 @@
+// Copyright 2026 Franklin "Snaipe" Mathieu.
+//
+// Use of this source code is governed by the MIT license that can be
+// found in the LICENSE file.
+
+package htutil
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestEventReader(t *testing.T) {
+	t.Parallel()
+
+	tcases := []struct {
+		Stream string
+		Events []Event
+		LastID string
+		Retry  time.Duration
+	}{
+		{
+			Stream: "data: hello\n\n",
+			Events: []Event{{Event: "message", Data: "hello"}},
+		},
+		{
+			Stream: "\uFEFFevent: update\r\nid: 1\r\ndata: a\r\ndata:b\r\n\r\n: comment\rdata: c\r\r",
+			Events: []Event{{ID: "1", Event: "update", Data: "a\nb"}, {ID: "1", Event: "message", Data: "c"}},
+			LastID: "1",
+		},
+		{
+			// Events without data are not dispatched, and neither is an
+			// incomplete last event.
+			Stream: "event: ignored\nid: 2\n\nretry: 1500\ndata\n\nretry: x\ndata: partial",
+			Events: []Event{{ID: "2", Event: "message", Retry: 1500 * time.Millisecond}},
+			LastID: "2",
+			Retry:  1500 * time.Millisecond,
+		},
+		{
+			Stream: "id: 3\ndata: a\n\nid: 4\x00\ndata: b\n\nid\ndata: c\n\n",
+			Events: []Event{{ID: "3", Event: "message", Data: "a"}, {ID: "3", Event: "message", Data: "b"}, {Event: "message", Data: "c"}},
+		},
+	}
+
+	for i, tcase := range tcases {
+		t.Run(fmt.Sprintf("%d", i), func(t *testing.T) {
+			er := NewEventReader(strings.NewReader(tcase.Stream))
+			var events []Event
+			for {
+				ev, err := er.Next()
+				if errors.Is(err, io.EOF) {
+					break
+				}
+				if err != nil {
+					t.Fatal(err)
+				}
+				events = append(events, ev)
+			}
+			if fmt.Sprint(events) != fmt.Sprint(tcase.Events) {
+				t.Fatalf("expected %v, got %v", tcase.Events, events)
+			}
+			if er.LastEventID() != tcase.LastID {
+				t.Fatalf("expected last event ID %q, got %q", tcase.LastID, er.LastEventID())
+			}
+			if er.Retry() != tcase.Retry {
+				t.Fatalf("expected retry %v, got %v", tcase.Retry, er.Retry())
+			}
+		})
+	}
+}
+
+func TestEventStream(t *testing.T) {
+	t.Parallel()
+
+	streamer := &EventStreamer{Retry: 2 * time.Second, KeepAlive: time.Millisecond}
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+		ew, err := streamer.Stream(w, req)
+		if err != nil {
+			RespondError(w, req, err)
+			return
+		}
+		defer ew.Close()
+
+		if err := ew.Send(Event{ID: "a\nb"}); !errors.Is(err, ErrInvalidEvent) {
+			t.Errorf("expected ErrInvalidEvent, got %v", err)
+		}
+		ew.Send(Event{Event: "resume", Data: ew.LastEventID()})
+		time.Sleep(10 * time.Millisecond)
+		ew.Send(Event{ID: "7", Data: "line 1\nline 2"})
+	}))
+	defer srv.Close()
+
+	req, _ := http.NewRequest("GET", srv.URL, nil)
+	req.Header.Set("Accept", "text/event-stream")
+	req.Header.Set("Last-Event-ID", "6")
+	resp, err := srv.Client().Do(req)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer resp.Body.Close()
+
+	if ctype := resp.Header.Get("Content-Type"); ctype != "text/event-stream" {
+		t.Fatalf("expected text/event-stream, got %q", ctype)
+	}
+	er := NewEventReader(resp.Body)
+	expected := []Event{{Event: "resume", Data: "6"}, {ID: "7", Event: "message", Data: "line 1\nline 2"}}
+	for _, exp := range expected {
+		ev, err := er.Next()
+		if err != nil {
+			t.Fatal(err)
+		}
+		if ev != exp {
+			t.Fatalf("expected %v, got %v", exp, ev)
+		}
+	}
+	if _, err := er.Next(); !errors.Is(err, io.EOF) {
+		t.Fatalf("expected the end of the stream, got %v", err)
+	}
+	if er.Retry() != 2*time.Second {
+		t.Fatalf("expected a retry of 2s, got %v", er.Retry())
+	}
+
+	req.Header.Set("Accept", "application/json")
+	resp, err = srv.Client().Do(req)
+	if err != nil {
+		t.Fatal(err)
+	}
+	resp.Body.Close()
+	if resp.StatusCode != http.StatusNotAcceptable {
+		t.Fatalf("expected status 406, got %d", resp.StatusCode)
+	}
+}
@@ -0,0 +1,258 @@
+// Copyright 2026 Franklin "Snaipe" Mathieu.
+//
+// Use of this source code is governed by the MIT license that can be
+// found in the LICENSE file.
+
+package htutil
+
+import (
+	"net/http"
+	"strconv"
+	"sync"
+)
+
+// Status is an HTTP status code, with helpers for its semantics as defined
+// by RFC 9110 §15.
+type Status int
+
+func (s Status) String() string {
+	if text := StatusText(int(s)); text != "" {
+		return strconv.Itoa(int(s)) + " " + text
+	}
+	return strconv.Itoa(int(s))
+}
+
+// StatusClass is the class of a status code, given by its first digit.
+type StatusClass int
+
+const (
+	UnknownClass StatusClass = iota
+	Informational
+	Successful
+	Redirection
+	ClientError
+	ServerError
+)
+
+var statusClassNames = [...]string{
+	UnknownClass:  "unknown",
+	Informational: "informational",
+	Successful:    "successful",
+	Redirection:   "redirection",
+	ClientError:   "client error",
+	ServerError:   "server error",
+}
+
+func (c StatusClass) String() string {
+	if c < 0 || int(c) >= len(statusClassNames) {
+		return statusClassNames[UnknownClass]
+	}
+	return statusClassNames[c]
+}
+
+// Class returns the class of s, or UnknownClass if s is not a valid status
+// code.
+func (s Status) Class() StatusClass {
+	if s < 100 || s >= 600 {
+		return UnknownClass
+	}
+	return StatusClass(s / 100)
+}
+
+// IsInformational returns whether s is a 1xx interim status.
+func (s Status) IsInformational() bool { return s >= 100 && s < 200 }
+
+// IsSuccess returns whether s is a 2xx status.
+func (s Status) IsSuccess() bool { return s >= 200 && s < 300 }
+
+// IsRedirect returns whether s is a 3xx status.
+func (s Status) IsRedirect() bool { return s >= 300 && s < 400 }
+
+// IsClientError returns whether s is a 4xx status.
+func (s Status) IsClientError() bool { return s >= 400 && s < 500 }
+
+// IsServerError returns whether s is a 5xx status.
+func (s Status) IsServerError() bool { return s >= 500 && s < 600 }
+
+// IsError returns whether s is either a client or a server error.
+func (s Status) IsError() bool { return s >= 400 && s < 600 }
+
+// AllowsBody returns whether responses with status s may have content.
+// Responses to HEAD requests and successful CONNECT requests never have
+// content regardless; see MustNotIncludeContent.
+func (s Status) AllowsBody() bool {
+	switch {
+	case s.IsInformational(), s == http.StatusNoContent, s == http.StatusNotModified:
+		return false
+	}
+	return true
+}
+
+// MustNotIncludeContent returns whether a response with status s to a
+// request with the specified method must not include content, as per
+// RFC 9110 §6.4.1.
+func (s Status) MustNotIncludeContent(method string) bool {
+	switch {
+	case method == http.MethodHead:
+		return true
+	case method == http.MethodConnect && s.IsSuccess():
+		return true
+	}
+	return !s.AllowsBody()
+}
+
+// IsRetryable returns whether a request with the specified method that
+// received a response with status s may be automatically retried.
+//
+// Statuses indicating that the request was not processed at all (408, 425,
+// 429) allow retrying any request. Statuses indicating a transient failure
+// of the server or of an upstream server (502, 503, 504) only allow
+// retrying idempotent requests, as the request may have been partially
+// processed. Callers should honor Retry-After when present.
+func (s Status) IsRetryable(method string) bool {
+	switch s {
+	case http.StatusRequestTimeout, http.StatusTooEarly, http.StatusTooManyRequests:
+		return true
+	case http.StatusBadGateway, http.StatusServiceUnavailable, http.StatusGatewayTimeout:
+		return isIdempotent(method)
+	}
+	return false
+}
+
+// isSafe returns whether method is safe, as per RFC 9110 §9.2.1.
+func isSafe(method string) bool {
+	switch method {
+	case http.MethodGet, http.MethodHead, http.MethodOptions, http.MethodTrace:
+		return true
+	}
+	return false
+}
+
+// isIdempotent returns whether method is idempotent, as per RFC 9110
+// §9.2.2.
+func isIdempotent(method string) bool {
+	switch method {
+	case http.MethodGet, http.MethodHead, http.MethodOptions, http.MethodTrace,
+		http.MethodPut, http.MethodDelete:
+		return true
+	}
+	return false
+}
+
+// StatusCategory is a finer-grained categorization of error statuses than
+// their class, telling what went wrong regardless of which vendor coined
+// the status code.
+type StatusCategory int
+
+const (
+	Uncategorized StatusCategory = iota
+
+	// ConnectionClosed statuses record that the connection was closed
+	// before a response could be sent, by either side.
+	ConnectionClosed
+
+	// RequestRejected statuses record that a gateway rejected the request
+	// before forwarding it, e.g. because of its size or of its TLS
+	// handshake.
+	RequestRejected
+
+	// UpstreamFailure statuses record that a gateway failed to obtain a
+	// response from an upstream server.
+	UpstreamFailure
+
+	// Throttled statuses record that the request was rejected because the
+	// client exceeded a rate or bandwidth limit.
+	Throttled
+)
+
+var statusCategoryNames = [...]string{
+	Uncategorized:    "uncategorized",
+	ConnectionClosed: "connection closed",
+	RequestRejected:  "request rejected",
+	UpstreamFailure:  "upstream failure",
+	Throttled:        "throttled",
+}
+
+func (c StatusCategory) String() string {
+	if c < 0 || int(c) >= len(statusCategoryNames) {
+		return statusCategoryNames[Uncategorized]
+	}
+	return statusCategoryNames[c]
+}
+
+type statusInfo struct {
+	text     string
+	category StatusCategory
+}
+
+var (
+	statusMu       sync.RWMutex
+	statusRegistry = map[int]statusInfo{
+		// nginx
+		444: {"No Response", ConnectionClosed},
+		494: {"Request Header Too Large", RequestRejected},
+		495: {"SSL Certificate Error", RequestRejected},
+		496: {"SSL Certificate Required", RequestRejected},
+		497: {"HTTP Request Sent to HTTPS Port", RequestRejected},
+		499: {"Client Closed Request", ConnectionClosed},
+
+		// Cloudflare
+		520: {"Web Server Returned an Unknown Error", UpstreamFailure},
+		521: {"Web Server Is Down", UpstreamFailure},
+		522: {"Connection Timed Out", UpstreamFailure},
+		523: {"Origin Is Unreachable", UpstreamFailure},
+		524: {"A Timeout Occurred", UpstreamFailure},
+		525: {"SSL Handshake Failed", UpstreamFailure},
+		526: {"Invalid SSL Certificate", UpstreamFailure},
+
+		// Miscellaneous proxies
+		509: {"Bandwidth Limit Exceeded", Throttled},
+		598: {"Network Read Timeout Error", UpstreamFailure},
+		599: {"Network Connect Timeout Error", UpstreamFailure},
+	}
+
+	// standardCategories are the categories of the standard statuses.
+	standardCategories = map[int]StatusCategory{
+		http.StatusRequestHeaderFieldsTooLarge: RequestRejected,
+		http.StatusTooManyRequests:             Throttled,
+		http.StatusBadGateway:                  UpstreamFailure,
+		http.StatusGatewayTimeout:              UpstreamFailure,
+	}
+)
+
+// RegisterStatus registers the reason phrase and the category of a
+// non-standard status code, such as the ones used by gateways and vendors,
+// so that StatusText, Status.Category, and everything that relies on them,
+// like problem rendering and logging, pick them up. Registered statuses
+// take precedence over the standard ones.
+func RegisterStatus(code int, text string, category StatusCategory) {
+	statusMu.Lock()
+	defer statusMu.Unlock()
+	statusRegistry[code] = statusInfo{text, category}
+}
+
+// StatusText returns the reason phrase of the status code, like
+// http.StatusText, but also knows about common non-standard status codes,
+// and the ones added with RegisterStatus. It returns the empty string if
+// the code is unknown.
+func StatusText(code int) string {
+	statusMu.RLock()
+	info, ok := statusRegistry[code]
+	statusMu.RUnlock()
+	if ok {
+		return info.text
+	}
+	return http.StatusText(code)
+}
+
+// Category returns the category of s, as registered with RegisterStatus
+// for non-standard statuses, or Uncategorized if s has none.
+func (s Status) Category() StatusCategory {
+	statusMu.RLock()
+	info, ok := statusRegistry[int(s)]
+	statusMu.RUnlock()
+	if ok {
+		return info.category
+	}
+	return standardCategories[int(s)]
+}
@@ -0,0 +1,137 @@
+// Copyright 2026 Franklin "Snaipe" Mathieu.
+//
+// Use of this source code is governed by the MIT license that can be
+// found in the LICENSE file.
+
+package htutil
+
+import (
+	"fmt"
+	"testing"
+)
+
+func TestStatusClass(t *testing.T) {
+	t.Parallel()
+
+	tcases := []struct {
+		Status Status
+		Class  StatusClass
+		String string
+	}{
+		{Status: 103, Class: Informational, String: "103 Early Hints"},
+		{Status: 204, Class: Successful, String: "204 No Content"},
+		{Status: 308, Class: Redirection, String: "308 Permanent Redirect"},
+		{Status: 418, Class: ClientError, String: "418 I'm a teapot"},
+		{Status: 499, Class: ClientError, String: "499 Client Closed Request"},
+		{Status: 599, Class: ServerError, String: "599 Network Connect Timeout Error"},
+		{Status: 590, Class: ServerError, String: "590"},
+		{Status: 42, Class: UnknownClass, String: "42"},
+	}
+
+	for i, tcase := range tcases {
+		t.Run(fmt.Sprintf("%d", i), func(t *testing.T) {
+			s := tcase.Status
+			if c := s.Class(); c != tcase.Class {
+				t.Fatalf("expected class %v, got %v", tcase.Class, c)
+			}
+			predicates := map[StatusClass]bool{
+				Informational: s.IsInformational(),
+				Successful:    s.IsSuccess(),
+				Redirection:   s.IsRedirect(),
+				ClientError:   s.IsClientError(),
+				ServerError:   s.IsServerError(),
+			}
+			for class, v := range predicates {
+				if v != (class == tcase.Class) {
+					t.Fatalf("expected %v predicate to be %v, got %v", class, class == tcase.Class, v)
+				}
+			}
+			if str := s.String(); str != tcase.String {
+				t.Fatalf("expected %q, got %q", tcase.String, str)
+			}
+		})
+	}
+}
+
+func TestRegisterStatus(t *testing.T) {
+	t.Cleanup(func() {
+		statusMu.Lock()
+		defer statusMu.Unlock()
+		delete(statusRegistry, 555)
+	})
+
+	if c := Status(555).Category(); c != Uncategorized {
+		t.Fatalf("expected %v, got %v", Uncategorized, c)
+	}
+	RegisterStatus(555, "Vendor Meltdown", UpstreamFailure)
+	if s := Status(555).String(); s != "555 Vendor Meltdown" {
+		t.Fatalf("expected %q, got %q", "555 Vendor Meltdown", s)
+	}
+	if c := Status(555).Category(); c != UpstreamFailure {
+		t.Fatalf("expected %v, got %v", UpstreamFailure, c)
+	}
+}
+
+func TestStatusCategory(t *testing.T) {
+	t.Parallel()
+
+	tcases := []struct {
+		Status   Status
+		Category StatusCategory
+	}{
+		{Status: 499, Category: ConnectionClosed},
+		{Status: 495, Category: RequestRejected},
+		{Status: 431, Category: RequestRejected},
+		{Status: 522, Category: UpstreamFailure},
+		{Status: 504, Category: UpstreamFailure},
+		{Status: 429, Category: Throttled},
+		{Status: 404, Category: Uncategorized},
+	}
+
+	for i, tcase := range tcases {
+		t.Run(fmt.Sprintf("%d", i), func(t *testing.T) {
+			if c := tcase.Status.Category(); c != tcase.Category {
+				t.Fatalf("expected %v, got %v", tcase.Category, c)
+			}
+		})
+	}
+}
+
+func TestStatusSemantics(t *testing.T) {
+	t.Parallel()
+
+	tcases := []struct {
+		Status         Status
+		Method         string
+		AllowsBody     bool
+		MustNotInclude bool
+		Retryable      bool
+	}{
+		{Status: 200, Method: "GET", AllowsBody: true},
+		{Status: 200, Method: "HEAD", AllowsBody: true, MustNotInclude: true},
+		{Status: 200, Method: "CONNECT", AllowsBody: true, MustNotInclude: true},
+		{Status: 407, Method: "CONNECT", AllowsBody: true},
+		{Status: 204, Method: "DELETE", MustNotInclude: true},
+		{Status: 304, Method: "GET", MustNotInclude: true},
+		{Status: 100, Method: "PUT", MustNotInclude: true},
+		{Status: 429, Method: "POST", AllowsBody: true, Retryable: true},
+		{Status: 503, Method: "POST", AllowsBody: true},
+		{Status: 503, Method: "PUT", AllowsBody: true, Retryable: true},
+		{Status: 500, Method: "GET", AllowsBody: true},
+	}
+
+	for i, tcase := range tcases {
+		t.Run(fmt.Sprintf("%d", i), func(t *testing.T) {
+			s := tcase.Status
+			if v := s.AllowsBody(); v != tcase.AllowsBody {
+				t.Fatalf("expected AllowsBody %v, got %v", tcase.AllowsBody, v)
+			}
+			if v := s.MustNotIncludeContent(tcase.Method); v != tcase.MustNotInclude {
+				t.Fatalf("expected MustNotIncludeContent %v, got %v", tcase.MustNotInclude, v)
+			}
+			if v := s.IsRetryable(tcase.Method); v != tcase.Retryable {
+				t.Fatalf("expected IsRetryable %v, got %v", tcase.Retryable, v)
+			}
+		})
+	}
+}
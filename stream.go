@@ -0,0 +1,204 @@
+// Copyright 2026 Franklin "Snaipe" Mathieu.
+//
+// Use of this source code is governed by the MIT license that can be
+// found in the LICENSE file.
+
+package htutil
+
+import (
+	"bytes"
+	"encoding/csv"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"strings"
+	"time"
+)
+
+// streamFormats are the media types that streams can be encoded in.
+var streamFormats = []string{"application/json", "application/x-ndjson", "text/csv"}
+
+// Streamer encodes large responses as a sequence of elements, which are
+// written and flushed incrementally instead of being held in memory.
+//
+// The supported formats are JSON arrays (application/json), newline
+// delimited JSON (application/x-ndjson), and CSV (text/csv).
+type Streamer struct {
+	// MediaTypes are the formats offered to clients, in order of
+	// preference. Defaults to all the supported formats.
+	MediaTypes []string
+
+	// FlushSize is the amount of encoded data after which the response is
+	// flushed. Defaults to 32KiB.
+	FlushSize int
+
+	// FlushInterval is the delay after which encoded data is flushed, even
+	// if there is less than FlushSize of it, so that clients get a steady
+	// stream. Defaults to 1 second.
+	FlushInterval time.Duration
+
+	// WriteTimeout, if set, is the time that clients get to receive each
+	// flushed chunk. Writes block while clients are slow to read, which
+	// holds back producers; the timeout fails the stream instead when a
+	// client stalls.
+	WriteTimeout time.Duration
+}
+
+// Stream is a response body being streamed, as started by Streamer.Stream.
+type Stream struct {
+	w       http.ResponseWriter
+	req     *http.Request
+	rc      *http.ResponseController
+	s       *Streamer
+	ctype   string
+	pending bytes.Buffer
+	csv     *csv.Writer
+	count   int
+	flushed time.Time
+	err     error
+}
+
+// Stream starts streaming the response to req, in the format negotiated
+// with its Accept header field. It returns a 406 Not Acceptable problem if
+// none of the formats of s is acceptable.
+//
+// Buffering middlewares of this package, like Cache, Coalesce, or
+// Conditional, are bypassed for the response; no header field may be set
+// once the stream is started.
+func (s *Streamer) Stream(w http.ResponseWriter, req *http.Request) (*Stream, error) {
+	offers := s.MediaTypes
+	if len(offers) == 0 {
+		offers = streamFormats
+	}
+	AddVary(w.Header(), "Accept")
+	ctype, _ := NegotiateContent(req.Header, "Accept", offers...)
+	if ctype == "" {
+		return nil, NewProblem(http.StatusNotAcceptable,
+			"Available representations: "+strings.Join(offers, ", ")+".")
+	}
+	if !containsString(streamFormats, ctype) {
+		return nil, fmt.Errorf("streaming %s: unsupported format", ctype)
+	}
+
+	bypassBuffers(req)
+	h := w.Header()
+	h.Set("Content-Type", ctype)
+	h.Del("Content-Length")
+	h.Del("ETag")
+
+	st := &Stream{
+		w:       w,
+		req:     req,
+		rc:      http.NewResponseController(w),
+		s:       s,
+		ctype:   ctype,
+		flushed: time.Now(),
+	}
+	if ctype == "text/csv" {
+		st.csv = csv.NewWriter(&st.pending)
+	}
+	return st, nil
+}
+
+// MediaType returns the format of the stream.
+func (st *Stream) MediaType() string {
+	return st.ctype
+}
+
+// ErrStreamRecord is returned when encoding a value that is not a record
+// into a CSV stream.
+var ErrStreamRecord = errors.New("CSV streams only encode []string records")
+
+// Encode appends v to the stream. For CSV streams, v must be a []string
+// record; any header record should be encoded first.
+//
+// Encode returns an error if the client went away, or failed to keep up
+// within the WriteTimeout of the Streamer; the handler should then stop
+// producing elements.
+func (st *Stream) Encode(v any) error {
+	if st.err != nil {
+		return st.err
+	}
+	if err := st.req.Context().Err(); err != nil {
+		st.err = err
+		return err
+	}
+
+	switch st.ctype {
+	case "application/json":
+		data, err := json.Marshal(v)
+		if err != nil {
+			return err
+		}
+		if st.count == 0 {
+			st.pending.WriteByte('[')
+		} else {
+			st.pending.WriteByte(',')
+		}
+		st.pending.Write(data)
+	case "application/x-ndjson":
+		if err := json.NewEncoder(&st.pending).Encode(v); err != nil {
+			return err
+		}
+	case "text/csv":
+		record, ok := v.([]string)
+		if !ok {
+			return ErrStreamRecord
+		}
+		if err := st.csv.Write(record); err != nil {
+			return err
+		}
+		st.csv.Flush()
+	}
+	st.count++
+
+	size := st.s.FlushSize
+	if size <= 0 {
+		size = 32 << 10
+	}
+	interval := st.s.FlushInterval
+	if interval <= 0 {
+		interval = time.Second
+	}
+	if st.pending.Len() >= size || time.Since(st.flushed) >= interval {
+		return st.Flush()
+	}
+	return nil
+}
+
+// Flush writes the encoded elements to the client.
+func (st *Stream) Flush() error {
+	if st.err != nil {
+		return st.err
+	}
+	if st.s.WriteTimeout > 0 {
+		// Not all writers support deadlines; streams are then only bounded
+		// by the server timeouts.
+		st.rc.SetWriteDeadline(time.Now().Add(st.s.WriteTimeout))
+	}
+	if st.req.Method != http.MethodHead {
+		if _, err := st.pending.WriteTo(st.w); err != nil {
+			st.err = err
+			return err
+		}
+	}
+	st.pending.Reset()
+	if err := st.rc.Flush(); err != nil && !errors.Is(err, http.ErrNotSupported) {
+		st.err = err
+		return err
+	}
+	st.flushed = time.Now()
+	return nil
+}
+
+// Close terminates the stream, and flushes the remaining elements.
+func (st *Stream) Close() error {
+	if st.ctype == "application/json" {
+		if st.count == 0 {
+			st.pending.WriteByte('[')
+		}
+		st.pending.WriteString("]\n")
+	}
+	return st.Flush()
+}
@@ -0,0 +1,195 @@
+// Copyright 2026 Franklin "Snaipe" Mathieu.
+//
+// Use of this source code is governed by the MIT license that can be
+// found in the LICENSE file.
+
+package htutil
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+func TestStream(t *testing.T) {
+	t.Parallel()
+
+	type row struct {
+		ID   int    `json:"id"`
+		Name string `json:"name"`
+	}
+
+	tcases := []struct {
+		Accept      string
+		Method      string
+		Rows        int
+		ContentType string
+		Body        string
+		Status      int
+	}{
+		{
+			Rows:        2,
+			ContentType: "application/json",
+			Body:        `[{"id":0,"name":"row 0"},{"id":1,"name":"row 1"}]` + "\n",
+		},
+		{
+			Rows:        0,
+			ContentType: "application/json",
+			Body:        "[]\n",
+		},
+		{
+			Accept:      "application/x-ndjson",
+			Rows:        2,
+			ContentType: "application/x-ndjson",
+			Body:        `{"id":0,"name":"row 0"}` + "\n" + `{"id":1,"name":"row 1"}` + "\n",
+		},
+		{
+			Accept:      "text/csv",
+			Rows:        2,
+			ContentType: "text/csv",
+			Body:        "id,name\n0,row 0\n1,row 1\n",
+		},
+		{
+			Method:      "HEAD",
+			Rows:        2,
+			ContentType: "application/json",
+			Body:        "",
+		},
+		{
+			Accept: "text/html",
+			Status: 406,
+		},
+	}
+
+	for i, tcase := range tcases {
+		t.Run(fmt.Sprintf("%d", i), func(t *testing.T) {
+			s := &Streamer{}
+			handler := http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+				st, err := s.Stream(w, req)
+				if err != nil {
+					RespondError(w, req, err)
+					return
+				}
+				if st.MediaType() == "text/csv" {
+					st.Encode([]string{"id", "name"})
+				}
+				for i := 0; i < tcase.Rows; i++ {
+					var err error
+					if st.MediaType() == "text/csv" {
+						err = st.Encode([]string{fmt.Sprint(i), fmt.Sprintf("row %d", i)})
+					} else {
+						err = st.Encode(row{ID: i, Name: fmt.Sprintf("row %d", i)})
+					}
+					if err != nil {
+						t.Fatalf("expected no error, got %v", err)
+					}
+				}
+				if err := st.Close(); err != nil {
+					t.Fatalf("expected no error, got %v", err)
+				}
+			})
+
+			method := tcase.Method
+			if method == "" {
+				method = "GET"
+			}
+			req := httptest.NewRequest(method, "/", nil)
+			if tcase.Accept != "" {
+				req.Header.Set("Accept", tcase.Accept)
+			}
+			rw := httptest.NewRecorder()
+			handler.ServeHTTP(rw, req)
+
+			status := tcase.Status
+			if status == 0 {
+				status = 200
+			}
+			if rw.Code != status {
+				t.Fatalf("expected status %d, got %d", status, rw.Code)
+			}
+			if status != 200 {
+				return
+			}
+			if ctype := rw.Header().Get("Content-Type"); ctype != tcase.ContentType {
+				t.Fatalf("expected content type %q, got %q", tcase.ContentType, ctype)
+			}
+			if rw.Body.String() != tcase.Body {
+				t.Fatalf("expected body %q, got %q", tcase.Body, rw.Body.String())
+			}
+		})
+	}
+}
+
+func TestStreamBypassesBuffering(t *testing.T) {
+	t.Parallel()
+
+	signing := &ResponseSigning{Signer: NewHMACSigner("k", []byte("secret"))}
+	tcases := []struct {
+		Wrap func(http.Handler) http.Handler
+	}{
+		{Wrap: func(next http.Handler) http.Handler {
+			return Conditional(Conditional(next, DigestETagger{}), DigestETagger{})
+		}},
+		{Wrap: func(next http.Handler) http.Handler { return Coalesce(next) }},
+		{Wrap: signing.Middleware},
+	}
+
+	for i, tcase := range tcases {
+		t.Run(fmt.Sprintf("%d", i), func(t *testing.T) {
+			// Flushing after each element lets the handler observe that the
+			// elements reached the client before the stream is closed.
+			s := &Streamer{FlushSize: 1}
+			var seen []string
+			var rw *httptest.ResponseRecorder
+			handler := tcase.Wrap(http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+				w.Header().Set("X-Before", "kept")
+				st, err := s.Stream(w, req)
+				if err != nil {
+					t.Fatalf("expected no error, got %v", err)
+				}
+				for i := 0; i < 3; i++ {
+					st.Encode(i)
+					seen = append(seen, rw.Body.String())
+				}
+				st.Close()
+			}))
+
+			rw = httptest.NewRecorder()
+			handler.ServeHTTP(rw, httptest.NewRequest("GET", "/", nil))
+
+			expected := []string{"[0", "[0,1", "[0,1,2"}
+			if strings.Join(seen, " ") != strings.Join(expected, " ") {
+				t.Fatalf("expected %v, got %v", expected, seen)
+			}
+			if !rw.Flushed {
+				t.Fatalf("expected response to be flushed")
+			}
+			if rw.Header().Get("ETag") != "" || rw.Header().Get("Signature") != "" {
+				t.Fatalf("expected no ETag nor Signature, got %v", rw.Header())
+			}
+			if rw.Header().Get("X-Before") != "kept" {
+				t.Fatalf("expected header set before streaming to be kept, got %v", rw.Header())
+			}
+			if rw.Body.String() != "[0,1,2]\n" {
+				t.Fatalf("expected [0,1,2], got %q", rw.Body.String())
+			}
+		})
+	}
+}
+
+func TestStreamCSVRecord(t *testing.T) {
+	t.Parallel()
+
+	req := httptest.NewRequest("GET", "/", nil)
+	req.Header.Set("Accept", "text/csv")
+	st, err := (&Streamer{}).Stream(httptest.NewRecorder(), req)
+	if err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+	if err := st.Encode(42); !errors.Is(err, ErrStreamRecord) {
+		t.Fatalf("expected ErrStreamRecord, got %v", err)
+	}
+}
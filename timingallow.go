@@ -0,0 +1,67 @@
+// Copyright 2026 Franklin "Snaipe" Mathieu.
+//
+// Use of this source code is governed by the MIT license that can be
+// found in the LICENSE file.
+
+package htutil
+
+import (
+	"net/http"
+	"strings"
+)
+
+// FormatTimingAllowOrigin formats a Timing-Allow-Origin header value
+// allowing the specified origins to access resource timing information.
+// Calling it with no origins returns "*", allowing all origins.
+func FormatTimingAllowOrigin(origins ...Origin) string {
+	if len(origins) == 0 {
+		return "*"
+	}
+	out := make([]string, len(origins))
+	for i, o := range origins {
+		out[i] = o.String()
+	}
+	return strings.Join(out, ", ")
+}
+
+// SetTimingAllowOrigin sets the Timing-Allow-Origin header field of h for
+// a response to req, such that only the origins allowed by policy may
+// access the detailed resource timing information of the response.
+//
+// If the policy allows any origin, "*" is emitted. Otherwise, if the
+// request carries an allowed Origin, that origin is reflected, and Origin
+// is added to the Vary header field. Since browsers do not send Origin
+// for all the requests that Resource Timing covers, requests without
+// Origin get the list of the exact origins of the policy instead.
+func SetTimingAllowOrigin(h http.Header, req *http.Request, policy *OriginPolicy) {
+	if policy.AllowsAny() {
+		h.Set("Timing-Allow-Origin", "*")
+		return
+	}
+
+	if len(req.Header.Values("Origin")) > 0 {
+		AddVary(h, "Origin")
+		o, ok := RequestOrigin(req)
+		if ok && policy.Allows(o) {
+			h.Set("Timing-Allow-Origin", FormatTimingAllowOrigin(o))
+		} else {
+			h.Del("Timing-Allow-Origin")
+		}
+		return
+	}
+
+	var origins []Origin
+	for _, pattern := range policy.Origins {
+		if strings.Contains(pattern, "*") {
+			continue
+		}
+		if o, err := ParseOrigin(pattern); err == nil && !o.IsNull() {
+			origins = append(origins, o)
+		}
+	}
+	if len(origins) == 0 {
+		h.Del("Timing-Allow-Origin")
+		return
+	}
+	h.Set("Timing-Allow-Origin", FormatTimingAllowOrigin(origins...))
+}
@@ -0,0 +1,50 @@
+// Copyright 2026 Franklin "Snaipe" Mathieu.
+//
+// Use of this source code is governed by the MIT license that can be
+// found in the LICENSE file.
+
+package htutil
+
+import (
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestSetTimingAllowOrigin(t *testing.T) {
+	t.Parallel()
+
+	restricted := &OriginPolicy{Origins: []string{"https://example.com", "https://*.example.org", "http://localhost:8080"}}
+
+	tcases := []struct {
+		Policy   *OriginPolicy
+		Origin   string
+		Expected string
+		Vary     bool
+	}{
+		{Policy: &OriginPolicy{Origins: []string{"*"}}, Origin: "https://a.test", Expected: "*"},
+		{Policy: restricted, Origin: "https://api.example.org", Expected: "https://api.example.org", Vary: true},
+		{Policy: restricted, Origin: "https://evil.test", Expected: "", Vary: true},
+		{Policy: restricted, Expected: "https://example.com, http://localhost:8080"},
+		{Policy: &OriginPolicy{}, Expected: ""},
+	}
+
+	for i, tcase := range tcases {
+		t.Run(fmt.Sprintf("%d", i), func(t *testing.T) {
+			req := httptest.NewRequest("GET", "/", nil)
+			if tcase.Origin != "" {
+				req.Header.Set("Origin", tcase.Origin)
+			}
+			h := http.Header{}
+			SetTimingAllowOrigin(h, req, tcase.Policy)
+
+			if actual := h.Get("Timing-Allow-Origin"); actual != tcase.Expected {
+				t.Fatalf("expected %q, got %q", tcase.Expected, actual)
+			}
+			if vary := h.Get("Vary") == "Origin"; vary != tcase.Vary {
+				t.Fatalf("expected Vary: Origin to be %v, got %v", tcase.Vary, h.Values("Vary"))
+			}
+		})
+	}
+}
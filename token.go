@@ -0,0 +1,114 @@
+// Copyright 2026 Franklin "Snaipe" Mathieu.
+//
+// Use of this source code is governed by the MIT license that can be
+// found in the LICENSE file.
+
+package htutil
+
+import (
+	"fmt"
+	"strings"
+)
+
+// isTchar returns whether c is a valid token character, as per
+// RFC 9110 §5.6.2.
+func isTchar(c byte) bool {
+	switch {
+	case c >= 'a' && c <= 'z', c >= 'A' && c <= 'Z', c >= '0' && c <= '9':
+		return true
+	}
+	return strings.IndexByte("!#$%&'*+-.^_`|~", c) != -1
+}
+
+// isQdtext returns whether c may appear unescaped in a quoted-string, as per
+// RFC 9110 §5.6.4.
+func isQdtext(c byte) bool {
+	return c == '\t' || c == ' ' || c == 0x21 || (c >= 0x23 && c <= 0x5B) || (c >= 0x5D && c <= 0x7E) || c >= 0x80
+}
+
+// isQuotable returns whether c may appear in a quoted-pair, as per
+// RFC 9110 §5.6.4.
+func isQuotable(c byte) bool {
+	return c == '\t' || c == ' ' || (c >= 0x21 && c <= 0x7E) || c >= 0x80
+}
+
+// IsToken returns whether s is a valid token, as per RFC 9110 §5.6.2.
+func IsToken(s string) bool {
+	return ValidateToken(s) == nil
+}
+
+// ValidateToken returns an error describing why s is not a valid token,
+// or nil if it is one.
+func ValidateToken(s string) error {
+	if s == "" {
+		return fmt.Errorf("empty token")
+	}
+	for i := 0; i < len(s); i++ {
+		if !isTchar(s[i]) {
+			return fmt.Errorf("invalid character %q at offset %d in token %q", s[i], i, s)
+		}
+	}
+	return nil
+}
+
+// QuoteString returns s as a quoted-string, as per RFC 9110 §5.6.4.
+//
+// Control characters other than horizontal tabs cannot be represented in
+// a quoted-string, and are replaced by spaces.
+func QuoteString(s string) string {
+	var out strings.Builder
+	out.Grow(len(s) + 2)
+	out.WriteByte('"')
+	for i := 0; i < len(s); i++ {
+		switch c := s[i]; {
+		case isQdtext(c):
+			out.WriteByte(c)
+		case isQuotable(c):
+			out.WriteByte('\\')
+			out.WriteByte(c)
+		default:
+			out.WriteByte(' ')
+		}
+	}
+	out.WriteByte('"')
+	return out.String()
+}
+
+// UnquoteString returns the content of the quoted-string s, as per
+// RFC 9110 §5.6.4.
+func UnquoteString(s string) (string, error) {
+	if len(s) < 2 || s[0] != '"' || s[len(s)-1] != '"' {
+		return "", fmt.Errorf("%s is not a quoted string", s)
+	}
+	s = s[1 : len(s)-1]
+
+	var out strings.Builder
+	out.Grow(len(s))
+	for i := 0; i < len(s); i++ {
+		switch c := s[i]; {
+		case c == '\\':
+			i++
+			if i == len(s) || !isQuotable(s[i]) {
+				return "", fmt.Errorf("invalid quoted-pair at offset %d", i)
+			}
+			out.WriteByte(s[i])
+		case isQdtext(c):
+			out.WriteByte(c)
+		default:
+			return "", fmt.Errorf("invalid character %q at offset %d in quoted string", c, i+1)
+		}
+	}
+	return out.String(), nil
+}
+
+// tokenOrQuoted returns s unchanged if it is a token, or as a quoted-string
+// otherwise. This is the form expected for parameter values.
+func tokenOrQuoted(s string) string {
+	if IsToken(s) {
+		return s
+	}
+	return QuoteString(s)
+}
+
+func isDigit(c byte) bool { return c >= '0' && c <= '9' }
+func isAlpha(c byte) bool { return (c >= 'a' && c <= 'z') || (c >= 'A' && c <= 'Z') }
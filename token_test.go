@@ -0,0 +1,101 @@
+// Copyright 2026 Franklin "Snaipe" Mathieu.
+//
+// Use of this source code is governed by the MIT license that can be
+// found in the LICENSE file.
+
+package htutil
+
+import (
+	"fmt"
+	"testing"
+)
+
+func TestIsToken(t *testing.T) {
+	t.Parallel()
+
+	tcases := []struct {
+		In       string
+		Expected bool
+	}{
+		{In: "gzip", Expected: true},
+		{In: "x-custom_thing.v1~!#$%&'*+^`|", Expected: true},
+		{In: "", Expected: false},
+		{In: "text/html", Expected: false},
+		{In: "a b", Expected: false},
+		{In: `"quoted"`, Expected: false},
+		{In: "caf\xc3\xa9", Expected: false},
+	}
+
+	for i, tcase := range tcases {
+		t.Run(fmt.Sprintf("%d", i), func(t *testing.T) {
+			actual := IsToken(tcase.In)
+			if actual != tcase.Expected {
+				t.Fatalf("expected %v, got %v", tcase.Expected, actual)
+			}
+		})
+	}
+}
+
+func TestQuoteString(t *testing.T) {
+	t.Parallel()
+
+	tcases := []struct {
+		In  string
+		Out string
+	}{
+		{In: "", Out: `""`},
+		{In: "hello world", Out: `"hello world"`},
+		{In: `say "hi"`, Out: `"say \"hi\""`},
+		{In: `back\slash`, Out: `"back\\slash"`},
+		{In: "tab\tnew\nline", Out: "\"tab\tnew line\""},
+	}
+
+	for i, tcase := range tcases {
+		t.Run(fmt.Sprintf("%d", i), func(t *testing.T) {
+			actual := QuoteString(tcase.In)
+			if actual != tcase.Out {
+				t.Fatalf("expected %s, got %s", tcase.Out, actual)
+			}
+		})
+	}
+}
+
+func TestUnquoteString(t *testing.T) {
+	t.Parallel()
+
+	tcases := []struct {
+		In  string
+		Out string
+		Err bool
+	}{
+		{In: `""`, Out: ""},
+		{In: `"hello world"`, Out: "hello world"},
+		{In: `"say \"hi\""`, Out: `say "hi"`},
+		{In: `"\a\\b"`, Out: `a\b`},
+		{In: `"unterminated\"`, Err: true},
+		{In: `"in"valid"`, Err: true},
+		{In: "\"ctl\x01\"", Err: true},
+		{In: `unquoted`, Err: true},
+	}
+
+	for i, tcase := range tcases {
+		t.Run(fmt.Sprintf("%d", i), func(t *testing.T) {
+			actual, err := UnquoteString(tcase.In)
+			if tcase.Err {
+				if err == nil {
+					t.Fatalf("expected error, got %q", actual)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatal(err)
+			}
+			if actual != tcase.Out {
+				t.Fatalf("expected %q, got %q", tcase.Out, actual)
+			}
+			if roundtrip, _ := UnquoteString(QuoteString(actual)); roundtrip != actual {
+				t.Fatalf("expected %q to round-trip, got %q", actual, roundtrip)
+			}
+		})
+	}
+}
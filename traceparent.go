@@ -0,0 +1,192 @@
+// Copyright 2026 Franklin "Snaipe" Mathieu.
+//
+// Use of this source code is governed by the MIT license that can be
+// found in the LICENSE file.
+
+package htutil
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"net/http"
+	"strings"
+)
+
+// TraceFlagSampled is the trace flag recording that the caller may have
+// sampled the trace.
+const TraceFlagSampled byte = 0x01
+
+// TraceParent is the value of a traceparent header field, which identifies
+// the position of a request in a distributed trace, as per the W3C Trace
+// Context recommendation.
+type TraceParent struct {
+	// Version is the version of the traceparent format.
+	Version byte
+
+	// TraceID identifies the whole trace.
+	TraceID [16]byte
+
+	// ParentID identifies the caller's span of the trace.
+	ParentID [8]byte
+
+	// Flags are the trace flags, such as TraceFlagSampled.
+	Flags byte
+}
+
+// NewTraceParent returns the traceparent of a new trace, with random trace
+// and parent identifiers.
+func NewTraceParent(sampled bool) TraceParent {
+	var tp TraceParent
+	randomID(tp.TraceID[:])
+	randomID(tp.ParentID[:])
+	if sampled {
+		tp.Flags = TraceFlagSampled
+	}
+	return tp
+}
+
+// Child returns the traceparent of a new span of the trace of tp, in the
+// version of the format that this package implements, with the same trace
+// identifier and flags, and a random parent identifier.
+func (tp TraceParent) Child() TraceParent {
+	child := TraceParent{TraceID: tp.TraceID, Flags: tp.Flags}
+	randomID(child.ParentID[:])
+	return child
+}
+
+// Sampled returns whether the caller may have sampled the trace.
+func (tp TraceParent) Sampled() bool {
+	return tp.Flags&TraceFlagSampled != 0
+}
+
+// IsZero returns whether tp is the zero TraceParent, which is invalid.
+func (tp TraceParent) IsZero() bool {
+	return tp == TraceParent{}
+}
+
+// String returns tp formatted as a traceparent header field value.
+func (tp TraceParent) String() string {
+	var out strings.Builder
+	out.Grow(55)
+	out.WriteString(hex.EncodeToString([]byte{tp.Version}))
+	out.WriteByte('-')
+	out.WriteString(hex.EncodeToString(tp.TraceID[:]))
+	out.WriteByte('-')
+	out.WriteString(hex.EncodeToString(tp.ParentID[:]))
+	out.WriteByte('-')
+	out.WriteString(hex.EncodeToString([]byte{tp.Flags}))
+	return out.String()
+}
+
+// ParseTraceParent parses a traceparent header field value.
+//
+// Values of a later version than 00 are parsed as per the version 00
+// format, ignoring the fields that follow, as the recommendation requires.
+// The invalid version ff, and all-zero trace and parent identifiers are
+// rejected.
+func ParseTraceParent(s string) (TraceParent, error) {
+	var tp TraceParent
+	var version, flags [1]byte
+	if len(s) < 55 || s[2] != '-' || s[35] != '-' || s[52] != '-' {
+		return tp, errors.New("parsing traceparent: malformed value")
+	}
+	if err := decodeLowerHex(version[:], s[0:2]); err != nil {
+		return tp, fmt.Errorf("parsing traceparent: version: %w", err)
+	}
+	tp.Version = version[0]
+	switch {
+	case tp.Version == 0xff:
+		return tp, errors.New("parsing traceparent: invalid version ff")
+	case tp.Version == 0 && len(s) != 55:
+		return tp, errors.New("parsing traceparent: trailing data in version 00")
+	case len(s) > 55 && s[55] != '-':
+		return tp, errors.New("parsing traceparent: malformed value")
+	}
+	if err := decodeLowerHex(tp.TraceID[:], s[3:35]); err != nil {
+		return tp, fmt.Errorf("parsing traceparent: trace-id: %w", err)
+	}
+	if err := decodeLowerHex(tp.ParentID[:], s[36:52]); err != nil {
+		return tp, fmt.Errorf("parsing traceparent: parent-id: %w", err)
+	}
+	if err := decodeLowerHex(flags[:], s[53:55]); err != nil {
+		return tp, fmt.Errorf("parsing traceparent: trace-flags: %w", err)
+	}
+	tp.Flags = flags[0]
+	if tp.TraceID == ([16]byte{}) {
+		return tp, errors.New("parsing traceparent: all-zero trace-id")
+	}
+	if tp.ParentID == ([8]byte{}) {
+		return tp, errors.New("parsing traceparent: all-zero parent-id")
+	}
+	return tp, nil
+}
+
+// decodeLowerHex decodes the lowercase hex digits of s into dst, which is
+// exactly large enough to hold them.
+func decodeLowerHex(dst []byte, s string) error {
+	for i := 0; i < len(s); i++ {
+		if !isDigit(s[i]) && (s[i] < 'a' || s[i] > 'f') {
+			return fmt.Errorf("invalid character %q", s[i])
+		}
+	}
+	_, err := hex.Decode(dst, []byte(s))
+	return err
+}
+
+func randomID(id []byte) {
+	if _, err := rand.Read(id); err != nil {
+		panic(err)
+	}
+}
+
+type traceContextKey struct{}
+
+type traceContext struct {
+	parent TraceParent
+	state  string
+}
+
+// TraceContext returns a middleware that places the requests served by
+// next in a distributed trace, as per the W3C Trace Context recommendation,
+// and makes their traceparent available through TraceParentFromContext.
+//
+// Requests carrying a valid traceparent header field get a child span of
+// the caller's trace, and keep their tracestate, available through
+// TraceStateFromContext; other requests start a new, unsampled trace. The
+// resulting traceparent is echoed in the traceresponse header field of the
+// response, and its trace identifier is added to the logger of the request
+// context as "trace_id".
+func TraceContext(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+		var tc traceContext
+		if parent, err := ParseTraceParent(req.Header.Get("Traceparent")); err == nil {
+			tc.parent = parent.Child()
+			tc.state = strings.Join(req.Header.Values("Tracestate"), ",")
+		} else {
+			tc.parent = NewTraceParent(false)
+		}
+		w.Header().Set("Traceresponse", tc.parent.String())
+
+		ctx := context.WithValue(req.Context(), traceContextKey{}, tc)
+		ctx = ContextWithLogger(ctx, ContextLogger(ctx).With("trace_id", hex.EncodeToString(tc.parent.TraceID[:])))
+		next.ServeHTTP(w, req.WithContext(ctx))
+	})
+}
+
+// TraceParentFromContext returns the traceparent assigned by a TraceContext
+// middleware to the request that ctx belongs to. Its ParentID identifies
+// the span of the request, and is the parent of outbound requests.
+func TraceParentFromContext(ctx context.Context) (TraceParent, bool) {
+	tc, ok := ctx.Value(traceContextKey{}).(traceContext)
+	return tc.parent, ok
+}
+
+// TraceStateFromContext returns the tracestate that the request that ctx
+// belongs to carried along a valid traceparent, if any.
+func TraceStateFromContext(ctx context.Context) string {
+	tc, _ := ctx.Value(traceContextKey{}).(traceContext)
+	return tc.state
+}
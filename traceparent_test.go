@@ -0,0 +1,155 @@
+// Copyright 2026 Franklin "Snaipe" Mathieu.
+//
+// Use of this source code is governed by the MIT license that can be
+// found in the LICENSE file.
+
+package htutil
+
+import (
+	"bytes"
+	"encoding/hex"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+func TestParseTraceParent(t *testing.T) {
+	t.Parallel()
+
+	tcases := []struct {
+		Value   string
+		Version byte
+		TraceID string
+		Parent  string
+		Sampled bool
+		Err     bool
+	}{
+		{Value: "00-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-01", TraceID: "4bf92f3577b34da6a3ce929d0e0e4736", Parent: "00f067aa0ba902b7", Sampled: true},
+		{Value: "00-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-00", TraceID: "4bf92f3577b34da6a3ce929d0e0e4736", Parent: "00f067aa0ba902b7"},
+		{Value: "cc-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-09-extra", Version: 0xcc, TraceID: "4bf92f3577b34da6a3ce929d0e0e4736", Parent: "00f067aa0ba902b7", Sampled: true},
+		{Value: "cc-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-01", Version: 0xcc, TraceID: "4bf92f3577b34da6a3ce929d0e0e4736", Parent: "00f067aa0ba902b7", Sampled: true},
+		{Value: "cc-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-01extra", Err: true},
+		{Value: "00-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-01-extra", Err: true},
+		{Value: "ff-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-01", Err: true},
+		{Value: "00-4BF92F3577B34DA6A3CE929D0E0E4736-00f067aa0ba902b7-01", Err: true},
+		{Value: "00-00000000000000000000000000000000-00f067aa0ba902b7-01", Err: true},
+		{Value: "00-4bf92f3577b34da6a3ce929d0e0e4736-0000000000000000-01", Err: true},
+		{Value: "00-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-0g", Err: true},
+		{Value: "0-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-01", Err: true},
+		{Value: "00_4bf92f3577b34da6a3ce929d0e0e4736_00f067aa0ba902b7_01", Err: true},
+		{Value: "", Err: true},
+	}
+
+	for i, tcase := range tcases {
+		t.Run(fmt.Sprintf("%d", i), func(t *testing.T) {
+			tp, err := ParseTraceParent(tcase.Value)
+			if (err != nil) != tcase.Err {
+				t.Fatalf("expected error %v, got %v", tcase.Err, err)
+			}
+			if err != nil {
+				return
+			}
+			if tp.Version != tcase.Version {
+				t.Fatalf("expected version %02x, got %02x", tcase.Version, tp.Version)
+			}
+			if id := hex.EncodeToString(tp.TraceID[:]); id != tcase.TraceID {
+				t.Fatalf("expected trace-id %v, got %v", tcase.TraceID, id)
+			}
+			if id := hex.EncodeToString(tp.ParentID[:]); id != tcase.Parent {
+				t.Fatalf("expected parent-id %v, got %v", tcase.Parent, id)
+			}
+			if tp.Sampled() != tcase.Sampled {
+				t.Fatalf("expected sampled %v, got %v", tcase.Sampled, tp.Sampled())
+			}
+			if tp.Version == 0 && tp.String() != tcase.Value {
+				t.Fatalf("expected %v, got %v", tcase.Value, tp.String())
+			}
+		})
+	}
+}
+
+func TestTraceParentChild(t *testing.T) {
+	t.Parallel()
+
+	parent, err := ParseTraceParent("cc-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-01-extra")
+	if err != nil {
+		t.Fatal(err)
+	}
+	child := parent.Child()
+	if child.Version != 0 || child.TraceID != parent.TraceID || child.Flags != parent.Flags {
+		t.Fatalf("expected a version 00 child of the same trace, got %v", child)
+	}
+	if child.ParentID == parent.ParentID {
+		t.Fatalf("expected a new parent-id, got %v", child)
+	}
+	if _, err := ParseTraceParent(child.String()); err != nil {
+		t.Fatal(err)
+	}
+
+	tp := NewTraceParent(true)
+	if !tp.Sampled() || tp.IsZero() {
+		t.Fatalf("expected a sampled trace, got %v", tp)
+	}
+	if _, err := ParseTraceParent(tp.String()); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestTraceContext(t *testing.T) {
+	t.Parallel()
+
+	tcases := []struct {
+		Header  http.Header
+		TraceID string
+		State   string
+	}{
+		{
+			Header:  http.Header{"Traceparent": {"00-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-01"}, "Tracestate": {"a=1", "b=2"}},
+			TraceID: "4bf92f3577b34da6a3ce929d0e0e4736",
+			State:   "a=1,b=2",
+		},
+		{Header: http.Header{"Traceparent": {"00-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7"}, "Tracestate": {"a=1"}}},
+		{Header: http.Header{}},
+	}
+
+	for i, tcase := range tcases {
+		t.Run(fmt.Sprintf("%d", i), func(t *testing.T) {
+			var buf bytes.Buffer
+			var tp TraceParent
+			var state string
+			handler := WithLogger(TraceContext(http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+				tp, _ = TraceParentFromContext(req.Context())
+				state = TraceStateFromContext(req.Context())
+				ContextLogger(req.Context()).Info("hello")
+			})), newTestLogger(&buf))
+
+			req := httptest.NewRequest("GET", "/", nil)
+			for k, vs := range tcase.Header {
+				for _, v := range vs {
+					req.Header.Add(k, v)
+				}
+			}
+			rw := httptest.NewRecorder()
+			handler.ServeHTTP(rw, req)
+
+			traceID := hex.EncodeToString(tp.TraceID[:])
+			if tcase.TraceID != "" && traceID != tcase.TraceID {
+				t.Fatalf("expected trace-id %v, got %v", tcase.TraceID, traceID)
+			}
+			if tcase.TraceID == "" && (tp.IsZero() || tp.Sampled()) {
+				t.Fatalf("expected a new unsampled trace, got %v", tp)
+			}
+			if state != tcase.State {
+				t.Fatalf("expected tracestate %q, got %q", tcase.State, state)
+			}
+			if got := rw.Header().Get("Traceresponse"); got != tp.String() {
+				t.Fatalf("expected traceresponse %v, got %v", tp, got)
+			}
+			if !strings.Contains(buf.String(), "trace_id="+traceID) {
+				t.Fatalf("expected trace-id to be logged, got %q", buf.String())
+			}
+		})
+	}
+}
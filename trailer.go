@@ -0,0 +1,173 @@
+// Copyright 2026 Franklin "Snaipe" Mathieu.
+//
+// Use of this source code is governed by the MIT license that can be
+// found in the LICENSE file.
+
+package htutil
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/textproto"
+	"strings"
+)
+
+// forbiddenTrailers are the fields that must not be sent in trailers, as
+// they are needed to frame, route, authenticate, or process the message,
+// as per RFC 9110 §6.5.1; net/http drops them as well.
+var forbiddenTrailers = map[string]struct{}{
+	"Authorization":       {},
+	"Cache-Control":       {},
+	"Connection":          {},
+	"Content-Encoding":    {},
+	"Content-Length":      {},
+	"Content-Range":       {},
+	"Content-Type":        {},
+	"Expect":              {},
+	"Host":                {},
+	"Keep-Alive":          {},
+	"Max-Forwards":        {},
+	"Pragma":              {},
+	"Proxy-Authenticate":  {},
+	"Proxy-Authorization": {},
+	"Proxy-Connection":    {},
+	"Range":               {},
+	"Realm":               {},
+	"Te":                  {},
+	"Trailer":             {},
+	"Transfer-Encoding":   {},
+	"Www-Authenticate":    {},
+}
+
+// AllowedInTrailer reports whether the field name may be sent in a trailer
+// section.
+func AllowedInTrailer(name string) bool {
+	_, forbidden := forbiddenTrailers[textproto.CanonicalMIMEHeaderKey(name)]
+	return !forbidden && IsToken(name)
+}
+
+// AnnounceTrailer adds names to the Trailer field of the response header
+// h, announcing the trailer fields the response will end with, as per RFC
+// 9110 §6.6.2. It must be called before the response header is written;
+// the values are then set with SetTrailer. Names already announced, and
+// fields that are not allowed in trailers, are skipped.
+func AnnounceTrailer(h http.Header, names ...string) {
+	var announced []string
+	for _, v := range h.Values("Trailer") {
+		announced = append(announced, SplitList(v)...)
+	}
+	var added []string
+	for _, name := range names {
+		if !AllowedInTrailer(name) || containsFold(announced, name) {
+			continue
+		}
+		announced = append(announced, name)
+		added = append(added, textproto.CanonicalMIMEHeaderKey(name))
+	}
+	if len(added) > 0 {
+		h.Add("Trailer", strings.Join(added, ", "))
+	}
+}
+
+// SetTrailer sets the trailer field name of the response whose header is
+// h to value, replacing any previous value.
+//
+// Values of announced trailer fields that are set in h before the response
+// header is written are sent in the header too; SetTrailer uses
+// http.TrailerPrefix instead, so that the value is only ever sent in the
+// trailer section, whether name was announced or not, and whenever it is
+// called. Fields that are not allowed in trailers are ignored.
+func SetTrailer(h http.Header, name, value string) {
+	if !AllowedInTrailer(name) {
+		return
+	}
+	h.Set(http.TrailerPrefix+textproto.CanonicalMIMEHeaderKey(name), value)
+}
+
+// StreamTrailers is a middleware announcing and sending trailer fields
+// summarizing streamed responses, which are only known once the content
+// was sent in full: its Content-Digest, as per RFC 9530, and the time taken
+// to serve it, as a Server-Timing metric.
+//
+// The trailer fields are announced when the response header is written,
+// unless the response has no content, or has a Content-Length, as trailers
+// can only be sent with chunked content in HTTP/1.1. If nothing was
+// written by the handler, they are sent as header fields instead.
+//
+// The Content-Digest is computed like in ContentDigest, which also
+// verifies the digests of requests, and honors Want-Content-Digest. Since
+// it covers the content as sent, after content coding, StreamTrailers must
+// wrap compressing middleware like Compression. The Server-Timing metrics
+// are reported like in ServerTiming with Trailer set.
+type StreamTrailers struct {
+	// Digest are the registered digest algorithms of the Content-Digest
+	// trailer field. No digest is computed if empty, or if the handler
+	// set Content-Digest itself.
+	Digest []string
+
+	// Timing, if set, is the name of a Server-Timing metric recording the
+	// duration of the handler.
+	Timing string
+}
+
+// Middleware returns a middleware sending the trailer fields of the
+// responses served by next.
+func (st *StreamTrailers) Middleware(next http.Handler) http.Handler {
+	streamed := next
+	if len(st.Digest) > 0 {
+		streamed = http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+			serveDigested(w, req, next, st.Digest)
+		})
+	}
+	if st.Timing != "" {
+		streamed = (&ServerTiming{Total: st.Timing, Trailer: true}).Middleware(streamed)
+	}
+	return http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+		if req.Method == http.MethodHead {
+			next.ServeHTTP(w, req)
+			return
+		}
+		streamed.ServeHTTP(w, req)
+	})
+}
+
+// ErrTrailerTooLarge is returned by ReadTrailer if the rest of the content
+// of a response is larger than the limit.
+var ErrTrailerTooLarge = errors.New("content too large to reach the trailer section")
+
+// ReadTrailer reads and discards the rest of the content of resp, up to
+// limit bytes, closes its body, and returns the trailer fields received.
+// A negative limit defaults to DefaultMaxBodySize.
+//
+// The Trailer of a http.Response is only filled once its body was read
+// until EOF, and holds fields that were announced but not received with
+// nil values; the returned header only has the received fields.
+func ReadTrailer(resp *http.Response, limit int64) (http.Header, error) {
+	if limit < 0 {
+		limit = DefaultMaxBodySize
+	}
+	defer resp.Body.Close()
+	n, err := io.Copy(io.Discard, io.LimitReader(resp.Body, limit+1))
+	if err != nil {
+		return nil, fmt.Errorf("reading trailer: %w", err)
+	}
+	if n > limit {
+		return nil, fmt.Errorf("reading trailer: %w", ErrTrailerTooLarge)
+	}
+	return ReceivedTrailer(resp), nil
+}
+
+// ReceivedTrailer returns the trailer fields of resp that were received,
+// without those that were announced but not sent. It must be called once
+// the body of resp was read until EOF.
+func ReceivedTrailer(resp *http.Response) http.Header {
+	trailer := make(http.Header, len(resp.Trailer))
+	for name, values := range resp.Trailer {
+		if len(values) > 0 {
+			trailer[name] = values
+		}
+	}
+	return trailer
+}
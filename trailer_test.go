@@ -0,0 +1,213 @@
+// Copyright 2026 Franklin "Snaipe" Mathieu.
+//
+// Use of this source code is governed by the MIT license that can be
+// found in the LICENSE file.
+
+package htutil
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"sort"
+	"strings"
+	"testing"
+)
+
+func TestAnnounceTrailer(t *testing.T) {
+	t.Parallel()
+
+	tcases := []struct {
+		Trailer []string
+		Names   []string
+		Expect  []string
+	}{
+		{Names: []string{"content-digest"}, Expect: []string{"Content-Digest"}},
+		{Names: []string{"Server-Timing", "Content-Length", "Host", "bad name"}, Expect: []string{"Server-Timing"}},
+		{Trailer: []string{"ETag"}, Names: []string{"etag", "Server-Timing"}, Expect: []string{"ETag", "Server-Timing"}},
+		{Trailer: []string{"ETag"}, Names: []string{"Transfer-Encoding"}, Expect: []string{"ETag"}},
+		{Names: nil, Expect: nil},
+	}
+
+	for i, tcase := range tcases {
+		t.Run(fmt.Sprintf("%d", i), func(t *testing.T) {
+			h := http.Header{}
+			for _, v := range tcase.Trailer {
+				h.Add("Trailer", v)
+			}
+			AnnounceTrailer(h, tcase.Names...)
+			if got := h.Values("Trailer"); fmt.Sprint(got) != fmt.Sprint(tcase.Expect) {
+				t.Fatalf("expected %q, got %q", tcase.Expect, got)
+			}
+		})
+	}
+}
+
+func TestSetTrailer(t *testing.T) {
+	t.Parallel()
+
+	handler := http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+		AnnounceTrailer(w.Header(), "X-Checksum")
+		// Set before the header is written: it must not leak into it.
+		SetTrailer(w.Header(), "x-checksum", "early")
+		SetTrailer(w.Header(), "Content-Length", "42")
+		io.WriteString(w, "hello")
+		SetTrailer(w.Header(), "X-Checksum", "abc")
+		SetTrailer(w.Header(), "X-Unannounced", "def")
+	})
+
+	srv := httptest.NewServer(handler)
+	defer srv.Close()
+
+	resp, err := srv.Client().Get(srv.URL)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := resp.Header.Get("X-Checksum"); got != "" {
+		t.Fatalf("expected no X-Checksum header field, got %q", got)
+	}
+	trailer, err := ReadTrailer(resp, -1)
+	if err != nil {
+		t.Fatal(err)
+	}
+	expected := http.Header{"X-Checksum": {"abc"}, "X-Unannounced": {"def"}}
+	if fmt.Sprint(trailer) != fmt.Sprint(expected) {
+		t.Fatalf("expected trailer %v, got %v", expected, trailer)
+	}
+}
+
+func TestStreamTrailers(t *testing.T) {
+	t.Parallel()
+
+	st := StreamTrailers{Digest: []string{"sha-256"}, Timing: "total"}
+
+	tcases := []struct {
+		Method   string
+		Handler  http.HandlerFunc
+		Header   []string
+		Trailer  []string
+		Announce []string
+	}{
+		{
+			Method: "GET",
+			Handler: func(w http.ResponseWriter, req *http.Request) {
+				io.WriteString(w, "hello, ")
+				http.NewResponseController(w).Flush()
+				io.WriteString(w, "world")
+			},
+			Trailer:  []string{"Content-Digest", "Server-Timing"},
+			Announce: []string{"Content-Digest", "Server-Timing"},
+		},
+		{
+			Method: "GET",
+			Handler: func(w http.ResponseWriter, req *http.Request) {
+				w.Header().Set("Content-Digest", "sha-256=:AAAA:")
+				io.WriteString(w, "hello, world")
+			},
+			Header:   []string{"Content-Digest"},
+			Trailer:  []string{"Server-Timing"},
+			Announce: []string{"Server-Timing"},
+		},
+		{
+			Method: "GET",
+			Handler: func(w http.ResponseWriter, req *http.Request) {
+				w.Header().Set("Content-Length", "12")
+				io.WriteString(w, "hello, world")
+			},
+		},
+		{
+			Method:  "GET",
+			Handler: func(w http.ResponseWriter, req *http.Request) {},
+			Header:  []string{"Content-Digest", "Server-Timing"},
+		},
+		{
+			Method: "GET",
+			Handler: func(w http.ResponseWriter, req *http.Request) {
+				w.WriteHeader(http.StatusNoContent)
+			},
+		},
+		{
+			Method: "HEAD",
+			Handler: func(w http.ResponseWriter, req *http.Request) {
+				io.WriteString(w, "hello, world")
+			},
+		},
+	}
+
+	for i, tcase := range tcases {
+		t.Run(fmt.Sprintf("%d", i), func(t *testing.T) {
+			srv := httptest.NewServer(st.Middleware(tcase.Handler))
+			defer srv.Close()
+
+			req, _ := http.NewRequest(tcase.Method, srv.URL, nil)
+			resp, err := srv.Client().Do(req)
+			if err != nil {
+				t.Fatal(err)
+			}
+			// The client moves the announced fields to resp.Trailer.
+			var announced []string
+			for name := range resp.Trailer {
+				announced = append(announced, name)
+			}
+			sort.Strings(announced)
+			if fmt.Sprint(announced) != fmt.Sprint(tcase.Announce) {
+				t.Fatalf("expected announced trailer fields %q, got %q", tcase.Announce, announced)
+			}
+			trailer, err := ReadTrailer(resp, -1)
+			if err != nil {
+				t.Fatal(err)
+			}
+			for _, name := range []string{"Content-Digest", "Server-Timing"} {
+				inHeader := containsString(tcase.Header, name)
+				if (resp.Header.Get(name) != "") != inHeader {
+					t.Fatalf("expected %s in header=%v, got %q", name, inHeader, resp.Header.Get(name))
+				}
+				inTrailer := containsString(tcase.Trailer, name)
+				if (trailer.Get(name) != "") != inTrailer {
+					t.Fatalf("expected %s in trailer=%v, got %v", name, inTrailer, trailer)
+				}
+			}
+			if containsString(tcase.Trailer, "Content-Digest") {
+				expected := "sha-256=:Ccp+TqpuiunH0mEWcSkYSINkTQffuny/vEyKLgg2DVs=:"
+				if got := trailer.Get("Content-Digest"); got != expected {
+					t.Fatalf("expected Content-Digest %q, got %q", expected, got)
+				}
+			}
+			if got := trailer.Get("Server-Timing"); got != "" && !strings.HasPrefix(got, "total;dur=") {
+				t.Fatalf("expected a total metric, got %q", got)
+			}
+		})
+	}
+
+	// Clients do not look for the trailer of HEAD responses.
+	rw := httptest.NewRecorder()
+	st.Middleware(http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+		io.WriteString(w, "hello, world")
+	})).ServeHTTP(rw, httptest.NewRequest("HEAD", "/", nil))
+	if announced := rw.Header().Values("Trailer"); len(announced) > 0 {
+		t.Fatalf("expected no trailer fields announced for HEAD, got %q", announced)
+	}
+}
+
+func TestReadTrailer(t *testing.T) {
+	t.Parallel()
+
+	resp := &http.Response{
+		Body:    io.NopCloser(strings.NewReader("hello, world")),
+		Trailer: http.Header{"Etag": {`"v1"`}, "Content-Digest": nil},
+	}
+	if _, err := ReadTrailer(resp, 4); !errors.Is(err, ErrTrailerTooLarge) {
+		t.Fatalf("expected ErrTrailerTooLarge, got %v", err)
+	}
+
+	resp.Body = io.NopCloser(strings.NewReader("hello, world"))
+	trailer, err := ReadTrailer(resp, 12)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if expected := (http.Header{"Etag": {`"v1"`}}); fmt.Sprint(trailer) != fmt.Sprint(expected) {
+		t.Fatalf("expected %v, got %v", expected, trailer)
+	}
+}
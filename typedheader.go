@@ -0,0 +1,176 @@
+// Copyright 2026 Franklin "Snaipe" Mathieu.
+//
+// Use of this source code is governed by the MIT license that can be
+// found in the LICENSE file.
+
+package htutil
+
+import (
+	"fmt"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Header is a typed view of a http.Header, parsing and formatting the
+// values of common header fields as per RFC 9110:
+//
+//	length, err := htutil.Header(resp.Header).ContentLength()
+//
+// Getters return ErrNoHeader if the field is absent, and an error if it is
+// invalid, or repeated with different values, rather than guessing.
+type Header http.Header
+
+// single returns the value of the singleton field name, or an error if it
+// is absent, or has conflicting values.
+func (h Header) single(name string) (string, error) {
+	values := http.Header(h).Values(name)
+	switch {
+	case len(values) == 0:
+		return "", ErrNoHeader
+	case len(values) > 1:
+		for _, v := range values[1:] {
+			if v != values[0] {
+				return "", fmt.Errorf("parsing %s: conflicting values", strings.ToLower(name))
+			}
+		}
+	}
+	return trimOWS(values[0]), nil
+}
+
+// date returns the HTTP-date value of the field name.
+func (h Header) date(name string) (time.Time, error) {
+	value, err := h.single(name)
+	if err != nil {
+		return time.Time{}, err
+	}
+	t, err := http.ParseTime(value)
+	if err != nil {
+		return time.Time{}, fmt.Errorf("parsing %s: %w", strings.ToLower(name), err)
+	}
+	return t, nil
+}
+
+func (h Header) setDate(name string, t time.Time) {
+	http.Header(h).Set(name, t.UTC().Format(http.TimeFormat))
+}
+
+// ContentLength returns the value of the Content-Length field. As per RFC
+// 9110 §8.6, a list of identical values is accepted as that value.
+func (h Header) ContentLength() (int64, error) {
+	values := http.Header(h).Values("Content-Length")
+	if len(values) == 0 {
+		return 0, ErrNoHeader
+	}
+	var length string
+	for _, v := range values {
+		for _, elem := range SplitList(v) {
+			if length != "" && elem != length {
+				return 0, fmt.Errorf("parsing content-length: conflicting values %s and %s", length, elem)
+			}
+			length = elem
+		}
+	}
+	for i := 0; i < len(length); i++ {
+		if !isDigit(length[i]) {
+			return 0, fmt.Errorf("parsing content-length: %q is not a number", length)
+		}
+	}
+	n, err := strconv.ParseInt(length, 10, 64)
+	if err != nil {
+		return 0, fmt.Errorf("parsing content-length: %w", err)
+	}
+	return n, nil
+}
+
+// SetContentLength sets the Content-Length field to n.
+func (h Header) SetContentLength(n int64) {
+	http.Header(h).Set("Content-Length", strconv.FormatInt(n, 10))
+}
+
+// ContentType returns the media type of the Content-Type field.
+func (h Header) ContentType() (MediaType, error) {
+	value, err := h.single("Content-Type")
+	if err != nil {
+		return MediaType{}, err
+	}
+	mt, err := ParseMediaType(value)
+	if err != nil {
+		return MediaType{}, fmt.Errorf("parsing content-type: %w", err)
+	}
+	if mt.Type == "*" || mt.Subtype == "*" {
+		return MediaType{}, fmt.Errorf("parsing content-type: %s is a media range", mt.Essence())
+	}
+	return mt, nil
+}
+
+// SetContentType sets the Content-Type field to mt.
+func (h Header) SetContentType(mt MediaType) {
+	http.Header(h).Set("Content-Type", mt.String())
+}
+
+// Date returns the value of the Date field.
+func (h Header) Date() (time.Time, error) {
+	return h.date("Date")
+}
+
+// SetDate sets the Date field to t.
+func (h Header) SetDate(t time.Time) {
+	h.setDate("Date", t)
+}
+
+// LastModified returns the value of the Last-Modified field.
+func (h Header) LastModified() (time.Time, error) {
+	return h.date("Last-Modified")
+}
+
+// SetLastModified sets the Last-Modified field to t.
+func (h Header) SetLastModified(t time.Time) {
+	h.setDate("Last-Modified", t)
+}
+
+// Expires returns the value of the Expires field. Caches must consider
+// invalid values as dates in the past, as per RFC 9111 §5.3.
+func (h Header) Expires() (time.Time, error) {
+	return h.date("Expires")
+}
+
+// SetExpires sets the Expires field to t.
+func (h Header) SetExpires(t time.Time) {
+	h.setDate("Expires", t)
+}
+
+// ETag returns the entity tag of the ETag field.
+func (h Header) ETag() (ETag, error) {
+	value, err := h.single("ETag")
+	if err != nil {
+		return ETag{}, err
+	}
+	return ParseETag(value)
+}
+
+// SetETag sets the ETag field to etag.
+func (h Header) SetETag(etag ETag) {
+	http.Header(h).Set("ETag", etag.String())
+}
+
+// RetryAfter returns the delay requested by the Retry-After field at now;
+// see ParseRetryAfter.
+func (h Header) RetryAfter(now time.Time) (time.Duration, error) {
+	value, err := h.single("Retry-After")
+	if err != nil {
+		return 0, err
+	}
+	return ParseRetryAfter(value, now)
+}
+
+// SetRetryAfter sets the Retry-After field to the delay d, in seconds,
+// rounded up. Negative delays are sent as 0.
+func (h Header) SetRetryAfter(d time.Duration) {
+	if d < 0 {
+		d = 0
+	}
+	secs := int64((d + time.Second - 1) / time.Second)
+	http.Header(h).Set("Retry-After", strconv.FormatInt(secs, 10))
+}
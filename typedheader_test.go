@@ -0,0 +1,201 @@
+// Copyright 2026 Franklin "Snaipe" Mathieu.
+//
+// Use of this source code is governed by the MIT license that can be
+// found in the LICENSE file.
+
+package htutil
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"testing"
+	"time"
+)
+
+func TestHeaderContentLength(t *testing.T) {
+	t.Parallel()
+
+	tcases := []struct {
+		Values []string
+		Expect int64
+		Err    error
+		Bad    bool
+	}{
+		{Values: []string{"42"}, Expect: 42},
+		{Values: []string{"42", "42"}, Expect: 42},
+		{Values: []string{"42, 42"}, Expect: 42},
+		{Values: []string{"0"}, Expect: 0},
+		{Values: nil, Err: ErrNoHeader},
+		{Values: []string{"42", "43"}, Bad: true},
+		{Values: []string{"+42"}, Bad: true},
+		{Values: []string{"-1"}, Bad: true},
+		{Values: []string{"4 2"}, Bad: true},
+		{Values: []string{""}, Bad: true},
+		{Values: []string{"99999999999999999999"}, Bad: true},
+	}
+
+	for i, tcase := range tcases {
+		t.Run(fmt.Sprintf("%d", i), func(t *testing.T) {
+			h := http.Header{}
+			for _, v := range tcase.Values {
+				h.Add("Content-Length", v)
+			}
+			n, err := Header(h).ContentLength()
+			if tcase.Err != nil && !errors.Is(err, tcase.Err) {
+				t.Fatalf("expected error %v, got %v", tcase.Err, err)
+			}
+			if tcase.Err == nil && (err != nil) != tcase.Bad {
+				t.Fatalf("expected error=%v, got %v", tcase.Bad, err)
+			}
+			if n != tcase.Expect {
+				t.Fatalf("expected %d, got %d", tcase.Expect, n)
+			}
+		})
+	}
+}
+
+func TestHeaderDates(t *testing.T) {
+	t.Parallel()
+
+	date := time.Date(2026, 1, 2, 3, 4, 5, 0, time.UTC)
+
+	tcases := []struct {
+		Values []string
+		Expect time.Time
+		Err    bool
+	}{
+		{Values: []string{"Fri, 02 Jan 2026 03:04:05 GMT"}, Expect: date},
+		{Values: []string{"Friday, 02-Jan-26 03:04:05 GMT"}, Expect: date},
+		{Values: []string{"Fri Jan  2 03:04:05 2026"}, Expect: date},
+		{Values: []string{"Fri, 02 Jan 2026 03:04:05 GMT", "Fri, 02 Jan 2026 03:04:05 GMT"}, Expect: date},
+		{Values: []string{"Fri, 02 Jan 2026 03:04:05 GMT", "Sat, 03 Jan 2026 03:04:05 GMT"}, Err: true},
+		{Values: []string{"2026-01-02T03:04:05Z"}, Err: true},
+		{Values: []string{"0"}, Err: true},
+	}
+
+	for i, tcase := range tcases {
+		t.Run(fmt.Sprintf("%d", i), func(t *testing.T) {
+			h := http.Header{}
+			for _, v := range tcase.Values {
+				h.Add("Date", v)
+				h.Add("Last-Modified", v)
+				h.Add("Expires", v)
+			}
+			for name, get := range map[string]func() (time.Time, error){
+				"Date":          Header(h).Date,
+				"Last-Modified": Header(h).LastModified,
+				"Expires":       Header(h).Expires,
+			} {
+				got, err := get()
+				if (err != nil) != tcase.Err {
+					t.Fatalf("%s: expected error=%v, got %v", name, tcase.Err, err)
+				}
+				if !got.Equal(tcase.Expect) {
+					t.Fatalf("%s: expected %v, got %v", name, tcase.Expect, got)
+				}
+			}
+		})
+	}
+
+	h := Header{}
+	h.SetDate(date.In(time.FixedZone("CET", 3600)))
+	h.SetLastModified(date)
+	h.SetExpires(date)
+	for _, name := range []string{"Date", "Last-Modified", "Expires"} {
+		if got := http.Header(h).Get(name); got != "Fri, 02 Jan 2026 03:04:05 GMT" {
+			t.Fatalf("expected %s to be formatted as an IMF-fixdate, got %q", name, got)
+		}
+	}
+	if _, err := (Header{}).Date(); !errors.Is(err, ErrNoHeader) {
+		t.Fatalf("expected ErrNoHeader, got %v", err)
+	}
+}
+
+func TestHeaderContentType(t *testing.T) {
+	t.Parallel()
+
+	tcases := []struct {
+		Value  string
+		Expect string
+		Err    bool
+	}{
+		{Value: "text/html; charset=UTF-8", Expect: "text/html;charset=UTF-8"},
+		{Value: "Application/JSON", Expect: "application/json"},
+		{Value: "text/*", Err: true},
+		{Value: "text", Err: true},
+		{Value: "text/plain; charset", Err: true},
+	}
+
+	for i, tcase := range tcases {
+		t.Run(fmt.Sprintf("%d", i), func(t *testing.T) {
+			h := Header{"Content-Type": {tcase.Value}}
+			mt, err := h.ContentType()
+			if (err != nil) != tcase.Err {
+				t.Fatalf("expected error=%v, got %v", tcase.Err, err)
+			}
+			if err != nil {
+				return
+			}
+			if mt.String() != tcase.Expect {
+				t.Fatalf("expected %s, got %s", tcase.Expect, mt)
+			}
+			h.SetContentType(mt)
+			if got := http.Header(h).Get("Content-Type"); got != tcase.Expect {
+				t.Fatalf("expected %s, got %s", tcase.Expect, got)
+			}
+		})
+	}
+}
+
+func TestHeaderRetryAfter(t *testing.T) {
+	t.Parallel()
+
+	now := time.Date(2026, 1, 2, 3, 4, 5, 0, time.UTC)
+
+	tcases := []struct {
+		Set    time.Duration
+		Value  string
+		Expect time.Duration
+	}{
+		{Set: 30 * time.Second, Value: "30", Expect: 30 * time.Second},
+		{Set: 1500 * time.Millisecond, Value: "2", Expect: 2 * time.Second},
+		{Set: -time.Second, Value: "0", Expect: 0},
+	}
+
+	for i, tcase := range tcases {
+		t.Run(fmt.Sprintf("%d", i), func(t *testing.T) {
+			h := Header{}
+			h.SetRetryAfter(tcase.Set)
+			if got := http.Header(h).Get("Retry-After"); got != tcase.Value {
+				t.Fatalf("expected %q, got %q", tcase.Value, got)
+			}
+			d, err := h.RetryAfter(now)
+			if err != nil || d != tcase.Expect {
+				t.Fatalf("expected %v, got %v (%v)", tcase.Expect, d, err)
+			}
+		})
+	}
+
+	h := Header{"Retry-After": {"Fri, 02 Jan 2026 03:05:05 GMT"}}
+	if d, err := h.RetryAfter(now); err != nil || d != time.Minute {
+		t.Fatalf("expected %v, got %v (%v)", time.Minute, d, err)
+	}
+}
+
+func TestHeaderETag(t *testing.T) {
+	t.Parallel()
+
+	h := Header{}
+	if _, err := h.ETag(); !errors.Is(err, ErrNoHeader) {
+		t.Fatalf("expected ErrNoHeader, got %v", err)
+	}
+	h.SetETag(ETag{Tag: "v1", Weak: true})
+	if etag, err := h.ETag(); err != nil || etag != (ETag{Tag: "v1", Weak: true}) {
+		t.Fatalf("expected W/\"v1\", got %v (%v)", etag, err)
+	}
+	http.Header(h).Set("ETag", "v1")
+	if _, err := h.ETag(); err == nil {
+		t.Fatalf("expected an unquoted entity tag to be rejected")
+	}
+}
@@ -0,0 +1,343 @@
+// Copyright 2026 Franklin "Snaipe" Mathieu.
+//
+// Use of this source code is governed by the MIT license that can be
+// found in the LICENSE file.
+
+package htutil
+
+import (
+	"fmt"
+	"reflect"
+	"sort"
+	"strconv"
+	"strings"
+	"unicode/utf8"
+)
+
+// URITemplate is a URI Template, as per RFC 6570, supporting expressions of
+// all four levels.
+type URITemplate struct {
+	raw   string
+	parts []templatePart
+}
+
+// templatePart is either a literal, or an expression if vars is not empty.
+type templatePart struct {
+	literal string
+	op      templateOp
+	vars    []templateVar
+}
+
+type templateVar struct {
+	name    string
+	explode bool
+	prefix  int
+}
+
+// templateOp describes the expansion of an expression operator, as per
+// RFC 6570 Appendix A.
+type templateOp struct {
+	first         string
+	sep           string
+	named         bool
+	ifEmpty       string
+	allowReserved bool
+}
+
+var templateOps = map[byte]templateOp{
+	0:   {first: "", sep: ","},
+	'+': {first: "", sep: ",", allowReserved: true},
+	'.': {first: ".", sep: "."},
+	'/': {first: "/", sep: "/"},
+	';': {first: ";", sep: ";", named: true},
+	'?': {first: "?", sep: "&", named: true, ifEmpty: "="},
+	'&': {first: "&", sep: "&", named: true, ifEmpty: "="},
+	'#': {first: "#", sep: ",", allowReserved: true},
+}
+
+// ParseURITemplate parses the URI Template s.
+func ParseURITemplate(s string) (*URITemplate, error) {
+	t := &URITemplate{raw: s}
+	for rest := s; rest != ""; {
+		start := strings.IndexAny(rest, "{}")
+		if start == -1 {
+			t.parts = append(t.parts, templatePart{literal: rest})
+			break
+		}
+		if rest[start] == '}' {
+			return nil, fmt.Errorf("parsing uri template: unexpected '}' at offset %d", len(s)-len(rest)+start)
+		}
+		if start > 0 {
+			t.parts = append(t.parts, templatePart{literal: rest[:start]})
+		}
+		end := strings.IndexByte(rest[start:], '}')
+		if end == -1 {
+			return nil, fmt.Errorf("parsing uri template: unterminated expression at offset %d", len(s)-len(rest)+start)
+		}
+		part, err := parseTemplateExpr(rest[start+1 : start+end])
+		if err != nil {
+			return nil, fmt.Errorf("parsing uri template: %w", err)
+		}
+		t.parts = append(t.parts, part)
+		rest = rest[start+end+1:]
+	}
+	return t, nil
+}
+
+// MustParseURITemplate is like ParseURITemplate, but panics if s cannot be
+// parsed.
+func MustParseURITemplate(s string) *URITemplate {
+	t, err := ParseURITemplate(s)
+	if err != nil {
+		panic(err)
+	}
+	return t
+}
+
+func parseTemplateExpr(expr string) (templatePart, error) {
+	var opc byte
+	if expr != "" && strings.IndexByte("+#./;?&=,!@|", expr[0]) != -1 {
+		opc, expr = expr[0], expr[1:]
+	}
+	op, ok := templateOps[opc]
+	if !ok {
+		return templatePart{}, fmt.Errorf("reserved operator %q", opc)
+	}
+	part := templatePart{op: op}
+	for _, spec := range strings.Split(expr, ",") {
+		v := templateVar{name: spec}
+		if name, ok := strings.CutSuffix(spec, "*"); ok {
+			v.name, v.explode = name, true
+		} else if name, prefix, ok := strings.Cut(spec, ":"); ok {
+			n, err := strconv.Atoi(prefix)
+			if err != nil || n <= 0 || n >= 10000 || prefix[0] == '0' {
+				return templatePart{}, fmt.Errorf("invalid prefix modifier %q", spec)
+			}
+			v.name, v.prefix = name, n
+		}
+		if !isTemplateVarname(v.name) {
+			return templatePart{}, fmt.Errorf("invalid variable name %q", v.name)
+		}
+		part.vars = append(part.vars, v)
+	}
+	return part, nil
+}
+
+// isTemplateVarname returns whether s is a valid varname, as per
+// RFC 6570 §2.3.
+func isTemplateVarname(s string) bool {
+	if s == "" || s[0] == '.' || s[len(s)-1] == '.' || strings.Contains(s, "..") {
+		return false
+	}
+	for i := 0; i < len(s); i++ {
+		switch c := s[i]; {
+		case isAlpha(c), isDigit(c), c == '_', c == '.':
+		case c == '%' && i+2 < len(s) && isHexDigit(s[i+1]) && isHexDigit(s[i+2]):
+			i += 2
+		default:
+			return false
+		}
+	}
+	return true
+}
+
+func isHexDigit(c byte) bool {
+	return isDigit(c) || (c >= 'a' && c <= 'f') || (c >= 'A' && c <= 'F')
+}
+
+// String returns the template as it was parsed.
+func (t *URITemplate) String() string {
+	return t.raw
+}
+
+// Variables returns the names of the variables of the template, in order of
+// first appearance.
+func (t *URITemplate) Variables() []string {
+	var names []string
+	for _, part := range t.parts {
+		for _, v := range part.vars {
+			if !containsString(names, v.name) {
+				names = append(names, v.name)
+			}
+		}
+	}
+	return names
+}
+
+// Expand returns the URI reference obtained by expanding the template with
+// the values of vars, as per RFC 6570 §3.
+//
+// Strings, and other values formatted with fmt.Sprint, are simple values.
+// Slices and arrays are lists, and maps are associative arrays, whose keys
+// are expanded in sorted order. Missing and nil values, and empty lists
+// and associative arrays, are undefined, and omitted from the expansion.
+func (t *URITemplate) Expand(vars map[string]any) string {
+	var out strings.Builder
+	for _, part := range t.parts {
+		if part.vars == nil {
+			out.WriteString(encodeTemplate(part.literal, true))
+			continue
+		}
+		first := true
+		for _, v := range part.vars {
+			value, ok := templateValueOf(vars[v.name])
+			if !ok {
+				continue
+			}
+			if first {
+				out.WriteString(part.op.first)
+				first = false
+			} else {
+				out.WriteString(part.op.sep)
+			}
+			expandTemplateVar(&out, part.op, v, value)
+		}
+	}
+	return out.String()
+}
+
+// templateValue is a defined variable value: a string, a list, or an
+// associative array of keys to values.
+type templateValue struct {
+	str   *string
+	list  []string
+	keys  []string
+	assoc map[string]string
+}
+
+func templateValueOf(v any) (templateValue, bool) {
+	if v == nil {
+		return templateValue{}, false
+	}
+	if s, ok := v.(string); ok {
+		return templateValue{str: &s}, true
+	}
+	rv := reflect.ValueOf(v)
+	switch rv.Kind() {
+	case reflect.Pointer:
+		if rv.IsNil() {
+			return templateValue{}, false
+		}
+		if _, ok := v.(fmt.Stringer); !ok {
+			return templateValueOf(rv.Elem().Interface())
+		}
+	case reflect.Slice, reflect.Array:
+		if rv.Len() == 0 {
+			return templateValue{}, false
+		}
+		list := make([]string, rv.Len())
+		for i := range list {
+			list[i] = fmt.Sprint(rv.Index(i).Interface())
+		}
+		return templateValue{list: list}, true
+	case reflect.Map:
+		if rv.Len() == 0 {
+			return templateValue{}, false
+		}
+		val := templateValue{assoc: make(map[string]string, rv.Len())}
+		for it := rv.MapRange(); it.Next(); {
+			key := fmt.Sprint(it.Key().Interface())
+			val.keys = append(val.keys, key)
+			val.assoc[key] = fmt.Sprint(it.Value().Interface())
+		}
+		sort.Strings(val.keys)
+		return val, true
+	}
+	s := fmt.Sprint(v)
+	return templateValue{str: &s}, true
+}
+
+func expandTemplateVar(out *strings.Builder, op templateOp, v templateVar, value templateValue) {
+	encode := func(s string) string {
+		return encodeTemplate(s, op.allowReserved)
+	}
+	named := func(name, s string) {
+		out.WriteString(encode(name))
+		if s == "" {
+			out.WriteString(op.ifEmpty)
+		} else {
+			out.WriteString("=" + encode(s))
+		}
+	}
+
+	switch {
+	case value.str != nil:
+		s := *value.str
+		if v.prefix > 0 && utf8.RuneCountInString(s) > v.prefix {
+			n := 0
+			for i := range s {
+				if n == v.prefix {
+					s = s[:i]
+					break
+				}
+				n++
+			}
+		}
+		if op.named {
+			named(v.name, s)
+		} else {
+			out.WriteString(encode(s))
+		}
+	case !v.explode:
+		if op.named {
+			out.WriteString(encode(v.name) + "=")
+		}
+		var items []string
+		if value.list != nil {
+			for _, item := range value.list {
+				items = append(items, encode(item))
+			}
+		} else {
+			for _, key := range value.keys {
+				items = append(items, encode(key), encode(value.assoc[key]))
+			}
+		}
+		out.WriteString(strings.Join(items, ","))
+	default:
+		for i, item := range value.list {
+			if i > 0 {
+				out.WriteString(op.sep)
+			}
+			if op.named {
+				named(v.name, item)
+			} else {
+				out.WriteString(encode(item))
+			}
+		}
+		for i, key := range value.keys {
+			if i > 0 {
+				out.WriteString(op.sep)
+			}
+			if op.named {
+				named(key, value.assoc[key])
+			} else {
+				out.WriteString(encode(key) + "=" + encode(value.assoc[key]))
+			}
+		}
+	}
+}
+
+// encodeTemplate percent-encodes the characters of s that are not
+// unreserved, nor reserved or part of a percent-encoded triplet if
+// allowReserved is true.
+func encodeTemplate(s string, allowReserved bool) string {
+	const hex = "0123456789ABCDEF"
+	var out strings.Builder
+	for i := 0; i < len(s); i++ {
+		c := s[i]
+		switch {
+		case isAlpha(c), isDigit(c), strings.IndexByte("-._~", c) != -1:
+			out.WriteByte(c)
+		case allowReserved && strings.IndexByte(":/?#[]@!$&'()*+,;=", c) != -1:
+			out.WriteByte(c)
+		case allowReserved && c == '%' && i+2 < len(s) && isHexDigit(s[i+1]) && isHexDigit(s[i+2]):
+			out.WriteString(s[i : i+3])
+			i += 2
+		default:
+			out.WriteByte('%')
+			out.WriteByte(hex[c>>4])
+			out.WriteByte(hex[c&0xf])
+		}
+	}
+	return out.String()
+}
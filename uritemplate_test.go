@@ -0,0 +1,163 @@
+// Copyright 2026 Franklin "Snaipe" Mathieu.
+//
+// Use of this source code is governed by the MIT license that can be
+// found in the LICENSE file.
+
+package htutil
+
+import (
+	"fmt"
+	"reflect"
+	"testing"
+)
+
+func TestURITemplateExpand(t *testing.T) {
+	t.Parallel()
+
+	// The variables of the examples of RFC 6570. Associative arrays are
+	// expanded in key order, which differs from the examples.
+	vars := map[string]any{
+		"count":      []string{"one", "two", "three"},
+		"dom":        []string{"example", "com"},
+		"dub":        "me/too",
+		"hello":      "Hello World!",
+		"half":       "50%",
+		"var":        "value",
+		"who":        "fred",
+		"base":       "http://example.com/home/",
+		"path":       "/foo/bar",
+		"list":       []string{"red", "green", "blue"},
+		"keys":       map[string]string{"semi": ";", "dot": ".", "comma": ","},
+		"v":          6,
+		"x":          "1024",
+		"y":          768,
+		"empty":      "",
+		"empty_keys": map[string]string{},
+		"undef":      nil,
+		"uni":        "né",
+	}
+
+	tcases := []struct {
+		Template string
+		Expect   string
+	}{
+		{Template: "{var}", Expect: "value"},
+		{Template: "{hello}", Expect: "Hello%20World%21"},
+		{Template: "{half}", Expect: "50%25"},
+		{Template: "{uni}", Expect: "n%C3%A9"},
+		{Template: "O{empty}X", Expect: "OX"},
+		{Template: "O{undef}X", Expect: "OX"},
+		{Template: "{x,y}", Expect: "1024,768"},
+		{Template: "{x,hello,y}", Expect: "1024,Hello%20World%21,768"},
+		{Template: "?{x,empty}", Expect: "?1024,"},
+		{Template: "?{x,undef}", Expect: "?1024"},
+		{Template: "?{undef,y}", Expect: "?768"},
+		{Template: "{var:3}", Expect: "val"},
+		{Template: "{var:30}", Expect: "value"},
+		{Template: "{uni:2}", Expect: "n%C3%A9"},
+		{Template: "{list}", Expect: "red,green,blue"},
+		{Template: "{list*}", Expect: "red,green,blue"},
+		{Template: "{keys}", Expect: "comma,%2C,dot,.,semi,%3B"},
+		{Template: "{keys*}", Expect: "comma=%2C,dot=.,semi=%3B"},
+		{Template: "{+var}", Expect: "value"},
+		{Template: "{+hello}", Expect: "Hello%20World!"},
+		{Template: "{+half}", Expect: "50%25"},
+		{Template: "{base}index", Expect: "http%3A%2F%2Fexample.com%2Fhome%2Findex"},
+		{Template: "{+base}index", Expect: "http://example.com/home/index"},
+		{Template: "O{+empty}X", Expect: "OX"},
+		{Template: "{+path}/here", Expect: "/foo/bar/here"},
+		{Template: "here?ref={+path}", Expect: "here?ref=/foo/bar"},
+		{Template: "{+path:6}/here", Expect: "/foo/b/here"},
+		{Template: "{+list}", Expect: "red,green,blue"},
+		{Template: "{+keys}", Expect: "comma,,,dot,.,semi,;"},
+		{Template: "{+keys*}", Expect: "comma=,,dot=.,semi=;"},
+		{Template: "{#x,hello,y}", Expect: "#1024,Hello%20World!,768"},
+		{Template: "{#path:6}/here", Expect: "#/foo/b/here"},
+		{Template: "{#keys*}", Expect: "#comma=,,dot=.,semi=;"},
+		{Template: "{.who}", Expect: ".fred"},
+		{Template: "{.half,who}", Expect: ".50%25.fred"},
+		{Template: "X{.var:3}", Expect: "X.val"},
+		{Template: "X{.list*}", Expect: "X.red.green.blue"},
+		{Template: "X{.keys*}", Expect: "X.comma=%2C.dot=..semi=%3B"},
+		{Template: "{.dom*}", Expect: ".example.com"},
+		{Template: "{/who,dub}", Expect: "/fred/me%2Ftoo"},
+		{Template: "{/var:1,var}", Expect: "/v/value"},
+		{Template: "{/list*,path:4}", Expect: "/red/green/blue/%2Ffoo"},
+		{Template: "{/keys*}", Expect: "/comma=%2C/dot=./semi=%3B"},
+		{Template: "{;x,y,empty}", Expect: ";x=1024;y=768;empty"},
+		{Template: "{;v,empty,who}", Expect: ";v=6;empty;who=fred"},
+		{Template: "{;hello:5}", Expect: ";hello=Hello"},
+		{Template: "{;list}", Expect: ";list=red,green,blue"},
+		{Template: "{;list*}", Expect: ";list=red;list=green;list=blue"},
+		{Template: "{;keys*}", Expect: ";comma=%2C;dot=.;semi=%3B"},
+		{Template: "{?x,y,empty}", Expect: "?x=1024&y=768&empty="},
+		{Template: "{?var:3}", Expect: "?var=val"},
+		{Template: "{?list*}", Expect: "?list=red&list=green&list=blue"},
+		{Template: "{?keys}", Expect: "?keys=comma,%2C,dot,.,semi,%3B"},
+		{Template: "{?keys*}", Expect: "?comma=%2C&dot=.&semi=%3B"},
+		{Template: "{?empty_keys*}", Expect: ""},
+		{Template: "{?undef}", Expect: ""},
+		{Template: "?fixed=yes{&x}", Expect: "?fixed=yes&x=1024"},
+		{Template: "{&var:3}", Expect: "&var=val"},
+		{Template: "{&keys*}", Expect: "&comma=%2C&dot=.&semi=%3B"},
+		{Template: "/search{?q}", Expect: "/search"},
+		{Template: "/a b/%7e{count}", Expect: "/a%20b/%7eone,two,three"},
+	}
+
+	for i, tcase := range tcases {
+		t.Run(fmt.Sprintf("%d", i), func(t *testing.T) {
+			tmpl, err := ParseURITemplate(tcase.Template)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if tmpl.String() != tcase.Template {
+				t.Fatalf("expected %q, got %q", tcase.Template, tmpl.String())
+			}
+			if out := tmpl.Expand(vars); out != tcase.Expect {
+				t.Fatalf("expected %q, got %q", tcase.Expect, out)
+			}
+		})
+	}
+}
+
+func TestParseURITemplate(t *testing.T) {
+	t.Parallel()
+
+	tcases := []struct {
+		Template  string
+		Variables []string
+		Err       bool
+	}{
+		{Template: "", Variables: nil},
+		{Template: "/static", Variables: nil},
+		{Template: "/users/{id}{?fields*,page,id}", Variables: []string{"id", "fields", "page"}},
+		{Template: "{a.b,_c,%41:10}", Variables: []string{"a.b", "_c", "%41"}},
+		{Template: "{", Err: true},
+		{Template: "}", Err: true},
+		{Template: "{var", Err: true},
+		{Template: "{}", Err: true},
+		{Template: "{=var}", Err: true},
+		{Template: "{var:0}", Err: true},
+		{Template: "{var:10000}", Err: true},
+		{Template: "{var:05}", Err: true},
+		{Template: "{va r}", Err: true},
+		{Template: "{a..b}", Err: true},
+		{Template: "{%zz}", Err: true},
+		{Template: "{var,}", Err: true},
+	}
+
+	for i, tcase := range tcases {
+		t.Run(fmt.Sprintf("%d", i), func(t *testing.T) {
+			tmpl, err := ParseURITemplate(tcase.Template)
+			if (err != nil) != tcase.Err {
+				t.Fatalf("expected error %v, got %v", tcase.Err, err)
+			}
+			if err != nil {
+				return
+			}
+			if vars := tmpl.Variables(); !reflect.DeepEqual(vars, tcase.Variables) {
+				t.Fatalf("expected %v, got %v", tcase.Variables, vars)
+			}
+		})
+	}
+}
@@ -5,11 +5,24 @@
 
 package htutil
 
-import "net/url"
+import (
+	"database/sql/driver"
+	"encoding/json"
+	"fmt"
+	"net/url"
+	"sort"
+	"strconv"
+	"strings"
+)
 
 // URL embeds *url.URL, but implements encoding.TextMarshaler and
 // encoding.TextUnmarshaler to simply call MarshalBinary and UnmarshalBinary
 // respectively.
+//
+// A URL with a nil *url.URL is absent, which is distinct from an empty
+// URL: it is marshaled as an empty text, as a JSON null, and as a SQL
+// NULL, from which it is also unmarshaled. It is the zero value, as
+// reported by IsZero for the omitzero option of encoding/json.
 type URL struct {
 	*url.URL
 }
@@ -22,5 +35,303 @@ func (u *URL) UnmarshalText(data []byte) error {
 }
 
 func (u URL) MarshalText() ([]byte, error) {
+	if u.URL == nil {
+		return nil, nil
+	}
 	return u.MarshalBinary()
 }
+
+// UnmarshalJSON unmarshals a JSON string, or null into an absent URL.
+func (u *URL) UnmarshalJSON(data []byte) error {
+	if string(data) == "null" {
+		u.URL = nil
+		return nil
+	}
+	var s string
+	if err := json.Unmarshal(data, &s); err != nil {
+		return err
+	}
+	return u.UnmarshalText([]byte(s))
+}
+
+// MarshalJSON marshals u as a JSON string, or null if u is absent.
+func (u URL) MarshalJSON() ([]byte, error) {
+	if u.URL == nil {
+		return []byte("null"), nil
+	}
+	return json.Marshal(u.URL.String())
+}
+
+// IsZero returns whether u is absent.
+func (u URL) IsZero() bool {
+	return u.URL == nil
+}
+
+// String returns the URL as a string, or "" if u is absent.
+func (u URL) String() string {
+	if u.URL == nil {
+		return ""
+	}
+	return u.URL.String()
+}
+
+// Value implements driver.Valuer, storing u as a string, or NULL if u is
+// absent.
+func (u URL) Value() (driver.Value, error) {
+	if u.URL == nil {
+		return nil, nil
+	}
+	return u.URL.String(), nil
+}
+
+// Scan implements sql.Scanner, loading u from a string or bytes, or NULL
+// into an absent URL.
+func (u *URL) Scan(src any) error {
+	switch src := src.(type) {
+	case nil:
+		u.URL = nil
+		return nil
+	case string:
+		return u.scan(src)
+	case []byte:
+		return u.scan(string(src))
+	}
+	return fmt.Errorf("scanning url: unsupported type %T", src)
+}
+
+func (u *URL) scan(s string) error {
+	parsed, err := url.Parse(s)
+	if err != nil {
+		return fmt.Errorf("scanning url: %w", err)
+	}
+	u.URL = parsed
+	return nil
+}
+
+// Normalize returns a copy of u in normal form, as per RFC 3986 §6.2.2 and
+// §6.2.3, so that equivalent URLs have the same string representation:
+//
+//   - the scheme and host are lowercased;
+//   - the default port of the scheme, or an empty port, is removed;
+//   - percent-encoded unreserved characters are decoded, and the hex
+//     digits of the other percent-encoded octets are uppercased;
+//   - the dot segments of the path are removed, and an empty path with a
+//     host becomes "/".
+//
+// The order of query parameters is preserved, as it may be significant.
+func (u URL) Normalize() URL {
+	if u.URL == nil {
+		return u
+	}
+	n := *u.URL
+	if n.User != nil {
+		user := *n.User
+		n.User = &user
+	}
+	n.Scheme = strings.ToLower(n.Scheme)
+	if n.Opaque != "" {
+		return URL{&n}
+	}
+
+	host := strings.ToLower(n.Host)
+	if i := strings.LastIndexByte(host, ':'); i != -1 && strings.IndexByte(host[i:], ']') == -1 {
+		if port := host[i+1:]; port == "" || port == defaultPorts[n.Scheme] {
+			host = host[:i]
+		}
+	}
+	n.Host = host
+
+	p := removeDotSegments(normalizePercent(n.EscapedPath()))
+	if p == "" && n.Host != "" {
+		p = "/"
+	}
+	if unescaped, err := url.PathUnescape(p); err == nil {
+		n.Path, n.RawPath = unescaped, p
+	}
+	n.RawQuery = normalizePercent(n.RawQuery)
+	if n.Fragment != "" {
+		f := normalizePercent(n.EscapedFragment())
+		if unescaped, err := url.PathUnescape(f); err == nil {
+			n.Fragment, n.RawFragment = unescaped, f
+		}
+	}
+	return URL{&n}
+}
+
+// Equal returns whether u and other are equivalent, that is, whether their
+// normal forms are the same; see Normalize. Absent URLs are only equal to
+// each other. Normalized URLs can be used as map keys through their
+// String method.
+func (u URL) Equal(other URL) bool {
+	if u.URL == nil || other.URL == nil {
+		return u.URL == nil && other.URL == nil
+	}
+	return u.Normalize().String() == other.Normalize().String()
+}
+
+// ResolveReference resolves the URI reference ref from the absolute base
+// URL u, as per RFC 3986 §5.2, like the ResolveReference method of
+// url.URL. An absent ref resolves to u.
+func (u URL) ResolveReference(ref URL) URL {
+	switch {
+	case ref.URL == nil:
+		return u
+	case u.URL == nil:
+		return ref
+	}
+	return URL{u.URL.ResolveReference(ref.URL)}
+}
+
+// WithParam returns a copy of u whose query parameter key is set to value,
+// in place of its first occurrence, or appended if absent. The other
+// parameters keep their order and original escaping.
+func (u URL) WithParam(key, value string) URL {
+	return u.setParam(key, []string{value})
+}
+
+// WithoutParam returns a copy of u without the query parameter key. The
+// other parameters keep their order and original escaping.
+func (u URL) WithoutParam(key string) URL {
+	return u.setParam(key, nil)
+}
+
+// MergeQuery returns a copy of u whose query parameters in values are set
+// to their values, like with WithParam; keys with no values are removed.
+// Keys absent from u are appended in sorted order, and the other
+// parameters keep their order and original escaping.
+func (u URL) MergeQuery(values url.Values) URL {
+	if u.URL == nil {
+		return u
+	}
+	keys := make([]string, 0, len(values))
+	for key := range values {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+
+	pairs := splitQuery(u.RawQuery)
+	for _, key := range keys {
+		pairs = replaceParam(pairs, key, values[key])
+	}
+	return u.withQuery(pairs)
+}
+
+// SortedQuery returns a copy of u whose query parameters are sorted by
+// key, so that URLs with the same parameters have the same query. The
+// values of a same key keep their order, as it may be significant, and
+// parameters keep their original escaping. Empty parameters are removed.
+func (u URL) SortedQuery() URL {
+	if u.URL == nil {
+		return u
+	}
+	pairs := splitQuery(u.RawQuery)
+	nonEmpty := pairs[:0]
+	for _, pair := range pairs {
+		if pair != "" {
+			nonEmpty = append(nonEmpty, pair)
+		}
+	}
+	sort.SliceStable(nonEmpty, func(i, j int) bool {
+		return queryKey(nonEmpty[i]) < queryKey(nonEmpty[j])
+	})
+	return u.withQuery(nonEmpty)
+}
+
+func (u URL) setParam(key string, values []string) URL {
+	if u.URL == nil {
+		return u
+	}
+	return u.withQuery(replaceParam(splitQuery(u.RawQuery), key, values))
+}
+
+// withQuery returns a copy of u with the raw query parameters pairs.
+func (u URL) withQuery(pairs []string) URL {
+	n := *u.URL
+	n.RawQuery = strings.Join(pairs, "&")
+	return URL{&n}
+}
+
+// splitQuery splits the raw query q into its raw key=value pairs.
+func splitQuery(q string) []string {
+	if q == "" {
+		return nil
+	}
+	return strings.Split(q, "&")
+}
+
+// queryKey returns the unescaped key of the raw query parameter pair, or
+// its raw key if it is not validly escaped.
+func queryKey(pair string) string {
+	key, _, _ := strings.Cut(pair, "=")
+	if unescaped, err := url.QueryUnescape(key); err == nil {
+		return unescaped
+	}
+	return key
+}
+
+// replaceParam replaces the parameters key of the raw query parameters
+// pairs with values, at the place of the first one, or at the end.
+func replaceParam(pairs []string, key string, values []string) []string {
+	replacement := make([]string, len(values))
+	for i, value := range values {
+		replacement[i] = url.QueryEscape(key) + "=" + url.QueryEscape(value)
+	}
+	out := make([]string, 0, len(pairs)+len(replacement))
+	for _, pair := range pairs {
+		if queryKey(pair) != key {
+			out = append(out, pair)
+			continue
+		}
+		out = append(out, replacement...)
+		replacement = nil
+	}
+	return append(out, replacement...)
+}
+
+// normalizePercent decodes the percent-encoded unreserved characters of s,
+// and uppercases the hex digits of the other percent-encoded octets.
+func normalizePercent(s string) string {
+	if strings.IndexByte(s, '%') == -1 {
+		return s
+	}
+	var out strings.Builder
+	for i := 0; i < len(s); i++ {
+		if s[i] != '%' || i+2 >= len(s) || !isHexDigit(s[i+1]) || !isHexDigit(s[i+2]) {
+			out.WriteByte(s[i])
+			continue
+		}
+		c, _ := strconv.ParseUint(s[i+1:i+3], 16, 8)
+		if b := byte(c); isAlpha(b) || isDigit(b) || strings.IndexByte("-._~", b) != -1 {
+			out.WriteByte(b)
+		} else {
+			out.WriteString(strings.ToUpper(s[i : i+3]))
+		}
+		i += 2
+	}
+	return out.String()
+}
+
+// removeDotSegments removes the "." and ".." segments of the path p, as
+// per RFC 3986 §5.2.4.
+func removeDotSegments(p string) string {
+	segments := strings.Split(p, "/")
+	out := make([]string, 0, len(segments))
+	for i, seg := range segments {
+		last := i == len(segments)-1
+		switch seg {
+		case ".":
+		case "..":
+			if len(out) > 1 || (len(out) == 1 && out[0] != "") {
+				out = out[:len(out)-1]
+			}
+		default:
+			out = append(out, seg)
+			continue
+		}
+		if last {
+			// A trailing dot segment leaves the path with a trailing slash.
+			out = append(out, "")
+		}
+	}
+	return strings.Join(out, "/")
+}
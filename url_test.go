@@ -0,0 +1,279 @@
+// Copyright 2026 Franklin "Snaipe" Mathieu.
+//
+// Use of this source code is governed by the MIT license that can be
+// found in the LICENSE file.
+
+package htutil
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/url"
+	"testing"
+)
+
+func TestURLJSON(t *testing.T) {
+	t.Parallel()
+
+	type T struct {
+		URL URL `json:"url"`
+	}
+
+	tcases := []struct {
+		JSON    string
+		Absent  bool
+		Expect  string
+		Marshal string
+		Err     bool
+	}{
+		{JSON: `{"url":"https://example.com/a?b=c"}`, Expect: "https://example.com/a?b=c", Marshal: `{"url":"https://example.com/a?b=c"}`},
+		{JSON: `{"url":""}`, Expect: "", Marshal: `{"url":""}`},
+		{JSON: `{"url":null}`, Absent: true, Marshal: `{"url":null}`},
+		{JSON: `{}`, Absent: true, Marshal: `{"url":null}`},
+		{JSON: `{"url":42}`, Err: true},
+		{JSON: `{"url":":"}`, Err: true},
+	}
+
+	for i, tcase := range tcases {
+		t.Run(fmt.Sprintf("%d", i), func(t *testing.T) {
+			var v T
+			err := json.Unmarshal([]byte(tcase.JSON), &v)
+			if (err != nil) != tcase.Err {
+				t.Fatalf("expected error %v, got %v", tcase.Err, err)
+			}
+			if err != nil {
+				return
+			}
+			if v.URL.IsZero() != tcase.Absent {
+				t.Fatalf("expected absent %v, got %v", tcase.Absent, v.URL.IsZero())
+			}
+			if v.URL.String() != tcase.Expect {
+				t.Fatalf("expected %q, got %q", tcase.Expect, v.URL.String())
+			}
+			out, err := json.Marshal(v)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if string(out) != tcase.Marshal {
+				t.Fatalf("expected %s, got %s", tcase.Marshal, out)
+			}
+		})
+	}
+}
+
+func TestURLSQL(t *testing.T) {
+	t.Parallel()
+
+	tcases := []struct {
+		Src    any
+		Absent bool
+		Expect string
+		Err    bool
+	}{
+		{Src: "https://example.com", Expect: "https://example.com"},
+		{Src: []byte("/path"), Expect: "/path"},
+		{Src: nil, Absent: true},
+		{Src: 42, Err: true},
+		{Src: ":", Err: true},
+	}
+
+	for i, tcase := range tcases {
+		t.Run(fmt.Sprintf("%d", i), func(t *testing.T) {
+			u := URL{&url.URL{Path: "/previous"}}
+			err := u.Scan(tcase.Src)
+			if (err != nil) != tcase.Err {
+				t.Fatalf("expected error %v, got %v", tcase.Err, err)
+			}
+			if err != nil {
+				return
+			}
+			if u.IsZero() != tcase.Absent {
+				t.Fatalf("expected absent %v, got %v", tcase.Absent, u.IsZero())
+			}
+			value, err := u.Value()
+			if err != nil {
+				t.Fatal(err)
+			}
+			if tcase.Absent {
+				if value != nil {
+					t.Fatalf("expected NULL, got %v", value)
+				}
+			} else if value != tcase.Expect {
+				t.Fatalf("expected %q, got %v", tcase.Expect, value)
+			}
+		})
+	}
+}
+
+func TestURLText(t *testing.T) {
+	t.Parallel()
+
+	var u URL
+	if text, err := u.MarshalText(); err != nil || len(text) != 0 {
+		t.Fatalf("expected empty text for an absent URL, got %q (error: %v)", text, err)
+	}
+	if s := u.String(); s != "" {
+		t.Fatalf("expected empty string for an absent URL, got %q", s)
+	}
+	if err := u.UnmarshalText(nil); err != nil || u.IsZero() {
+		t.Fatalf("expected an empty URL from an empty text, got %v (error: %v)", u.URL, err)
+	}
+}
+
+func TestURLNormalize(t *testing.T) {
+	t.Parallel()
+
+	tcases := []struct {
+		URL    string
+		Expect string
+	}{
+		{URL: "HTTP://Example.COM:80/a/./b/../c/%7euser?q=%2f#F", Expect: "http://example.com/a/c/~user?q=%2F#F"},
+		{URL: "https://example.com:443", Expect: "https://example.com/"},
+		{URL: "https://example.com:8443/", Expect: "https://example.com:8443/"},
+		{URL: "http://example.com:/", Expect: "http://example.com/"},
+		{URL: "http://[::1]:80/", Expect: "http://[::1]/"},
+		{URL: "http://[::1]/", Expect: "http://[::1]/"},
+		{URL: "http://example.com/a/b/..", Expect: "http://example.com/a/"},
+		{URL: "http://example.com/a/.", Expect: "http://example.com/a/"},
+		{URL: "http://example.com/../../a", Expect: "http://example.com/a"},
+		{URL: "http://example.com/a%2fb/%41", Expect: "http://example.com/a%2Fb/A"},
+		{URL: "http://example.com/?b=1&a=2", Expect: "http://example.com/?b=1&a=2"},
+		{URL: "a/./b/../c", Expect: "a/c"},
+		{URL: "MAILTO:User@Example.com", Expect: "mailto:User@Example.com"},
+	}
+
+	for i, tcase := range tcases {
+		t.Run(fmt.Sprintf("%d", i), func(t *testing.T) {
+			u, err := url.Parse(tcase.URL)
+			if err != nil {
+				t.Fatal(err)
+			}
+			orig := u.String()
+			if got := (URL{u}).Normalize().String(); got != tcase.Expect {
+				t.Fatalf("expected %q, got %q", tcase.Expect, got)
+			}
+			if u.String() != orig {
+				t.Fatalf("expected %q to be left untouched, got %q", orig, u.String())
+			}
+		})
+	}
+
+	if !(URL{}).Normalize().IsZero() {
+		t.Fatal("expected an absent URL to normalize to an absent URL")
+	}
+}
+
+func TestURLEqual(t *testing.T) {
+	t.Parallel()
+
+	tcases := []struct {
+		A, B  string
+		Equal bool
+	}{
+		{A: "http://example.com", B: "HTTP://EXAMPLE.com:80/", Equal: true},
+		{A: "http://example.com/~a", B: "http://example.com/%7Ea", Equal: true},
+		{A: "http://example.com/a", B: "http://example.com/b/../a", Equal: true},
+		{A: "http://example.com/a", B: "https://example.com/a"},
+		{A: "http://example.com/a", B: "http://example.com/A"},
+		{A: "http://example.com/?a=1&b=2", B: "http://example.com/?b=2&a=1"},
+	}
+
+	for i, tcase := range tcases {
+		t.Run(fmt.Sprintf("%d", i), func(t *testing.T) {
+			var a, b URL
+			if err := a.UnmarshalText([]byte(tcase.A)); err != nil {
+				t.Fatal(err)
+			}
+			if err := b.UnmarshalText([]byte(tcase.B)); err != nil {
+				t.Fatal(err)
+			}
+			if a.Equal(b) != tcase.Equal || b.Equal(a) != tcase.Equal {
+				t.Fatalf("expected equal %v for %q and %q", tcase.Equal, tcase.A, tcase.B)
+			}
+		})
+	}
+
+	if !(URL{}).Equal(URL{}) {
+		t.Fatal("expected absent URLs to be equal")
+	}
+	if (URL{}).Equal(URL{&url.URL{}}) {
+		t.Fatal("expected an absent URL to differ from an empty URL")
+	}
+}
+
+func TestURLResolveReference(t *testing.T) {
+	t.Parallel()
+
+	base := URL{&url.URL{Scheme: "http", Host: "a", Path: "/b/c/d;p", RawQuery: "q"}}
+
+	tcases := []struct {
+		Ref    string
+		Expect string
+	}{
+		{Ref: "g", Expect: "http://a/b/c/g"},
+		{Ref: "../g", Expect: "http://a/b/g"},
+		{Ref: "//g", Expect: "http://g"},
+		{Ref: "?y", Expect: "http://a/b/c/d;p?y"},
+		{Ref: "#s", Expect: "http://a/b/c/d;p?q#s"},
+		{Ref: "https://other/x", Expect: "https://other/x"},
+	}
+
+	for i, tcase := range tcases {
+		t.Run(fmt.Sprintf("%d", i), func(t *testing.T) {
+			ref, err := url.Parse(tcase.Ref)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if got := base.ResolveReference(URL{ref}).String(); got != tcase.Expect {
+				t.Fatalf("expected %q, got %q", tcase.Expect, got)
+			}
+		})
+	}
+
+	if got := base.ResolveReference(URL{}); got.URL != base.URL {
+		t.Fatalf("expected an absent reference to resolve to the base, got %v", got)
+	}
+}
+
+func TestURLQuery(t *testing.T) {
+	t.Parallel()
+
+	tcases := []struct {
+		URL    string
+		Edit   func(URL) URL
+		Expect string
+	}{
+		{"/a?b=1&c=2", func(u URL) URL { return u.WithParam("c", "3") }, "/a?b=1&c=3"},
+		{"/a?b=1&c=2", func(u URL) URL { return u.WithParam("d", "x y") }, "/a?b=1&c=2&d=x+y"},
+		{"/a?c=1&b=%7E&c=2", func(u URL) URL { return u.WithParam("c", "3") }, "/a?c=3&b=%7E"},
+		{"/a?p%61ge=2&q=%2F", func(u URL) URL { return u.WithParam("page", "3") }, "/a?page=3&q=%2F"},
+		{"/a", func(u URL) URL { return u.WithParam("page", "1") }, "/a?page=1"},
+		{"/a?b=1&c=2&b=3#f", func(u URL) URL { return u.WithoutParam("b") }, "/a?c=2#f"},
+		{"/a?b=1", func(u URL) URL { return u.WithoutParam("b") }, "/a"},
+		{"/a?b=1&c=%2f", func(u URL) URL { return u.WithoutParam("d") }, "/a?b=1&c=%2f"},
+		{"/a?c=%7e&a=2&b=1&a=1&&", func(u URL) URL { return u.SortedQuery() }, "/a?a=2&a=1&b=1&c=%7e"},
+		{"/a?z=1&b=2", func(u URL) URL {
+			return u.MergeQuery(url.Values{"z": {"9"}, "b": nil, "y": {"1", "2"}, "a": {"&"}})
+		}, "/a?z=9&a=%26&y=1&y=2"},
+	}
+
+	for i, tcase := range tcases {
+		t.Run(fmt.Sprintf("%d", i), func(t *testing.T) {
+			parsed, err := url.Parse(tcase.URL)
+			if err != nil {
+				t.Fatal(err)
+			}
+			u := URL{parsed}
+			if got := tcase.Edit(u).String(); got != tcase.Expect {
+				t.Fatalf("expected %q, got %q", tcase.Expect, got)
+			}
+			if got := u.String(); got != tcase.URL {
+				t.Fatalf("expected the original URL to be left unchanged, got %q", got)
+			}
+		})
+	}
+
+	if got := (URL{}).WithParam("a", "b"); !got.IsZero() {
+		t.Fatalf("expected an absent URL to stay absent, got %v", got)
+	}
+}
@@ -0,0 +1,111 @@
+// Copyright 2026 Franklin "Snaipe" Mathieu.
+//
+// Use of this source code is governed by the MIT license that can be
+// found in the LICENSE file.
+
+package htutil
+
+import (
+	"fmt"
+	"strings"
+)
+
+// Product is a product identifier, as found in the User-Agent and Server
+// header fields per RFC 9110 §10.1.5.
+type Product struct {
+	// Name is the name of the product.
+	Name string
+
+	// Version is the version of the product, or empty if unspecified.
+	Version string
+
+	// Comments are the comments that immediately follow the product
+	// identifier, in order.
+	Comments []string
+}
+
+// ParseProduct parses a single product identifier of the form
+// `name[/version]`.
+func ParseProduct(s string) (Product, error) {
+	name, version := s, ""
+	if i := strings.IndexByte(s, '/'); i != -1 {
+		name, version = s[:i], s[i+1:]
+		if err := ValidateToken(version); err != nil {
+			return Product{}, fmt.Errorf("parsing product version: %w", err)
+		}
+	}
+	if err := ValidateToken(name); err != nil {
+		return Product{}, fmt.Errorf("parsing product name: %w", err)
+	}
+	return Product{Name: name, Version: version}, nil
+}
+
+func (p Product) String() string {
+	var out strings.Builder
+	out.WriteString(p.Name)
+	if p.Version != "" {
+		out.WriteByte('/')
+		out.WriteString(p.Version)
+	}
+	for _, comment := range p.Comments {
+		out.WriteByte(' ')
+		out.WriteString(quoteComment(comment))
+	}
+	return out.String()
+}
+
+// UserAgent is an ordered list of product identifiers, as found in the
+// User-Agent and Server header fields.
+type UserAgent []Product
+
+// ParseUserAgent parses the value of a User-Agent or Server header field
+// into its product identifiers and comments. It only implements the
+// grammar of RFC 9110 §10.1.5, and does not attempt to recognize
+// particular browsers or devices.
+func ParseUserAgent(s string) (UserAgent, error) {
+	words, err := SplitWords(s)
+	if err != nil {
+		return nil, fmt.Errorf("parsing user agent: %w", err)
+	}
+	if len(words) == 0 {
+		return nil, fmt.Errorf("parsing user agent: empty value")
+	}
+	if words[0].Comment {
+		return nil, fmt.Errorf("parsing user agent: value starts with a comment")
+	}
+
+	var ua UserAgent
+	for _, word := range words {
+		if word.Comment {
+			last := &ua[len(ua)-1]
+			last.Comments = append(last.Comments, word.Text)
+			continue
+		}
+		product, err := ParseProduct(word.Text)
+		if err != nil {
+			return nil, fmt.Errorf("parsing user agent: %w", err)
+		}
+		ua = append(ua, product)
+	}
+	return ua, nil
+}
+
+// Product returns the first product identifier with the specified name,
+// compared case-insensitively.
+func (ua UserAgent) Product(name string) (Product, bool) {
+	for _, p := range ua {
+		if strings.EqualFold(p.Name, name) {
+			return p, true
+		}
+	}
+	return Product{}, false
+}
+
+// String formats ua into a well-formed User-Agent value.
+func (ua UserAgent) String() string {
+	parts := make([]string, len(ua))
+	for i, p := range ua {
+		parts[i] = p.String()
+	}
+	return strings.Join(parts, " ")
+}
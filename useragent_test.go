@@ -0,0 +1,89 @@
+// Copyright 2026 Franklin "Snaipe" Mathieu.
+//
+// Use of this source code is governed by the MIT license that can be
+// found in the LICENSE file.
+
+package htutil
+
+import (
+	"fmt"
+	"reflect"
+	"testing"
+)
+
+func TestParseUserAgent(t *testing.T) {
+	t.Parallel()
+
+	tcases := []struct {
+		In  string
+		Out UserAgent
+		Err bool
+	}{
+		{
+			In: "curl/8.4.0",
+			Out: UserAgent{
+				{Name: "curl", Version: "8.4.0"},
+			},
+		},
+		{
+			In: "Mozilla/5.0 (Windows NT 10.0; Win64; x64) AppleWebKit/537.36 (KHTML, like Gecko) Chrome/120.0.0.0 Safari/537.36",
+			Out: UserAgent{
+				{Name: "Mozilla", Version: "5.0", Comments: []string{"Windows NT 10.0; Win64; x64"}},
+				{Name: "AppleWebKit", Version: "537.36", Comments: []string{"KHTML, like Gecko"}},
+				{Name: "Chrome", Version: "120.0.0.0"},
+				{Name: "Safari", Version: "537.36"},
+			},
+		},
+		{
+			In: "Go-http-client (a) (b)",
+			Out: UserAgent{
+				{Name: "Go-http-client", Comments: []string{"a", "b"}},
+			},
+		},
+		{In: "", Err: true},
+		{In: "(comment) curl/1.0", Err: true},
+		{In: "curl/1.0/2.0", Err: true},
+		{In: "curl/1.0 (unterminated", Err: true},
+	}
+
+	for i, tcase := range tcases {
+		t.Run(fmt.Sprintf("%d", i), func(t *testing.T) {
+			actual, err := ParseUserAgent(tcase.In)
+			if tcase.Err {
+				if err == nil {
+					t.Fatalf("expected error, got %v", actual)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatal(err)
+			}
+			if !reflect.DeepEqual(actual, tcase.Out) {
+				t.Fatalf("expected %v, got %v", tcase.Out, actual)
+			}
+			if actual.String() != tcase.In {
+				t.Fatalf("expected %q to round-trip, got %q", tcase.In, actual.String())
+			}
+		})
+	}
+}
+
+func TestUserAgentString(t *testing.T) {
+	t.Parallel()
+
+	ua := UserAgent{
+		{Name: "myapp", Version: "1.2", Comments: []string{"+https://example.com (bot)"}},
+		{Name: "Go-http-client", Version: "1.1"},
+	}
+	expected := `myapp/1.2 (+https://example.com \(bot\)) Go-http-client/1.1`
+	if ua.String() != expected {
+		t.Fatalf("expected %q, got %q", expected, ua.String())
+	}
+	parsed, err := ParseUserAgent(ua.String())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !reflect.DeepEqual(parsed, ua) {
+		t.Fatalf("expected %v, got %v", ua, parsed)
+	}
+}
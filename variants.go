@@ -0,0 +1,250 @@
+// Copyright 2026 Franklin "Snaipe" Mathieu.
+//
+// Use of this source code is governed by the MIT license that can be
+// found in the LICENSE file.
+
+package htutil
+
+import (
+	"fmt"
+	"net/http"
+	"net/textproto"
+	"sort"
+	"strings"
+
+	"snai.pe/go-htutil/sfv"
+)
+
+// VariantAxis is a single dimension of content negotiation advertised in
+// the Variants header field: the request header field used to negotiate,
+// and the available values for it. The first value is the default.
+type VariantAxis struct {
+	Field  string
+	Values []string
+}
+
+// Variants describes the representations available for a resource, as per
+// the HTTP Representation Variants specification
+// (draft-ietf-httpbis-variants). It lets caches select among stored
+// negotiated responses more precisely than Vary would allow.
+type Variants []VariantAxis
+
+// ParseVariants parses a Variants header value.
+func ParseVariants(value string) (Variants, error) {
+	dict, err := sfv.ParseDictionary(value)
+	if err != nil {
+		return nil, fmt.Errorf("parsing variants: %w", err)
+	}
+	vs := make(Variants, 0, len(dict))
+	for _, member := range dict {
+		list, ok := member.Member.(sfv.InnerList)
+		if !ok {
+			return nil, fmt.Errorf("parsing variants: %s is not an inner list", member.Key)
+		}
+		values, err := sfInnerListStrings(list)
+		if err != nil {
+			return nil, fmt.Errorf("parsing variants: %s: %w", member.Key, err)
+		}
+		vs = append(vs, VariantAxis{
+			Field:  textproto.CanonicalMIMEHeaderKey(member.Key),
+			Values: values,
+		})
+	}
+	return vs, nil
+}
+
+// String formats vs as a Variants header value.
+func (vs Variants) String() string {
+	dict := make(sfv.Dictionary, len(vs))
+	for i, axis := range vs {
+		dict[i] = sfv.DictMember{
+			Key:    strings.ToLower(axis.Field),
+			Member: sfInnerListOf(axis.Values),
+		}
+	}
+	s, _ := sfv.FormatDictionary(dict)
+	return s
+}
+
+// ParseVariantKey parses a Variant-Key header value, which lists the
+// variant keys (one value per Variants axis) that a response satisfies.
+func ParseVariantKey(value string) ([][]string, error) {
+	list, err := sfv.ParseList(value)
+	if err != nil {
+		return nil, fmt.Errorf("parsing variant-key: %w", err)
+	}
+	keys := make([][]string, 0, len(list))
+	for _, member := range list {
+		inner, ok := member.(sfv.InnerList)
+		if !ok {
+			return nil, fmt.Errorf("parsing variant-key: member is not an inner list")
+		}
+		key, err := sfInnerListStrings(inner)
+		if err != nil {
+			return nil, fmt.Errorf("parsing variant-key: %w", err)
+		}
+		keys = append(keys, key)
+	}
+	return keys, nil
+}
+
+// FormatVariantKey formats the specified variant keys as a Variant-Key
+// header value.
+func FormatVariantKey(keys ...[]string) string {
+	list := make(sfv.List, len(keys))
+	for i, key := range keys {
+		list[i] = sfInnerListOf(key)
+	}
+	s, _ := sfv.FormatList(list)
+	return s
+}
+
+// SetVariants sets the Variants and Variant-Key header fields of h, and
+// appends the negotiated fields to Vary for the benefit of caches that do
+// not implement Variants.
+func SetVariants(h http.Header, vs Variants, keys ...[]string) {
+	h.Set("Variants", vs.String())
+	h.Set("Variant-Key", FormatVariantKey(keys...))
+	for _, axis := range vs {
+		AddVary(h, axis.Field)
+	}
+}
+
+// sfInnerListOf represents values as an inner list of tokens, or of strings
+// for values that are not valid structured field tokens.
+func sfInnerListOf(values []string) sfv.InnerList {
+	var list sfv.InnerList
+	for _, v := range values {
+		var item interface{} = v
+		if sfv.IsToken(v) {
+			item = sfv.Token(v)
+		}
+		list.Items = append(list.Items, sfv.Item{Value: item})
+	}
+	return list
+}
+
+func sfInnerListStrings(list sfv.InnerList) ([]string, error) {
+	out := make([]string, 0, len(list.Items))
+	for _, item := range list.Items {
+		switch v := item.Value.(type) {
+		case sfv.Token:
+			out = append(out, string(v))
+		case string:
+			out = append(out, v)
+		default:
+			return nil, fmt.Errorf("%v is not a token or a string", v)
+		}
+	}
+	return out, nil
+}
+
+// negotiateAxis returns the available values of axis that are acceptable
+// for a request with the header fields h, sorted by preference. If none
+// are acceptable, the default value is returned.
+func negotiateAxis(h http.Header, axis VariantAxis) []string {
+	if len(axis.Values) == 0 {
+		return nil
+	}
+
+	values := h.Values(axis.Field)
+	if len(values) == 0 {
+		return axis.Values
+	}
+
+	var match func(pattern, value string) bool
+	switch textproto.CanonicalMIMEHeaderKey(axis.Field) {
+	case "Accept", "Accept-Encoding", "Accept-Charset":
+		match = func(pattern, value string) bool {
+			return dumbglob(strings.ToLower(pattern), strings.ToLower(value))
+		}
+	case "Accept-Language":
+		match = languageRangeMatches
+	default:
+		// Unknown request header field; only exact matches are possible.
+		match = func(pattern, value string) bool { return pattern == value }
+	}
+
+	accepts := ParseAccept(values...)
+
+	type candidate struct {
+		value   string
+		quality float32
+	}
+	var candidates []candidate
+	for _, v := range axis.Values {
+		quality := float32(-1)
+		for _, acc := range accepts {
+			if match(acc.Value, v) {
+				quality = acc.Quality
+				break
+			}
+		}
+		if quality < 0 && strings.EqualFold(axis.Field, "Accept-Encoding") && v == "identity" {
+			// identity is implicitly acceptable, but least preferred.
+			quality = 0.001
+		}
+		if quality > 0 {
+			candidates = append(candidates, candidate{v, quality})
+		}
+	}
+	sort.SliceStable(candidates, func(i, j int) bool {
+		return candidates[i].quality > candidates[j].quality
+	})
+
+	out := make([]string, 0, len(candidates))
+	for _, c := range candidates {
+		out = append(out, c.value)
+	}
+	if len(out) == 0 {
+		out = append(out, axis.Values[0])
+	}
+	return out
+}
+
+// Negotiate returns the variant keys that are acceptable for a request with
+// the header fields h, in order of preference.
+func (vs Variants) Negotiate(h http.Header) [][]string {
+	keys := [][]string{{}}
+	for _, axis := range vs {
+		values := negotiateAxis(h, axis)
+		next := make([][]string, 0, len(keys)*len(values))
+		for _, key := range keys {
+			for _, v := range values {
+				k := make([]string, len(key), len(key)+1)
+				copy(k, key)
+				next = append(next, append(k, v))
+			}
+		}
+		keys = next
+	}
+	return keys
+}
+
+// Select returns the index of the stored response that best satisfies a
+// request with the header fields h, given the Variant-Key of each stored
+// response. It returns -1 if none is acceptable.
+func (vs Variants) Select(h http.Header, stored [][][]string) int {
+	for _, want := range vs.Negotiate(h) {
+		for i, keys := range stored {
+			for _, key := range keys {
+				if variantKeyEqual(want, key) {
+					return i
+				}
+			}
+		}
+	}
+	return -1
+}
+
+func variantKeyEqual(lhs, rhs []string) bool {
+	if len(lhs) != len(rhs) {
+		return false
+	}
+	for i := range lhs {
+		if lhs[i] != rhs[i] {
+			return false
+		}
+	}
+	return true
+}
@@ -0,0 +1,79 @@
+// Copyright 2026 Franklin "Snaipe" Mathieu.
+//
+// Use of this source code is governed by the MIT license that can be
+// found in the LICENSE file.
+
+package htutil
+
+import (
+	"fmt"
+	"net/http"
+	"reflect"
+	"testing"
+)
+
+func TestVariantsRoundTrip(t *testing.T) {
+	t.Parallel()
+
+	vs := Variants{
+		{Field: "Accept-Encoding", Values: []string{"gzip", "br"}},
+		{Field: "Accept-Language", Values: []string{"en", "fr", "zh-Hant"}},
+	}
+	const expected = "accept-encoding=(gzip br), accept-language=(en fr zh-Hant)"
+	if vs.String() != expected {
+		t.Fatalf("expected %s, got %s", expected, vs.String())
+	}
+	parsed, err := ParseVariants(expected)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !reflect.DeepEqual(parsed, vs) {
+		t.Fatalf("expected %v, got %v", vs, parsed)
+	}
+
+	keys := [][]string{{"gzip", "fr"}, {"identity", "fr"}}
+	if s := FormatVariantKey(keys...); s != "(gzip fr), (identity fr)" {
+		t.Fatalf("unexpected variant key %s", s)
+	}
+	parsedKeys, err := ParseVariantKey("(gzip fr), (identity fr)")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !reflect.DeepEqual(parsedKeys, keys) {
+		t.Fatalf("expected %v, got %v", keys, parsedKeys)
+	}
+}
+
+func TestVariantsSelect(t *testing.T) {
+	t.Parallel()
+
+	vs := Variants{
+		{Field: "Accept-Encoding", Values: []string{"identity", "gzip", "br"}},
+		{Field: "Accept-Language", Values: []string{"en", "fr", "de"}},
+	}
+	stored := [][][]string{
+		{{"identity", "en"}},
+		{{"gzip", "fr"}},
+		{{"br", "de"}, {"gzip", "de"}},
+	}
+
+	tcases := []struct {
+		Header   http.Header
+		Expected int
+	}{
+		{Header: http.Header{}, Expected: 0},
+		{Header: http.Header{"Accept-Language": {"fr-CH, fr;q=0.9"}}, Expected: 1},
+		{Header: http.Header{"Accept-Language": {"de"}, "Accept-Encoding": {"gzip"}}, Expected: 2},
+		{Header: http.Header{"Accept-Language": {"ja"}, "Accept-Encoding": {"identity;q=0, br"}}, Expected: -1},
+		{Header: http.Header{"Accept-Language": {"ja"}}, Expected: 0},
+	}
+
+	for i, tcase := range tcases {
+		t.Run(fmt.Sprintf("%d", i), func(t *testing.T) {
+			actual := vs.Select(tcase.Header, stored)
+			if actual != tcase.Expected {
+				t.Fatalf("expected %d, got %d (negotiated %v)", tcase.Expected, actual, vs.Negotiate(tcase.Header))
+			}
+		})
+	}
+}
@@ -0,0 +1,59 @@
+// Copyright 2026 Franklin "Snaipe" Mathieu.
+//
+// Use of this source code is governed by the MIT license that can be
+// found in the LICENSE file.
+
+package htutil
+
+import (
+	"net/http"
+	"net/textproto"
+	"strings"
+)
+
+// ParseVary returns the field names listed in the Vary header fields of h,
+// canonicalized and without duplicates, in order of appearance. It returns
+// ["*"] if the response varies on "*", as it then varies on aspects of the
+// request beyond its header fields, as per RFC 9110 §12.5.5.
+func ParseVary(h http.Header) []string {
+	var names []string
+	for _, value := range h.Values("Vary") {
+		for _, name := range SplitList(value) {
+			if name == "*" {
+				return []string{"*"}
+			}
+			name = textproto.CanonicalMIMEHeaderKey(name)
+			if !containsString(names, name) {
+				names = append(names, name)
+			}
+		}
+	}
+	return names
+}
+
+// AddVary adds the field names fields to the Vary header field of h,
+// unless already listed; field names are case-insensitive. Adding "*"
+// replaces all the field names, and nothing is added to a Vary of "*".
+func AddVary(h http.Header, fields ...string) {
+	listed := ParseVary(h)
+	if len(listed) == 1 && listed[0] == "*" {
+		return
+	}
+	var added []string
+	for _, field := range fields {
+		for _, name := range SplitList(field) {
+			if name == "*" {
+				h.Set("Vary", "*")
+				return
+			}
+			canonical := textproto.CanonicalMIMEHeaderKey(name)
+			if !containsString(listed, canonical) {
+				listed = append(listed, canonical)
+				added = append(added, name)
+			}
+		}
+	}
+	if len(added) > 0 {
+		h.Add("Vary", strings.Join(added, ", "))
+	}
+}
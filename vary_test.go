@@ -0,0 +1,67 @@
+// Copyright 2026 Franklin "Snaipe" Mathieu.
+//
+// Use of this source code is governed by the MIT license that can be
+// found in the LICENSE file.
+
+package htutil
+
+import (
+	"fmt"
+	"net/http"
+	"testing"
+)
+
+func TestParseVary(t *testing.T) {
+	t.Parallel()
+
+	tcases := []struct {
+		Values   []string
+		Expected []string
+	}{
+		{Values: nil, Expected: nil},
+		{Values: []string{"accept-encoding"}, Expected: []string{"Accept-Encoding"}},
+		{Values: []string{"Accept, Origin", "accept, ,Cookie"}, Expected: []string{"Accept", "Origin", "Cookie"}},
+		{Values: []string{"Accept", "*"}, Expected: []string{"*"}},
+	}
+
+	for i, tcase := range tcases {
+		t.Run(fmt.Sprintf("%d", i), func(t *testing.T) {
+			h := http.Header{"Vary": tcase.Values}
+			if names := ParseVary(h); fmt.Sprint(names) != fmt.Sprint(tcase.Expected) {
+				t.Fatalf("expected %q, got %q", tcase.Expected, names)
+			}
+		})
+	}
+}
+
+func TestAddVary(t *testing.T) {
+	t.Parallel()
+
+	tcases := []struct {
+		Values   []string
+		Fields   []string
+		Expected []string
+	}{
+		{Values: nil, Fields: []string{"Accept"}, Expected: []string{"Accept"}},
+		{Values: nil, Fields: []string{"Accept", "accept", "Origin"}, Expected: []string{"Accept, Origin"}},
+		{Values: []string{"Accept, Origin"}, Fields: []string{"origin"}, Expected: []string{"Accept, Origin"}},
+		{Values: []string{"Accept"}, Fields: []string{"Accept-Encoding, Cookie"}, Expected: []string{"Accept", "Accept-Encoding, Cookie"}},
+		{Values: []string{"Dictionary-ID"}, Fields: []string{"Dictionary-Id"}, Expected: []string{"Dictionary-ID"}},
+		{Values: []string{"Accept"}, Fields: []string{"Origin", "*"}, Expected: []string{"*"}},
+		{Values: []string{"*"}, Fields: []string{"Accept"}, Expected: []string{"*"}},
+		{Values: []string{"Accept"}, Fields: nil, Expected: []string{"Accept"}},
+	}
+
+	for i, tcase := range tcases {
+		t.Run(fmt.Sprintf("%d", i), func(t *testing.T) {
+			h := http.Header{}
+			if tcase.Values != nil {
+				h["Vary"] = tcase.Values
+			}
+			AddVary(h, tcase.Fields...)
+			if values := h.Values("Vary"); fmt.Sprint(values) != fmt.Sprint(tcase.Expected) {
+				t.Fatalf("expected %q, got %q", tcase.Expected, values)
+			}
+		})
+	}
+}
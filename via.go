@@ -0,0 +1,115 @@
+// Copyright 2026 Franklin "Snaipe" Mathieu.
+//
+// Use of this source code is governed by the MIT license that can be
+// found in the LICENSE file.
+
+package htutil
+
+import (
+	"net/http"
+	"strings"
+)
+
+// Via is a member of the Via header field, recording an intermediary that
+// forwarded a message, as per RFC 9110 §7.6.3.
+type Via struct {
+	// Protocol is the name of the protocol the message was received with,
+	// or "" for HTTP, whose name is omitted.
+	Protocol string
+
+	// Version is the version of the protocol the message was received
+	// with, like "1.1".
+	Version string
+
+	// ReceivedBy is the host and optional port of the recipient, or a
+	// pseudonym hiding it.
+	ReceivedBy string
+
+	// Comment is an optional comment identifying the software of the
+	// recipient.
+	Comment string
+}
+
+// String returns v as a member of the Via header field.
+func (v Via) String() string {
+	var out strings.Builder
+	if v.Protocol != "" && !strings.EqualFold(v.Protocol, "HTTP") {
+		out.WriteString(v.Protocol + "/")
+	}
+	out.WriteString(v.Version + " " + v.ReceivedBy)
+	if v.Comment != "" {
+		out.WriteString(" " + quoteComment(v.Comment))
+	}
+	return out.String()
+}
+
+// ParseVia returns the members of the Via header fields of h, in the order
+// the message was forwarded. Unparseable members are silently dropped.
+func ParseVia(h http.Header) []Via {
+	var vias []Via
+	for _, value := range h.Values("Via") {
+		for _, member := range SplitCommentedList(value) {
+			if via, ok := parseViaMember(member); ok {
+				vias = append(vias, via)
+			}
+		}
+	}
+	return vias
+}
+
+func parseViaMember(member string) (Via, bool) {
+	words, err := SplitWords(member)
+	if err != nil || len(words) < 2 || len(words) > 3 || words[0].Comment || words[1].Comment {
+		return Via{}, false
+	}
+	var via Via
+	if name, version, ok := strings.Cut(words[0].Text, "/"); ok {
+		if !IsToken(name) {
+			return Via{}, false
+		}
+		via.Protocol, via.Version = name, version
+		if strings.EqualFold(name, "HTTP") {
+			via.Protocol = ""
+		}
+	} else {
+		via.Version = words[0].Text
+	}
+	if !IsToken(via.Version) || strings.ContainsRune(words[1].Text, ',') {
+		return Via{}, false
+	}
+	via.ReceivedBy = words[1].Text
+	if len(words) == 3 {
+		if !words[2].Comment {
+			return Via{}, false
+		}
+		via.Comment = words[2].Text
+	}
+	return via, true
+}
+
+// AppendVia appends a member to the Via header field of h, recording that
+// the message was received with the protocol protoVersion, like "1.1", or
+// the Proto of a http.Request, like "HTTP/2.0", by the recipient named
+// pseudonym, which must be a token, or a host and optional port.
+//
+// Proxies forwarding requests should check for loops with ViaLoop first.
+func AppendVia(h http.Header, protoVersion, pseudonym string) {
+	via := Via{Version: protoVersion, ReceivedBy: pseudonym}
+	if name, version, ok := strings.Cut(protoVersion, "/"); ok {
+		via.Protocol, via.Version = name, version
+	}
+	h.Add("Via", via.String())
+}
+
+// ViaLoop reports whether the message whose header is h was already
+// forwarded by the recipient named pseudonym, which means that it is
+// looping between intermediaries, and should be rejected with 508 Loop
+// Detected rather than forwarded again.
+func ViaLoop(h http.Header, pseudonym string) bool {
+	for _, via := range ParseVia(h) {
+		if strings.EqualFold(via.ReceivedBy, pseudonym) {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,94 @@
+// Copyright 2026 Franklin "Snaipe" Mathieu.
+//
+// Use of this source code is governed by the MIT license that can be
+// found in the LICENSE file.
+
+package htutil
+
+import (
+	"fmt"
+	"net/http"
+	"reflect"
+	"testing"
+)
+
+func TestParseVia(t *testing.T) {
+	t.Parallel()
+
+	tcases := []struct {
+		Values []string
+		Expect []Via
+	}{
+		{
+			Values: []string{"1.0 fred, 1.1 p.example.net"},
+			Expect: []Via{{Version: "1.0", ReceivedBy: "fred"}, {Version: "1.1", ReceivedBy: "p.example.net"}},
+		},
+		{
+			Values: []string{"HTTP/1.1 proxy:8080 (Apache/2.4, mod_proxy)", "2.0 cdn"},
+			Expect: []Via{
+				{Version: "1.1", ReceivedBy: "proxy:8080", Comment: "Apache/2.4, mod_proxy"},
+				{Version: "2.0", ReceivedBy: "cdn"},
+			},
+		},
+		{
+			Values: []string{"WebSocket/13 gateway"},
+			Expect: []Via{{Protocol: "WebSocket", Version: "13", ReceivedBy: "gateway"}},
+		},
+		{
+			Values: []string{"1.1, 1.1 a b, 1.1 (comment) c, 1.1 x y, 1.1 ok, 1.1 bad (unterminated"},
+			Expect: []Via{{Version: "1.1", ReceivedBy: "ok"}},
+		},
+		{Values: nil, Expect: nil},
+	}
+
+	for i, tcase := range tcases {
+		t.Run(fmt.Sprintf("%d", i), func(t *testing.T) {
+			h := http.Header{"Via": tcase.Values}
+			if got := ParseVia(h); !reflect.DeepEqual(got, tcase.Expect) {
+				t.Fatalf("expected %+v, got %+v", tcase.Expect, got)
+			}
+		})
+	}
+}
+
+func TestAppendVia(t *testing.T) {
+	t.Parallel()
+
+	h := http.Header{}
+	AppendVia(h, "HTTP/1.1", "edge")
+	AppendVia(h, "2.0", "origin-proxy:8443")
+	AppendVia(h, "WebSocket/13", "gateway")
+
+	expected := []string{"1.1 edge", "2.0 origin-proxy:8443", "WebSocket/13 gateway"}
+	if got := h.Values("Via"); !reflect.DeepEqual(got, expected) {
+		t.Fatalf("expected %q, got %q", expected, got)
+	}
+	if got := (Via{Version: "1.1", ReceivedBy: "edge", Comment: "htutil (go)"}).String(); got != `1.1 edge (htutil \(go\))` {
+		t.Fatalf("expected a quoted comment, got %q", got)
+	}
+}
+
+func TestViaLoop(t *testing.T) {
+	t.Parallel()
+
+	tcases := []struct {
+		Values    []string
+		Pseudonym string
+		Expect    bool
+	}{
+		{Values: []string{"1.1 edge, 1.1 origin"}, Pseudonym: "edge", Expect: true},
+		{Values: []string{"1.1 Edge (cache)"}, Pseudonym: "edge", Expect: true},
+		{Values: []string{"1.1 edge-2"}, Pseudonym: "edge", Expect: false},
+		{Values: []string{"1.1 other (edge)"}, Pseudonym: "edge", Expect: false},
+		{Values: nil, Pseudonym: "edge", Expect: false},
+	}
+
+	for i, tcase := range tcases {
+		t.Run(fmt.Sprintf("%d", i), func(t *testing.T) {
+			h := http.Header{"Via": tcase.Values}
+			if got := ViaLoop(h, tcase.Pseudonym); got != tcase.Expect {
+				t.Fatalf("expected %v, got %v", tcase.Expect, got)
+			}
+		})
+	}
+}
@@ -0,0 +1,257 @@
+// Copyright 2026 Franklin "Snaipe" Mathieu.
+//
+// Use of this source code is governed by the MIT license that can be
+// found in the LICENSE file.
+
+package htutil
+
+import (
+	"bufio"
+	"io"
+	"net"
+	"net/http"
+)
+
+// Function types for each of the methods of a http.ResponseWriter and
+// its optional interfaces, as hooked by WriterHooks.
+type (
+	WriteHeaderFunc func(status int)
+	WriteFunc       func(p []byte) (int, error)
+	FlushFunc       func()
+	ReadFromFunc    func(r io.Reader) (int64, error)
+	HijackFunc      func() (net.Conn, *bufio.ReadWriter, error)
+	PushFunc        func(target string, opts *http.PushOptions) error
+)
+
+// WriterHooks is a set of hooks to install with Wrap. Each hook receives the
+// next function in the chain, and returns the function to call in its
+// place. Nil hooks leave the corresponding method untouched.
+type WriterHooks struct {
+	WriteHeader func(next WriteHeaderFunc) WriteHeaderFunc
+	Write       func(next WriteFunc) WriteFunc
+	Flush       func(next FlushFunc) FlushFunc
+	ReadFrom    func(next ReadFromFunc) ReadFromFunc
+	Hijack      func(next HijackFunc) HijackFunc
+	Push        func(next PushFunc) PushFunc
+}
+
+type rwUnwrapper interface {
+	Unwrap() http.ResponseWriter
+}
+
+// hookedWriter implements all the optional interfaces; Wrap only exposes
+// the ones that the underlying writer implements.
+type hookedWriter struct {
+	w           http.ResponseWriter
+	writeHeader WriteHeaderFunc
+	write       WriteFunc
+	flush       FlushFunc
+	readFrom    ReadFromFunc
+	hijack      HijackFunc
+	push        PushFunc
+}
+
+func (rw *hookedWriter) Header() http.Header                 { return rw.w.Header() }
+func (rw *hookedWriter) WriteHeader(status int)              { rw.writeHeader(status) }
+func (rw *hookedWriter) Write(p []byte) (int, error)         { return rw.write(p) }
+func (rw *hookedWriter) Flush()                              { rw.flush() }
+func (rw *hookedWriter) ReadFrom(r io.Reader) (int64, error) { return rw.readFrom(r) }
+func (rw *hookedWriter) Unwrap() http.ResponseWriter         { return rw.w }
+
+func (rw *hookedWriter) Hijack() (net.Conn, *bufio.ReadWriter, error) {
+	return rw.hijack()
+}
+
+func (rw *hookedWriter) Push(target string, opts *http.PushOptions) error {
+	return rw.push(target, opts)
+}
+
+// writerOnly hides the ReadFrom method of a writer, to prevent io.Copy
+// from recursing into it.
+type writerOnly struct {
+	io.Writer
+}
+
+// Wrap returns a http.ResponseWriter that calls the specified hooks, and
+// that implements http.Flusher, http.Hijacker, io.ReaderFrom, and
+// http.Pusher if, and only if, w implements them. The returned writer
+// also has an Unwrap method returning w, for use with
+// http.ResponseController.
+//
+// If the Write hook is set but not the ReadFrom hook, ReadFrom copies
+// through the hooked Write rather than calling the ReadFrom method of w,
+// so that hooks never miss any written bytes.
+func Wrap(w http.ResponseWriter, hooks WriterHooks) http.ResponseWriter {
+	rw := &hookedWriter{
+		w:           w,
+		writeHeader: w.WriteHeader,
+		write:       w.Write,
+	}
+	if hooks.WriteHeader != nil {
+		rw.writeHeader = hooks.WriteHeader(rw.writeHeader)
+	}
+	if hooks.Write != nil {
+		rw.write = hooks.Write(rw.write)
+	}
+
+	flusher, f := w.(http.Flusher)
+	if f {
+		rw.flush = flusher.Flush
+		if hooks.Flush != nil {
+			rw.flush = hooks.Flush(rw.flush)
+		}
+	}
+
+	hijacker, h := w.(http.Hijacker)
+	if h {
+		rw.hijack = hijacker.Hijack
+		if hooks.Hijack != nil {
+			rw.hijack = hooks.Hijack(rw.hijack)
+		}
+	}
+
+	readerFrom, r := w.(io.ReaderFrom)
+	if r {
+		rw.readFrom = readerFrom.ReadFrom
+		switch {
+		case hooks.ReadFrom != nil:
+			rw.readFrom = hooks.ReadFrom(rw.readFrom)
+		case hooks.Write != nil:
+			rw.readFrom = func(r io.Reader) (int64, error) {
+				return io.Copy(writerOnly{rw}, r)
+			}
+		}
+	}
+
+	pusher, p := w.(http.Pusher)
+	if p {
+		rw.push = pusher.Push
+		if hooks.Push != nil {
+			rw.push = hooks.Push(rw.push)
+		}
+	}
+
+	switch {
+	case f && h && r && p:
+		return struct {
+			http.ResponseWriter
+			http.Flusher
+			http.Hijacker
+			io.ReaderFrom
+			http.Pusher
+			rwUnwrapper
+		}{rw, rw, rw, rw, rw, rw}
+	case f && h && r && !p:
+		return struct {
+			http.ResponseWriter
+			http.Flusher
+			http.Hijacker
+			io.ReaderFrom
+			rwUnwrapper
+		}{rw, rw, rw, rw, rw}
+	case f && h && !r && p:
+		return struct {
+			http.ResponseWriter
+			http.Flusher
+			http.Hijacker
+			http.Pusher
+			rwUnwrapper
+		}{rw, rw, rw, rw, rw}
+	case f && h && !r && !p:
+		return struct {
+			http.ResponseWriter
+			http.Flusher
+			http.Hijacker
+			rwUnwrapper
+		}{rw, rw, rw, rw}
+	case f && !h && r && p:
+		return struct {
+			http.ResponseWriter
+			http.Flusher
+			io.ReaderFrom
+			http.Pusher
+			rwUnwrapper
+		}{rw, rw, rw, rw, rw}
+	case f && !h && r && !p:
+		return struct {
+			http.ResponseWriter
+			http.Flusher
+			io.ReaderFrom
+			rwUnwrapper
+		}{rw, rw, rw, rw}
+	case f && !h && !r && p:
+		return struct {
+			http.ResponseWriter
+			http.Flusher
+			http.Pusher
+			rwUnwrapper
+		}{rw, rw, rw, rw}
+	case f && !h && !r && !p:
+		return struct {
+			http.ResponseWriter
+			http.Flusher
+			rwUnwrapper
+		}{rw, rw, rw}
+	case !f && h && r && p:
+		return struct {
+			http.ResponseWriter
+			http.Hijacker
+			io.ReaderFrom
+			http.Pusher
+			rwUnwrapper
+		}{rw, rw, rw, rw, rw}
+	case !f && h && r && !p:
+		return struct {
+			http.ResponseWriter
+			http.Hijacker
+			io.ReaderFrom
+			rwUnwrapper
+		}{rw, rw, rw, rw}
+	case !f && h && !r && p:
+		return struct {
+			http.ResponseWriter
+			http.Hijacker
+			http.Pusher
+			rwUnwrapper
+		}{rw, rw, rw, rw}
+	case !f && h && !r && !p:
+		return struct {
+			http.ResponseWriter
+			http.Hijacker
+			rwUnwrapper
+		}{rw, rw, rw}
+	case !f && !h && r && p:
+		return struct {
+			http.ResponseWriter
+			io.ReaderFrom
+			http.Pusher
+			rwUnwrapper
+		}{rw, rw, rw, rw}
+	case !f && !h && r && !p:
+		return struct {
+			http.ResponseWriter
+			io.ReaderFrom
+			rwUnwrapper
+		}{rw, rw, rw}
+	case !f && !h && !r && p:
+		return struct {
+			http.ResponseWriter
+			http.Pusher
+			rwUnwrapper
+		}{rw, rw, rw}
+	default:
+		return struct {
+			http.ResponseWriter
+			rwUnwrapper
+		}{rw, rw}
+	}
+}
+
+// Unwrap returns the http.ResponseWriter wrapped by w, or nil if w is not
+// a wrapper.
+func Unwrap(w http.ResponseWriter) http.ResponseWriter {
+	if u, ok := w.(rwUnwrapper); ok {
+		return u.Unwrap()
+	}
+	return nil
+}
@@ -0,0 +1,116 @@
+// Copyright 2026 Franklin "Snaipe" Mathieu.
+//
+// Use of this source code is governed by the MIT license that can be
+// found in the LICENSE file.
+
+package htutil
+
+import (
+	"bufio"
+	"errors"
+	"io"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+type fullWriter struct {
+	*httptest.ResponseRecorder
+	hijacked bool
+}
+
+func (w *fullWriter) Hijack() (net.Conn, *bufio.ReadWriter, error) {
+	w.hijacked = true
+	return nil, nil, errors.New("hijacked")
+}
+
+func (w *fullWriter) ReadFrom(r io.Reader) (int64, error) {
+	return io.Copy(w.ResponseRecorder, r)
+}
+
+func (w *fullWriter) Push(target string, opts *http.PushOptions) error {
+	return http.ErrNotSupported
+}
+
+func TestWrapInterfaces(t *testing.T) {
+	t.Parallel()
+
+	w := Wrap(httptest.NewRecorder(), WriterHooks{})
+	if _, ok := w.(http.Flusher); !ok {
+		t.Fatalf("expected wrapper to implement http.Flusher")
+	}
+	if _, ok := w.(http.Hijacker); ok {
+		t.Fatalf("expected wrapper to not implement http.Hijacker")
+	}
+	if _, ok := w.(io.ReaderFrom); ok {
+		t.Fatalf("expected wrapper to not implement io.ReaderFrom")
+	}
+	if _, ok := w.(http.Pusher); ok {
+		t.Fatalf("expected wrapper to not implement http.Pusher")
+	}
+
+	full := &fullWriter{ResponseRecorder: httptest.NewRecorder()}
+	w = Wrap(full, WriterHooks{})
+	if _, ok := w.(http.Flusher); !ok {
+		t.Fatalf("expected wrapper to implement http.Flusher")
+	}
+	if _, ok := w.(io.ReaderFrom); !ok {
+		t.Fatalf("expected wrapper to implement io.ReaderFrom")
+	}
+	if _, ok := w.(http.Pusher); !ok {
+		t.Fatalf("expected wrapper to implement http.Pusher")
+	}
+	hj, ok := w.(http.Hijacker)
+	if !ok {
+		t.Fatalf("expected wrapper to implement http.Hijacker")
+	}
+	hj.Hijack()
+	if !full.hijacked {
+		t.Fatalf("expected Hijack to be forwarded")
+	}
+	if Unwrap(w) != full {
+		t.Fatalf("expected Unwrap to return the wrapped writer")
+	}
+}
+
+func TestWrapHooks(t *testing.T) {
+	t.Parallel()
+
+	var (
+		status int
+		n      int
+	)
+	hooks := WriterHooks{
+		WriteHeader: func(next WriteHeaderFunc) WriteHeaderFunc {
+			return func(code int) {
+				status = code
+				next(code)
+			}
+		},
+		Write: func(next WriteFunc) WriteFunc {
+			return func(p []byte) (int, error) {
+				written, err := next(p)
+				n += written
+				return written, err
+			}
+		},
+	}
+
+	full := &fullWriter{ResponseRecorder: httptest.NewRecorder()}
+	w := Wrap(full, hooks)
+	w.WriteHeader(http.StatusTeapot)
+	w.Write([]byte("hello, "))
+	w.(io.ReaderFrom).ReadFrom(strings.NewReader("world"))
+
+	if status != http.StatusTeapot {
+		t.Fatalf("expected status %d, got %d", http.StatusTeapot, status)
+	}
+	if n != len("hello, world") {
+		t.Fatalf("expected %d bytes written, got %d", len("hello, world"), n)
+	}
+	if body := full.Body.String(); body != "hello, world" {
+		t.Fatalf("expected body %q, got %q", "hello, world", body)
+	}
+}